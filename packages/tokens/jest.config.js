@@ -0,0 +1,13 @@
+// Tests sit next to the code they cover (src/**/*.test.ts). ts-jest compiles them to CommonJS,
+// so the .js extensions of the ESM imports are mapped back to the TypeScript sources.
+export default {
+  testEnvironment: 'node',
+  roots: ['<rootDir>/src'],
+  testMatch: ['**/*.test.ts'],
+  transform: {
+    '^.+\\.ts$': 'ts-jest'
+  },
+  moduleNameMapper: {
+    '^(\\.{1,2}/.*)\\.js$': '$1'
+  }
+};
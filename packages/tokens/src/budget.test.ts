@@ -0,0 +1,104 @@
+import { countTokens } from './counting.js';
+import { allocateBudget, promptBudget } from './budget.js';
+
+const MODEL = 'gpt-4o';
+
+const long = (word: string) => `${word} `.repeat(600);
+const total = (allocation: Record<string, { tokens: number }>) =>
+  Object.values(allocation).reduce((sum, section) => sum + section.tokens, 0);
+
+describe('promptBudget', () => {
+  it('reserves the output and a safety margin', () => {
+    expect(promptBudget({ contextWindow: 1000, maxOutputTokens: 200 })).toBe(780);
+    expect(promptBudget({ contextWindow: 1000, maxOutputTokens: 200 }, 0)).toBe(800);
+  });
+
+  it('rounds the margin up', () => {
+    expect(promptBudget({ contextWindow: 1001, maxOutputTokens: 0 }, 0.01)).toBe(990);
+  });
+
+  it('never goes below zero', () => {
+    expect(promptBudget({ contextWindow: 1000, maxOutputTokens: 4000 })).toBe(0);
+  });
+});
+
+describe('allocateBudget', () => {
+  it('leaves sections that fit untouched', () => {
+    const allocation = allocateBudget([
+      { name: 'task', text: 'Build a login page' },
+      { name: 'notes', text: 'Use the design system' }
+    ], 1000, MODEL);
+
+    expect(allocation.task).toEqual({ text: 'Build a login page', tokens: countTokens('Build a login page', MODEL), truncated: false });
+    expect(allocation.notes.truncated).toBe(false);
+  });
+
+  it('gives what a small section does not need to the others', () => {
+    const allocation = allocateBudget([
+      { name: 'task', text: 'Build a login page' },
+      { name: 'context', text: long('context') }
+    ], 200, MODEL);
+
+    expect(allocation.task.truncated).toBe(false);
+    expect(allocation.context.truncated).toBe(true);
+    // More than the even split of 100 it would get if the task held on to its share
+    expect(allocation.context.tokens).toBeGreaterThan(100);
+    expect(total(allocation)).toBeLessThanOrEqual(200);
+  });
+
+  it('splits a contended budget by weight', () => {
+    const allocation = allocateBudget([
+      { name: 'history', text: long('history'), weight: 1 },
+      { name: 'code', text: long('code'), weight: 3 }
+    ], 400, MODEL);
+
+    expect(allocation.history.tokens).toBeLessThanOrEqual(100);
+    expect(allocation.code.tokens).toBeLessThanOrEqual(300);
+    expect(allocation.code.tokens).toBeGreaterThan(allocation.history.tokens);
+  });
+
+  it('treats a missing, zero or negative weight as 1', () => {
+    const allocation = allocateBudget([
+      { name: 'a', text: long('alpha') },
+      { name: 'b', text: long('bravo'), weight: 0 },
+      { name: 'c', text: long('charlie'), weight: -2 }
+    ], 300, MODEL);
+
+    for (const section of Object.values(allocation)) {
+      expect(section.tokens).toBeLessThanOrEqual(100);
+    }
+  });
+
+  it('rounds shares down so the sections never exceed the budget', () => {
+    const sections = [
+      { name: 'a', text: long('alpha'), weight: 1 },
+      { name: 'b', text: long('bravo'), weight: 2 },
+      { name: 'c', text: long('charlie'), weight: 4 }
+    ];
+
+    for (const budget of [97, 100, 101, 103, 257]) {
+      expect(total(allocateBudget(sections, budget, MODEL))).toBeLessThanOrEqual(budget);
+    }
+  });
+
+  it('applies each section its own truncation strategy', () => {
+    const text = Array.from({ length: 500 }, (_, index) => `item ${index}`).join('\n');
+    const allocation = allocateBudget([
+      { name: 'head', text, strategy: 'head' },
+      { name: 'tail', text, strategy: 'tail' }
+    ], 200, MODEL);
+
+    expect(allocation.head.text.startsWith('item 0\n')).toBe(true);
+    expect(allocation.tail.text.endsWith('item 499')).toBe(true);
+  });
+
+  it.each([0, -50])('empties every non-empty section for a budget of %i', budget => {
+    const allocation = allocateBudget([
+      { name: 'a', text: long('alpha') },
+      { name: 'b', text: 'short' }
+    ], budget, MODEL);
+
+    expect(allocation.a).toEqual({ text: '', tokens: 0, truncated: true });
+    expect(allocation.b).toEqual({ text: '', tokens: 0, truncated: true });
+  });
+});
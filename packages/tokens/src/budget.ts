@@ -0,0 +1,65 @@
+import { countTokens } from './counting.js';
+import { TruncatedText, TruncationStrategy, truncateToTokens } from './truncation.js';
+
+export interface ContextLimits {
+  contextWindow: number;
+  maxOutputTokens: number;
+}
+
+export interface BudgetSection {
+  name: string;
+  text: string;
+  // Relative share of the budget when sections compete for it (default 1)
+  weight?: number;
+  strategy?: TruncationStrategy;
+}
+
+const DEFAULT_SAFETY_MARGIN = 0.02;
+
+// Tokens a prompt may use: the context window less the reserved output and a margin that absorbs
+// the difference between our count and the provider's
+export function promptBudget(limits: ContextLimits, safetyMargin: number = DEFAULT_SAFETY_MARGIN): number {
+  const margin = Math.ceil(limits.contextWindow * safetyMargin);
+  return Math.max(0, limits.contextWindow - limits.maxOutputTokens - margin);
+}
+
+// Splits a token budget across sections by weight. A section that needs less than its share hands
+// the rest to the others, so the budget is only cut where it is actually contended; sections that
+// still do not fit are truncated with their own strategy.
+export function allocateBudget(
+  sections: BudgetSection[],
+  budget: number,
+  model: string
+): Record<string, TruncatedText> {
+  const needs = sections.map(section => ({ section, tokens: countTokens(section.text, model) }));
+  const result: Record<string, TruncatedText> = {};
+
+  let remaining = budget;
+  let pending = [...needs].sort((a, b) => a.tokens / weightOf(a.section) - b.tokens / weightOf(b.section));
+
+  while (pending.length > 0) {
+    const totalWeight = pending.reduce((sum, entry) => sum + weightOf(entry.section), 0);
+    const { section, tokens } = pending[0];
+    const share = Math.floor(remaining * weightOf(section) / totalWeight);
+
+    if (tokens <= share) {
+      result[section.name] = { text: section.text, tokens, truncated: false };
+      remaining -= tokens;
+      pending = pending.slice(1);
+      continue;
+    }
+
+    // Sorted by need per unit of weight, so every section left is over its share as well
+    for (const entry of pending) {
+      const allowance = Math.floor(remaining * weightOf(entry.section) / totalWeight);
+      result[entry.section.name] = truncateToTokens(entry.section.text, allowance, model, entry.section.strategy);
+    }
+    break;
+  }
+
+  return result;
+}
+
+function weightOf(section: BudgetSection): number {
+  return section.weight && section.weight > 0 ? section.weight : 1;
+}
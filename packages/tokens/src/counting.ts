@@ -0,0 +1,56 @@
+import { getEncoding, Tiktoken, TiktokenEncoding } from 'js-tiktoken';
+
+export interface Message {
+  role: string;
+  content: string;
+}
+
+interface Tokenizer {
+  encoding: TiktokenEncoding;
+  // Applied on top of the encoding's count for models whose tokenizer is not public
+  scale: number;
+}
+
+// Every chat message carries a few tokens of framing (role, separators), and the reply is primed
+// with a few more; these match OpenAI's published accounting and are close enough for the others
+const MESSAGE_OVERHEAD = 4;
+const REPLY_OVERHEAD = 3;
+
+const encoders = new Map<TiktokenEncoding, Tiktoken>();
+
+// OpenAI models are counted exactly with their own encoding. Anthropic and Gemini do not publish
+// their tokenizers, so they are counted with cl100k and scaled up so estimates err on the high side.
+export function tokenizerFor(model: string): Tokenizer {
+  const name = model.toLowerCase();
+  if (/^(gpt-4o|gpt-4\.1|gpt-5|o\d)/.test(name)) return { encoding: 'o200k_base', scale: 1 };
+  if (name.startsWith('gpt-')) return { encoding: 'cl100k_base', scale: 1 };
+  if (name.startsWith('gemini')) return { encoding: 'cl100k_base', scale: 1.1 };
+  // Claude and anything unknown get the widest margin
+  return { encoding: 'cl100k_base', scale: 1.2 };
+}
+
+function encoder(encoding: TiktokenEncoding): Tiktoken {
+  let cached = encoders.get(encoding);
+  if (!cached) {
+    cached = getEncoding(encoding);
+    encoders.set(encoding, cached);
+  }
+  return cached;
+}
+
+export function countTokens(text: string, model: string): number {
+  if (!text) return 0;
+  const { encoding, scale } = tokenizerFor(model);
+  // Special-token markers in user content are counted as plain text rather than rejected
+  const tokens = encoder(encoding).encode(text, [], []).length;
+  return Math.ceil(tokens * scale);
+}
+
+export function countMessageTokens(messages: Message[], model: string): number {
+  if (messages.length === 0) return 0;
+  return messages.reduce((total, message) => total + messageTokens(message, model), REPLY_OVERHEAD);
+}
+
+export function messageTokens(message: Message, model: string): number {
+  return countTokens(message.content, model) + MESSAGE_OVERHEAD;
+}
@@ -0,0 +1,8 @@
+export type { Message } from './counting.js';
+export { countTokens, countMessageTokens, messageTokens, tokenizerFor } from './counting.js';
+export type { TruncatedText, TruncationStrategy } from './truncation.js';
+export { truncateToTokens } from './truncation.js';
+export type { BudgetSection, ContextLimits } from './budget.js';
+export { allocateBudget, promptBudget } from './budget.js';
+export type { FittedMessages } from './messages.js';
+export { fitMessages } from './messages.js';
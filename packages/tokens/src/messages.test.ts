@@ -0,0 +1,98 @@
+import { Message, countMessageTokens, messageTokens } from './counting.js';
+import { fitMessages } from './messages.js';
+
+const MODEL = 'gpt-4o';
+
+const turn = (role: string, label: string, size = 100): Message => ({
+  role,
+  content: `${label}: ${'detail '.repeat(size)}`
+});
+
+// system, opening request, three tool-call rounds, latest message
+const conversation: Message[] = [
+  { role: 'system', content: 'You are the backend architect.' },
+  { role: 'user', content: 'Design the API for the billing service.' },
+  turn('assistant', 'call 1'),
+  turn('user', 'result 1'),
+  turn('assistant', 'call 2'),
+  turn('user', 'result 2'),
+  turn('assistant', 'call 3'),
+  { role: 'user', content: 'Now write the OpenAPI document.' }
+];
+
+describe('fitMessages', () => {
+  it('returns a conversation that fits as it is', () => {
+    const result = fitMessages(conversation, 100000, MODEL);
+
+    expect(result.messages).toBe(conversation);
+    expect(result).toMatchObject({ tokens: countMessageTokens(conversation, MODEL), dropped: 0, truncated: 0 });
+  });
+
+  it('drops the oldest turns in pairs, keeping the system message, opening request and latest message', () => {
+    const budget = countMessageTokens(conversation, MODEL)
+      - messageTokens(conversation[2], MODEL)
+      - messageTokens(conversation[3], MODEL);
+    const result = fitMessages(conversation, budget, MODEL);
+
+    expect(result.messages).toEqual([conversation[0], conversation[1], ...conversation.slice(4)]);
+    expect(result).toMatchObject({ dropped: 2, truncated: 0 });
+    expect(result.tokens).toBe(countMessageTokens(result.messages, MODEL));
+    expect(result.tokens).toBeLessThanOrEqual(budget);
+  });
+
+  it('keeps user and assistant turns alternating after dropping', () => {
+    const result = fitMessages(conversation, countMessageTokens(conversation, MODEL) - 150, MODEL);
+    const roles = result.messages.filter(message => message.role !== 'system').map(message => message.role);
+
+    roles.slice(1).forEach((role, index) => expect(role).not.toBe(roles[index]));
+  });
+
+  it('cuts the largest remaining messages once nothing more can be dropped', () => {
+    const budget = 120;
+    const result = fitMessages(conversation, budget, MODEL);
+
+    expect(result.dropped).toBe(4);
+    expect(result.truncated).toBeGreaterThan(0);
+    expect(result.tokens).toBeLessThanOrEqual(budget);
+    expect(result.tokens).toBe(countMessageTokens(result.messages, MODEL));
+    expect(result.messages[0]).toEqual(conversation[0]);
+    expect(result.messages[result.messages.length - 1]).toEqual(conversation[conversation.length - 1]);
+  });
+
+  it('cuts a system message that alone is over budget', () => {
+    const messages: Message[] = [
+      turn('system', 'instructions', 2000),
+      { role: 'user', content: 'hi' }
+    ];
+    const result = fitMessages(messages, 300, MODEL);
+
+    expect(result).toMatchObject({ dropped: 0, truncated: 1 });
+    expect(result.tokens).toBeLessThanOrEqual(300);
+    expect(result.messages[0].content).toMatch(/\[truncated \d+ characters\]/);
+    expect(result.messages[1]).toEqual(messages[1]);
+  });
+
+  it('does not modify the messages it was given', () => {
+    const messages: Message[] = [turn('system', 'instructions', 2000), { role: 'user', content: 'hi' }];
+    const original = messages[0].content;
+
+    fitMessages(messages, 300, MODEL);
+
+    expect(messages[0].content).toBe(original);
+  });
+
+  it.each([0, -100])('empties every message for a budget of %i, leaving only the framing', budget => {
+    const messages: Message[] = [turn('system', 'instructions'), turn('user', 'request')];
+    const result = fitMessages(messages, budget, MODEL);
+
+    expect(result.messages.map(message => message.content)).toEqual(['', '']);
+    expect(result.truncated).toBe(2);
+    // Role and separator tokens cannot be cut, so the result stays over a budget this small
+    expect(result.tokens).toBe(countMessageTokens(result.messages, MODEL));
+    expect(result.tokens).toBeGreaterThan(0);
+  });
+
+  it('leaves an empty conversation alone', () => {
+    expect(fitMessages([], 0, MODEL)).toEqual({ messages: [], tokens: 0, dropped: 0, truncated: 0 });
+  });
+});
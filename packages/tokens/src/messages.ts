@@ -0,0 +1,64 @@
+import { Message, countMessageTokens, countTokens, messageTokens } from './counting.js';
+import { truncateToTokens } from './truncation.js';
+
+export interface FittedMessages<T extends Message> {
+  messages: T[];
+  tokens: number;
+  // Messages removed from the conversation, and kept messages whose content was cut
+  dropped: number;
+  truncated: number;
+}
+
+// Fits a conversation into a prompt budget. System messages, the opening request and the latest
+// message are kept; the oldest turns in between (tool-call rounds in an agent loop) are dropped
+// first, in assistant/user pairs so the turns still alternate. If that is not enough, the largest
+// remaining messages are cut in the middle, which keeps their framing and their conclusion.
+export function fitMessages<T extends Message>(messages: T[], budget: number, model: string): FittedMessages<T> {
+  let tokens = countMessageTokens(messages, model);
+  if (tokens <= budget) return { messages, tokens, dropped: 0, truncated: 0 };
+
+  const firstTurn = messages.findIndex(message => message.role !== 'system');
+  const protectedIndexes = new Set<number>([firstTurn, messages.length - 1]);
+  messages.forEach((message, index) => {
+    if (message.role === 'system') protectedIndexes.add(index);
+  });
+
+  const sizes = messages.map(message => messageTokens(message, model));
+  const droppedIndexes = new Set<number>();
+  for (let index = firstTurn + 1; index < messages.length - 1 && tokens > budget; index += 2) {
+    const pair = [index, index + 1].filter(candidate => !protectedIndexes.has(candidate));
+    if (pair.length < 2) break;
+    pair.forEach(candidate => {
+      droppedIndexes.add(candidate);
+      tokens -= sizes[candidate];
+    });
+  }
+
+  const kept = messages
+    .map((message, index) => ({ message, index }))
+    .filter(entry => !droppedIndexes.has(entry.index));
+
+  let truncated = 0;
+  const bySize = [...kept].sort((a, b) => sizes[b.index] - sizes[a.index]);
+  for (const entry of bySize) {
+    if (tokens <= budget) break;
+    const excess = tokens - budget;
+    const current = sizes[entry.index];
+    // The per-message framing cannot be cut, only the content
+    const content = countTokens(entry.message.content, model);
+    const cut = truncateToTokens(entry.message.content, Math.max(0, content - excess), model, 'middle');
+    entry.message = { ...entry.message, content: cut.text };
+    const size = messageTokens(entry.message, model);
+    tokens -= current - size;
+    sizes[entry.index] = size;
+    truncated++;
+  }
+
+  return {
+    messages: kept.map(entry => entry.message),
+    tokens,
+    dropped: droppedIndexes.size,
+    truncated
+  };
+}
+
@@ -0,0 +1,90 @@
+import { countTokens } from './counting.js';
+import { truncateToTokens } from './truncation.js';
+
+const MODEL = 'gpt-4o';
+const MARKER = /\n\.\.\. \[truncated \d+ characters\] \.\.\.\n/;
+// A high surrogate not followed by a low one, or a low one not preceded by a high one
+const LONE_SURROGATE = /[\uD800-\uDBFF](?![\uDC00-\uDFFF])|(?<![\uD800-\uDBFF])[\uDC00-\uDFFF]/;
+
+const lines = Array.from({ length: 2000 }, (_, index) => `line ${index}`).join('\n');
+
+describe('truncateToTokens', () => {
+  it('returns text that fits unchanged', () => {
+    const result = truncateToTokens('hello world', 100, MODEL);
+
+    expect(result).toEqual({ text: 'hello world', tokens: countTokens('hello world', MODEL), truncated: false });
+  });
+
+  it('keeps the beginning with head', () => {
+    const result = truncateToTokens(lines, 100, MODEL, 'head');
+
+    expect(result.truncated).toBe(true);
+    expect(result.tokens).toBeLessThanOrEqual(100);
+    expect(result.tokens).toBe(countTokens(result.text, MODEL));
+    expect(result.text.startsWith('line 0\nline 1\n')).toBe(true);
+    expect(result.text).toMatch(new RegExp(`${MARKER.source}$`));
+  });
+
+  it('keeps the end with tail', () => {
+    const result = truncateToTokens(lines, 100, MODEL, 'tail');
+
+    expect(result.tokens).toBeLessThanOrEqual(100);
+    expect(result.text.endsWith('line 1998\nline 1999')).toBe(true);
+    expect(result.text).toMatch(new RegExp(`^${MARKER.source}`));
+  });
+
+  it('keeps both ends with middle', () => {
+    const result = truncateToTokens(lines, 100, MODEL, 'middle');
+
+    expect(result.tokens).toBeLessThanOrEqual(100);
+    expect(result.text.startsWith('line 0\n')).toBe(true);
+    expect(result.text.endsWith('line 1999')).toBe(true);
+    expect(result.text).toMatch(MARKER);
+  });
+
+  it('reports how many characters the marker replaced', () => {
+    const result = truncateToTokens(lines, 100, MODEL, 'head');
+    const [kept] = result.text.split(MARKER);
+    const removed = Number(result.text.match(/truncated (\d+) characters/)![1]);
+
+    expect(kept.length + removed).toBe(lines.length);
+  });
+
+  it('may cut in the middle of a word but keeps what it kept verbatim', () => {
+    const text = 'supercalifragilisticexpialidocious '.repeat(500);
+    const result = truncateToTokens(text, 50, MODEL, 'head');
+    const [kept] = result.text.split(MARKER);
+
+    expect(result.tokens).toBeLessThanOrEqual(50);
+    expect(kept.length).toBeGreaterThan(0);
+    expect(text.startsWith(kept)).toBe(true);
+  });
+
+  it.each(['head', 'tail', 'middle'] as const)('does not split characters outside the BMP with %s', strategy => {
+    const text = '😀'.repeat(3000);
+
+    for (const budget of [7, 50, 101, 333]) {
+      const result = truncateToTokens(text, budget, MODEL, strategy);
+
+      expect(result.tokens).toBeLessThanOrEqual(budget);
+      expect(result.text).not.toMatch(LONE_SURROGATE);
+    }
+  });
+
+  it('keeps multibyte text within budget', () => {
+    const text = 'Xin chào thế giới, こんにちは世界。'.repeat(400);
+    const result = truncateToTokens(text, 64, MODEL, 'middle');
+
+    expect(result.truncated).toBe(true);
+    expect(result.tokens).toBeLessThanOrEqual(64);
+    expect(result.tokens).toBe(countTokens(result.text, MODEL));
+  });
+
+  it('returns nothing when not even the marker fits', () => {
+    expect(truncateToTokens(lines, 3, MODEL)).toEqual({ text: '', tokens: 0, truncated: true });
+  });
+
+  it.each([0, -10])('returns nothing for a budget of %i', budget => {
+    expect(truncateToTokens(lines, budget, MODEL)).toEqual({ text: '', tokens: 0, truncated: true });
+  });
+});
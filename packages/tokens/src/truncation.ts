@@ -0,0 +1,56 @@
+import { countTokens } from './counting.js';
+
+// head keeps the beginning, tail keeps the end, middle keeps both ends and cuts what lies between
+export type TruncationStrategy = 'head' | 'tail' | 'middle';
+
+export interface TruncatedText {
+  text: string;
+  tokens: number;
+  truncated: boolean;
+}
+
+function marker(removedChars: number): string {
+  return `\n... [truncated ${removedChars} characters] ...\n`;
+}
+
+// True when index falls between the two halves of a surrogate pair (emoji and other characters
+// outside the BMP), where slicing would leave half a character behind
+function splitsPair(text: string, index: number): boolean {
+  if (index <= 0 || index >= text.length) return false;
+  const high = text.charCodeAt(index - 1);
+  const low = text.charCodeAt(index);
+  return high >= 0xd800 && high <= 0xdbff && low >= 0xdc00 && low <= 0xdfff;
+}
+
+function cut(text: string, keep: number, strategy: TruncationStrategy): string {
+  const front = strategy === 'head' ? keep : strategy === 'tail' ? 0 : Math.ceil(keep / 2);
+  let end = front;
+  let start = text.length - (keep - front);
+  if (splitsPair(text, end)) end--;
+  if (splitsPair(text, start)) start++;
+  return text.slice(0, end) + marker(start - end) + text.slice(start);
+}
+
+// Cuts text down to at most maxTokens for the model, leaving a marker where content was removed.
+// The first cut is sized from the overall token density and shrunk until it fits, which keeps the
+// number of full encodes small even for very large inputs.
+export function truncateToTokens(
+  text: string,
+  maxTokens: number,
+  model: string,
+  strategy: TruncationStrategy = 'head'
+): TruncatedText {
+  const total = countTokens(text, model);
+  if (total <= maxTokens) return { text, tokens: total, truncated: false };
+
+  let keep = Math.floor(text.length * (maxTokens / total));
+  while (keep > 0) {
+    const candidate = cut(text, keep, strategy);
+    const tokens = countTokens(candidate, model);
+    if (tokens <= maxTokens) return { text: candidate, tokens, truncated: true };
+    keep = Math.floor(keep * Math.max(0.5, maxTokens / tokens) * 0.98);
+  }
+
+  // Not even the marker fits
+  return { text: '', tokens: 0, truncated: true };
+}
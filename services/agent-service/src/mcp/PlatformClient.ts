@@ -0,0 +1,126 @@
+import axios from 'axios';
+
+export interface ArtifactContent {
+  name: string;
+  type: string;
+  contentType: string;
+  content: Buffer;
+}
+
+export interface ArtifactFilter {
+  projectId?: string;
+  runId?: string;
+  type?: string;
+}
+
+const TEXT_TYPE = /^text\/|json|xml|yaml|javascript|typescript|svg/;
+
+export const isTextContent = (contentType: string) => TEXT_TYPE.test(contentType);
+
+// Code bundles are stored as a JSON list of { path, content } (or { files: [...] })
+export function bundleFiles(content: Buffer): Array<{ path: string; content: string }> {
+  const parsed = JSON.parse(content.toString('utf-8'));
+  const entries = Array.isArray(parsed) ? parsed : parsed?.files;
+  return (Array.isArray(entries) ? entries : [])
+    .filter((entry: any) => typeof entry?.path === 'string')
+    .map((entry: any) => ({ path: entry.path, content: typeof entry.content === 'string' ? entry.content : '' }));
+}
+
+// Binary responses (artifact content) carry their JSON error body as bytes
+function errorMessage(data: any): string | undefined {
+  if (!Buffer.isBuffer(data)) return data?.error;
+  try {
+    return JSON.parse(data.toString('utf-8')).error;
+  } catch {
+    return undefined;
+  }
+}
+
+// Reads the project service and artifact store on behalf of MCP clients, always as the MCP caller
+// (its Authorization header). The platform's own agents call in with PROJECT_SERVICE_TOKEN. Artifact
+// reads are only allowed for artifacts of projects the caller can read from project-service.
+export class PlatformClient {
+  private projectServiceUrl = process.env.PROJECT_SERVICE_URL || 'http://localhost:3002';
+  private artifactServiceUrl = process.env.ARTIFACT_SERVICE_URL || 'http://localhost:3008';
+  private maxArtifactBytes = parseInt(process.env.MCP_ARTIFACT_MAX_BYTES || '1048576');
+
+  async getProject(projectId: string, authorization: string): Promise<any> {
+    const response = await this.get(`${this.projectServiceUrl}/api/projects/${encodeURIComponent(projectId)}`, {
+      headers: { Authorization: authorization }
+    });
+    return response.data.data;
+  }
+
+  // Listing by run alone may span projects; artifacts of projects the caller cannot read are left out
+  async listArtifacts(filter: ArtifactFilter, authorization: string): Promise<any[]> {
+    if (filter.projectId) {
+      await this.getProject(filter.projectId, authorization);
+    }
+
+    const response = await this.get(`${this.artifactServiceUrl}/api/artifacts`, {
+      params: filter,
+      headers: { Authorization: authorization }
+    });
+    const artifacts: any[] = response.data.data;
+    const readable = await this.readableProjects(artifacts.map(artifact => artifact.projectId), authorization);
+
+    return artifacts
+      .filter(artifact => readable.has(artifact.projectId))
+      .map(artifact => ({
+        id: artifact._id,
+        name: artifact.name,
+        type: artifact.type,
+        contentType: artifact.contentType,
+        size: artifact.size,
+        runId: artifact.runId,
+        stageId: artifact.stageId,
+        createdAt: artifact.createdAt
+      }));
+  }
+
+  async readArtifact(artifactId: string, authorization: string): Promise<ArtifactContent> {
+    const id = encodeURIComponent(artifactId);
+    const { data: meta } = await this.get(`${this.artifactServiceUrl}/api/artifacts/${id}`, {
+      headers: { Authorization: authorization }
+    });
+    const artifact = meta.data;
+    const readable = await this.readableProjects([artifact.projectId], authorization);
+    if (!readable.has(artifact.projectId)) {
+      throw new Error('Artifact not found');
+    }
+    if (artifact.size > this.maxArtifactBytes) {
+      throw new Error(`Artifact ${artifactId} is ${artifact.size} bytes; the limit is ${this.maxArtifactBytes}`);
+    }
+
+    const response = await this.get(`${this.artifactServiceUrl}/api/artifacts/${id}/content`, {
+      responseType: 'arraybuffer',
+      headers: { Authorization: authorization }
+    });
+    return {
+      name: artifact.name,
+      type: artifact.type,
+      contentType: artifact.contentType,
+      content: Buffer.from(response.data)
+    };
+  }
+
+  // Projects among these the caller can read; artifacts without a project belong to nobody's
+  private async readableProjects(projectIds: Array<string | undefined>, authorization: string): Promise<Set<string>> {
+    const unique = Array.from(new Set(projectIds.filter((id): id is string => !!id)));
+    const checks = await Promise.all(unique.map(projectId =>
+      this.getProject(projectId, authorization).then(() => projectId, () => null)
+    ));
+    return new Set(checks.filter((id): id is string => id !== null));
+  }
+
+  private async get(url: string, options: Record<string, any> = {}) {
+    try {
+      return await axios.get(url, { timeout: 10000, ...options });
+    } catch (error) {
+      if (axios.isAxiosError(error) && error.response) {
+        throw new Error(errorMessage(error.response.data) || `Request failed with status ${error.response.status}`);
+      }
+      throw error;
+    }
+  }
+}
@@ -0,0 +1,123 @@
+import { McpServer, ResourceTemplate } from '@modelcontextprotocol/sdk/server/mcp.js';
+import { z } from 'zod';
+import { PlatformClient, bundleFiles, isTextContent } from './PlatformClient.js';
+
+// Template variables may come back as a list when a URI repeats one
+const variable = (value: string | string[]) => Array.isArray(value) ? value[0] : value;
+
+const json = (uri: URL, data: any) => ({
+  contents: [{ uri: uri.href, mimeType: 'application/json', text: JSON.stringify(data, null, 2) }]
+});
+
+const toolText = (data: any) => ({
+  content: [{ type: 'text' as const, text: typeof data === 'string' ? data : JSON.stringify(data, null, 2) }]
+});
+
+const toolError = (error: unknown) => ({
+  content: [{ type: 'text' as const, text: error instanceof Error ? error.message : String(error) }],
+  isError: true
+});
+
+// The run an agent's tool call belongs to, as sent by McpToolClient in _meta
+const callerProject = (extra: any): string | undefined => extra?._meta?.projectId;
+
+// MCP view of the platform: projects and artifacts as resources for any MCP client, and the same
+// reads as tools for agents, whose tool protocol has no resources. A server is built per request so
+// project and artifact reads run with that request's credentials.
+export function createPlatformMcpServer(platform: PlatformClient, authorization: string): McpServer {
+  const server = new McpServer({ name: 'ai-pipeline-platform', version: '1.0.0' });
+
+  server.resource(
+    'project',
+    new ResourceTemplate('project://{projectId}', { list: undefined }),
+    { description: 'A project: description, requirements and collaborators', mimeType: 'application/json' },
+    async (uri, { projectId }) => json(uri, await platform.getProject(variable(projectId), authorization))
+  );
+
+  server.resource(
+    'project-artifacts',
+    new ResourceTemplate('project://{projectId}/artifacts', { list: undefined }),
+    { description: 'Artifacts stored for a project, newest first', mimeType: 'application/json' },
+    async (uri, { projectId }) => json(uri, await platform.listArtifacts({ projectId: variable(projectId) }, authorization))
+  );
+
+  server.resource(
+    'run-artifacts',
+    new ResourceTemplate('run://{runId}/artifacts', { list: undefined }),
+    { description: 'Artifacts produced by a pipeline run', mimeType: 'application/json' },
+    async (uri, { runId }) => json(uri, await platform.listArtifacts({ runId: variable(runId) }, authorization))
+  );
+
+  server.resource(
+    'artifact',
+    new ResourceTemplate('artifact://{artifactId}', { list: undefined }),
+    { description: 'Content of a stored artifact (code bundle, design doc, diagram)' },
+    async (uri, { artifactId }) => {
+      const artifact = await platform.readArtifact(variable(artifactId), authorization);
+      return {
+        contents: [isTextContent(artifact.contentType)
+          ? { uri: uri.href, mimeType: artifact.contentType, text: artifact.content.toString('utf-8') }
+          : { uri: uri.href, mimeType: artifact.contentType, blob: artifact.content.toString('base64') }]
+      };
+    }
+  );
+
+  server.tool(
+    'get_project',
+    'Read a project: its description, requirements and collaborators. Defaults to the current run\'s project.',
+    { projectId: z.string().optional() },
+    async ({ projectId }, extra) => {
+      try {
+        const id = projectId || callerProject(extra);
+        if (!id) return toolError('projectId is required outside a project run');
+        return toolText(await platform.getProject(id, authorization));
+      } catch (error) {
+        return toolError(error);
+      }
+    }
+  );
+
+  server.tool(
+    'list_artifacts',
+    'List stored artifacts (code-bundle, design-doc, diagram, other) for a project or a run, newest first. Defaults to the current run\'s project.',
+    {
+      projectId: z.string().optional(),
+      runId: z.string().optional(),
+      type: z.enum(['code-bundle', 'design-doc', 'diagram', 'other']).optional()
+    },
+    async ({ projectId, runId, type }, extra) => {
+      try {
+        const project = projectId || (runId ? undefined : callerProject(extra));
+        if (!project && !runId) return toolError('projectId or runId is required outside a project run');
+        return toolText(await platform.listArtifacts({ projectId: project, runId, type }, authorization));
+      } catch (error) {
+        return toolError(error);
+      }
+    }
+  );
+
+  server.tool(
+    'read_artifact',
+    'Read a stored artifact. For a code bundle, pass path to read one file; without it the bundle\'s file list is returned.',
+    { artifactId: z.string(), path: z.string().optional() },
+    async ({ artifactId, path }) => {
+      try {
+        const artifact = await platform.readArtifact(artifactId, authorization);
+        if (artifact.type === 'code-bundle') {
+          const files = bundleFiles(artifact.content);
+          if (!path) return toolText(files.map(file => file.path));
+          const file = files.find(entry => entry.path === path);
+          return file ? toolText(file.content) : toolError(`No file ${path} in bundle ${artifactId}`);
+        }
+        if (!isTextContent(artifact.contentType)) {
+          return toolError(`Artifact ${artifactId} is ${artifact.contentType}, not text`);
+        }
+        return toolText(artifact.content.toString('utf-8'));
+      } catch (error) {
+        return toolError(error);
+      }
+    }
+  );
+
+  return server;
+}
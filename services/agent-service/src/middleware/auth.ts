@@ -0,0 +1,112 @@
+import { Request, Response, NextFunction } from 'express';
+import axios from 'axios';
+import { timingSafeEqual } from 'crypto';
+
+export interface User {
+  _id: string;
+  username: string;
+  email: string;
+  role: 'user' | 'admin';
+  isActive: boolean;
+}
+
+export interface AuthenticatedRequest extends Request {
+  user?: User;
+  // Set when the caller is another platform service presenting INTERNAL_SERVICE_TOKEN
+  service?: boolean;
+}
+
+export const authenticateToken = async (
+  req: AuthenticatedRequest,
+  res: Response,
+  next: NextFunction
+): Promise<void> => {
+  const authHeader = req.headers.authorization;
+  const token = authHeader && authHeader.split(' ')[1];
+
+  if (!token) {
+    res.status(401).json({
+      success: false,
+      error: 'Access token required'
+    });
+    return;
+  }
+
+  try {
+    // Verify with auth service
+    const authServiceUrl = process.env.AUTH_SERVICE_URL || 'http://localhost:3001';
+    const response = await axios.get(`${authServiceUrl}/api/auth/verify`, {
+      headers: { Authorization: `Bearer ${token}` },
+      timeout: 5000
+    });
+
+    if (!response.data.success) {
+      res.status(401).json({
+        success: false,
+        error: 'Invalid token'
+      });
+      return;
+    }
+
+    req.user = {
+      _id: response.data.data.userId,
+      username: response.data.data.username,
+      email: response.data.data.email,
+      role: response.data.data.role,
+      isActive: response.data.data.isActive
+    };
+
+    next();
+  } catch (error) {
+    console.error('Authentication error:', error);
+    res.status(403).json({
+      success: false,
+      error: 'Authentication failed'
+    });
+  }
+};
+
+export const requireAuth = authenticateToken;
+
+const serviceTokenMatches = (provided?: string): boolean => {
+  const expected = process.env.INTERNAL_SERVICE_TOKEN;
+  return !!expected && !!provided && provided.length === expected.length &&
+    timingSafeEqual(Buffer.from(provided), Buffer.from(expected));
+};
+
+// Platform services only, e.g. pipeline-service and eval-service running stages
+export const requireServiceToken = (req: AuthenticatedRequest, res: Response, next: NextFunction): void => {
+  if (!serviceTokenMatches(req.get('X-Service-Token'))) {
+    res.status(401).json({
+      success: false,
+      error: 'Service token required'
+    });
+    return;
+  }
+  req.service = true;
+  next();
+};
+
+// Platform services (X-Service-Token) or authenticated admins
+export const requireAdminOrService = (
+  req: AuthenticatedRequest,
+  res: Response,
+  next: NextFunction
+): void => {
+  if (serviceTokenMatches(req.get('X-Service-Token'))) {
+    req.service = true;
+    next();
+    return;
+  }
+
+  authenticateToken(req, res, () => {
+    if (req.user!.role !== 'admin') {
+      res.status(403).json({
+        success: false,
+        error: 'Admin access required'
+      });
+      return;
+    }
+    next();
+  });
+};
@@ -0,0 +1,146 @@
+import express, { Request, Response } from 'express';
+import { body, param, validationResult } from 'express-validator';
+import { AgentRuntime } from '../runtime/AgentRuntime.js';
+import { RoleRegistry } from '../runtime/RoleRegistry.js';
+import { ToolRegistry } from '../tools/ToolRegistry.js';
+import { ModerationBlockedError } from '../runtime/ModerationClient.js';
+import { OutputSchemaError } from '../runtime/OutputValidator.js';
+import { requireServiceToken } from '../middleware/auth.js';
+
+const router = express.Router();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+// Streams a stage as newline-delimited JSON: {"type":"token","text"} while the agent writes, then
+// one {"type":"result","data"} (the usual ExecuteStage response) or {"type":"error","error"}
+async function streamStage(runtime: AgentRuntime, role: string, req: Request, res: Response, signal: AbortSignal) {
+  res.status(200).set({
+    'Content-Type': 'application/x-ndjson',
+    'Cache-Control': 'no-cache',
+    'X-Accel-Buffering': 'no'
+  });
+  res.flushHeaders();
+
+  const send = (message: Record<string, any>) => {
+    if (!res.writableEnded) res.write(`${JSON.stringify(message)}\n`);
+  };
+
+  try {
+    const result = await runtime.executeStage(role, req.body, {
+      signal,
+      onToken: text => send({ type: 'token', text })
+    });
+    send({ type: 'result', data: result });
+  } catch (error) {
+    if (!signal.aborted) console.error('Stage execution error:', error);
+    send({ type: 'error', error: error instanceof Error ? error.message : 'Failed to execute stage' });
+  }
+  res.end();
+}
+
+export default function createAgentRoutes(runtime: AgentRuntime, roles: RoleRegistry, tools: ToolRegistry) {
+  // GET /api/agents - List configured agent roles
+  router.get('/', (req: Request, res: Response) => {
+    res.json({
+      success: true,
+      data: roles.list().map(role => ({
+        id: role.id,
+        name: role.name,
+        description: role.description,
+        model: role.model,
+        outputFormat: role.outputFormat,
+        outputSchema: role.outputSchema,
+        requiredInputs: role.requiredInputs,
+        toolScopes: role.toolScopes || [],
+        tools: tools.availableTo(role).map(tool => tool.name)
+      }))
+    });
+  });
+
+  // POST /api/agents/:role/execute - Execute a pipeline stage with the given agent role (internal).
+  // toolScopes come from the stage's pipeline definition and grant tools, so only services may call this.
+  router.post('/:role/execute',
+    requireServiceToken,
+    [
+      param('role').isString().isLength({ min: 1 }).withMessage('Agent role is required'),
+      body('runId').isString().isLength({ min: 1 }).withMessage('Run ID is required'),
+      body('stageId').isString().isLength({ min: 1 }).withMessage('Stage ID is required'),
+      body('projectId').optional().isString().withMessage('Project ID must be a string'),
+      body('inputs').isObject().withMessage('Inputs must be an object'),
+      body('previousOutputs').optional().isObject().withMessage('Previous outputs must be an object'),
+      body('files').optional().isObject().withMessage('Files must be an object'),
+      body('modelOverride').optional().isString().withMessage('Model override must be a string'),
+      body('toolScopes').optional().isArray().withMessage('Tool scopes must be a list'),
+      body('toolScopes.*').isString().isLength({ min: 1 }).withMessage('Tool scopes must be scope names'),
+      body('outputSchema').optional().isObject().withMessage('Output schema must be a JSON Schema object'),
+      body('outputRepairs').optional().isInt({ min: 0, max: 5 }).withMessage('Output repairs must be between 0 and 5'),
+      body('promptVersion').optional().isInt({ min: 1 }).withMessage('Prompt version must be a positive integer'),
+      body('memory').optional().isBoolean().withMessage('Memory must be a boolean'),
+      body('stream').optional().isBoolean().withMessage('stream must be a boolean')
+    ],
+    validateRequest,
+    async (req: Request, res: Response) => {
+      const { role } = req.params;
+
+      if (!roles.get(role)) {
+        return res.status(404).json({
+          success: false,
+          error: `Agent role "${role}" not found`
+        });
+      }
+
+      // The caller hanging up (a cancelled run, a stage timeout) cancels the agent's LLM call
+      const abort = new AbortController();
+      res.on('close', () => {
+        if (!res.writableFinished) abort.abort();
+      });
+
+      if (req.body.stream) {
+        return streamStage(runtime, role, req, res, abort.signal);
+      }
+
+      try {
+        const result = await runtime.executeStage(role, req.body, { signal: abort.signal });
+
+        res.status(result.status === 'completed' ? 200 : 422).json({
+          success: result.status === 'completed',
+          data: result,
+          ...(result.error ? { error: result.error } : {})
+        });
+      } catch (error) {
+        if (error instanceof ModerationBlockedError) {
+          return res.status(422).json({
+            success: false,
+            error: error.message,
+            moderationRecordId: error.recordId
+          });
+        }
+        if (error instanceof OutputSchemaError) {
+          return res.status(400).json({
+            success: false,
+            error: error.message
+          });
+        }
+
+        console.error('Stage execution error:', error);
+        res.status(500).json({
+          success: false,
+          error: error instanceof Error ? error.message : 'Failed to execute stage'
+        });
+      }
+    }
+  );
+
+  return router;
+}
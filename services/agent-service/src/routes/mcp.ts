@@ -0,0 +1,49 @@
+import express, { Request, Response } from 'express';
+import { StreamableHTTPServerTransport } from '@modelcontextprotocol/sdk/server/streamableHttp.js';
+import { PlatformClient } from '../mcp/PlatformClient.js';
+import { createPlatformMcpServer } from '../mcp/PlatformMcpServer.js';
+import { requireAuth } from '../middleware/auth.js';
+
+const router = express.Router();
+
+const methodNotAllowed = (req: Request, res: Response) => {
+  res.status(405).json({
+    jsonrpc: '2.0',
+    error: { code: -32000, message: 'Method not allowed; this MCP server is stateless, use POST' },
+    id: null
+  });
+};
+
+export default function createMcpRoutes(platform: PlatformClient) {
+  // POST /mcp - Streamable HTTP MCP endpoint, stateless: every request gets its own server and transport.
+  // Callers authenticate as a platform user; reads are then made with their credentials.
+  router.post('/', requireAuth, async (req: Request, res: Response) => {
+    const server = createPlatformMcpServer(platform, req.headers.authorization!);
+    const transport = new StreamableHTTPServerTransport({ sessionIdGenerator: undefined });
+
+    res.on('close', () => {
+      transport.close();
+      server.close();
+    });
+
+    try {
+      await server.connect(transport);
+      await transport.handleRequest(req, res, req.body);
+    } catch (error) {
+      console.error('MCP request error:', error);
+      if (!res.headersSent) {
+        res.status(500).json({
+          jsonrpc: '2.0',
+          error: { code: -32603, message: 'Internal server error' },
+          id: null
+        });
+      }
+    }
+  });
+
+  // No sessions, so no server-initiated stream to open and nothing to end
+  router.get('/', methodNotAllowed);
+  router.delete('/', methodNotAllowed);
+
+  return router;
+}
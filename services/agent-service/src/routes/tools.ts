@@ -0,0 +1,156 @@
+import express, { Request, Response } from 'express';
+import { body, param, validationResult } from 'express-validator';
+import { ToolRegistry } from '../tools/ToolRegistry.js';
+import { requireAdminOrService, AuthenticatedRequest } from '../middleware/auth.js';
+
+const router = express.Router();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const serverNameParam = param('name').matches(/^[a-z][a-z0-9_-]*$/).withMessage('Invalid tool server name');
+
+const callerOf = (req: AuthenticatedRequest) => req.service ? 'service' : `user:${req.user!._id}`;
+
+// A registered server can only be replaced, refreshed or removed by whoever registered it, or by an admin
+const mayManage = (req: AuthenticatedRequest, tools: ToolRegistry, name: string) => {
+  const owner = tools.ownerOf(name);
+  return !owner || req.user?.role === 'admin' || owner === callerOf(req);
+};
+
+export default function createToolRoutes(tools: ToolRegistry) {
+  // GET /api/tools - Tools agents can call, with the scopes each requires
+  router.get('/', (req: Request, res: Response) => {
+    res.json({
+      success: true,
+      data: tools.list()
+    });
+  });
+
+  // GET /api/tools/servers - Registered tool servers
+  router.get('/servers', (req: Request, res: Response) => {
+    res.json({
+      success: true,
+      data: tools.listServers()
+    });
+  });
+
+  // POST /api/tools/servers - Register (or re-register) a gRPC tool server or an MCP server and discover its tools.
+  // Admins and platform services only. Tools of an MCP server always require mcp:<name>; callers cannot pick
+  // the scopes that gate their own tools.
+  router.post('/servers',
+    requireAdminOrService,
+    [
+      body('name').matches(/^[a-z][a-z0-9_-]*$/).withMessage('Name must be a lowercase identifier'),
+      body('protocol').optional().isIn(['grpc', 'mcp']).withMessage('Protocol must be grpc or mcp'),
+      body('address').if(body('protocol').not().equals('mcp')).matches(/^[\w.-]+:\d+$/).withMessage('Address must be host:port'),
+      body('tls').optional().isBoolean().withMessage('tls must be a boolean'),
+      body('url').if(body('protocol').equals('mcp'))
+        .isURL({ protocols: ['http', 'https'], require_tld: false, require_protocol: true }).withMessage('MCP servers need an http(s) url'),
+      body('headers').optional().isObject().withMessage('Headers must be an object'),
+      body('headers.*').optional().isString().withMessage('Header values must be strings')
+    ],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const { name, protocol = 'grpc', address, tls, url, headers } = req.body;
+        if (!mayManage(req, tools, name)) {
+          return res.status(409).json({
+            success: false,
+            error: `Tool server ${name} is already registered`
+          });
+        }
+
+        const discovered = await tools.register(protocol === 'mcp'
+          ? { name, protocol, url, headers }
+          : { name, protocol, address, tls }, callerOf(req));
+
+        res.status(201).json({
+          success: true,
+          data: { name, protocol, ...(protocol === 'mcp' ? { url } : { address }), tools: discovered }
+        });
+      } catch (error) {
+        console.error('Tool server registration error:', error);
+        res.status(502).json({
+          success: false,
+          error: error instanceof Error ? error.message : 'Failed to register tool server'
+        });
+      }
+    }
+  );
+
+  // POST /api/tools/servers/:name/refresh - Re-discover a server's tools
+  router.post('/servers/:name/refresh',
+    requireAdminOrService,
+    [serverNameParam],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        if (!mayManage(req, tools, req.params.name)) {
+          return res.status(403).json({
+            success: false,
+            error: 'Only the server\'s owner or an admin can refresh it'
+          });
+        }
+
+        const discovered = await tools.refresh(req.params.name);
+        if (!discovered) {
+          return res.status(404).json({
+            success: false,
+            error: 'Tool server not found'
+          });
+        }
+
+        res.json({
+          success: true,
+          data: discovered
+        });
+      } catch (error) {
+        console.error('Tool server refresh error:', error);
+        res.status(502).json({
+          success: false,
+          error: error instanceof Error ? error.message : 'Failed to refresh tool server'
+        });
+      }
+    }
+  );
+
+  // DELETE /api/tools/servers/:name - Stop offering a server's tools
+  router.delete('/servers/:name',
+    requireAdminOrService,
+    [serverNameParam],
+    validateRequest,
+    (req: AuthenticatedRequest, res: Response) => {
+      if (!mayManage(req, tools, req.params.name)) {
+        return res.status(403).json({
+          success: false,
+          error: 'Only the server\'s owner or an admin can remove it'
+        });
+      }
+
+      if (!tools.unregister(req.params.name)) {
+        return res.status(404).json({
+          success: false,
+          error: 'Tool server not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        message: 'Tool server removed'
+      });
+    }
+  );
+
+  return router;
+}
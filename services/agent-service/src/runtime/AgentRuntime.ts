@@ -0,0 +1,344 @@
+import {
+  AgentRoleConfig,
+  ChatCompletionResponse,
+  ChatMessage,
+  ExecuteStageRequest,
+  ExecuteStageResponse,
+  LLMUsage,
+  ToolCallRecord
+} from '../types/index.js';
+import { RoleRegistry } from './RoleRegistry.js';
+import { ContextAssembler } from './ContextAssembler.js';
+import { LLMClient } from './LLMClient.js';
+import { PromptClient } from './PromptClient.js';
+import { TemplateClient, TemplateReference } from './TemplateClient.js';
+import { ConversationClient, RecalledMemory } from './ConversationClient.js';
+import { ModerationClient } from './ModerationClient.js';
+import { renderTemplate } from './PromptRenderer.js';
+import { assertValidSchema, checkOutput, CheckedOutput, repairPrompt } from './OutputValidator.js';
+import { ToolRegistry, ToolAccessError } from '../tools/ToolRegistry.js';
+import { toolInstructions, parseToolCall, toolResultMessage } from '../tools/ToolProtocol.js';
+
+const sumUsage = (usages: LLMUsage[]): LLMUsage => usages.reduce((sum, usage) => ({
+  promptTokens: sum.promptTokens + (usage?.promptTokens || 0),
+  completionTokens: sum.completionTokens + (usage?.completionTokens || 0),
+  totalTokens: sum.totalTokens + (usage?.totalTokens || 0),
+  ...(sum.costUsd !== undefined || usage?.costUsd !== undefined ? { costUsd: (sum.costUsd || 0) + (usage?.costUsd || 0) } : {})
+}), { promptTokens: 0, completionTokens: 0, totalTokens: 0 } as LLMUsage);
+
+// Recalled summaries join the system prompt, so they are not summarized again from this stage's
+// transcript; they come from project data and are screened on their own
+function memoryContext(memories: RecalledMemory[]): string {
+  const entries = memories.map(memory => `### ${memory.agentRole || memory.stageId} (stage ${memory.stageId})\n${memory.summary}`);
+  return [
+    '## Decisions from earlier stages of this project',
+    'Stay consistent with these unless the request says otherwise.',
+    ...entries
+  ].join('\n\n');
+}
+
+export interface ExecuteOptions {
+  // Receives the agent's output as the model generates it
+  onToken?: (text: string) => void;
+  // Aborted when the caller goes away, e.g. the run was cancelled; the LLM call is cancelled with it
+  signal?: AbortSignal;
+}
+
+export class AgentRuntime {
+  private contextAssembler = new ContextAssembler();
+
+  constructor(
+    private roles: RoleRegistry,
+    private llm: LLMClient,
+    private tools: ToolRegistry = new ToolRegistry(),
+    private prompts: PromptClient = new PromptClient(),
+    private templates: TemplateClient = new TemplateClient(),
+    private conversations: ConversationClient = new ConversationClient(),
+    private moderation: ModerationClient = new ModerationClient()
+  ) {}
+
+  async executeStage(roleId: string, request: ExecuteStageRequest, options: ExecuteOptions = {}): Promise<ExecuteStageResponse> {
+    const role = this.roles.get(roleId);
+    if (!role) {
+      throw new Error(`Unknown agent role: ${roleId}`);
+    }
+
+    const missingInputs = role.requiredInputs.filter(input => request.inputs[input] === undefined);
+    if (missingInputs.length > 0) {
+      throw new Error(`Missing required inputs for ${role.id}: ${missingInputs.join(', ')}`);
+    }
+
+    const outputSchema = request.outputSchema ?? role.outputSchema;
+    const maxRepairs = request.outputRepairs ?? role.outputRepairs ?? parseInt(process.env.AGENT_OUTPUT_REPAIRS || '2');
+    // Checked before any tokens are spent on a stage that could never pass
+    if (outputSchema) assertValidSchema(outputSchema);
+
+    const startTime = Date.now();
+    const files = await this.composeFiles(request);
+    const variables = this.contextAssembler.assemble(role, { ...request, files });
+    const { messages, prompt } = await this.buildMessages(role, variables, request.promptVersion);
+    await this.screenPrompt(messages, request);
+    const memories = await this.recall(role, request);
+    if (memories.length > 0) {
+      const [context] = await this.moderation.screen('prompt', [memoryContext(memories)], request);
+      if (messages[0]?.role === 'system') messages[0].content += `\n\n${context}`;
+      else messages.unshift({ role: 'system', content: context });
+    }
+
+    const model = request.modelOverride || role.model;
+    const transcript = {
+      runId: request.runId,
+      stageId: request.stageId,
+      projectId: request.projectId,
+      agentRole: role.id,
+      model
+    };
+
+    const tools = this.tools.availableTo(role, request.toolScopes);
+    if (tools.length > 0) {
+      const instructions = toolInstructions(tools);
+      if (messages[0]?.role === 'system') messages[0].content += `\n\n${instructions}`;
+      else messages.unshift({ role: 'system', content: instructions });
+    }
+
+    let completion: ChatCompletionResponse;
+    let checked: CheckedOutput;
+    let repairs = 0;
+    const usages: LLMUsage[] = [];
+    const toolCalls: ToolCallRecord[] = [];
+    try {
+      completion = await this.respond(
+        () => this.converse(role, request, model, messages, usages, toolCalls, tools.length > 0, options),
+        request
+      );
+      checked = checkOutput(role.outputFormat, completion.content, outputSchema);
+
+      // The model is shown what was wrong and asked again; tools are off and nothing is streamed,
+      // since the stage's live output has already been sent
+      while (checked.errors.length > 0 && repairs < maxRepairs) {
+        repairs++;
+        messages.push(
+          { role: 'assistant', content: completion.content },
+          { role: 'user', content: repairPrompt(checked.errors, outputSchema) }
+        );
+        completion = await this.respond(
+          () => this.converse(role, request, model, messages, usages, toolCalls, false, { signal: options.signal }),
+          request
+        );
+        checked = checkOutput(role.outputFormat, completion.content, outputSchema);
+      }
+    } catch (error) {
+      this.conversations.record({
+        ...transcript,
+        status: 'failed',
+        messages,
+        error: error instanceof Error ? error.message : 'LLM call failed',
+        usage: usages.length > 0 ? sumUsage(usages) : undefined,
+        durationMs: Date.now() - startTime
+      });
+      throw error;
+    }
+
+    const response: ExecuteStageResponse = {
+      runId: request.runId,
+      stageId: request.stageId,
+      role: role.id,
+      status: 'completed',
+      output: checked.errors.length === 0 ? checked.output : null,
+      rawOutput: completion.content,
+      model: completion.model,
+      usage: sumUsage(usages),
+      durationMs: 0,
+      ...(repairs > 0 ? { repairs } : {}),
+      ...(toolCalls.length > 0 ? { toolCalls } : {}),
+      ...(prompt ? { prompt } : {}),
+      ...(memories.length > 0
+        ? { memories: memories.map(memory => ({ runId: memory.runId, stageId: memory.stageId, score: memory.score })) }
+        : {})
+    };
+
+    if (checked.errors.length > 0) {
+      response.status = 'failed';
+      response.errorType = 'output_invalid';
+      response.validationErrors = checked.errors;
+      response.error = `Agent output failed validation after ${repairs} repair(s): ${checked.errors.join('; ')}`;
+    }
+
+    response.durationMs = Date.now() - startTime;
+
+    this.conversations.record({
+      ...transcript,
+      model: completion.model,
+      status: response.status,
+      messages: [...messages, { role: 'assistant', content: completion.content }],
+      error: response.error,
+      usage: response.usage,
+      durationMs: response.durationMs
+    });
+
+    return response;
+  }
+
+  // Screened before it is parsed, returned or persisted; streamed tokens have already gone out
+  private async respond(
+    complete: () => Promise<ChatCompletionResponse>,
+    request: ExecuteStageRequest
+  ): Promise<ChatCompletionResponse> {
+    const completion = await complete();
+    const [content] = await this.moderation.screen('output', [completion.content], request);
+    return { ...completion, content };
+  }
+
+  // What earlier stages of the project decided that bears on this one, looked up by the role and
+  // the stage's inputs. Only stages of a project have memory to draw on.
+  private async recall(role: AgentRoleConfig, request: ExecuteStageRequest): Promise<RecalledMemory[]> {
+    const limit = role.memoryLimit ?? parseInt(process.env.AGENT_MEMORY_LIMIT || '5');
+    if (!request.projectId || request.memory === false || limit === 0) return [];
+
+    const query = `${role.name}: ${role.description}\n\n${JSON.stringify(request.inputs).slice(0, 4000)}`;
+    return this.conversations.recall(request.projectId, query, limit, request);
+  }
+
+  // The conversation carries the project description, inputs, upstream outputs and files; the
+  // role's own system prompt is platform-authored and is not screened
+  private async screenPrompt(messages: ChatMessage[], request: ExecuteStageRequest): Promise<void> {
+    const screened = messages.filter(message => message.role !== 'system');
+    const contents = await this.moderation.screen('prompt', screened.map(message => message.content), request);
+    screened.forEach((message, index) => {
+      message.content = contents[index];
+    });
+  }
+
+  // Chats until the model gives a final answer. Each tool call it makes is checked against the
+  // role's and stage's scopes, run on its tool server and answered with the result; after maxToolCalls the
+  // model is told to answer without tools. Messages, usage and tool calls accumulate in place.
+  private async converse(
+    role: AgentRoleConfig,
+    request: ExecuteStageRequest,
+    model: string,
+    messages: ChatMessage[],
+    usages: LLMUsage[],
+    toolCalls: ToolCallRecord[],
+    toolsEnabled: boolean,
+    options: ExecuteOptions
+  ): Promise<ChatCompletionResponse> {
+    const maxToolCalls = role.maxToolCalls ?? parseInt(process.env.AGENT_MAX_TOOL_CALLS || '8');
+
+    while (true) {
+      if (options.signal?.aborted) {
+        throw new Error('Stage cancelled');
+      }
+
+      const chatRequest = {
+        model,
+        messages,
+        temperature: role.temperature,
+        maxTokens: role.maxOutputTokens,
+        metadata: {
+          runId: request.runId,
+          stageId: request.stageId,
+          role: role.id,
+          ...(request.projectId ? { projectId: request.projectId } : {})
+        }
+      };
+      const completion = options.onToken
+        ? await this.llm.streamChat(chatRequest, options.onToken, options.signal)
+        : await this.llm.chat(chatRequest, options.signal);
+      usages.push(completion.usage);
+
+      const call = toolsEnabled ? parseToolCall(completion.content) : undefined;
+      if (!call) return completion;
+
+      messages.push({ role: 'assistant', content: completion.content });
+
+      if ('invalid' in call) {
+        messages.push({ role: 'user', content: toolResultMessage('invalid', { error: `Invalid tool call: ${call.invalid}` }) });
+        continue;
+      }
+      if (toolCalls.length >= maxToolCalls) {
+        toolsEnabled = false;
+        messages.push({
+          role: 'user',
+          content: toolResultMessage(call.name, { error: `Tool call limit (${maxToolCalls}) reached; give your final answer now without tools` })
+        });
+        continue;
+      }
+
+      const startTime = Date.now();
+      const record: ToolCallRecord = { tool: call.name, arguments: call.arguments, status: 'completed', durationMs: 0 };
+      let result: { output?: string; error?: string };
+      try {
+        result = {
+          output: await this.tools.invoke(role, call.name, call.arguments, {
+            runId: request.runId,
+            stageId: request.stageId,
+            projectId: request.projectId
+          }, request.toolScopes)
+        };
+      } catch (error) {
+        record.status = error instanceof ToolAccessError ? 'denied' : 'failed';
+        record.error = error instanceof Error ? error.message : 'Tool call failed';
+        result = { error: record.error };
+      }
+      record.durationMs = Date.now() - startTime;
+      toolCalls.push(record);
+
+      messages.push({ role: 'user', content: toolResultMessage(call.name, result) });
+    }
+  }
+
+  // A starter template named in the inputs is rendered underneath the project's own files
+  private async composeFiles(request: ExecuteStageRequest): Promise<Record<string, string> | undefined> {
+    const template = request.inputs.template;
+    if (!template) return request.files;
+
+    if (!this.templates.isConfigured()) {
+      throw new Error('Stage requests a starter template but TEMPLATE_SERVICE_URL is not configured');
+    }
+
+    const reference: TemplateReference = typeof template === 'string'
+      ? { name: template, parameters: { projectName: request.inputs.projectName } }
+      : template;
+    const skeleton = await this.templates.render(reference);
+
+    return { ...skeleton, ...request.files };
+  }
+
+  // A pinned promptVersion must be the one that runs, so it never falls back to the bundled template
+  private async buildMessages(
+    role: AgentRoleConfig,
+    variables: Record<string, any>,
+    promptVersion?: number
+  ): Promise<{ messages: ChatMessage[]; prompt?: { name: string; version: number } }> {
+    let systemPrompt = role.systemPrompt ? renderTemplate(role.systemPrompt, variables) : undefined;
+    let prompt: string | undefined;
+    let used: { name: string; version: number } | undefined;
+
+    if (promptVersion !== undefined && (!role.promptName || !this.prompts.isConfigured())) {
+      throw new Error(`Cannot run prompt version ${promptVersion} for ${role.id}: ${role.promptName
+        ? 'PROMPT_SERVICE_URL is not configured'
+        : 'the role has no managed prompt'}`);
+    }
+
+    if (role.promptName && this.prompts.isConfigured()) {
+      try {
+        const rendered = await this.prompts.render(role.promptName, variables, promptVersion);
+        systemPrompt = rendered.systemPrompt ?? systemPrompt;
+        prompt = rendered.prompt;
+        used = { name: rendered.name, version: rendered.version };
+      } catch (error) {
+        if (promptVersion !== undefined) throw error;
+        // Keep stages running on the bundled template if the prompt service is unreachable
+        console.error(`Falling back to bundled prompt for ${role.id}:`, error);
+      }
+    }
+
+    const messages: ChatMessage[] = [];
+    if (systemPrompt) {
+      messages.push({ role: 'system', content: systemPrompt });
+    }
+    messages.push({ role: 'user', content: prompt ?? renderTemplate(role.promptTemplate, variables) });
+
+    return { messages, prompt: used };
+  }
+}
@@ -0,0 +1,73 @@
+import { allocateBudget, countTokens } from '@ai-pipeline/tokens';
+import { AgentRoleConfig, ExecuteStageRequest } from '../types/index.js';
+
+const DEFAULT_MAX_CONTEXT_TOKENS = 16000;
+// Rough conversion for roles that still set a character budget
+const CHARS_PER_TOKEN = 4;
+
+export class ContextAssembler {
+  // Builds the template variables for a stage from its inputs, upstream outputs and project files.
+  // Upstream outputs and files share the role's token budget, counted for the model the stage runs
+  // on; whatever one of them does not need is left to the other.
+  assemble(role: AgentRoleConfig, request: ExecuteStageRequest): Record<string, any> {
+    const model = request.modelOverride || role.model;
+    const budget = this.tokenBudget(role);
+    const files = request.files || {};
+
+    const allocation = allocateBudget([
+      { name: 'previousOutputs', text: this.formatPreviousOutputs(request.previousOutputs || {}), strategy: 'head' },
+      { name: 'files', text: this.formatFiles(files) }
+    ], budget, model);
+
+    return {
+      ...request.inputs,
+      inputs: request.inputs,
+      runId: request.runId,
+      stageId: request.stageId,
+      projectId: request.projectId || '',
+      previousOutputs: allocation.previousOutputs.text,
+      files: allocation.files.truncated
+        ? this.packFiles(files, budget - allocation.previousOutputs.tokens, model)
+        : allocation.files.text
+    };
+  }
+
+  private tokenBudget(role: AgentRoleConfig): number {
+    if (role.maxContextTokens) return role.maxContextTokens;
+    if (role.maxContextChars) return Math.floor(role.maxContextChars / CHARS_PER_TOKEN);
+    return DEFAULT_MAX_CONTEXT_TOKENS;
+  }
+
+  private formatPreviousOutputs(outputs: Record<string, any>): string {
+    return Object.entries(outputs).map(([stageId, output]) => {
+      const body = typeof output === 'string' ? output : JSON.stringify(output, null, 2);
+      return `### ${stageId}\n${body}`;
+    }).join('\n\n');
+  }
+
+  private formatFiles(files: Record<string, string>): string {
+    return Object.entries(files)
+      .map(([filePath, content]) => `--- ${filePath} ---\n${content}`)
+      .join('\n\n');
+  }
+
+  // Whole files in order while they fit; a file cut in half is worse than none
+  private packFiles(files: Record<string, string>, budget: number, model: string): string {
+    const sections: string[] = [];
+    let used = 0;
+
+    for (const [filePath, content] of Object.entries(files)) {
+      const section = `--- ${filePath} ---\n${content}`;
+      const tokens = countTokens(section, model);
+      if (used + tokens > budget) {
+        // Keep the file list complete even when contents no longer fit
+        sections.push(`--- ${filePath} --- (content omitted)`);
+        continue;
+      }
+      sections.push(section);
+      used += tokens;
+    }
+
+    return sections.join('\n\n');
+  }
+}
@@ -0,0 +1,75 @@
+import axios from 'axios';
+import { ChatMessage, LLMUsage } from '../types/index.js';
+
+export interface StageTranscript {
+  runId: string;
+  stageId: string;
+  projectId?: string;
+  agentRole: string;
+  model?: string;
+  status: 'completed' | 'failed';
+  messages: ChatMessage[];
+  error?: string;
+  usage?: LLMUsage;
+  durationMs?: number;
+}
+
+export interface RecalledMemory {
+  runId: string;
+  stageId: string;
+  agentRole?: string;
+  summary: string;
+  score: number;
+}
+
+// Sends each stage's prompt and reply to the conversation service and asks it what earlier stages
+// of the project decided; neither recording nor recall ever fails a stage
+export class ConversationClient {
+  private baseUrl: string | undefined;
+
+  constructor(baseUrl?: string) {
+    this.baseUrl = baseUrl || process.env.CONVERSATION_SERVICE_URL;
+  }
+
+  isConfigured(): boolean {
+    return !!this.baseUrl;
+  }
+
+  record(transcript: StageTranscript): void {
+    if (!this.baseUrl) return;
+
+    axios.post(`${this.baseUrl}/api/conversations/transcripts`, transcript, {
+      headers: { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' },
+      timeout: 10000
+    })
+      .catch(error => console.error(`Failed to record transcript for ${transcript.runId}/${transcript.stageId}:`, error.message));
+  }
+
+  // Summaries of the project's earlier stages closest to the query, best first; empty when the
+  // service is unset or does not answer in time
+  async recall(
+    projectId: string,
+    query: string,
+    limit: number,
+    stage: { runId: string; stageId: string }
+  ): Promise<RecalledMemory[]> {
+    if (!this.baseUrl) return [];
+
+    try {
+      const response = await axios.post(`${this.baseUrl}/api/conversations/memory/search`, {
+        projectId,
+        query,
+        limit: Math.min(limit, 20),
+        runId: stage.runId,
+        stageId: stage.stageId
+      }, {
+        headers: { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' },
+        timeout: parseInt(process.env.AGENT_MEMORY_TIMEOUT_MS || '15000')
+      });
+      return response.data.data || [];
+    } catch (error) {
+      console.error(`Failed to recall memory for ${stage.runId}/${stage.stageId}:`, error instanceof Error ? error.message : error);
+      return [];
+    }
+  }
+}
@@ -0,0 +1,116 @@
+import * as path from 'path';
+import axios from 'axios';
+import * as grpc from '@grpc/grpc-js';
+import * as protoLoader from '@grpc/proto-loader';
+import { ChatCompletionRequest, ChatCompletionResponse } from '../types/index.js';
+
+const PROTO_PATH = process.env.LLM_PROTO_PATH || path.join(process.cwd(), 'proto', 'llm_gateway.proto');
+
+export type DeltaHandler = (text: string) => void;
+
+export class LLMClient {
+  private baseUrl: string;
+  private timeout: number;
+  // host:port of the gateway's gRPC server; without it streamChat falls back to HTTP
+  private grpcAddress?: string;
+  private grpcClient?: any;
+
+  constructor(baseUrl?: string, timeout?: number, grpcAddress?: string) {
+    this.baseUrl = baseUrl || process.env.LLM_GATEWAY_URL || 'http://localhost:3006';
+    this.timeout = timeout || parseInt(process.env.LLM_TIMEOUT_MS || '120000');
+    this.grpcAddress = grpcAddress || process.env.LLM_GATEWAY_GRPC_ADDRESS || undefined;
+  }
+
+  async chat(request: ChatCompletionRequest, signal?: AbortSignal): Promise<ChatCompletionResponse> {
+    try {
+      const response = await axios.post(`${this.baseUrl}/api/llm/chat`, request, {
+        timeout: this.timeout,
+        signal
+      });
+
+      if (!response.data.success) {
+        throw new Error(response.data.error || 'LLM gateway request failed');
+      }
+
+      return response.data.data;
+    } catch (error) {
+      if (axios.isAxiosError(error) && error.response?.data?.error) {
+        throw new Error(`LLM gateway error: ${error.response.data.error}`);
+      }
+      throw new Error(`LLM gateway error: ${error instanceof Error ? error.message : 'Unknown error'}`);
+    }
+  }
+
+  // Streams the completion through the gateway's StreamChat RPC, passing text to onDelta as it is
+  // generated. Aborting the signal cancels the call, which cancels the provider request upstream.
+  // Without a gRPC address the completion is fetched over HTTP and handed over as a single delta.
+  async streamChat(request: ChatCompletionRequest, onDelta: DeltaHandler, signal?: AbortSignal): Promise<ChatCompletionResponse> {
+    if (!this.grpcAddress) {
+      const completion = await this.chat(request, signal);
+      if (completion.content) onDelta(completion.content);
+      return completion;
+    }
+
+    signal?.throwIfAborted();
+
+    return new Promise((resolve, reject) => {
+      let completion: ChatCompletionResponse | undefined;
+      let content = '';
+      const call = this.client().StreamChat({
+        model: request.model,
+        messages: request.messages,
+        temperature: request.temperature,
+        maxTokens: request.maxTokens,
+        metadata: request.metadata || {}
+      }, { deadline: Date.now() + this.timeout });
+
+      const onAbort = () => call.cancel();
+      signal?.addEventListener('abort', onAbort, { once: true });
+      const settle = () => signal?.removeEventListener('abort', onAbort);
+
+      call.on('data', (chunk: any) => {
+        if (chunk.chunk === 'delta') {
+          content += chunk.delta;
+          onDelta(chunk.delta);
+        } else if (chunk.chunk === 'done') {
+          const done = chunk.done;
+          completion = {
+            id: done.id,
+            provider: done.provider,
+            model: done.model,
+            // Filled in from the deltas, so it matches exactly what was streamed
+            content: '',
+            finishReason: done.finishReason,
+            usage: {
+              promptTokens: done.usage?.promptTokens || 0,
+              completionTokens: done.usage?.completionTokens || 0,
+              totalTokens: done.usage?.totalTokens || 0,
+              costUsd: done.usage?.costUsd || 0
+            }
+          };
+        }
+      });
+      call.on('end', () => {
+        settle();
+        if (completion) resolve({ ...completion, content });
+        else reject(new Error('LLM gateway error: stream ended without a completion'));
+      });
+      call.on('error', (error: grpc.ServiceError) => {
+        settle();
+        reject(new Error(error.code === grpc.status.CANCELLED && signal?.aborted
+          ? 'LLM call cancelled'
+          : `LLM gateway error: ${error.details || error.message}`));
+      });
+    });
+  }
+
+  // The proto is loaded on first use so HTTP-only setups do not need it
+  private client(): any {
+    if (!this.grpcClient) {
+      const definition = protoLoader.loadSync(PROTO_PATH, { keepCase: false, longs: Number, defaults: true, oneofs: true });
+      const proto = grpc.loadPackageDefinition(definition) as any;
+      this.grpcClient = new proto.aipipeline.llm.v1.LLMGateway(this.grpcAddress, grpc.credentials.createInsecure());
+    }
+    return this.grpcClient;
+  }
+}
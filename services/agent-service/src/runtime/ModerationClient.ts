@@ -0,0 +1,69 @@
+import axios from 'axios';
+
+export interface ModerationContext {
+  runId: string;
+  stageId: string;
+  projectId?: string;
+}
+
+export class ModerationBlockedError extends Error {
+  constructor(message: string, public recordId?: string) {
+    super(message);
+    this.name = 'ModerationBlockedError';
+  }
+}
+
+// Screens stage prompts and agent output with the moderation service. Redactions come back in
+// place of the original text and blocked content raises ModerationBlockedError. If the service
+// cannot be reached the stage fails, unless MODERATION_FAIL_OPEN lets the text through unscreened.
+export class ModerationClient {
+  private baseUrl: string | undefined;
+  private failOpen: boolean;
+
+  constructor(baseUrl?: string) {
+    this.baseUrl = baseUrl || process.env.MODERATION_SERVICE_URL;
+    this.failOpen = process.env.MODERATION_FAIL_OPEN === 'true';
+  }
+
+  isConfigured(): boolean {
+    return !!this.baseUrl;
+  }
+
+  // Returns the texts as they may be used, in the same order
+  async screen(source: 'prompt' | 'output', texts: string[], context: ModerationContext): Promise<string[]> {
+    if (!this.baseUrl || texts.length === 0) return texts;
+
+    let result: any;
+    try {
+      const response = await axios.post(`${this.baseUrl}/api/moderation/checks`, {
+        source,
+        items: texts.map((content, index) => ({ name: `${source}-${index + 1}`, content })),
+        runId: context.runId,
+        stageId: context.stageId,
+        projectId: context.projectId
+      }, {
+        headers: { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' },
+        timeout: parseInt(process.env.MODERATION_TIMEOUT_MS || '10000')
+      });
+      result = response.data.data;
+    } catch (error) {
+      if (this.failOpen) {
+        console.error(`Moderation unavailable, ${source} for ${context.runId}/${context.stageId} not screened:`, error instanceof Error ? error.message : error);
+        return texts;
+      }
+      throw new Error(`Moderation check failed: ${axios.isAxiosError(error) ? error.response?.data?.error || error.message : 'unknown error'}`);
+    }
+
+    if (result.decision === 'blocked') {
+      const rules = Array.from(new Set(result.findings
+        .filter((finding: any) => finding.action === 'block')
+        .map((finding: any) => finding.rule)));
+      throw new ModerationBlockedError(
+        `Agent ${source} blocked by moderation policy ${result.policy.name} (${rules.join(', ')}); see audit record ${result.recordId}`,
+        result.recordId
+      );
+    }
+
+    return result.items.map((item: { content: string }) => item.content);
+  }
+}
@@ -0,0 +1,58 @@
+import { GeneratedFile, OutputFormat } from '../types/index.js';
+
+export class OutputParseError extends Error {
+  constructor(message: string, public rawOutput: string) {
+    super(message);
+    this.name = 'OutputParseError';
+  }
+}
+
+export function parseOutput(format: OutputFormat, raw: string): any {
+  switch (format) {
+    case 'json':
+      return extractJson(raw);
+    case 'files':
+      return extractFiles(raw);
+    default:
+      return raw.trim();
+  }
+}
+
+export function extractJson(raw: string): any {
+  const fenced = raw.match(/```(?:json)?\s*([\s\S]*?)```/);
+  const candidate = fenced ? fenced[1] : raw;
+
+  try {
+    return JSON.parse(candidate.trim());
+  } catch {
+    // Fall back to the outermost object or array in the response
+    const start = candidate.search(/[{[]/);
+    const end = Math.max(candidate.lastIndexOf('}'), candidate.lastIndexOf(']'));
+    if (start !== -1 && end > start) {
+      try {
+        return JSON.parse(candidate.slice(start, end + 1));
+      } catch {
+        // Reported below
+      }
+    }
+  }
+
+  throw new OutputParseError('Agent response is not valid JSON', raw);
+}
+
+function extractFiles(raw: string): { files: GeneratedFile[] } {
+  const parsed = extractJson(raw);
+  const files = Array.isArray(parsed) ? parsed : parsed.files;
+
+  if (!Array.isArray(files)) {
+    throw new OutputParseError('Agent response does not contain a files array', raw);
+  }
+
+  for (const file of files) {
+    if (typeof file?.path !== 'string' || typeof file?.content !== 'string') {
+      throw new OutputParseError('Each generated file must have a path and content', raw);
+    }
+  }
+
+  return { ...(Array.isArray(parsed) ? {} : parsed), files };
+}
@@ -0,0 +1,77 @@
+import Ajv, { ErrorObject, ValidateFunction } from 'ajv';
+import { OutputFormat } from '../types/index.js';
+import { OutputParseError, parseOutput } from './OutputParser.js';
+
+const ajv = new Ajv({ allErrors: true, strict: false });
+// Roles and stages reuse a handful of schemas, so compiled validators are kept by schema text
+const validators = new Map<string, ValidateFunction>();
+
+// At most this many errors are reported back to the model, which is plenty to steer a repair
+const MAX_REPORTED_ERRORS = 20;
+
+export class OutputSchemaError extends Error {
+  constructor(message: string) {
+    super(message);
+    this.name = 'OutputSchemaError';
+  }
+}
+
+export interface CheckedOutput {
+  output: any;
+  // Empty when the output parsed and matched the schema
+  errors: string[];
+}
+
+function validatorFor(schema: Record<string, any>): ValidateFunction {
+  const key = JSON.stringify(schema);
+  let validate = validators.get(key);
+  if (!validate) {
+    try {
+      validate = ajv.compile(schema);
+    } catch (error) {
+      throw new OutputSchemaError(`Invalid output schema: ${error instanceof Error ? error.message : 'cannot be compiled'}`);
+    }
+    validators.set(key, validate);
+  }
+  return validate;
+}
+
+function describe(error: ErrorObject): string {
+  const where = error.instancePath || '(root)';
+  if (error.keyword === 'additionalProperties') return `${where} has unexpected property "${error.params.additionalProperty}"`;
+  if (error.keyword === 'enum') return `${where} must be one of ${error.params.allowedValues.map((value: any) => JSON.stringify(value)).join(', ')}`;
+  return `${where} ${error.message}`;
+}
+
+// Throws OutputSchemaError when the schema itself is unusable, so a bad definition is not
+// mistaken for a bad answer
+export function assertValidSchema(schema: Record<string, any>): void {
+  validatorFor(schema);
+}
+
+// Parses the raw response for the role's format and checks it against the schema, if any
+export function checkOutput(format: OutputFormat, raw: string, schema?: Record<string, any>): CheckedOutput {
+  let output: any;
+  try {
+    output = parseOutput(format, raw);
+  } catch (error) {
+    if (error instanceof OutputParseError) return { output: undefined, errors: [error.message] };
+    throw error;
+  }
+
+  if (!schema) return { output, errors: [] };
+
+  const validate = validatorFor(schema);
+  if (validate(output)) return { output, errors: [] };
+  return { output, errors: (validate.errors || []).slice(0, MAX_REPORTED_ERRORS).map(describe) };
+}
+
+// Follow-up turn asking the model to correct its previous answer
+export function repairPrompt(errors: string[], schema?: Record<string, any>): string {
+  const problems = errors.map(error => `- ${error}`).join('\n');
+  const shape = schema
+    ? `\n\nThe answer must be JSON matching this schema:\n\`\`\`json\n${JSON.stringify(schema, null, 2)}\n\`\`\``
+    : '';
+  return `Your previous response could not be used:\n${problems}${shape}\n\n`
+    + 'Respond again with the complete, corrected answer only, with no commentary.';
+}
@@ -0,0 +1,39 @@
+import axios from 'axios';
+
+export interface RenderedPrompt {
+  name: string;
+  version: number;
+  systemPrompt?: string;
+  prompt: string;
+}
+
+export class PromptClient {
+  private baseUrl: string | undefined;
+
+  constructor(baseUrl?: string) {
+    this.baseUrl = baseUrl || process.env.PROMPT_SERVICE_URL;
+  }
+
+  isConfigured(): boolean {
+    return !!this.baseUrl;
+  }
+
+  // Renders the published version unless a version is given
+  async render(name: string, variables: Record<string, any>, version?: number): Promise<RenderedPrompt> {
+    try {
+      const response = await axios.post(`${this.baseUrl}/api/prompts/${encodeURIComponent(name)}/render`, {
+        variables,
+        ...(version ? { version } : {})
+      }, {
+        timeout: 10000
+      });
+
+      return response.data.data;
+    } catch (error) {
+      if (axios.isAxiosError(error) && error.response?.data?.error) {
+        throw new Error(`Prompt service error: ${error.response.data.error}`);
+      }
+      throw new Error(`Prompt service error: ${error instanceof Error ? error.message : 'Unknown error'}`);
+    }
+  }
+}
@@ -0,0 +1,27 @@
+// Renders {{variable}} placeholders, supporting dotted paths such as {{inputs.projectName}}
+export function renderTemplate(template: string, variables: Record<string, any>): string {
+  return template.replace(/\{\{\s*([\w.]+)\s*\}\}/g, (match, key: string) => {
+    const value = key.split('.').reduce<any>((current, part) => {
+      return current !== undefined && current !== null ? current[part] : undefined;
+    }, variables);
+
+    if (value === undefined || value === null) return '';
+    if (typeof value === 'string') return value;
+    return JSON.stringify(value, null, 2);
+  });
+}
+
+export function findMissingVariables(template: string, variables: Record<string, any>): string[] {
+  const missing: string[] = [];
+  const pattern = /\{\{\s*([\w.]+)\s*\}\}/g;
+  let match: RegExpExecArray | null;
+
+  while ((match = pattern.exec(template)) !== null) {
+    const root = match[1].split('.')[0];
+    if (variables[root] === undefined && !missing.includes(root)) {
+      missing.push(root);
+    }
+  }
+
+  return missing;
+}
@@ -0,0 +1,80 @@
+import * as path from 'path';
+import { promises as fs } from 'fs';
+import * as yaml from 'yaml';
+import { AgentRoleConfig } from '../types/index.js';
+import { assertValidSchema } from './OutputValidator.js';
+
+const DEFAULT_ROLE: Omit<AgentRoleConfig, 'id' | 'name' | 'promptTemplate'> = {
+  description: '',
+  model: 'gemini-1.5-pro-latest',
+  temperature: 0.2,
+  maxOutputTokens: 8192,
+  outputFormat: 'text',
+  requiredInputs: []
+};
+
+export class RoleRegistry {
+  private roles: Map<string, AgentRoleConfig> = new Map();
+
+  constructor(private rolesDir: string) {}
+
+  async load(): Promise<void> {
+    const entries = await fs.readdir(this.rolesDir);
+    const roles: Map<string, AgentRoleConfig> = new Map();
+
+    for (const entry of entries) {
+      if (!entry.endsWith('.yaml') && !entry.endsWith('.yml')) continue;
+
+      const raw = await fs.readFile(path.join(this.rolesDir, entry), 'utf-8');
+      const role = this.parseRole(yaml.parse(raw), entry);
+      roles.set(role.id, role);
+    }
+
+    this.roles = roles;
+  }
+
+  get(roleId: string): AgentRoleConfig | undefined {
+    return this.roles.get(roleId);
+  }
+
+  list(): AgentRoleConfig[] {
+    return Array.from(this.roles.values());
+  }
+
+  private parseRole(data: any, source: string): AgentRoleConfig {
+    if (!data || typeof data !== 'object') {
+      throw new Error(`Invalid role definition in ${source}`);
+    }
+    if (!data.id || !data.name || !data.promptTemplate) {
+      throw new Error(`Role definition ${source} must declare id, name and promptTemplate`);
+    }
+    if (data.outputFormat && !['json', 'text', 'files'].includes(data.outputFormat)) {
+      throw new Error(`Role ${data.id} has unsupported outputFormat "${data.outputFormat}"`);
+    }
+    if (data.toolScopes !== undefined && !(Array.isArray(data.toolScopes) && data.toolScopes.every((scope: any) => typeof scope === 'string'))) {
+      throw new Error(`Role ${data.id} toolScopes must be a list of scope names`);
+    }
+    if (data.outputSchema !== undefined) {
+      if (!data.outputSchema || typeof data.outputSchema !== 'object' || Array.isArray(data.outputSchema)) {
+        throw new Error(`Role ${data.id} outputSchema must be a JSON Schema object`);
+      }
+      try {
+        assertValidSchema(data.outputSchema);
+      } catch (error) {
+        throw new Error(`Role ${data.id} ${error instanceof Error ? error.message : 'has an invalid outputSchema'}`);
+      }
+    }
+    if (data.outputRepairs !== undefined && !(Number.isInteger(data.outputRepairs) && data.outputRepairs >= 0)) {
+      throw new Error(`Role ${data.id} outputRepairs must be a non-negative integer`);
+    }
+    if (data.memoryLimit !== undefined && !(Number.isInteger(data.memoryLimit) && data.memoryLimit >= 0)) {
+      throw new Error(`Role ${data.id} memoryLimit must be a non-negative integer`);
+    }
+
+    return {
+      ...DEFAULT_ROLE,
+      ...data,
+      requiredInputs: data.requiredInputs || []
+    };
+  }
+}
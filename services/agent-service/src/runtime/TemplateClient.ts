@@ -0,0 +1,40 @@
+import axios from 'axios';
+
+export interface TemplateReference {
+  name: string;
+  version?: number;
+  parameters?: Record<string, any>;
+}
+
+export class TemplateClient {
+  private baseUrl: string | undefined;
+
+  constructor(baseUrl?: string) {
+    this.baseUrl = baseUrl || process.env.TEMPLATE_SERVICE_URL;
+  }
+
+  isConfigured(): boolean {
+    return !!this.baseUrl;
+  }
+
+  // Renders a starter template into a path -> content map
+  async render(reference: TemplateReference): Promise<Record<string, string>> {
+    try {
+      const response = await axios.post(`${this.baseUrl}/api/templates/${encodeURIComponent(reference.name)}/render`, {
+        version: reference.version,
+        parameters: reference.parameters || {}
+      }, {
+        timeout: 10000
+      });
+
+      return Object.fromEntries(
+        response.data.data.files.map((file: { path: string; content: string }) => [file.path, file.content])
+      );
+    } catch (error) {
+      if (axios.isAxiosError(error) && error.response?.data?.error) {
+        throw new Error(`Template service error: ${error.response.data.error}`);
+      }
+      throw new Error(`Template service error: ${error instanceof Error ? error.message : 'Unknown error'}`);
+    }
+  }
+}
@@ -0,0 +1,129 @@
+import express from 'express';
+import cors from 'cors';
+import * as path from 'path';
+import { config } from 'dotenv';
+import winston from 'winston';
+import { RoleRegistry } from './runtime/RoleRegistry.js';
+import { LLMClient } from './runtime/LLMClient.js';
+import { AgentRuntime } from './runtime/AgentRuntime.js';
+import { ToolRegistry } from './tools/ToolRegistry.js';
+import createAgentRoutes from './routes/agents.js';
+import createToolRoutes from './routes/tools.js';
+import createMcpRoutes from './routes/mcp.js';
+import { PlatformClient } from './mcp/PlatformClient.js';
+
+// Load environment variables
+config();
+
+// Configure logger
+const logger = winston.createLogger({
+  level: process.env.LOG_LEVEL || 'info',
+  format: winston.format.combine(
+    winston.format.timestamp(),
+    winston.format.errors({ stack: true }),
+    winston.format.json()
+  ),
+  transports: [
+    new winston.transports.Console({
+      format: winston.format.simple()
+    })
+  ]
+});
+
+const app = express();
+
+// Middleware
+app.use(cors({
+  origin: process.env.FRONTEND_URL || "http://localhost:5173",
+  credentials: true
+}));
+
+app.use(express.json({ limit: '20mb' }));
+app.use(express.urlencoded({ extended: true }));
+
+// Request logging
+app.use((req, res, next) => {
+  logger.info(`${req.method} ${req.path}`, {
+    ip: req.ip,
+    userAgent: req.get('User-Agent')
+  });
+  next();
+});
+
+// Initialize agent runtime
+const roles = new RoleRegistry(process.env.AGENT_ROLES_DIR || path.join(process.cwd(), 'roles'));
+const tools = new ToolRegistry();
+const runtime = new AgentRuntime(roles, new LLMClient(), tools);
+
+// Health check endpoint
+app.get('/health', (req, res) => {
+  res.json({
+    status: 'ok',
+    service: 'agent-service',
+    timestamp: new Date().toISOString(),
+    version: '1.0.0',
+    roles: roles.list().map(role => role.id),
+    tools: tools.list().map(tool => tool.name)
+  });
+});
+
+// Routes
+app.use('/api/agents', createAgentRoutes(runtime, roles, tools));
+app.use('/api/tools', createToolRoutes(tools));
+app.use('/mcp', createMcpRoutes(new PlatformClient()));
+
+// Error handling middleware
+app.use((err: Error, req: express.Request, res: express.Response, next: express.NextFunction) => {
+  logger.error('Unhandled error:', err);
+  res.status(500).json({
+    success: false,
+    error: 'Internal server error'
+  });
+});
+
+// 404 handler
+app.use('*', (req, res) => {
+  res.status(404).json({
+    success: false,
+    error: 'Route not found'
+  });
+});
+
+const PORT = process.env.PORT || 3005;
+
+// The platform's own MCP server is offered to agents like any other, once it is listening.
+// Agents call it as the PROJECT_SERVICE_TOKEN account, so they read what that account can read.
+const registerPlatformTools = async () => {
+  if (process.env.PLATFORM_MCP_ENABLED === 'false') return;
+  if (!process.env.PROJECT_SERVICE_TOKEN) {
+    logger.warn('PROJECT_SERVICE_TOKEN is not set; platform MCP tools are not offered to agents');
+    return;
+  }
+  try {
+    await tools.register({
+      name: 'platform',
+      protocol: 'mcp',
+      url: process.env.PLATFORM_MCP_URL || `http://localhost:${PORT}/mcp`,
+      headers: { Authorization: `Bearer ${process.env.PROJECT_SERVICE_TOKEN}` },
+      scopes: ['platform:read']
+    });
+  } catch (error) {
+    logger.error('Failed to register platform MCP tools:', error);
+  }
+};
+
+roles.load()
+  .then(() => tools.loadFromEnv())
+  .then(() => tools.loadMcpFromEnv())
+  .then(() => {
+    app.listen(PORT, async () => {
+      logger.info(`🤖 Agent Service running on port ${PORT}`);
+      logger.info(`🧩 Loaded agent roles: ${roles.list().map(role => role.id).join(', ')}`);
+      await registerPlatformTools();
+      logger.info(`🔧 Agent tools: ${tools.list().map(tool => tool.name).join(', ') || 'none'}`);
+    });
+  })
+  .catch((error) => {
+    logger.error('❌ Failed to load agent roles:', error);
+    process.exit(1);
+  });
@@ -0,0 +1,92 @@
+import { Client } from '@modelcontextprotocol/sdk/client/index.js';
+import { StreamableHTTPClientTransport } from '@modelcontextprotocol/sdk/client/streamableHttp.js';
+import { ToolDefinition, ToolServerConfig } from '../types/index.js';
+import { InvokeContext, ToolClient } from './ToolServerClient.js';
+
+// MCP servers name their tools freely (search, fetch, ...), so their tools are offered to agents
+// as <server>.<tool> to keep names unique across servers
+export const mcpToolName = (server: string, tool: string) => `${server}.${tool}`;
+
+// Flattens MCP tool content into the text the agent sees; non-text parts are only named
+export function contentText(content: any[] = []): string {
+  return content.map(part => {
+    if (part?.type === 'text') return part.text;
+    if (part?.type === 'resource' && typeof part.resource?.text === 'string') return part.resource.text;
+    if (part?.type === 'resource_link') return `[resource ${part.uri}]`;
+    return `[${part?.type || 'unknown'} content omitted]`;
+  }).join('\n');
+}
+
+// MCP client (streamable HTTP) for one MCP server. The session is opened on first use and
+// reopened after a failed connect, so a server that starts after the agents is picked up.
+export class McpToolClient implements ToolClient {
+  private connecting?: Promise<Client>;
+
+  constructor(private config: ToolServerConfig) {}
+
+  async listTools(timeoutMs = 5000): Promise<ToolDefinition[]> {
+    const client = await this.connect();
+    const scopes = this.config.scopes && this.config.scopes.length > 0 ? this.config.scopes : [`mcp:${this.config.name}`];
+
+    const tools: ToolDefinition[] = [];
+    let cursor: string | undefined;
+    do {
+      const page = await client.listTools(cursor ? { cursor } : {}, { timeout: timeoutMs });
+      tools.push(...page.tools.map(tool => ({
+        name: mcpToolName(this.config.name, tool.name),
+        description: tool.description || tool.title || tool.name,
+        inputSchema: tool.inputSchema,
+        scopes,
+        server: this.config.name
+      })));
+      cursor = page.nextCursor;
+    } while (cursor);
+
+    return tools;
+  }
+
+  async invoke(tool: string, args: any, context: InvokeContext, timeoutMs: number): Promise<{ output: string; error?: string }> {
+    const client = await this.connect();
+    const prefix = mcpToolName(this.config.name, '');
+    const name = tool.startsWith(prefix) ? tool.slice(prefix.length) : tool;
+
+    const result: any = await client.callTool({
+      name,
+      arguments: args ?? {},
+      // Lets servers that care attribute the call to a run
+      _meta: {
+        runId: context.runId,
+        stageId: context.stageId,
+        ...(context.projectId ? { projectId: context.projectId } : {}),
+        agentRole: context.agentRole
+      }
+    }, undefined, { timeout: timeoutMs });
+
+    const text = result.content
+      ? contentText(result.content)
+      : JSON.stringify(result.structuredContent ?? result.toolResult ?? '');
+    return result.isError ? { output: '', error: text || `${tool} failed` } : { output: text };
+  }
+
+  close(): void {
+    const connecting = this.connecting;
+    this.connecting = undefined;
+    connecting?.then(client => client.close()).catch(() => undefined);
+  }
+
+  private connect(): Promise<Client> {
+    if (!this.connecting) {
+      const client = new Client({ name: 'ai-pipeline-agent-service', version: '1.0.0' });
+      const transport = new StreamableHTTPClientTransport(new URL(this.config.url!), {
+        requestInit: { headers: this.config.headers }
+      });
+      this.connecting = client.connect(transport)
+        .then(() => client)
+        .catch(error => {
+          this.connecting = undefined;
+          throw new Error(`${this.config.name} MCP connect failed: ${error instanceof Error ? error.message : error}`);
+        });
+    }
+    return this.connecting;
+  }
+}
@@ -0,0 +1,52 @@
+import { ToolDefinition } from '../types/index.js';
+
+// Provider-neutral tool calling: the model asks for a tool with a <tool_call> block and gets the
+// result back as a <tool_result> message, so any chat model behind the LLM gateway can use tools.
+
+const TOOL_CALL = /<tool_call>\s*([\s\S]*?)\s*<\/tool_call>/;
+const MAX_RESULT_CHARS = parseInt(process.env.TOOL_RESULT_MAX_CHARS || '20000');
+
+export function toolInstructions(tools: ToolDefinition[]): string {
+  const catalog = tools.map(tool => [
+    `- ${tool.name}: ${tool.description}`,
+    tool.inputSchema ? `  arguments schema: ${JSON.stringify(tool.inputSchema)}` : undefined
+  ].filter(Boolean).join('\n')).join('\n');
+
+  return [
+    'You can call these tools before giving your final answer:',
+    catalog,
+    '',
+    'To call a tool, reply with only this block and nothing else:',
+    '<tool_call>{"name": "<tool name>", "arguments": { ... }}</tool_call>',
+    'The result comes back in a <tool_result> message. Call one tool at a time. When you have what you',
+    'need, reply with the final answer in the requested format and no <tool_call> block.'
+  ].join('\n');
+}
+
+export type ParsedToolCall = { name: string; arguments: any } | { invalid: string };
+
+// Returns undefined for a final answer
+export function parseToolCall(content: string): ParsedToolCall | undefined {
+  const match = content.match(TOOL_CALL);
+  if (!match) return undefined;
+
+  try {
+    const call = JSON.parse(match[1]);
+    if (typeof call?.name !== 'string') return { invalid: 'the call needs a "name"' };
+    return { name: call.name, arguments: call.arguments ?? {} };
+  } catch (error) {
+    return { invalid: `the call is not valid JSON (${error instanceof Error ? error.message : error})` };
+  }
+}
+
+export function toolResultMessage(name: string, result: { output?: string; error?: string }): string {
+  if (result.error !== undefined) {
+    return `<tool_result name="${name}" status="error">${result.error}</tool_result>`;
+  }
+
+  const output = result.output || '';
+  const truncated = output.length > MAX_RESULT_CHARS
+    ? `${output.slice(0, MAX_RESULT_CHARS)}\n[truncated ${output.length - MAX_RESULT_CHARS} characters]`
+    : output;
+  return `<tool_result name="${name}">${truncated}</tool_result>`;
+}
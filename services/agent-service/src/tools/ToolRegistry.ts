@@ -0,0 +1,167 @@
+import { AgentRoleConfig, ToolDefinition, ToolServerConfig } from '../types/index.js';
+import { InvokeContext, ToolClient, ToolServerClient } from './ToolServerClient.js';
+import { McpToolClient } from './McpToolClient.js';
+
+export class ToolAccessError extends Error {
+  constructor(message: string) {
+    super(message);
+    this.name = 'ToolAccessError';
+  }
+}
+
+interface RegisteredServer {
+  config: ToolServerConfig;
+  client: ToolClient;
+  tools: ToolDefinition[];
+  // Who registered the server through the API ('user:<id>' or 'service'); 'system' for startup config
+  owner: string;
+  registeredAt: Date;
+}
+
+// A granted scope covers the same scope, or every scope under it with a trailing wildcard
+// (repo:* covers repo:read); "*" covers everything
+export const scopeGranted = (granted: string[], required: string) =>
+  granted.some(scope => scope === '*' || scope === required || (scope.endsWith(':*') && required.startsWith(scope.slice(0, -1))));
+
+// A role's own scopes plus any the stage grants it
+export const grantedScopes = (role: AgentRoleConfig, stageScopes: string[] = []) =>
+  Array.from(new Set([...(role.toolScopes || []), ...stageScopes]));
+
+export const canUseTool = (scopes: string[], tool: ToolDefinition) =>
+  tool.scopes.every(scope => scopeGranted(scopes, scope));
+
+// Tool servers agents can call during a stage: gRPC tool plugins and MCP servers. Servers are
+// registered at startup from TOOL_SERVERS and MCP_SERVERS or at runtime through /api/tools/servers;
+// their tools are discovered with ListTools (gRPC) or tools/list (MCP).
+export class ToolRegistry {
+  private servers: Map<string, RegisteredServer> = new Map();
+  private invokeTimeoutMs = parseInt(process.env.TOOL_TIMEOUT_MS || '30000');
+
+  // TOOL_SERVERS=web=tools-web:50051,repo=tools-repo:50051
+  async loadFromEnv(value = process.env.TOOL_SERVERS || ''): Promise<void> {
+    const entries = value.split(',').map(entry => entry.trim()).filter(Boolean);
+    for (const entry of entries) {
+      const [name, address] = entry.split('=');
+      try {
+        await this.register({ name: name.trim(), address: address?.trim(), tls: process.env.TOOL_SERVERS_TLS === 'true' });
+      } catch (error) {
+        // A tool server that is not up yet should not keep the agents from starting
+        console.error(`Failed to register tool server ${name}:`, error instanceof Error ? error.message : error);
+      }
+    }
+  }
+
+  // MCP_SERVERS=github=https://mcp.example.com/mcp,docs=http://docs-mcp:8080/mcp; a bearer token for
+  // a server is read from MCP_TOKEN_<NAME> (GITHUB, DOCS, ...)
+  async loadMcpFromEnv(value = process.env.MCP_SERVERS || ''): Promise<void> {
+    const entries = value.split(',').map(entry => entry.trim()).filter(Boolean);
+    for (const entry of entries) {
+      const separator = entry.indexOf('=');
+      const name = entry.slice(0, separator).trim();
+      const url = entry.slice(separator + 1).trim();
+      const token = process.env[`MCP_TOKEN_${name.toUpperCase().replace(/-/g, '_')}`];
+      try {
+        await this.register({ name, protocol: 'mcp', url, ...(token ? { headers: { Authorization: `Bearer ${token}` } } : {}) });
+      } catch (error) {
+        console.error(`Failed to register MCP server ${name}:`, error instanceof Error ? error.message : error);
+      }
+    }
+  }
+
+  async register(config: ToolServerConfig, owner = 'system'): Promise<ToolDefinition[]> {
+    if (!config.name) {
+      throw new Error('Tool servers need a name');
+    }
+    if (config.protocol === 'mcp' ? !config.url : !config.address) {
+      throw new Error(config.protocol === 'mcp' ? 'MCP servers need a url' : 'gRPC tool servers need an address');
+    }
+
+    const client: ToolClient = config.protocol === 'mcp' ? new McpToolClient(config) : new ToolServerClient(config);
+    let tools: ToolDefinition[];
+    try {
+      tools = await client.listTools();
+    } catch (error) {
+      client.close();
+      throw error;
+    }
+
+    for (const tool of tools) {
+      const owner = this.findTool(tool.name);
+      if (owner && owner.server !== config.name) {
+        client.close();
+        throw new Error(`Tool ${tool.name} is already provided by ${owner.server}`);
+      }
+    }
+
+    this.servers.get(config.name)?.client.close();
+    this.servers.set(config.name, { config, client, tools, owner, registeredAt: new Date() });
+    return tools;
+  }
+
+  unregister(name: string): boolean {
+    const server = this.servers.get(name);
+    if (!server) return false;
+    server.client.close();
+    return this.servers.delete(name);
+  }
+
+  // Re-reads a server's tool list, e.g. after it was redeployed with new tools
+  async refresh(name: string): Promise<ToolDefinition[] | null> {
+    const server = this.servers.get(name);
+    return server ? this.register(server.config, server.owner) : null;
+  }
+
+  ownerOf(name: string): string | undefined {
+    return this.servers.get(name)?.owner;
+  }
+
+  listServers() {
+    return Array.from(this.servers.values()).map(({ config: { headers, ...config }, ...server }) => ({
+      ...config,
+      protocol: config.protocol || 'grpc',
+      tools: server.tools.map(tool => tool.name),
+      registeredAt: server.registeredAt
+    }));
+  }
+
+  list(): ToolDefinition[] {
+    return Array.from(this.servers.values()).flatMap(server => server.tools);
+  }
+
+  availableTo(role: AgentRoleConfig, stageScopes: string[] = []): ToolDefinition[] {
+    const scopes = grantedScopes(role, stageScopes);
+    return this.list().filter(tool => canUseTool(scopes, tool));
+  }
+
+  // Scopes are checked here rather than trusted from the prompt: the model may name any tool
+  async invoke(
+    role: AgentRoleConfig,
+    name: string,
+    args: any,
+    context: Omit<InvokeContext, 'agentRole' | 'grantedScopes'>,
+    stageScopes: string[] = []
+  ): Promise<string> {
+    const tool = this.findTool(name);
+    if (!tool) {
+      throw new ToolAccessError(`Unknown tool ${name}`);
+    }
+    const scopes = grantedScopes(role, stageScopes);
+    if (!canUseTool(scopes, tool)) {
+      const missing = tool.scopes.filter(scope => !scopeGranted(scopes, scope));
+      throw new ToolAccessError(`Role ${role.id} lacks scope ${missing.join(', ')} for tool ${name}`);
+    }
+
+    const result = await this.servers.get(tool.server)!.client.invoke(name, args, {
+      ...context,
+      agentRole: role.id,
+      grantedScopes: scopes
+    }, this.invokeTimeoutMs);
+
+    if (result.error) throw new Error(result.error);
+    return result.output;
+  }
+
+  private findTool(name: string): ToolDefinition | undefined {
+    return this.list().find(tool => tool.name === name);
+  }
+}
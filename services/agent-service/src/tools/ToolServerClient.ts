@@ -0,0 +1,83 @@
+import * as path from 'path';
+import * as grpc from '@grpc/grpc-js';
+import * as protoLoader from '@grpc/proto-loader';
+import { ToolDefinition, ToolServerConfig } from '../types/index.js';
+
+const PROTO_PATH = process.env.TOOL_PROTO_PATH || path.join(process.cwd(), 'proto', 'tool_plugin.proto');
+
+let ToolPlugin: grpc.ServiceClientConstructor | undefined;
+
+// The proto is loaded on first use so the service starts without it when no tools are registered
+const loadService = (): grpc.ServiceClientConstructor => {
+  if (!ToolPlugin) {
+    const definition = protoLoader.loadSync(PROTO_PATH, { keepCase: false, defaults: true });
+    const proto = grpc.loadPackageDefinition(definition) as any;
+    ToolPlugin = proto.aipipeline.tools.v1.ToolPlugin as grpc.ServiceClientConstructor;
+  }
+  return ToolPlugin;
+};
+
+export interface InvokeContext {
+  runId: string;
+  stageId: string;
+  projectId?: string;
+  agentRole: string;
+  grantedScopes: string[];
+}
+
+// What the registry needs from a tool server, whichever protocol it speaks
+export interface ToolClient {
+  listTools(timeoutMs?: number): Promise<ToolDefinition[]>;
+  invoke(tool: string, args: any, context: InvokeContext, timeoutMs: number): Promise<{ output: string; error?: string }>;
+  close(): void;
+}
+
+// gRPC client for one tool server
+export class ToolServerClient implements ToolClient {
+  private client: any;
+
+  constructor(private config: ToolServerConfig) {
+    const Service = loadService();
+    this.client = new Service(
+      config.address!,
+      config.tls ? grpc.credentials.createSsl() : grpc.credentials.createInsecure()
+    );
+  }
+
+  async listTools(timeoutMs = 5000): Promise<ToolDefinition[]> {
+    const response: any = await this.call('ListTools', {}, timeoutMs);
+    return (response.tools || []).map((tool: any) => ({
+      name: tool.name,
+      description: tool.description,
+      inputSchema: tool.inputSchemaJson ? JSON.parse(tool.inputSchemaJson) : undefined,
+      scopes: tool.scopes || [],
+      server: this.config.name
+    }));
+  }
+
+  async invoke(tool: string, args: any, context: InvokeContext, timeoutMs: number): Promise<{ output: string; error?: string }> {
+    const response: any = await this.call('Invoke', {
+      tool,
+      argumentsJson: JSON.stringify(args ?? {}),
+      runId: context.runId,
+      stageId: context.stageId,
+      projectId: context.projectId || '',
+      agentRole: context.agentRole,
+      grantedScopes: context.grantedScopes
+    }, timeoutMs);
+    return { output: response.output || '', error: response.error || undefined };
+  }
+
+  close(): void {
+    this.client.close();
+  }
+
+  private call(method: string, request: any, timeoutMs: number): Promise<any> {
+    return new Promise((resolve, reject) => {
+      this.client[method](request, { deadline: Date.now() + timeoutMs }, (error: grpc.ServiceError | null, response: any) => {
+        if (error) reject(new Error(`${this.config.name} ${method} failed: ${error.details || error.message}`));
+        else resolve(response);
+      });
+    });
+  }
+}
@@ -0,0 +1,144 @@
+// Agent Service types
+export type OutputFormat = 'json' | 'text' | 'files';
+
+export interface AgentRoleConfig {
+  id: string;
+  name: string;
+  description: string;
+  model: string;
+  temperature: number;
+  maxOutputTokens: number;
+  systemPrompt?: string;
+  promptTemplate: string;
+  // Published prompt in the prompt service; promptTemplate is used when it is unavailable
+  promptName?: string;
+  outputFormat: OutputFormat;
+  requiredInputs: string[];
+  // Token budget for upstream outputs and project files in the prompt (default 16000);
+  // maxContextChars is the older character budget, used when this is unset
+  maxContextTokens?: number;
+  maxContextChars?: number;
+  // Tool scopes granted to the role, e.g. web:search or repo:*; it is offered every registered
+  // tool whose scopes it holds
+  toolScopes?: string[];
+  maxToolCalls?: number;
+  // JSON Schema the parsed output must match; stages can supply their own
+  outputSchema?: Record<string, any>;
+  // Follow-up prompts allowed to fix output that does not parse or match the schema (default 2)
+  outputRepairs?: number;
+  // Summaries of earlier stages of the project recalled into the prompt (default AGENT_MEMORY_LIMIT, 0 turns it off)
+  memoryLimit?: number;
+}
+
+// ExecuteStage contract used by the orchestrator for every specialist role
+export interface ExecuteStageRequest {
+  runId: string;
+  stageId: string;
+  projectId?: string;
+  inputs: Record<string, any>;
+  previousOutputs?: Record<string, any>;
+  files?: Record<string, string>;
+  modelOverride?: string;
+  // Granted by the stage on top of the role's toolScopes, e.g. to attach an MCP server to one stage
+  toolScopes?: string[];
+  // Override the role's outputSchema and outputRepairs for this stage
+  outputSchema?: Record<string, any>;
+  outputRepairs?: number;
+  // Render this version of the role's managed prompt instead of the published one, e.g. a draft under evaluation
+  promptVersion?: number;
+  // Set to false to leave out what earlier stages of the project decided
+  memory?: boolean;
+}
+
+// output_invalid: the output still did not parse or match its schema once repairs ran out
+export type StageErrorType = 'output_invalid';
+
+export interface ExecuteStageResponse {
+  runId: string;
+  stageId: string;
+  role: string;
+  status: 'completed' | 'failed';
+  output: any;
+  rawOutput: string;
+  model: string;
+  usage?: LLMUsage;
+  durationMs: number;
+  error?: string;
+  errorType?: StageErrorType;
+  validationErrors?: string[];
+  // Repair prompts it took to get usable output
+  repairs?: number;
+  toolCalls?: ToolCallRecord[];
+  // The managed prompt version the stage ran with; absent when it used the role's bundled template
+  prompt?: { name: string; version: number };
+  // Earlier stages whose summaries were added to the prompt
+  memories?: Array<{ runId: string; stageId: string; score: number }>;
+}
+
+// LLM gateway types
+export interface ChatMessage {
+  role: 'system' | 'user' | 'assistant';
+  content: string;
+}
+
+export interface LLMUsage {
+  promptTokens: number;
+  completionTokens: number;
+  totalTokens: number;
+  costUsd?: number;
+}
+
+export interface ChatCompletionRequest {
+  model: string;
+  messages: ChatMessage[];
+  temperature?: number;
+  maxTokens?: number;
+  metadata?: Record<string, string>;
+}
+
+export interface ChatCompletionResponse {
+  id: string;
+  provider: string;
+  model: string;
+  content: string;
+  finishReason: string;
+  usage: LLMUsage;
+}
+
+export interface GeneratedFile {
+  path: string;
+  content: string;
+}
+
+// Tool servers: gRPC tool plugins (see proto/tool_plugin.proto) or MCP servers
+export type ToolServerProtocol = 'grpc' | 'mcp';
+
+export interface ToolServerConfig {
+  name: string;
+  protocol?: ToolServerProtocol;
+  // host:port of a gRPC server
+  address?: string;
+  tls?: boolean;
+  // Streamable HTTP endpoint of an MCP server
+  url?: string;
+  // Sent with every MCP request, e.g. Authorization for a hosted server; never listed back
+  headers?: Record<string, string>;
+  // MCP has no scopes of its own, so every tool of an MCP server requires these (default mcp:<name>)
+  scopes?: string[];
+}
+
+export interface ToolDefinition {
+  name: string;
+  description: string;
+  inputSchema?: Record<string, any>;
+  scopes: string[];
+  server: string;
+}
+
+export interface ToolCallRecord {
+  tool: string;
+  arguments: any;
+  status: 'completed' | 'failed' | 'denied';
+  error?: string;
+  durationMs: number;
+}
@@ -0,0 +1,109 @@
+// Routing rules: which public path prefixes reach which internal service, and how
+export interface RouteRule {
+  name: string;
+  paths: string[];
+  target: string;
+  // 'required' rejects anonymous callers; 'public' passes requests through untouched
+  auth: 'required' | 'public';
+  // Public prefix -> upstream prefix, when they differ
+  rewrite?: Record<string, string>;
+  // Per-caller request budget for this route within the rate limit window
+  rateLimit?: number;
+  // Sub-paths the budget applies to, when it should not cover the whole route
+  rateLimitPaths?: string[];
+  ws?: boolean;
+}
+
+export const services = {
+  auth: process.env.AUTH_SERVICE_URL || 'http://localhost:3001',
+  project: process.env.PROJECT_SERVICE_URL || 'http://localhost:3002',
+  github: process.env.GITHUB_SERVICE_URL || 'http://localhost:3003',
+  pipeline: process.env.PIPELINE_SERVICE_URL || 'http://localhost:3004',
+  prompt: process.env.PROMPT_SERVICE_URL || 'http://localhost:3007',
+  webhook: process.env.WEBHOOK_SERVICE_URL || 'http://localhost:3009',
+  notification: process.env.NOTIFICATION_SERVICE_URL || 'http://localhost:3010',
+  review: process.env.REVIEW_SERVICE_URL || 'http://localhost:3011',
+  llm: process.env.LLM_GATEWAY_URL || 'http://localhost:3006',
+  template: process.env.TEMPLATE_SERVICE_URL || 'http://localhost:3012',
+  realtime: process.env.REALTIME_SERVICE_URL || 'http://localhost:3013',
+  conversation: process.env.CONVERSATION_SERVICE_URL || 'http://localhost:3014',
+  telemetry: process.env.TELEMETRY_SERVICE_URL || 'http://localhost:3015',
+  secretScan: process.env.SECRET_SCAN_SERVICE_URL || 'http://localhost:3016',
+  qualityGate: process.env.QUALITY_GATE_SERVICE_URL || 'http://localhost:3017',
+  preview: process.env.PREVIEW_SERVICE_URL || 'http://localhost:3018',
+  diagram: process.env.DIAGRAM_SERVICE_URL || 'http://localhost:3019',
+  moderation: process.env.MODERATION_SERVICE_URL || 'http://localhost:3020',
+  eval: process.env.EVAL_SERVICE_URL || 'http://localhost:3021',
+  artifact: process.env.ARTIFACT_SERVICE_URL || 'http://localhost:3008'
+};
+
+// Internal ingestion endpoints (webhook/notification events, LLM chat) are deliberately absent
+export const routes: RouteRule[] = [
+  // Login and registration are throttled harder than the rest of the API; token refreshes are not
+  {
+    name: 'Authentication service',
+    paths: ['/api/auth'],
+    target: services.auth,
+    auth: 'public',
+    rateLimit: 20,
+    rateLimitPaths: ['/login', '/register']
+  },
+  { name: 'Authentication service', paths: ['/api/organizations'], target: services.auth, auth: 'required' },
+  // Identity providers authenticate with an organization SCIM token, which the auth service checks
+  { name: 'Authentication service', paths: ['/scim/v2'], target: services.auth, auth: 'public' },
+  { name: 'Project service', paths: ['/api/projects'], target: services.project, auth: 'required' },
+  { name: 'GitHub service', paths: ['/api/github', '/api/git'], target: services.github, auth: 'required' },
+  { name: 'Pipeline service', paths: ['/api/pipeline'], target: services.pipeline, auth: 'required' },
+  { name: 'Prompt service', paths: ['/api/prompts'], target: services.prompt, auth: 'required' },
+  { name: 'Webhook service', paths: ['/api/webhooks'], target: services.webhook, auth: 'required' },
+  { name: 'Notification service', paths: ['/api/notifications/channels'], target: services.notification, auth: 'required' },
+  { name: 'Review service', paths: ['/api/reviews'], target: services.review, auth: 'required' },
+  {
+    name: 'LLM gateway',
+    paths: ['/api/models', '/api/budgets', '/api/routing'],
+    target: services.llm,
+    auth: 'required',
+    rewrite: {
+      '^/api/models': '/api/llm/models',
+      '^/api/budgets': '/api/llm/budgets',
+      '^/api/routing': '/api/llm/routing'
+    }
+  },
+  { name: 'Template service', paths: ['/api/templates'], target: services.template, auth: 'required' },
+  { name: 'Realtime service', paths: ['/api/realtime'], target: services.realtime, auth: 'required' },
+  { name: 'Realtime service', paths: ['/realtime/socket.io'], target: services.realtime, auth: 'public', ws: true },
+  // Transcript recording and checkpoints stay internal
+  { name: 'Conversation service', paths: ['/api/conversations/runs'], target: services.conversation, auth: 'required' },
+  {
+    name: 'Telemetry service',
+    paths: ['/api/telemetry/stages', '/api/telemetry/failures', '/api/telemetry/models', '/api/telemetry/runs'],
+    target: services.telemetry,
+    auth: 'required'
+  },
+  { name: 'Secret scan service', paths: ['/api/secret-scans'], target: services.secretScan, auth: 'required' },
+  // Gate runs execute commands in the sandbox, so only the orchestrator can start them
+  {
+    name: 'Quality gate service',
+    paths: ['/api/quality-gates/reports', '/api/quality-gates/checks'],
+    target: services.qualityGate,
+    auth: 'required'
+  },
+  // Builds stay internal; stakeholders list, extend and stop previews
+  { name: 'Preview service', paths: ['/api/previews/deployments'], target: services.preview, auth: 'required' },
+  // Signed documentation links carry their own authorization and must come before the catch-all rule
+  { name: 'Diagram service', paths: ['/api/diagrams/public'], target: services.diagram, auth: 'public' },
+  { name: 'Diagram service', paths: ['/api/diagrams'], target: services.diagram, auth: 'required' },
+  // Checks stay internal; users manage policies and review what was blocked
+  {
+    name: 'Moderation service',
+    paths: ['/api/moderation/policies', '/api/moderation/audit'],
+    target: services.moderation,
+    auth: 'required'
+  },
+  // Datasets and eval reports for comparing prompt and model changes
+  { name: 'Eval service', paths: ['/api/evals'], target: services.eval, auth: 'required' },
+  // Uploads and retention stay internal; users compare what two runs generated
+  { name: 'Artifact service', paths: ['/api/artifacts/diff'], target: services.artifact, auth: 'required' },
+  // Real-time pipeline updates; Socket.IO authenticates on its own handshake
+  { name: 'WebSocket', paths: ['/socket.io'], target: services.pipeline, auth: 'public', ws: true }
+];
@@ -1,11 +1,16 @@
 import express from 'express';
+import https from 'https';
+import fs from 'fs';
+import { randomUUID } from 'crypto';
 import cors from 'cors';
 import helmet from 'helmet';
 import rateLimit from 'express-rate-limit';
-import { createProxyMiddleware } from 'http-proxy-middleware';
+import { createProxyMiddleware, fixRequestBody } from 'http-proxy-middleware';
 import jwt from 'jsonwebtoken';
 import dotenv from 'dotenv';
 import winston from 'winston';
+import { routes, services } from './routing.js';
+import { SessionChecker } from './sessions.js';
 
 // Load environment variables
 dotenv.config();
@@ -33,32 +38,54 @@ app.use(cors({
   credentials: true
 }));
 
-// Rate limiting
-const limiter = rateLimit({
-  windowMs: 15 * 60 * 1000, // 15 minutes
-  max: 100, // limit each IP to 100 requests per windowMs
-  message: 'Too many requests from this IP, please try again later.',
-  standardHeaders: true,
-  legacyHeaders: false,
-});
-app.use(limiter);
-
-// Body parsing
-app.use(express.json({ limit: '10mb' }));
-app.use(express.urlencoded({ extended: true }));
-
-// Request logging middleware
-app.use((req, res, next) => {
-  logger.info(`${req.method} ${req.path}`, {
-    ip: req.ip,
-    userAgent: req.get('User-Agent'),
-    timestamp: new Date().toISOString()
+// No body parsing: the gateway handles no request bodies itself, and a parsed body would be
+// consumed before the proxy could forward it
+
+// Unified access log: one line per request once the response is sent, correlated by request ID
+app.use((req: any, res, next) => {
+  const requestId = req.get('X-Request-Id') || randomUUID();
+  const startedAt = process.hrtime.bigint();
+  req.headers['x-request-id'] = requestId;
+  res.setHeader('X-Request-Id', requestId);
+
+  res.on('finish', () => {
+    const route = routes.find(rule => rule.paths.some(path => req.originalUrl.startsWith(path)));
+    logger.info('access', {
+      requestId,
+      method: req.method,
+      path: req.originalUrl,
+      status: res.statusCode,
+      durationMs: Number(process.hrtime.bigint() - startedAt) / 1e6,
+      service: route?.name,
+      userId: req.user?.userId,
+      ip: req.ip,
+      userAgent: req.get('User-Agent')
+    });
   });
   next();
 });
 
+const sessions = new SessionChecker(services.auth);
+
+// Verifies the token and, for session tokens, that the session has not been revoked.
+// Resolves to the token's claims, null for an invalid or revoked token.
+const verifyToken = async (token: string): Promise<any | null> => {
+  let decoded: any;
+  try {
+    const secret = process.env.JWT_SECRET || 'fallback-secret';
+    decoded = jwt.verify(token, secret);
+  } catch (error) {
+    return null;
+  }
+
+  if (decoded.sid && !await sessions.isActive(decoded.sid, token)) {
+    return null;
+  }
+  return decoded;
+};
+
 // JWT Authentication middleware
-const authenticateToken = (req: any, res: any, next: any) => {
+const authenticateToken = async (req: any, res: any, next: any) => {
   const authHeader = req.headers.authorization;
   const token = authHeader && authHeader.split(' ')[1];
 
@@ -70,42 +97,59 @@ const authenticateToken = (req: any, res: any, next: any) => {
   }
 
   try {
-    const secret = process.env.JWT_SECRET || 'fallback-secret';
-    const decoded = jwt.verify(token, secret);
+    const decoded = await verifyToken(token);
+    if (!decoded) {
+      return res.status(403).json({ 
+        success: false, 
+        error: 'Invalid token' 
+      });
+    }
     req.user = decoded;
     next();
   } catch (error) {
-    return res.status(403).json({ 
-      success: false, 
-      error: 'Invalid token' 
+    logger.error('Session check failed:', error);
+    res.status(503).json({
+      success: false,
+      error: 'Authentication service unavailable'
     });
   }
 };
 
 // Optional authentication middleware
-const optionalAuth = (req: any, res: any, next: any) => {
+const optionalAuth = async (req: any, res: any, next: any) => {
   const authHeader = req.headers.authorization;
   const token = authHeader && authHeader.split(' ')[1];
 
   if (token) {
     try {
-      const secret = process.env.JWT_SECRET || 'fallback-secret';
-      const decoded = jwt.verify(token, secret);
-      req.user = decoded;
+      const decoded = await verifyToken(token);
+      if (decoded) req.user = decoded;
     } catch (error) {
-      // Continue without authentication
+      // Continue without authentication; required routes check again
     }
   }
   next();
 };
 
-// Service URLs
-const services = {
-  auth: process.env.AUTH_SERVICE_URL || 'http://localhost:3001',
-  project: process.env.PROJECT_SERVICE_URL || 'http://localhost:3002',
-  github: process.env.GITHUB_SERVICE_URL || 'http://localhost:3003',
-  pipeline: process.env.PIPELINE_SERVICE_URL || 'http://localhost:3004'
-};
+// Rate limiting, keyed by user for authenticated callers and by IP otherwise
+const RATE_LIMIT_WINDOW_MS = parseInt(process.env.RATE_LIMIT_WINDOW_MS || String(15 * 60 * 1000));
+const RATE_LIMIT_MAX = parseInt(process.env.RATE_LIMIT_MAX || '100');
+const RATE_LIMIT_MAX_AUTHENTICATED = parseInt(process.env.RATE_LIMIT_MAX_AUTHENTICATED || '1000');
+
+const createLimiter = (limit: (req: any) => number) => rateLimit({
+  windowMs: RATE_LIMIT_WINDOW_MS,
+  limit,
+  keyGenerator: (req: any) => req.user?.userId ? `user:${req.user.userId}` : `ip:${req.ip}`,
+  message: {
+    success: false,
+    error: 'Too many requests, please try again later.'
+  },
+  standardHeaders: true,
+  legacyHeaders: false,
+});
+
+app.use(optionalAuth);
+app.use(createLimiter(req => req.user ? RATE_LIMIT_MAX_AUTHENTICATED : RATE_LIMIT_MAX));
 
 // Health check endpoint
 app.get('/health', (req, res) => {
@@ -117,96 +161,42 @@ app.get('/health', (req, res) => {
   });
 });
 
-// Authentication routes (public)
-app.use('/api/auth', createProxyMiddleware({
-  target: services.auth,
-  changeOrigin: true,
-  pathRewrite: {
-    '^/api/auth': '/api/auth'
-  },
-  onError: (err, req, res) => {
-    logger.error('Auth service proxy error:', err);
-    res.status(503).json({ 
-      success: false, 
-      error: 'Authentication service unavailable' 
-    });
-  }
-}));
-
-// Project routes (protected)
-app.use('/api/projects', authenticateToken, createProxyMiddleware({
-  target: services.project,
-  changeOrigin: true,
-  pathRewrite: {
-    '^/api/projects': '/api/projects'
-  },
-  onProxyReq: (proxyReq, req: any, res) => {
-    // Forward user info to microservice
-    if (req.user) {
-      proxyReq.setHeader('X-User-Id', req.user.userId);
-      proxyReq.setHeader('X-User-Email', req.user.email || '');
-    }
-  },
-  onError: (err, req, res) => {
-    logger.error('Project service proxy error:', err);
-    res.status(503).json({ 
-      success: false, 
-      error: 'Project service unavailable' 
-    });
-  }
-}));
-
-// GitHub routes (protected)
-app.use('/api/github', authenticateToken, createProxyMiddleware({
-  target: services.github,
-  changeOrigin: true,
-  pathRewrite: {
-    '^/api/github': '/api/github'
-  },
-  onProxyReq: (proxyReq, req: any, res) => {
-    if (req.user) {
-      proxyReq.setHeader('X-User-Id', req.user.userId);
-    }
-  },
-  onError: (err, req, res) => {
-    logger.error('GitHub service proxy error:', err);
-    res.status(503).json({ 
-      success: false, 
-      error: 'GitHub service unavailable' 
-    });
+// Proxy each routing rule to its service
+for (const route of routes) {
+  const guards: express.RequestHandler[] = [];
+  if (route.auth === 'required') guards.push(authenticateToken);
+  if (route.rateLimit) {
+    const limiter = createLimiter(() => route.rateLimit!);
+    const limitedPaths = route.rateLimitPaths;
+    guards.push(limitedPaths
+      ? (req, res, next) => limitedPaths.includes(req.path) ? limiter(req, res, next) : next()
+      : limiter);
   }
-}));
 
-// Pipeline routes (protected)
-app.use('/api/pipeline', authenticateToken, createProxyMiddleware({
-  target: services.pipeline,
-  changeOrigin: true,
-  pathRewrite: {
-    '^/api/pipeline': '/api/pipeline'
-  },
-  onProxyReq: (proxyReq, req: any, res) => {
-    if (req.user) {
-      proxyReq.setHeader('X-User-Id', req.user.userId);
+  app.use(route.paths, ...guards, createProxyMiddleware({
+    target: route.target,
+    changeOrigin: true,
+    ws: route.ws,
+    pathRewrite: route.rewrite,
+    onProxyReq: (proxyReq, req: any) => {
+      // Identity headers only ever come from the gateway
+      proxyReq.removeHeader('X-User-Id');
+      if (req.user) {
+        proxyReq.setHeader('X-User-Id', req.user.userId);
+      }
+      // Re-streams the body should anything upstream of the proxy have parsed it
+      fixRequestBody(proxyReq, req);
+    },
+    onError: (err, req: any, res: any) => {
+      logger.error(`${route.name} proxy error:`, err);
+      if (route.ws || res.headersSent || typeof res.status !== 'function') return;
+      res.status(503).json({
+        success: false,
+        error: `${route.name} unavailable`
+      });
     }
-  },
-  onError: (err, req, res) => {
-    logger.error('Pipeline service proxy error:', err);
-    res.status(503).json({ 
-      success: false, 
-      error: 'Pipeline service unavailable' 
-    });
-  }
-}));
-
-// WebSocket proxy for real-time features
-app.use('/socket.io', createProxyMiddleware({
-  target: services.pipeline,
-  changeOrigin: true,
-  ws: true, // Enable WebSocket proxying
-  onError: (err, req, res) => {
-    logger.error('WebSocket proxy error:', err);
-  }
-}));
+  }));
+}
 
 // Catch-all for undefined routes
 app.use('*', (req, res) => {
@@ -226,10 +216,19 @@ app.use((err: any, req: any, res: any, next: any) => {
   });
 });
 
-// Start server
-app.listen(PORT, () => {
-  logger.info(`🚀 API Gateway running on port ${PORT}`);
+// Start server, terminating TLS here when a certificate is configured
+const onListening = (scheme: string) => () => {
+  logger.info(`🚀 API Gateway running on ${scheme}://localhost:${PORT}`);
   logger.info('Service URLs:', services);
-});
+};
+
+if (process.env.TLS_CERT_PATH && process.env.TLS_KEY_PATH) {
+  https.createServer({
+    cert: fs.readFileSync(process.env.TLS_CERT_PATH),
+    key: fs.readFileSync(process.env.TLS_KEY_PATH)
+  }, app).listen(PORT, onListening('https'));
+} else {
+  app.listen(PORT, onListening('http'));
+}
 
 export default app;
\ No newline at end of file
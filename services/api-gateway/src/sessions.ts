@@ -0,0 +1,40 @@
+// Access tokens carry the dashboard session they belong to (sid). The gateway asks auth-service
+// whether that session is still live, so a revoked session stops working here instead of lasting
+// until its token expires. Live sessions are re-checked after SESSION_CHECK_TTL_MS; revoked ones
+// are remembered for as long as they stay in the cache.
+export class SessionChecker {
+  private checked: Map<string, { active: boolean; checkedAt: number }> = new Map();
+  private ttlMs = parseInt(process.env.SESSION_CHECK_TTL_MS || '30000');
+  private maxEntries = parseInt(process.env.SESSION_CHECK_MAX_ENTRIES || '10000');
+
+  constructor(private authServiceUrl: string) {}
+
+  // Throws when auth-service cannot answer and the session is not cached
+  async isActive(sid: string, token: string): Promise<boolean> {
+    const cached = this.checked.get(sid);
+    if (cached && (!cached.active || Date.now() - cached.checkedAt < this.ttlMs)) {
+      return cached.active;
+    }
+
+    const response = await fetch(`${this.authServiceUrl}/api/auth/verify`, {
+      headers: { Authorization: `Bearer ${token}` },
+      signal: AbortSignal.timeout(5000)
+    });
+    if (response.status >= 500) {
+      throw new Error(`Auth service responded with ${response.status}`);
+    }
+
+    const active = response.ok;
+    this.remember(sid, active);
+    return active;
+  }
+
+  private remember(sid: string, active: boolean): void {
+    this.checked.delete(sid);
+    this.checked.set(sid, { active, checkedAt: Date.now() });
+    // Maps iterate in insertion order, so the first key is the least recently checked
+    while (this.checked.size > this.maxEntries) {
+      this.checked.delete(this.checked.keys().next().value!);
+    }
+  }
+}
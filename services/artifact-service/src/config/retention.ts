@@ -0,0 +1,15 @@
+import { ArtifactType } from '../models/Artifact.js';
+
+// Days each artifact type is kept before the retention sweep removes it (0 keeps forever)
+export const RETENTION_DAYS: Record<ArtifactType, number> = {
+  'code-bundle': parseInt(process.env.RETENTION_DAYS_CODE_BUNDLE || '90'),
+  'design-doc': parseInt(process.env.RETENTION_DAYS_DESIGN_DOC || '0'),
+  'diagram': parseInt(process.env.RETENTION_DAYS_DIAGRAM || '0'),
+  'other': parseInt(process.env.RETENTION_DAYS_OTHER || '30')
+};
+
+export function computeExpiry(type: ArtifactType, retentionDays?: number): Date | undefined {
+  const days = retentionDays ?? RETENTION_DAYS[type];
+  if (!days) return undefined;
+  return new Date(Date.now() + days * 24 * 60 * 60 * 1000);
+}
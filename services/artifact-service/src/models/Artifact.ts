@@ -0,0 +1,100 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export type ArtifactType = 'code-bundle' | 'design-doc' | 'diagram' | 'other';
+
+export interface IArtifact extends Document {
+  projectId?: string;
+  runId?: string;
+  stageId?: string;
+  type: ArtifactType;
+  name: string;
+  contentType: string;
+  size: number;
+  sha256: string;
+  storageKey: string;
+  metadata?: Record<string, string>;
+  createdBy?: string;
+  expiresAt?: Date;
+  quarantine?: {
+    reason: string;
+    scanId?: string;
+    quarantinedAt: Date;
+  };
+  moderation?: {
+    decision: 'flagged' | 'redacted';
+    recordId?: string;
+  };
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const ArtifactSchema: Schema = new Schema({
+  projectId: {
+    type: String,
+    index: true
+  },
+  runId: {
+    type: String,
+    index: true
+  },
+  stageId: {
+    type: String
+  },
+  type: {
+    type: String,
+    enum: ['code-bundle', 'design-doc', 'diagram', 'other'],
+    required: true
+  },
+  name: {
+    type: String,
+    required: true,
+    trim: true,
+    maxlength: 255
+  },
+  contentType: {
+    type: String,
+    required: true
+  },
+  size: {
+    type: Number,
+    required: true
+  },
+  sha256: {
+    type: String,
+    required: true,
+    index: true
+  },
+  storageKey: {
+    type: String,
+    required: true
+  },
+  metadata: {
+    type: Map,
+    of: String
+  },
+  createdBy: {
+    type: String
+  },
+  expiresAt: {
+    type: Date,
+    index: true
+  },
+  // Set when a scan finds leaked credentials; quarantined artifacts cannot be downloaded
+  quarantine: {
+    reason: { type: String },
+    scanId: { type: String },
+    quarantinedAt: { type: Date }
+  },
+  // Set when moderation redacted or flagged content on upload; blocked content is never stored
+  moderation: {
+    decision: { type: String, enum: ['flagged', 'redacted'] },
+    recordId: { type: String }
+  }
+}, {
+  timestamps: true
+});
+
+ArtifactSchema.index({ projectId: 1, createdAt: -1 });
+ArtifactSchema.index({ runId: 1, stageId: 1 });
+
+export const Artifact = mongoose.model<IArtifact>('Artifact', ArtifactSchema);
@@ -0,0 +1,507 @@
+import express, { Request, Response } from 'express';
+import { body, param, query, validationResult } from 'express-validator';
+import axios from 'axios';
+import { Artifact, IArtifact } from '../models/Artifact.js';
+import { AuthenticatedRequest, requireServiceToken, requireUserOrService } from '../middleware/auth.js';
+import { StorageService } from '../services/StorageService.js';
+import { RetentionService } from '../services/RetentionService.js';
+import { ArtifactDiffService } from '../services/ArtifactDiffService.js';
+import { ModerationClient, ModerationOutcome } from '../services/ModerationClient.js';
+import { computeExpiry } from '../config/retention.js';
+
+const router = express.Router();
+
+const DEFAULT_URL_TTL_SECONDS = parseInt(process.env.DOWNLOAD_URL_TTL_SECONDS || '900');
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const artifactIdParam = param('id').isMongoId().withMessage('Invalid artifact ID');
+
+// Platform services and admins see every artifact; users see those of projects they can read through
+// project-service, and projectless artifacts they created. Network failures are rethrown.
+const canAccessArtifact = async (
+  req: AuthenticatedRequest,
+  artifact: Pick<IArtifact, 'projectId' | 'createdBy'>
+): Promise<boolean> => {
+  if (req.service || req.user!.role === 'admin') return true;
+  if (!artifact.projectId) return !!artifact.createdBy && artifact.createdBy === req.user!._id;
+
+  const projectServiceUrl = process.env.PROJECT_SERVICE_URL || 'http://localhost:3002';
+  try {
+    await axios.get(`${projectServiceUrl}/api/projects/${encodeURIComponent(artifact.projectId)}`, {
+      headers: { Authorization: req.headers.authorization },
+      timeout: 5000
+    });
+    return true;
+  } catch (error) {
+    if (!axios.isAxiosError(error) || !error.response) throw error;
+    return false;
+  }
+};
+
+// Loads an artifact the caller may see. Answers 404 for missing and hidden artifacts alike, and 502
+// when project-service cannot answer; resolves to null once it has answered.
+const findArtifact = async (req: AuthenticatedRequest, res: Response): Promise<IArtifact | null> => {
+  const artifact = await Artifact.findById(req.params.id);
+  let visible = false;
+  try {
+    visible = !!artifact && await canAccessArtifact(req, artifact);
+  } catch (error) {
+    console.error('Project access check error:', error);
+    res.status(502).json({
+      success: false,
+      error: 'Failed to check project access'
+    });
+    return null;
+  }
+
+  if (!visible) {
+    res.status(404).json({
+      success: false,
+      error: 'Artifact not found'
+    });
+    return null;
+  }
+  return artifact;
+};
+
+export default function createArtifactRoutes(
+  storage: StorageService,
+  retention: RetentionService,
+  diffs: ArtifactDiffService,
+  moderation: ModerationClient = new ModerationClient()
+) {
+  // POST /api/artifacts - Upload a stage output (content-addressed). With MODERATION_SERVICE_URL set,
+  // text content is screened first: blocked content is refused, redacted content is stored redacted.
+  // Internal: stages upload their outputs with the service token.
+  router.post('/',
+    requireServiceToken,
+    [
+      body('name').trim().isLength({ min: 1, max: 255 }).withMessage('Artifact name is required'),
+      body('type').isIn(['code-bundle', 'design-doc', 'diagram', 'other']).withMessage('Invalid artifact type'),
+      body('content').isString().withMessage('Content must be a string'),
+      body('encoding').optional().isIn(['utf-8', 'base64']).withMessage('Encoding must be utf-8 or base64'),
+      body('contentType').optional().isString().withMessage('Content type must be a string'),
+      body('projectId').optional().isString().withMessage('Project ID must be a string'),
+      body('runId').optional().isString().withMessage('Run ID must be a string'),
+      body('stageId').optional().isString().withMessage('Stage ID must be a string'),
+      body('retentionDays').optional().isInt({ min: 0 }).withMessage('Retention days must be a non-negative integer'),
+      body('metadata').optional().isObject().withMessage('Metadata must be an object')
+    ],
+    validateRequest,
+    async (req: Request, res: Response) => {
+      try {
+        const { name, type, content, encoding = 'utf-8', projectId, runId, stageId, retentionDays, metadata } = req.body;
+        const contentType = req.body.contentType || (type === 'code-bundle' ? 'application/json' : 'application/octet-stream');
+        let buffer = Buffer.from(content, encoding === 'base64' ? 'base64' : 'utf-8');
+
+        let screened: ModerationOutcome | undefined;
+        if (moderation.screens(contentType)) {
+          screened = await moderation.check(buffer, { type, name, contentType, projectId, runId, stageId });
+          if (screened.decision === 'blocked') {
+            return res.status(422).json({
+              success: false,
+              error: 'Artifact blocked by moderation policy',
+              moderationRecordId: screened.recordId,
+              findings: screened.findings
+            });
+          }
+          buffer = screened.content;
+        }
+
+        const stored = await storage.putContent(buffer, contentType);
+
+        const artifact = new Artifact({
+          projectId,
+          runId,
+          stageId,
+          type,
+          name,
+          contentType,
+          size: stored.size,
+          sha256: stored.sha256,
+          storageKey: stored.storageKey,
+          metadata,
+          createdBy: req.get('X-User-Id'),
+          expiresAt: computeExpiry(type, retentionDays),
+          ...(screened && screened.decision !== 'allowed'
+            ? { moderation: { decision: screened.decision, recordId: screened.recordId } }
+            : {})
+        });
+        await artifact.save();
+
+        res.status(201).json({
+          success: true,
+          data: {
+            ...artifact.toJSON(),
+            deduplicated: stored.deduplicated
+          }
+        });
+      } catch (error) {
+        console.error('Artifact upload error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to store artifact'
+        });
+      }
+    }
+  );
+
+  // GET /api/artifacts - List artifacts for a project or run
+  router.get('/',
+    requireUserOrService,
+    [
+      query('projectId').optional().isString().withMessage('Project ID must be a string'),
+      query('runId').optional().isString().withMessage('Run ID must be a string'),
+      query('stageId').optional().isString().withMessage('Stage ID must be a string'),
+      query('type').optional().isIn(['code-bundle', 'design-doc', 'diagram', 'other']).withMessage('Invalid artifact type')
+    ],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const { projectId, runId, stageId, type } = req.query as Record<string, string | undefined>;
+        if (!projectId && !runId) {
+          return res.status(400).json({
+            success: false,
+            error: 'projectId or runId is required'
+          });
+        }
+
+        if (projectId && !await canAccessArtifact(req, { projectId })) {
+          return res.status(404).json({
+            success: false,
+            error: 'Project not found'
+          });
+        }
+
+        const filter: any = {};
+        if (projectId) filter.projectId = projectId;
+        if (runId) filter.runId = runId;
+        if (stageId) filter.stageId = stageId;
+        if (type) filter.type = type;
+
+        const artifacts = await Artifact.find(filter).sort({ createdAt: -1 }).limit(500);
+
+        // A run listed without its project may span projects; one lookup per project decides
+        const lookups = new Map<string, Promise<boolean>>();
+        const visible = await Promise.all(artifacts.map(artifact => {
+          if (!artifact.projectId) return canAccessArtifact(req, artifact);
+          if (!lookups.has(artifact.projectId)) {
+            lookups.set(artifact.projectId, canAccessArtifact(req, artifact));
+          }
+          return lookups.get(artifact.projectId)!;
+        }));
+
+        res.json({
+          success: true,
+          data: artifacts.filter((_, index) => visible[index])
+        });
+      } catch (error) {
+        console.error('Artifact list error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to list artifacts'
+        });
+      }
+    }
+  );
+
+  // GET /api/artifacts/diff - What changed in the generated output between two runs of a project the
+  // caller can read (checked in project-service with their Authorization header)
+  router.get('/diff',
+    requireUserOrService,
+    [
+      query('projectId').isString().isLength({ min: 1 }).withMessage('Project ID is required'),
+      query('base').isString().isLength({ min: 1 }).withMessage('Base run ID is required'),
+      query('head').isString().isLength({ min: 1 }).withMessage('Head run ID is required'),
+      query('includeUnchanged').optional().isBoolean().withMessage('includeUnchanged must be a boolean')
+    ],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      const { projectId, base, head } = req.query as Record<string, string>;
+
+      try {
+        if (!await canAccessArtifact(req, { projectId })) {
+          return res.status(404).json({
+            success: false,
+            error: 'Project not found'
+          });
+        }
+      } catch (error) {
+        console.error('Project access check error:', error);
+        return res.status(502).json({
+          success: false,
+          error: 'Failed to check project access'
+        });
+      }
+
+      try {
+        const diff = await diffs.diffRuns(projectId, base, head);
+        if (!diff) {
+          return res.status(404).json({
+            success: false,
+            error: 'Both runs need stored artifacts in this project'
+          });
+        }
+
+        if (req.query.includeUnchanged !== 'true') {
+          diff.files = diff.files.filter(file => file.status !== 'unchanged');
+        }
+
+        res.json({
+          success: true,
+          data: diff
+        });
+      } catch (error) {
+        console.error('Artifact diff error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to diff run artifacts'
+        });
+      }
+    }
+  );
+
+  // GET /api/artifacts/:id - Artifact metadata
+  router.get('/:id',
+    requireUserOrService,
+    [artifactIdParam],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const artifact = await findArtifact(req, res);
+        if (!artifact) return;
+
+        res.json({
+          success: true,
+          data: artifact
+        });
+      } catch (error) {
+        console.error('Artifact fetch error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to fetch artifact'
+        });
+      }
+    }
+  );
+
+  // GET /api/artifacts/:id/download-url - Signed, time-limited download link
+  router.get('/:id/download-url',
+    requireUserOrService,
+    [
+      artifactIdParam,
+      query('expiresIn').optional().isInt({ min: 60, max: 604800 }).withMessage('expiresIn must be between 60 and 604800 seconds')
+    ],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const artifact = await findArtifact(req, res);
+        if (!artifact) return;
+
+        if (artifact.quarantine?.quarantinedAt) {
+          return res.status(423).json({
+            success: false,
+            error: `Artifact is quarantined: ${artifact.quarantine.reason}`
+          });
+        }
+
+        const expiresIn = parseInt(req.query.expiresIn as string) || DEFAULT_URL_TTL_SECONDS;
+        const url = await storage.getDownloadUrl(artifact.storageKey, artifact.name, expiresIn);
+
+        res.json({
+          success: true,
+          data: {
+            url,
+            expiresAt: new Date(Date.now() + expiresIn * 1000).toISOString()
+          }
+        });
+      } catch (error) {
+        console.error('Download URL error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to create download URL'
+        });
+      }
+    }
+  );
+
+  // GET /api/artifacts/:id/content - Artifact bytes, for services that read artifacts directly
+  // (agents reading them as MCP resources) rather than handing out a download link
+  router.get('/:id/content',
+    requireUserOrService,
+    [artifactIdParam],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const artifact = await findArtifact(req, res);
+        if (!artifact) return;
+
+        if (artifact.quarantine?.quarantinedAt) {
+          return res.status(423).json({
+            success: false,
+            error: `Artifact is quarantined: ${artifact.quarantine.reason}`
+          });
+        }
+
+        const content = await storage.getContent(artifact.storageKey);
+        res.type(artifact.contentType).send(content);
+      } catch (error) {
+        console.error('Artifact content error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to read artifact content'
+        });
+      }
+    }
+  );
+
+  // PATCH /api/artifacts/:id/retention - Extend, shorten or clear an artifact's retention
+  router.patch('/:id/retention',
+    requireServiceToken,
+    [
+      artifactIdParam,
+      body('retentionDays').isInt({ min: 0 }).withMessage('Retention days must be a non-negative integer')
+    ],
+    validateRequest,
+    async (req: Request, res: Response) => {
+      try {
+        const artifact = await Artifact.findById(req.params.id);
+        if (!artifact) {
+          return res.status(404).json({
+            success: false,
+            error: 'Artifact not found'
+          });
+        }
+
+        artifact.expiresAt = computeExpiry(artifact.type, req.body.retentionDays);
+        await artifact.save();
+
+        res.json({
+          success: true,
+          data: artifact
+        });
+      } catch (error) {
+        console.error('Retention update error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to update retention'
+        });
+      }
+    }
+  );
+
+  // PUT /api/artifacts/:id/quarantine - Block downloads, e.g. after a secret scan finds credentials
+  router.put('/:id/quarantine',
+    requireServiceToken,
+    [
+      artifactIdParam,
+      body('reason').isString().isLength({ min: 1, max: 500 }).withMessage('A reason is required'),
+      body('scanId').optional().isString().withMessage('Scan ID must be a string')
+    ],
+    validateRequest,
+    async (req: Request, res: Response) => {
+      try {
+        const artifact = await Artifact.findByIdAndUpdate(req.params.id, {
+          quarantine: {
+            reason: req.body.reason,
+            scanId: req.body.scanId,
+            quarantinedAt: new Date()
+          }
+        }, { new: true });
+        if (!artifact) {
+          return res.status(404).json({
+            success: false,
+            error: 'Artifact not found'
+          });
+        }
+
+        res.json({
+          success: true,
+          data: artifact,
+          message: 'Artifact quarantined'
+        });
+      } catch (error) {
+        console.error('Artifact quarantine error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to quarantine artifact'
+        });
+      }
+    }
+  );
+
+  // DELETE /api/artifacts/:id/quarantine - Release an artifact once its findings are resolved
+  router.delete('/:id/quarantine',
+    requireServiceToken,
+    [artifactIdParam],
+    validateRequest,
+    async (req: Request, res: Response) => {
+      try {
+        const artifact = await Artifact.findByIdAndUpdate(req.params.id, { $unset: { quarantine: 1 } }, { new: true });
+        if (!artifact) {
+          return res.status(404).json({
+            success: false,
+            error: 'Artifact not found'
+          });
+        }
+
+        res.json({
+          success: true,
+          data: artifact,
+          message: 'Artifact released from quarantine'
+        });
+      } catch (error) {
+        console.error('Artifact release error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to release artifact'
+        });
+      }
+    }
+  );
+
+  // DELETE /api/artifacts/:id - Delete an artifact
+  router.delete('/:id',
+    requireServiceToken,
+    [artifactIdParam],
+    validateRequest,
+    async (req: Request, res: Response) => {
+      try {
+        const artifact = await Artifact.findByIdAndDelete(req.params.id);
+        if (!artifact) {
+          return res.status(404).json({
+            success: false,
+            error: 'Artifact not found'
+          });
+        }
+
+        await retention.deleteIfUnreferenced(artifact.storageKey);
+
+        res.json({
+          success: true,
+          message: 'Artifact deleted successfully'
+        });
+      } catch (error) {
+        console.error('Artifact delete error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to delete artifact'
+        });
+      }
+    }
+  );
+
+  return router;
+}
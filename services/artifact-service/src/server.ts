@@ -0,0 +1,118 @@
+import express from 'express';
+import cors from 'cors';
+import mongoose from 'mongoose';
+import dotenv from 'dotenv';
+import winston from 'winston';
+import { StorageService } from './services/StorageService.js';
+import { RetentionService } from './services/RetentionService.js';
+import { ArtifactDiffService } from './services/ArtifactDiffService.js';
+import createArtifactRoutes from './routes/artifacts.js';
+
+// Load environment variables
+dotenv.config();
+
+const app = express();
+const PORT = process.env.PORT || 3008;
+
+// Logger configuration
+const logger = winston.createLogger({
+  level: 'info',
+  format: winston.format.combine(
+    winston.format.timestamp(),
+    winston.format.json()
+  ),
+  transports: [
+    new winston.transports.Console(),
+    new winston.transports.File({ filename: 'logs/artifact-service.log' })
+  ]
+});
+
+const storage = new StorageService();
+const retention = new RetentionService(storage);
+
+// Database connection
+const MONGODB_URI = process.env.MONGODB_URI || 'mongodb://localhost:27017/ai-pipeline';
+
+mongoose.connect(MONGODB_URI)
+  .then(() => {
+    logger.info('🗄️  Connected to MongoDB');
+    retention.start(parseInt(process.env.RETENTION_SWEEP_INTERVAL_MS || '3600000'));
+  })
+  .catch((error) => {
+    logger.error('❌ MongoDB connection error:', error);
+    process.exit(1);
+  });
+
+storage.ensureBucket()
+  .then(() => {
+    logger.info('🪣 Artifact bucket ready');
+  })
+  .catch((error) => {
+    logger.error('❌ Object storage error:', error);
+  });
+
+// Middleware
+app.use(cors({
+  origin: process.env.FRONTEND_URL || 'http://localhost:5173',
+  credentials: true
+}));
+app.use(express.json({ limit: '100mb' })); // Code bundles can be large
+app.use(express.urlencoded({ extended: true }));
+
+// Request logging middleware
+app.use((req, res, next) => {
+  logger.info(`${req.method} ${req.path}`, {
+    ip: req.ip,
+    userAgent: req.get('User-Agent'),
+    timestamp: new Date().toISOString()
+  });
+  next();
+});
+
+// Routes
+app.use('/api/artifacts', createArtifactRoutes(storage, retention, new ArtifactDiffService(storage)));
+
+// Health check endpoint
+app.get('/health', (req, res) => {
+  res.json({
+    status: 'ok',
+    service: 'artifact-service',
+    timestamp: new Date().toISOString(),
+    version: '1.0.0',
+    database: mongoose.connection.readyState === 1 ? 'connected' : 'disconnected'
+  });
+});
+
+// Catch-all for undefined routes
+app.use('*', (req, res) => {
+  res.status(404).json({
+    success: false,
+    error: 'Route not found',
+    service: 'artifact-service'
+  });
+});
+
+// Global error handler
+app.use((err: any, req: any, res: any, next: any) => {
+  logger.error('Unhandled error:', err);
+  res.status(500).json({
+    success: false,
+    error: 'Internal server error',
+    service: 'artifact-service'
+  });
+});
+
+// Graceful shutdown
+process.on('SIGTERM', () => {
+  logger.info('SIGTERM received. Shutting down gracefully...');
+  retention.stop();
+  mongoose.connection.close();
+  process.exit(0);
+});
+
+// Start server
+app.listen(PORT, () => {
+  logger.info(`📦 Artifact Service running on port ${PORT}`);
+});
+
+export default app;
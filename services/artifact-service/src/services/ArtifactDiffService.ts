@@ -0,0 +1,270 @@
+import { Artifact, IArtifact } from '../models/Artifact.js';
+import { StorageService } from './StorageService.js';
+
+export type FileChange = 'added' | 'removed' | 'modified' | 'unchanged';
+
+export interface FileDiff {
+  path: string;
+  status: FileChange;
+  // Artifact the file came from on each side, for fetching full contents
+  baseArtifactId?: string;
+  headArtifactId?: string;
+  additions: number;
+  deletions: number;
+  // Binary content is compared by digest only
+  binary?: boolean;
+}
+
+export interface StructuralChange {
+  path: string;
+  kind: 'heading' | 'dependency' | 'json-key';
+  added: string[];
+  removed: string[];
+}
+
+export interface RunDiff {
+  projectId: string;
+  base: { runId: string; artifacts: number; files: number };
+  head: { runId: string; artifacts: number; files: number };
+  totals: Record<FileChange, number> & { additions: number; deletions: number };
+  // Changed files grouped by top-level directory and by extension
+  directories: Record<string, Record<Exclude<FileChange, 'unchanged'>, number>>;
+  extensions: Record<string, number>;
+  structure: StructuralChange[];
+  files: FileDiff[];
+}
+
+interface RunFile {
+  path: string;
+  artifactId: string;
+  sha256?: string;
+  load: () => Promise<string | Buffer>;
+}
+
+// Above this many line pairs the exact line diff is replaced by a multiset comparison
+const MAX_LCS_CELLS = parseInt(process.env.DIFF_MAX_LCS_CELLS || '4000000');
+
+// Compares what two runs of a project generated. Code bundles are expanded into their files,
+// using each run's latest bundle since that is its final code; other artifacts are compared
+// as one file each, keyed by stage and name.
+export class ArtifactDiffService {
+  constructor(private storage: StorageService) {}
+
+  async diffRuns(projectId: string, baseRunId: string, headRunId: string): Promise<RunDiff | null> {
+    const [baseArtifacts, headArtifacts] = await Promise.all([
+      Artifact.find({ projectId, runId: baseRunId }).sort({ createdAt: -1 }),
+      Artifact.find({ projectId, runId: headRunId }).sort({ createdAt: -1 })
+    ]);
+    if (baseArtifacts.length === 0 || headArtifacts.length === 0) return null;
+
+    const [baseFiles, headFiles] = await Promise.all([this.runFiles(baseArtifacts), this.runFiles(headArtifacts)]);
+    const paths = Array.from(new Set([...baseFiles.keys(), ...headFiles.keys()])).sort();
+
+    const files: FileDiff[] = [];
+    const structure: StructuralChange[] = [];
+    for (const path of paths) {
+      const diff = await this.diffFile(path, baseFiles.get(path), headFiles.get(path), structure);
+      files.push(diff);
+    }
+
+    return {
+      projectId,
+      base: { runId: baseRunId, artifacts: baseArtifacts.length, files: baseFiles.size },
+      head: { runId: headRunId, artifacts: headArtifacts.length, files: headFiles.size },
+      totals: {
+        added: files.filter(file => file.status === 'added').length,
+        removed: files.filter(file => file.status === 'removed').length,
+        modified: files.filter(file => file.status === 'modified').length,
+        unchanged: files.filter(file => file.status === 'unchanged').length,
+        additions: files.reduce((sum, file) => sum + file.additions, 0),
+        deletions: files.reduce((sum, file) => sum + file.deletions, 0)
+      },
+      ...summarize(files),
+      structure,
+      files
+    };
+  }
+
+  private async runFiles(artifacts: IArtifact[]): Promise<Map<string, RunFile>> {
+    const files = new Map<string, RunFile>();
+
+    // Artifacts are newest first, so the first bundle seen is the run's final code
+    const bundle = artifacts.find(artifact => artifact.type === 'code-bundle');
+    if (bundle) {
+      const content = JSON.parse((await this.storage.getContent(bundle.storageKey)).toString('utf-8'));
+      const entries = Array.isArray(content) ? content : content?.files;
+      for (const entry of Array.isArray(entries) ? entries : []) {
+        if (typeof entry?.path !== 'string') continue;
+        const text = typeof entry.content === 'string' ? entry.content : '';
+        files.set(entry.path, { path: entry.path, artifactId: String(bundle._id), load: async () => text });
+      }
+    }
+
+    for (const artifact of artifacts) {
+      if (artifact.type === 'code-bundle') continue;
+      const path = `${artifact.stageId || 'artifacts'}/${artifact.name}`;
+      if (files.has(path)) continue;
+      files.set(path, {
+        path,
+        artifactId: String(artifact._id),
+        sha256: artifact.sha256,
+        load: () => this.storage.getContent(artifact.storageKey)
+      });
+    }
+
+    return files;
+  }
+
+  private async diffFile(path: string, base: RunFile | undefined, head: RunFile | undefined, structure: StructuralChange[]): Promise<FileDiff> {
+    const diff: FileDiff = {
+      path,
+      status: 'unchanged',
+      baseArtifactId: base?.artifactId,
+      headArtifactId: head?.artifactId,
+      additions: 0,
+      deletions: 0
+    };
+
+    // Stored artifacts with the same digest are identical without loading them
+    if (base?.sha256 && base.sha256 === head?.sha256) return diff;
+
+    const [before, after] = await Promise.all([base?.load(), head?.load()]);
+    if (isBinary(before) || isBinary(after)) {
+      diff.binary = true;
+      diff.status = !base ? 'added' : !head ? 'removed' : 'modified';
+      return diff;
+    }
+
+    const beforeText = toText(before);
+    const afterText = toText(after);
+    if (base && head && beforeText === afterText) return diff;
+
+    diff.status = !base ? 'added' : !head ? 'removed' : 'modified';
+    const stats = lineStats(beforeText, afterText);
+    diff.additions = stats.additions;
+    diff.deletions = stats.deletions;
+
+    const change = structuralChange(path, beforeText, afterText);
+    if (change) structure.push(change);
+
+    return diff;
+  }
+}
+
+const isBinary = (content: string | Buffer | undefined) =>
+  Buffer.isBuffer(content) && content.subarray(0, 8000).includes(0);
+
+const toText = (content: string | Buffer | undefined): string | undefined =>
+  content === undefined ? undefined : Buffer.isBuffer(content) ? content.toString('utf-8') : content;
+
+const splitLines = (text: string | undefined) => (text ? text.replace(/\r\n/g, '\n').replace(/\n$/, '').split('\n') : []);
+
+// Added and removed line counts, from the longest common subsequence of the two versions
+export function lineStats(before: string | undefined, after: string | undefined): { additions: number; deletions: number } {
+  const a = splitLines(before);
+  const b = splitLines(after);
+
+  let start = 0;
+  while (start < a.length && start < b.length && a[start] === b[start]) start++;
+  let endA = a.length;
+  let endB = b.length;
+  while (endA > start && endB > start && a[endA - 1] === b[endB - 1]) {
+    endA--;
+    endB--;
+  }
+
+  const middleA = a.slice(start, endA);
+  const middleB = b.slice(start, endB);
+  if (middleA.length === 0 || middleB.length === 0) {
+    return { additions: middleB.length, deletions: middleA.length };
+  }
+
+  let common: number;
+  if (middleA.length * middleB.length <= MAX_LCS_CELLS) {
+    // One row at a time keeps memory linear in the shorter side
+    let previous = new Array(middleB.length + 1).fill(0);
+    for (let i = 1; i <= middleA.length; i++) {
+      const current = new Array(middleB.length + 1).fill(0);
+      for (let j = 1; j <= middleB.length; j++) {
+        current[j] = middleA[i - 1] === middleB[j - 1] ? previous[j - 1] + 1 : Math.max(previous[j], current[j - 1]);
+      }
+      previous = current;
+    }
+    common = previous[middleB.length];
+  } else {
+    // Large rewrites: count lines present on both sides, ignoring order
+    const counts = new Map<string, number>();
+    middleA.forEach(line => counts.set(line, (counts.get(line) || 0) + 1));
+    common = 0;
+    for (const line of middleB) {
+      const remaining = counts.get(line) || 0;
+      if (remaining > 0) {
+        counts.set(line, remaining - 1);
+        common++;
+      }
+    }
+  }
+
+  return { additions: middleB.length - common, deletions: middleA.length - common };
+}
+
+const setChange = (path: string, kind: StructuralChange['kind'], before: string[], after: string[]): StructuralChange | null => {
+  const added = after.filter(item => !before.includes(item));
+  const removed = before.filter(item => !after.includes(item));
+  return added.length > 0 || removed.length > 0 ? { path, kind, added, removed } : null;
+};
+
+const markdownHeadings = (text: string | undefined) =>
+  splitLines(text).filter(line => /^#{1,6}\s/.test(line)).map(line => line.trim());
+
+const parseJson = (text: string | undefined): any => {
+  try {
+    return text ? JSON.parse(text) : {};
+  } catch {
+    return undefined;
+  }
+};
+
+const dependencies = (manifest: any) => Object.entries({
+  ...manifest?.dependencies,
+  ...manifest?.devDependencies
+}).map(([name, version]) => `${name}@${version}`);
+
+// What changed in the shape of a file, beyond its lines: document sections, package
+// dependencies and top-level keys of other JSON files
+export function structuralChange(path: string, before: string | undefined, after: string | undefined): StructuralChange | null {
+  const name = path.split('/').pop() || path;
+
+  if (/\.(md|markdown)$/i.test(name)) {
+    return setChange(path, 'heading', markdownHeadings(before), markdownHeadings(after));
+  }
+
+  if (/\.json$/i.test(name)) {
+    const [a, b] = [parseJson(before), parseJson(after)];
+    if (a === undefined || b === undefined) return null;
+    if (name === 'package.json') return setChange(path, 'dependency', dependencies(a), dependencies(b));
+    if (a && b && typeof a === 'object' && typeof b === 'object' && !Array.isArray(a) && !Array.isArray(b)) {
+      return setChange(path, 'json-key', Object.keys(a), Object.keys(b));
+    }
+  }
+
+  return null;
+}
+
+function summarize(files: FileDiff[]): Pick<RunDiff, 'directories' | 'extensions'> {
+  const directories: RunDiff['directories'] = {};
+  const extensions: RunDiff['extensions'] = {};
+
+  for (const file of files) {
+    if (file.status === 'unchanged') continue;
+
+    const directory = file.path.includes('/') ? file.path.split('/')[0] : '.';
+    directories[directory] ||= { added: 0, removed: 0, modified: 0 };
+    directories[directory][file.status]++;
+
+    const extension = file.path.match(/\.([a-z0-9]+)$/i)?.[1].toLowerCase() || '(none)';
+    extensions[extension] = (extensions[extension] || 0) + 1;
+  }
+
+  return { directories, extensions };
+}
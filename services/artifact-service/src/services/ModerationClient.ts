@@ -0,0 +1,91 @@
+import axios from 'axios';
+
+const TEXT_CONTENT_TYPES = /^(text\/|application\/(json|xml|yaml|x-yaml|javascript|typescript))/;
+
+export interface ModerationOutcome {
+  decision: 'allowed' | 'flagged' | 'redacted' | 'blocked';
+  // The content to store: redactions applied
+  content: Buffer;
+  recordId?: string;
+  findings: any[];
+}
+
+interface UploadContext {
+  type: string;
+  name: string;
+  contentType: string;
+  projectId?: string;
+  runId?: string;
+  stageId?: string;
+}
+
+// Screens text artifacts with the moderation service before they are stored. Code bundles are
+// checked file by file so a redaction lands in the right file. Binary content is not screened.
+export class ModerationClient {
+  private baseUrl: string | undefined;
+
+  constructor(baseUrl?: string) {
+    this.baseUrl = baseUrl || process.env.MODERATION_SERVICE_URL;
+  }
+
+  isConfigured(): boolean {
+    return !!this.baseUrl;
+  }
+
+  screens(contentType: string): boolean {
+    return this.isConfigured() && TEXT_CONTENT_TYPES.test(contentType);
+  }
+
+  async check(content: Buffer, context: UploadContext): Promise<ModerationOutcome> {
+    const text = content.toString('utf-8');
+    const bundle = context.type === 'code-bundle' ? this.parseBundle(text) : undefined;
+    const items = bundle
+      ? bundle.files.map((file: any) => ({ name: file.path, content: typeof file.content === 'string' ? file.content : '' }))
+      : [{ name: context.name, content: text }];
+
+    let result: any;
+    try {
+      const response = await axios.post(`${this.baseUrl}/api/moderation/checks`, {
+        source: 'artifact',
+        items,
+        projectId: context.projectId,
+        runId: context.runId,
+        stageId: context.stageId
+      }, {
+        headers: { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' },
+        timeout: parseInt(process.env.MODERATION_TIMEOUT_MS || '30000')
+      });
+      result = response.data.data;
+    } catch (error) {
+      if (!this.failOpen) throw error;
+      console.error(`Moderation unavailable, artifact ${context.name} stored unscreened:`, error instanceof Error ? error.message : error);
+      return { decision: 'allowed', content, findings: [] };
+    }
+
+    let screened = content;
+    if (result.decision === 'redacted') {
+      if (bundle) {
+        result.items.forEach((item: { content: string }, index: number) => {
+          bundle.files[index].content = item.content;
+        });
+        screened = Buffer.from(JSON.stringify(bundle.root), 'utf-8');
+      } else {
+        screened = Buffer.from(result.items[0].content, 'utf-8');
+      }
+    }
+
+    return { decision: result.decision, content: screened, recordId: result.recordId, findings: result.findings };
+  }
+
+  // Bundles are either a list of files or an object with a files list
+  private parseBundle(text: string): { root: any; files: any[] } | undefined {
+    try {
+      const root = JSON.parse(text);
+      const files = Array.isArray(root) ? root : root?.files;
+      if (!Array.isArray(files)) return undefined;
+      return { root, files: files.filter((file: any) => typeof file?.path === 'string') };
+    } catch {
+      return undefined;
+    }
+  }
+}
@@ -0,0 +1,40 @@
+import { Artifact } from '../models/Artifact.js';
+import { StorageService } from './StorageService.js';
+
+export class RetentionService {
+  private timer: NodeJS.Timeout | null = null;
+
+  constructor(private storage: StorageService) {}
+
+  start(intervalMs: number): void {
+    this.timer = setInterval(() => {
+      this.sweep().catch(error => console.error('Retention sweep failed:', error));
+    }, intervalMs);
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = null;
+    }
+  }
+
+  // Removes expired artifact records and any stored objects no longer referenced
+  async sweep(): Promise<number> {
+    const expired = await Artifact.find({ expiresAt: { $lte: new Date() } }).limit(500);
+
+    for (const artifact of expired) {
+      await Artifact.deleteOne({ _id: artifact._id });
+      await this.deleteIfUnreferenced(artifact.storageKey);
+    }
+
+    return expired.length;
+  }
+
+  async deleteIfUnreferenced(storageKey: string): Promise<void> {
+    const references = await Artifact.countDocuments({ storageKey });
+    if (references === 0) {
+      await this.storage.delete(storageKey);
+    }
+  }
+}
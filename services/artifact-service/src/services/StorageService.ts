@@ -0,0 +1,101 @@
+import { createHash } from 'crypto';
+import {
+  S3Client,
+  PutObjectCommand,
+  HeadObjectCommand,
+  GetObjectCommand,
+  DeleteObjectCommand,
+  CreateBucketCommand,
+  HeadBucketCommand
+} from '@aws-sdk/client-s3';
+import { getSignedUrl } from '@aws-sdk/s3-request-presigner';
+
+export interface StoredObject {
+  sha256: string;
+  storageKey: string;
+  size: number;
+  deduplicated: boolean;
+}
+
+export class StorageService {
+  private client: S3Client;
+  private bucket: string;
+
+  constructor() {
+    this.bucket = process.env.S3_BUCKET || 'ai-pipeline-artifacts';
+    this.client = new S3Client({
+      region: process.env.S3_REGION || 'us-east-1',
+      endpoint: process.env.S3_ENDPOINT,
+      forcePathStyle: process.env.S3_FORCE_PATH_STYLE !== 'false',
+      credentials: process.env.S3_ACCESS_KEY_ID ? {
+        accessKeyId: process.env.S3_ACCESS_KEY_ID,
+        secretAccessKey: process.env.S3_SECRET_ACCESS_KEY || ''
+      } : undefined
+    });
+  }
+
+  async ensureBucket(): Promise<void> {
+    try {
+      await this.client.send(new HeadBucketCommand({ Bucket: this.bucket }));
+    } catch {
+      await this.client.send(new CreateBucketCommand({ Bucket: this.bucket }));
+    }
+  }
+
+  // Stores content under its SHA-256 digest so identical uploads share one object
+  async putContent(content: Buffer, contentType: string): Promise<StoredObject> {
+    const sha256 = createHash('sha256').update(content).digest('hex');
+    const storageKey = `sha256/${sha256.slice(0, 2)}/${sha256}`;
+
+    if (await this.exists(storageKey)) {
+      return { sha256, storageKey, size: content.length, deduplicated: true };
+    }
+
+    await this.client.send(new PutObjectCommand({
+      Bucket: this.bucket,
+      Key: storageKey,
+      Body: content,
+      ContentType: contentType,
+      ChecksumSHA256: Buffer.from(sha256, 'hex').toString('base64')
+    }));
+
+    return { sha256, storageKey, size: content.length, deduplicated: false };
+  }
+
+  async getContent(storageKey: string): Promise<Buffer> {
+    const { Body } = await this.client.send(new GetObjectCommand({
+      Bucket: this.bucket,
+      Key: storageKey
+    }));
+
+    if (!Body) throw new Error(`Object ${storageKey} has no content`);
+    return Buffer.from(await Body.transformToByteArray());
+  }
+
+  async getDownloadUrl(storageKey: string, fileName: string, expiresIn: number): Promise<string> {
+    return getSignedUrl(this.client, new GetObjectCommand({
+      Bucket: this.bucket,
+      Key: storageKey,
+      ResponseContentDisposition: `attachment; filename="${fileName.replace(/"/g, '')}"`
+    }), { expiresIn });
+  }
+
+  async delete(storageKey: string): Promise<void> {
+    await this.client.send(new DeleteObjectCommand({
+      Bucket: this.bucket,
+      Key: storageKey
+    }));
+  }
+
+  private async exists(storageKey: string): Promise<boolean> {
+    try {
+      await this.client.send(new HeadObjectCommand({ Bucket: this.bucket, Key: storageKey }));
+      return true;
+    } catch (error: any) {
+      if (error?.$metadata?.httpStatusCode === 404 || error?.name === 'NotFound') {
+        return false;
+      }
+      throw error;
+    }
+  }
+}
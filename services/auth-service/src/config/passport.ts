@@ -7,12 +7,24 @@ import { User, IUser } from '../models/User.js';
 const hasGitHubCredentials = process.env.GITHUB_CLIENT_ID && process.env.GITHUB_CLIENT_SECRET;
 const hasGoogleCredentials = process.env.GOOGLE_CLIENT_ID && process.env.GOOGLE_CLIENT_SECRET;
 
+type ProfileEmail = { value: string; primary?: boolean; verified?: boolean | string };
+
+// The address the provider has verified, preferring the primary one. Only a verified address may
+// link a sign-in to an existing account: accounts are matched by email, including ones an
+// identity provider created over SCIM, and an unverified address says nothing about who owns it.
+const verifiedEmail = (emails?: ProfileEmail[]): string | undefined => {
+  const verified = (emails || []).filter(email => email.verified === true || email.verified === 'true');
+  return (verified.find(email => email.primary) || verified[0])?.value?.toLowerCase();
+};
+
 // GitHub OAuth Strategy
 if (hasGitHubCredentials) {
   passport.use(new GitHubStrategy({
     clientID: process.env.GITHUB_CLIENT_ID!,
     clientSecret: process.env.GITHUB_CLIENT_SECRET!,
-    callbackURL: "/api/auth/github/callback"
+    callbackURL: "/api/auth/github/callback",
+    // Every address with its verified flag, not just the primary one
+    allRawEmails: true
   }, async (accessToken: string, refreshToken: string, profile: any, done: any) => {
   try {
     // Check if user already exists with GitHub ID
@@ -32,8 +44,8 @@ if (hasGitHubCredentials) {
       return done(null, user);
     }
 
-    // Check if user exists with same email
-    const email = profile.emails?.[0]?.value;
+    // Check if user exists with the same verified email
+    const email = verifiedEmail(profile.emails);
     if (email) {
       user = await User.findOne({ email });
       if (user) {
@@ -107,8 +119,8 @@ if (hasGoogleCredentials) {
       return done(null, user);
     }
 
-    // Check if user exists with same email
-    const email = profile.emails?.[0]?.value;
+    // Check if user exists with the same verified email
+    const email = verifiedEmail(profile.emails);
     if (email) {
       user = await User.findOne({ email });
       if (user) {
@@ -1,17 +1,41 @@
 import jwt from 'jsonwebtoken';
 import { Request, Response, NextFunction } from 'express';
 import { User, IUser } from '../models/User.js';
+import { Session } from '../models/Session.js';
 import '../types/express.js';
 
 export interface AuthenticatedRequest extends Request {
   user?: IUser;
+  // Dashboard session the access token belongs to
+  sessionId?: string;
 }
 
-export const generateToken = (userId: string): string => {
+interface TokenPayload {
+  userId: string;
+  sid?: string;
+}
+
+// Access tokens are short-lived and renewed with /refresh. Revoking a session also stops its tokens
+// early: requireAuth and /verify check the session on every request, and the gateway re-checks it
+// within SESSION_CHECK_TTL_MS.
+export const accessTokenLifetime = () => process.env.JWT_EXPIRES_IN || '15m';
+
+export const generateToken = (userId: string, sessionId?: string): string => {
   const secret = process.env.JWT_SECRET || 'fallback-secret-change-in-production';
-  const expiresIn = process.env.JWT_EXPIRES_IN || '7d';
+  const expiresIn = accessTokenLifetime();
   
-  return jwt.sign({ userId }, secret, { expiresIn } as jwt.SignOptions);
+  return jwt.sign(sessionId ? { userId, sid: sessionId } : { userId }, secret, { expiresIn } as jwt.SignOptions);
+};
+
+// Resolves a verified token to its user; tokens issued for a session die with the session
+const resolveUser = async (decoded: TokenPayload): Promise<IUser | null> => {
+  const [user, sessionActive] = await Promise.all([
+    User.findById(decoded.userId),
+    decoded.sid
+      ? Session.exists({ _id: decoded.sid, revokedAt: { $exists: false }, expiresAt: { $gt: new Date() } })
+      : Promise.resolve(true)
+  ]);
+  return user && user.isActive && sessionActive ? user : null;
 };
 
 export const authenticateToken = async (
@@ -32,10 +56,10 @@ export const authenticateToken = async (
 
   try {
     const secret = process.env.JWT_SECRET || 'fallback-secret-change-in-production';
-    const decoded = jwt.verify(token, secret) as { userId: string };
+    const decoded = jwt.verify(token, secret) as TokenPayload;
     
-    const user = await User.findById(decoded.userId);
-    if (!user || !user.isActive) {
+    const user = await resolveUser(decoded);
+    if (!user) {
       res.status(401).json({
         success: false,
         error: 'Invalid or expired token'
@@ -44,6 +68,7 @@ export const authenticateToken = async (
     }
 
     req.user = user;
+    req.sessionId = decoded.sid;
     next();
   } catch (error) {
     res.status(403).json({
@@ -66,11 +91,12 @@ export const optionalAuth = async (
   if (token) {
     try {
       const secret = process.env.JWT_SECRET || 'fallback-secret-change-in-production';
-      const decoded = jwt.verify(token, secret) as { userId: string };
+      const decoded = jwt.verify(token, secret) as TokenPayload;
       
-      const user = await User.findById(decoded.userId);
-      if (user && user.isActive) {
+      const user = await resolveUser(decoded);
+      if (user) {
         req.user = user;
+        req.sessionId = decoded.sid;
       }
     } catch (error) {
       // Ignore invalid tokens in optional auth
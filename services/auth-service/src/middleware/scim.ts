@@ -0,0 +1,37 @@
+import { Request, Response, NextFunction } from 'express';
+import { Organization, IOrganization } from '../models/Organization.js';
+import { hashScimToken, scimError } from '../services/scim.js';
+
+export interface ScimRequest extends Request {
+  organization?: IOrganization;
+}
+
+// Authenticates an identity provider by its organization's SCIM bearer token. The organization
+// it resolves to bounds everything the provider can see or change.
+export const requireScimToken = async (
+  req: ScimRequest,
+  res: Response,
+  next: NextFunction
+): Promise<void> => {
+  const authHeader = req.headers.authorization;
+  const token = authHeader?.startsWith('Bearer ') ? authHeader.slice(7).trim() : undefined;
+
+  if (!token) {
+    scimError(res, 401, 'SCIM bearer token required');
+    return;
+  }
+
+  try {
+    const organization = await Organization.findOne({ 'scim.tokenHash': hashScimToken(token) });
+    if (!organization) {
+      scimError(res, 401, 'Invalid SCIM token');
+      return;
+    }
+
+    req.organization = organization;
+    next();
+  } catch (error) {
+    console.error('SCIM authentication error:', error);
+    scimError(res, 500, 'Failed to authenticate SCIM request');
+  }
+};
@@ -0,0 +1,92 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export type OrganizationRole = 'owner' | 'admin' | 'member';
+
+export interface IOrganizationMember {
+  userId: mongoose.Types.ObjectId;
+  role: OrganizationRole;
+  joinedAt: Date;
+}
+
+export interface IOrganization extends Document {
+  name: string;
+  slug: string;
+  description: string;
+  members: IOrganizationMember[];
+  // Bearer token the organization's identity provider uses for SCIM provisioning
+  scim?: {
+    tokenHash?: string;
+    tokenPrefix?: string;
+    enabledAt?: Date;
+  };
+  createdBy: mongoose.Types.ObjectId;
+  createdAt: Date;
+  updatedAt: Date;
+  getMemberRole(userId: string): OrganizationRole | null;
+}
+
+const OrganizationMemberSchema = new Schema({
+  userId: {
+    type: Schema.Types.ObjectId,
+    ref: 'User',
+    required: true
+  },
+  role: {
+    type: String,
+    enum: ['owner', 'admin', 'member'],
+    default: 'member'
+  },
+  joinedAt: {
+    type: Date,
+    default: Date.now
+  }
+}, { _id: false });
+
+const OrganizationSchema: Schema = new Schema({
+  name: {
+    type: String,
+    required: true,
+    trim: true,
+    maxlength: 100
+  },
+  slug: {
+    type: String,
+    required: true,
+    unique: true,
+    lowercase: true,
+    trim: true,
+    match: /^[a-z0-9][a-z0-9-]{1,48}$/
+  },
+  description: {
+    type: String,
+    default: '',
+    maxlength: 500
+  },
+  members: [OrganizationMemberSchema],
+  scim: {
+    tokenHash: {
+      type: String,
+      select: false
+    },
+    tokenPrefix: String,
+    enabledAt: Date
+  },
+  createdBy: {
+    type: Schema.Types.ObjectId,
+    ref: 'User',
+    required: true
+  }
+}, {
+  timestamps: true
+});
+
+OrganizationSchema.methods.getMemberRole = function(this: IOrganization, userId: string): OrganizationRole | null {
+  const member = this.members.find(m => m.userId.toString() === userId);
+  return member ? member.role : null;
+};
+
+OrganizationSchema.index({ 'members.userId': 1 });
+OrganizationSchema.index({ 'scim.tokenHash': 1 }, { sparse: true });
+
+export const Organization = mongoose.model<IOrganization>('Organization', OrganizationSchema);
+export default Organization;
@@ -0,0 +1,53 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+// Links a user to an organization's identity provider. The SCIM User resource is this record plus
+// the user it points at; `active` is the provider's view, which may differ per organization.
+export interface IScimIdentity extends Document {
+  organizationId: mongoose.Types.ObjectId;
+  userId: mongoose.Types.ObjectId;
+  userName: string;
+  externalId?: string;
+  active: boolean;
+  deactivatedAt?: Date;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const ScimIdentitySchema: Schema = new Schema({
+  organizationId: {
+    type: Schema.Types.ObjectId,
+    ref: 'Organization',
+    required: true
+  },
+  userId: {
+    type: Schema.Types.ObjectId,
+    ref: 'User',
+    required: true
+  },
+  // SCIM userNames compare case-insensitively
+  userName: {
+    type: String,
+    required: true,
+    trim: true,
+    lowercase: true
+  },
+  externalId: {
+    type: String,
+    trim: true
+  },
+  active: {
+    type: Boolean,
+    default: true
+  },
+  deactivatedAt: {
+    type: Date
+  }
+}, {
+  timestamps: true
+});
+
+ScimIdentitySchema.index({ organizationId: 1, userId: 1 }, { unique: true });
+ScimIdentitySchema.index({ organizationId: 1, userName: 1 }, { unique: true });
+
+export const ScimIdentity = mongoose.model<IScimIdentity>('ScimIdentity', ScimIdentitySchema);
+export default ScimIdentity;
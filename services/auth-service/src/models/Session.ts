@@ -0,0 +1,72 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+// A dashboard sign-in. Access tokens carry the session ID, so revoking the session revokes them too.
+// The refresh token rotates on every use; the session keeps the current token's hash and the
+// previous one, so replaying a rotated token is recognised as theft.
+export interface ISession extends Document {
+  userId: mongoose.Types.ObjectId;
+  refreshTokenHash: string;
+  previousTokenHash?: string;
+  userAgent?: string;
+  ip?: string;
+  lastUsedAt: Date;
+  expiresAt: Date;
+  revokedAt?: Date;
+  revokedReason?: 'logout' | 'revoked' | 'reuse' | 'password-change';
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const SessionSchema: Schema = new Schema({
+  userId: {
+    type: Schema.Types.ObjectId,
+    ref: 'User',
+    required: true
+  },
+  refreshTokenHash: {
+    type: String,
+    required: true,
+    unique: true
+  },
+  previousTokenHash: {
+    type: String
+  },
+  userAgent: {
+    type: String,
+    maxlength: 500
+  },
+  ip: {
+    type: String
+  },
+  lastUsedAt: {
+    type: Date,
+    default: Date.now
+  },
+  expiresAt: {
+    type: Date,
+    required: true
+  },
+  revokedAt: {
+    type: Date
+  },
+  revokedReason: {
+    type: String,
+    enum: ['logout', 'revoked', 'reuse', 'password-change']
+  }
+}, {
+  timestamps: true,
+  toJSON: {
+    transform: function(doc, ret) {
+      const { refreshTokenHash, previousTokenHash, ...session } = ret;
+      return session;
+    }
+  }
+});
+
+SessionSchema.index({ userId: 1, revokedAt: 1 });
+SessionSchema.index({ previousTokenHash: 1 }, { sparse: true });
+// Expired sessions are removed by MongoDB
+SessionSchema.index({ expiresAt: 1 }, { expireAfterSeconds: 0 });
+
+export const Session = mongoose.model<ISession>('Session', SessionSchema);
+export default Session;
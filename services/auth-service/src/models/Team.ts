@@ -0,0 +1,55 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export interface ITeam extends Document {
+  organizationId: mongoose.Types.ObjectId;
+  name: string;
+  slug: string;
+  description: string;
+  members: mongoose.Types.ObjectId[];
+  // Group ID in the organization's identity provider, for teams managed over SCIM
+  externalId?: string;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const TeamSchema: Schema = new Schema({
+  organizationId: {
+    type: Schema.Types.ObjectId,
+    ref: 'Organization',
+    required: true
+  },
+  name: {
+    type: String,
+    required: true,
+    trim: true,
+    maxlength: 100
+  },
+  slug: {
+    type: String,
+    required: true,
+    lowercase: true,
+    trim: true,
+    match: /^[a-z0-9][a-z0-9-]{1,48}$/
+  },
+  description: {
+    type: String,
+    default: '',
+    maxlength: 500
+  },
+  members: [{
+    type: Schema.Types.ObjectId,
+    ref: 'User'
+  }],
+  externalId: {
+    type: String,
+    trim: true
+  }
+}, {
+  timestamps: true
+});
+
+TeamSchema.index({ organizationId: 1, slug: 1 }, { unique: true });
+TeamSchema.index({ members: 1 });
+
+export const Team = mongoose.model<ITeam>('Team', TeamSchema);
+export default Team;
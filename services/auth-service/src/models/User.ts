@@ -29,11 +29,19 @@ export interface IUser extends Document {
       };
     };
   };
+  // Set when an organization's identity provider created the account over SCIM. Such accounts are
+  // IdP-managed: the provider owns their profile, email and activation, and since it asserts the
+  // email without verifying it, OAuth sign-ins link to them only through a provider-verified email
+  provisioning?: {
+    source: 'scim';
+    organizationId: mongoose.Types.ObjectId;
+  };
   lastLogin?: Date;
   isActive: boolean;
   createdAt: Date;
   updatedAt: Date;
   comparePassword(candidatePassword: string): Promise<boolean>;
+  isIdpManaged(): boolean;
 }
 
 const UserSchema: Schema = new Schema({
@@ -55,8 +63,9 @@ const UserSchema: Schema = new Schema({
   password: {
     type: String,
     required: function(this: IUser) {
-      // Password is only required if no OAuth providers are configured
-      return !this.oauth?.providers?.github && !this.oauth?.providers?.google;
+      // Password is only required if no OAuth providers are configured; IdP-managed users sign
+      // in through an OAuth provider that has verified the account's email
+      return !this.oauth?.providers?.github && !this.oauth?.providers?.google && !this.isIdpManaged();
     },
     minlength: 6
   },
@@ -103,6 +112,16 @@ const UserSchema: Schema = new Schema({
       }
     }
   },
+  provisioning: {
+    source: {
+      type: String,
+      enum: ['scim']
+    },
+    organizationId: {
+      type: Schema.Types.ObjectId,
+      ref: 'Organization'
+    }
+  },
   lastLogin: {
     type: Date
   },
@@ -139,6 +158,10 @@ UserSchema.methods.comparePassword = async function(candidatePassword: string):
   return bcrypt.compare(candidatePassword, this.password);
 };
 
+UserSchema.methods.isIdpManaged = function(this: IUser): boolean {
+  return this.provisioning?.source === 'scim';
+};
+
 // Indexes for better query performance
 UserSchema.index({ email: 1 });
 UserSchema.index({ username: 1 });
@@ -1,12 +1,21 @@
 import express, { Request, Response } from 'express';
-import { body, validationResult } from 'express-validator';
+import { body, param, validationResult } from 'express-validator';
 import passport from '../config/passport.js';
 import { User } from '../models/User.js';
+import { Session } from '../models/Session.js';
 import { 
-  generateToken, 
   requireAuth, 
+  optionalAuth,
   AuthenticatedRequest 
 } from '../middleware/auth.js';
+import { buildPrincipal } from '../services/principal.js';
+import {
+  createSession,
+  refreshSession,
+  revokeSession,
+  revokeSessionByRefreshToken,
+  revokeUserSessions
+} from '../services/sessions.js';
 import '../types/express.js';
 
 const router = express.Router();
@@ -67,14 +76,14 @@ router.post('/register',
 
       await user.save();
 
-      // Generate token
-      const token = generateToken((user._id as any).toString());
+      // Start a dashboard session
+      const session = await createSession(user, req);
 
       res.status(201).json({
         success: true,
         data: {
           user: user.toJSON(),
-          token
+          ...session
         },
         message: 'User registered successfully'
       });
@@ -130,14 +139,14 @@ router.post('/login',
       user.lastLogin = new Date();
       await user.save();
 
-      // Generate token
-      const token = generateToken((user._id as any).toString());
+      // Start a dashboard session
+      const session = await createSession(user, req);
 
       res.json({
         success: true,
         data: {
           user: user.toJSON(),
-          token
+          ...session
         }
       });
     } catch (error) {
@@ -244,9 +253,10 @@ router.post('/change-password', requireAuth,
         });
       }
 
-      // Update password
+      // Update password and sign out every other session
       user.password = newPassword;
       await user.save();
+      await revokeUserSessions(String(user._id), 'password-change', (req as AuthenticatedRequest).sessionId);
 
       res.json({
         success: true,
@@ -281,14 +291,15 @@ router.get('/github/callback', (req: Request, res: Response, next) => {
   }
   
   passport.authenticate('github', { session: false, failureRedirect: '/login?error=github_auth_failed' })(req, res, next);
-}, (req: Request, res: Response) => {
+}, async (req: Request, res: Response) => {
     try {
       const user = req.user as any;
-      const token = generateToken(user._id.toString());
+      const session = await createSession(user, req);
       
-      // Redirect to frontend with token
+      // Redirect to frontend with token; the refresh token goes in the fragment, which browsers
+      // never send to servers or in Referer headers
       const frontendUrl = process.env.FRONTEND_URL || 'http://localhost:5173';
-      res.redirect(`${frontendUrl}?token=${token}&login=success`);
+      res.redirect(`${frontendUrl}?token=${session.token}&login=success#refreshToken=${session.refreshToken}`);
     } catch (error) {
       console.error('GitHub OAuth callback error:', error);
       res.redirect('/login?error=github_callback_failed');
@@ -313,14 +324,15 @@ router.get('/google/callback', (req: Request, res: Response, next) => {
   }
   
   passport.authenticate('google', { session: false, failureRedirect: '/login?error=google_auth_failed' })(req, res, next);
-}, (req: Request, res: Response) => {
+}, async (req: Request, res: Response) => {
     try {
       const user = req.user as any;
-      const token = generateToken(user._id.toString());
+      const session = await createSession(user, req);
       
-      // Redirect to frontend with token
+      // Redirect to frontend with token; the refresh token goes in the fragment, which browsers
+      // never send to servers or in Referer headers
       const frontendUrl = process.env.FRONTEND_URL || 'http://localhost:5173';
-      res.redirect(`${frontendUrl}?token=${token}&login=success`);
+      res.redirect(`${frontendUrl}?token=${session.token}&login=success#refreshToken=${session.refreshToken}`);
     } catch (error) {
       console.error('Google OAuth callback error:', error);
       res.redirect('/login?error=google_callback_failed');
@@ -328,14 +340,127 @@ router.get('/google/callback', (req: Request, res: Response, next) => {
   }
 );
 
-// POST /api/auth/logout - Logout (mainly for session cleanup)
-router.post('/logout', (req: Request, res: Response) => {
-  res.json({
-    success: true,
-    message: 'Logged out successfully'
-  });
+// POST /api/auth/refresh - Exchange a refresh token for a new access/refresh pair (rotation)
+router.post('/refresh',
+  [body('refreshToken').isString().notEmpty().withMessage('Refresh token is required')],
+  async (req: Request, res: Response) => {
+    try {
+      const errors = validationResult(req);
+      if (!errors.isEmpty()) {
+        return res.status(400).json({
+          success: false,
+          error: 'Validation failed',
+          details: errors.array()
+        });
+      }
+
+      const session = await refreshSession(req.body.refreshToken, req);
+      if (!session) {
+        return res.status(401).json({
+          success: false,
+          error: 'Invalid or expired refresh token'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: session
+      });
+    } catch (error) {
+      console.error('Refresh session error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to refresh session'
+      });
+    }
+  }
+);
+
+// POST /api/auth/logout - Revoke the current session (by access token or refresh token)
+router.post('/logout', optionalAuth, async (req: AuthenticatedRequest, res: Response) => {
+  try {
+    if (typeof req.body?.refreshToken === 'string') {
+      await revokeSessionByRefreshToken(req.body.refreshToken);
+    }
+    if (req.sessionId) {
+      const session = await Session.findById(req.sessionId);
+      if (session) await revokeSession(session, 'logout');
+    }
+
+    res.json({
+      success: true,
+      message: 'Logged out successfully'
+    });
+  } catch (error) {
+    console.error('Logout error:', error);
+    res.status(500).json({
+      success: false,
+      error: 'Failed to logout'
+    });
+  }
+});
+
+// GET /api/auth/sessions - Active sessions for the current user
+router.get('/sessions', requireAuth, async (req: AuthenticatedRequest, res: Response) => {
+  try {
+    const sessions = await Session.find({ userId: req.user!._id, revokedAt: { $exists: false } })
+      .sort({ lastUsedAt: -1 })
+      .limit(100);
+
+    res.json({
+      success: true,
+      data: sessions.map(session => ({
+        ...session.toJSON(),
+        current: String(session._id) === req.sessionId
+      }))
+    });
+  } catch (error) {
+    console.error('List sessions error:', error);
+    res.status(500).json({
+      success: false,
+      error: 'Failed to list sessions'
+    });
+  }
 });
 
+// DELETE /api/auth/sessions/:id - Revoke one of the current user's sessions
+router.delete('/sessions/:id', requireAuth,
+  [param('id').isMongoId().withMessage('Invalid session ID')],
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const errors = validationResult(req);
+      if (!errors.isEmpty()) {
+        return res.status(400).json({
+          success: false,
+          error: 'Validation failed',
+          details: errors.array()
+        });
+      }
+
+      const session = await Session.findOne({ _id: req.params.id, userId: req.user!._id });
+      if (!session) {
+        return res.status(404).json({
+          success: false,
+          error: 'Session not found'
+        });
+      }
+
+      await revokeSession(session, 'revoked');
+
+      res.json({
+        success: true,
+        message: 'Session revoked'
+      });
+    } catch (error) {
+      console.error('Revoke session error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to revoke session'
+      });
+    }
+  }
+);
+
 // GET /api/auth/verify - Verify token (for other microservices)
 router.get('/verify', requireAuth, (req: AuthenticatedRequest, res: Response) => {
   const user = (req as AuthenticatedRequest).user!;
@@ -351,4 +476,22 @@ router.get('/verify', requireAuth, (req: AuthenticatedRequest, res: Response) =>
   });
 });
 
+// GET /api/auth/principal - Resolved identity with organization and team memberships
+// Used by other services for authorization decisions and audit attribution
+router.get('/principal', requireAuth, async (req: AuthenticatedRequest, res: Response) => {
+  try {
+    const principal = await buildPrincipal(req.user!);
+    res.json({
+      success: true,
+      data: principal
+    });
+  } catch (error) {
+    console.error('Get principal error:', error);
+    res.status(500).json({
+      success: false,
+      error: 'Failed to resolve principal'
+    });
+  }
+});
+
 export default router;
\ No newline at end of file
@@ -0,0 +1,536 @@
+import express, { Request, Response } from 'express';
+import { body, param, validationResult } from 'express-validator';
+import { Organization, IOrganization, OrganizationRole } from '../models/Organization.js';
+import { Team } from '../models/Team.js';
+import { User } from '../models/User.js';
+import { generateScimToken, hashScimToken, SCIM_BASE_URL } from '../services/scim.js';
+import { requireAuth, AuthenticatedRequest } from '../middleware/auth.js';
+import '../types/express.js';
+
+const router = express.Router();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const slugRule = (field: string) => param(field).matches(/^[a-z0-9][a-z0-9-]{1,48}$/).withMessage('Invalid slug');
+const MANAGER_ROLES: OrganizationRole[] = ['owner', 'admin'];
+
+// Loads an organization the current user belongs to; `roles` restricts who may proceed
+const loadOrganization = async (
+  req: AuthenticatedRequest,
+  res: Response,
+  roles?: OrganizationRole[]
+): Promise<IOrganization | null> => {
+  const organization = await Organization.findOne({ slug: req.params.slug });
+  const role = organization?.getMemberRole(String(req.user!._id));
+  const isAdmin = req.user!.role === 'admin';
+
+  if (!organization || (!role && !isAdmin)) {
+    res.status(404).json({
+      success: false,
+      error: 'Organization not found'
+    });
+    return null;
+  }
+
+  if (roles && !isAdmin && (!role || !roles.includes(role))) {
+    res.status(403).json({
+      success: false,
+      error: 'Insufficient organization permissions'
+    });
+    return null;
+  }
+
+  return organization;
+};
+
+router.use(requireAuth);
+
+// POST /api/organizations - Create an organization; the creator becomes its owner
+router.post('/',
+  [
+    body('name').trim().isLength({ min: 1, max: 100 }).withMessage('Organization name is required'),
+    body('slug').matches(/^[a-z0-9][a-z0-9-]{1,48}$/).withMessage('Slug must be 2-49 lowercase letters, digits or hyphens'),
+    body('description').optional().isString().isLength({ max: 500 }).withMessage('Description must be less than 500 characters')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const existing = await Organization.findOne({ slug: req.body.slug });
+      if (existing) {
+        return res.status(409).json({
+          success: false,
+          error: 'Organization slug already taken'
+        });
+      }
+
+      const organization = new Organization({
+        name: req.body.name,
+        slug: req.body.slug,
+        description: req.body.description || '',
+        createdBy: req.user!._id,
+        members: [{ userId: req.user!._id, role: 'owner' }]
+      });
+      await organization.save();
+
+      res.status(201).json({
+        success: true,
+        data: organization,
+        message: 'Organization created successfully'
+      });
+    } catch (error) {
+      console.error('Create organization error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to create organization'
+      });
+    }
+  }
+);
+
+// GET /api/organizations - Organizations the current user belongs to
+router.get('/', async (req: AuthenticatedRequest, res: Response) => {
+  try {
+    const organizations = await Organization.find({ 'members.userId': req.user!._id }).sort({ name: 1 });
+
+    res.json({
+      success: true,
+      data: organizations
+    });
+  } catch (error) {
+    console.error('List organizations error:', error);
+    res.status(500).json({
+      success: false,
+      error: 'Failed to list organizations'
+    });
+  }
+});
+
+// GET /api/organizations/:slug - Organization with member details
+router.get('/:slug',
+  [slugRule('slug')],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const organization = await loadOrganization(req, res);
+      if (!organization) return;
+
+      await organization.populate('members.userId', 'username email profile');
+
+      res.json({
+        success: true,
+        data: organization
+      });
+    } catch (error) {
+      console.error('Get organization error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to get organization'
+      });
+    }
+  }
+);
+
+// POST /api/organizations/:slug/members - Add a user by email
+router.post('/:slug/members',
+  [
+    slugRule('slug'),
+    body('email').isEmail().normalizeEmail().withMessage('Valid email is required'),
+    body('role').optional().isIn(['admin', 'member']).withMessage('Role must be admin or member')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const organization = await loadOrganization(req, res, MANAGER_ROLES);
+      if (!organization) return;
+
+      const user = await User.findOne({ email: req.body.email, isActive: true });
+      if (!user) {
+        return res.status(404).json({
+          success: false,
+          error: 'User not found'
+        });
+      }
+
+      if (organization.getMemberRole(String(user._id))) {
+        return res.status(409).json({
+          success: false,
+          error: 'User is already a member'
+        });
+      }
+
+      organization.members.push({ userId: user._id as any, role: req.body.role || 'member', joinedAt: new Date() });
+      await organization.save();
+
+      res.status(201).json({
+        success: true,
+        data: organization,
+        message: 'Member added successfully'
+      });
+    } catch (error) {
+      console.error('Add organization member error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to add member'
+      });
+    }
+  }
+);
+
+// PATCH /api/organizations/:slug/members/:userId - Change a member's role
+router.patch('/:slug/members/:userId',
+  [
+    slugRule('slug'),
+    param('userId').isMongoId().withMessage('Invalid user ID'),
+    body('role').isIn(['owner', 'admin', 'member']).withMessage('Role must be owner, admin or member')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const organization = await loadOrganization(req, res, ['owner']);
+      if (!organization) return;
+
+      const member = organization.members.find(m => m.userId.toString() === req.params.userId);
+      if (!member) {
+        return res.status(404).json({
+          success: false,
+          error: 'Member not found'
+        });
+      }
+
+      const owners = organization.members.filter(m => m.role === 'owner');
+      if (member.role === 'owner' && req.body.role !== 'owner' && owners.length === 1) {
+        return res.status(400).json({
+          success: false,
+          error: 'An organization must keep at least one owner'
+        });
+      }
+
+      member.role = req.body.role;
+      await organization.save();
+
+      res.json({
+        success: true,
+        data: organization,
+        message: 'Member role updated successfully'
+      });
+    } catch (error) {
+      console.error('Update organization member error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to update member'
+      });
+    }
+  }
+);
+
+// DELETE /api/organizations/:slug/members/:userId - Remove a member (or leave)
+router.delete('/:slug/members/:userId',
+  [
+    slugRule('slug'),
+    param('userId').isMongoId().withMessage('Invalid user ID')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const leaving = req.params.userId === String(req.user!._id);
+      const organization = await loadOrganization(req, res, leaving ? undefined : MANAGER_ROLES);
+      if (!organization) return;
+
+      const member = organization.members.find(m => m.userId.toString() === req.params.userId);
+      if (!member) {
+        return res.status(404).json({
+          success: false,
+          error: 'Member not found'
+        });
+      }
+
+      if (member.role === 'owner' && organization.members.filter(m => m.role === 'owner').length === 1) {
+        return res.status(400).json({
+          success: false,
+          error: 'An organization must keep at least one owner'
+        });
+      }
+
+      organization.members = organization.members.filter(m => m.userId.toString() !== req.params.userId);
+      await organization.save();
+      await Team.updateMany({ organizationId: organization._id }, { $pull: { members: member.userId } });
+
+      res.json({
+        success: true,
+        message: 'Member removed successfully'
+      });
+    } catch (error) {
+      console.error('Remove organization member error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to remove member'
+      });
+    }
+  }
+);
+
+// POST /api/organizations/:slug/teams - Create a team
+router.post('/:slug/teams',
+  [
+    slugRule('slug'),
+    body('name').trim().isLength({ min: 1, max: 100 }).withMessage('Team name is required'),
+    body('slug').matches(/^[a-z0-9][a-z0-9-]{1,48}$/).withMessage('Slug must be 2-49 lowercase letters, digits or hyphens'),
+    body('description').optional().isString().isLength({ max: 500 }).withMessage('Description must be less than 500 characters')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const organization = await loadOrganization(req, res, MANAGER_ROLES);
+      if (!organization) return;
+
+      const existing = await Team.findOne({ organizationId: organization._id, slug: req.body.slug });
+      if (existing) {
+        return res.status(409).json({
+          success: false,
+          error: 'Team slug already taken'
+        });
+      }
+
+      const team = new Team({
+        organizationId: organization._id,
+        name: req.body.name,
+        slug: req.body.slug,
+        description: req.body.description || ''
+      });
+      await team.save();
+
+      res.status(201).json({
+        success: true,
+        data: team,
+        message: 'Team created successfully'
+      });
+    } catch (error) {
+      console.error('Create team error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to create team'
+      });
+    }
+  }
+);
+
+// GET /api/organizations/:slug/teams - List teams
+router.get('/:slug/teams',
+  [slugRule('slug')],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const organization = await loadOrganization(req, res);
+      if (!organization) return;
+
+      const teams = await Team.find({ organizationId: organization._id })
+        .populate('members', 'username email')
+        .sort({ name: 1 });
+
+      res.json({
+        success: true,
+        data: teams
+      });
+    } catch (error) {
+      console.error('List teams error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to list teams'
+      });
+    }
+  }
+);
+
+// POST /api/organizations/:slug/teams/:team/members - Add an organization member to a team
+router.post('/:slug/teams/:team/members',
+  [
+    slugRule('slug'),
+    slugRule('team'),
+    body('userId').isMongoId().withMessage('Valid user ID is required')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const organization = await loadOrganization(req, res, MANAGER_ROLES);
+      if (!organization) return;
+
+      if (!organization.getMemberRole(req.body.userId)) {
+        return res.status(400).json({
+          success: false,
+          error: 'User must be an organization member first'
+        });
+      }
+
+      const team = await Team.findOneAndUpdate(
+        { organizationId: organization._id, slug: req.params.team },
+        { $addToSet: { members: req.body.userId } },
+        { new: true }
+      );
+      if (!team) {
+        return res.status(404).json({
+          success: false,
+          error: 'Team not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: team,
+        message: 'Team member added successfully'
+      });
+    } catch (error) {
+      console.error('Add team member error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to add team member'
+      });
+    }
+  }
+);
+
+// DELETE /api/organizations/:slug/teams/:team/members/:userId - Remove a team member
+router.delete('/:slug/teams/:team/members/:userId',
+  [
+    slugRule('slug'),
+    slugRule('team'),
+    param('userId').isMongoId().withMessage('Invalid user ID')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const organization = await loadOrganization(req, res, MANAGER_ROLES);
+      if (!organization) return;
+
+      const team = await Team.findOneAndUpdate(
+        { organizationId: organization._id, slug: req.params.team },
+        { $pull: { members: req.params.userId } },
+        { new: true }
+      );
+      if (!team) {
+        return res.status(404).json({
+          success: false,
+          error: 'Team not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: team,
+        message: 'Team member removed successfully'
+      });
+    } catch (error) {
+      console.error('Remove team member error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to remove team member'
+      });
+    }
+  }
+);
+
+// DELETE /api/organizations/:slug/teams/:team - Delete a team
+router.delete('/:slug/teams/:team',
+  [slugRule('slug'), slugRule('team')],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const organization = await loadOrganization(req, res, MANAGER_ROLES);
+      if (!organization) return;
+
+      const team = await Team.findOneAndDelete({ organizationId: organization._id, slug: req.params.team });
+      if (!team) {
+        return res.status(404).json({
+          success: false,
+          error: 'Team not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        message: 'Team deleted successfully'
+      });
+    } catch (error) {
+      console.error('Delete team error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to delete team'
+      });
+    }
+  }
+);
+
+// POST /api/organizations/:slug/scim-token - Issue (or rotate) the identity provider's SCIM token
+router.post('/:slug/scim-token',
+  [slugRule('slug')],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const organization = await loadOrganization(req, res, ['owner']);
+      if (!organization) return;
+
+      // Only the hash is stored, so the token is shown once; rotating invalidates the previous one
+      const token = generateScimToken();
+      organization.scim = {
+        tokenHash: hashScimToken(token),
+        tokenPrefix: token.slice(0, 12),
+        enabledAt: new Date()
+      };
+      await organization.save();
+
+      res.status(201).json({
+        success: true,
+        data: {
+          token,
+          tokenPrefix: token.slice(0, 12),
+          baseUrl: SCIM_BASE_URL
+        },
+        message: 'SCIM token issued; store it now, it will not be shown again'
+      });
+    } catch (error) {
+      console.error('Issue SCIM token error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to issue SCIM token'
+      });
+    }
+  }
+);
+
+// DELETE /api/organizations/:slug/scim-token - Disconnect the identity provider
+router.delete('/:slug/scim-token',
+  [slugRule('slug')],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const organization = await loadOrganization(req, res, ['owner']);
+      if (!organization) return;
+
+      organization.scim = undefined;
+      await organization.save();
+
+      res.json({
+        success: true,
+        message: 'SCIM provisioning disabled'
+      });
+    } catch (error) {
+      console.error('Revoke SCIM token error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to revoke SCIM token'
+      });
+    }
+  }
+);
+
+export default router;
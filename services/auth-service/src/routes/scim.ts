@@ -0,0 +1,261 @@
+import express, { Response } from 'express';
+import crypto from 'crypto';
+import mongoose from 'mongoose';
+import { IOrganization } from '../models/Organization.js';
+import { Team, ITeam } from '../models/Team.js';
+import { User } from '../models/User.js';
+import { ScimIdentity } from '../models/ScimIdentity.js';
+import { requireScimToken, ScimRequest } from '../middleware/scim.js';
+import {
+  ScimError,
+  SCIM_MAX_RESULTS,
+  scimSend,
+  scimError,
+  listResponse,
+  toScimUser,
+  toScimGroup,
+  parseFilter,
+  userChangesFromResource,
+  userChangesFromPatch,
+  groupChangesFromPatch,
+  serviceProviderConfig,
+  resourceTypes
+} from '../services/scim.js';
+import { provisionUser, updateUser, deprovisionUser } from '../services/provisioning.js';
+
+// SCIM 2.0 provisioning for an organization's identity provider. Users map to accounts plus their
+// organization membership; Groups map to the organization's teams.
+const router = express.Router();
+
+// Routes throw ScimError for protocol errors; anything else is a 500 in SCIM error format
+const handle = (label: string, handler: (req: ScimRequest, res: Response) => Promise<void>) =>
+  async (req: ScimRequest, res: Response) => {
+    try {
+      await handler(req, res);
+    } catch (error) {
+      if (error instanceof ScimError) {
+        return scimError(res, error.status, error.message, error.scimType);
+      }
+      console.error(`SCIM ${label} error:`, error);
+      scimError(res, 500, `Failed to ${label}`);
+    }
+  };
+
+const paging = (req: ScimRequest) => ({
+  startIndex: Math.max(parseInt(req.query.startIndex as string) || 1, 1),
+  count: Math.min(Math.max(parseInt(req.query.count as string) || 100, 0), SCIM_MAX_RESULTS)
+});
+
+const notFound = (resource: string) => new ScimError(404, `${resource} not found`);
+
+const loadIdentity = async (organization: IOrganization, id: string) => {
+  if (!mongoose.isValidObjectId(id)) throw notFound('User');
+  const [identity, user] = await Promise.all([
+    ScimIdentity.findOne({ organizationId: organization._id, userId: id }),
+    User.findById(id)
+  ]);
+  if (!identity || !user) throw notFound('User');
+  return { identity, user };
+};
+
+const loadTeam = async (organization: IOrganization, id: string) => {
+  const team = mongoose.isValidObjectId(id) ? await Team.findOne({ _id: id, organizationId: organization._id }) : null;
+  if (!team) throw notFound('Group');
+  return team;
+};
+
+const groupResource = async (team: ITeam) =>
+  toScimGroup(team, await User.find({ _id: { $in: team.members } }).select('username'));
+
+// Team members must belong to the organization; anyone else (e.g. a deactivated user the provider
+// still lists) is left out rather than failing the whole sync
+const organizationMembers = (organization: IOrganization, ids: string[]) =>
+  [...new Set(ids)].filter(id => organization.getMemberRole(id)).map(id => new mongoose.Types.ObjectId(id));
+
+const uniqueTeamSlug = async (organization: IOrganization, displayName: string) => {
+  let base = displayName.toLowerCase().replace(/[^a-z0-9]+/g, '-').replace(/^-+|-+$/g, '').slice(0, 40);
+  if (base.length < 2) base = 'team';
+
+  let candidate = base;
+  while (await Team.exists({ organizationId: organization._id, slug: candidate })) {
+    candidate = `${base}-${crypto.randomBytes(2).toString('hex')}`;
+  }
+  return candidate;
+};
+
+const assertDisplayName = (displayName: unknown): string => {
+  if (typeof displayName !== 'string' || !displayName.trim() || displayName.length > 100) {
+    throw new ScimError(400, 'displayName is required and must be at most 100 characters', 'invalidValue');
+  }
+  return displayName.trim();
+};
+
+router.use(requireScimToken);
+
+// GET /scim/v2/ServiceProviderConfig - Capabilities, read by providers when the app is connected
+router.get('/ServiceProviderConfig', (req: ScimRequest, res: Response) => {
+  scimSend(res, 200, serviceProviderConfig());
+});
+
+// GET /scim/v2/ResourceTypes - Supported resource types
+router.get('/ResourceTypes', (req: ScimRequest, res: Response) => {
+  const types = resourceTypes();
+  scimSend(res, 200, listResponse(types, types.length, 1));
+});
+
+// GET /scim/v2/Users - Users provisioned into the organization, optionally filtered
+router.get('/Users', handle('list users', async (req, res) => {
+  const organization = req.organization!;
+  const filter = parseFilter(req.query.filter, ['userName', 'externalId', 'emails.value']);
+  const { startIndex, count } = paging(req);
+
+  const query: any = { organizationId: organization._id };
+  if (filter?.attribute === 'userName') query.userName = filter.value.toLowerCase();
+  if (filter?.attribute === 'externalId') query.externalId = filter.value;
+  if (filter?.attribute === 'emails.value') {
+    const user = await User.findOne({ email: filter.value.toLowerCase() }).select('_id');
+    query.userId = user?._id ?? null;
+  }
+
+  const [total, identities] = await Promise.all([
+    ScimIdentity.countDocuments(query),
+    ScimIdentity.find(query).sort({ createdAt: 1 }).skip(startIndex - 1).limit(count)
+  ]);
+  const users = await User.find({ _id: { $in: identities.map(identity => identity.userId) } });
+  const byId = new Map(users.map(user => [String(user._id), user]));
+
+  const resources = identities
+    .filter(identity => byId.has(String(identity.userId)))
+    .map(identity => toScimUser(identity, byId.get(String(identity.userId))!));
+
+  scimSend(res, 200, listResponse(resources, total, startIndex));
+}));
+
+// POST /scim/v2/Users - Provision a user (or link an existing account with the same email)
+router.post('/Users', handle('create user', async (req, res) => {
+  const { identity, user } = await provisionUser(req.organization!, userChangesFromResource(req.body || {}));
+  scimSend(res, 201, toScimUser(identity, user));
+}));
+
+// GET /scim/v2/Users/:id
+router.get('/Users/:id', handle('get user', async (req, res) => {
+  const { identity, user } = await loadIdentity(req.organization!, req.params.id);
+  scimSend(res, 200, toScimUser(identity, user));
+}));
+
+// PUT /scim/v2/Users/:id - Replace the user's attributes
+router.put('/Users/:id', handle('replace user', async (req, res) => {
+  const organization = req.organization!;
+  const { identity, user } = await loadIdentity(organization, req.params.id);
+  const changes = userChangesFromResource(req.body || {});
+  if (!changes.userName) throw new ScimError(400, 'userName is required', 'invalidValue');
+
+  // A replace that omits active means active, per RFC 7643's default
+  await updateUser(organization, identity, user, { ...changes, active: changes.active ?? true });
+  scimSend(res, 200, toScimUser(identity, user));
+}));
+
+// PATCH /scim/v2/Users/:id - Partial update; `active: false` is how providers deactivate
+router.patch('/Users/:id', handle('update user', async (req, res) => {
+  const organization = req.organization!;
+  const { identity, user } = await loadIdentity(organization, req.params.id);
+
+  await updateUser(organization, identity, user, userChangesFromPatch(req.body));
+  scimSend(res, 200, toScimUser(identity, user));
+}));
+
+// DELETE /scim/v2/Users/:id - Deprovision: revoke access and unlink from the provider
+router.delete('/Users/:id', handle('delete user', async (req, res) => {
+  const organization = req.organization!;
+  const { identity, user } = await loadIdentity(organization, req.params.id);
+
+  await deprovisionUser(organization, identity, user);
+  res.status(204).end();
+}));
+
+// GET /scim/v2/Groups - Teams in the organization, optionally filtered
+router.get('/Groups', handle('list groups', async (req, res) => {
+  const organization = req.organization!;
+  const filter = parseFilter(req.query.filter, ['displayName', 'externalId']);
+  const { startIndex, count } = paging(req);
+
+  const query: any = { organizationId: organization._id };
+  if (filter?.attribute === 'displayName') query.name = filter.value;
+  if (filter?.attribute === 'externalId') query.externalId = filter.value;
+
+  const [total, teams] = await Promise.all([
+    Team.countDocuments(query),
+    Team.find(query).sort({ createdAt: 1 }).skip(startIndex - 1).limit(count)
+  ]);
+
+  // Member lists are large and providers fetch them per group, so listings follow `excludedAttributes`
+  const resources = req.query.excludedAttributes === 'members'
+    ? teams.map(team => ({ ...toScimGroup(team, []), members: undefined }))
+    : await Promise.all(teams.map(groupResource));
+
+  scimSend(res, 200, listResponse(resources, total, startIndex));
+}));
+
+// POST /scim/v2/Groups - Create a team
+router.post('/Groups', handle('create group', async (req, res) => {
+  const organization = req.organization!;
+  const displayName = assertDisplayName(req.body?.displayName);
+
+  if (await Team.exists({ organizationId: organization._id, name: displayName })) {
+    throw new ScimError(409, `Group ${displayName} already exists`, 'uniqueness');
+  }
+
+  const team = await Team.create({
+    organizationId: organization._id,
+    name: displayName,
+    slug: await uniqueTeamSlug(organization, displayName),
+    externalId: req.body.externalId,
+    members: organizationMembers(organization, (req.body.members || []).map((member: any) => String(member?.value)))
+  });
+
+  scimSend(res, 201, await groupResource(team));
+}));
+
+// GET /scim/v2/Groups/:id
+router.get('/Groups/:id', handle('get group', async (req, res) => {
+  scimSend(res, 200, await groupResource(await loadTeam(req.organization!, req.params.id)));
+}));
+
+// PUT /scim/v2/Groups/:id - Replace name and members
+router.put('/Groups/:id', handle('replace group', async (req, res) => {
+  const organization = req.organization!;
+  const team = await loadTeam(organization, req.params.id);
+
+  team.name = assertDisplayName(req.body?.displayName);
+  if (req.body.externalId !== undefined) team.externalId = req.body.externalId;
+  team.members = organizationMembers(organization, (req.body.members || []).map((member: any) => String(member?.value)));
+  await team.save();
+
+  scimSend(res, 200, await groupResource(team));
+}));
+
+// PATCH /scim/v2/Groups/:id - Rename or add/remove members
+router.patch('/Groups/:id', handle('update group', async (req, res) => {
+  const organization = req.organization!;
+  const team = await loadTeam(organization, req.params.id);
+  const changes = groupChangesFromPatch(req.body);
+
+  if (changes.displayName !== undefined) team.name = assertDisplayName(changes.displayName);
+  if (changes.externalId !== undefined) team.externalId = changes.externalId;
+
+  let members = changes.members !== undefined ? changes.members : team.members.map(String);
+  members = [...members, ...changes.addMembers].filter(id => !changes.removeMembers.includes(id));
+  team.members = organizationMembers(organization, members);
+  await team.save();
+
+  scimSend(res, 200, await groupResource(team));
+}));
+
+// DELETE /scim/v2/Groups/:id - Delete the team
+router.delete('/Groups/:id', handle('delete group', async (req, res) => {
+  const team = await loadTeam(req.organization!, req.params.id);
+  await team.deleteOne();
+  res.status(204).end();
+}));
+
+export default router;
@@ -6,6 +6,8 @@ import dotenv from 'dotenv';
 import winston from 'winston';
 import passport from './config/passport.js';
 import authRoutes from './routes/auth.js';
+import organizationRoutes from './routes/organizations.js';
+import scimRoutes from './routes/scim.js';
 
 // Load environment variables
 dotenv.config();
@@ -43,7 +45,8 @@ app.use(cors({
   origin: process.env.FRONTEND_URL || 'http://localhost:5173',
   credentials: true
 }));
-app.use(express.json());
+// Identity providers send SCIM payloads as application/scim+json
+app.use(express.json({ type: ['application/json', 'application/scim+json'] }));
 app.use(express.urlencoded({ extended: true }));
 
 // Session configuration for OAuth
@@ -73,6 +76,8 @@ app.use((req, res, next) => {
 
 // Routes
 app.use('/api/auth', authRoutes);
+app.use('/api/organizations', organizationRoutes);
+app.use('/scim/v2', scimRoutes);
 
 // Health check endpoint
 app.get('/health', (req, res) => {
@@ -99,7 +104,33 @@ app.get('/info', (req, res) => {
       { method: 'GET', path: '/api/auth/github', description: 'GitHub OAuth login' },
       { method: 'GET', path: '/api/auth/google', description: 'Google OAuth login' },
       { method: 'GET', path: '/api/auth/verify', description: 'Verify JWT token' },
-      { method: 'POST', path: '/api/auth/logout', description: 'Logout user' }
+      { method: 'GET', path: '/api/auth/principal', description: 'Resolve principal with organizations and teams' },
+      { method: 'POST', path: '/api/auth/refresh', description: 'Rotate refresh token for a new access token' },
+      { method: 'POST', path: '/api/auth/logout', description: 'Revoke the current session' },
+      { method: 'GET', path: '/api/auth/sessions', description: 'List active sessions' },
+      { method: 'DELETE', path: '/api/auth/sessions/:id', description: 'Revoke a session' },
+      { method: 'POST', path: '/api/organizations', description: 'Create organization' },
+      { method: 'GET', path: '/api/organizations', description: 'List my organizations' },
+      { method: 'GET', path: '/api/organizations/:slug', description: 'Get organization' },
+      { method: 'POST', path: '/api/organizations/:slug/members', description: 'Add organization member' },
+      { method: 'PATCH', path: '/api/organizations/:slug/members/:userId', description: 'Change member role' },
+      { method: 'DELETE', path: '/api/organizations/:slug/members/:userId', description: 'Remove organization member' },
+      { method: 'POST', path: '/api/organizations/:slug/teams', description: 'Create team' },
+      { method: 'GET', path: '/api/organizations/:slug/teams', description: 'List teams' },
+      { method: 'POST', path: '/api/organizations/:slug/teams/:team/members', description: 'Add team member' },
+      { method: 'DELETE', path: '/api/organizations/:slug/teams/:team/members/:userId', description: 'Remove team member' },
+      { method: 'DELETE', path: '/api/organizations/:slug/teams/:team', description: 'Delete team' },
+      { method: 'POST', path: '/api/organizations/:slug/scim-token', description: 'Issue or rotate SCIM token' },
+      { method: 'DELETE', path: '/api/organizations/:slug/scim-token', description: 'Disable SCIM provisioning' },
+      { method: 'GET', path: '/scim/v2/ServiceProviderConfig', description: 'SCIM capabilities' },
+      { method: 'GET', path: '/scim/v2/Users', description: 'SCIM list users' },
+      { method: 'POST', path: '/scim/v2/Users', description: 'SCIM provision user' },
+      { method: 'PATCH', path: '/scim/v2/Users/:id', description: 'SCIM update or deactivate user' },
+      { method: 'DELETE', path: '/scim/v2/Users/:id', description: 'SCIM deprovision user' },
+      { method: 'GET', path: '/scim/v2/Groups', description: 'SCIM list groups (teams)' },
+      { method: 'POST', path: '/scim/v2/Groups', description: 'SCIM create group' },
+      { method: 'PATCH', path: '/scim/v2/Groups/:id', description: 'SCIM update group members' },
+      { method: 'DELETE', path: '/scim/v2/Groups/:id', description: 'SCIM delete group' }
     ]
   });
 });
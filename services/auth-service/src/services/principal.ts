@@ -0,0 +1,40 @@
+import { IUser } from '../models/User.js';
+import { Organization } from '../models/Organization.js';
+import { Team } from '../models/Team.js';
+
+export interface Principal {
+  type: 'user';
+  id: string;
+  username: string;
+  email: string;
+  role: 'user' | 'admin';
+  organizations: Array<{ id: string; slug: string; role: string }>;
+  teams: Array<{ id: string; slug: string; organizationId: string }>;
+}
+
+// The identity other services use for authorization checks and audit attribution
+export async function buildPrincipal(user: IUser): Promise<Principal> {
+  const userId = String(user._id);
+  const [organizations, teams] = await Promise.all([
+    Organization.find({ 'members.userId': user._id }).select('slug members'),
+    Team.find({ members: user._id }).select('slug organizationId')
+  ]);
+
+  return {
+    type: 'user',
+    id: userId,
+    username: user.username,
+    email: user.email,
+    role: user.role,
+    organizations: organizations.map(org => ({
+      id: String(org._id),
+      slug: org.slug,
+      role: org.getMemberRole(userId) || 'member'
+    })),
+    teams: teams.map(team => ({
+      id: String(team._id),
+      slug: team.slug,
+      organizationId: String(team.organizationId)
+    }))
+  };
+}
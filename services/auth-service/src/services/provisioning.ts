@@ -0,0 +1,157 @@
+import crypto from 'crypto';
+import { IOrganization } from '../models/Organization.js';
+import { Team } from '../models/Team.js';
+import { User, IUser } from '../models/User.js';
+import { ScimIdentity, IScimIdentity } from '../models/ScimIdentity.js';
+import { ScimError, UserChanges } from './scim.js';
+import { revokeUserSessions } from './sessions.js';
+
+// Applies identity-provider changes to users and organization membership. Deactivation is what
+// revokes access: the user leaves the organization and its teams, and an account the provider
+// created is disabled outright and its sessions revoked, which fails every token check in
+// requireAuth and /verify.
+
+const isOwnedBy = (user: IUser, organization: IOrganization) =>
+  user.isIdpManaged() && String(user.provisioning!.organizationId) === String(organization._id);
+
+// Usernames are 3-30 characters and globally unique; SCIM userNames are often email addresses
+const uniqueUsername = async (userName: string): Promise<string> => {
+  let base = userName.split('@')[0].toLowerCase().replace(/[^a-z0-9_.-]+/g, '').slice(0, 24);
+  if (base.length < 3) base = `${base}user`;
+
+  let candidate = base;
+  while (await User.exists({ username: candidate })) {
+    candidate = `${base}-${crypto.randomBytes(2).toString('hex')}`;
+  }
+  return candidate;
+};
+
+const assertUserNameFree = async (organization: IOrganization, userName: string, except?: IScimIdentity) => {
+  const existing = await ScimIdentity.findOne({ organizationId: organization._id, userName: userName.toLowerCase() });
+  if (existing && String(existing._id) !== String(except?._id)) {
+    throw new ScimError(409, `userName ${userName} is already provisioned`, 'uniqueness');
+  }
+};
+
+const addMember = (organization: IOrganization, user: IUser) => {
+  if (!organization.getMemberRole(String(user._id))) {
+    organization.members.push({ userId: user._id as any, role: 'member', joinedAt: new Date() });
+  }
+};
+
+export async function provisionUser(
+  organization: IOrganization,
+  changes: UserChanges
+): Promise<{ identity: IScimIdentity; user: IUser }> {
+  if (!changes.userName) throw new ScimError(400, 'userName is required', 'invalidValue');
+  const email = changes.email || (changes.userName.includes('@') ? changes.userName.toLowerCase() : undefined);
+  if (!email) throw new ScimError(400, 'A work email is required', 'invalidValue');
+
+  await assertUserNameFree(organization, changes.userName);
+
+  // An existing account with the same email is linked rather than duplicated; the provider then
+  // controls its membership of this organization but not the account itself. The provider's email
+  // is unverified, so only accounts that already joined the organization (or that this provider
+  // created before deprovisioning them) can be claimed this way.
+  let user = await User.findOne({ email });
+  if (user && !isOwnedBy(user, organization) && !organization.getMemberRole(String(user._id))) {
+    throw new ScimError(409, `Email ${email} belongs to an account outside this organization`, 'uniqueness');
+  }
+  if (user && await ScimIdentity.exists({ organizationId: organization._id, userId: user._id })) {
+    throw new ScimError(409, `User ${email} is already provisioned`, 'uniqueness');
+  }
+  if (!user) {
+    user = await User.create({
+      username: await uniqueUsername(changes.userName),
+      email,
+      profile: { firstName: changes.givenName, lastName: changes.familyName },
+      provisioning: { source: 'scim', organizationId: organization._id },
+      isActive: changes.active !== false
+    });
+  }
+
+  const identity = await ScimIdentity.create({
+    organizationId: organization._id,
+    userId: user._id,
+    userName: changes.userName,
+    externalId: changes.externalId,
+    active: changes.active !== false
+  });
+
+  if (identity.active) {
+    addMember(organization, user);
+    await organization.save();
+  }
+
+  return { identity, user };
+}
+
+export async function updateUser(
+  organization: IOrganization,
+  identity: IScimIdentity,
+  user: IUser,
+  changes: UserChanges
+): Promise<void> {
+  if (changes.userName && changes.userName.toLowerCase() !== identity.userName) {
+    await assertUserNameFree(organization, changes.userName, identity);
+    identity.userName = changes.userName;
+  }
+  if (changes.externalId !== undefined) identity.externalId = changes.externalId;
+
+  // Profile and email belong to the account, so only the provider that created it may change them
+  if (isOwnedBy(user, organization)) {
+    if (changes.givenName !== undefined) user.profile.firstName = changes.givenName;
+    if (changes.familyName !== undefined) user.profile.lastName = changes.familyName;
+    if (changes.email && changes.email !== user.email) {
+      if (await User.exists({ email: changes.email, _id: { $ne: user._id } })) {
+        throw new ScimError(409, `Email ${changes.email} belongs to another account`, 'uniqueness');
+      }
+      user.email = changes.email;
+    }
+    await user.save();
+  }
+
+  await identity.save();
+  if (changes.active !== undefined) await setActive(organization, identity, user, changes.active);
+}
+
+export async function setActive(
+  organization: IOrganization,
+  identity: IScimIdentity,
+  user: IUser,
+  active: boolean
+): Promise<void> {
+  if (identity.active === active) return;
+
+  if (active) {
+    // Reactivated users come back as plain members; the provider re-pushes their groups
+    addMember(organization, user);
+    await organization.save();
+    identity.deactivatedAt = undefined;
+  } else {
+    const owners = organization.members.filter(member => member.role === 'owner');
+    if (organization.getMemberRole(String(user._id)) === 'owner' && owners.length === 1) {
+      throw new ScimError(400, "Cannot deactivate the organization's last owner", 'mutability');
+    }
+
+    organization.members = organization.members.filter(member => member.userId.toString() !== String(user._id));
+    await organization.save();
+    await Team.updateMany({ organizationId: organization._id }, { $pull: { members: user._id } });
+    identity.deactivatedAt = new Date();
+  }
+
+  identity.active = active;
+  await identity.save();
+
+  if (isOwnedBy(user, organization)) {
+    user.isActive = active;
+    await user.save();
+    if (!active) await revokeUserSessions(String(user._id), 'revoked');
+  }
+}
+
+// DELETE deactivates, then forgets the link; the account itself is kept for audit attribution
+export async function deprovisionUser(organization: IOrganization, identity: IScimIdentity, user: IUser): Promise<void> {
+  await setActive(organization, identity, user, false);
+  await identity.deleteOne();
+}
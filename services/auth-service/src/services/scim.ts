@@ -0,0 +1,267 @@
+import crypto from 'crypto';
+import { Response } from 'express';
+import { IUser } from '../models/User.js';
+import { ITeam } from '../models/Team.js';
+import { IScimIdentity } from '../models/ScimIdentity.js';
+
+// SCIM 2.0 (RFC 7643/7644) wire format: schemas, errors, resource mapping, filters and PATCH operations
+
+export const SCIM_SCHEMAS = {
+  user: 'urn:ietf:params:scim:schemas:core:2.0:User',
+  group: 'urn:ietf:params:scim:schemas:core:2.0:Group',
+  listResponse: 'urn:ietf:params:scim:api:messages:2.0:ListResponse',
+  patchOp: 'urn:ietf:params:scim:api:messages:2.0:PatchOp',
+  error: 'urn:ietf:params:scim:api:messages:2.0:Error',
+  serviceProviderConfig: 'urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig',
+  resourceType: 'urn:ietf:params:scim:schemas:core:2.0:ResourceType'
+};
+
+export const SCIM_CONTENT_TYPE = 'application/scim+json';
+export const SCIM_BASE_URL = (process.env.SCIM_BASE_URL || 'http://localhost:3000/scim/v2').replace(/\/$/, '');
+export const SCIM_MAX_RESULTS = 200;
+
+export class ScimError extends Error {
+  constructor(public status: number, message: string, public scimType?: string) {
+    super(message);
+  }
+}
+
+export const generateScimToken = () => `scim_${crypto.randomBytes(32).toString('base64url')}`;
+
+// Tokens are only stored hashed; they are high-entropy, so a plain digest is enough
+export const hashScimToken = (token: string) => crypto.createHash('sha256').update(token).digest('hex');
+
+export const scimSend = (res: Response, status: number, body: unknown) => {
+  res.status(status).type(SCIM_CONTENT_TYPE).json(body);
+};
+
+export const scimError = (res: Response, status: number, detail: string, scimType?: string) => {
+  scimSend(res, status, {
+    schemas: [SCIM_SCHEMAS.error],
+    status: String(status),
+    ...(scimType ? { scimType } : {}),
+    detail
+  });
+};
+
+export const listResponse = (resources: unknown[], totalResults: number, startIndex: number) => ({
+  schemas: [SCIM_SCHEMAS.listResponse],
+  totalResults,
+  startIndex,
+  itemsPerPage: resources.length,
+  Resources: resources
+});
+
+export const toScimUser = (identity: IScimIdentity, user: IUser) => ({
+  schemas: [SCIM_SCHEMAS.user],
+  id: String(user._id),
+  externalId: identity.externalId,
+  userName: identity.userName,
+  name: {
+    givenName: user.profile?.firstName,
+    familyName: user.profile?.lastName
+  },
+  displayName: [user.profile?.firstName, user.profile?.lastName].filter(Boolean).join(' ') || user.username,
+  emails: [{ value: user.email, type: 'work', primary: true }],
+  active: identity.active,
+  meta: {
+    resourceType: 'User',
+    created: identity.createdAt.toISOString(),
+    lastModified: identity.updatedAt.toISOString(),
+    location: `${SCIM_BASE_URL}/Users/${user._id}`
+  }
+});
+
+export const toScimGroup = (team: ITeam, members: IUser[]) => ({
+  schemas: [SCIM_SCHEMAS.group],
+  id: String(team._id),
+  externalId: team.externalId,
+  displayName: team.name,
+  members: members.map(member => ({
+    value: String(member._id),
+    display: member.username,
+    $ref: `${SCIM_BASE_URL}/Users/${member._id}`
+  })),
+  meta: {
+    resourceType: 'Group',
+    created: team.createdAt.toISOString(),
+    lastModified: team.updatedAt.toISOString(),
+    location: `${SCIM_BASE_URL}/Groups/${team._id}`
+  }
+});
+
+// Only the `<attribute> eq "<value>"` form is supported; it is what identity providers use to
+// look a resource up before creating it
+export const parseFilter = (filter: unknown, attributes: string[]): { attribute: string; value: string } | undefined => {
+  if (filter === undefined || filter === '') return undefined;
+
+  const match = typeof filter === 'string' ? filter.trim().match(/^([\w.]+)\s+eq\s+"((?:[^"\\]|\\.)*)"$/i) : null;
+  const attribute = match && attributes.find(candidate => candidate.toLowerCase() === match[1].toLowerCase());
+  if (!match || !attribute) {
+    throw new ScimError(400, `Unsupported filter; use <attribute> eq "<value>" with one of: ${attributes.join(', ')}`, 'invalidFilter');
+  }
+
+  return { attribute, value: match[2].replace(/\\(.)/g, '$1') };
+};
+
+// Some providers send booleans as strings ("False")
+const toBoolean = (value: unknown): boolean => {
+  if (typeof value === 'boolean') return value;
+  if (typeof value === 'string' && ['true', 'false'].includes(value.toLowerCase())) return value.toLowerCase() === 'true';
+  throw new ScimError(400, 'active must be a boolean', 'invalidValue');
+};
+
+export interface UserChanges {
+  userName?: string;
+  externalId?: string;
+  givenName?: string;
+  familyName?: string;
+  email?: string;
+  active?: boolean;
+}
+
+const primaryEmail = (emails: unknown): string | undefined => {
+  if (!Array.isArray(emails)) return undefined;
+  const email = emails.find(entry => entry?.primary === true || entry?.primary === 'True') || emails[0];
+  return typeof email?.value === 'string' ? email.value.trim().toLowerCase() : undefined;
+};
+
+// Maps a full SCIM User (POST/PUT body) onto the attributes this service keeps
+export const userChangesFromResource = (resource: any): UserChanges => ({
+  userName: typeof resource.userName === 'string' ? resource.userName.trim() : undefined,
+  externalId: typeof resource.externalId === 'string' ? resource.externalId : undefined,
+  givenName: resource.name?.givenName,
+  familyName: resource.name?.familyName,
+  email: primaryEmail(resource.emails),
+  active: resource.active === undefined ? undefined : toBoolean(resource.active)
+});
+
+const assertPatchOp = (body: any): Array<{ op: string; path?: string; value?: any }> => {
+  if (!Array.isArray(body?.Operations) || body.Operations.length === 0) {
+    throw new ScimError(400, 'PATCH requires a non-empty Operations array', 'invalidSyntax');
+  }
+  return body.Operations.map((operation: any) => {
+    const op = String(operation?.op || '').toLowerCase();
+    if (!['add', 'replace', 'remove'].includes(op)) {
+      throw new ScimError(400, `Unsupported PATCH op "${operation?.op}"`, 'invalidSyntax');
+    }
+    return { op, path: operation.path, value: operation.value };
+  });
+};
+
+// Maps a SCIM PatchOp on a User onto attribute changes. Paths follow the attributes the provider
+// sends in practice; a pathless op carries an object of attributes instead.
+export const userChangesFromPatch = (body: any): UserChanges => {
+  const changes: UserChanges = {};
+
+  const apply = (path: string, value: any) => {
+    switch (path.toLowerCase()) {
+      case 'username': changes.userName = String(value).trim(); break;
+      case 'externalid': changes.externalId = String(value); break;
+      case 'active': changes.active = toBoolean(value); break;
+      case 'name.givenname': changes.givenName = value; break;
+      case 'name.familyname': changes.familyName = value; break;
+      case 'name':
+        changes.givenName = value?.givenName;
+        changes.familyName = value?.familyName;
+        break;
+      case 'emails': changes.email = primaryEmail(value); break;
+      case 'emails[type eq "work"].value':
+      case 'emails[primary eq true].value':
+        changes.email = String(value).trim().toLowerCase();
+        break;
+      default:
+        // Attributes this service does not store (phone numbers, titles, ...) are accepted and ignored
+        break;
+    }
+  };
+
+  for (const operation of assertPatchOp(body)) {
+    if (operation.op === 'remove') {
+      throw new ScimError(400, 'Removing user attributes is not supported', 'mutability');
+    }
+    if (operation.path) {
+      apply(operation.path, operation.value);
+    } else if (operation.value && typeof operation.value === 'object') {
+      for (const [path, value] of Object.entries(operation.value)) apply(path, value);
+    }
+  }
+
+  return changes;
+};
+
+export interface GroupChanges {
+  displayName?: string;
+  externalId?: string;
+  // Replaces the member list
+  members?: string[];
+  addMembers: string[];
+  removeMembers: string[];
+}
+
+const memberIds = (value: any): string[] =>
+  (Array.isArray(value) ? value : value ? [value] : []).map(member => String(member?.value ?? member));
+
+export const groupChangesFromPatch = (body: any): GroupChanges => {
+  const changes: GroupChanges = { addMembers: [], removeMembers: [] };
+
+  for (const operation of assertPatchOp(body)) {
+    const path = operation.path?.trim() || '';
+    // e.g. members[value eq "64b0..."]
+    const memberFilter = path.match(/^members\[value eq "([^"]+)"\]$/i);
+
+    if (memberFilter) {
+      if (operation.op !== 'remove') throw new ScimError(400, `Unsupported PATCH path "${path}"`, 'invalidPath');
+      changes.removeMembers.push(memberFilter[1]);
+    } else if (path.toLowerCase() === 'members') {
+      if (operation.op === 'add') changes.addMembers.push(...memberIds(operation.value));
+      else if (operation.op === 'remove') {
+        // A remove without a value clears the group
+        if (operation.value === undefined) changes.members = [];
+        else changes.removeMembers.push(...memberIds(operation.value));
+      } else changes.members = memberIds(operation.value);
+    } else if (path.toLowerCase() === 'displayname') {
+      changes.displayName = String(operation.value);
+    } else if (path.toLowerCase() === 'externalid') {
+      changes.externalId = String(operation.value);
+    } else if (!path && operation.value && typeof operation.value === 'object') {
+      if (operation.value.displayName !== undefined) changes.displayName = String(operation.value.displayName);
+      if (operation.value.externalId !== undefined) changes.externalId = String(operation.value.externalId);
+      if (operation.value.members !== undefined) {
+        if (operation.op === 'add') changes.addMembers.push(...memberIds(operation.value.members));
+        else changes.members = memberIds(operation.value.members);
+      }
+    } else {
+      throw new ScimError(400, `Unsupported PATCH path "${path}"`, 'invalidPath');
+    }
+  }
+
+  return changes;
+};
+
+export const serviceProviderConfig = () => ({
+  schemas: [SCIM_SCHEMAS.serviceProviderConfig],
+  documentationUri: `${SCIM_BASE_URL}/ServiceProviderConfig`,
+  patch: { supported: true },
+  bulk: { supported: false, maxOperations: 0, maxPayloadSize: 0 },
+  filter: { supported: true, maxResults: SCIM_MAX_RESULTS },
+  changePassword: { supported: false },
+  sort: { supported: false },
+  etag: { supported: false },
+  authenticationSchemes: [{
+    type: 'oauthbearertoken',
+    name: 'Bearer token',
+    description: 'Organization SCIM token from POST /api/organizations/:slug/scim-token',
+    primary: true
+  }],
+  meta: { resourceType: 'ServiceProviderConfig', location: `${SCIM_BASE_URL}/ServiceProviderConfig` }
+});
+
+export const resourceTypes = () => [
+  { id: 'User', name: 'User', endpoint: '/Users', schema: SCIM_SCHEMAS.user },
+  { id: 'Group', name: 'Group', endpoint: '/Groups', schema: SCIM_SCHEMAS.group }
+].map(type => ({
+  schemas: [SCIM_SCHEMAS.resourceType],
+  ...type,
+  meta: { resourceType: 'ResourceType', location: `${SCIM_BASE_URL}/ResourceTypes/${type.id}` }
+}));
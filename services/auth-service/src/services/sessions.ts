@@ -0,0 +1,95 @@
+import crypto from 'crypto';
+import { Request } from 'express';
+import { IUser, User } from '../models/User.js';
+import { Session, ISession } from '../models/Session.js';
+import { accessTokenLifetime, generateToken } from '../middleware/auth.js';
+
+export interface SessionTokens {
+  token: string;
+  refreshToken: string;
+  expiresIn: string;
+  sessionId: string;
+}
+
+const REFRESH_TOKEN_TTL_DAYS = parseInt(process.env.REFRESH_TOKEN_TTL_DAYS || '30');
+
+const hashToken = (token: string) => crypto.createHash('sha256').update(token).digest('hex');
+const newRefreshToken = () => crypto.randomBytes(48).toString('base64url');
+const refreshExpiry = () => new Date(Date.now() + REFRESH_TOKEN_TTL_DAYS * 24 * 60 * 60 * 1000);
+
+const tokensFor = (user: IUser, session: ISession, refreshToken: string): SessionTokens => ({
+  token: generateToken(String(user._id), String(session._id)),
+  refreshToken,
+  expiresIn: accessTokenLifetime(),
+  sessionId: String(session._id)
+});
+
+// Starts a session after a successful sign-in (password, registration or OAuth)
+export async function createSession(user: IUser, req: Request): Promise<SessionTokens> {
+  const refreshToken = newRefreshToken();
+  const session = await Session.create({
+    userId: user._id,
+    refreshTokenHash: hashToken(refreshToken),
+    userAgent: req.get('User-Agent')?.slice(0, 500),
+    ip: req.ip,
+    expiresAt: refreshExpiry()
+  });
+  return tokensFor(user, session, refreshToken);
+}
+
+// Exchanges a refresh token for a new pair. Returns null when the token is unknown, expired or
+// revoked; presenting an already-rotated token revokes the whole session, since either the
+// legitimate client or an attacker is holding a stolen copy.
+export async function refreshSession(refreshToken: string, req: Request): Promise<SessionTokens | null> {
+  const hash = hashToken(refreshToken);
+  const session = await Session.findOne({ refreshTokenHash: hash });
+
+  if (!session) {
+    const replayed = await Session.findOne({ previousTokenHash: hash, revokedAt: { $exists: false } });
+    if (replayed) await revokeSession(replayed, 'reuse');
+    return null;
+  }
+  if (session.revokedAt || session.expiresAt <= new Date()) return null;
+
+  const user = await User.findById(session.userId);
+  if (!user || !user.isActive) {
+    await revokeSession(session, 'revoked');
+    return null;
+  }
+
+  const next = newRefreshToken();
+  // Conditional on the hash so two concurrent refreshes with the same token cannot both win
+  const rotated = await Session.findOneAndUpdate(
+    { _id: session._id, refreshTokenHash: hash, revokedAt: { $exists: false } },
+    {
+      refreshTokenHash: hashToken(next),
+      previousTokenHash: hash,
+      lastUsedAt: new Date(),
+      ip: req.ip,
+      expiresAt: refreshExpiry()
+    },
+    { new: true }
+  );
+  return rotated ? tokensFor(user, rotated, next) : null;
+}
+
+export async function revokeSession(session: ISession, reason: NonNullable<ISession['revokedReason']>): Promise<void> {
+  if (session.revokedAt) return;
+  session.revokedAt = new Date();
+  session.revokedReason = reason;
+  await session.save();
+}
+
+export async function revokeSessionByRefreshToken(refreshToken: string): Promise<boolean> {
+  const session = await Session.findOne({ refreshTokenHash: hashToken(refreshToken) });
+  if (!session) return false;
+  await revokeSession(session, 'logout');
+  return true;
+}
+
+export async function revokeUserSessions(userId: string, reason: NonNullable<ISession['revokedReason']>, exceptSessionId?: string): Promise<number> {
+  const filter: any = { userId, revokedAt: { $exists: false } };
+  if (exceptSessionId) filter._id = { $ne: exceptSessionId };
+  const result = await Session.updateMany(filter, { revokedAt: new Date(), revokedReason: reason });
+  return result.modifiedCount;
+}
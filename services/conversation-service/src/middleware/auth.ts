@@ -0,0 +1,108 @@
+import { Request, Response, NextFunction } from 'express';
+import jwt from 'jsonwebtoken';
+import axios from 'axios';
+import { timingSafeEqual } from 'crypto';
+
+export interface User {
+  _id: string;
+  username: string;
+  email: string;
+  role: 'user' | 'admin';
+  isActive: boolean;
+}
+
+export interface AuthenticatedRequest extends Request {
+  user?: User;
+}
+
+export const authenticateToken = async (
+  req: AuthenticatedRequest,
+  res: Response,
+  next: NextFunction
+): Promise<void> => {
+  const authHeader = req.headers.authorization;
+  const token = authHeader && authHeader.split(' ')[1];
+
+  if (!token) {
+    res.status(401).json({
+      success: false,
+      error: 'Access token required'
+    });
+    return;
+  }
+
+  try {
+    // First verify JWT locally
+    const secret = process.env.JWT_SECRET || 'fallback-secret-change-in-production';
+    const decoded = jwt.verify(token, secret) as { userId: string };
+    
+    // Then verify with auth service for user details
+    const authServiceUrl = process.env.AUTH_SERVICE_URL || 'http://localhost:3001';
+    const response = await axios.get(`${authServiceUrl}/api/auth/verify`, {
+      headers: { Authorization: `Bearer ${token}` },
+      timeout: 5000
+    });
+
+    if (!response.data.success) {
+      res.status(401).json({
+        success: false,
+        error: 'Invalid token'
+      });
+      return;
+    }
+
+    req.user = {
+      _id: response.data.data.userId,
+      username: response.data.data.username,
+      email: response.data.data.email,
+      role: response.data.data.role,
+      isActive: response.data.data.isActive
+    };
+
+    next();
+  } catch (error) {
+    console.error('Authentication error:', error);
+    res.status(403).json({
+      success: false,
+      error: 'Invalid token'
+    });
+  }
+};
+
+export const requireAuth = authenticateToken;
+
+export const requireRole = (role: 'admin' | 'user') => {
+  return (req: AuthenticatedRequest, res: Response, next: NextFunction) => {
+    if (!req.user) {
+      return res.status(401).json({
+        success: false,
+        error: 'Authentication required'
+      });
+    }
+
+    if (role === 'admin' && req.user.role !== 'admin') {
+      return res.status(403).json({
+        success: false,
+        error: 'Admin access required'
+      });
+    }
+
+    next();
+  };
+};
+
+// Internal callers (pipeline-service checkpoints, agent-service transcripts) present the shared INTERNAL_SERVICE_TOKEN
+export const requireServiceToken = (req: Request, res: Response, next: NextFunction): void => {
+  const expected = process.env.INTERNAL_SERVICE_TOKEN;
+  const provided = req.get('X-Service-Token');
+
+  if (!expected || !provided || provided.length !== expected.length ||
+      !timingSafeEqual(Buffer.from(provided), Buffer.from(expected))) {
+    res.status(401).json({
+      success: false,
+      error: 'Service token required'
+    });
+    return;
+  }
+  next();
+};
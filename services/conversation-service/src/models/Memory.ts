@@ -0,0 +1,60 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export interface IMemory extends Document {
+  projectId: string;
+  runId: string;
+  stageId: string;
+  // Attempt of the stage the summary was written from; a later completed attempt replaces it
+  attempt: number;
+  agentRole?: string;
+  summary: string;
+  // Kept here even when an external vector store indexes it, so the store can be rebuilt
+  embedding: number[];
+  embeddingModel: string;
+  redactions: number;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const MemorySchema: Schema = new Schema({
+  projectId: {
+    type: String,
+    required: true
+  },
+  runId: {
+    type: String,
+    required: true
+  },
+  stageId: {
+    type: String,
+    required: true
+  },
+  attempt: {
+    type: Number,
+    default: 1
+  },
+  agentRole: { type: String },
+  summary: {
+    type: String,
+    required: true
+  },
+  embedding: {
+    type: [Number],
+    default: []
+  },
+  embeddingModel: {
+    type: String,
+    required: true
+  },
+  redactions: {
+    type: Number,
+    default: 0
+  }
+}, {
+  timestamps: true
+});
+
+MemorySchema.index({ runId: 1, stageId: 1 }, { unique: true });
+MemorySchema.index({ projectId: 1, createdAt: -1 });
+
+export const Memory = mongoose.model<IMemory>('Memory', MemorySchema);
@@ -0,0 +1,49 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export interface IStageState extends Document {
+  runId: string;
+  stageId: string;
+  projectId?: string;
+  // Owner of runs outside a project
+  tenantId?: string;
+  status: 'running' | 'completed' | 'failed' | 'skipped';
+  // Whatever the stage needs to pick up where it left off, e.g. its outputs
+  state: Record<string, any>;
+  revision: number;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const StageStateSchema: Schema = new Schema({
+  runId: {
+    type: String,
+    required: true
+  },
+  stageId: {
+    type: String,
+    required: true
+  },
+  projectId: { type: String },
+  tenantId: { type: String },
+  status: {
+    type: String,
+    enum: ['running', 'completed', 'failed', 'skipped'],
+    required: true
+  },
+  state: {
+    type: Schema.Types.Mixed,
+    default: {}
+  },
+  // Incremented on every save so readers can tell checkpoints apart
+  revision: {
+    type: Number,
+    default: 1
+  }
+}, {
+  timestamps: true,
+  minimize: false
+});
+
+StageStateSchema.index({ runId: 1, stageId: 1 }, { unique: true });
+
+export const StageState = mongoose.model<IStageState>('StageState', StageStateSchema);
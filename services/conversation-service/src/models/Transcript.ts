@@ -0,0 +1,87 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export interface TranscriptMessage {
+  role: 'system' | 'user' | 'assistant';
+  content: string;
+}
+
+export interface ITranscript extends Document {
+  runId: string;
+  stageId: string;
+  projectId?: string;
+  attempt: number;
+  agentRole?: string;
+  model?: string;
+  status: 'completed' | 'failed';
+  messages: TranscriptMessage[];
+  error?: string;
+  usage?: {
+    promptTokens: number;
+    completionTokens: number;
+    totalTokens: number;
+    costUsd?: number;
+  };
+  durationMs?: number;
+  // Number of values masked, on ingest or by later redaction requests
+  redactions: number;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const MessageSchema = new Schema({
+  role: {
+    type: String,
+    enum: ['system', 'user', 'assistant'],
+    required: true
+  },
+  content: {
+    type: String,
+    default: ''
+  }
+}, { _id: false });
+
+const TranscriptSchema: Schema = new Schema({
+  runId: {
+    type: String,
+    required: true
+  },
+  stageId: {
+    type: String,
+    required: true
+  },
+  projectId: {
+    type: String,
+    index: true
+  },
+  // Retries of a stage keep their own transcript
+  attempt: {
+    type: Number,
+    default: 1
+  },
+  agentRole: { type: String },
+  model: { type: String },
+  status: {
+    type: String,
+    enum: ['completed', 'failed'],
+    required: true
+  },
+  messages: [MessageSchema],
+  error: { type: String },
+  usage: {
+    promptTokens: Number,
+    completionTokens: Number,
+    totalTokens: Number,
+    costUsd: Number
+  },
+  durationMs: { type: Number },
+  redactions: {
+    type: Number,
+    default: 0
+  }
+}, {
+  timestamps: true
+});
+
+TranscriptSchema.index({ runId: 1, stageId: 1, attempt: 1 }, { unique: true });
+
+export const Transcript = mongoose.model<ITranscript>('Transcript', TranscriptSchema);
@@ -0,0 +1,406 @@
+import express, { Request, Response } from 'express';
+import { body, param, query, validationResult } from 'express-validator';
+import { Transcript } from '../models/Transcript.js';
+import { StageState } from '../models/StageState.js';
+import { Memory } from '../models/Memory.js';
+import { Redactor } from '../services/Redactor.js';
+import { MemoryService } from '../services/MemoryService.js';
+import { canAccessProject, canAccessRun } from '../services/RunAccess.js';
+import { AuthenticatedRequest, requireAuth, requireRole, requireServiceToken } from '../middleware/auth.js';
+
+const router = express.Router();
+const redactor = new Redactor();
+const memory = new MemoryService();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+// Answers 404 for runs the caller cannot see, so they look the same as runs with nothing recorded
+const requireRunAccess = async (req: AuthenticatedRequest, res: Response, next: express.NextFunction) => {
+  try {
+    if (!await canAccessRun(req.params.runId, req.user!, req.headers.authorization!)) {
+      return res.status(404).json({
+        success: false,
+        error: 'No conversations recorded for this run'
+      });
+    }
+    next();
+  } catch (error) {
+    console.error('Run access check error:', error);
+    res.status(502).json({
+      success: false,
+      error: 'Failed to check run access'
+    });
+  }
+};
+
+const runIdParam = param('runId').isString().isLength({ min: 1, max: 200 }).withMessage('Invalid run ID');
+const stageIdParam = param('stageId').isString().isLength({ min: 1, max: 200 }).withMessage('Invalid stage ID');
+
+// POST /api/conversations/transcripts - Record an agent exchange for a stage (internal)
+router.post('/transcripts',
+  requireServiceToken,
+  [
+    body('runId').isString().isLength({ min: 1 }).withMessage('Run ID is required'),
+    body('stageId').isString().isLength({ min: 1 }).withMessage('Stage ID is required'),
+    body('status').isIn(['completed', 'failed']).withMessage('Status must be completed or failed'),
+    body('messages').isArray({ min: 1 }).withMessage('At least one message is required'),
+    body('messages.*.role').isIn(['system', 'user', 'assistant']).withMessage('Message role must be system, user or assistant'),
+    body('messages.*.content').isString().withMessage('Message content must be a string'),
+    body('attempt').optional().isInt({ min: 1 }).withMessage('Attempt must be a positive integer')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const { runId, stageId } = req.body;
+      const messages = redactor.redactValue(req.body.messages);
+      const error = req.body.error ? redactor.redactText(req.body.error) : undefined;
+      const attempt = req.body.attempt || (await Transcript.countDocuments({ runId, stageId })) + 1;
+
+      const transcript = await Transcript.findOneAndUpdate(
+        { runId, stageId, attempt },
+        {
+          projectId: req.body.projectId,
+          agentRole: req.body.agentRole,
+          model: req.body.model,
+          status: req.body.status,
+          messages: messages.value,
+          error: error?.value,
+          usage: req.body.usage,
+          durationMs: req.body.durationMs,
+          redactions: messages.count + (error?.count || 0)
+        },
+        { upsert: true, new: true, setDefaultsOnInsert: true }
+      );
+
+      // Summarized in the background; a stage never waits on, or fails because of, its memory
+      memory.remember(transcript)
+        .catch(error => console.error(`Error remembering ${runId}/${stageId}:`, error));
+
+      res.status(201).json({
+        success: true,
+        data: { id: transcript._id, attempt: transcript.attempt, redactions: transcript.redactions },
+        message: 'Transcript recorded'
+      });
+    } catch (error) {
+      console.error('Error recording transcript:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to record transcript'
+      });
+    }
+  }
+);
+
+// PUT /api/conversations/state/:runId/:stageId - Checkpoint a stage's state (internal)
+router.put('/state/:runId/:stageId',
+  requireServiceToken,
+  [
+    runIdParam,
+    stageIdParam,
+    body('status').isIn(['running', 'completed', 'failed', 'skipped']).withMessage('Status must be running, completed, failed or skipped'),
+    body('state').optional().isObject().withMessage('State must be an object')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const { runId, stageId } = req.params;
+      const state = redactor.redactValue(req.body.state || {});
+
+      const checkpoint = await StageState.findOneAndUpdate(
+        { runId, stageId },
+        {
+          $set: { projectId: req.body.projectId, tenantId: req.body.tenantId, status: req.body.status, state: state.value },
+          $inc: { revision: 1 }
+        },
+        { upsert: true, new: true }
+      );
+
+      res.json({
+        success: true,
+        data: checkpoint
+      });
+    } catch (error) {
+      console.error('Error saving stage state:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to save stage state'
+      });
+    }
+  }
+);
+
+// GET /api/conversations/state/:runId - Latest checkpoint of every stage, for resuming a run (internal)
+router.get('/state/:runId',
+  requireServiceToken,
+  [runIdParam],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const checkpoints = await StageState.find({ runId: req.params.runId });
+
+      res.json({
+        success: true,
+        data: Object.fromEntries(checkpoints.map(checkpoint => [checkpoint.stageId, checkpoint]))
+      });
+    } catch (error) {
+      console.error('Error fetching run state:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch run state'
+      });
+    }
+  }
+);
+
+// POST /api/conversations/memory/search - Summaries of a project's earlier stages most relevant to a query (internal)
+router.post('/memory/search',
+  requireServiceToken,
+  [
+    body('projectId').isString().isLength({ min: 1 }).withMessage('Project ID is required'),
+    body('query').isString().isLength({ min: 1, max: 20000 }).withMessage('Query must be 1-20000 characters'),
+    body('limit').optional().isInt({ min: 1, max: 20 }).withMessage('Limit must be between 1 and 20'),
+    body('minScore').optional().isFloat({ min: -1, max: 1 }).withMessage('minScore must be between -1 and 1'),
+    body('runId').optional().isString(),
+    body('stageId').optional().isString()
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      if (!memory.isEnabled()) {
+        return res.json({
+          success: true,
+          data: []
+        });
+      }
+
+      const memories = await memory.recall(req.body.projectId, req.body.query, {
+        limit: req.body.limit,
+        minScore: req.body.minScore,
+        runId: req.body.runId,
+        stageId: req.body.stageId
+      });
+
+      res.json({
+        success: true,
+        data: memories
+      });
+    } catch (error) {
+      console.error('Error searching memory:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to search memory'
+      });
+    }
+  }
+);
+
+// GET /api/conversations/memory?projectId= - What has been remembered for a project, newest first
+router.get('/memory',
+  requireAuth,
+  [
+    query('projectId').isString().isLength({ min: 1 }).withMessage('Project ID is required'),
+    query('limit').optional().isInt({ min: 1, max: 200 }).withMessage('Limit must be between 1 and 200')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      if (req.user!.role !== 'admin' && !await canAccessProject(req.query.projectId as string, req.headers.authorization!)) {
+        return res.status(404).json({
+          success: false,
+          error: 'Project not found'
+        });
+      }
+
+      const memories = await Memory.find({ projectId: req.query.projectId as string })
+        .select('-embedding')
+        .sort({ createdAt: -1 })
+        .limit(parseInt(req.query.limit as string) || 50);
+
+      res.json({
+        success: true,
+        data: memories
+      });
+    } catch (error) {
+      console.error('Error fetching memory:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch memory'
+      });
+    }
+  }
+);
+
+// GET /api/conversations/runs/:runId - Transcript index and stage state for a run
+router.get('/runs/:runId',
+  requireAuth,
+  [runIdParam],
+  validateRequest,
+  requireRunAccess,
+  async (req: Request, res: Response) => {
+    try {
+      const [transcripts, states] = await Promise.all([
+        Transcript.find({ runId: req.params.runId }).select('-messages').sort({ createdAt: 1 }),
+        StageState.find({ runId: req.params.runId }).sort({ createdAt: 1 })
+      ]);
+
+      if (transcripts.length === 0 && states.length === 0) {
+        return res.status(404).json({
+          success: false,
+          error: 'No conversations recorded for this run'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: {
+          runId: req.params.runId,
+          transcripts,
+          states
+        }
+      });
+    } catch (error) {
+      console.error('Error fetching run conversations:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch run conversations'
+      });
+    }
+  }
+);
+
+// GET /api/conversations/runs/:runId/stages/:stageId - Full transcripts of every attempt at a stage
+router.get('/runs/:runId/stages/:stageId',
+  requireAuth,
+  [runIdParam, stageIdParam],
+  validateRequest,
+  requireRunAccess,
+  async (req: Request, res: Response) => {
+    try {
+      const { runId, stageId } = req.params;
+      const [transcripts, state] = await Promise.all([
+        Transcript.find({ runId, stageId }).sort({ attempt: 1 }),
+        StageState.findOne({ runId, stageId })
+      ]);
+
+      if (transcripts.length === 0 && !state) {
+        return res.status(404).json({
+          success: false,
+          error: 'No conversations recorded for this stage'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: {
+          runId,
+          stageId,
+          transcripts,
+          state
+        }
+      });
+    } catch (error) {
+      console.error('Error fetching stage conversations:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch stage conversations'
+      });
+    }
+  }
+);
+
+// POST /api/conversations/runs/:runId/redact - Mask given terms (and known secret formats) everywhere in a run, memory included
+router.post('/runs/:runId/redact',
+  requireAuth,
+  [
+    runIdParam,
+    body('terms').isArray({ min: 1 }).withMessage('At least one term is required'),
+    body('terms.*').isString().isLength({ min: 3 }).withMessage('Terms must be at least 3 characters')
+  ],
+  validateRequest,
+  requireRunAccess,
+  async (req: Request, res: Response) => {
+    try {
+      const terms: string[] = req.body.terms;
+      let redactions = 0;
+
+      for (const transcript of await Transcript.find({ runId: req.params.runId })) {
+        const messages = redactor.redactValue(transcript.toObject().messages, terms);
+        const error = transcript.error ? redactor.redactText(transcript.error, terms) : undefined;
+        const count = messages.count + (error?.count || 0);
+        if (count === 0) continue;
+
+        transcript.set('messages', messages.value);
+        transcript.error = error?.value;
+        transcript.redactions += count;
+        await transcript.save();
+        redactions += count;
+      }
+
+      for (const checkpoint of await StageState.find({ runId: req.params.runId })) {
+        const state = redactor.redactValue(checkpoint.state, terms);
+        if (state.count === 0) continue;
+
+        checkpoint.state = state.value;
+        checkpoint.markModified('state');
+        await checkpoint.save();
+        redactions += state.count;
+      }
+
+      redactions += await memory.redactRun(req.params.runId, terms);
+
+      res.json({
+        success: true,
+        data: { runId: req.params.runId, redactions },
+        message: `Redacted ${redactions} occurrence(s)`
+      });
+    } catch (error) {
+      console.error('Error redacting run conversations:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to redact run conversations'
+      });
+    }
+  }
+);
+
+// DELETE /api/conversations/runs/:runId - Remove all transcripts, state and memory of a run (admin only)
+router.delete('/runs/:runId',
+  requireAuth,
+  requireRole('admin'),
+  [runIdParam],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const [transcripts, states, memories] = await Promise.all([
+        Transcript.deleteMany({ runId: req.params.runId }),
+        StageState.deleteMany({ runId: req.params.runId }),
+        memory.forgetRun(req.params.runId)
+      ]);
+
+      res.json({
+        success: true,
+        data: { transcripts: transcripts.deletedCount, states: states.deletedCount, memories },
+        message: 'Run conversations removed'
+      });
+    } catch (error) {
+      console.error('Error removing run conversations:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to remove run conversations'
+      });
+    }
+  }
+);
+
+export default router;
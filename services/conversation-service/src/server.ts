@@ -0,0 +1,122 @@
+import express from 'express';
+import cors from 'cors';
+import mongoose from 'mongoose';
+import dotenv from 'dotenv';
+import winston from 'winston';
+import conversationRoutes from './routes/conversations.js';
+
+// Load environment variables
+dotenv.config();
+
+const app = express();
+const PORT = process.env.PORT || 3014;
+
+// Logger configuration
+const logger = winston.createLogger({
+  level: 'info',
+  format: winston.format.combine(
+    winston.format.timestamp(),
+    winston.format.json()
+  ),
+  transports: [
+    new winston.transports.Console(),
+    new winston.transports.File({ filename: 'logs/conversation-service.log' })
+  ]
+});
+
+// Database connection
+const MONGODB_URI = process.env.MONGODB_URI || 'mongodb://localhost:27017/ai-pipeline';
+
+mongoose.connect(MONGODB_URI)
+  .then(() => {
+    logger.info('🗄️  Connected to MongoDB');
+  })
+  .catch((error) => {
+    logger.error('❌ MongoDB connection error:', error);
+    process.exit(1);
+  });
+
+// Middleware
+app.use(cors({
+  origin: process.env.FRONTEND_URL || 'http://localhost:5173',
+  credentials: true
+}));
+app.use(express.json({ limit: '20mb' }));
+app.use(express.urlencoded({ extended: true }));
+
+// Request logging middleware
+app.use((req, res, next) => {
+  logger.info(`${req.method} ${req.path}`, {
+    ip: req.ip,
+    userAgent: req.get('User-Agent'),
+    timestamp: new Date().toISOString()
+  });
+  next();
+});
+
+// Routes
+app.use('/api/conversations', conversationRoutes);
+
+// Health check endpoint
+app.get('/health', (req, res) => {
+  res.json({
+    status: 'ok',
+    service: 'conversation-service',
+    timestamp: new Date().toISOString(),
+    version: '1.0.0',
+    database: mongoose.connection.readyState === 1 ? 'connected' : 'disconnected'
+  });
+});
+
+// Service info endpoint
+app.get('/info', (req, res) => {
+  res.json({
+    service: 'Conversation Service',
+    version: '1.0.0',
+    description: 'Agent conversation transcripts, run state and project memory',
+    endpoints: [
+      { method: 'POST', path: '/api/conversations/transcripts', description: 'Record stage transcript (internal)' },
+      { method: 'POST', path: '/api/conversations/memory/search', description: 'Recall relevant earlier stages of a project (internal)' },
+      { method: 'GET', path: '/api/conversations/memory', description: 'Stage summaries remembered for a project' },
+      { method: 'PUT', path: '/api/conversations/state/:runId/:stageId', description: 'Checkpoint stage state (internal)' },
+      { method: 'GET', path: '/api/conversations/state/:runId', description: 'Stage checkpoints for resuming a run (internal)' },
+      { method: 'GET', path: '/api/conversations/runs/:runId', description: 'Run transcript index and state' },
+      { method: 'GET', path: '/api/conversations/runs/:runId/stages/:stageId', description: 'Stage transcripts for every attempt' },
+      { method: 'POST', path: '/api/conversations/runs/:runId/redact', description: 'Redact terms across a run' },
+      { method: 'DELETE', path: '/api/conversations/runs/:runId', description: 'Remove run conversations' }
+    ]
+  });
+});
+
+// Catch-all for undefined routes
+app.use('*', (req, res) => {
+  res.status(404).json({
+    success: false,
+    error: 'Route not found',
+    service: 'conversation-service'
+  });
+});
+
+// Global error handler
+app.use((err: any, req: any, res: any, next: any) => {
+  logger.error('Unhandled error:', err);
+  res.status(500).json({
+    success: false,
+    error: 'Internal server error',
+    service: 'conversation-service'
+  });
+});
+
+// Graceful shutdown
+process.on('SIGTERM', () => {
+  logger.info('SIGTERM received. Shutting down gracefully...');
+  mongoose.connection.close();
+  process.exit(0);
+});
+
+// Start server
+app.listen(PORT, () => {
+  logger.info(`💬 Conversation Service running on port ${PORT}`);
+});
+
+export default app;
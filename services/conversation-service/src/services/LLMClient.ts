@@ -0,0 +1,54 @@
+import axios from 'axios';
+
+export interface ChatMessage {
+  role: 'system' | 'user' | 'assistant';
+  content: string;
+}
+
+export interface Embeddings {
+  model: string;
+  embeddings: number[][];
+  dimensions: number;
+}
+
+// The two gateway calls memory needs: a completion to summarize a stage and vectors to index it
+export class LLMClient {
+  private baseUrl: string;
+  private timeout: number;
+
+  constructor(baseUrl?: string, timeout?: number) {
+    this.baseUrl = baseUrl || process.env.LLM_GATEWAY_URL || 'http://localhost:3006';
+    this.timeout = timeout || parseInt(process.env.LLM_TIMEOUT_MS || '120000');
+  }
+
+  async chat(request: {
+    model: string;
+    messages: ChatMessage[];
+    temperature?: number;
+    maxTokens?: number;
+    metadata?: Record<string, any>;
+  }): Promise<{ content: string; model: string }> {
+    return this.post('/api/llm/chat', request);
+  }
+
+  async embed(input: string[], model?: string, metadata?: Record<string, any>): Promise<Embeddings> {
+    return this.post('/api/llm/embeddings', { ...(model ? { model } : {}), input, metadata });
+  }
+
+  private async post(path: string, payload: Record<string, any>): Promise<any> {
+    try {
+      const response = await axios.post(`${this.baseUrl}${path}`, payload, { timeout: this.timeout });
+
+      if (!response.data.success) {
+        throw new Error(response.data.error || 'LLM gateway request failed');
+      }
+
+      return response.data.data;
+    } catch (error) {
+      if (axios.isAxiosError(error) && error.response?.data?.error) {
+        throw new Error(`LLM gateway error: ${error.response.data.error}`);
+      }
+      throw new Error(`LLM gateway error: ${error instanceof Error ? error.message : 'Unknown error'}`);
+    }
+  }
+}
@@ -0,0 +1,177 @@
+import { Memory } from '../models/Memory.js';
+import { ITranscript } from '../models/Transcript.js';
+import { LLMClient } from './LLMClient.js';
+import { Redactor } from './Redactor.js';
+import { createVectorStore, VectorStore } from './VectorStore.js';
+
+export interface RecallOptions {
+  limit?: number;
+  // The asking stage; its own earlier attempt is not handed back to it
+  runId?: string;
+  stageId?: string;
+  minScore?: number;
+}
+
+export interface RecalledMemory {
+  id: string;
+  runId: string;
+  stageId: string;
+  agentRole?: string;
+  summary: string;
+  score: number;
+  createdAt: Date;
+}
+
+const SUMMARY_PROMPT = `You keep the memory of a software project that a pipeline of AI specialists builds stage by stage.
+Summarize the stage below for the specialists working on later stages. Keep what they must stay consistent with:
+decisions and the reasons for them, technologies, conventions and names chosen, interfaces and data shapes,
+constraints and open questions. Write at most {{maxPoints}} short bullet points and leave out anything that only
+restates the request. Reply with the bullet points only.`;
+
+// Summarizes each completed stage of a project, embeds the summary and finds the ones relevant to a
+// later stage. Summaries are written from transcripts after redaction, so they never hold more than
+// the transcripts do.
+export class MemoryService {
+  private summaryModel = process.env.MEMORY_SUMMARY_MODEL || 'gpt-4o-mini';
+  private embeddingModel = process.env.LLM_EMBEDDING_MODEL || undefined;
+  private sourceChars = parseInt(process.env.MEMORY_SOURCE_CHARS || '12000');
+  private maxPoints = parseInt(process.env.MEMORY_SUMMARY_POINTS || '8');
+  private defaultLimit = parseInt(process.env.MEMORY_RECALL_LIMIT || '5');
+  private defaultMinScore = parseFloat(process.env.MEMORY_MIN_SCORE || '0.3');
+
+  constructor(
+    private llm: LLMClient = new LLMClient(),
+    private store: VectorStore = createVectorStore(),
+    private redactor: Redactor = new Redactor()
+  ) {}
+
+  isEnabled(): boolean {
+    return process.env.MEMORY_ENABLED !== 'false';
+  }
+
+  // Only completed stages of a project are remembered; a later attempt replaces an earlier one
+  async remember(transcript: ITranscript): Promise<void> {
+    if (!this.isEnabled() || !transcript.projectId || transcript.status !== 'completed') return;
+
+    const source = this.source(transcript);
+    if (!source) return;
+
+    const metadata = {
+      projectId: transcript.projectId,
+      runId: transcript.runId,
+      stageId: transcript.stageId,
+      purpose: 'memory'
+    };
+    const completion = await this.llm.chat({
+      model: this.summaryModel,
+      messages: [
+        { role: 'system', content: SUMMARY_PROMPT.replace('{{maxPoints}}', String(this.maxPoints)) },
+        { role: 'user', content: source }
+      ],
+      temperature: 0,
+      maxTokens: 600,
+      metadata
+    });
+    const summary = this.redactor.redactText(completion.content.trim());
+    if (!summary.value) return;
+
+    const embedded = await this.llm.embed([this.embeddingText(transcript.agentRole, transcript.stageId, summary.value)], this.embeddingModel, metadata);
+
+    const memory = await Memory.findOneAndUpdate(
+      { runId: transcript.runId, stageId: transcript.stageId, attempt: { $lte: transcript.attempt } },
+      {
+        projectId: transcript.projectId,
+        attempt: transcript.attempt,
+        agentRole: transcript.agentRole,
+        summary: summary.value,
+        embedding: embedded.embeddings[0],
+        embeddingModel: embedded.model,
+        redactions: summary.count
+      },
+      { upsert: true, new: true, setDefaultsOnInsert: true }
+    ).catch(error => {
+      // A newer attempt was remembered first; the unique index refuses the older one
+      if (error?.code === 11000) return null;
+      throw error;
+    });
+    if (!memory) return;
+
+    await this.store.upsert(String(memory._id), memory.embedding, {
+      projectId: memory.projectId,
+      runId: memory.runId,
+      stageId: memory.stageId
+    });
+  }
+
+  async recall(projectId: string, query: string, options: RecallOptions = {}): Promise<RecalledMemory[]> {
+    const limit = options.limit || this.defaultLimit;
+    const minScore = options.minScore ?? this.defaultMinScore;
+
+    const embedded = await this.llm.embed([query], this.embeddingModel, { projectId, purpose: 'memory' });
+    // One extra in case the asking stage's own memory is among the matches
+    const matches = await this.store.search(projectId, embedded.embeddings[0], limit + 1);
+    const scores = new Map(matches.filter(match => match.score >= minScore).map(match => [match.id, match.score]));
+    if (scores.size === 0) return [];
+
+    const memories = await Memory.find({ _id: { $in: Array.from(scores.keys()) }, projectId }).select('-embedding');
+
+    return memories
+      .filter(memory => !(memory.runId === options.runId && memory.stageId === options.stageId))
+      .map(memory => ({
+        id: String(memory._id),
+        runId: memory.runId,
+        stageId: memory.stageId,
+        agentRole: memory.agentRole,
+        summary: memory.summary,
+        score: scores.get(String(memory._id)) || 0,
+        createdAt: memory.createdAt
+      }))
+      .sort((a, b) => b.score - a.score)
+      .slice(0, limit);
+  }
+
+  // Masks the terms in the run's summaries. Embeddings are left as they are; they cannot be read
+  // back into text, and recomputing them would send the summaries out again.
+  async redactRun(runId: string, terms: string[]): Promise<number> {
+    let redactions = 0;
+    for (const memory of await Memory.find({ runId })) {
+      const summary = this.redactor.redactText(memory.summary, terms);
+      if (summary.count === 0) continue;
+
+      memory.summary = summary.value;
+      memory.redactions += summary.count;
+      await memory.save();
+      redactions += summary.count;
+    }
+    return redactions;
+  }
+
+  async forgetRun(runId: string): Promise<number> {
+    const ids = (await Memory.find({ runId }).select('_id')).map(memory => String(memory._id));
+    await this.store.remove(ids);
+    const result = await Memory.deleteMany({ runId });
+    return result.deletedCount;
+  }
+
+  // The stage's request and its final answer; the request is cut short first, since upstream
+  // outputs and files in it are remembered by the stages that produced them
+  private source(transcript: ITranscript): string | undefined {
+    const request = transcript.messages.find(message => message.role === 'user')?.content || '';
+    const answer = [...transcript.messages].reverse().find(message => message.role === 'assistant')?.content || '';
+    if (!answer) return undefined;
+
+    const requestBudget = Math.min(request.length, Math.floor(this.sourceChars / 3));
+    const answerBudget = this.sourceChars - requestBudget;
+    const clip = (text: string, max: number) => text.length > max ? `${text.slice(0, max)}\n[truncated]` : text;
+
+    return [
+      `Stage: ${transcript.stageId}${transcript.agentRole ? ` (${transcript.agentRole})` : ''}`,
+      `Request:\n${clip(request, requestBudget)}`,
+      `Result:\n${clip(answer, answerBudget)}`
+    ].join('\n\n');
+  }
+
+  private embeddingText(agentRole: string | undefined, stageId: string, summary: string): string {
+    return `${agentRole || stageId} (${stageId}):\n${summary}`;
+  }
+}
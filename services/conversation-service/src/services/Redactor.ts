@@ -0,0 +1,85 @@
+export interface Redacted<T> {
+  value: T;
+  count: number;
+}
+
+interface RedactionRule {
+  name: string;
+  pattern: RegExp;
+  replace: (match: string, ...groups: string[]) => string;
+}
+
+const mask = (name: string) => () => `[REDACTED:${name}]`;
+
+const BUILTIN_RULES: RedactionRule[] = [
+  {
+    name: 'private-key',
+    pattern: /-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----/g,
+    replace: mask('private-key')
+  },
+  { name: 'api-key', pattern: /\bsk-(?:ant-)?[A-Za-z0-9_-]{20,}/g, replace: mask('api-key') },
+  { name: 'github-token', pattern: /\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})/g, replace: mask('github-token') },
+  { name: 'gitlab-token', pattern: /\bglpat-[A-Za-z0-9_-]{20,}/g, replace: mask('gitlab-token') },
+  { name: 'aws-key', pattern: /\b(?:AKIA|ASIA)[0-9A-Z]{16}\b/g, replace: mask('aws-key') },
+  { name: 'bearer-token', pattern: /\bBearer\s+[A-Za-z0-9._~+/-]{16,}=*/g, replace: mask('bearer-token') },
+  // Keeps the key so the transcript still shows which setting was there
+  {
+    name: 'secret',
+    pattern: /\b(password|passwd|secret|api[_-]?key|access[_-]?token|client[_-]?secret)(\s*[:=]\s*)(["']?)[^\s"'`,;]{6,}\3/gi,
+    replace: (match, key, separator, quote) => `${key}${separator}${quote}[REDACTED:secret]${quote}`
+  }
+];
+
+const escapeRegExp = (value: string) => value.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+
+// Masks credentials and other sensitive values in transcripts and stage state before they are
+// stored, and on request afterwards. REDACTION_PATTERNS adds comma-separated regexes.
+export class Redactor {
+  private rules: RedactionRule[];
+
+  constructor(extraPatterns: string = process.env.REDACTION_PATTERNS || '') {
+    const custom = extraPatterns.split(',').map(pattern => pattern.trim()).filter(Boolean)
+      .map(pattern => ({ name: 'custom', pattern: new RegExp(pattern, 'g'), replace: mask('custom') }));
+    this.rules = [...BUILTIN_RULES, ...custom];
+  }
+
+  // Applies the built-in rules plus any literal terms supplied by the caller
+  redactText(text: string, terms: string[] = []): Redacted<string> {
+    let count = 0;
+    let value = text;
+
+    const rules = [
+      ...this.rules,
+      ...terms.filter(Boolean).map(term => ({ name: 'term', pattern: new RegExp(escapeRegExp(term), 'g'), replace: () => '[REDACTED]' }))
+    ];
+
+    for (const rule of rules) {
+      value = value.replace(rule.pattern, (match: string, ...groups: any[]) => {
+        count++;
+        return rule.replace(match, ...groups);
+      });
+    }
+
+    return { value, count };
+  }
+
+  // Walks objects and arrays, redacting every string it finds
+  redactValue<T>(input: T, terms: string[] = []): Redacted<T> {
+    let count = 0;
+
+    const walk = (value: any): any => {
+      if (typeof value === 'string') {
+        const result = this.redactText(value, terms);
+        count += result.count;
+        return result.value;
+      }
+      if (Array.isArray(value)) return value.map(walk);
+      if (value && typeof value === 'object' && !(value instanceof Date)) {
+        return Object.fromEntries(Object.entries(value).map(([key, entry]) => [key, walk(entry)]));
+      }
+      return value;
+    };
+
+    return { value: walk(input), count };
+  }
+}
@@ -0,0 +1,34 @@
+import axios from 'axios';
+import { StageState } from '../models/StageState.js';
+import { Transcript } from '../models/Transcript.js';
+import { User } from '../middleware/auth.js';
+
+// Whether the caller can see a project, asked of project-service as the caller. Throws when
+// project-service cannot answer.
+export const canAccessProject = async (projectId: string, authorization: string): Promise<boolean> => {
+  const projectServiceUrl = process.env.PROJECT_SERVICE_URL || 'http://localhost:3002';
+  try {
+    await axios.get(`${projectServiceUrl}/api/projects/${encodeURIComponent(projectId)}`, {
+      headers: { Authorization: authorization },
+      timeout: 5000
+    });
+    return true;
+  } catch (error) {
+    if (!axios.isAxiosError(error) || !error.response) throw error;
+    return false;
+  }
+};
+
+// Run IDs are guessable, so transcripts are only shown to the run's owner: members of its project,
+// or for runs without a project the user who started it. Admins see every run. Resolves to null
+// when nothing is recorded for the run.
+export const canAccessRun = async (runId: string, user: User, authorization: string): Promise<boolean | null> => {
+  // Checkpoints come from pipeline-service and carry the tenant; transcripts only the project
+  const state = await StageState.findOne({ runId }).select('projectId tenantId');
+  const owner = state || await Transcript.findOne({ runId }).select('projectId');
+  if (!owner) return null;
+  if (user.role === 'admin') return true;
+  if (!owner.projectId) return !!state?.tenantId && state.tenantId === user._id;
+
+  return canAccessProject(owner.projectId, authorization);
+};
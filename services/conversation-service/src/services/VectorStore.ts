@@ -0,0 +1,141 @@
+import axios from 'axios';
+import { Memory } from '../models/Memory.js';
+
+export interface VectorMatch {
+  // ID of the Memory document the vector belongs to
+  id: string;
+  score: number;
+}
+
+export interface VectorStore {
+  readonly name: string;
+  upsert(id: string, vector: number[], payload: { projectId: string; runId: string; stageId: string }): Promise<void>;
+  search(projectId: string, vector: number[], limit: number): Promise<VectorMatch[]>;
+  remove(ids: string[]): Promise<void>;
+}
+
+export function cosineSimilarity(a: number[], b: number[]): number {
+  if (a.length === 0 || a.length !== b.length) return 0;
+  let dot = 0;
+  let normA = 0;
+  let normB = 0;
+  for (let i = 0; i < a.length; i++) {
+    dot += a[i] * b[i];
+    normA += a[i] * a[i];
+    normB += b[i] * b[i];
+  }
+  return normA === 0 || normB === 0 ? 0 : dot / Math.sqrt(normA * normB);
+}
+
+// Scores a project's memories in process from the embeddings kept on the documents. Enough for the
+// few hundred stages a project accumulates; MEMORY_SCAN_LIMIT caps how many recent ones are read.
+export class MongoVectorStore implements VectorStore {
+  readonly name = 'mongo';
+  private scanLimit = parseInt(process.env.MEMORY_SCAN_LIMIT || '2000');
+
+  async upsert(): Promise<void> {
+    // The embedding is already stored on the memory itself
+  }
+
+  async search(projectId: string, vector: number[], limit: number): Promise<VectorMatch[]> {
+    const memories = await Memory.find({ projectId })
+      .select('embedding')
+      .sort({ createdAt: -1 })
+      .limit(this.scanLimit)
+      .lean();
+
+    return memories
+      // Vectors from a different embedding model have another length and cannot be compared
+      .filter(memory => memory.embedding.length === vector.length)
+      .map(memory => ({ id: String(memory._id), score: cosineSimilarity(vector, memory.embedding) }))
+      .sort((a, b) => b.score - a.score)
+      .slice(0, limit);
+  }
+
+  async remove(): Promise<void> {
+    // Removed along with the memory documents
+  }
+}
+
+// Qdrant over its REST API. The collection is created on first write with the embedding model's
+// dimensions; changing LLM_EMBEDDING_MODEL to one of another size needs a new MEMORY_QDRANT_COLLECTION.
+export class QdrantVectorStore implements VectorStore {
+  readonly name = 'qdrant';
+  private collection = process.env.MEMORY_QDRANT_COLLECTION || 'conversation-memory';
+  private ready = false;
+
+  constructor(private baseUrl: string, private apiKey: string | undefined = process.env.QDRANT_API_KEY) {}
+
+  async upsert(id: string, vector: number[], payload: { projectId: string; runId: string; stageId: string }): Promise<void> {
+    await this.ensureCollection(vector.length);
+    await this.request('put', `/collections/${this.collection}/points?wait=true`, {
+      points: [{ id: this.pointId(id), vector, payload: { ...payload, memoryId: id } }]
+    });
+  }
+
+  async search(projectId: string, vector: number[], limit: number): Promise<VectorMatch[]> {
+    try {
+      const result = await this.request('post', `/collections/${this.collection}/points/search`, {
+        vector,
+        limit,
+        filter: { must: [{ key: 'projectId', match: { value: projectId } }] },
+        with_payload: ['memoryId']
+      });
+      return (result || []).map((point: any) => ({ id: point.payload.memoryId, score: point.score }));
+    } catch (error) {
+      // Nothing has been remembered yet
+      if (axios.isAxiosError(error) && error.response?.status === 404) return [];
+      throw error;
+    }
+  }
+
+  async remove(ids: string[]): Promise<void> {
+    if (ids.length === 0) return;
+    try {
+      await this.request('post', `/collections/${this.collection}/points/delete?wait=true`, {
+        points: ids.map(id => this.pointId(id))
+      });
+    } catch (error) {
+      if (axios.isAxiosError(error) && error.response?.status === 404) return;
+      throw error;
+    }
+  }
+
+  private async ensureCollection(size: number): Promise<void> {
+    if (this.ready) return;
+    try {
+      await this.request('get', `/collections/${this.collection}`);
+    } catch (error) {
+      if (!axios.isAxiosError(error) || error.response?.status !== 404) throw error;
+      await this.request('put', `/collections/${this.collection}`, {
+        vectors: { size, distance: 'Cosine' }
+      });
+      await this.request('put', `/collections/${this.collection}/index`, {
+        field_name: 'projectId',
+        field_schema: 'keyword'
+      });
+    }
+    this.ready = true;
+  }
+
+  // Qdrant point IDs are UUIDs or integers; ObjectIds are zero-padded into a UUID
+  private pointId(id: string): string {
+    const hex = id.padStart(32, '0');
+    return `${hex.slice(0, 8)}-${hex.slice(8, 12)}-${hex.slice(12, 16)}-${hex.slice(16, 20)}-${hex.slice(20)}`;
+  }
+
+  private async request(method: 'get' | 'put' | 'post', path: string, data?: Record<string, any>): Promise<any> {
+    const response = await axios.request({
+      method,
+      url: `${this.baseUrl}${path}`,
+      data,
+      timeout: 10000,
+      headers: this.apiKey ? { 'api-key': this.apiKey } : undefined
+    });
+    return response.data.result;
+  }
+}
+
+export function createVectorStore(): VectorStore {
+  return process.env.QDRANT_URL ? new QdrantVectorStore(process.env.QDRANT_URL) : new MongoVectorStore();
+}
@@ -0,0 +1,73 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export interface DiagramRender {
+  format: 'svg' | 'png';
+  artifactId: string;
+  size: number;
+}
+
+export interface IDiagram extends Document {
+  projectId?: string;
+  runId?: string;
+  stageId?: string;
+  name: string;
+  title: string;
+  sourceType: 'mermaid' | 'plantuml';
+  source: string;
+  status: 'rendered' | 'failed';
+  renders: DiagramRender[];
+  error?: string;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const RenderSchema = new Schema({
+  format: {
+    type: String,
+    enum: ['svg', 'png'],
+    required: true
+  },
+  artifactId: { type: String, required: true },
+  size: { type: Number, required: true }
+}, { _id: false });
+
+const DiagramSchema: Schema = new Schema({
+  projectId: {
+    type: String,
+    index: true
+  },
+  runId: {
+    type: String,
+    index: true
+  },
+  stageId: { type: String },
+  name: {
+    type: String,
+    required: true
+  },
+  title: {
+    type: String,
+    required: true
+  },
+  sourceType: {
+    type: String,
+    enum: ['mermaid', 'plantuml'],
+    required: true
+  },
+  // Kept so diagrams can be re-rendered or edited without rerunning the architect
+  source: {
+    type: String,
+    required: true
+  },
+  status: {
+    type: String,
+    enum: ['rendered', 'failed'],
+    required: true
+  },
+  renders: [RenderSchema],
+  error: { type: String }
+}, {
+  timestamps: true
+});
+
+export const Diagram = mongoose.model<IDiagram>('Diagram', DiagramSchema);
@@ -0,0 +1,274 @@
+import express, { Request, Response } from 'express';
+import { body, param, query, validationResult } from 'express-validator';
+import { Diagram } from '../models/Diagram.js';
+import { DiagramBuilder } from '../services/DiagramBuilder.js';
+import { DiagramPublisher } from '../services/DiagramPublisher.js';
+import { ArtifactClient } from '../services/ArtifactClient.js';
+import { LinkSigner } from '../services/LinkSigner.js';
+import { requireAuth, requireServiceToken } from '../middleware/auth.js';
+
+const router = express.Router();
+const builder = new DiagramBuilder();
+const publisher = new DiagramPublisher();
+const artifacts = new ArtifactClient();
+const signer = new LinkSigner();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const formatsBody = [
+  body('formats').optional().isArray({ min: 1 }).withMessage('Formats must be a non-empty array'),
+  body('formats.*').optional().isIn(['svg', 'png']).withMessage('Formats must be svg or png')
+];
+
+const DEFAULT_LINK_TTL_DAYS = parseInt(process.env.DIAGRAM_LINK_TTL_DAYS || '30');
+// Storage URLs behind a signed link only need to live long enough for the redirect to be followed
+const REDIRECT_URL_TTL_SECONDS = 300;
+
+// POST /api/diagrams/architecture - Render the diagrams for an architect-stage output (internal;
+// renders are stored under the given project and run, so only the orchestrator may request them)
+router.post('/architecture',
+  requireServiceToken,
+  [
+    body('architecture').isObject().withMessage('Architecture output is required'),
+    body('projectName').optional().isString().withMessage('Project name must be a string'),
+    ...formatsBody
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const { architecture, projectName, projectId, runId, stageId, formats } = req.body;
+      const sources = builder.fromArchitecture(architecture, projectName);
+
+      if (sources.length === 0) {
+        return res.status(400).json({
+          success: false,
+          error: 'The architecture output has no tech stack, components, files or diagrams to draw'
+        });
+      }
+
+      const diagrams = [];
+      for (const source of sources) {
+        diagrams.push(await publisher.publish(source, { projectId, runId, stageId }, formats));
+      }
+
+      res.status(201).json({
+        success: true,
+        data: {
+          diagrams,
+          failed: diagrams.filter(diagram => diagram.status === 'failed').length
+        }
+      });
+    } catch (error) {
+      console.error('Error rendering architecture diagrams:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to render architecture diagrams'
+      });
+    }
+  }
+);
+
+// POST /api/diagrams - Render a single Mermaid or PlantUML source
+router.post('/',
+  requireAuth,
+  [
+    body('title').trim().isLength({ min: 1, max: 200 }).withMessage('Title is required'),
+    body('type').isIn(['mermaid', 'plantuml']).withMessage('Type must be mermaid or plantuml'),
+    body('source').isString().isLength({ min: 1, max: 100000 }).withMessage('Source is required'),
+    body('projectId').optional().isString().withMessage('Project ID must be a string'),
+    ...formatsBody
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const { title, type, source, projectId, formats } = req.body;
+      const name = (req.body.name || title).toLowerCase().replace(/[^a-z0-9]+/g, '-').replace(/^-+|-+$/g, '') || 'diagram';
+
+      const diagram = await publisher.publish({ name, title, type, source }, { projectId }, formats);
+
+      if (diagram.status === 'failed') {
+        return res.status(400).json({
+          success: false,
+          error: diagram.error,
+          data: diagram
+        });
+      }
+
+      res.status(201).json({
+        success: true,
+        data: diagram
+      });
+    } catch (error) {
+      console.error('Error rendering diagram:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to render diagram'
+      });
+    }
+  }
+);
+
+// GET /api/diagrams/public/:file - Follow a signed documentation link, e.g. <id>.svg?expires=&sig=
+router.get('/public/:file',
+  [
+    param('file').matches(/^[0-9a-f]{24}\.(svg|png)$/).withMessage('Invalid diagram link'),
+    query('expires').isInt().withMessage('Invalid diagram link'),
+    query('sig').isString().withMessage('Invalid diagram link')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const [diagramId, format] = req.params.file.split('.');
+      if (!signer.verify(diagramId, format, parseInt(req.query.expires as string), req.query.sig as string)) {
+        return res.status(403).json({
+          success: false,
+          error: 'Link is invalid or has expired'
+        });
+      }
+
+      const diagram = await Diagram.findById(diagramId);
+      const render = diagram?.renders.find(candidate => candidate.format === format);
+      if (!render) {
+        return res.status(404).json({
+          success: false,
+          error: 'Diagram not found'
+        });
+      }
+
+      res.redirect(302, await artifacts.downloadUrl(render.artifactId, REDIRECT_URL_TTL_SECONDS));
+    } catch (error) {
+      console.error('Error resolving diagram link:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to resolve diagram link'
+      });
+    }
+  }
+);
+
+// GET /api/diagrams - Diagrams for a project or run
+router.get('/',
+  requireAuth,
+  [
+    query('projectId').optional().isString().withMessage('Project ID must be a string'),
+    query('runId').optional().isString().withMessage('Run ID must be a string')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const { projectId, runId } = req.query;
+      if (!projectId && !runId) {
+        return res.status(400).json({
+          success: false,
+          error: 'projectId or runId is required'
+        });
+      }
+
+      const filter: any = {};
+      if (projectId) filter.projectId = projectId;
+      if (runId) filter.runId = runId;
+
+      const diagrams = await Diagram.find(filter).select('-source').sort({ createdAt: -1 }).limit(200);
+
+      res.json({
+        success: true,
+        data: diagrams
+      });
+    } catch (error) {
+      console.error('Error fetching diagrams:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch diagrams'
+      });
+    }
+  }
+);
+
+// GET /api/diagrams/:id - Diagram with its source
+router.get('/:id',
+  requireAuth,
+  [param('id').isMongoId().withMessage('Invalid diagram ID')],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const diagram = await Diagram.findById(req.params.id);
+      if (!diagram) {
+        return res.status(404).json({
+          success: false,
+          error: 'Diagram not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: diagram
+      });
+    } catch (error) {
+      console.error('Error fetching diagram:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch diagram'
+      });
+    }
+  }
+);
+
+// GET /api/diagrams/:id/links - Signed links to each rendered format, for embedding in documentation
+router.get('/:id/links',
+  requireAuth,
+  [
+    param('id').isMongoId().withMessage('Invalid diagram ID'),
+    query('expiresInDays').optional().isInt({ min: 1, max: 365 }).withMessage('expiresInDays must be between 1 and 365')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const diagram = await Diagram.findById(req.params.id);
+      if (!diagram) {
+        return res.status(404).json({
+          success: false,
+          error: 'Diagram not found'
+        });
+      }
+      if (diagram.status !== 'rendered') {
+        return res.status(409).json({
+          success: false,
+          error: `Diagram failed to render: ${diagram.error}`
+        });
+      }
+
+      const days = parseInt(req.query.expiresInDays as string) || DEFAULT_LINK_TTL_DAYS;
+      const expiresAt = new Date(Date.now() + days * 24 * 60 * 60 * 1000);
+
+      res.json({
+        success: true,
+        data: {
+          expiresAt: expiresAt.toISOString(),
+          links: Object.fromEntries(diagram.renders.map(render => [render.format, signer.sign(diagram.id, render.format, expiresAt)])),
+          markdown: diagram.renders.some(render => render.format === 'svg')
+            ? `![${diagram.title}](${signer.sign(diagram.id, 'svg', expiresAt)})`
+            : undefined
+        }
+      });
+    } catch (error) {
+      console.error('Error signing diagram links:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to sign diagram links'
+      });
+    }
+  }
+);
+
+export default router;
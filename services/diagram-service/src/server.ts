@@ -0,0 +1,119 @@
+import express from 'express';
+import cors from 'cors';
+import mongoose from 'mongoose';
+import dotenv from 'dotenv';
+import winston from 'winston';
+import diagramRoutes from './routes/diagrams.js';
+
+// Load environment variables
+dotenv.config();
+
+const app = express();
+const PORT = process.env.PORT || 3019;
+
+// Logger configuration
+const logger = winston.createLogger({
+  level: 'info',
+  format: winston.format.combine(
+    winston.format.timestamp(),
+    winston.format.json()
+  ),
+  transports: [
+    new winston.transports.Console(),
+    new winston.transports.File({ filename: 'logs/diagram-service.log' })
+  ]
+});
+
+// Database connection
+const MONGODB_URI = process.env.MONGODB_URI || 'mongodb://localhost:27017/ai-pipeline';
+
+mongoose.connect(MONGODB_URI)
+  .then(() => {
+    logger.info('🗄️  Connected to MongoDB');
+  })
+  .catch((error) => {
+    logger.error('❌ MongoDB connection error:', error);
+    process.exit(1);
+  });
+
+// Middleware
+app.use(cors({
+  origin: process.env.FRONTEND_URL || 'http://localhost:5173',
+  credentials: true
+}));
+app.use(express.json({ limit: '5mb' }));
+app.use(express.urlencoded({ extended: true }));
+
+// Request logging middleware
+app.use((req, res, next) => {
+  logger.info(`${req.method} ${req.path}`, {
+    ip: req.ip,
+    userAgent: req.get('User-Agent'),
+    timestamp: new Date().toISOString()
+  });
+  next();
+});
+
+// Routes
+app.use('/api/diagrams', diagramRoutes);
+
+// Health check endpoint
+app.get('/health', (req, res) => {
+  res.json({
+    status: 'ok',
+    service: 'diagram-service',
+    timestamp: new Date().toISOString(),
+    version: '1.0.0',
+    database: mongoose.connection.readyState === 1 ? 'connected' : 'disconnected'
+  });
+});
+
+// Service info endpoint
+app.get('/info', (req, res) => {
+  res.json({
+    service: 'Diagram Service',
+    version: '1.0.0',
+    description: 'Renders architecture diagrams and serves them through signed links',
+    endpoints: [
+      { method: 'POST', path: '/api/diagrams/architecture', description: 'Render diagrams for an architect output' },
+      { method: 'POST', path: '/api/diagrams', description: 'Render a Mermaid or PlantUML source' },
+      { method: 'GET', path: '/api/diagrams', description: 'List diagrams' },
+      { method: 'GET', path: '/api/diagrams/:id', description: 'Diagram with source' },
+      { method: 'GET', path: '/api/diagrams/:id/links', description: 'Signed documentation links' },
+      { method: 'GET', path: '/api/diagrams/public/:id.:format', description: 'Follow a signed link' }
+    ]
+  });
+});
+
+// Catch-all for undefined routes
+app.use('*', (req, res) => {
+  res.status(404).json({
+    success: false,
+    error: 'Route not found',
+    service: 'diagram-service'
+  });
+});
+
+// Global error handler
+app.use((err: any, req: any, res: any, next: any) => {
+  logger.error('Unhandled error:', err);
+  res.status(500).json({
+    success: false,
+    error: 'Internal server error',
+    service: 'diagram-service'
+  });
+});
+
+// Graceful shutdown
+process.on('SIGTERM', () => {
+  logger.info('SIGTERM received. Shutting down gracefully...');
+  mongoose.connection.close();
+  process.exit(0);
+});
+
+// Start server
+app.listen(PORT, () => {
+  logger.info(`📐 Diagram Service running on port ${PORT}`);
+});
+
+export default app;
@@ -0,0 +1,45 @@
+import axios from 'axios';
+
+export interface StoredArtifact {
+  _id: string;
+  size: number;
+}
+
+// Stores rendered diagrams as artifacts and asks the artifact service for signed download links
+export class ArtifactClient {
+  private baseUrl: string;
+
+  constructor(baseUrl?: string) {
+    this.baseUrl = baseUrl || process.env.ARTIFACT_SERVICE_URL || 'http://localhost:3008';
+  }
+
+  async store(content: Buffer, details: {
+    name: string;
+    contentType: string;
+    projectId?: string;
+    runId?: string;
+    stageId?: string;
+    metadata?: Record<string, string>;
+  }): Promise<StoredArtifact> {
+    const { data } = await axios.post(`${this.baseUrl}/api/artifacts`, {
+      ...details,
+      type: 'diagram',
+      content: content.toString('base64'),
+      encoding: 'base64'
+    }, { headers: this.headers(), timeout: 30000 });
+    return data.data;
+  }
+
+  async downloadUrl(artifactId: string, expiresInSeconds: number): Promise<string> {
+    const { data } = await axios.get(`${this.baseUrl}/api/artifacts/${artifactId}/download-url`, {
+      params: { expiresIn: expiresInSeconds },
+      headers: this.headers(),
+      timeout: 5000
+    });
+    return data.data.url;
+  }
+
+  private headers() {
+    return { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' };
+  }
+}
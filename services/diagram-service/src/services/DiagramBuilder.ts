@@ -0,0 +1,122 @@
+export type DiagramSourceType = 'mermaid' | 'plantuml';
+
+export interface DiagramSource {
+  name: string;
+  title: string;
+  type: DiagramSourceType;
+  source: string;
+}
+
+// Shape produced by the architect role (agent-service/roles/architect.yaml)
+export interface ArchitectureOutput {
+  summary?: string;
+  techStack?: { frontend?: string[]; backend?: string[]; database?: string | string[] };
+  components?: Array<{ name: string; responsibility?: string }>;
+  files?: Array<{ path: string; purpose?: string }>;
+  // Diagrams the architect wrote itself
+  diagrams?: Array<{ name?: string; title?: string; type?: string; source?: string }>;
+}
+
+const MAX_TREE_FILES = 60;
+
+// Mermaid labels are quoted; quotes and angle brackets inside them need entity codes
+const label = (text: string) => text.replace(/"/g, '#quot;').replace(/</g, '#lt;').replace(/>/g, '#gt;');
+
+const slug = (text: string) => text.toLowerCase().replace(/[^a-z0-9]+/g, '-').replace(/^-+|-+$/g, '') || 'diagram';
+
+const asList = (value: string | string[] | undefined): string[] =>
+  (Array.isArray(value) ? value : value ? [value] : []).filter(item => typeof item === 'string' && item.trim());
+
+// Turns an architect-stage output into diagram sources: an architecture overview and a file layout
+// derived from the structured fields, plus any Mermaid or PlantUML the architect included
+export class DiagramBuilder {
+  fromArchitecture(architecture: ArchitectureOutput, projectName = 'System'): DiagramSource[] {
+    const diagrams: DiagramSource[] = [];
+
+    const overview = this.overview(architecture, projectName);
+    if (overview) diagrams.push(overview);
+
+    const layout = this.fileLayout(architecture.files || []);
+    if (layout) diagrams.push(layout);
+
+    for (const [index, diagram] of (architecture.diagrams || []).entries()) {
+      if (!diagram?.source || !['mermaid', 'plantuml'].includes(diagram.type || 'mermaid')) continue;
+      const title = diagram.title || diagram.name || `Diagram ${index + 1}`;
+      diagrams.push({
+        name: slug(diagram.name || title),
+        title,
+        type: (diagram.type || 'mermaid') as DiagramSourceType,
+        source: diagram.source
+      });
+    }
+
+    return diagrams;
+  }
+
+  private overview(architecture: ArchitectureOutput, projectName: string): DiagramSource | undefined {
+    const frontend = asList(architecture.techStack?.frontend);
+    const backend = asList(architecture.techStack?.backend);
+    const databases = asList(architecture.techStack?.database);
+    const components = (architecture.components || []).filter(component => component?.name);
+
+    if (frontend.length + backend.length + databases.length + components.length === 0) return undefined;
+
+    const lines = ['flowchart LR', `  user(["User"])`];
+    const tier = (id: string, title: string, items: string[]) => {
+      if (items.length === 0) return;
+      lines.push(`  subgraph ${id}["${label(title)}"]`);
+      items.forEach((item, index) => lines.push(`    ${id}${index}["${label(item)}"]`));
+      lines.push('  end');
+    };
+
+    tier('fe', 'Frontend', frontend);
+    tier('be', 'Backend', backend);
+    databases.forEach((database, index) => lines.push(`  db${index}[("${label(database)}")]`));
+
+    if (components.length > 0) {
+      lines.push(`  subgraph comp["${label(`${projectName} components`)}"]`);
+      components.forEach((component, index) => {
+        const text = component.responsibility ? `${label(component.name)}<br/>${label(component.responsibility)}` : label(component.name);
+        lines.push(`    c${index}["${text}"]`);
+      });
+      lines.push('  end');
+    }
+
+    // The architect output has no explicit relationships, so tiers are wired front to back
+    const flow = ['user', frontend.length ? 'fe' : '', backend.length ? 'be' : components.length ? 'comp' : ''].filter(Boolean);
+    for (let index = 1; index < flow.length; index++) lines.push(`  ${flow[index - 1]} --> ${flow[index]}`);
+    if (backend.length && components.length) lines.push('  be --- comp');
+    databases.forEach((_, index) => lines.push(`  ${flow[flow.length - 1]} --> db${index}`));
+
+    return { name: 'architecture', title: `${projectName} architecture`, type: 'mermaid', source: lines.join('\n') };
+  }
+
+  private fileLayout(files: Array<{ path: string; purpose?: string }>): DiagramSource | undefined {
+    const paths = files.map(file => file?.path).filter((path): path is string => typeof path === 'string' && path.length > 0);
+    if (paths.length === 0) return undefined;
+
+    const lines = ['flowchart LR', '  root["/"]'];
+    const ids = new Map<string, string>([['', 'root']]);
+
+    for (const path of paths.slice(0, MAX_TREE_FILES)) {
+      const parts = path.replace(/^\.?\//, '').split('/').filter(Boolean);
+      let parent = '';
+      parts.forEach((part, index) => {
+        const current = parent ? `${parent}/${part}` : part;
+        if (!ids.has(current)) {
+          const id = `n${ids.size}`;
+          ids.set(current, id);
+          const isDirectory = index < parts.length - 1;
+          lines.push(`  ${id}${isDirectory ? `["${label(part)}/"]` : `("${label(part)}")`}`);
+          lines.push(`  ${ids.get(parent)} --> ${id}`);
+        }
+        parent = current;
+      });
+    }
+    if (paths.length > MAX_TREE_FILES) {
+      lines.push(`  more["… ${paths.length - MAX_TREE_FILES} more files"]`, '  root -.-> more');
+    }
+
+    return { name: 'file-layout', title: 'File layout', type: 'mermaid', source: lines.join('\n') };
+  }
+}
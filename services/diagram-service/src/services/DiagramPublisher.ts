@@ -0,0 +1,55 @@
+import { Diagram, IDiagram, DiagramRender } from '../models/Diagram.js';
+import { DiagramSource } from './DiagramBuilder.js';
+import { Renderer, RenderFormat, CONTENT_TYPES } from './Renderer.js';
+import { ArtifactClient } from './ArtifactClient.js';
+
+export interface PublishContext {
+  projectId?: string;
+  runId?: string;
+  stageId?: string;
+}
+
+export const DEFAULT_FORMATS = (process.env.DIAGRAM_FORMATS || 'svg,png')
+  .split(',')
+  .map(format => format.trim())
+  .filter((format): format is RenderFormat => format === 'svg' || format === 'png');
+
+// Renders a diagram in each format, stores the images as artifacts and records the diagram. A
+// source that fails to render is still recorded, with the error, so it can be fixed and re-rendered.
+export class DiagramPublisher {
+  constructor(
+    private renderer: Renderer = new Renderer(),
+    private artifacts: ArtifactClient = new ArtifactClient()
+  ) {}
+
+  async publish(diagram: DiagramSource, context: PublishContext, formats: RenderFormat[] = DEFAULT_FORMATS): Promise<IDiagram> {
+    const renders: DiagramRender[] = [];
+    let error: string | undefined;
+
+    try {
+      for (const format of formats) {
+        const image = await this.renderer.render(diagram.type, diagram.source, format);
+        const artifact = await this.artifacts.store(image, {
+          name: `${diagram.name}.${format}`,
+          contentType: CONTENT_TYPES[format],
+          ...context,
+          metadata: { diagram: diagram.name, sourceType: diagram.type, format }
+        });
+        renders.push({ format, artifactId: artifact._id, size: artifact.size });
+      }
+    } catch (renderError) {
+      error = renderError instanceof Error ? renderError.message : String(renderError);
+    }
+
+    return Diagram.create({
+      ...context,
+      name: diagram.name,
+      title: diagram.title,
+      sourceType: diagram.type,
+      source: diagram.source,
+      status: error ? 'failed' : 'rendered',
+      renders: error ? [] : renders,
+      error
+    });
+  }
+}
@@ -0,0 +1,31 @@
+import { createHmac, timingSafeEqual } from 'crypto';
+
+// Signs stable, long-lived diagram links for documentation. A valid link redirects to a short-lived
+// storage URL, so documentation keeps working after individual download URLs expire.
+export class LinkSigner {
+  private secret: string;
+  private publicUrl: string;
+
+  constructor(secret?: string, publicUrl?: string) {
+    this.secret = secret || process.env.DIAGRAM_LINK_SECRET || process.env.JWT_SECRET || 'dev-diagram-link-secret';
+    this.publicUrl = (publicUrl || process.env.DIAGRAM_PUBLIC_URL || 'http://localhost:3000').replace(/\/$/, '');
+  }
+
+  sign(diagramId: string, format: string, expiresAt: Date): string {
+    const expires = Math.floor(expiresAt.getTime() / 1000);
+    const signature = this.signature(diagramId, format, expires);
+    return `${this.publicUrl}/api/diagrams/public/${diagramId}.${format}?expires=${expires}&sig=${signature}`;
+  }
+
+  verify(diagramId: string, format: string, expires: number, signature: string): boolean {
+    if (!Number.isFinite(expires) || expires * 1000 < Date.now()) return false;
+
+    const expected = Buffer.from(this.signature(diagramId, format, expires));
+    const actual = Buffer.from(signature);
+    return expected.length === actual.length && timingSafeEqual(expected, actual);
+  }
+
+  private signature(diagramId: string, format: string, expires: number): string {
+    return createHmac('sha256', this.secret).update(`${diagramId}.${format}.${expires}`).digest('base64url');
+  }
+}
@@ -0,0 +1,35 @@
+import axios from 'axios';
+import { DiagramSourceType } from './DiagramBuilder.js';
+
+export type RenderFormat = 'svg' | 'png';
+
+export const CONTENT_TYPES: Record<RenderFormat, string> = {
+  svg: 'image/svg+xml',
+  png: 'image/png'
+};
+
+// Renders Mermaid and PlantUML through a Kroki server (KROKI_URL), which runs the actual renderers
+export class Renderer {
+  private baseUrl: string;
+
+  constructor(baseUrl?: string) {
+    this.baseUrl = (baseUrl || process.env.KROKI_URL || 'http://localhost:8000').replace(/\/$/, '');
+  }
+
+  async render(type: DiagramSourceType, source: string, format: RenderFormat): Promise<Buffer> {
+    try {
+      const response = await axios.post(`${this.baseUrl}/${type}/${format}`, source, {
+        headers: { 'Content-Type': 'text/plain' },
+        responseType: 'arraybuffer',
+        timeout: parseInt(process.env.DIAGRAM_RENDER_TIMEOUT_MS || '30000')
+      });
+      return Buffer.from(response.data);
+    } catch (error) {
+      // Kroki answers syntax errors with a 400 and a plain-text explanation
+      if (axios.isAxiosError(error) && error.response?.status === 400) {
+        throw new Error(`Invalid ${type} source: ${Buffer.from(error.response.data).toString('utf-8').slice(0, 500)}`);
+      }
+      throw error;
+    }
+  }
+}
@@ -0,0 +1,59 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export interface IEvalDataset extends Document {
+  name: string;
+  description: string;
+  // Agent role the cases are written for, e.g. architect; cases hold that role's inputs
+  role?: string;
+  tags: string[];
+  latestVersion: number;
+  // Named versions such as baseline or stable; moving a label does not change any version
+  labels: Map<string, number>;
+  ownerId: string;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const EvalDatasetSchema: Schema = new Schema({
+  name: {
+    type: String,
+    required: true,
+    unique: true,
+    trim: true,
+    maxlength: 100,
+    match: /^[a-z0-9][a-z0-9._-]*$/
+  },
+  description: {
+    type: String,
+    default: '',
+    maxlength: 2000
+  },
+  role: {
+    type: String,
+    trim: true,
+    index: true
+  },
+  tags: [{
+    type: String,
+    trim: true
+  }],
+  latestVersion: {
+    type: Number,
+    default: 0
+  },
+  labels: {
+    type: Map,
+    of: Number,
+    default: {}
+  },
+  ownerId: {
+    type: String,
+    required: true
+  }
+}, {
+  timestamps: true
+});
+
+EvalDatasetSchema.index({ tags: 1 });
+
+export const EvalDataset = mongoose.model<IEvalDataset>('EvalDataset', EvalDatasetSchema);
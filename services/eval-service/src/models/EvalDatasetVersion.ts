@@ -0,0 +1,103 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+// contains / not_contains: text (or the value at path) includes the value
+// matches: text (or the value at path) matches the regular expression in value
+// equals: the value at path equals value
+// json_schema: the parsed output (or the value at path) matches the JSON Schema in value
+// rubric: an LLM judge scores the output against criterion
+export type ExpectationKind = 'contains' | 'not_contains' | 'matches' | 'equals' | 'json_schema' | 'rubric';
+
+export const EXPECTATION_KINDS: ExpectationKind[] = ['contains', 'not_contains', 'matches', 'equals', 'json_schema', 'rubric'];
+
+export interface IExpectation {
+  kind: ExpectationKind;
+  // Dot path into the parsed (JSON) output, e.g. techStack.database or files.0.path
+  path?: string;
+  value?: any;
+  criterion?: string;
+  description?: string;
+  weight: number;
+}
+
+export interface IEvalCase {
+  key: string;
+  description?: string;
+  // Requirements the case feeds the agent, e.g. projectName, projectType and description
+  inputs: Record<string, any>;
+  expected: IExpectation[];
+  tags: string[];
+}
+
+export interface IEvalDatasetVersion extends Document {
+  datasetName: string;
+  version: number;
+  cases: IEvalCase[];
+  changelog: string;
+  createdBy: string;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const ExpectationSchema = new Schema({
+  kind: {
+    type: String,
+    enum: EXPECTATION_KINDS,
+    required: true
+  },
+  path: String,
+  value: Schema.Types.Mixed,
+  criterion: String,
+  description: String,
+  weight: {
+    type: Number,
+    default: 1,
+    min: 0
+  }
+}, { _id: false });
+
+const EvalCaseSchema = new Schema({
+  key: {
+    type: String,
+    required: true
+  },
+  description: String,
+  inputs: {
+    type: Schema.Types.Mixed,
+    default: {}
+  },
+  expected: [ExpectationSchema],
+  tags: [{
+    type: String,
+    trim: true
+  }]
+}, { _id: false });
+
+// Versions are immutable snapshots, so scores against a version stay comparable; changes to the
+// cases go into a new version
+const EvalDatasetVersionSchema: Schema = new Schema({
+  datasetName: {
+    type: String,
+    required: true,
+    index: true
+  },
+  version: {
+    type: Number,
+    required: true
+  },
+  cases: [EvalCaseSchema],
+  changelog: {
+    type: String,
+    default: '',
+    maxlength: 5000
+  },
+  createdBy: {
+    type: String,
+    required: true
+  }
+}, {
+  timestamps: true
+});
+
+EvalDatasetVersionSchema.index({ datasetName: 1, version: -1 }, { unique: true });
+
+export const EvalDatasetVersion = mongoose.model<IEvalDatasetVersion>('EvalDatasetVersion', EvalDatasetVersionSchema);
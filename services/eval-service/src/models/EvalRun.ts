@@ -0,0 +1,179 @@
+import mongoose, { Schema, Document } from 'mongoose';
+import { ExpectationKind } from './EvalDatasetVersion.js';
+
+export type EvalRunStatus = 'queued' | 'running' | 'completed' | 'failed' | 'cancelled';
+
+export interface IUsage {
+  promptTokens: number;
+  completionTokens: number;
+  totalTokens: number;
+  costUsd: number;
+}
+
+export interface ICheckResult {
+  kind: ExpectationKind;
+  path?: string;
+  description?: string;
+  passed: boolean;
+  // 0-1; deterministic checks score 0 or 1, rubric judges anything in between
+  score: number;
+  weight: number;
+  // Why a check failed, or the judge's reasoning
+  detail?: string;
+}
+
+export interface ICaseResult {
+  key: string;
+  tags: string[];
+  passed: boolean;
+  // Weighted mean of the check scores
+  score: number;
+  checks: ICheckResult[];
+  // Shortened to EVAL_MAX_OUTPUT_CHARS
+  rawOutput?: string;
+  model?: string;
+  // The agent failed or its output never validated; checks still ran on whatever it returned
+  error?: string;
+  usage?: IUsage;
+  durationMs: number;
+}
+
+export interface IRunSummary {
+  cases: number;
+  passed: number;
+  errored: number;
+  score: number;
+  passRate: number;
+  byTag: Record<string, { cases: number; score: number; passRate: number }>;
+  usage: IUsage;
+  judgeUsage: IUsage;
+}
+
+export interface IEvalRun extends Document {
+  datasetName: string;
+  datasetVersion: number;
+  // Only cases carrying all of these tags were replayed
+  caseTags: string[];
+  role: string;
+  // What was asked for; unset fields mean the role's published prompt and configured model
+  candidate: {
+    promptVersion?: number;
+    model?: string;
+  };
+  // What actually ran, as reported by the agent service
+  promptName?: string;
+  promptVersion?: number;
+  judgeModel: string;
+  label?: string;
+  status: EvalRunStatus;
+  progress: { completed: number; total: number };
+  results: ICaseResult[];
+  summary?: IRunSummary;
+  error?: string;
+  createdBy: string;
+  startedAt?: Date;
+  completedAt?: Date;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const UsageSchema = new Schema({
+  promptTokens: { type: Number, default: 0 },
+  completionTokens: { type: Number, default: 0 },
+  totalTokens: { type: Number, default: 0 },
+  costUsd: { type: Number, default: 0 }
+}, { _id: false });
+
+const CheckResultSchema = new Schema({
+  kind: { type: String, required: true },
+  path: String,
+  description: String,
+  passed: { type: Boolean, required: true },
+  score: { type: Number, required: true },
+  weight: { type: Number, required: true },
+  detail: String
+}, { _id: false });
+
+const CaseResultSchema = new Schema({
+  key: { type: String, required: true },
+  tags: [String],
+  passed: { type: Boolean, required: true },
+  score: { type: Number, required: true },
+  checks: [CheckResultSchema],
+  rawOutput: String,
+  model: String,
+  error: String,
+  usage: UsageSchema,
+  durationMs: { type: Number, default: 0 }
+}, { _id: false });
+
+const EvalRunSchema: Schema = new Schema({
+  datasetName: {
+    type: String,
+    required: true
+  },
+  datasetVersion: {
+    type: Number,
+    required: true
+  },
+  caseTags: [{
+    type: String
+  }],
+  role: {
+    type: String,
+    required: true,
+    index: true
+  },
+  candidate: {
+    promptVersion: Number,
+    model: String
+  },
+  promptName: {
+    type: String
+  },
+  promptVersion: {
+    type: Number
+  },
+  judgeModel: {
+    type: String,
+    required: true
+  },
+  label: {
+    type: String,
+    maxlength: 200
+  },
+  status: {
+    type: String,
+    enum: ['queued', 'running', 'completed', 'failed', 'cancelled'],
+    default: 'queued',
+    index: true
+  },
+  progress: {
+    completed: { type: Number, default: 0 },
+    total: { type: Number, default: 0 }
+  },
+  results: [CaseResultSchema],
+  summary: {
+    type: Schema.Types.Mixed
+  },
+  error: {
+    type: String
+  },
+  createdBy: {
+    type: String,
+    required: true
+  },
+  startedAt: {
+    type: Date
+  },
+  completedAt: {
+    type: Date
+  }
+}, {
+  timestamps: true
+});
+
+// Reports compare runs over the same dataset version, grouped by prompt version and model
+EvalRunSchema.index({ datasetName: 1, datasetVersion: 1, promptName: 1, promptVersion: 1, createdAt: -1 });
+
+export const EvalRun = mongoose.model<IEvalRun>('EvalRun', EvalRunSchema);
@@ -0,0 +1,374 @@
+import express, { Request, Response } from 'express';
+import { body, param, query, validationResult } from 'express-validator';
+import { EvalDataset } from '../models/EvalDataset.js';
+import { EvalDatasetVersion } from '../models/EvalDatasetVersion.js';
+import { requireAuth, AuthenticatedRequest } from '../middleware/auth.js';
+import { filterCases, normalizeCases, resolveDataset, validateCases } from '../services/DatasetStore.js';
+
+const router = express.Router();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const NAME_PATTERN = /^[a-z0-9][a-z0-9._-]*$/;
+
+const nameParam = param('name').matches(NAME_PATTERN).withMessage('Invalid dataset name');
+// latest and plain numbers already mean something as version references
+const labelParam = param('label')
+  .matches(NAME_PATTERN)
+  .custom(label => label !== 'latest' && !/^\d+$/.test(label))
+  .withMessage('Labels are lowercase names other than latest or a number');
+
+const tagList = (value: unknown): string[] => String(value || '')
+  .split(',')
+  .map(tag => tag.trim())
+  .filter(Boolean);
+
+// POST /api/evals/datasets - Create a dataset
+router.post('/',
+  requireAuth,
+  [
+    body('name').matches(NAME_PATTERN).isLength({ max: 100 }).withMessage('Name must be lowercase letters, digits, dots, dashes or underscores'),
+    body('description').optional().isString().isLength({ max: 2000 }).withMessage('Description must be less than 2000 characters'),
+    body('role').optional().isString().isLength({ min: 1 }).withMessage('Role must be an agent role'),
+    body('tags').optional().isArray().withMessage('Tags must be a list'),
+    body('tags.*').isString().isLength({ min: 1 }).withMessage('Tags must be non-empty strings')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      if (await EvalDataset.exists({ name: req.body.name })) {
+        return res.status(409).json({
+          success: false,
+          error: 'Dataset already exists'
+        });
+      }
+
+      const dataset = await EvalDataset.create({
+        name: req.body.name,
+        description: req.body.description || '',
+        role: req.body.role,
+        tags: req.body.tags || [],
+        ownerId: req.user!._id
+      });
+
+      res.status(201).json({
+        success: true,
+        data: dataset,
+        message: 'Dataset created successfully'
+      });
+    } catch (error) {
+      console.error('Error creating dataset:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to create dataset'
+      });
+    }
+  }
+);
+
+// GET /api/evals/datasets - List datasets, optionally by role or tags (comma-separated, all must match)
+router.get('/',
+  [
+    query('role').optional().isString().withMessage('Role must be a string'),
+    query('tags').optional().isString().withMessage('Tags must be a comma-separated list')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const filter: any = {};
+      if (req.query.role) filter.role = req.query.role;
+      const tags = tagList(req.query.tags);
+      if (tags.length > 0) filter.tags = { $all: tags };
+
+      const datasets = await EvalDataset.find(filter).sort({ name: 1 });
+
+      res.json({
+        success: true,
+        data: datasets
+      });
+    } catch (error) {
+      console.error('Error fetching datasets:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch datasets'
+      });
+    }
+  }
+);
+
+// GET /api/evals/datasets/:name - Dataset with its labels
+router.get('/:name',
+  [nameParam],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const dataset = await EvalDataset.findOne({ name: req.params.name });
+      if (!dataset) {
+        return res.status(404).json({
+          success: false,
+          error: 'Dataset not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: dataset
+      });
+    } catch (error) {
+      console.error('Error fetching dataset:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch dataset'
+      });
+    }
+  }
+);
+
+// PUT /api/evals/datasets/:name - Update description, role or tags
+router.put('/:name',
+  requireAuth,
+  [
+    nameParam,
+    body('description').optional().isString().isLength({ max: 2000 }).withMessage('Description must be less than 2000 characters'),
+    body('role').optional().isString().isLength({ min: 1 }).withMessage('Role must be an agent role'),
+    body('tags').optional().isArray().withMessage('Tags must be a list'),
+    body('tags.*').isString().isLength({ min: 1 }).withMessage('Tags must be non-empty strings')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const update = Object.fromEntries(
+        ['description', 'role', 'tags'].filter(key => req.body[key] !== undefined).map(key => [key, req.body[key]])
+      );
+      const dataset = await EvalDataset.findOneAndUpdate({ name: req.params.name }, update, { new: true, runValidators: true });
+      if (!dataset) {
+        return res.status(404).json({
+          success: false,
+          error: 'Dataset not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: dataset,
+        message: 'Dataset updated successfully'
+      });
+    } catch (error) {
+      console.error('Error updating dataset:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to update dataset'
+      });
+    }
+  }
+);
+
+// POST /api/evals/datasets/:name/versions - Store the cases as the next version
+router.post('/:name/versions',
+  requireAuth,
+  [
+    nameParam,
+    body('cases').isArray({ min: 1 }).withMessage('At least one case is required'),
+    body('changelog').isString().isLength({ min: 1, max: 5000 }).withMessage('Changelog entry is required')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const problems = validateCases(req.body.cases);
+      if (problems.length > 0) {
+        return res.status(400).json({
+          success: false,
+          error: 'Invalid cases',
+          details: problems
+        });
+      }
+
+      const dataset = await EvalDataset.findOneAndUpdate(
+        { name: req.params.name },
+        { $inc: { latestVersion: 1 } },
+        { new: true }
+      );
+      if (!dataset) {
+        return res.status(404).json({
+          success: false,
+          error: 'Dataset not found'
+        });
+      }
+
+      const version = await EvalDatasetVersion.create({
+        datasetName: dataset.name,
+        version: dataset.latestVersion,
+        cases: normalizeCases(req.body.cases),
+        changelog: req.body.changelog,
+        createdBy: req.user!._id
+      });
+
+      res.status(201).json({
+        success: true,
+        data: version,
+        message: `Version ${version.version} created successfully`
+      });
+    } catch (error) {
+      console.error('Error creating dataset version:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to create dataset version'
+      });
+    }
+  }
+);
+
+// GET /api/evals/datasets/:name/versions - Version history with case counts
+router.get('/:name/versions',
+  [nameParam],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const versions = await EvalDatasetVersion.aggregate([
+        { $match: { datasetName: req.params.name } },
+        { $sort: { version: -1 } },
+        { $project: { version: 1, changelog: 1, createdBy: 1, createdAt: 1, caseCount: { $size: '$cases' } } }
+      ]);
+
+      res.json({
+        success: true,
+        data: versions
+      });
+    } catch (error) {
+      console.error('Error fetching dataset versions:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch dataset versions'
+      });
+    }
+  }
+);
+
+// GET /api/evals/datasets/:name/versions/:ref - A version by number, label or latest, optionally
+// only the cases carrying all of ?tags=
+router.get('/:name/versions/:ref',
+  [
+    nameParam,
+    param('ref').matches(/^[a-z0-9][a-z0-9._-]*$/).withMessage('Version must be a number, a label or latest'),
+    query('tags').optional().isString().withMessage('Tags must be a comma-separated list')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const resolved = await resolveDataset(req.params.name, req.params.ref);
+      if (!resolved) {
+        return res.status(404).json({
+          success: false,
+          error: 'Dataset version not found'
+        });
+      }
+
+      const { version } = resolved;
+      res.json({
+        success: true,
+        data: {
+          ...version.toJSON(),
+          cases: filterCases(version.cases, tagList(req.query.tags))
+        }
+      });
+    } catch (error) {
+      console.error('Error fetching dataset version:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch dataset version'
+      });
+    }
+  }
+);
+
+// PUT /api/evals/datasets/:name/labels/:label - Point a label such as baseline at a version
+router.put('/:name/labels/:label',
+  requireAuth,
+  [
+    nameParam,
+    labelParam,
+    body('version').isInt({ min: 1 }).withMessage('Version must be a positive integer')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      if (!await EvalDatasetVersion.exists({ datasetName: req.params.name, version: req.body.version })) {
+        return res.status(404).json({
+          success: false,
+          error: 'Dataset version not found'
+        });
+      }
+
+      const dataset = await EvalDataset.findOneAndUpdate(
+        { name: req.params.name },
+        { $set: { [`labels.${req.params.label}`]: req.body.version } },
+        { new: true }
+      );
+      if (!dataset) {
+        return res.status(404).json({
+          success: false,
+          error: 'Dataset not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: dataset,
+        message: `${req.params.label} now points at version ${req.body.version}`
+      });
+    } catch (error) {
+      console.error('Error labelling dataset version:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to label dataset version'
+      });
+    }
+  }
+);
+
+// DELETE /api/evals/datasets/:name/labels/:label - Remove a label
+router.delete('/:name/labels/:label',
+  requireAuth,
+  [nameParam, labelParam],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const dataset = await EvalDataset.findOneAndUpdate(
+        { name: req.params.name },
+        { $unset: { [`labels.${req.params.label}`]: 1 } },
+        { new: true }
+      );
+      if (!dataset) {
+        return res.status(404).json({
+          success: false,
+          error: 'Dataset not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: dataset,
+        message: `Label ${req.params.label} removed`
+      });
+    } catch (error) {
+      console.error('Error removing dataset label:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to remove dataset label'
+      });
+    }
+  }
+);
+
+export default router;
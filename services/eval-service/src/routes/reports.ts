@@ -0,0 +1,90 @@
+import express, { Request, Response } from 'express';
+import { query, validationResult } from 'express-validator';
+import { EvalRun } from '../models/EvalRun.js';
+
+const router = express.Router();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+// GET /api/evals/reports - Latest completed score per prompt version and model over each dataset
+// version, so candidates are only ranked against runs of the same cases
+router.get('/',
+  [
+    query('dataset').isString().isLength({ min: 1 }).withMessage('Dataset is required'),
+    query('datasetVersion').optional().isInt({ min: 1 }).withMessage('Dataset version must be a positive integer'),
+    query('role').optional().isString().withMessage('Role must be a string')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const match: any = { datasetName: req.query.dataset, status: 'completed', caseTags: { $size: 0 } };
+      if (req.query.datasetVersion) match.datasetVersion = parseInt(String(req.query.datasetVersion));
+      if (req.query.role) match.role = req.query.role;
+
+      const reports = await EvalRun.aggregate([
+        { $match: match },
+        { $sort: { completedAt: -1 } },
+        {
+          $group: {
+            _id: {
+              datasetVersion: '$datasetVersion',
+              role: '$role',
+              promptName: '$promptName',
+              promptVersion: '$promptVersion',
+              model: '$candidate.model'
+            },
+            runId: { $first: '$_id' },
+            summary: { $first: '$summary' },
+            judgeModel: { $first: '$judgeModel' },
+            completedAt: { $first: '$completedAt' },
+            runs: { $sum: 1 }
+          }
+        },
+        { $sort: { '_id.datasetVersion': -1, 'summary.score': -1 } },
+        {
+          $project: {
+            _id: 0,
+            datasetVersion: '$_id.datasetVersion',
+            role: '$_id.role',
+            promptName: '$_id.promptName',
+            promptVersion: '$_id.promptVersion',
+            model: '$_id.model',
+            runId: 1,
+            score: '$summary.score',
+            passRate: '$summary.passRate',
+            cases: '$summary.cases',
+            byTag: '$summary.byTag',
+            costUsd: '$summary.usage.costUsd',
+            judgeModel: 1,
+            completedAt: 1,
+            runs: 1
+          }
+        }
+      ]);
+
+      res.json({
+        success: true,
+        data: reports
+      });
+    } catch (error) {
+      console.error('Error fetching eval reports:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch eval reports'
+      });
+    }
+  }
+);
+
+export default router;
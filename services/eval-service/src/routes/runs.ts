@@ -0,0 +1,250 @@
+import express, { Request, Response } from 'express';
+import mongoose from 'mongoose';
+import { body, param, query, validationResult } from 'express-validator';
+import { EvalRun } from '../models/EvalRun.js';
+import { requireAuth, AuthenticatedRequest } from '../middleware/auth.js';
+import { filterCases, resolveDataset } from '../services/DatasetStore.js';
+import { EvalRunner } from '../services/EvalRunner.js';
+import { compareRuns } from '../services/Comparison.js';
+
+const router = express.Router();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const idParam = param('id').custom(id => mongoose.isValidObjectId(id)).withMessage('Invalid run ID');
+
+export default function createRunRoutes(runner: EvalRunner) {
+  // POST /api/evals/runs - Replay a dataset through one or more prompt version/model candidates,
+  // one run per candidate
+  router.post('/',
+    requireAuth,
+    [
+      body('dataset').isString().isLength({ min: 1 }).withMessage('Dataset is required'),
+      body('datasetVersion').optional().isString().isLength({ min: 1 }).withMessage('Dataset version must be a number, a label or latest'),
+      body('tags').optional().isArray().withMessage('Tags must be a list'),
+      body('tags.*').isString().isLength({ min: 1 }).withMessage('Tags must be non-empty strings'),
+      body('role').optional().isString().isLength({ min: 1 }).withMessage('Role must be an agent role'),
+      body('candidates').isArray({ min: 1, max: 10 }).withMessage('Between 1 and 10 candidates are required'),
+      body('candidates.*.promptVersion').optional().isInt({ min: 1 }).withMessage('Prompt version must be a positive integer'),
+      body('candidates.*.model').optional().isString().isLength({ min: 1 }).withMessage('Model must be a model ID or alias'),
+      body('judgeModel').optional().isString().isLength({ min: 1 }).withMessage('Judge model must be a model ID or alias'),
+      body('label').optional().isString().isLength({ max: 200 }).withMessage('Label must be less than 200 characters')
+    ],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const resolved = await resolveDataset(req.body.dataset, String(req.body.datasetVersion || 'latest'));
+        if (!resolved) {
+          return res.status(404).json({
+            success: false,
+            error: 'Dataset version not found'
+          });
+        }
+
+        const role = req.body.role || resolved.dataset.role;
+        if (!role) {
+          return res.status(400).json({
+            success: false,
+            error: 'Dataset has no agent role; pass role'
+          });
+        }
+
+        const tags: string[] = req.body.tags || [];
+        const cases = filterCases(resolved.version.cases, tags);
+        if (cases.length === 0) {
+          return res.status(400).json({
+            success: false,
+            error: 'No cases match the tags'
+          });
+        }
+
+        const runs = await EvalRun.insertMany(req.body.candidates.map((candidate: any) => ({
+          datasetName: resolved.dataset.name,
+          datasetVersion: resolved.version.version,
+          caseTags: tags,
+          role,
+          candidate: {
+            ...(candidate.promptVersion ? { promptVersion: candidate.promptVersion } : {}),
+            ...(candidate.model ? { model: candidate.model } : {})
+          },
+          judgeModel: req.body.judgeModel || process.env.EVAL_JUDGE_MODEL || 'gpt-4o',
+          label: req.body.label,
+          progress: { completed: 0, total: cases.length },
+          createdBy: req.user!._id
+        })));
+        runs.forEach(run => runner.start(run, cases));
+
+        res.status(202).json({
+          success: true,
+          data: runs,
+          message: `Started ${runs.length} eval run(s) over ${cases.length} case(s)`
+        });
+      } catch (error) {
+        console.error('Error starting eval runs:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to start eval runs'
+        });
+      }
+    }
+  );
+
+  // GET /api/evals/runs - Runs without their case results, newest first
+  router.get('/',
+    [
+      query('dataset').optional().isString().withMessage('Dataset must be a string'),
+      query('role').optional().isString().withMessage('Role must be a string'),
+      query('promptName').optional().isString().withMessage('Prompt name must be a string'),
+      query('status').optional().isIn(['queued', 'running', 'completed', 'failed', 'cancelled']).withMessage('Invalid status'),
+      query('limit').optional().isInt({ min: 1, max: 200 }).withMessage('Limit must be between 1 and 200')
+    ],
+    validateRequest,
+    async (req: Request, res: Response) => {
+      try {
+        const filter: any = {};
+        if (req.query.dataset) filter.datasetName = req.query.dataset;
+        if (req.query.role) filter.role = req.query.role;
+        if (req.query.promptName) filter.promptName = req.query.promptName;
+        if (req.query.status) filter.status = req.query.status;
+
+        const runs = await EvalRun.find(filter)
+          .select('-results')
+          .sort({ createdAt: -1 })
+          .limit(parseInt(String(req.query.limit || '50')));
+
+        res.json({
+          success: true,
+          data: runs
+        });
+      } catch (error) {
+        console.error('Error fetching eval runs:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to fetch eval runs'
+        });
+      }
+    }
+  );
+
+  // GET /api/evals/runs/:id - Run with per-case results and check outcomes
+  router.get('/:id',
+    [idParam],
+    validateRequest,
+    async (req: Request, res: Response) => {
+      try {
+        const run = await EvalRun.findById(req.params.id);
+        if (!run) {
+          return res.status(404).json({
+            success: false,
+            error: 'Eval run not found'
+          });
+        }
+
+        res.json({
+          success: true,
+          data: run
+        });
+      } catch (error) {
+        console.error('Error fetching eval run:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to fetch eval run'
+        });
+      }
+    }
+  );
+
+  // GET /api/evals/runs/:id/compare?baseline=<runId> - Score deltas against another run of the dataset
+  router.get('/:id/compare',
+    [
+      idParam,
+      query('baseline').custom(id => mongoose.isValidObjectId(id)).withMessage('Baseline must be a run ID')
+    ],
+    validateRequest,
+    async (req: Request, res: Response) => {
+      try {
+        const [run, baseline] = await Promise.all([
+          EvalRun.findById(req.params.id),
+          EvalRun.findById(req.query.baseline)
+        ]);
+        if (!run || !baseline) {
+          return res.status(404).json({
+            success: false,
+            error: 'Eval run not found'
+          });
+        }
+        if (run.datasetName !== baseline.datasetName) {
+          return res.status(409).json({
+            success: false,
+            error: 'Runs are over different datasets'
+          });
+        }
+        if (run.status !== 'completed' || baseline.status !== 'completed') {
+          return res.status(409).json({
+            success: false,
+            error: 'Both runs must be completed'
+          });
+        }
+
+        res.json({
+          success: true,
+          data: compareRuns(run, baseline)
+        });
+      } catch (error) {
+        console.error('Error comparing eval runs:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to compare eval runs'
+        });
+      }
+    }
+  );
+
+  // POST /api/evals/runs/:id/cancel - Stop a queued or running run; finished cases are kept
+  router.post('/:id/cancel',
+    requireAuth,
+    [idParam],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const run = await EvalRun.findById(req.params.id);
+        if (!run) {
+          return res.status(404).json({
+            success: false,
+            error: 'Eval run not found'
+          });
+        }
+        if (!runner.cancel(run.id)) {
+          return res.status(409).json({
+            success: false,
+            error: `Run is ${run.status}`
+          });
+        }
+
+        res.json({
+          success: true,
+          message: 'Eval run cancelled'
+        });
+      } catch (error) {
+        console.error('Error cancelling eval run:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to cancel eval run'
+        });
+      }
+    }
+  );
+
+  return router;
+}
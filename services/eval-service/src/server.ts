@@ -0,0 +1,142 @@
+import express from 'express';
+import cors from 'cors';
+import mongoose from 'mongoose';
+import dotenv from 'dotenv';
+import winston from 'winston';
+import datasetRoutes from './routes/datasets.js';
+import createRunRoutes from './routes/runs.js';
+import reportRoutes from './routes/reports.js';
+import { EvalRunner } from './services/EvalRunner.js';
+import { AgentClient } from './services/AgentClient.js';
+import { LLMClient } from './services/LLMClient.js';
+import { Judge } from './services/Judge.js';
+
+// Load environment variables
+dotenv.config();
+
+const app = express();
+const PORT = process.env.PORT || 3021;
+
+// Logger configuration
+const logger = winston.createLogger({
+  level: 'info',
+  format: winston.format.combine(
+    winston.format.timestamp(),
+    winston.format.json()
+  ),
+  transports: [
+    new winston.transports.Console(),
+    new winston.transports.File({ filename: 'logs/eval-service.log' })
+  ]
+});
+
+const runner = new EvalRunner(new AgentClient(), new Judge(new LLMClient()));
+
+// Database connection
+const MONGODB_URI = process.env.MONGODB_URI || 'mongodb://localhost:27017/ai-pipeline';
+
+mongoose.connect(MONGODB_URI)
+  .then(() => {
+    logger.info('🗄️  Connected to MongoDB');
+    return runner.recover();
+  })
+  .then((interrupted) => {
+    if (interrupted) logger.warn(`Marked ${interrupted} interrupted eval run(s) as failed`);
+  })
+  .catch((error) => {
+    logger.error('❌ MongoDB connection error:', error);
+    process.exit(1);
+  });
+
+// Middleware
+app.use(cors({
+  origin: process.env.FRONTEND_URL || 'http://localhost:5173',
+  credentials: true
+}));
+app.use(express.json({ limit: '50mb' }));
+app.use(express.urlencoded({ extended: true }));
+
+// Request logging middleware
+app.use((req, res, next) => {
+  logger.info(`${req.method} ${req.path}`, {
+    ip: req.ip,
+    userAgent: req.get('User-Agent'),
+    timestamp: new Date().toISOString()
+  });
+  next();
+});
+
+// Routes
+app.use('/api/evals/datasets', datasetRoutes);
+app.use('/api/evals/runs', createRunRoutes(runner));
+app.use('/api/evals/reports', reportRoutes);
+
+// Health check endpoint
+app.get('/health', (req, res) => {
+  res.json({
+    status: 'ok',
+    service: 'eval-service',
+    timestamp: new Date().toISOString(),
+    version: '1.0.0',
+    database: mongoose.connection.readyState === 1 ? 'connected' : 'disconnected'
+  });
+});
+
+// Service info endpoint
+app.get('/info', (req, res) => {
+  res.json({
+    service: 'Eval Service',
+    version: '1.0.0',
+    description: 'Labeled evaluation datasets and eval runs for agent prompts and models',
+    endpoints: [
+      { method: 'POST', path: '/api/evals/datasets', description: 'Create a dataset' },
+      { method: 'GET', path: '/api/evals/datasets', description: 'List datasets by role or tags' },
+      { method: 'GET', path: '/api/evals/datasets/:name', description: 'Dataset with its labels' },
+      { method: 'PUT', path: '/api/evals/datasets/:name', description: 'Update description, role or tags' },
+      { method: 'POST', path: '/api/evals/datasets/:name/versions', description: 'Store cases as a new version' },
+      { method: 'GET', path: '/api/evals/datasets/:name/versions', description: 'Version history' },
+      { method: 'GET', path: '/api/evals/datasets/:name/versions/:ref', description: 'Cases of a version, label or latest' },
+      { method: 'PUT', path: '/api/evals/datasets/:name/labels/:label', description: 'Point a label at a version' },
+      { method: 'DELETE', path: '/api/evals/datasets/:name/labels/:label', description: 'Remove a label' },
+      { method: 'POST', path: '/api/evals/runs', description: 'Replay a dataset through prompt version/model candidates' },
+      { method: 'GET', path: '/api/evals/runs', description: 'Eval runs with their summaries' },
+      { method: 'GET', path: '/api/evals/runs/:id', description: 'Run with per-case results' },
+      { method: 'GET', path: '/api/evals/runs/:id/compare', description: 'Score deltas against a baseline run' },
+      { method: 'POST', path: '/api/evals/runs/:id/cancel', description: 'Cancel a running run' },
+      { method: 'GET', path: '/api/evals/reports', description: 'Latest scores per prompt version and model' }
+    ]
+  });
+});
+
+// Catch-all for undefined routes
+app.use('*', (req, res) => {
+  res.status(404).json({
+    success: false,
+    error: 'Route not found',
+    service: 'eval-service'
+  });
+});
+
+// Global error handler
+app.use((err: any, req: any, res: any, next: any) => {
+  logger.error('Unhandled error:', err);
+  res.status(500).json({
+    success: false,
+    error: 'Internal server error',
+    service: 'eval-service'
+  });
+});
+
+// Graceful shutdown
+process.on('SIGTERM', () => {
+  logger.info('SIGTERM received. Shutting down gracefully...');
+  mongoose.connection.close();
+  process.exit(0);
+});
+
+// Start server
+app.listen(PORT, () => {
+  logger.info(`🧪 Eval Service running on port ${PORT}`);
+});
+
+export default app;
@@ -0,0 +1,34 @@
+import axios from 'axios';
+import { ExecuteStageRequest, ExecuteStageResponse } from '../types/index.js';
+
+export class AgentClient {
+  private baseUrl: string;
+  private timeout: number;
+
+  constructor(baseUrl?: string, timeout?: number) {
+    this.baseUrl = baseUrl || process.env.AGENT_SERVICE_URL || 'http://localhost:3005';
+    this.timeout = timeout || parseInt(process.env.EVAL_CASE_TIMEOUT_MS || '300000');
+  }
+
+  // Runs one case as a stage. Output that failed validation (422) is still returned, since its
+  // raw text can be scored; any other failure throws.
+  async execute(role: string, request: ExecuteStageRequest, signal?: AbortSignal): Promise<ExecuteStageResponse> {
+    try {
+      const response = await axios.post(`${this.baseUrl}/api/agents/${encodeURIComponent(role)}/execute`, request, {
+        headers: { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' },
+        timeout: this.timeout,
+        signal
+      });
+
+      return response.data.data;
+    } catch (error) {
+      if (axios.isAxiosError(error) && error.response?.status === 422 && error.response.data?.data) {
+        return error.response.data.data;
+      }
+      if (axios.isAxiosError(error) && error.response?.data?.error) {
+        throw new Error(`Agent service error: ${error.response.data.error}`);
+      }
+      throw new Error(`Agent service error: ${error instanceof Error ? error.message : 'Unknown error'}`);
+    }
+  }
+}
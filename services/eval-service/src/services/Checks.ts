@@ -0,0 +1,89 @@
+import { isDeepStrictEqual } from 'util';
+import Ajv, { ValidateFunction } from 'ajv';
+import { IExpectation } from '../models/EvalDatasetVersion.js';
+import { ICheckResult } from '../models/EvalRun.js';
+
+const ajv = new Ajv({ allErrors: true, strict: false });
+const validators = new Map<string, ValidateFunction>();
+
+export interface AgentAnswer {
+  // Parsed per the role's output format; a string for text roles
+  output: any;
+  rawOutput: string;
+}
+
+const MISSING = Symbol('missing');
+
+// Follows a dot path such as techStack.database or files.0.path
+export function valueAt(value: any, path: string): any {
+  let current = value;
+  for (const segment of path.split('.')) {
+    if (current === null || typeof current !== 'object' || !(segment in current)) return MISSING;
+    current = current[segment];
+  }
+  return current;
+}
+
+function asText(value: any): string {
+  return typeof value === 'string' ? value : JSON.stringify(value);
+}
+
+// Compiled validators are kept by schema text, since every run replays the same cases
+export function schemaValidator(schema: Record<string, any>): ValidateFunction {
+  const key = JSON.stringify(schema);
+  let validate = validators.get(key);
+  if (!validate) {
+    validate = ajv.compile(schema);
+    validators.set(key, validate);
+  }
+  return validate;
+}
+
+function result(expectation: IExpectation, passed: boolean, detail?: string): ICheckResult {
+  return {
+    kind: expectation.kind,
+    path: expectation.path,
+    description: expectation.description,
+    passed,
+    score: passed ? 1 : 0,
+    weight: expectation.weight,
+    ...(passed || !detail ? {} : { detail })
+  };
+}
+
+// Scores every expectation except rubrics, which need a judge. Text checks look at the raw output
+// unless a path picks a value out of the parsed output.
+export function runCheck(expectation: IExpectation, answer: AgentAnswer): ICheckResult {
+  const target = expectation.path ? valueAt(answer.output, expectation.path) : undefined;
+  if (expectation.path && target === MISSING) {
+    return result(expectation, false, `Output has no value at ${expectation.path}`);
+  }
+
+  switch (expectation.kind) {
+    case 'contains':
+    case 'not_contains': {
+      const text = expectation.path ? asText(target) : answer.rawOutput;
+      const found = text.includes(expectation.value);
+      return expectation.kind === 'contains'
+        ? result(expectation, found, `Does not contain "${expectation.value}"`)
+        : result(expectation, !found, `Contains "${expectation.value}"`);
+    }
+    case 'matches': {
+      const text = expectation.path ? asText(target) : answer.rawOutput;
+      return result(expectation, new RegExp(expectation.value).test(text), `Does not match /${expectation.value}/`);
+    }
+    case 'equals':
+      return result(expectation, isDeepStrictEqual(target, expectation.value),
+        `Expected ${asText(expectation.value)}, got ${asText(target)}`.slice(0, 500));
+    case 'json_schema': {
+      const value = expectation.path ? target : answer.output;
+      const validate = schemaValidator(expectation.value);
+      if (validate(value)) return result(expectation, true);
+      const errors = (validate.errors || []).slice(0, 5)
+        .map(error => `${error.instancePath || '/'} ${error.message}`);
+      return result(expectation, false, errors.join('; '));
+    }
+    default:
+      throw new Error(`${expectation.kind} expectations are scored by a judge`);
+  }
+}
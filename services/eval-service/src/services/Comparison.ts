@@ -0,0 +1,78 @@
+import { IEvalRun } from '../models/EvalRun.js';
+
+export interface CaseDelta {
+  key: string;
+  score: number;
+  baselineScore: number;
+  delta: number;
+  passed: boolean;
+  baselinePassed: boolean;
+}
+
+export interface RunComparison {
+  runId: string;
+  baselineId: string;
+  datasetName: string;
+  datasetVersions: { run: number; baseline: number };
+  score: number;
+  baselineScore: number;
+  scoreDelta: number;
+  passRate: number;
+  baselinePassRate: number;
+  passRateDelta: number;
+  cases: CaseDelta[];
+  // Cases that passed on the baseline and fail now, and the reverse
+  regressions: string[];
+  improvements: string[];
+  // Cases only one of the runs has, e.g. after the dataset gained cases; left out of the scores above
+  unmatched: string[];
+}
+
+const round = (value: number) => Math.round(value * 10000) / 10000;
+
+// Compares two completed runs case by case over the cases both replayed, so runs of different
+// dataset versions or tag filters still compare like with like
+export function compareRuns(run: IEvalRun, baseline: IEvalRun): RunComparison {
+  const baselineCases = new Map(baseline.results.map(result => [result.key, result]));
+  const runKeys = new Set(run.results.map(result => result.key));
+
+  const cases: CaseDelta[] = run.results
+    .filter(result => baselineCases.has(result.key))
+    .map(result => {
+      const before = baselineCases.get(result.key)!;
+      return {
+        key: result.key,
+        score: result.score,
+        baselineScore: before.score,
+        delta: round(result.score - before.score),
+        passed: result.passed,
+        baselinePassed: before.passed
+      };
+    });
+
+  const mean = (values: number[]) => values.length === 0 ? 0 : round(values.reduce((sum, value) => sum + value, 0) / values.length);
+  const score = mean(cases.map(delta => delta.score));
+  const baselineScore = mean(cases.map(delta => delta.baselineScore));
+  const passRate = mean(cases.map(delta => delta.passed ? 1 : 0));
+  const baselinePassRate = mean(cases.map(delta => delta.baselinePassed ? 1 : 0));
+
+  return {
+    runId: run.id,
+    baselineId: baseline.id,
+    datasetName: run.datasetName,
+    datasetVersions: { run: run.datasetVersion, baseline: baseline.datasetVersion },
+    score,
+    baselineScore,
+    scoreDelta: round(score - baselineScore),
+    passRate,
+    baselinePassRate,
+    passRateDelta: round(passRate - baselinePassRate),
+    cases,
+    regressions: cases.filter(delta => delta.baselinePassed && !delta.passed).map(delta => delta.key),
+    improvements: cases.filter(delta => !delta.baselinePassed && delta.passed).map(delta => delta.key),
+    unmatched: [
+      ...run.results.filter(result => !baselineCases.has(result.key)).map(result => result.key),
+      ...baseline.results.filter(result => !runKeys.has(result.key)).map(result => result.key)
+    ]
+  };
+}
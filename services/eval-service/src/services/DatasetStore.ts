@@ -0,0 +1,111 @@
+import { EvalDataset, IEvalDataset } from '../models/EvalDataset.js';
+import { EXPECTATION_KINDS, EvalDatasetVersion, IEvalCase, IEvalDatasetVersion } from '../models/EvalDatasetVersion.js';
+import { schemaValidator } from './Checks.js';
+
+const KEY_PATTERN = /^[a-z0-9][a-z0-9._-]*$/;
+
+// Problems with a set of cases, phrased for the author; empty when they can be stored
+export function validateCases(cases: any[]): string[] {
+  const errors: string[] = [];
+  const keys = new Set<string>();
+
+  cases.forEach((testCase, index) => {
+    const label = `Case ${testCase?.key || index + 1}`;
+    if (!testCase || typeof testCase !== 'object') {
+      errors.push(`Case ${index + 1} must be an object`);
+      return;
+    }
+    if (typeof testCase.key !== 'string' || !KEY_PATTERN.test(testCase.key)) {
+      errors.push(`${label} needs a key of lowercase letters, digits, dots, dashes or underscores`);
+    } else if (keys.has(testCase.key)) {
+      errors.push(`${label} is declared more than once`);
+    } else {
+      keys.add(testCase.key);
+    }
+    if (testCase.inputs !== undefined && (typeof testCase.inputs !== 'object' || Array.isArray(testCase.inputs))) {
+      errors.push(`${label} inputs must be an object`);
+    }
+    if (!Array.isArray(testCase.expected) || testCase.expected.length === 0) {
+      errors.push(`${label} needs at least one expected property`);
+      return;
+    }
+
+    testCase.expected.forEach((expectation: any, position: number) => {
+      const where = `${label} expectation ${position + 1}`;
+      if (!EXPECTATION_KINDS.includes(expectation?.kind)) {
+        errors.push(`${where} kind must be one of ${EXPECTATION_KINDS.join(', ')}`);
+        return;
+      }
+      if (expectation.weight !== undefined && !(typeof expectation.weight === 'number' && expectation.weight >= 0)) {
+        errors.push(`${where} weight must be a non-negative number`);
+      }
+      switch (expectation.kind) {
+        case 'contains':
+        case 'not_contains':
+          if (typeof expectation.value !== 'string' || !expectation.value) errors.push(`${where} needs a text value`);
+          break;
+        case 'matches':
+          try {
+            new RegExp(expectation.value);
+            if (typeof expectation.value !== 'string') throw new Error();
+          } catch {
+            errors.push(`${where} value must be a regular expression`);
+          }
+          break;
+        case 'equals':
+          if (!expectation.path) errors.push(`${where} needs the path whose value is compared`);
+          break;
+        case 'json_schema':
+          if (!expectation.value || typeof expectation.value !== 'object') {
+            errors.push(`${where} value must be a JSON Schema object`);
+            break;
+          }
+          try {
+            schemaValidator(expectation.value);
+          } catch (error) {
+            errors.push(`${where} value is not a valid JSON Schema: ${error instanceof Error ? error.message : error}`);
+          }
+          break;
+        case 'rubric':
+          if (typeof expectation.criterion !== 'string' || !expectation.criterion.trim()) errors.push(`${where} needs a criterion for the judge`);
+          break;
+      }
+    });
+  });
+
+  return errors;
+}
+
+export function normalizeCases(cases: any[]): IEvalCase[] {
+  return cases.map(testCase => ({
+    key: testCase.key,
+    description: testCase.description,
+    inputs: testCase.inputs || {},
+    expected: testCase.expected.map((expectation: any) => ({ ...expectation, weight: expectation.weight ?? 1 })),
+    tags: testCase.tags || []
+  }));
+}
+
+export interface ResolvedDataset {
+  dataset: IEvalDataset;
+  version: IEvalDatasetVersion;
+}
+
+// A version reference is a number, a label such as baseline, or latest (the default)
+export async function resolveDataset(name: string, ref: string = 'latest'): Promise<ResolvedDataset | undefined> {
+  const dataset = await EvalDataset.findOne({ name });
+  if (!dataset) return undefined;
+
+  const number = ref === 'latest'
+    ? dataset.latestVersion
+    : /^\d+$/.test(ref) ? parseInt(ref) : dataset.labels.get(ref);
+  if (!number) return undefined;
+
+  const version = await EvalDatasetVersion.findOne({ datasetName: name, version: number });
+  return version ? { dataset, version } : undefined;
+}
+
+// Cases carrying every one of the tags; all cases without a filter
+export function filterCases(cases: IEvalCase[], tags: string[] = []): IEvalCase[] {
+  return tags.length === 0 ? cases : cases.filter(testCase => tags.every(tag => testCase.tags.includes(tag)));
+}
@@ -0,0 +1,215 @@
+import { EvalRun, ICaseResult, ICheckResult, IEvalRun, IRunSummary, IUsage } from '../models/EvalRun.js';
+import { IEvalCase } from '../models/EvalDatasetVersion.js';
+import { AgentClient } from './AgentClient.js';
+import { Judge } from './Judge.js';
+import { runCheck } from './Checks.js';
+import { ExecuteStageResponse, LLMUsage } from '../types/index.js';
+
+const emptyUsage = (): IUsage => ({ promptTokens: 0, completionTokens: 0, totalTokens: 0, costUsd: 0 });
+
+function addUsage(total: IUsage, usage?: LLMUsage): void {
+  if (!usage) return;
+  total.promptTokens += usage.promptTokens || 0;
+  total.completionTokens += usage.completionTokens || 0;
+  total.totalTokens += usage.totalTokens || 0;
+  total.costUsd += usage.costUsd || 0;
+}
+
+const round = (value: number) => Math.round(value * 10000) / 10000;
+
+// Weighted mean of the check scores; checks weighted 0 are reported but do not count
+export function caseScore(checks: ICheckResult[]): number {
+  const weight = checks.reduce((sum, check) => sum + check.weight, 0);
+  if (weight === 0) return checks.every(check => check.passed) ? 1 : 0;
+  return round(checks.reduce((sum, check) => sum + check.score * check.weight, 0) / weight);
+}
+
+export function summarize(results: ICaseResult[], judgeUsage: IUsage): IRunSummary {
+  const usage = emptyUsage();
+  const tags: Record<string, ICaseResult[]> = {};
+  for (const result of results) {
+    addUsage(usage, result.usage);
+    for (const tag of result.tags) (tags[tag] ||= []).push(result);
+  }
+
+  const mean = (cases: ICaseResult[]) => cases.length === 0
+    ? 0
+    : round(cases.reduce((sum, result) => sum + result.score, 0) / cases.length);
+  const passRate = (cases: ICaseResult[]) => cases.length === 0
+    ? 0
+    : round(cases.filter(result => result.passed).length / cases.length);
+
+  return {
+    cases: results.length,
+    passed: results.filter(result => result.passed).length,
+    errored: results.filter(result => result.error).length,
+    score: mean(results),
+    passRate: passRate(results),
+    byTag: Object.fromEntries(Object.entries(tags).map(([tag, cases]) => [
+      tag,
+      { cases: cases.length, score: mean(cases), passRate: passRate(cases) }
+    ])),
+    usage,
+    judgeUsage
+  };
+}
+
+// Replays a dataset's cases through the agent service with the run's prompt version and model,
+// scores each output and stores the results on the run. Runs execute in this process, up to
+// EVAL_CONCURRENCY cases at a time; a restart fails whatever was in flight.
+export class EvalRunner {
+  private active: Map<string, AbortController> = new Map();
+  private concurrency = parseInt(process.env.EVAL_CONCURRENCY || '4');
+  private maxOutputChars = parseInt(process.env.EVAL_MAX_OUTPUT_CHARS || '20000');
+
+  constructor(
+    private agents: AgentClient,
+    private judge: Judge
+  ) {}
+
+  // Marks runs left queued or running by a previous process as failed
+  async recover(): Promise<number> {
+    const result = await EvalRun.updateMany(
+      { status: { $in: ['queued', 'running'] } },
+      { $set: { status: 'failed', error: 'Interrupted by a restart of the eval service', completedAt: new Date() } }
+    );
+    return result.modifiedCount;
+  }
+
+  start(run: IEvalRun, cases: IEvalCase[]): void {
+    const abort = new AbortController();
+    this.active.set(run.id, abort);
+
+    this.execute(run, cases, abort.signal)
+      .catch(async error => {
+        console.error(`Eval run ${run.id} failed:`, error);
+        await EvalRun.updateOne({ _id: run._id }, {
+          $set: {
+            status: abort.signal.aborted ? 'cancelled' : 'failed',
+            error: abort.signal.aborted ? undefined : error instanceof Error ? error.message : 'Eval run failed',
+            completedAt: new Date()
+          }
+        }).catch(() => undefined);
+      })
+      .finally(() => this.active.delete(run.id));
+  }
+
+  cancel(runId: string): boolean {
+    const abort = this.active.get(runId);
+    abort?.abort();
+    return !!abort;
+  }
+
+  private async execute(run: IEvalRun, cases: IEvalCase[], signal: AbortSignal): Promise<void> {
+    await EvalRun.updateOne({ _id: run._id }, {
+      $set: { status: 'running', startedAt: new Date(), progress: { completed: 0, total: cases.length } }
+    });
+
+    const results: ICaseResult[] = [];
+    const judgeUsage = emptyUsage();
+    let prompt: { name: string; version: number } | undefined;
+    let next = 0;
+
+    const worker = async () => {
+      while (next < cases.length) {
+        signal.throwIfAborted();
+        const testCase = cases[next++];
+        const outcome = await this.runCase(run, testCase, judgeUsage, signal);
+        prompt ||= outcome.prompt;
+        results.push(outcome.result);
+        await EvalRun.updateOne({ _id: run._id }, {
+          $push: { results: outcome.result },
+          $set: { 'progress.completed': results.length }
+        });
+      }
+    };
+    await Promise.all(Array.from({ length: Math.min(this.concurrency, cases.length) }, worker));
+
+    // Results arrive in completion order; reports list them in dataset order
+    const order = new Map(cases.map((testCase, index) => [testCase.key, index]));
+    results.sort((a, b) => order.get(a.key)! - order.get(b.key)!);
+
+    await EvalRun.updateOne({ _id: run._id }, {
+      $set: {
+        status: 'completed',
+        results,
+        summary: summarize(results, judgeUsage),
+        ...(prompt ? { promptName: prompt.name, promptVersion: prompt.version } : {}),
+        completedAt: new Date()
+      }
+    });
+  }
+
+  private async runCase(
+    run: IEvalRun,
+    testCase: IEvalCase,
+    judgeUsage: IUsage,
+    signal: AbortSignal
+  ): Promise<{ result: ICaseResult; prompt?: { name: string; version: number } }> {
+    const startTime = Date.now();
+    const base = { key: testCase.key, tags: testCase.tags };
+
+    let answer: ExecuteStageResponse;
+    try {
+      answer = await this.agents.execute(run.role, {
+        runId: `eval-${run.id}`,
+        stageId: testCase.key,
+        inputs: testCase.inputs,
+        ...(run.candidate.model ? { modelOverride: run.candidate.model } : {}),
+        ...(run.candidate.promptVersion ? { promptVersion: run.candidate.promptVersion } : {})
+      }, signal);
+    } catch (error) {
+      signal.throwIfAborted();
+      // A case the agent could not run at all scores nothing
+      return {
+        result: {
+          ...base,
+          passed: false,
+          score: 0,
+          checks: [],
+          error: error instanceof Error ? error.message : 'Agent call failed',
+          durationMs: Date.now() - startTime
+        }
+      };
+    }
+
+    const rawOutput = answer.rawOutput || '';
+    const checks: ICheckResult[] = [];
+    for (const expectation of testCase.expected) {
+      if (expectation.kind !== 'rubric') {
+        checks.push(runCheck(expectation, { output: answer.output, rawOutput }));
+        continue;
+      }
+      try {
+        const judgement = await this.judge.judge(expectation, testCase.inputs, rawOutput, run.judgeModel, signal);
+        addUsage(judgeUsage, judgement.usage);
+        checks.push(judgement.check);
+      } catch (error) {
+        signal.throwIfAborted();
+        checks.push({
+          kind: expectation.kind,
+          description: expectation.description || expectation.criterion,
+          passed: false,
+          score: 0,
+          weight: expectation.weight,
+          detail: error instanceof Error ? error.message : 'Judge failed'
+        });
+      }
+    }
+
+    return {
+      result: {
+        ...base,
+        passed: !answer.error && checks.every(check => check.passed),
+        score: caseScore(checks),
+        checks,
+        rawOutput: rawOutput.slice(0, this.maxOutputChars),
+        model: answer.model,
+        ...(answer.error ? { error: answer.error } : {}),
+        usage: answer.usage ? { costUsd: 0, ...answer.usage } : undefined,
+        durationMs: Date.now() - startTime
+      },
+      prompt: answer.prompt
+    };
+  }
+}
@@ -0,0 +1,91 @@
+import { LLMClient } from './LLMClient.js';
+import { IExpectation } from '../models/EvalDatasetVersion.js';
+import { ICheckResult } from '../models/EvalRun.js';
+import { LLMUsage } from '../types/index.js';
+
+const SYSTEM_PROMPT = `You are a strict evaluator grading the output of an AI agent against one criterion.
+Judge only the criterion you are given, not style or anything else.
+
+Respond with JSON only, using this shape:
+{
+  "score": 1,
+  "reasoning": "one or two sentences"
+}
+
+score is an integer from 1 to 5: 1 means the output clearly fails the criterion, 3 that it partly
+meets it, 5 that it fully meets it.`;
+
+const MAX_JUDGED_CHARS = 60000;
+
+export interface Judgement {
+  check: ICheckResult;
+  usage?: LLMUsage;
+}
+
+// Scores outputs against rubric criteria with an LLM. Scores 1-5 are mapped onto 0-1; a rubric
+// passes at EVAL_RUBRIC_PASS_SCORE or above.
+export class Judge {
+  private passScore: number;
+
+  constructor(private llm: LLMClient) {
+    this.passScore = parseFloat(process.env.EVAL_RUBRIC_PASS_SCORE || '0.75');
+  }
+
+  async judge(
+    expectation: IExpectation,
+    inputs: Record<string, any>,
+    rawOutput: string,
+    model: string,
+    signal?: AbortSignal
+  ): Promise<Judgement> {
+    const completion = await this.llm.chat({
+      model,
+      messages: [
+        { role: 'system', content: SYSTEM_PROMPT },
+        {
+          role: 'user',
+          content: [
+            `Criterion: ${expectation.criterion}`,
+            `Inputs the agent was given:\n${JSON.stringify(inputs, null, 2)}`,
+            `Agent output:\n${rawOutput.slice(0, MAX_JUDGED_CHARS)}`
+          ].join('\n\n')
+        }
+      ],
+      temperature: 0,
+      maxTokens: 512,
+      metadata: { purpose: 'eval-judge' }
+    }, signal);
+
+    const base = {
+      kind: expectation.kind,
+      description: expectation.description || expectation.criterion,
+      weight: expectation.weight
+    };
+    const verdict = this.parse(completion.content);
+    if (!verdict) {
+      return {
+        check: { ...base, passed: false, score: 0, detail: 'Judge did not return a score' },
+        usage: completion.usage
+      };
+    }
+
+    const score = (verdict.score - 1) / 4;
+    return {
+      check: { ...base, passed: score >= this.passScore, score, detail: verdict.reasoning },
+      usage: completion.usage
+    };
+  }
+
+  private parse(content: string): { score: number; reasoning?: string } | undefined {
+    const json = content.match(/\{[\s\S]*\}/);
+    if (!json) return undefined;
+    try {
+      const parsed = JSON.parse(json[0]);
+      const score = Math.round(Number(parsed.score));
+      if (!(score >= 1 && score <= 5)) return undefined;
+      return { score, reasoning: typeof parsed.reasoning === 'string' ? parsed.reasoning : undefined };
+    } catch {
+      return undefined;
+    }
+  }
+}
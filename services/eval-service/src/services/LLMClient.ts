@@ -0,0 +1,32 @@
+import axios from 'axios';
+import { ChatCompletionRequest, ChatCompletionResponse } from '../types/index.js';
+
+export class LLMClient {
+  private baseUrl: string;
+  private timeout: number;
+
+  constructor(baseUrl?: string, timeout?: number) {
+    this.baseUrl = baseUrl || process.env.LLM_GATEWAY_URL || 'http://localhost:3006';
+    this.timeout = timeout || parseInt(process.env.LLM_TIMEOUT_MS || '120000');
+  }
+
+  async chat(request: ChatCompletionRequest, signal?: AbortSignal): Promise<ChatCompletionResponse> {
+    try {
+      const response = await axios.post(`${this.baseUrl}/api/llm/chat`, request, {
+        timeout: this.timeout,
+        signal
+      });
+
+      if (!response.data.success) {
+        throw new Error(response.data.error || 'LLM gateway request failed');
+      }
+
+      return response.data.data;
+    } catch (error) {
+      if (axios.isAxiosError(error) && error.response?.data?.error) {
+        throw new Error(`LLM gateway error: ${error.response.data.error}`);
+      }
+      throw new Error(`LLM gateway error: ${error instanceof Error ? error.message : 'Unknown error'}`);
+    }
+  }
+}
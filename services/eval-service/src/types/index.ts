@@ -0,0 +1,50 @@
+// LLM gateway types
+export interface ChatMessage {
+  role: 'system' | 'user' | 'assistant';
+  content: string;
+}
+
+export interface ChatCompletionRequest {
+  model: string;
+  messages: ChatMessage[];
+  temperature?: number;
+  maxTokens?: number;
+  metadata?: Record<string, string>;
+}
+
+export interface LLMUsage {
+  promptTokens: number;
+  completionTokens: number;
+  totalTokens: number;
+  costUsd?: number;
+}
+
+export interface ChatCompletionResponse {
+  id: string;
+  provider: string;
+  model: string;
+  content: string;
+  finishReason: string;
+  usage: LLMUsage;
+}
+
+// Agent service types
+export interface ExecuteStageRequest {
+  runId: string;
+  stageId: string;
+  inputs: Record<string, any>;
+  modelOverride?: string;
+  promptVersion?: number;
+}
+
+export interface ExecuteStageResponse {
+  status: 'completed' | 'failed';
+  output: any;
+  rawOutput: string;
+  model: string;
+  usage?: LLMUsage;
+  durationMs: number;
+  error?: string;
+  errorType?: string;
+  prompt?: { name: string; version: number };
+}
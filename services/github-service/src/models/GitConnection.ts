@@ -0,0 +1,63 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export interface IGitConnection extends Document {
+  projectId: string;
+  provider: 'github' | 'gitlab';
+  owner: string;
+  repo: string;
+  defaultBranch: string;
+  baseUrl?: string;
+  encryptedToken: string;
+  repositoryUrl?: string;
+  createdBy: string;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const GitConnectionSchema: Schema = new Schema({
+  projectId: {
+    type: String,
+    required: true,
+    unique: true
+  },
+  provider: {
+    type: String,
+    enum: ['github', 'gitlab'],
+    required: true
+  },
+  // GitHub user/org or GitLab namespace
+  owner: {
+    type: String,
+    required: true,
+    trim: true
+  },
+  repo: {
+    type: String,
+    required: true,
+    trim: true
+  },
+  defaultBranch: {
+    type: String,
+    default: 'main'
+  },
+  // Self-hosted GitLab API base URL
+  baseUrl: {
+    type: String
+  },
+  encryptedToken: {
+    type: String,
+    required: true,
+    select: false
+  },
+  repositoryUrl: {
+    type: String
+  },
+  createdBy: {
+    type: String,
+    required: true
+  }
+}, {
+  timestamps: true
+});
+
+export const GitConnection = mongoose.model<IGitConnection>('GitConnection', GitConnectionSchema);
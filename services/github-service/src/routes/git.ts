@@ -0,0 +1,381 @@
+import express, { Request, Response } from 'express';
+import { body, param, validationResult } from 'express-validator';
+import axios from 'axios';
+import { requireAuth, AuthenticatedRequest } from '../middleware/auth.js';
+import { GitConnection, IGitConnection } from '../models/GitConnection.js';
+import { encryptToken, decryptToken } from '../services/tokenCrypto.js';
+import { GitProvider, RepositoryTarget, CommitFile } from '../services/git/GitProvider.js';
+import { GitHubProvider } from '../services/git/GitHubProvider.js';
+import { GitLabProvider } from '../services/git/GitLabProvider.js';
+
+const router = express.Router();
+
+const providers: Record<string, GitProvider> = {
+  github: new GitHubProvider(),
+  gitlab: new GitLabProvider()
+};
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const projectIdParam = param('projectId').isMongoId().withMessage('Invalid project ID');
+
+// Confirms through project-service that the caller can edit the project
+const ensureProjectAccess = async (req: AuthenticatedRequest, res: Response): Promise<boolean> => {
+  const projectServiceUrl = process.env.PROJECT_SERVICE_URL || 'http://localhost:3002';
+
+  try {
+    const response = await axios.get(`${projectServiceUrl}/api/projects/${req.params.projectId}`, {
+      headers: { Authorization: req.headers.authorization },
+      timeout: 5000
+    });
+    const project = response.data.data;
+    const userId = req.user!._id;
+    const collaborator = project.collaborators?.find((c: any) => c.userId === userId);
+
+    if (project.ownerId === userId || collaborator?.role === 'editor') {
+      return true;
+    }
+  } catch (error) {
+    if (!axios.isAxiosError(error) || !error.response) throw error;
+  }
+
+  res.status(404).json({
+    success: false,
+    error: 'Project not found'
+  });
+  return false;
+};
+
+const loadConnection = async (projectId: string): Promise<{ connection: IGitConnection; target: RepositoryTarget } | null> => {
+  const connection = await GitConnection.findOne({ projectId }).select('+encryptedToken');
+  if (!connection) return null;
+
+  return {
+    connection,
+    target: {
+      token: decryptToken(connection.encryptedToken),
+      owner: connection.owner,
+      repo: connection.repo,
+      baseUrl: connection.baseUrl
+    }
+  };
+};
+
+// Code bundles in artifact-service are JSON: either a files array or { files: [...] }. Fetched as the
+// caller, so only bundles of projects they can read are committed.
+const loadArtifactFiles = async (artifactId: string, authorization: string): Promise<CommitFile[]> => {
+  const artifactServiceUrl = process.env.ARTIFACT_SERVICE_URL || 'http://localhost:3008';
+  const { data: link } = await axios.get(`${artifactServiceUrl}/api/artifacts/${artifactId}/download-url`, {
+    headers: { Authorization: authorization },
+    timeout: 10000
+  });
+  const { data: bundle } = await axios.get(link.data.url, { timeout: 60000 });
+
+  const files = Array.isArray(bundle) ? bundle : bundle?.files;
+  if (!Array.isArray(files)) {
+    throw new Error('Artifact is not a code bundle');
+  }
+  return files.map((file: any) => ({ path: file.path, content: file.content }));
+};
+
+export const buildPullRequestBody = (description: string, reviewSummary?: string): string => {
+  const sections = [description || 'Generated by AI Pipeline.'];
+  if (reviewSummary) {
+    sections.push(`## AI Review Summary\n\n${reviewSummary}`);
+  }
+  return sections.join('\n\n');
+};
+
+const providerError = (error: unknown): string => {
+  if (axios.isAxiosError(error)) {
+    return error.response?.data?.message || error.message;
+  }
+  return error instanceof Error ? error.message : 'Unknown error';
+};
+
+// PUT /api/git/projects/:projectId/connection - Store the repository and access token for a project
+router.put('/projects/:projectId/connection',
+  requireAuth,
+  [
+    projectIdParam,
+    body('provider').isIn(['github', 'gitlab']).withMessage('Provider must be github or gitlab'),
+    body('owner').isString().isLength({ min: 1 }).withMessage('Repository owner or namespace is required'),
+    body('repo').isString().isLength({ min: 1 }).withMessage('Repository name is required'),
+    body('token').isString().isLength({ min: 1 }).withMessage('Access token is required'),
+    body('defaultBranch').optional().isString().withMessage('Default branch must be a string'),
+    body('baseUrl').optional().isURL({ require_tld: false }).withMessage('Base URL must be a URL')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      if (!(await ensureProjectAccess(req, res))) return;
+
+      const { provider, owner, repo, token, defaultBranch, baseUrl } = req.body;
+      const connection = await GitConnection.findOneAndUpdate(
+        { projectId: req.params.projectId },
+        {
+          provider,
+          owner,
+          repo,
+          defaultBranch: defaultBranch || 'main',
+          baseUrl,
+          encryptedToken: encryptToken(token),
+          createdBy: req.user!._id
+        },
+        { upsert: true, new: true, setDefaultsOnInsert: true }
+      );
+
+      res.json({
+        success: true,
+        data: connection,
+        message: 'Repository connection saved'
+      });
+    } catch (error) {
+      console.error('Error saving git connection:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to save repository connection'
+      });
+    }
+  }
+);
+
+// GET /api/git/projects/:projectId/connection - Connection details (never the token)
+router.get('/projects/:projectId/connection',
+  requireAuth,
+  [projectIdParam],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      if (!(await ensureProjectAccess(req, res))) return;
+
+      const connection = await GitConnection.findOne({ projectId: req.params.projectId });
+      if (!connection) {
+        return res.status(404).json({
+          success: false,
+          error: 'No repository connected'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: connection
+      });
+    } catch (error) {
+      console.error('Error fetching git connection:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch repository connection'
+      });
+    }
+  }
+);
+
+// DELETE /api/git/projects/:projectId/connection - Forget the repository and token
+router.delete('/projects/:projectId/connection',
+  requireAuth,
+  [projectIdParam],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      if (!(await ensureProjectAccess(req, res))) return;
+
+      await GitConnection.deleteOne({ projectId: req.params.projectId });
+
+      res.json({
+        success: true,
+        message: 'Repository connection removed'
+      });
+    } catch (error) {
+      console.error('Error deleting git connection:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to remove repository connection'
+      });
+    }
+  }
+);
+
+// POST /api/git/projects/:projectId/repository - Create the connected repository on the provider
+router.post('/projects/:projectId/repository',
+  requireAuth,
+  [
+    projectIdParam,
+    body('description').optional().isString().withMessage('Description must be a string'),
+    body('private').optional().isBoolean().withMessage('Private must be a boolean')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      if (!(await ensureProjectAccess(req, res))) return;
+
+      const loaded = await loadConnection(req.params.projectId);
+      if (!loaded) {
+        return res.status(404).json({
+          success: false,
+          error: 'No repository connected'
+        });
+      }
+
+      const { connection, target } = loaded;
+      const created = await providers[connection.provider].createRepository(target, {
+        description: req.body.description,
+        private: req.body.private
+      });
+
+      connection.owner = created.owner;
+      connection.repo = created.repo;
+      connection.defaultBranch = created.defaultBranch;
+      connection.repositoryUrl = created.url;
+      await connection.save();
+
+      res.status(201).json({
+        success: true,
+        data: created,
+        message: 'Repository created successfully'
+      });
+    } catch (error) {
+      console.error('Error creating repository:', error);
+      res.status(502).json({
+        success: false,
+        error: `Failed to create repository: ${providerError(error)}`
+      });
+    }
+  }
+);
+
+// POST /api/git/projects/:projectId/publish - Commit generated files to a branch and optionally open a PR/MR
+router.post('/projects/:projectId/publish',
+  requireAuth,
+  [
+    projectIdParam,
+    body('files').optional().isArray({ min: 1 }).withMessage('Files must be a non-empty array'),
+    body('files.*.path').optional().isString().isLength({ min: 1 }).withMessage('Each file needs a path'),
+    body('files.*.content').optional().isString().withMessage('Each file needs content'),
+    body('artifactId').optional().isMongoId().withMessage('Invalid artifact ID'),
+    body('commitMessage').isString().isLength({ min: 1 }).withMessage('Commit message is required'),
+    body('branch').optional().matches(/^[\w./-]+$/).withMessage('Invalid branch name'),
+    body('pullRequest').optional().isObject().withMessage('Pull request must be an object'),
+    body('pullRequest.title').optional().isString().isLength({ min: 1 }).withMessage('Pull request title is required'),
+    body('pullRequest.description').optional().isString().withMessage('Pull request description must be a string'),
+    body('pullRequest.reviewSummary').optional().isString().withMessage('Review summary must be a string')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      if (!req.body.files && !req.body.artifactId) {
+        return res.status(400).json({
+          success: false,
+          error: 'files or artifactId is required'
+        });
+      }
+
+      if (!(await ensureProjectAccess(req, res))) return;
+
+      const loaded = await loadConnection(req.params.projectId);
+      if (!loaded) {
+        return res.status(404).json({
+          success: false,
+          error: 'No repository connected'
+        });
+      }
+
+      const { connection, target } = loaded;
+      const provider = providers[connection.provider];
+      const files: CommitFile[] = req.body.files || await loadArtifactFiles(req.body.artifactId, req.headers.authorization || '');
+      const branch = req.body.branch || `ai-generated-${Date.now()}`;
+
+      const commit = await provider.commitFiles(target, files, req.body.commitMessage, branch, connection.defaultBranch);
+
+      let pullRequest;
+      if (req.body.pullRequest) {
+        const { title, description, reviewSummary } = req.body.pullRequest;
+        pullRequest = await provider.createPullRequest(
+          target,
+          title || req.body.commitMessage,
+          buildPullRequestBody(description, reviewSummary),
+          branch,
+          connection.defaultBranch
+        );
+      }
+
+      res.json({
+        success: true,
+        data: {
+          provider: connection.provider,
+          ...commit,
+          files: files.length,
+          pullRequest
+        },
+        message: pullRequest ? 'Code committed and pull request opened' : 'Code committed successfully'
+      });
+    } catch (error) {
+      console.error('Error publishing code:', error);
+      res.status(502).json({
+        success: false,
+        error: `Failed to publish code: ${providerError(error)}`
+      });
+    }
+  }
+);
+
+// POST /api/git/projects/:projectId/pulls/:number/reviews - Post a review with line comments on a PR/MR
+router.post('/projects/:projectId/pulls/:number/reviews',
+  requireAuth,
+  [
+    projectIdParam,
+    param('number').isInt({ min: 1 }).withMessage('Invalid pull request number'),
+    body('summary').isString().withMessage('Review summary is required'),
+    body('comments').optional().isArray().withMessage('Comments must be an array'),
+    body('comments.*.path').optional().isString().isLength({ min: 1 }).withMessage('Each comment needs a path'),
+    body('comments.*.line').optional().isInt({ min: 1 }).withMessage('Each comment needs a line number'),
+    body('comments.*.body').optional().isString().isLength({ min: 1 }).withMessage('Each comment needs a body'),
+    body('requestChanges').optional().isBoolean().withMessage('requestChanges must be a boolean')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      if (!(await ensureProjectAccess(req, res))) return;
+
+      const loaded = await loadConnection(req.params.projectId);
+      if (!loaded) {
+        return res.status(404).json({
+          success: false,
+          error: 'No repository connected'
+        });
+      }
+
+      const { connection, target } = loaded;
+      const result = await providers[connection.provider].postReview(target, parseInt(req.params.number), {
+        summary: req.body.summary,
+        comments: req.body.comments || [],
+        requestChanges: Boolean(req.body.requestChanges)
+      });
+
+      res.status(201).json({
+        success: true,
+        data: result,
+        message: 'Review posted successfully'
+      });
+    } catch (error) {
+      console.error('Error posting review:', error);
+      res.status(502).json({
+        success: false,
+        error: `Failed to post review: ${providerError(error)}`
+      });
+    }
+  }
+);
+
+export default router;
@@ -1,8 +1,10 @@
 import express from 'express';
 import cors from 'cors';
+import mongoose from 'mongoose';
 import dotenv from 'dotenv';
 import winston from 'winston';
 import githubRoutes from './routes/github.js';
+import gitRoutes from './routes/git.js';
 
 // Load environment variables
 dotenv.config();
@@ -23,6 +25,18 @@ const logger = winston.createLogger({
   ]
 });
 
+// Database connection (per-project repository connections)
+const MONGODB_URI = process.env.MONGODB_URI || 'mongodb://localhost:27017/ai-pipeline';
+
+mongoose.connect(MONGODB_URI)
+  .then(() => {
+    logger.info('🗄️  Connected to MongoDB');
+  })
+  .catch((error) => {
+    logger.error('❌ MongoDB connection error:', error);
+    process.exit(1);
+  });
+
 // Middleware
 app.use(cors({
   origin: process.env.FRONTEND_URL || 'http://localhost:5173',
@@ -43,6 +57,7 @@ app.use((req, res, next) => {
 
 // Routes
 app.use('/api/github', githubRoutes);
+app.use('/api/git', gitRoutes);
 
 // Health check endpoint
 app.get('/health', (req, res) => {
@@ -50,7 +65,8 @@ app.get('/health', (req, res) => {
     status: 'ok',
     service: 'github-service',
     timestamp: new Date().toISOString(),
-    version: '1.0.0'
+    version: '1.0.0',
+    database: mongoose.connection.readyState === 1 ? 'connected' : 'disconnected'
   });
 });
 
@@ -67,7 +83,13 @@ app.get('/info', (req, res) => {
       { method: 'POST', path: '/api/github/get-file', description: 'Get file content from repository' },
       { method: 'POST', path: '/api/github/push-code', description: 'Push generated code to repository' },
       { method: 'POST', path: '/api/github/create-pr', description: 'Create pull request' },
-      { method: 'POST', path: '/api/github/push-and-pr', description: 'Push code and create PR in one operation' }
+      { method: 'POST', path: '/api/github/push-and-pr', description: 'Push code and create PR in one operation' },
+      { method: 'PUT', path: '/api/git/projects/:projectId/connection', description: 'Connect project to GitHub/GitLab repository' },
+      { method: 'GET', path: '/api/git/projects/:projectId/connection', description: 'Get project repository connection' },
+      { method: 'DELETE', path: '/api/git/projects/:projectId/connection', description: 'Remove project repository connection' },
+      { method: 'POST', path: '/api/git/projects/:projectId/repository', description: 'Create connected repository' },
+      { method: 'POST', path: '/api/git/projects/:projectId/publish', description: 'Commit generated files and open PR/MR' },
+      { method: 'POST', path: '/api/git/projects/:projectId/pulls/:number/reviews', description: 'Post review comments on PR/MR' }
     ],
     features: [
       'GitHub API proxy with token management',
@@ -76,6 +98,8 @@ app.get('/info', (req, res) => {
       'File content retrieval',
       'Code generation and push to repositories',
       'Pull request creation and management',
+      'GitLab projects and merge requests',
+      'Per-project encrypted access tokens',
       'Secure token handling with validation',
       'Rate limiting and error handling'
     ]
@@ -137,6 +161,7 @@ app.use((err: any, req: any, res: any, next: any) => {
 // Graceful shutdown
 process.on('SIGTERM', () => {
   logger.info('SIGTERM received. Shutting down gracefully...');
+  mongoose.connection.close();
   process.exit(0);
 });
 
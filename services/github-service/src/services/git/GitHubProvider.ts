@@ -0,0 +1,120 @@
+import { Octokit } from '@octokit/rest';
+import {
+  GitProvider,
+  RepositoryTarget,
+  CreateRepositoryOptions,
+  CreatedRepository,
+  CommitFile,
+  CommitResult,
+  PullRequestResult,
+  PullRequestReview
+} from './GitProvider.js';
+
+export class GitHubProvider implements GitProvider {
+  readonly name = 'github' as const;
+
+  async createRepository(target: RepositoryTarget, options: CreateRepositoryOptions): Promise<CreatedRepository> {
+    const octokit = new Octokit({ auth: target.token });
+    const { data: user } = await octokit.users.getAuthenticated();
+
+    const params = {
+      name: target.repo,
+      description: options.description,
+      private: options.private ?? true,
+      auto_init: true
+    };
+
+    // Creating under another owner means creating in an organization
+    const { data } = target.owner && target.owner !== user.login
+      ? await octokit.repos.createInOrg({ org: target.owner, ...params })
+      : await octokit.repos.createForAuthenticatedUser(params);
+
+    return {
+      owner: data.owner.login,
+      repo: data.name,
+      url: data.html_url,
+      defaultBranch: data.default_branch || 'main'
+    };
+  }
+
+  // Writes all files as a single commit via the git data API
+  async commitFiles(target: RepositoryTarget, files: CommitFile[], message: string, branch: string, baseBranch: string): Promise<CommitResult> {
+    const octokit = new Octokit({ auth: target.token });
+    const { owner, repo } = target;
+
+    const branchExists = await octokit.git.getRef({ owner, repo, ref: `heads/${branch}` })
+      .then(() => true)
+      .catch(() => false);
+
+    const { data: baseRef } = await octokit.git.getRef({ owner, repo, ref: `heads/${branchExists ? branch : baseBranch}` });
+    const { data: parent } = await octokit.git.getCommit({ owner, repo, commit_sha: baseRef.object.sha });
+
+    const { data: tree } = await octokit.git.createTree({
+      owner,
+      repo,
+      base_tree: parent.tree.sha,
+      tree: files.map(file => ({
+        path: file.path,
+        mode: '100644' as const,
+        type: 'blob' as const,
+        content: file.content
+      }))
+    });
+
+    const { data: commit } = await octokit.git.createCommit({
+      owner,
+      repo,
+      message,
+      tree: tree.sha,
+      parents: [parent.sha]
+    });
+
+    if (branchExists) {
+      await octokit.git.updateRef({ owner, repo, ref: `heads/${branch}`, sha: commit.sha });
+    } else {
+      await octokit.git.createRef({ owner, repo, ref: `refs/heads/${branch}`, sha: commit.sha });
+    }
+
+    return {
+      branch,
+      commitSha: commit.sha,
+      commitUrl: commit.html_url
+    };
+  }
+
+  async createPullRequest(target: RepositoryTarget, title: string, body: string, head: string, base: string): Promise<PullRequestResult> {
+    const octokit = new Octokit({ auth: target.token });
+    const { data } = await octokit.pulls.create({
+      owner: target.owner,
+      repo: target.repo,
+      title,
+      body,
+      head,
+      base
+    });
+
+    return {
+      number: data.number,
+      url: data.html_url
+    };
+  }
+
+  async postReview(target: RepositoryTarget, number: number, review: PullRequestReview): Promise<{ url?: string }> {
+    const octokit = new Octokit({ auth: target.token });
+    const { data } = await octokit.pulls.createReview({
+      owner: target.owner,
+      repo: target.repo,
+      pull_number: number,
+      body: review.summary,
+      event: review.requestChanges ? 'REQUEST_CHANGES' : 'COMMENT',
+      comments: review.comments.map(comment => ({
+        path: comment.path,
+        line: comment.line,
+        side: 'RIGHT',
+        body: comment.body
+      }))
+    });
+
+    return { url: data.html_url };
+  }
+}
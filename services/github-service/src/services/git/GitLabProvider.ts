@@ -0,0 +1,131 @@
+import axios, { AxiosInstance } from 'axios';
+import {
+  GitProvider,
+  RepositoryTarget,
+  CreateRepositoryOptions,
+  CreatedRepository,
+  CommitFile,
+  CommitResult,
+  PullRequestResult,
+  PullRequestReview
+} from './GitProvider.js';
+
+// GitLab REST v4; merge requests play the role of pull requests
+export class GitLabProvider implements GitProvider {
+  readonly name = 'gitlab' as const;
+
+  private client(target: RepositoryTarget): AxiosInstance {
+    return axios.create({
+      baseURL: target.baseUrl || process.env.GITLAB_BASE_URL || 'https://gitlab.com/api/v4',
+      headers: { 'PRIVATE-TOKEN': target.token },
+      timeout: 30000
+    });
+  }
+
+  private projectPath(target: RepositoryTarget): string {
+    return encodeURIComponent(`${target.owner}/${target.repo}`);
+  }
+
+  async createRepository(target: RepositoryTarget, options: CreateRepositoryOptions): Promise<CreatedRepository> {
+    const client = this.client(target);
+    const { data: user } = await client.get('/user');
+
+    let namespaceId: number | undefined;
+    if (target.owner && target.owner !== user.username) {
+      const { data: namespace } = await client.get(`/namespaces/${encodeURIComponent(target.owner)}`);
+      namespaceId = namespace.id;
+    }
+
+    const { data } = await client.post('/projects', {
+      name: target.repo,
+      path: target.repo,
+      namespace_id: namespaceId,
+      description: options.description,
+      visibility: options.private === false ? 'public' : 'private',
+      initialize_with_readme: true
+    });
+
+    return {
+      owner: data.namespace.full_path,
+      repo: data.path,
+      url: data.web_url,
+      defaultBranch: data.default_branch || 'main'
+    };
+  }
+
+  async commitFiles(target: RepositoryTarget, files: CommitFile[], message: string, branch: string, baseBranch: string): Promise<CommitResult> {
+    const client = this.client(target);
+    const project = this.projectPath(target);
+
+    const branchExists = await client.get(`/projects/${project}/repository/branches/${encodeURIComponent(branch)}`)
+      .then(() => true)
+      .catch(() => false);
+    const ref = branchExists ? branch : baseBranch;
+
+    // GitLab needs to know whether each file is new or an update
+    const actions = await Promise.all(files.map(async file => {
+      const exists = await client.head(`/projects/${project}/repository/files/${encodeURIComponent(file.path)}`, { params: { ref } })
+        .then(() => true)
+        .catch(() => false);
+
+      return {
+        action: exists ? 'update' : 'create',
+        file_path: file.path,
+        content: file.content
+      };
+    }));
+
+    const { data } = await client.post(`/projects/${project}/repository/commits`, {
+      branch,
+      start_branch: branchExists ? undefined : baseBranch,
+      commit_message: message,
+      actions
+    });
+
+    return {
+      branch,
+      commitSha: data.id,
+      commitUrl: data.web_url
+    };
+  }
+
+  async createPullRequest(target: RepositoryTarget, title: string, body: string, head: string, base: string): Promise<PullRequestResult> {
+    const { data } = await this.client(target).post(`/projects/${this.projectPath(target)}/merge_requests`, {
+      title,
+      description: body,
+      source_branch: head,
+      target_branch: base
+    });
+
+    return {
+      number: data.iid,
+      url: data.web_url
+    };
+  }
+
+  // Line comments become diff discussions anchored on the MR's latest diff
+  async postReview(target: RepositoryTarget, number: number, review: PullRequestReview): Promise<{ url?: string }> {
+    const client = this.client(target);
+    const project = this.projectPath(target);
+    const { data: mergeRequest } = await client.get(`/projects/${project}/merge_requests/${number}`);
+    const refs = mergeRequest.diff_refs;
+
+    for (const comment of review.comments) {
+      await client.post(`/projects/${project}/merge_requests/${number}/discussions`, {
+        body: comment.body,
+        position: {
+          position_type: 'text',
+          base_sha: refs.base_sha,
+          start_sha: refs.start_sha,
+          head_sha: refs.head_sha,
+          new_path: comment.path,
+          new_line: comment.line
+        }
+      });
+    }
+
+    await client.post(`/projects/${project}/merge_requests/${number}/notes`, { body: review.summary });
+
+    return { url: mergeRequest.web_url };
+  }
+}
@@ -0,0 +1,55 @@
+export interface RepositoryTarget {
+  token: string;
+  owner: string;
+  repo: string;
+  baseUrl?: string;
+}
+
+export interface CreateRepositoryOptions {
+  description?: string;
+  private?: boolean;
+}
+
+export interface CreatedRepository {
+  owner: string;
+  repo: string;
+  url: string;
+  defaultBranch: string;
+}
+
+export interface CommitFile {
+  path: string;
+  content: string;
+}
+
+export interface CommitResult {
+  branch: string;
+  commitSha: string;
+  commitUrl?: string;
+}
+
+export interface PullRequestResult {
+  number: number;
+  url: string;
+}
+
+export interface ReviewComment {
+  path: string;
+  line: number;
+  body: string;
+}
+
+export interface PullRequestReview {
+  summary: string;
+  comments: ReviewComment[];
+  requestChanges: boolean;
+}
+
+// Common surface over GitHub and GitLab for publishing generated projects
+export interface GitProvider {
+  readonly name: 'github' | 'gitlab';
+  createRepository(target: RepositoryTarget, options: CreateRepositoryOptions): Promise<CreatedRepository>;
+  commitFiles(target: RepositoryTarget, files: CommitFile[], message: string, branch: string, baseBranch: string): Promise<CommitResult>;
+  createPullRequest(target: RepositoryTarget, title: string, body: string, head: string, base: string): Promise<PullRequestResult>;
+  postReview(target: RepositoryTarget, number: number, review: PullRequestReview): Promise<{ url?: string }>;
+}
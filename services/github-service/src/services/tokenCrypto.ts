@@ -0,0 +1,24 @@
+import { createCipheriv, createDecipheriv, createHash, randomBytes } from 'crypto';
+
+// AES-256-GCM for per-project access tokens stored at rest
+const getKey = (): Buffer => {
+  const secret = process.env.GIT_TOKEN_ENCRYPTION_KEY;
+  if (!secret) {
+    throw new Error('GIT_TOKEN_ENCRYPTION_KEY is not configured');
+  }
+  return createHash('sha256').update(secret).digest();
+};
+
+export const encryptToken = (token: string): string => {
+  const iv = randomBytes(12);
+  const cipher = createCipheriv('aes-256-gcm', getKey(), iv);
+  const encrypted = Buffer.concat([cipher.update(token, 'utf-8'), cipher.final()]);
+  return [iv, cipher.getAuthTag(), encrypted].map(part => part.toString('base64')).join('.');
+};
+
+export const decryptToken = (payload: string): string => {
+  const [iv, tag, encrypted] = payload.split('.').map(part => Buffer.from(part, 'base64'));
+  const decipher = createDecipheriv('aes-256-gcm', getKey(), iv);
+  decipher.setAuthTag(tag);
+  return Buffer.concat([decipher.update(encrypted), decipher.final()]).toString('utf-8');
+};
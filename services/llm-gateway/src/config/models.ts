@@ -0,0 +1,86 @@
+import { ModelSpec } from '../types/index.js';
+
+// Seed entries for the model registry: provider limits and list prices (USD per million tokens)
+export const MODEL_SPECS: Record<string, ModelSpec> = {
+  'gpt-4o': {
+    provider: 'openai',
+    contextWindow: 128000,
+    maxOutputTokens: 16384,
+    inputPricePerMillion: 2.5,
+    outputPricePerMillion: 10,
+    capabilities: ['chat', 'json', 'vision', 'tools'],
+    aliases: ['gpt-4o-class']
+  },
+  'gpt-4o-mini': {
+    provider: 'openai',
+    contextWindow: 128000,
+    maxOutputTokens: 16384,
+    inputPricePerMillion: 0.15,
+    outputPricePerMillion: 0.6,
+    capabilities: ['chat', 'json', 'vision', 'tools'],
+    aliases: ['gpt-4o-mini-class']
+  },
+  'claude-3-5-sonnet-latest': {
+    provider: 'anthropic',
+    contextWindow: 200000,
+    maxOutputTokens: 8192,
+    inputPricePerMillion: 3,
+    outputPricePerMillion: 15,
+    capabilities: ['chat', 'vision', 'tools'],
+    aliases: ['gpt-4o-class']
+  },
+  'claude-3-5-haiku-latest': {
+    provider: 'anthropic',
+    contextWindow: 200000,
+    maxOutputTokens: 8192,
+    inputPricePerMillion: 0.8,
+    outputPricePerMillion: 4,
+    capabilities: ['chat', 'tools'],
+    aliases: ['gpt-4o-mini-class']
+  },
+  'gemini-1.5-pro-latest': {
+    provider: 'gemini',
+    contextWindow: 2000000,
+    maxOutputTokens: 8192,
+    inputPricePerMillion: 1.25,
+    outputPricePerMillion: 5,
+    capabilities: ['chat', 'json', 'vision', 'tools'],
+    aliases: ['gpt-4o-class']
+  },
+  'gemini-1.5-flash-latest': {
+    provider: 'gemini',
+    contextWindow: 1000000,
+    maxOutputTokens: 8192,
+    inputPricePerMillion: 0.075,
+    outputPricePerMillion: 0.3,
+    capabilities: ['chat', 'json', 'vision', 'tools'],
+    aliases: ['gpt-4o-mini-class']
+  },
+  // Embedding models produce no output tokens; embeddings is the provider-neutral alias
+  'text-embedding-3-small': {
+    provider: 'openai',
+    contextWindow: 8191,
+    maxOutputTokens: 0,
+    inputPricePerMillion: 0.02,
+    outputPricePerMillion: 0,
+    capabilities: ['embeddings'],
+    aliases: ['embeddings']
+  },
+  'text-embedding-3-large': {
+    provider: 'openai',
+    contextWindow: 8191,
+    maxOutputTokens: 0,
+    inputPricePerMillion: 0.13,
+    outputPricePerMillion: 0,
+    capabilities: ['embeddings']
+  },
+  'text-embedding-004': {
+    provider: 'gemini',
+    contextWindow: 2048,
+    maxOutputTokens: 0,
+    inputPricePerMillion: 0,
+    outputPricePerMillion: 0,
+    capabilities: ['embeddings'],
+    aliases: ['embeddings']
+  }
+};
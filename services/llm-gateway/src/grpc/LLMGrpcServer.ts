@@ -0,0 +1,111 @@
+import * as path from 'path';
+import * as grpc from '@grpc/grpc-js';
+import * as protoLoader from '@grpc/proto-loader';
+import { LLMGatewayService } from '../services/LLMGatewayService.js';
+import { BudgetExceededError } from '../services/BudgetService.js';
+import { ChatCompletionRequest, ProviderError } from '../types/index.js';
+
+const PROTO_PATH = process.env.LLM_PROTO_PATH || path.join(process.cwd(), 'proto', 'llm_gateway.proto');
+
+// Same split as the HTTP route: client mistakes, missing providers, rate limits and budgets keep their meaning,
+// everything else from upstream is unavailable
+function toStatus(error: unknown): { code: grpc.status; details: string } {
+  const details = error instanceof Error ? error.message : 'Failed to complete chat request';
+  if (error instanceof BudgetExceededError) return { code: grpc.status.RESOURCE_EXHAUSTED, details };
+  if (error instanceof ProviderError) {
+    if (error.status === 400) return { code: grpc.status.INVALID_ARGUMENT, details };
+    if (error.reason === 'rate_limit') return { code: grpc.status.RESOURCE_EXHAUSTED, details };
+    return { code: grpc.status.UNAVAILABLE, details };
+  }
+  return { code: grpc.status.INTERNAL, details };
+}
+
+function toRequest(message: any): ChatCompletionRequest {
+  return {
+    model: message.model,
+    messages: (message.messages || []).map((m: any) => ({ role: m.role, content: m.content })),
+    // proto3 optional fields come back as undefined when unset
+    temperature: message.temperature ?? undefined,
+    maxTokens: message.maxTokens || undefined,
+    metadata: message.metadata && Object.keys(message.metadata).length > 0 ? message.metadata : undefined,
+    contextOverflow: message.contextOverflow || undefined
+  };
+}
+
+// gRPC front of the gateway for callers that want completions as they are generated
+export class LLMGrpcServer {
+  private server = new grpc.Server();
+
+  constructor(private gateway: LLMGatewayService) {
+    const definition = protoLoader.loadSync(PROTO_PATH, { keepCase: false, defaults: true, oneofs: true });
+    const proto = grpc.loadPackageDefinition(definition) as any;
+
+    this.server.addService(proto.aipipeline.llm.v1.LLMGateway.service, {
+      StreamChat: (call: grpc.ServerWritableStream<any, any>) => this.streamChat(call)
+    });
+  }
+
+  start(port: number | string): Promise<number> {
+    return new Promise((resolve, reject) => {
+      this.server.bindAsync(`0.0.0.0:${port}`, grpc.ServerCredentials.createInsecure(), (error, boundPort) => {
+        if (error) reject(error);
+        else resolve(boundPort);
+      });
+    });
+  }
+
+  stop(): void {
+    this.server.forceShutdown();
+  }
+
+  private async streamChat(call: grpc.ServerWritableStream<any, any>): Promise<void> {
+    const request = toRequest(call.request);
+    const validation = this.validate(request);
+    if (validation) {
+      call.emit('error', { code: grpc.status.INVALID_ARGUMENT, details: validation });
+      return;
+    }
+
+    // The caller cancelling (a run aborted upstream) cancels the provider request
+    const abort = new AbortController();
+    call.on('cancelled', () => abort.abort());
+
+    try {
+      const completion = await this.gateway.streamChat(request, delta => {
+        if (!call.cancelled) call.write({ delta });
+      }, abort.signal);
+
+      if (call.cancelled) return;
+      call.write({
+        done: {
+          id: completion.id,
+          provider: completion.provider,
+          model: completion.model,
+          requestedModel: completion.requestedModel || '',
+          finishReason: completion.finishReason,
+          usage: completion.usage,
+          latencyMs: completion.latencyMs || 0,
+          attempts: completion.attempts || 1,
+          budgetWarnings: completion.budgetWarnings || [],
+          contextTruncation: completion.contextTruncation,
+          routingPolicy: completion.routingPolicy || '',
+          fallbacks: completion.fallbacks || []
+        }
+      });
+      call.end();
+    } catch (error) {
+      if (call.cancelled) return;
+      console.error('Streaming chat error:', error);
+      call.emit('error', toStatus(error));
+    }
+  }
+
+  private validate(request: ChatCompletionRequest): string | undefined {
+    if (!request.model) return 'Model is required';
+    if (request.messages.length === 0) return 'At least one message is required';
+    if (request.messages.some(message => !['system', 'user', 'assistant'].includes(message.role))) return 'Invalid message role';
+    if (request.temperature !== undefined && (request.temperature < 0 || request.temperature > 2)) return 'Temperature must be between 0 and 2';
+    if (request.contextOverflow !== undefined && !['truncate', 'error'].includes(request.contextOverflow)) return 'contextOverflow must be truncate or error';
+    return undefined;
+  }
+}
@@ -0,0 +1,91 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export type BudgetScope = 'project' | 'provider';
+export type BudgetPeriod = 'daily' | 'monthly' | 'total';
+
+export interface BudgetOverride {
+  until: Date;
+  reason: string;
+  grantedBy: string;
+  grantedAt: Date;
+}
+
+export interface IBudget extends Document {
+  scope: BudgetScope;
+  scopeId: string;
+  period: BudgetPeriod;
+  limitUsd: number;
+  softThreshold: number;
+  hardStop: boolean;
+  spentUsd: number;
+  periodStart: Date;
+  warnedAt?: Date;
+  override?: BudgetOverride;
+  createdBy: string;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const BudgetSchema: Schema = new Schema({
+  // project budgets key on projectId; provider budgets cap the gateway's API key for that provider
+  scope: {
+    type: String,
+    enum: ['project', 'provider'],
+    required: true
+  },
+  scopeId: {
+    type: String,
+    required: true,
+    trim: true
+  },
+  period: {
+    type: String,
+    enum: ['daily', 'monthly', 'total'],
+    default: 'monthly'
+  },
+  limitUsd: {
+    type: Number,
+    required: true,
+    min: 0
+  },
+  // Fraction of the limit at which a warning is raised
+  softThreshold: {
+    type: Number,
+    default: 0.8,
+    min: 0,
+    max: 1
+  },
+  // When false the limit only warns
+  hardStop: {
+    type: Boolean,
+    default: true
+  },
+  spentUsd: {
+    type: Number,
+    default: 0
+  },
+  periodStart: {
+    type: Date,
+    default: Date.now
+  },
+  warnedAt: {
+    type: Date
+  },
+  // Temporarily lifts the hard stop
+  override: {
+    until: Date,
+    reason: String,
+    grantedBy: String,
+    grantedAt: Date
+  },
+  createdBy: {
+    type: String,
+    required: true
+  }
+}, {
+  timestamps: true
+});
+
+BudgetSchema.index({ scope: 1, scopeId: 1 }, { unique: true });
+
+export const Budget = mongoose.model<IBudget>('Budget', BudgetSchema);
@@ -0,0 +1,82 @@
+import mongoose, { Schema, Document } from 'mongoose';
+import { ProviderName } from '../types/index.js';
+
+export interface IModelDefinition extends Document {
+  modelId: string;
+  provider: ProviderName;
+  contextWindow: number;
+  maxOutputTokens: number;
+  inputPricePerMillion: number;
+  outputPricePerMillion: number;
+  capabilities: string[];
+  aliases: string[];
+  priority: number;
+  enabled: boolean;
+  deprecatedAt?: Date;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const ModelDefinitionSchema: Schema = new Schema({
+  // Provider model ID sent upstream, e.g. gpt-4o
+  modelId: {
+    type: String,
+    required: true,
+    unique: true,
+    trim: true
+  },
+  provider: {
+    type: String,
+    enum: ['openai', 'anthropic', 'gemini'],
+    required: true
+  },
+  contextWindow: {
+    type: Number,
+    required: true,
+    min: 1
+  },
+  maxOutputTokens: {
+    type: Number,
+    required: true,
+    min: 1
+  },
+  // USD per million tokens
+  inputPricePerMillion: {
+    type: Number,
+    required: true,
+    min: 0
+  },
+  outputPricePerMillion: {
+    type: Number,
+    required: true,
+    min: 0
+  },
+  capabilities: [{
+    type: String,
+    trim: true
+  }],
+  // Class names such as gpt-4o-class; several models may share one
+  aliases: [{
+    type: String,
+    trim: true
+  }],
+  // Lower wins when an alias matches several models
+  priority: {
+    type: Number,
+    default: 100
+  },
+  enabled: {
+    type: Boolean,
+    default: true
+  },
+  // Calls are refused once this date has passed
+  deprecatedAt: {
+    type: Date
+  }
+}, {
+  timestamps: true
+});
+
+ModelDefinitionSchema.index({ aliases: 1 });
+
+export const ModelDefinition = mongoose.model<IModelDefinition>('ModelDefinition', ModelDefinitionSchema);
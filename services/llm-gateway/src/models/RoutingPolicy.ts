@@ -0,0 +1,62 @@
+import mongoose, { Schema, Document } from 'mongoose';
+import { ProviderFailure } from '../types/index.js';
+
+export interface IRoutingPolicy extends Document {
+  projectId?: string;
+  model: string;
+  primary?: string;
+  fallbacks: string[];
+  fallbackOn: ProviderFailure[];
+  healthWeighted: boolean;
+  enabled: boolean;
+  updatedBy: string;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const RoutingPolicySchema: Schema = new Schema({
+  // Set for a project's override; without it the policy applies to every project
+  projectId: {
+    type: String,
+    trim: true
+  },
+  // Requested model ID or alias the policy applies to; * matches any
+  model: {
+    type: String,
+    required: true,
+    trim: true
+  },
+  // Model called first instead of the requested one
+  primary: {
+    type: String,
+    trim: true
+  },
+  // Tried in order once the primary fails for one of the fallbackOn reasons
+  fallbacks: [{
+    type: String,
+    trim: true
+  }],
+  fallbackOn: [{
+    type: String,
+    enum: ['rate_limit', 'outage', 'content_filter']
+  }],
+  // Models on providers that are failing or cooling down are tried after healthy ones
+  healthWeighted: {
+    type: Boolean,
+    default: true
+  },
+  enabled: {
+    type: Boolean,
+    default: true
+  },
+  updatedBy: {
+    type: String,
+    required: true
+  }
+}, {
+  timestamps: true
+});
+
+RoutingPolicySchema.index({ projectId: 1, model: 1 }, { unique: true });
+
+export const RoutingPolicy = mongoose.model<IRoutingPolicy>('RoutingPolicy', RoutingPolicySchema);
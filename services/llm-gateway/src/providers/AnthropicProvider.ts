@@ -0,0 +1,130 @@
+import axios from 'axios';
+import { ChatCompletionRequest, ProviderCompletion, ProviderError } from '../types/index.js';
+import { DeltaHandler, LLMProvider, fingerprint, parseRateLimitHeaders, sseData, toProviderError } from './LLMProvider.js';
+
+export class AnthropicProvider implements LLMProvider {
+  readonly name = 'anthropic' as const;
+  private baseUrl = process.env.ANTHROPIC_BASE_URL || 'https://api.anthropic.com/v1';
+
+  constructor(private apiKey: string | undefined = process.env.ANTHROPIC_API_KEY) {}
+
+  isConfigured(): boolean {
+    return !!this.apiKey;
+  }
+
+  keyId(): string {
+    return fingerprint(this.apiKey);
+  }
+
+  async complete(request: ChatCompletionRequest): Promise<ProviderCompletion> {
+    try {
+      const { data, headers } = await axios.post(`${this.baseUrl}/messages`, this.body(request), {
+        headers: this.headers(),
+        timeout: 120000
+      });
+
+      const inputTokens = data.usage?.input_tokens || 0;
+      const outputTokens = data.usage?.output_tokens || 0;
+
+      return {
+        id: data.id,
+        content: (data.content || [])
+          .filter((block: any) => block.type === 'text')
+          .map((block: any) => block.text)
+          .join(''),
+        finishReason: data.stop_reason || 'unknown',
+        usage: {
+          promptTokens: inputTokens,
+          completionTokens: outputTokens,
+          totalTokens: inputTokens + outputTokens
+        },
+        rateLimit: parseRateLimitHeaders(headers)
+      };
+    } catch (error) {
+      throw toProviderError(this.name, error);
+    }
+  }
+
+  async stream(request: ChatCompletionRequest, onDelta: DeltaHandler, signal?: AbortSignal): Promise<ProviderCompletion> {
+    try {
+      const response = await axios.post(`${this.baseUrl}/messages`, { ...this.body(request), stream: true }, {
+        headers: this.headers(),
+        timeout: 120000,
+        responseType: 'stream',
+        signal
+      });
+
+      let id = '';
+      let content = '';
+      let finishReason = 'unknown';
+      let inputTokens = 0;
+      let outputTokens = 0;
+
+      for await (const event of sseData(response.data)) {
+        switch (event.type) {
+          case 'message_start':
+            id = event.message?.id || id;
+            inputTokens = event.message?.usage?.input_tokens || 0;
+            break;
+          case 'content_block_delta':
+            if (event.delta?.type === 'text_delta' && event.delta.text) {
+              content += event.delta.text;
+              onDelta(event.delta.text);
+            }
+            break;
+          case 'message_delta':
+            finishReason = event.delta?.stop_reason || finishReason;
+            outputTokens = event.usage?.output_tokens ?? outputTokens;
+            break;
+          case 'error':
+            // Errors after the stream has started arrive as events rather than HTTP statuses
+            throw new ProviderError(
+              `${this.name}: ${event.error?.message || 'stream error'}`,
+              this.name,
+              undefined,
+              event.error?.type === 'overloaded_error',
+              event.error?.type === 'overloaded_error' ? 'outage' : undefined
+            );
+        }
+      }
+
+      return {
+        id,
+        content,
+        finishReason,
+        usage: {
+          promptTokens: inputTokens,
+          completionTokens: outputTokens,
+          totalTokens: inputTokens + outputTokens
+        },
+        rateLimit: parseRateLimitHeaders(response.headers)
+      };
+    } catch (error) {
+      throw toProviderError(this.name, error);
+    }
+  }
+
+  private headers() {
+    return {
+      'x-api-key': this.apiKey,
+      'anthropic-version': '2023-06-01'
+    };
+  }
+
+  private body(request: ChatCompletionRequest) {
+    // Anthropic takes the system prompt separately from the conversation
+    const system = request.messages
+      .filter(message => message.role === 'system')
+      .map(message => message.content)
+      .join('\n\n');
+    const messages = request.messages.filter(message => message.role !== 'system');
+
+    return {
+      model: request.model,
+      ...(system ? { system } : {}),
+      messages,
+      temperature: request.temperature,
+      max_tokens: request.maxTokens || 4096
+    };
+  }
+}
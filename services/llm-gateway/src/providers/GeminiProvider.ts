@@ -0,0 +1,144 @@
+import axios from 'axios';
+import { ChatCompletionRequest, ProviderCompletion, ProviderEmbedding, ProviderError } from '../types/index.js';
+import { DeltaHandler, LLMProvider, fingerprint, parseRateLimitHeaders, sseData, toProviderError } from './LLMProvider.js';
+
+export class GeminiProvider implements LLMProvider {
+  readonly name = 'gemini' as const;
+  private baseUrl = process.env.GEMINI_BASE_URL || 'https://generativelanguage.googleapis.com/v1beta';
+
+  constructor(private apiKey: string | undefined = process.env.GEMINI_API_KEY) {}
+
+  isConfigured(): boolean {
+    return !!this.apiKey;
+  }
+
+  keyId(): string {
+    return fingerprint(this.apiKey);
+  }
+
+  async complete(request: ChatCompletionRequest): Promise<ProviderCompletion> {
+    try {
+      const { data, headers } = await axios.post(
+        `${this.baseUrl}/models/${request.model}:generateContent`,
+        this.body(request),
+        {
+          params: { key: this.apiKey },
+          timeout: 120000
+        }
+      );
+
+      this.checkBlocked(data);
+      const candidate = data.candidates?.[0];
+      return {
+        id: data.responseId || `gemini_${Date.now()}`,
+        content: this.text(candidate),
+        finishReason: (candidate?.finishReason || 'unknown').toLowerCase(),
+        usage: this.usage(data),
+        rateLimit: parseRateLimitHeaders(headers)
+      };
+    } catch (error) {
+      throw toProviderError(this.name, error);
+    }
+  }
+
+  async stream(request: ChatCompletionRequest, onDelta: DeltaHandler, signal?: AbortSignal): Promise<ProviderCompletion> {
+    try {
+      const response = await axios.post(
+        `${this.baseUrl}/models/${request.model}:streamGenerateContent`,
+        this.body(request),
+        {
+          params: { key: this.apiKey, alt: 'sse' },
+          timeout: 120000,
+          responseType: 'stream',
+          signal
+        }
+      );
+
+      const completion: ProviderCompletion = {
+        id: `gemini_${Date.now()}`,
+        content: '',
+        finishReason: 'unknown',
+        usage: { promptTokens: 0, completionTokens: 0, totalTokens: 0 },
+        rateLimit: parseRateLimitHeaders(response.headers)
+      };
+      // Each event is a partial response; usage metadata is cumulative, so the last one wins
+      for await (const data of sseData(response.data)) {
+        this.checkBlocked(data);
+        const candidate = data.candidates?.[0];
+        const delta = this.text(candidate);
+        if (delta) {
+          completion.content += delta;
+          onDelta(delta);
+        }
+        completion.id = data.responseId || completion.id;
+        if (candidate?.finishReason) completion.finishReason = candidate.finishReason.toLowerCase();
+        if (data.usageMetadata) completion.usage = this.usage(data);
+      }
+      return completion;
+    } catch (error) {
+      throw toProviderError(this.name, error);
+    }
+  }
+
+  async embed(model: string, input: string[]): Promise<ProviderEmbedding> {
+    try {
+      const { data, headers } = await axios.post(
+        `${this.baseUrl}/models/${model}:batchEmbedContents`,
+        { requests: input.map(text => ({ model: `models/${model}`, content: { parts: [{ text }] } })) },
+        {
+          params: { key: this.apiKey },
+          timeout: 60000
+        }
+      );
+
+      return {
+        embeddings: (data.embeddings || []).map((embedding: any) => embedding.values),
+        rateLimit: parseRateLimitHeaders(headers)
+      };
+    } catch (error) {
+      throw toProviderError(this.name, error);
+    }
+  }
+
+  private body(request: ChatCompletionRequest) {
+    const system = request.messages
+      .filter(message => message.role === 'system')
+      .map(message => message.content)
+      .join('\n\n');
+    const contents = request.messages
+      .filter(message => message.role !== 'system')
+      .map(message => ({
+        role: message.role === 'assistant' ? 'model' : 'user',
+        parts: [{ text: message.content }]
+      }));
+
+    return {
+      ...(system ? { systemInstruction: { parts: [{ text: system }] } } : {}),
+      contents,
+      generationConfig: {
+        temperature: request.temperature,
+        maxOutputTokens: request.maxTokens
+      }
+    };
+  }
+
+  // A blocked prompt comes back as a successful response without candidates
+  private checkBlocked(data: any): void {
+    const blockReason = data.promptFeedback?.blockReason;
+    if (blockReason) {
+      throw new ProviderError(`${this.name}: prompt blocked (${blockReason.toLowerCase()})`, this.name, 400, false, 'content_filter');
+    }
+  }
+
+  private text(candidate: any): string {
+    return (candidate?.content?.parts || []).map((part: any) => part.text || '').join('');
+  }
+
+  private usage(data: any): ProviderCompletion['usage'] {
+    return {
+      promptTokens: data.usageMetadata?.promptTokenCount || 0,
+      completionTokens: data.usageMetadata?.candidatesTokenCount || 0,
+      totalTokens: data.usageMetadata?.totalTokenCount || 0
+    };
+  }
+}
@@ -0,0 +1,150 @@
+import axios from 'axios';
+import { createHash } from 'crypto';
+import {
+  ChatCompletionRequest,
+  LimitWindow,
+  ProviderCompletion,
+  ProviderEmbedding,
+  ProviderError,
+  ProviderFailure,
+  ProviderName,
+  RateLimitInfo
+} from '../types/index.js';
+
+export type DeltaHandler = (text: string) => void;
+
+export interface LLMProvider {
+  readonly name: ProviderName;
+  isConfigured(): boolean;
+  // Stable, non-secret identifier of the API key, which rate limits are tracked under
+  keyId(): string;
+  complete(request: ChatCompletionRequest): Promise<ProviderCompletion>;
+  // Same completion, with its text passed to onDelta as the provider generates it. Aborting the
+  // signal cancels the upstream request.
+  stream(request: ChatCompletionRequest, onDelta: DeltaHandler, signal?: AbortSignal): Promise<ProviderCompletion>;
+  // Providers with embedding models; Anthropic has none
+  embed?(model: string, input: string[]): Promise<ProviderEmbedding>;
+}
+
+// Parsed data payloads of a server-sent event stream, as all three providers use for streaming
+export async function* sseData(stream: AsyncIterable<Buffer | string>): AsyncGenerator<any> {
+  let buffer = '';
+  for await (const chunk of stream) {
+    buffer = (buffer + chunk.toString()).replace(/\r\n/g, '\n');
+
+    let boundary: number;
+    while ((boundary = buffer.indexOf('\n\n')) >= 0) {
+      const event = buffer.slice(0, boundary);
+      buffer = buffer.slice(boundary + 2);
+
+      const data = event.split('\n')
+        .filter(line => line.startsWith('data:'))
+        .map(line => line.slice(5).trimStart())
+        .join('\n');
+      if (data && data !== '[DONE]') yield JSON.parse(data);
+    }
+  }
+}
+
+export function fingerprint(apiKey: string | undefined): string {
+  return apiKey ? createHash('sha256').update(apiKey).digest('hex').slice(0, 12) : 'none';
+}
+
+// OpenAI resets read like 6m0s, 1.5s or 20ms
+function parseDuration(value: string): number | undefined {
+  const parts = value.match(/(\d+(?:\.\d+)?)(ms|h|m|s)/g);
+  if (!parts) return undefined;
+  const units: Record<string, number> = { h: 3_600_000, m: 60_000, s: 1000, ms: 1 };
+  return parts.reduce((total, part) => {
+    const [, amount, unit] = part.match(/(\d+(?:\.\d+)?)(ms|h|m|s)/)!;
+    return total + parseFloat(amount) * units[unit];
+  }, 0);
+}
+
+// A reset given as a duration (OpenAI), an RFC 3339 time (Anthropic) or seconds
+function parseReset(value: string | undefined, now: number): number | undefined {
+  if (!value) return undefined;
+  if (/^\d+(\.\d+)?$/.test(value)) return now + parseFloat(value) * 1000;
+  const time = Date.parse(value);
+  if (!isNaN(time)) return time;
+  const duration = parseDuration(value);
+  return duration === undefined ? undefined : now + duration;
+}
+
+function limitWindow(limit?: string, remaining?: string, reset?: string, now = Date.now()): LimitWindow | undefined {
+  const resetAt = parseReset(reset, now);
+  if (remaining === undefined || resetAt === undefined) return undefined;
+  return {
+    ...(limit !== undefined ? { limit: parseInt(limit) } : {}),
+    remaining: parseInt(remaining),
+    resetAt
+  };
+}
+
+// Reads OpenAI's x-ratelimit-* and Anthropic's anthropic-ratelimit-* headers, plus retry-after
+// from any provider
+export function parseRateLimitHeaders(headers: Record<string, any> | undefined): RateLimitInfo | undefined {
+  if (!headers) return undefined;
+  const header = (name: string): string | undefined => {
+    const value = typeof headers.get === 'function' ? headers.get(name) : headers[name];
+    return value === undefined || value === null ? undefined : String(value);
+  };
+  const now = Date.now();
+
+  const requests = limitWindow(header('x-ratelimit-limit-requests'), header('x-ratelimit-remaining-requests'), header('x-ratelimit-reset-requests'), now)
+    || limitWindow(header('anthropic-ratelimit-requests-limit'), header('anthropic-ratelimit-requests-remaining'), header('anthropic-ratelimit-requests-reset'), now);
+  const tokens = limitWindow(header('x-ratelimit-limit-tokens'), header('x-ratelimit-remaining-tokens'), header('x-ratelimit-reset-tokens'), now)
+    || limitWindow(header('anthropic-ratelimit-tokens-limit'), header('anthropic-ratelimit-tokens-remaining'), header('anthropic-ratelimit-tokens-reset'), now);
+  const organization = header('openai-organization') || header('anthropic-organization-id');
+  const retryAfter = header('retry-after-ms') !== undefined
+    ? parseFloat(header('retry-after-ms')!)
+    : (parseReset(header('retry-after'), now) ?? now) - now;
+
+  if (!requests && !tokens && !organization && !(retryAfter > 0)) return undefined;
+  return {
+    ...(organization ? { organization } : {}),
+    ...(requests ? { requests } : {}),
+    ...(tokens ? { tokens } : {}),
+    ...(retryAfter > 0 ? { retryAfterMs: Math.round(retryAfter) } : {})
+  };
+}
+
+// Finish reasons with which the providers report a response withheld by their safety systems
+const FILTERED_FINISH_REASONS = ['content_filter', 'safety', 'prohibited_content', 'blocklist', 'spii', 'refusal'];
+
+export function isFiltered(completion: ProviderCompletion): boolean {
+  return FILTERED_FINISH_REASONS.includes(completion.finishReason);
+}
+
+function failureReason(status: number | undefined, data: any): ProviderFailure | undefined {
+  if (status === 429) return 'rate_limit';
+  if (!status || status === 408 || status >= 500) return 'outage';
+  const code = `${data?.error?.code || ''} ${data?.error?.type || ''}`;
+  if (/content_filter|content_policy|safety/i.test(code)) return 'content_filter';
+  return undefined;
+}
+
+// Converts transport failures into ProviderErrors, flagging which ones are worth retrying and
+// which ones another provider might not have
+export function toProviderError(provider: ProviderName, error: unknown): ProviderError {
+  if (error instanceof ProviderError) return error;
+
+  // Cancelled by the caller; neither worth retrying nor the provider's fault
+  if (axios.isCancel(error)) {
+    return new ProviderError(`${provider}: request cancelled`, provider);
+  }
+
+  if (axios.isAxiosError(error)) {
+    const status = error.response?.status;
+    const data = error.response?.data as any;
+    const message = data?.error?.message || data?.error || error.message;
+    const retryable = !status || status === 408 || status === 429 || status >= 500;
+    return new ProviderError(`${provider}: ${message}`, provider, status, retryable, failureReason(status, data))
+      .withRateLimit(parseRateLimitHeaders(error.response?.headers));
+  }
+
+  return new ProviderError(
+    `${provider}: ${error instanceof Error ? error.message : 'Unknown error'}`,
+    provider
+  );
+}
@@ -0,0 +1,114 @@
+import axios from 'axios';
+import { ChatCompletionRequest, ProviderCompletion, ProviderEmbedding } from '../types/index.js';
+import { DeltaHandler, LLMProvider, fingerprint, parseRateLimitHeaders, sseData, toProviderError } from './LLMProvider.js';
+
+export class OpenAIProvider implements LLMProvider {
+  readonly name = 'openai' as const;
+  private baseUrl = process.env.OPENAI_BASE_URL || 'https://api.openai.com/v1';
+
+  constructor(private apiKey: string | undefined = process.env.OPENAI_API_KEY) {}
+
+  isConfigured(): boolean {
+    return !!this.apiKey;
+  }
+
+  keyId(): string {
+    return fingerprint(this.apiKey);
+  }
+
+  async complete(request: ChatCompletionRequest): Promise<ProviderCompletion> {
+    try {
+      const { data, headers } = await axios.post(`${this.baseUrl}/chat/completions`, this.body(request), {
+        headers: { Authorization: `Bearer ${this.apiKey}` },
+        timeout: 120000
+      });
+
+      const choice = data.choices?.[0];
+      return {
+        id: data.id,
+        content: choice?.message?.content || '',
+        finishReason: choice?.finish_reason || 'unknown',
+        usage: {
+          promptTokens: data.usage?.prompt_tokens || 0,
+          completionTokens: data.usage?.completion_tokens || 0,
+          totalTokens: data.usage?.total_tokens || 0
+        },
+        rateLimit: parseRateLimitHeaders(headers)
+      };
+    } catch (error) {
+      throw toProviderError(this.name, error);
+    }
+  }
+
+  async stream(request: ChatCompletionRequest, onDelta: DeltaHandler, signal?: AbortSignal): Promise<ProviderCompletion> {
+    try {
+      const response = await axios.post(`${this.baseUrl}/chat/completions`, {
+        ...this.body(request),
+        stream: true,
+        // Usage arrives in a final chunk with no choices
+        stream_options: { include_usage: true }
+      }, {
+        headers: { Authorization: `Bearer ${this.apiKey}` },
+        timeout: 120000,
+        responseType: 'stream',
+        signal
+      });
+
+      const completion: ProviderCompletion = {
+        id: '',
+        content: '',
+        finishReason: 'unknown',
+        usage: { promptTokens: 0, completionTokens: 0, totalTokens: 0 },
+        rateLimit: parseRateLimitHeaders(response.headers)
+      };
+      for await (const data of sseData(response.data)) {
+        completion.id = data.id || completion.id;
+        const choice = data.choices?.[0];
+        const delta = choice?.delta?.content;
+        if (delta) {
+          completion.content += delta;
+          onDelta(delta);
+        }
+        if (choice?.finish_reason) completion.finishReason = choice.finish_reason;
+        if (data.usage) {
+          completion.usage = {
+            promptTokens: data.usage.prompt_tokens || 0,
+            completionTokens: data.usage.completion_tokens || 0,
+            totalTokens: data.usage.total_tokens || 0
+          };
+        }
+      }
+      return completion;
+    } catch (error) {
+      throw toProviderError(this.name, error);
+    }
+  }
+
+  async embed(model: string, input: string[]): Promise<ProviderEmbedding> {
+    try {
+      const { data, headers } = await axios.post(`${this.baseUrl}/embeddings`, { model, input }, {
+        headers: { Authorization: `Bearer ${this.apiKey}` },
+        timeout: 60000
+      });
+
+      return {
+        embeddings: [...(data.data || [])]
+          .sort((a: any, b: any) => a.index - b.index)
+          .map((item: any) => item.embedding),
+        promptTokens: data.usage?.prompt_tokens,
+        rateLimit: parseRateLimitHeaders(headers)
+      };
+    } catch (error) {
+      throw toProviderError(this.name, error);
+    }
+  }
+
+  private body(request: ChatCompletionRequest) {
+    return {
+      model: request.model,
+      messages: request.messages,
+      temperature: request.temperature,
+      max_tokens: request.maxTokens
+    };
+  }
+}
@@ -0,0 +1,306 @@
+import express, { Request, Response } from 'express';
+import { body, param, query, validationResult } from 'express-validator';
+import { Budget, IBudget } from '../models/Budget.js';
+import { BudgetService } from '../services/BudgetService.js';
+import { requireAuth, requireRole, AuthenticatedRequest } from '../middleware/auth.js';
+
+const router = express.Router();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const budgetIdParam = param('id').isMongoId().withMessage('Invalid budget ID');
+
+const budgetFields = [
+  body('period').optional().isIn(['daily', 'monthly', 'total']).withMessage('Period must be daily, monthly or total'),
+  body('softThreshold').optional().isFloat({ min: 0, max: 1 }).withMessage('Soft threshold must be between 0 and 1'),
+  body('hardStop').optional().isBoolean().withMessage('hardStop must be a boolean')
+];
+
+// Adds the spend for the current period so callers don't need to know about rollover
+const withSpend = (budget: IBudget) => {
+  const spent = BudgetService.currentSpend(budget);
+  return {
+    ...budget.toObject(),
+    currentSpendUsd: spent,
+    remainingUsd: Math.max(budget.limitUsd - spent, 0),
+    overrideActive: BudgetService.overrideActive(budget)
+  };
+};
+
+// GET /api/llm/budgets - Budgets with current spend, optionally filtered by scope
+router.get('/',
+  requireAuth,
+  [
+    query('scope').optional().isIn(['project', 'provider']).withMessage('Scope must be project or provider'),
+    query('scopeId').optional().isString().withMessage('Scope ID must be a string')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const filter: any = {};
+      if (req.query.scope) filter.scope = req.query.scope;
+      if (req.query.scopeId) filter.scopeId = req.query.scopeId;
+
+      const budgets = await Budget.find(filter).sort({ scope: 1, scopeId: 1 });
+
+      res.json({
+        success: true,
+        data: budgets.map(withSpend)
+      });
+    } catch (error) {
+      console.error('Budget list error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to list budgets'
+      });
+    }
+  }
+);
+
+// GET /api/llm/budgets/:id - Budget with current spend
+router.get('/:id',
+  requireAuth,
+  [budgetIdParam],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const budget = await Budget.findById(req.params.id);
+      if (!budget) {
+        return res.status(404).json({
+          success: false,
+          error: 'Budget not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: withSpend(budget)
+      });
+    } catch (error) {
+      console.error('Budget fetch error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch budget'
+      });
+    }
+  }
+);
+
+// POST /api/llm/budgets - Create a project or provider budget (admin only)
+router.post('/',
+  requireAuth,
+  requireRole('admin'),
+  [
+    body('scope').isIn(['project', 'provider']).withMessage('Scope must be project or provider'),
+    body('scopeId').isString().isLength({ min: 1 }).withMessage('Scope ID is required'),
+    body('limitUsd').isFloat({ min: 0 }).withMessage('Limit must be a non-negative amount'),
+    ...budgetFields
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const { scope, scopeId, limitUsd, period, softThreshold, hardStop } = req.body;
+
+      if (await Budget.exists({ scope, scopeId })) {
+        return res.status(409).json({
+          success: false,
+          error: 'A budget already exists for this scope'
+        });
+      }
+
+      const budget = await Budget.create({
+        scope,
+        scopeId,
+        limitUsd,
+        period,
+        softThreshold,
+        hardStop,
+        createdBy: req.user!._id
+      });
+
+      res.status(201).json({
+        success: true,
+        data: withSpend(budget),
+        message: 'Budget created successfully'
+      });
+    } catch (error) {
+      console.error('Budget create error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to create budget'
+      });
+    }
+  }
+);
+
+// PUT /api/llm/budgets/:id - Change limit, period or thresholds (admin only)
+router.put('/:id',
+  requireAuth,
+  requireRole('admin'),
+  [
+    budgetIdParam,
+    body('limitUsd').optional().isFloat({ min: 0 }).withMessage('Limit must be a non-negative amount'),
+    ...budgetFields
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const budget = await Budget.findById(req.params.id);
+      if (!budget) {
+        return res.status(404).json({
+          success: false,
+          error: 'Budget not found'
+        });
+      }
+
+      const { limitUsd, period, softThreshold, hardStop } = req.body;
+      if (limitUsd !== undefined) budget.limitUsd = limitUsd;
+      if (softThreshold !== undefined) budget.softThreshold = softThreshold;
+      if (hardStop !== undefined) budget.hardStop = hardStop;
+      if (period !== undefined && period !== budget.period) {
+        // Spend is only meaningful within a period, so switching periods starts afresh
+        budget.period = period;
+        budget.periodStart = new Date();
+        budget.spentUsd = 0;
+        budget.warnedAt = undefined;
+      }
+      await budget.save();
+
+      res.json({
+        success: true,
+        data: withSpend(budget),
+        message: 'Budget updated successfully'
+      });
+    } catch (error) {
+      console.error('Budget update error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to update budget'
+      });
+    }
+  }
+);
+
+// DELETE /api/llm/budgets/:id - Remove a budget (admin only)
+router.delete('/:id',
+  requireAuth,
+  requireRole('admin'),
+  [budgetIdParam],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const budget = await Budget.findByIdAndDelete(req.params.id);
+      if (!budget) {
+        return res.status(404).json({
+          success: false,
+          error: 'Budget not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        message: 'Budget removed successfully'
+      });
+    } catch (error) {
+      console.error('Budget delete error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to remove budget'
+      });
+    }
+  }
+);
+
+// POST /api/llm/budgets/:id/override - Lift the hard stop until a given time (admin only)
+router.post('/:id/override',
+  requireAuth,
+  requireRole('admin'),
+  [
+    budgetIdParam,
+    body('until').isISO8601().withMessage('Override end time must be an ISO 8601 date'),
+    body('reason').isString().isLength({ min: 1, max: 500 }).withMessage('A reason is required')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const until = new Date(req.body.until);
+      if (until <= new Date()) {
+        return res.status(400).json({
+          success: false,
+          error: 'Override end time must be in the future'
+        });
+      }
+
+      const budget = await Budget.findByIdAndUpdate(req.params.id, {
+        override: {
+          until,
+          reason: req.body.reason,
+          grantedBy: req.user!._id,
+          grantedAt: new Date()
+        }
+      }, { new: true });
+      if (!budget) {
+        return res.status(404).json({
+          success: false,
+          error: 'Budget not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: withSpend(budget),
+        message: 'Budget override granted'
+      });
+    } catch (error) {
+      console.error('Budget override error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to override budget'
+      });
+    }
+  }
+);
+
+// DELETE /api/llm/budgets/:id/override - Reinstate the hard stop (admin only)
+router.delete('/:id/override',
+  requireAuth,
+  requireRole('admin'),
+  [budgetIdParam],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const budget = await Budget.findByIdAndUpdate(req.params.id, { $unset: { override: 1 } }, { new: true });
+      if (!budget) {
+        return res.status(404).json({
+          success: false,
+          error: 'Budget not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: withSpend(budget),
+        message: 'Budget override revoked'
+      });
+    } catch (error) {
+      console.error('Budget override revoke error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to revoke budget override'
+      });
+    }
+  }
+);
+
+export default router;
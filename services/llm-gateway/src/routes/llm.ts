@@ -0,0 +1,186 @@
+import express, { Request, Response } from 'express';
+import { body, validationResult } from 'express-validator';
+import { LLMGatewayService } from '../services/LLMGatewayService.js';
+import { BudgetExceededError } from '../services/BudgetService.js';
+import { countMessageTokens, promptBudget } from '@ai-pipeline/tokens';
+import { ProviderError } from '../types/index.js';
+
+const router = express.Router();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+export default function createLLMRoutes(gateway: LLMGatewayService) {
+  // POST /api/llm/chat - Unified chat completion across providers
+  router.post('/chat',
+    [
+      body('model').isString().isLength({ min: 1 }).withMessage('Model is required'),
+      body('messages').isArray({ min: 1 }).withMessage('At least one message is required'),
+      body('messages.*.role').isIn(['system', 'user', 'assistant']).withMessage('Invalid message role'),
+      body('messages.*.content').isString().withMessage('Message content must be a string'),
+      body('temperature').optional().isFloat({ min: 0, max: 2 }).withMessage('Temperature must be between 0 and 2'),
+      body('maxTokens').optional().isInt({ min: 1 }).withMessage('maxTokens must be a positive integer'),
+      body('metadata').optional().isObject().withMessage('Metadata must be an object'),
+      body('contextOverflow').optional().isIn(['truncate', 'error']).withMessage('contextOverflow must be truncate or error')
+    ],
+    validateRequest,
+    async (req: Request, res: Response) => {
+      try {
+        const completion = await gateway.chat(req.body);
+
+        res.json({
+          success: true,
+          data: completion
+        });
+      } catch (error) {
+        console.error('Chat completion error:', error);
+
+        if (error instanceof BudgetExceededError) {
+          return res.status(402).json({
+            success: false,
+            error: error.message,
+            budgetId: error.budget._id
+          });
+        }
+
+        if (error instanceof ProviderError) {
+          // Client mistakes, rate limits and missing providers pass through; other upstream
+          // failures surface as a bad gateway
+          const status = error.status === 400 || error.status === 503 ? error.status : error.reason === 'rate_limit' ? 429 : 502;
+          return res.status(status).json({
+            success: false,
+            error: error.message,
+            provider: error.provider
+          });
+        }
+
+        res.status(500).json({
+          success: false,
+          error: 'Failed to complete chat request'
+        });
+      }
+    }
+  );
+
+  // POST /api/llm/embeddings - Embed texts, one vector per input
+  router.post('/embeddings',
+    [
+      body('model').optional().isString().isLength({ min: 1 }).withMessage('Model must be a model ID or alias'),
+      body('input').isArray({ min: 1, max: 256 }).withMessage('Between 1 and 256 inputs are required'),
+      body('input.*').isString().isLength({ min: 1 }).withMessage('Inputs must be non-empty strings'),
+      body('metadata').optional().isObject().withMessage('Metadata must be an object')
+    ],
+    validateRequest,
+    async (req: Request, res: Response) => {
+      try {
+        const result = await gateway.embed({
+          model: req.body.model || process.env.LLM_EMBEDDING_MODEL || 'embeddings',
+          input: req.body.input,
+          metadata: req.body.metadata
+        });
+
+        res.json({
+          success: true,
+          data: result
+        });
+      } catch (error) {
+        console.error('Embedding error:', error);
+
+        if (error instanceof BudgetExceededError) {
+          return res.status(402).json({
+            success: false,
+            error: error.message,
+            budgetId: error.budget._id
+          });
+        }
+
+        if (error instanceof ProviderError) {
+          const status = error.status === 400 || error.status === 503 ? error.status : error.reason === 'rate_limit' ? 429 : 502;
+          return res.status(status).json({
+            success: false,
+            error: error.message,
+            provider: error.provider
+          });
+        }
+
+        res.status(500).json({
+          success: false,
+          error: 'Failed to embed input'
+        });
+      }
+    }
+  );
+
+  // POST /api/llm/tokens - Count a prompt's tokens against the model's context window
+  router.post('/tokens',
+    [
+      body('model').isString().isLength({ min: 1 }).withMessage('Model is required'),
+      body('messages').isArray({ min: 1 }).withMessage('At least one message is required'),
+      body('messages.*.content').isString().withMessage('Message content must be a string'),
+      body('maxTokens').optional().isInt({ min: 1 }).withMessage('maxTokens must be a positive integer')
+    ],
+    validateRequest,
+    (req: Request, res: Response) => {
+      try {
+        const resolved = gateway.resolveModel(req.body.model);
+        if (!resolved) {
+          return res.status(400).json({
+            success: false,
+            error: `Unsupported model: ${req.body.model}`
+          });
+        }
+
+        const { id: model, spec } = resolved;
+        const maxOutputTokens = Math.min(req.body.maxTokens || spec.maxOutputTokens, spec.maxOutputTokens);
+        const promptTokens = countMessageTokens(req.body.messages, model);
+        const budget = promptBudget({ contextWindow: spec.contextWindow, maxOutputTokens });
+
+        res.json({
+          success: true,
+          data: {
+            model,
+            promptTokens,
+            contextWindow: spec.contextWindow,
+            maxOutputTokens,
+            promptBudget: budget,
+            fits: promptTokens <= budget
+          }
+        });
+      } catch (error) {
+        console.error('Token count error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to count tokens'
+        });
+      }
+    }
+  );
+
+  // GET /api/llm/rate-limits - Provider capacity per API key and organization, and queued requests
+  router.get('/rate-limits', (req: Request, res: Response) => {
+    res.json({
+      success: true,
+      data: gateway.rateLimits()
+    });
+  });
+
+  // GET /api/llm/providers - Provider configuration status
+  router.get('/providers', (req: Request, res: Response) => {
+    res.json({
+      success: true,
+      data: gateway.listProviders()
+    });
+  });
+
+  return router;
+}
@@ -0,0 +1,202 @@
+import express, { Request, Response } from 'express';
+import { body, query, validationResult } from 'express-validator';
+import { ModelDefinition } from '../models/ModelDefinition.js';
+import { ModelRegistry } from '../services/ModelRegistry.js';
+import { LLMGatewayService } from '../services/LLMGatewayService.js';
+import { requireAuth, requireRole } from '../middleware/auth.js';
+
+const router = express.Router();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const modelFields = (optional: boolean) => {
+  const field = (name: string) => optional ? body(name).optional() : body(name);
+  return [
+    field('provider').isIn(['openai', 'anthropic', 'gemini']).withMessage('Provider must be openai, anthropic or gemini'),
+    field('contextWindow').isInt({ min: 1 }).withMessage('Context window must be a positive integer'),
+    field('maxOutputTokens').isInt({ min: 1 }).withMessage('maxOutputTokens must be a positive integer'),
+    field('inputPricePerMillion').isFloat({ min: 0 }).withMessage('Input price must be a non-negative number'),
+    field('outputPricePerMillion').isFloat({ min: 0 }).withMessage('Output price must be a non-negative number'),
+    body('capabilities').optional().isArray().withMessage('Capabilities must be an array'),
+    body('aliases').optional().isArray().withMessage('Aliases must be an array'),
+    body('priority').optional().isInt().withMessage('Priority must be an integer'),
+    body('enabled').optional().isBoolean().withMessage('Enabled must be a boolean'),
+    body('deprecatedAt').optional({ nullable: true }).isISO8601().withMessage('Deprecation date must be an ISO 8601 date')
+  ];
+};
+
+const EDITABLE_FIELDS = [
+  'provider', 'contextWindow', 'maxOutputTokens', 'inputPricePerMillion', 'outputPricePerMillion',
+  'capabilities', 'aliases', 'priority', 'enabled', 'deprecatedAt'
+];
+
+const pickFields = (source: any) =>
+  Object.fromEntries(EDITABLE_FIELDS.filter(key => source[key] !== undefined).map(key => [key, source[key]]));
+
+export default function createModelRoutes(registry: ModelRegistry, gateway: LLMGatewayService) {
+  // GET /api/llm/models - Registered models with limits, pricing and aliases
+  router.get('/',
+    [query('includeDisabled').optional().isBoolean().withMessage('includeDisabled must be a boolean')],
+    validateRequest,
+    (req: Request, res: Response) => {
+      res.json({
+        success: true,
+        data: registry.list(req.query.includeDisabled === 'true')
+      });
+    }
+  );
+
+  // GET /api/llm/models/resolve/:name - Resolve a model ID or alias to the model that would be called
+  router.get('/resolve/:name', (req: Request, res: Response) => {
+    const resolved = gateway.resolveModel(req.params.name);
+    if (!resolved) {
+      return res.status(404).json({
+        success: false,
+        error: `No usable model registered for "${req.params.name}"`
+      });
+    }
+
+    res.json({
+      success: true,
+      data: { id: resolved.id, ...resolved.spec }
+    });
+  });
+
+  // GET /api/llm/models/:modelId - Registry entry
+  router.get('/:modelId', async (req: Request, res: Response) => {
+    try {
+      const model = await ModelDefinition.findOne({ modelId: req.params.modelId });
+      if (!model) {
+        return res.status(404).json({
+          success: false,
+          error: 'Model not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: model
+      });
+    } catch (error) {
+      console.error('Model fetch error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch model'
+      });
+    }
+  });
+
+  // POST /api/llm/models - Register a model (admin only)
+  router.post('/',
+    requireAuth,
+    requireRole('admin'),
+    [
+      body('modelId').isString().isLength({ min: 1 }).withMessage('Model ID is required'),
+      ...modelFields(false)
+    ],
+    validateRequest,
+    async (req: Request, res: Response) => {
+      try {
+        if (await ModelDefinition.exists({ modelId: req.body.modelId })) {
+          return res.status(409).json({
+            success: false,
+            error: 'Model is already registered'
+          });
+        }
+
+        const model = await ModelDefinition.create({ modelId: req.body.modelId, ...pickFields(req.body) });
+        await registry.refresh();
+
+        res.status(201).json({
+          success: true,
+          data: model,
+          message: 'Model registered successfully'
+        });
+      } catch (error) {
+        console.error('Model create error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to register model'
+        });
+      }
+    }
+  );
+
+  // PUT /api/llm/models/:modelId - Update limits, pricing, aliases or deprecation (admin only)
+  router.put('/:modelId',
+    requireAuth,
+    requireRole('admin'),
+    modelFields(true),
+    validateRequest,
+    async (req: Request, res: Response) => {
+      try {
+        const model = await ModelDefinition.findOneAndUpdate(
+          { modelId: req.params.modelId },
+          pickFields(req.body),
+          { new: true, runValidators: true }
+        );
+        if (!model) {
+          return res.status(404).json({
+            success: false,
+            error: 'Model not found'
+          });
+        }
+        await registry.refresh();
+
+        res.json({
+          success: true,
+          data: model,
+          message: 'Model updated successfully'
+        });
+      } catch (error) {
+        console.error('Model update error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to update model'
+        });
+      }
+    }
+  );
+
+  // DELETE /api/llm/models/:modelId - Remove a model from the registry (admin only)
+  router.delete('/:modelId',
+    requireAuth,
+    requireRole('admin'),
+    async (req: Request, res: Response) => {
+      try {
+        const result = await ModelDefinition.deleteOne({ modelId: req.params.modelId });
+        if (result.deletedCount === 0) {
+          return res.status(404).json({
+            success: false,
+            error: 'Model not found'
+          });
+        }
+        await registry.refresh();
+
+        res.json({
+          success: true,
+          message: 'Model removed successfully'
+        });
+      } catch (error) {
+        console.error('Model delete error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to remove model'
+        });
+      }
+    }
+  );
+
+  return router;
+}
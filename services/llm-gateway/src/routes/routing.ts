@@ -0,0 +1,185 @@
+import express, { Request, Response } from 'express';
+import { body, param, query, validationResult } from 'express-validator';
+import { RoutingPolicy } from '../models/RoutingPolicy.js';
+import { FAILURES, RoutingService } from '../services/RoutingService.js';
+import { LLMGatewayService } from '../services/LLMGatewayService.js';
+import { requireAuth, AuthenticatedRequest } from '../middleware/auth.js';
+
+const router = express.Router();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+export default function createRoutingRoutes(routing: RoutingService, gateway: LLMGatewayService) {
+  // GET /api/llm/routing/policies - Routing policies, optionally one project's overrides
+  router.get('/policies',
+    requireAuth,
+    [query('projectId').optional().isString().withMessage('Project ID must be a string')],
+    validateRequest,
+    async (req: Request, res: Response) => {
+      try {
+        const filter: any = {};
+        if (req.query.projectId) filter.projectId = req.query.projectId;
+
+        const policies = await RoutingPolicy.find(filter).sort({ projectId: 1, model: 1 });
+
+        res.json({
+          success: true,
+          data: policies
+        });
+      } catch (error) {
+        console.error('Routing policy list error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to list routing policies'
+        });
+      }
+    }
+  );
+
+  // PUT /api/llm/routing/policies - Create or replace the policy for a model, globally (admin only)
+  // or as a project's override
+  router.put('/policies',
+    requireAuth,
+    [
+      body('model').isString().isLength({ min: 1 }).withMessage('Model (ID, alias or *) is required'),
+      body('projectId').optional().isString().isLength({ min: 1 }).withMessage('Project ID must be a string'),
+      body('primary').optional().isString().isLength({ min: 1 }).withMessage('Primary must be a model ID or alias'),
+      body('fallbacks').optional().isArray().withMessage('Fallbacks must be a list of models'),
+      body('fallbacks.*').isString().isLength({ min: 1 }).withMessage('Fallbacks must be model IDs or aliases'),
+      body('fallbackOn').optional().isArray().withMessage('fallbackOn must be a list'),
+      body('fallbackOn.*').isIn(FAILURES).withMessage(`fallbackOn entries must be one of ${FAILURES.join(', ')}`),
+      body('healthWeighted').optional().isBoolean().withMessage('healthWeighted must be a boolean'),
+      body('enabled').optional().isBoolean().withMessage('Enabled must be a boolean')
+    ],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const { model, projectId, primary, fallbacks, fallbackOn, healthWeighted, enabled } = req.body;
+
+        if (!projectId && req.user!.role !== 'admin') {
+          return res.status(403).json({
+            success: false,
+            error: 'Admin access required to change global routing'
+          });
+        }
+
+        const unknown = [primary, ...(fallbacks || [])]
+          .filter(name => name && !gateway.resolveModel(name));
+        if (unknown.length > 0) {
+          return res.status(400).json({
+            success: false,
+            error: `No usable model registered for: ${unknown.join(', ')}`
+          });
+        }
+
+        // Filter fields are copied into a new document, so a project override gets its projectId
+        const policy = await RoutingPolicy.findOneAndUpdate(
+          { projectId: projectId || { $exists: false }, model },
+          {
+            $set: {
+              fallbacks: fallbacks || [],
+              fallbackOn: fallbackOn || [],
+              healthWeighted: healthWeighted ?? true,
+              enabled: enabled ?? true,
+              updatedBy: req.user!._id,
+              ...(primary ? { primary } : {})
+            },
+            ...(primary ? {} : { $unset: { primary: 1 } })
+          },
+          { new: true, upsert: true, runValidators: true }
+        );
+        await routing.refresh();
+
+        res.json({
+          success: true,
+          data: policy,
+          message: 'Routing policy saved successfully'
+        });
+      } catch (error) {
+        console.error('Routing policy save error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to save routing policy'
+        });
+      }
+    }
+  );
+
+  // DELETE /api/llm/routing/policies/:id - Remove a policy (admins for global ones)
+  router.delete('/policies/:id',
+    requireAuth,
+    [param('id').isMongoId().withMessage('Invalid policy ID')],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const policy = await RoutingPolicy.findById(req.params.id);
+        if (!policy) {
+          return res.status(404).json({
+            success: false,
+            error: 'Routing policy not found'
+          });
+        }
+        if (!policy.projectId && req.user!.role !== 'admin') {
+          return res.status(403).json({
+            success: false,
+            error: 'Admin access required to change global routing'
+          });
+        }
+
+        await policy.deleteOne();
+        await routing.refresh();
+
+        res.json({
+          success: true,
+          message: 'Routing policy removed successfully'
+        });
+      } catch (error) {
+        console.error('Routing policy delete error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to remove routing policy'
+        });
+      }
+    }
+  );
+
+  // GET /api/llm/routing/resolve?model=&projectId= - The models a request would be tried on, in order
+  router.get('/resolve',
+    [
+      query('model').isString().isLength({ min: 1 }).withMessage('Model is required'),
+      query('projectId').optional().isString().withMessage('Project ID must be a string')
+    ],
+    validateRequest,
+    (req: Request, res: Response) => {
+      const plan = gateway.planRoute(String(req.query.model), req.query.projectId ? String(req.query.projectId) : undefined);
+      const providers = new Map(gateway.listProviders().map(provider => [provider.name, provider]));
+
+      res.json({
+        success: true,
+        data: {
+          policy: plan.policy ? String(plan.policy._id) : undefined,
+          fallbackOn: plan.fallbackOn,
+          candidates: plan.candidates.map(candidate => ({
+            model: candidate.id,
+            provider: candidate.spec.provider,
+            configured: providers.get(candidate.spec.provider)?.configured ?? false,
+            health: providers.get(candidate.spec.provider)?.health
+          }))
+        }
+      });
+    }
+  );
+
+  return router;
+}
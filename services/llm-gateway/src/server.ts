@@ -0,0 +1,142 @@
+import express from 'express';
+import cors from 'cors';
+import mongoose from 'mongoose';
+import { config } from 'dotenv';
+import winston from 'winston';
+import { LLMGatewayService } from './services/LLMGatewayService.js';
+import { OpenAIProvider } from './providers/OpenAIProvider.js';
+import { AnthropicProvider } from './providers/AnthropicProvider.js';
+import { GeminiProvider } from './providers/GeminiProvider.js';
+import { ModelRegistry } from './services/ModelRegistry.js';
+import { BudgetService } from './services/BudgetService.js';
+import { ProviderHealth } from './services/ProviderHealth.js';
+import { RoutingService } from './services/RoutingService.js';
+import createLLMRoutes from './routes/llm.js';
+import createModelRoutes from './routes/models.js';
+import budgetRoutes from './routes/budgets.js';
+import createRoutingRoutes from './routes/routing.js';
+import { LLMGrpcServer } from './grpc/LLMGrpcServer.js';
+
+// Load environment variables
+config();
+
+// Configure logger
+const logger = winston.createLogger({
+  level: process.env.LOG_LEVEL || 'info',
+  format: winston.format.combine(
+    winston.format.timestamp(),
+    winston.format.errors({ stack: true }),
+    winston.format.json()
+  ),
+  transports: [
+    new winston.transports.Console({
+      format: winston.format.simple()
+    })
+  ]
+});
+
+const app = express();
+
+// Middleware
+app.use(cors({
+  origin: process.env.FRONTEND_URL || "http://localhost:5173",
+  credentials: true
+}));
+
+app.use(express.json({ limit: '20mb' }));
+app.use(express.urlencoded({ extended: true }));
+
+// Request logging
+app.use((req, res, next) => {
+  logger.info(`${req.method} ${req.path}`, {
+    ip: req.ip,
+    userAgent: req.get('User-Agent')
+  });
+  next();
+});
+
+// Initialize model registry, budgets, routing and LLM gateway
+const registry = new ModelRegistry();
+const budgets = new BudgetService();
+const health = new ProviderHealth();
+const routing = new RoutingService(registry, health);
+const gateway = new LLMGatewayService([
+  new OpenAIProvider(),
+  new AnthropicProvider(),
+  new GeminiProvider()
+], registry, budgets, routing, health);
+
+// Database connection (model registry, budgets and routing policies)
+const MONGODB_URI = process.env.MONGODB_URI || 'mongodb://localhost:27017/ai-pipeline';
+
+mongoose.connect(MONGODB_URI)
+  .then(async () => {
+    logger.info('🗄️  Connected to MongoDB');
+    await registry.initialize();
+    logger.info(`📚 Model registry loaded: ${registry.list().length} models`);
+    await routing.initialize();
+  })
+  .catch((error) => {
+    logger.error('❌ Model registry initialization error:', error);
+    process.exit(1);
+  });
+
+// Health check endpoint
+app.get('/health', (req, res) => {
+  res.json({
+    status: 'ok',
+    service: 'llm-gateway',
+    timestamp: new Date().toISOString(),
+    version: '1.0.0',
+    providers: gateway.listProviders(),
+    database: mongoose.connection.readyState === 1 ? 'connected' : 'disconnected'
+  });
+});
+
+// Routes
+app.use('/api/llm/models', createModelRoutes(registry, gateway));
+app.use('/api/llm/budgets', budgetRoutes);
+app.use('/api/llm/routing', createRoutingRoutes(routing, gateway));
+app.use('/api/llm', createLLMRoutes(gateway));
+
+// Error handling middleware
+app.use((err: Error, req: express.Request, res: express.Response, next: express.NextFunction) => {
+  logger.error('Unhandled error:', err);
+  res.status(500).json({
+    success: false,
+    error: 'Internal server error'
+  });
+});
+
+// 404 handler
+app.use('*', (req, res) => {
+  res.status(404).json({
+    success: false,
+    error: 'Route not found'
+  });
+});
+
+const PORT = process.env.PORT || 3006;
+const GRPC_PORT = process.env.LLM_GRPC_PORT || 50056;
+
+// Streaming completions (proto/llm_gateway.proto)
+const grpcServer = new LLMGrpcServer(gateway);
+
+// Graceful shutdown
+process.on('SIGTERM', () => {
+  logger.info('SIGTERM received. Shutting down gracefully...');
+  registry.stop();
+  routing.stop();
+  grpcServer.stop();
+  mongoose.connection.close();
+  process.exit(0);
+});
+
+app.listen(PORT, () => {
+  logger.info(`🧠 LLM Gateway running on port ${PORT}`);
+  logger.info(`🔌 Configured providers: ${gateway.listProviders().filter(p => p.configured).map(p => p.name).join(', ') || 'none'}`);
+});
+
+grpcServer.start(GRPC_PORT)
+  .then(port => logger.info(`📡 LLM Gateway gRPC streaming on port ${port}`))
+  .catch(error => logger.error('❌ Failed to start gRPC server:', error));
@@ -0,0 +1,123 @@
+import axios from 'axios';
+import { randomUUID } from 'crypto';
+import { Budget, BudgetPeriod, IBudget } from '../models/Budget.js';
+import { ProviderName } from '../types/index.js';
+
+export interface SpendContext {
+  projectId?: string;
+  provider: ProviderName;
+}
+
+export class BudgetExceededError extends Error {
+  constructor(public budget: IBudget) {
+    super(`${budget.scope} budget for ${budget.scopeId} exhausted: $${budget.spentUsd.toFixed(2)} of $${budget.limitUsd.toFixed(2)} (${budget.period})`);
+    this.name = 'BudgetExceededError';
+  }
+}
+
+// Checks spend budgets before each call and records cost afterwards. Spend is tracked on the
+// budget itself and reset when its period rolls over.
+export class BudgetService {
+  private notificationServiceUrl?: string;
+
+  constructor() {
+    this.notificationServiceUrl = process.env.NOTIFICATION_SERVICE_URL;
+  }
+
+  static periodStart(budget: IBudget, now: Date = new Date()): Date {
+    const starts: Record<BudgetPeriod, () => Date> = {
+      daily: () => new Date(Date.UTC(now.getUTCFullYear(), now.getUTCMonth(), now.getUTCDate())),
+      monthly: () => new Date(Date.UTC(now.getUTCFullYear(), now.getUTCMonth(), 1)),
+      total: () => budget.periodStart
+    };
+    return starts[budget.period]();
+  }
+
+  static currentSpend(budget: IBudget, now: Date = new Date()): number {
+    return budget.periodStart < BudgetService.periodStart(budget, now) ? 0 : budget.spentUsd;
+  }
+
+  static overrideActive(budget: IBudget, now: Date = new Date()): boolean {
+    return !!budget.override?.until && budget.override.until > now;
+  }
+
+  // Throws when a hard-stop budget is exhausted; returns warnings for budgets past their soft threshold
+  async check(context: SpendContext): Promise<string[]> {
+    const warnings: string[] = [];
+
+    for (const budget of await this.applicable(context)) {
+      const spent = BudgetService.currentSpend(budget);
+
+      if (spent >= budget.limitUsd) {
+        if (budget.hardStop && !BudgetService.overrideActive(budget)) {
+          throw new BudgetExceededError(budget);
+        }
+        warnings.push(`${budget.scope} budget for ${budget.scopeId} is over its $${budget.limitUsd.toFixed(2)} ${budget.period} limit`);
+      } else if (spent >= budget.limitUsd * budget.softThreshold) {
+        warnings.push(`${budget.scope} budget for ${budget.scopeId} at ${Math.round(spent / budget.limitUsd * 100)}% of $${budget.limitUsd.toFixed(2)} ${budget.period} limit`);
+      }
+    }
+
+    return warnings;
+  }
+
+  async record(context: SpendContext, costUsd: number): Promise<void> {
+    if (!costUsd) return;
+
+    for (const budget of await this.applicable(context)) {
+      const start = BudgetService.periodStart(budget);
+
+      await Budget.updateOne(
+        { _id: budget._id, periodStart: { $lt: start } },
+        { $set: { periodStart: start, spentUsd: 0 }, $unset: { warnedAt: 1 } }
+      );
+      const updated = await Budget.findByIdAndUpdate(budget._id, { $inc: { spentUsd: costUsd } }, { new: true });
+
+      if (updated && updated.spentUsd >= updated.limitUsd * updated.softThreshold) {
+        await this.warnOnce(updated, start);
+      }
+    }
+  }
+
+  private async applicable(context: SpendContext): Promise<IBudget[]> {
+    const scopes: Array<{ scope: string; scopeId: string }> = [{ scope: 'provider', scopeId: context.provider }];
+    if (context.projectId) {
+      scopes.push({ scope: 'project', scopeId: context.projectId });
+    }
+    return Budget.find({ $or: scopes });
+  }
+
+  // Notifies once per period when spend crosses the soft threshold
+  private async warnOnce(budget: IBudget, periodStart: Date): Promise<void> {
+    const claimed = await Budget.findOneAndUpdate(
+      { _id: budget._id, $or: [{ warnedAt: { $exists: false } }, { warnedAt: { $lt: periodStart } }] },
+      { $set: { warnedAt: new Date() } }
+    );
+    if (!claimed || !this.notificationServiceUrl) return;
+
+    axios.post(`${this.notificationServiceUrl}/api/notifications/events`, {
+      specversion: '1.0',
+      id: randomUUID(),
+      type: 'budget.warning',
+      source: '/llm-gateway',
+      time: new Date().toISOString(),
+      subject: String(budget._id),
+      datacontenttype: 'application/json',
+      projectid: budget.scope === 'project' ? budget.scopeId : undefined,
+      data: {
+        scope: budget.scope,
+        scopeId: budget.scopeId,
+        period: budget.period,
+        spentUsd: budget.spentUsd.toFixed(2),
+        limitUsd: budget.limitUsd.toFixed(2),
+        hardStop: budget.hardStop
+      }
+    }, {
+      headers: {
+        'Content-Type': 'application/cloudevents+json',
+        'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || ''
+      },
+      timeout: 5000
+    }).catch(error => console.error('Failed to publish budget.warning event:', error.message));
+  }
+}
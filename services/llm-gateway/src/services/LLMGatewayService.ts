@@ -0,0 +1,349 @@
+import { countMessageTokens, countTokens, fitMessages, promptBudget } from '@ai-pipeline/tokens';
+import { DeltaHandler, LLMProvider, isFiltered } from '../providers/LLMProvider.js';
+import { ModelRegistry } from './ModelRegistry.js';
+import { BudgetService } from './BudgetService.js';
+import { ProviderHealth, ProviderHealthSnapshot } from './ProviderHealth.js';
+import { RoutePlan, RoutingService } from './RoutingService.js';
+import { BucketSnapshot, RateLimitScheduler } from './RateLimitScheduler.js';
+import {
+  ChatCompletionRequest,
+  ChatCompletionResponse,
+  ChatMessage,
+  ContextTruncation,
+  EmbeddingRequest,
+  EmbeddingResponse,
+  LLMUsage,
+  ModelSpec,
+  ProviderCompletion,
+  ProviderError,
+  ProviderFailure,
+  ProviderName,
+  ResolvedModel,
+  RouteAttempt
+} from '../types/index.js';
+
+interface RetryOptions {
+  maxRetries: number;
+  baseDelay: number;
+  maxDelay: number;
+}
+
+// Least output kept in reserve when an unspecified maxTokens gives way to a long prompt
+const MIN_OUTPUT_TOKENS = parseInt(process.env.LLM_MIN_OUTPUT_TOKENS || '1024');
+
+export class LLMGatewayService {
+  private providers: Map<ProviderName, LLMProvider> = new Map();
+  private retryOptions: RetryOptions;
+
+  constructor(
+    providers: LLMProvider[],
+    private registry: ModelRegistry,
+    private budgets: BudgetService,
+    private routing: RoutingService,
+    private health: ProviderHealth,
+    private limits: RateLimitScheduler = new RateLimitScheduler(),
+    retryOptions?: Partial<RetryOptions>
+  ) {
+    providers.forEach(provider => this.providers.set(provider.name, provider));
+    this.retryOptions = {
+      maxRetries: parseInt(process.env.LLM_MAX_RETRIES || '3'),
+      baseDelay: 1000,
+      maxDelay: 15000,
+      ...retryOptions
+    };
+  }
+
+  listProviders(): Array<{ name: ProviderName; configured: boolean; health: ProviderHealthSnapshot }> {
+    return Array.from(this.providers.values()).map(provider => ({
+      name: provider.name,
+      configured: provider.isConfigured(),
+      health: this.health.snapshot(provider.name)
+    }));
+  }
+
+  // Model IDs and aliases are resolved through the registry; unregistered models are refused
+  resolveModel(name: string): ResolvedModel | undefined {
+    return this.registry.resolve(name, providerName => this.isConfigured(providerName));
+  }
+
+  // Provider capacity as last reported, and how many requests are waiting for it
+  rateLimits(): BucketSnapshot[] {
+    return this.limits.snapshot();
+  }
+
+  // The models a request would be tried on, in order
+  planRoute(model: string, projectId?: string): RoutePlan {
+    return this.routing.plan(model, projectId, providerName => this.isConfigured(providerName));
+  }
+
+  async chat(request: ChatCompletionRequest): Promise<ChatCompletionResponse> {
+    return this.complete(request, (provider, normalized) => provider.complete(normalized));
+  }
+
+  // Embeds the inputs with the model, or the first embedding model under the alias whose provider is
+  // configured. Budgets, rate limits and provider health apply as for chat; there is no fallback.
+  async embed(request: EmbeddingRequest): Promise<EmbeddingResponse> {
+    const candidates = this.registry.resolveAll(request.model, provider => this.isConfigured(provider))
+      .filter(candidate => candidate.spec.capabilities?.includes('embeddings'));
+    if (candidates.length === 0) {
+      throw new ProviderError(`Unsupported embedding model: ${request.model}`, 'openai', 400);
+    }
+
+    const { id: model, spec } = candidates[0];
+    const providerName = spec.provider;
+    const provider = this.providers.get(providerName);
+    if (!this.isConfigured(providerName) || !provider?.embed) {
+      throw new ProviderError(`Provider ${providerName} is not configured`, providerName, 503);
+    }
+
+    const spendContext = { projectId: request.metadata?.projectId, provider: providerName };
+    const budgetWarnings = await this.budgets.check(spendContext);
+
+    const tokens = request.input.reduce((sum, text) => sum + countTokens(text, model), 0);
+    const keyId = provider.keyId();
+    await this.limits.acquire(providerName, keyId, tokens);
+
+    try {
+      const result = await provider.embed(model, request.input);
+      this.limits.observe(providerName, keyId, result.rateLimit);
+      this.health.recordSuccess(providerName);
+
+      const promptTokens = result.promptTokens ?? tokens;
+      const usage = this.withCost({ promptTokens, completionTokens: 0, totalTokens: promptTokens }, spec);
+      await this.budgets.record(spendContext, usage.costUsd || 0)
+        .catch(error => console.error('Failed to record LLM spend:', error));
+
+      return {
+        provider: providerName,
+        model,
+        requestedModel: model !== request.model ? request.model : undefined,
+        embeddings: result.embeddings,
+        dimensions: result.embeddings[0]?.length || 0,
+        usage,
+        budgetWarnings: budgetWarnings.length > 0 ? budgetWarnings : undefined
+      };
+    } catch (error) {
+      if (error instanceof ProviderError && error.status !== undefined) {
+        this.limits.observe(providerName, keyId, error.rateLimit, error.status === 429);
+      }
+      if (error instanceof ProviderError && error.reason) {
+        this.health.recordFailure(providerName, error.reason, error.message);
+      }
+      throw error;
+    }
+  }
+
+  // Like chat, with the text passed to onDelta as it is generated. A failure before any text has
+  // been sent is retried as usual; after that the partial output cannot be taken back, so the
+  // error is returned. Aborting the signal cancels the provider request.
+  async streamChat(request: ChatCompletionRequest, onDelta: DeltaHandler, signal?: AbortSignal): Promise<ChatCompletionResponse> {
+    let streamed = false;
+    return this.complete(
+      request,
+      (provider, normalized) => provider.stream(normalized, text => {
+        streamed = true;
+        onDelta(text);
+      }, signal),
+      () => !streamed && !signal?.aborted,
+      signal
+    );
+  }
+
+  // Works through the request's routing chain. A failure the policy falls back on moves to the
+  // next model straight away rather than retrying the failing provider; other failures, and any
+  // failure on the last model, are retried with backoff as before.
+  private async complete(
+    request: ChatCompletionRequest,
+    call: (provider: LLMProvider, normalized: ChatCompletionRequest) => Promise<ProviderCompletion>,
+    canRetry: () => boolean = () => true,
+    signal?: AbortSignal
+  ): Promise<ChatCompletionResponse> {
+    const plan = this.planRoute(request.model, request.metadata?.projectId);
+    if (plan.candidates.length === 0) {
+      throw new ProviderError(`Unsupported model: ${request.model}`, 'openai', 400);
+    }
+
+    const candidates = plan.candidates.filter(candidate => this.isConfigured(candidate.spec.provider));
+    if (candidates.length === 0) {
+      const providerName = plan.candidates[0].spec.provider;
+      throw new ProviderError(`Provider ${providerName} is not configured`, providerName, 503);
+    }
+
+    const startTime = Date.now();
+    const fallbacks: RouteAttempt[] = [];
+    let attempts = 0;
+
+    for (let index = 0; ; index++) {
+      const candidate = candidates[index];
+      const fallbackOn = index < candidates.length - 1 ? plan.fallbackOn : [];
+
+      try {
+        const completion = await this.attempt(request, candidate, call, canRetry, fallbackOn, () => attempts++, signal);
+        return {
+          ...completion,
+          latencyMs: Date.now() - startTime,
+          attempts,
+          ...(plan.policy ? { routingPolicy: String(plan.policy._id) } : {}),
+          ...(fallbacks.length > 0 ? { fallbacks } : {})
+        };
+      } catch (error) {
+        const reason = error instanceof ProviderError ? error.reason : undefined;
+        if (!reason || !fallbackOn.includes(reason) || !canRetry()) throw error;
+
+        const next = candidates[index + 1];
+        console.warn(`Falling back from ${candidate.id} to ${next.id} (${reason}): ${(error as Error).message}`);
+        fallbacks.push({ model: candidate.id, provider: candidate.spec.provider, reason, error: (error as Error).message });
+      }
+    }
+  }
+
+  // Calls one model, retrying transient failures unless they are among failFastOn, which the
+  // caller handles by falling back
+  private async attempt(
+    request: ChatCompletionRequest,
+    resolved: ResolvedModel,
+    call: (provider: LLMProvider, normalized: ChatCompletionRequest) => Promise<ProviderCompletion>,
+    canRetry: () => boolean,
+    failFastOn: ProviderFailure[],
+    onCall: () => void,
+    signal?: AbortSignal
+  ): Promise<ChatCompletionResponse> {
+    const { id: model, spec } = resolved;
+    const providerName = spec.provider;
+    const provider = this.providers.get(providerName)!;
+
+    // Runaway loops are stopped here, before any spend
+    const spendContext = { projectId: request.metadata?.projectId, provider: providerName };
+    const budgetWarnings = await this.budgets.check(spendContext);
+
+    const context = this.fitContext(request, model, spec);
+    const normalized: ChatCompletionRequest = {
+      ...request,
+      model,
+      messages: context.messages,
+      maxTokens: context.maxTokens
+    };
+
+    const keyId = provider.keyId();
+    let attempt = 0;
+
+    while (true) {
+      // Waits for capacity rather than sending a request the provider would refuse with a 429. When
+      // none is due soon this throws a rate_limit error, which falls back like a provider's would.
+      await this.limits.acquire(providerName, keyId, context.promptTokens + context.maxTokens, signal);
+
+      try {
+        onCall();
+        const completion = await call(provider, normalized);
+        this.limits.observe(providerName, keyId, completion.rateLimit);
+        this.health.recordSuccess(providerName);
+
+        // A refusal is worth trying elsewhere only while nothing has been streamed
+        if (isFiltered(completion) && failFastOn.includes('content_filter') && canRetry()) {
+          throw new ProviderError(`${providerName}: response withheld by content filter (${completion.finishReason})`,
+            providerName, undefined, false, 'content_filter');
+        }
+
+        const usage = this.withCost(completion.usage, spec);
+        await this.budgets.record(spendContext, usage.costUsd || 0)
+          .catch(error => console.error('Failed to record LLM spend:', error));
+
+        return {
+          id: completion.id,
+          provider: providerName,
+          model,
+          requestedModel: model !== request.model ? request.model : undefined,
+          content: completion.content,
+          finishReason: completion.finishReason,
+          usage,
+          budgetWarnings: budgetWarnings.length > 0 ? budgetWarnings : undefined,
+          contextTruncation: context.truncation
+        };
+      } catch (error) {
+        if (error instanceof ProviderError && error.status !== undefined) {
+          this.limits.observe(providerName, keyId, error.rateLimit, error.status === 429);
+        }
+        if (error instanceof ProviderError && error.reason) {
+          this.health.recordFailure(providerName, error.reason, error.message);
+        }
+
+        const failFast = error instanceof ProviderError && !!error.reason && failFastOn.includes(error.reason);
+        const retryable = error instanceof ProviderError && error.retryable;
+        if (failFast || !retryable || !canRetry() || attempt >= this.retryOptions.maxRetries) {
+          throw error;
+        }
+
+        await this.delay(this.calculateRetryDelay(attempt));
+        attempt++;
+      }
+    }
+  }
+
+  // Sizes the request to the model's context window so it does not fail at the provider with a
+  // context-length error. Without an explicit maxTokens the reply reservation shrinks first, down
+  // to MIN_OUTPUT_TOKENS; a prompt that still does not fit loses its oldest turns and then has its
+  // largest messages cut, or is refused when the caller asked for contextOverflow 'error'.
+  private fitContext(
+    request: ChatCompletionRequest,
+    model: string,
+    spec: ModelSpec
+  ): { messages: ChatMessage[]; maxTokens: number; promptTokens: number; truncation?: ContextTruncation } {
+    const promptTokens = countMessageTokens(request.messages, model);
+    let maxTokens = Math.min(request.maxTokens || spec.maxOutputTokens, spec.maxOutputTokens);
+
+    if (!request.maxTokens) {
+      const room = promptBudget({ contextWindow: spec.contextWindow, maxOutputTokens: 0 }) - promptTokens;
+      maxTokens = Math.max(Math.min(maxTokens, room), Math.min(MIN_OUTPUT_TOKENS, spec.maxOutputTokens));
+    }
+
+    const budget = promptBudget({ contextWindow: spec.contextWindow, maxOutputTokens: maxTokens });
+    if (promptTokens <= budget) {
+      return { messages: request.messages, maxTokens, promptTokens };
+    }
+
+    if (request.contextOverflow === 'error') {
+      throw new ProviderError(
+        `Prompt is about ${promptTokens} tokens; ${model} accepts ${budget} with ${maxTokens} reserved for output`,
+        spec.provider,
+        400
+      );
+    }
+
+    const fitted = fitMessages(request.messages, budget, model);
+    console.warn(`Prompt for ${model} cut from ${promptTokens} to ${fitted.tokens} tokens ` +
+      `(${fitted.dropped} messages dropped, ${fitted.truncated} truncated)`);
+
+    return {
+      messages: fitted.messages,
+      maxTokens,
+      promptTokens: fitted.tokens,
+      truncation: {
+        originalPromptTokens: promptTokens,
+        promptTokens: fitted.tokens,
+        droppedMessages: fitted.dropped,
+        truncatedMessages: fitted.truncated
+      }
+    };
+  }
+
+  private withCost(usage: Omit<LLMUsage, 'costUsd'>, spec: ModelSpec): LLMUsage {
+    const costUsd = (usage.promptTokens * spec.inputPricePerMillion +
+      usage.completionTokens * spec.outputPricePerMillion) / 1_000_000;
+
+    return { ...usage, costUsd: Math.round(costUsd * 1_000_000) / 1_000_000 };
+  }
+
+  private isConfigured(providerName: ProviderName): boolean {
+    return !!this.providers.get(providerName)?.isConfigured();
+  }
+
+  private calculateRetryDelay(attempt: number): number {
+    const exponentialDelay = this.retryOptions.baseDelay * Math.pow(2, attempt);
+    const jitter = Math.random() * 1000; // Add jitter to prevent thundering herd
+    return Math.min(exponentialDelay + jitter, this.retryOptions.maxDelay);
+  }
+
+  private async delay(ms: number): Promise<void> {
+    return new Promise(resolve => setTimeout(resolve, ms));
+  }
+}
@@ -0,0 +1,74 @@
+import { ModelDefinition, IModelDefinition } from '../models/ModelDefinition.js';
+import { MODEL_SPECS } from '../config/models.js';
+import { ProviderName, ResolvedModel } from '../types/index.js';
+
+// Cached view of the model registry; the collection is seeded from MODEL_SPECS when empty
+export class ModelRegistry {
+  private entries: IModelDefinition[] = [];
+  private refreshTimer?: NodeJS.Timeout;
+
+  async initialize(): Promise<void> {
+    if (await ModelDefinition.countDocuments() === 0) {
+      const seeds = Object.entries(MODEL_SPECS).map(([modelId, spec], index) => ({
+        modelId,
+        ...spec,
+        priority: (index + 1) * 10
+      }));
+      await ModelDefinition.insertMany(seeds);
+    }
+
+    await this.refresh();
+
+    // Other gateway replicas may have changed the registry
+    const interval = parseInt(process.env.MODEL_REGISTRY_REFRESH_MS || '60000');
+    this.refreshTimer = setInterval(() => {
+      this.refresh().catch(error => console.error('Model registry refresh error:', error));
+    }, interval);
+  }
+
+  stop(): void {
+    if (this.refreshTimer) clearInterval(this.refreshTimer);
+  }
+
+  async refresh(): Promise<void> {
+    this.entries = await ModelDefinition.find().sort({ priority: 1, modelId: 1 });
+  }
+
+  list(includeDisabled = false): IModelDefinition[] {
+    return this.entries.filter(entry => includeDisabled || entry.enabled);
+  }
+
+  // Resolves a model ID or alias to a usable model, preferring providers that are configured
+  resolve(name: string, isAvailable: (provider: ProviderName) => boolean = () => true): ResolvedModel | undefined {
+    return this.resolveAll(name, isAvailable)[0];
+  }
+
+  // Every usable model for an ID or alias: models on configured providers first, then by priority
+  resolveAll(name: string, isAvailable: (provider: ProviderName) => boolean = () => true): ResolvedModel[] {
+    const now = Date.now();
+    const usable = (entry: IModelDefinition) =>
+      entry.enabled && !(entry.deprecatedAt && entry.deprecatedAt.getTime() <= now);
+
+    const exact = this.entries.find(entry => entry.modelId === name);
+    const candidates = exact
+      ? [exact].filter(usable)
+      : this.entries.filter(entry => entry.aliases.includes(name) && usable(entry));
+
+    return [
+      ...candidates.filter(entry => isAvailable(entry.provider)),
+      ...candidates.filter(entry => !isAvailable(entry.provider))
+    ].map(match => ({
+      id: match.modelId,
+      spec: {
+        provider: match.provider,
+        contextWindow: match.contextWindow,
+        maxOutputTokens: match.maxOutputTokens,
+        inputPricePerMillion: match.inputPricePerMillion,
+        outputPricePerMillion: match.outputPricePerMillion,
+        capabilities: match.capabilities,
+        aliases: match.aliases,
+        deprecatedAt: match.deprecatedAt
+      }
+    }));
+  }
+}
@@ -0,0 +1,77 @@
+import { ProviderFailure, ProviderName } from '../types/index.js';
+
+export interface ProviderHealthSnapshot {
+  provider: ProviderName;
+  // Moving average of call outcomes, 1 when every recent call succeeded
+  score: number;
+  healthy: boolean;
+  consecutiveFailures: number;
+  coolingDownUntil?: string;
+  lastFailure?: { reason: ProviderFailure; error: string; at: string };
+}
+
+interface HealthState {
+  score: number;
+  consecutiveFailures: number;
+  coolingDownUntil: number;
+  lastFailure?: { reason: ProviderFailure; error: string; at: number };
+}
+
+// Weight of the latest outcome in the score
+const SMOOTHING = 0.2;
+
+// What this replica has seen of each provider. Rate limits and repeated outages put a provider in
+// cooldown; routing tries its models last until the cooldown ends.
+export class ProviderHealth {
+  private states: Map<ProviderName, HealthState> = new Map();
+  private minScore = parseFloat(process.env.LLM_HEALTH_MIN_SCORE || '0.5');
+  private failureThreshold = parseInt(process.env.LLM_HEALTH_FAILURE_THRESHOLD || '3');
+  private cooldownMs = parseInt(process.env.LLM_HEALTH_COOLDOWN_MS || '30000');
+
+  recordSuccess(provider: ProviderName): void {
+    const state = this.state(provider);
+    state.score = state.score * (1 - SMOOTHING) + SMOOTHING;
+    state.consecutiveFailures = 0;
+  }
+
+  // Content-filter refusals say nothing about the provider's health and are not recorded
+  recordFailure(provider: ProviderName, reason: ProviderFailure, error: string): void {
+    if (reason === 'content_filter') return;
+
+    const state = this.state(provider);
+    state.score = state.score * (1 - SMOOTHING);
+    state.consecutiveFailures++;
+    state.lastFailure = { reason, error, at: Date.now() };
+
+    if (reason === 'rate_limit' || state.consecutiveFailures >= this.failureThreshold) {
+      state.coolingDownUntil = Date.now() + this.cooldownMs;
+    }
+  }
+
+  isHealthy(provider: ProviderName): boolean {
+    const state = this.states.get(provider);
+    if (!state) return true;
+    return state.coolingDownUntil <= Date.now() && state.score >= this.minScore;
+  }
+
+  snapshot(provider: ProviderName): ProviderHealthSnapshot {
+    const state = this.state(provider);
+    return {
+      provider,
+      score: Math.round(state.score * 1000) / 1000,
+      healthy: this.isHealthy(provider),
+      consecutiveFailures: state.consecutiveFailures,
+      ...(state.coolingDownUntil > Date.now() ? { coolingDownUntil: new Date(state.coolingDownUntil).toISOString() } : {}),
+      ...(state.lastFailure ? { lastFailure: { ...state.lastFailure, at: new Date(state.lastFailure.at).toISOString() } } : {})
+    };
+  }
+
+  private state(provider: ProviderName): HealthState {
+    let state = this.states.get(provider);
+    if (!state) {
+      state = { score: 1, consecutiveFailures: 0, coolingDownUntil: 0 };
+      this.states.set(provider, state);
+    }
+    return state;
+  }
+}
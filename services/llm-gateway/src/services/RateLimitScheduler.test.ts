@@ -0,0 +1,183 @@
+import { RateLimitScheduler } from './RateLimitScheduler.js';
+import { ProviderError } from '../types/index.js';
+
+// Tracks a promise without awaiting it, so a test can look at it between timer advances
+const track = (promise: Promise<void>) => {
+  const state: { status: 'pending' | 'resolved' | 'rejected'; error?: ProviderError } = { status: 'pending' };
+  promise.then(
+    () => { state.status = 'resolved'; },
+    error => {
+      state.status = 'rejected';
+      state.error = error;
+    }
+  );
+  return state;
+};
+
+describe('RateLimitScheduler', () => {
+  let scheduler: RateLimitScheduler;
+
+  beforeEach(() => {
+    jest.useFakeTimers();
+    scheduler = new RateLimitScheduler();
+  });
+
+  afterEach(() => {
+    jest.useRealTimers();
+  });
+
+  it('lets requests through while no limits are known', async () => {
+    await expect(scheduler.acquire('openai', 'key-1', 1000)).resolves.toBeUndefined();
+  });
+
+  it('holds requests until the request window resets', async () => {
+    scheduler.observe('openai', 'key-1', { requests: { limit: 10, remaining: 0, resetAt: Date.now() + 1000 } });
+    const request = track(scheduler.acquire('openai', 'key-1', 10));
+
+    await jest.advanceTimersByTimeAsync(999);
+    expect(request.status).toBe('pending');
+
+    await jest.advanceTimersByTimeAsync(1);
+    expect(request.status).toBe('resolved');
+  });
+
+  it('counts capacity down locally between responses', async () => {
+    scheduler.observe('openai', 'key-1', { requests: { limit: 10, remaining: 2, resetAt: Date.now() + 1000 } });
+
+    const first = track(scheduler.acquire('openai', 'key-1', 10));
+    const second = track(scheduler.acquire('openai', 'key-1', 10));
+    const third = track(scheduler.acquire('openai', 'key-1', 10));
+    await jest.advanceTimersByTimeAsync(0);
+
+    expect([first.status, second.status, third.status]).toEqual(['resolved', 'resolved', 'pending']);
+  });
+
+  it('waits for token capacity, but sends a request larger than the whole window', async () => {
+    scheduler.observe('anthropic', 'key-1', { tokens: { limit: 1000, remaining: 300, resetAt: Date.now() + 2000 } });
+
+    const fits = track(scheduler.acquire('anthropic', 'key-1', 200));
+    const waits = track(scheduler.acquire('anthropic', 'key-1', 200));
+    await jest.advanceTimersByTimeAsync(0);
+    expect([fits.status, waits.status]).toEqual(['resolved', 'pending']);
+
+    scheduler.observe('anthropic', 'key-2', { tokens: { limit: 1000, remaining: 0, resetAt: Date.now() + 2000 } });
+    await expect(scheduler.acquire('anthropic', 'key-2', 5000)).resolves.toBeUndefined();
+  });
+
+  it('sends queued requests in arrival order even when a later one would fit sooner', async () => {
+    scheduler.observe('openai', 'key-1', { tokens: { limit: 1000, remaining: 500, resetAt: Date.now() + 1000 } });
+    const order: string[] = [];
+
+    const large = scheduler.acquire('openai', 'key-1', 600).then(() => order.push('large'));
+    const small = scheduler.acquire('openai', 'key-1', 100).then(() => order.push('small'));
+    await jest.advanceTimersByTimeAsync(500);
+    expect(order).toEqual([]);
+
+    await jest.advanceTimersByTimeAsync(500);
+    await Promise.all([large, small]);
+    expect(order).toEqual(['large', 'small']);
+  });
+
+  it('pauses for retry-after, or for the default backoff after a 429 without hints', async () => {
+    scheduler.observe('openai', 'key-1', { retryAfterMs: 3000 }, true);
+    scheduler.observe('gemini', 'key-1', undefined, true);
+
+    const retryAfter = track(scheduler.acquire('openai', 'key-1', 10));
+    const backoff = track(scheduler.acquire('gemini', 'key-1', 10));
+
+    await jest.advanceTimersByTimeAsync(3000);
+    expect([retryAfter.status, backoff.status]).toEqual(['resolved', 'pending']);
+
+    await jest.advanceTimersByTimeAsync(2000);
+    expect(backoff.status).toBe('resolved');
+  });
+
+  it('applies limits reported for an organization to every key in it', async () => {
+    scheduler.observe('openai', 'key-1', {
+      organization: 'org-1',
+      requests: { limit: 100, remaining: 0, resetAt: Date.now() + 1000 }
+    });
+    scheduler.observe('openai', 'key-2', { organization: 'org-1' });
+
+    const sameOrganization = track(scheduler.acquire('openai', 'key-2', 10));
+    const otherKey = track(scheduler.acquire('openai', 'key-3', 10));
+    await jest.advanceTimersByTimeAsync(0);
+
+    expect([sameOrganization.status, otherKey.status]).toEqual(['pending', 'resolved']);
+  });
+
+  it('releases waiters early when a response reports fresh capacity', async () => {
+    scheduler.observe('openai', 'key-1', { requests: { limit: 10, remaining: 0, resetAt: Date.now() + 10000 } });
+    const request = track(scheduler.acquire('openai', 'key-1', 10));
+    await jest.advanceTimersByTimeAsync(100);
+    expect(request.status).toBe('pending');
+
+    scheduler.observe('openai', 'key-1', { requests: { limit: 10, remaining: 5, resetAt: Date.now() + 10000 } });
+    await jest.advanceTimersByTimeAsync(0);
+    expect(request.status).toBe('resolved');
+  });
+
+  it('rejects with a rate_limit error when capacity is further off than the maximum wait', async () => {
+    scheduler.observe('openai', 'key-1', { retryAfterMs: 120000 }, true);
+
+    const error = await scheduler.acquire('openai', 'key-1', 10).catch(caught => caught);
+
+    expect(error).toBeInstanceOf(ProviderError);
+    expect(error).toMatchObject({ status: 429, reason: 'rate_limit', retryable: false });
+    expect(error.message).toBe('openai: rate limited (capacity expected in 120s)');
+  });
+
+  it('rejects a queued request once its wait would pass its deadline', async () => {
+    scheduler.observe('openai', 'key-1', { retryAfterMs: 1000 }, true);
+    const request = track(scheduler.acquire('openai', 'key-1', 10));
+
+    scheduler.observe('openai', 'key-1', { retryAfterMs: 120000 }, true);
+    await jest.advanceTimersByTimeAsync(0);
+
+    expect(request.status).toBe('rejected');
+    expect(request.error?.reason).toBe('rate_limit');
+  });
+
+  it('rejects requests beyond the queue limit', async () => {
+    process.env.LLM_RATE_LIMIT_MAX_QUEUE = '2';
+    try {
+      scheduler = new RateLimitScheduler();
+    } finally {
+      delete process.env.LLM_RATE_LIMIT_MAX_QUEUE;
+    }
+    scheduler.observe('openai', 'key-1', { retryAfterMs: 1000 }, true);
+
+    const queued = [scheduler.acquire('openai', 'key-1', 10), scheduler.acquire('openai', 'key-1', 10)];
+    await expect(scheduler.acquire('openai', 'key-1', 10)).rejects.toThrow('openai: rate limited (2 requests already queued)');
+
+    await jest.advanceTimersByTimeAsync(1000);
+    await expect(Promise.all(queued)).resolves.toBeDefined();
+  });
+
+  it('drops a cancelled request from the queue', async () => {
+    scheduler.observe('openai', 'key-1', { retryAfterMs: 1000 }, true);
+    const abort = new AbortController();
+    const request = track(scheduler.acquire('openai', 'key-1', 10, abort.signal));
+
+    abort.abort();
+    await jest.advanceTimersByTimeAsync(0);
+
+    expect(request.status).toBe('rejected');
+    expect(request.error?.message).toBe('openai: request cancelled');
+    expect(scheduler.snapshot().find(bucket => bucket.bucket === 'openai:key:key-1')?.queued).toBe(0);
+  });
+
+  it('reports remaining capacity and queue depth per bucket', async () => {
+    scheduler.observe('openai', 'key-1', { requests: { limit: 10, remaining: 0, resetAt: Date.now() + 1000 } });
+    track(scheduler.acquire('openai', 'key-1', 10));
+
+    expect(scheduler.snapshot()).toEqual([{
+      bucket: 'openai:key:key-1',
+      requests: { limit: 10, remaining: 0, resetAt: Date.now() + 1000, resetsIn: 1000 },
+      tokens: undefined,
+      queued: 1
+    }]);
+
+    await jest.advanceTimersByTimeAsync(1000);
+  });
+});
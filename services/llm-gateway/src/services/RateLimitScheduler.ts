@@ -0,0 +1,203 @@
+import { LimitWindow, ProviderError, ProviderName, RateLimitInfo } from '../types/index.js';
+
+interface Bucket {
+  requests?: LimitWindow;
+  tokens?: LimitWindow;
+  // Set from retry-after, or a default pause after a 429 that gave no hint
+  blockedUntil: number;
+}
+
+interface Waiter {
+  tokens: number;
+  deadline: number;
+  resolve: () => void;
+  reject: (error: Error) => void;
+}
+
+export interface BucketSnapshot {
+  bucket: string;
+  requests?: LimitWindow & { resetsIn: number };
+  tokens?: LimitWindow & { resetsIn: number };
+  blockedFor?: number;
+  queued: number;
+}
+
+// Holds outgoing requests until the provider has capacity for them, going by the rate-limit
+// headers of earlier responses. Capacity is tracked per API key and, once a response names the
+// organization, per organization, since providers usually enforce limits for the whole org. Each
+// key's requests leave in arrival order.
+export class RateLimitScheduler {
+  private buckets: Map<string, Bucket> = new Map();
+  private organizations: Map<string, string> = new Map();
+  private queues: Map<string, Waiter[]> = new Map();
+  private timers: Map<string, NodeJS.Timeout> = new Map();
+  private maxWaitMs = parseInt(process.env.LLM_RATE_LIMIT_MAX_WAIT_MS || '60000');
+  private maxQueue = parseInt(process.env.LLM_RATE_LIMIT_MAX_QUEUE || '100');
+  private backoffMs = parseInt(process.env.LLM_RATE_LIMIT_BACKOFF_MS || '5000');
+
+  // Resolves once a request of about this many tokens fits under the key's and organization's
+  // limits. Throws a rate_limit ProviderError when that is further off than LLM_RATE_LIMIT_MAX_WAIT_MS
+  // or the queue is full, so routing can fall back instead.
+  acquire(provider: ProviderName, keyId: string, tokens: number, signal?: AbortSignal): Promise<void> {
+    const key = this.keyBucket(provider, keyId);
+    const queue = this.queues.get(key) || [];
+
+    if (queue.length === 0 && this.waitFor(key, tokens) === 0) {
+      this.consume(key, tokens);
+      return Promise.resolve();
+    }
+
+    const wait = this.waitFor(key, tokens);
+    if (wait > this.maxWaitMs || queue.length >= this.maxQueue) {
+      return Promise.reject(this.limitedError(provider, wait, queue.length));
+    }
+
+    return new Promise((resolve, reject) => {
+      const waiter: Waiter = {
+        tokens,
+        deadline: Date.now() + this.maxWaitMs,
+        resolve: () => {
+          signal?.removeEventListener('abort', onAbort);
+          resolve();
+        },
+        reject: error => {
+          signal?.removeEventListener('abort', onAbort);
+          reject(error);
+        }
+      };
+      const onAbort = () => {
+        const waiting = this.queues.get(key) || [];
+        const index = waiting.indexOf(waiter);
+        if (index >= 0) waiting.splice(index, 1);
+        waiter.reject(new ProviderError(`${provider}: request cancelled`, provider));
+      };
+      signal?.addEventListener('abort', onAbort, { once: true });
+
+      queue.push(waiter);
+      this.queues.set(key, queue);
+      this.pump(key);
+    });
+  }
+
+  // Records what a response, successful or not, said about the limits
+  observe(provider: ProviderName, keyId: string, info: RateLimitInfo | undefined, limited = false): void {
+    const key = this.keyBucket(provider, keyId);
+    if (info?.organization) {
+      this.organizations.set(key, `${provider}:org:${info.organization}`);
+    }
+
+    // Reported limits belong to the organization when the response names one
+    const targetName = this.organizations.get(key) || key;
+    const target = this.bucket(targetName);
+    if (info?.requests) target.requests = info.requests;
+    if (info?.tokens) target.tokens = info.tokens;
+
+    if (info?.retryAfterMs) {
+      target.blockedUntil = Math.max(target.blockedUntil, Date.now() + info.retryAfterMs);
+    } else if (limited && !info?.requests && !info?.tokens) {
+      target.blockedUntil = Math.max(target.blockedUntil, Date.now() + this.backoffMs);
+    }
+
+    // Waiters may be able to go sooner, or have to wait longer, than their timer assumed
+    for (const waiting of this.queues.keys()) {
+      if (waiting !== targetName && this.organizations.get(waiting) !== targetName) continue;
+      clearTimeout(this.timers.get(waiting));
+      this.timers.delete(waiting);
+      this.pump(waiting);
+    }
+  }
+
+  snapshot(): BucketSnapshot[] {
+    const now = Date.now();
+    const window = (limit?: LimitWindow) => limit && limit.resetAt > now
+      ? { ...limit, resetsIn: limit.resetAt - now }
+      : undefined;
+
+    return Array.from(this.buckets.entries()).map(([bucket, state]) => ({
+      bucket,
+      requests: window(state.requests),
+      tokens: window(state.tokens),
+      ...(state.blockedUntil > now ? { blockedFor: state.blockedUntil - now } : {}),
+      queued: this.queues.get(bucket)?.length || 0
+    }));
+  }
+
+  private pump(key: string): void {
+    if (this.timers.has(key)) return;
+
+    const provider = key.split(':')[0] as ProviderName;
+    const queue = this.queues.get(key) || [];
+    while (queue.length > 0) {
+      const head = queue[0];
+      const wait = this.waitFor(key, head.tokens);
+
+      if (wait === 0) {
+        queue.shift();
+        this.consume(key, head.tokens);
+        head.resolve();
+        continue;
+      }
+      if (Date.now() + wait > head.deadline) {
+        queue.shift();
+        head.reject(this.limitedError(provider, wait, queue.length));
+        continue;
+      }
+
+      this.timers.set(key, setTimeout(() => {
+        this.timers.delete(key);
+        this.pump(key);
+      }, wait));
+      return;
+    }
+  }
+
+  private waitFor(key: string, tokens: number): number {
+    const now = Date.now();
+    const buckets = [this.bucket(key)];
+    const organization = this.organizations.get(key);
+    if (organization) buckets.push(this.bucket(organization));
+
+    let wait = 0;
+    for (const bucket of buckets) {
+      if (bucket.blockedUntil > now) wait = Math.max(wait, bucket.blockedUntil - now);
+      if (bucket.requests && bucket.requests.resetAt > now && bucket.requests.remaining < 1) {
+        wait = Math.max(wait, bucket.requests.resetAt - now);
+      }
+      // A request larger than the whole window can never fit; the provider decides on it
+      const fits = bucket.tokens?.limit === undefined || tokens <= bucket.tokens.limit;
+      if (bucket.tokens && fits && bucket.tokens.resetAt > now && bucket.tokens.remaining < tokens) {
+        wait = Math.max(wait, bucket.tokens.resetAt - now);
+      }
+    }
+    return wait;
+  }
+
+  // Counted down locally so requests sent before the next response don't all see the same capacity
+  private consume(key: string, tokens: number): void {
+    const organization = this.organizations.get(key);
+    for (const bucket of [this.bucket(key), ...(organization ? [this.bucket(organization)] : [])]) {
+      if (bucket.requests) bucket.requests.remaining--;
+      if (bucket.tokens) bucket.tokens.remaining -= tokens;
+    }
+  }
+
+  private limitedError(provider: ProviderName, wait: number, queued: number): ProviderError {
+    const detail = queued >= this.maxQueue
+      ? `${queued} requests already queued`
+      : `capacity expected in ${Math.ceil(wait / 1000)}s`;
+    return new ProviderError(`${provider}: rate limited (${detail})`, provider, 429, false, 'rate_limit');
+  }
+
+  private keyBucket(provider: ProviderName, keyId: string): string {
+    return `${provider}:key:${keyId}`;
+  }
+
+  private bucket(name: string): Bucket {
+    let bucket = this.buckets.get(name);
+    if (!bucket) {
+      bucket = { blockedUntil: 0 };
+      this.buckets.set(name, bucket);
+    }
+    return bucket;
+  }
+}
@@ -0,0 +1,87 @@
+import { IRoutingPolicy, RoutingPolicy } from '../models/RoutingPolicy.js';
+import { ModelRegistry } from './ModelRegistry.js';
+import { ProviderHealth } from './ProviderHealth.js';
+import { ProviderFailure, ProviderName, ResolvedModel } from '../types/index.js';
+
+export const FAILURES: ProviderFailure[] = ['rate_limit', 'outage', 'content_filter'];
+
+// Failures that move a request to the next model when no policy says otherwise
+const DEFAULT_FALLBACK_ON = (process.env.LLM_FALLBACK_ON || FAILURES.join(','))
+  .split(',')
+  .map(reason => reason.trim())
+  .filter((reason): reason is ProviderFailure => FAILURES.includes(reason as ProviderFailure));
+
+export interface RoutePlan {
+  policy?: IRoutingPolicy;
+  // In the order they are tried
+  candidates: ResolvedModel[];
+  fallbackOn: ProviderFailure[];
+}
+
+// Cached routing policies. A request's chain is its primary model then the policy's fallbacks,
+// with aliases expanded to every model registered under them; without a policy an alias still
+// falls back across its models.
+export class RoutingService {
+  private policies: IRoutingPolicy[] = [];
+  private refreshTimer?: NodeJS.Timeout;
+
+  constructor(private registry: ModelRegistry, private health: ProviderHealth) {}
+
+  async initialize(): Promise<void> {
+    await this.refresh();
+
+    // Other gateway replicas may have changed the policies
+    const interval = parseInt(process.env.MODEL_REGISTRY_REFRESH_MS || '60000');
+    this.refreshTimer = setInterval(() => {
+      this.refresh().catch(error => console.error('Routing policy refresh error:', error));
+    }, interval);
+  }
+
+  stop(): void {
+    if (this.refreshTimer) clearInterval(this.refreshTimer);
+  }
+
+  async refresh(): Promise<void> {
+    this.policies = await RoutingPolicy.find({ enabled: true });
+  }
+
+  // A project's own policy for the model wins over its catch-all, then the global ones
+  policyFor(model: string, projectId?: string): IRoutingPolicy | undefined {
+    const find = (scope: string | undefined, name: string) =>
+      this.policies.find(policy => (policy.projectId || undefined) === scope && policy.model === name);
+
+    return (projectId ? find(projectId, model) || find(projectId, '*') : undefined)
+      || find(undefined, model)
+      || find(undefined, '*');
+  }
+
+  plan(model: string, projectId: string | undefined, isAvailable: (provider: ProviderName) => boolean): RoutePlan {
+    const policy = this.policyFor(model, projectId);
+    const names = [policy?.primary || model, ...(policy?.fallbacks || [])];
+
+    const seen = new Set<string>();
+    let candidates: ResolvedModel[] = [];
+    for (const name of names) {
+      for (const resolved of this.registry.resolveAll(name, isAvailable)) {
+        if (seen.has(resolved.id)) continue;
+        seen.add(resolved.id);
+        candidates.push(resolved);
+      }
+    }
+
+    // Stable, so the policy's order holds among equally healthy providers
+    if (policy?.healthWeighted ?? true) {
+      const rank = (candidate: ResolvedModel) => this.health.isHealthy(candidate.spec.provider) ? 0 : 1;
+      candidates = candidates
+        .map((candidate, index) => ({ candidate, index }))
+        .sort((a, b) => rank(a.candidate) - rank(b.candidate) || a.index - b.index)
+        .map(({ candidate }) => candidate);
+    }
+
+    return {
+      policy,
+      candidates,
+      fallbackOn: policy && policy.fallbackOn.length > 0 ? policy.fallbackOn : DEFAULT_FALLBACK_ON
+    };
+  }
+}
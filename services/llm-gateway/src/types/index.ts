@@ -0,0 +1,148 @@
+// LLM Gateway types
+export type ProviderName = 'openai' | 'anthropic' | 'gemini';
+
+export interface ChatMessage {
+  role: 'system' | 'user' | 'assistant';
+  content: string;
+}
+
+export interface ChatCompletionRequest {
+  model: string;
+  messages: ChatMessage[];
+  temperature?: number;
+  maxTokens?: number;
+  metadata?: Record<string, string>;
+  // What to do with a prompt that does not fit the model's context window (default truncate)
+  contextOverflow?: ContextOverflow;
+}
+
+export type ContextOverflow = 'truncate' | 'error';
+
+// Reported when a prompt was cut down to fit the model's context window
+export interface ContextTruncation {
+  originalPromptTokens: number;
+  promptTokens: number;
+  droppedMessages: number;
+  truncatedMessages: number;
+}
+
+export interface LLMUsage {
+  promptTokens: number;
+  completionTokens: number;
+  totalTokens: number;
+  costUsd?: number;
+}
+
+export interface ChatCompletionResponse {
+  id: string;
+  provider: ProviderName;
+  model: string;
+  requestedModel?: string;
+  content: string;
+  finishReason: string;
+  usage: LLMUsage;
+  latencyMs?: number;
+  attempts?: number;
+  budgetWarnings?: string[];
+  contextTruncation?: ContextTruncation;
+  // Routing policy that chose the models, and the ones given up on before this answer
+  routingPolicy?: string;
+  fallbacks?: RouteAttempt[];
+}
+
+export interface EmbeddingRequest {
+  model: string;
+  input: string[];
+  metadata?: Record<string, string>;
+}
+
+export interface EmbeddingResponse {
+  provider: ProviderName;
+  model: string;
+  requestedModel?: string;
+  // One vector per input, in input order
+  embeddings: number[][];
+  dimensions: number;
+  usage: LLMUsage;
+  budgetWarnings?: string[];
+}
+
+// Failures that can send a request on to the next model in its routing chain
+export type ProviderFailure = 'rate_limit' | 'outage' | 'content_filter';
+
+export interface RouteAttempt {
+  model: string;
+  provider: ProviderName;
+  reason: ProviderFailure;
+  error: string;
+}
+
+// Normalized result returned by each provider adapter before cost is applied
+export interface ProviderCompletion {
+  id: string;
+  content: string;
+  finishReason: string;
+  usage: Omit<LLMUsage, 'costUsd'>;
+  rateLimit?: RateLimitInfo;
+}
+
+export interface ProviderEmbedding {
+  embeddings: number[][];
+  // Absent when the provider does not report it
+  promptTokens?: number;
+  rateLimit?: RateLimitInfo;
+}
+
+// Remaining capacity in one provider limit window; resetAt is epoch milliseconds
+export interface LimitWindow {
+  limit?: number;
+  remaining: number;
+  resetAt: number;
+}
+
+// What a provider's response headers said about its rate limits
+export interface RateLimitInfo {
+  // Limits are usually shared by every key in the organization
+  organization?: string;
+  requests?: LimitWindow;
+  tokens?: LimitWindow;
+  retryAfterMs?: number;
+}
+
+export interface ModelSpec {
+  provider: ProviderName;
+  contextWindow: number;
+  maxOutputTokens: number;
+  inputPricePerMillion: number;
+  outputPricePerMillion: number;
+  capabilities?: string[];
+  aliases?: string[];
+  deprecatedAt?: Date;
+}
+
+// A registry entry resolved from a model ID or alias
+export interface ResolvedModel {
+  id: string;
+  spec: ModelSpec;
+}
+
+export class ProviderError extends Error {
+  constructor(
+    message: string,
+    public provider: ProviderName,
+    public status?: number,
+    public retryable: boolean = false,
+    public reason?: ProviderFailure
+  ) {
+    super(message);
+    this.name = 'ProviderError';
+  }
+
+  // Rate-limit headers of the failed response, when it had any
+  rateLimit?: RateLimitInfo;
+
+  withRateLimit(rateLimit?: RateLimitInfo): this {
+    this.rateLimit = rateLimit;
+    return this;
+  }
+}
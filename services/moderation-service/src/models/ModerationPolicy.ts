@@ -0,0 +1,72 @@
+import mongoose, { Schema, Document } from 'mongoose';
+import { CategorySettings, CustomRule } from '../services/Moderator.js';
+
+export interface IModerationPolicy extends Document {
+  // The default policy has no project; a project's own policy replaces it entirely
+  projectId?: string;
+  name: string;
+  categories: {
+    pii: CategorySettings;
+    toxicity: CategorySettings;
+    license: CategorySettings;
+  };
+  rules: CustomRule[];
+  allowlist: string[];
+  blockedTerms: string[];
+  updatedBy?: string;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const CategorySchema = (action: string) => new Schema({
+  enabled: { type: Boolean, default: true },
+  action: {
+    type: String,
+    enum: ['block', 'redact', 'flag'],
+    default: action
+  }
+}, { _id: false });
+
+const RuleSchema = new Schema({
+  id: { type: String, required: true },
+  description: { type: String, required: true },
+  pattern: { type: String, required: true },
+  flags: { type: String },
+  category: {
+    type: String,
+    enum: ['pii', 'toxicity', 'license', 'custom'],
+    default: 'custom'
+  },
+  action: {
+    type: String,
+    enum: ['block', 'redact', 'flag'],
+    default: 'block'
+  }
+}, { _id: false });
+
+const ModerationPolicySchema: Schema = new Schema({
+  projectId: {
+    type: String,
+    unique: true,
+    sparse: true
+  },
+  name: {
+    type: String,
+    required: true,
+    trim: true,
+    maxlength: 100
+  },
+  categories: {
+    pii: { type: CategorySchema('redact'), default: () => ({}) },
+    toxicity: { type: CategorySchema('block'), default: () => ({}) },
+    license: { type: CategorySchema('block'), default: () => ({}) }
+  },
+  rules: [RuleSchema],
+  allowlist: [{ type: String }],
+  blockedTerms: [{ type: String }],
+  updatedBy: { type: String }
+}, {
+  timestamps: true
+});
+
+export const ModerationPolicy = mongoose.model<IModerationPolicy>('ModerationPolicy', ModerationPolicySchema);
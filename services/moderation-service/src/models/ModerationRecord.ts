@@ -0,0 +1,77 @@
+import mongoose, { Schema, Document } from 'mongoose';
+import { ModerationDecision, ModerationFinding, ModerationSource } from '../services/Moderator.js';
+
+// Audit entry for a check that found something. The screened content itself is not kept, only
+// masked excerpts and a fingerprint of each blocked item.
+export interface IModerationRecord extends Document {
+  projectId?: string;
+  runId?: string;
+  stageId?: string;
+  source: ModerationSource;
+  decision: Exclude<ModerationDecision, 'allowed'>;
+  policyId?: string;
+  policyName: string;
+  itemsChecked: number;
+  blockedItems: Array<{ name: string; fingerprint: string }>;
+  findings: ModerationFinding[];
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const FindingSchema = new Schema({
+  item: { type: String, required: true },
+  line: { type: Number, required: true },
+  column: { type: Number, required: true },
+  category: {
+    type: String,
+    enum: ['pii', 'toxicity', 'license', 'custom'],
+    required: true
+  },
+  rule: { type: String, required: true },
+  description: { type: String, required: true },
+  action: {
+    type: String,
+    enum: ['block', 'redact', 'flag'],
+    required: true
+  },
+  excerpt: { type: String, required: true }
+}, { _id: false });
+
+const ModerationRecordSchema: Schema = new Schema({
+  projectId: {
+    type: String,
+    index: true
+  },
+  runId: {
+    type: String,
+    index: true
+  },
+  stageId: { type: String },
+  source: {
+    type: String,
+    enum: ['prompt', 'output', 'artifact', 'files'],
+    required: true
+  },
+  decision: {
+    type: String,
+    enum: ['flagged', 'redacted', 'blocked'],
+    required: true,
+    index: true
+  },
+  policyId: { type: String },
+  policyName: { type: String, required: true },
+  itemsChecked: {
+    type: Number,
+    default: 0
+  },
+  blockedItems: [{
+    _id: false,
+    name: { type: String, required: true },
+    fingerprint: { type: String, required: true }
+  }],
+  findings: [FindingSchema]
+}, {
+  timestamps: true
+});
+
+export const ModerationRecord = mongoose.model<IModerationRecord>('ModerationRecord', ModerationRecordSchema);
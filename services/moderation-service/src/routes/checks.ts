@@ -0,0 +1,164 @@
+import express, { Request, Response } from 'express';
+import { body, param, query, validationResult } from 'express-validator';
+import { ModerationRecord } from '../models/ModerationRecord.js';
+import { ModeratedItem, Moderator } from '../services/Moderator.js';
+import { PolicyStore } from '../services/PolicyStore.js';
+import { requireAuth, requireServiceToken } from '../middleware/auth.js';
+
+const router = express.Router();
+const moderator = new Moderator();
+const policies = new PolicyStore();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+// POST /api/moderation/checks - Screen prompts, agent output, artifacts or files against the
+// project's policy. Anything found is written to the audit log; the redacted items are returned
+// for the caller to use in place of the originals. Internal: callers present the service token.
+router.post('/checks',
+  requireServiceToken,
+  [
+    body('source').isIn(['prompt', 'output', 'artifact', 'files']).withMessage('Source must be prompt, output, artifact or files'),
+    body('items').optional().isArray().withMessage('Items must be an array'),
+    body('items.*.name').optional().isString().withMessage('Each item needs a name'),
+    body('items.*.content').optional().isString().withMessage('Each item needs content'),
+    body('content').optional().isString().withMessage('Content must be a string'),
+    body('projectId').optional().isString().withMessage('Project ID must be a string'),
+    body('runId').optional().isString().withMessage('Run ID must be a string'),
+    body('stageId').optional().isString().withMessage('Stage ID must be a string')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const { source, projectId, runId, stageId } = req.body;
+      const items: ModeratedItem[] = req.body.items
+        || (typeof req.body.content === 'string' ? [{ name: req.body.name || source, content: req.body.content }] : []);
+
+      if (items.length === 0) {
+        return res.status(400).json({
+          success: false,
+          error: 'Provide items or content to check'
+        });
+      }
+
+      const policy = await policies.resolve(projectId);
+      const result = moderator.moderate(items, policy.settings);
+
+      let recordId: string | undefined;
+      if (result.decision !== 'allowed') {
+        const record = await ModerationRecord.create({
+          projectId,
+          runId,
+          stageId,
+          source,
+          decision: result.decision,
+          policyId: policy.id,
+          policyName: policy.name,
+          itemsChecked: items.length,
+          blockedItems: result.blockedItems.map(name => ({
+            name,
+            fingerprint: moderator.fingerprint(items.find(item => item.name === name)!.content)
+          })),
+          findings: result.findings
+        });
+        recordId = record.id;
+      }
+
+      res.json({
+        success: true,
+        data: {
+          decision: result.decision,
+          findings: result.findings,
+          items: result.items,
+          blockedItems: result.blockedItems,
+          policy: { id: policy.id, name: policy.name },
+          recordId
+        }
+      });
+    } catch (error) {
+      console.error('Error running moderation check:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to run moderation check'
+      });
+    }
+  }
+);
+
+// GET /api/moderation/audit - Blocked, redacted and flagged items for a project or run
+router.get('/audit',
+  requireAuth,
+  [
+    query('projectId').optional().isString().withMessage('Project ID must be a string'),
+    query('runId').optional().isString().withMessage('Run ID must be a string'),
+    query('decision').optional().isIn(['flagged', 'redacted', 'blocked']).withMessage('Decision must be flagged, redacted or blocked'),
+    query('source').optional().isIn(['prompt', 'output', 'artifact', 'files']).withMessage('Source must be prompt, output, artifact or files'),
+    query('limit').optional().isInt({ min: 1, max: 500 }).withMessage('Limit must be between 1 and 500')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const filter: any = {};
+      if (req.query.projectId) filter.projectId = req.query.projectId;
+      if (req.query.runId) filter.runId = req.query.runId;
+      if (req.query.decision) filter.decision = req.query.decision;
+      if (req.query.source) filter.source = req.query.source;
+
+      const records = await ModerationRecord.find(filter)
+        .sort({ createdAt: -1 })
+        .limit(parseInt(req.query.limit as string) || 100);
+
+      res.json({
+        success: true,
+        data: records
+      });
+    } catch (error) {
+      console.error('Error fetching moderation audit:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch moderation audit'
+      });
+    }
+  }
+);
+
+// GET /api/moderation/audit/:id - One audit entry with its findings
+router.get('/audit/:id',
+  requireAuth,
+  [param('id').isMongoId().withMessage('Invalid audit record ID')],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const record = await ModerationRecord.findById(req.params.id);
+      if (!record) {
+        return res.status(404).json({
+          success: false,
+          error: 'Audit record not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: record
+      });
+    } catch (error) {
+      console.error('Error fetching moderation audit record:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch moderation audit record'
+      });
+    }
+  }
+);
+
+export default router;
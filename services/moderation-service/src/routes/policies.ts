@@ -0,0 +1,196 @@
+import express, { Response } from 'express';
+import { body, param, query, validationResult } from 'express-validator';
+import { ModerationPolicy } from '../models/ModerationPolicy.js';
+import { ACTIONS, CATEGORIES, CustomRule, compileCustomRule } from '../services/Moderator.js';
+import { PolicyStore } from '../services/PolicyStore.js';
+import { AuthenticatedRequest, requireAuth, requireRole } from '../middleware/auth.js';
+
+const router = express.Router();
+const policies = new PolicyStore();
+
+// Validation middleware
+const validateRequest = (req: AuthenticatedRequest, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const policyValidators = [
+  body('name').optional().trim().isLength({ min: 1, max: 100 }).withMessage('Name must be 1-100 characters'),
+  ...CATEGORIES.flatMap(category => [
+    body(`categories.${category}.enabled`).optional().isBoolean().withMessage(`categories.${category}.enabled must be a boolean`),
+    body(`categories.${category}.action`).optional().isIn(ACTIONS).withMessage(`categories.${category}.action must be block, redact or flag`)
+  ]),
+  body('rules').optional().isArray().withMessage('Rules must be an array'),
+  body('rules.*.id').optional().isString().isLength({ min: 1 }).withMessage('Each rule needs an id'),
+  body('rules.*.description').optional().isString().isLength({ min: 1 }).withMessage('Each rule needs a description'),
+  body('rules.*.pattern').optional().isString().isLength({ min: 1 }).withMessage('Each rule needs a pattern'),
+  body('rules.*.action').optional().isIn(ACTIONS).withMessage('Rule action must be block, redact or flag'),
+  body('allowlist').optional().isArray().withMessage('Allowlist must be an array of strings'),
+  body('blockedTerms').optional().isArray().withMessage('Blocked terms must be an array of strings')
+];
+
+// Custom patterns are compiled up front so a bad rule is rejected instead of silently skipped later
+function invalidRules(rules: CustomRule[] | undefined): string[] {
+  return (rules || []).flatMap(rule => {
+    try {
+      compileCustomRule(rule);
+      return [];
+    } catch (error) {
+      return [`${rule.id}: ${error instanceof Error ? error.message : 'invalid pattern'}`];
+    }
+  });
+}
+
+async function savePolicy(req: AuthenticatedRequest, res: Response, projectId?: string) {
+  const errors = invalidRules(req.body.rules);
+  if (errors.length > 0) {
+    return res.status(400).json({
+      success: false,
+      error: 'Invalid rule patterns',
+      details: errors
+    });
+  }
+
+  const update: Record<string, any> = { updatedBy: req.user?._id };
+  for (const field of ['name', 'rules', 'allowlist', 'blockedTerms']) {
+    if (req.body[field] !== undefined) update[field] = req.body[field];
+  }
+  for (const category of CATEGORIES) {
+    for (const setting of ['enabled', 'action']) {
+      const value = req.body.categories?.[category]?.[setting];
+      if (value !== undefined) update[`categories.${category}.${setting}`] = value;
+    }
+  }
+
+  // An upsert takes projectId from the filter
+  const filter = projectId ? { projectId } : { projectId: { $exists: false } };
+  const policy = await ModerationPolicy.findOneAndUpdate(filter, {
+    $set: update,
+    ...(update.name ? {} : { $setOnInsert: { name: projectId ? `Project ${projectId}` : 'Default' } })
+  }, { new: true, upsert: true, runValidators: true, setDefaultsOnInsert: true });
+
+  res.json({
+    success: true,
+    data: policy,
+    message: 'Moderation policy saved'
+  });
+}
+
+// GET /api/moderation/policies - Stored policies: the default and any project overrides
+router.get('/',
+  requireAuth,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const stored = await ModerationPolicy.find().sort({ projectId: 1 });
+
+      res.json({
+        success: true,
+        data: stored
+      });
+    } catch (error) {
+      console.error('Error fetching moderation policies:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch moderation policies'
+      });
+    }
+  }
+);
+
+// GET /api/moderation/policies/effective?projectId= - The policy checks for a project run under
+router.get('/effective',
+  requireAuth,
+  [query('projectId').optional().isString().withMessage('Project ID must be a string')],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const policy = await policies.resolve(req.query.projectId as string | undefined);
+
+      res.json({
+        success: true,
+        data: policy
+      });
+    } catch (error) {
+      console.error('Error resolving moderation policy:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to resolve moderation policy'
+      });
+    }
+  }
+);
+
+// PUT /api/moderation/policies/default - Create or update the policy used by projects without their own (admins)
+router.put('/default',
+  requireAuth,
+  requireRole('admin'),
+  policyValidators,
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      await savePolicy(req, res);
+    } catch (error) {
+      console.error('Error saving default moderation policy:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to save moderation policy'
+      });
+    }
+  }
+);
+
+// PUT /api/moderation/policies/projects/:projectId - Create or update a project's own policy
+router.put('/projects/:projectId',
+  requireAuth,
+  [param('projectId').isString().isLength({ min: 1 }).withMessage('Project ID is required'), ...policyValidators],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      await savePolicy(req, res, req.params.projectId);
+    } catch (error) {
+      console.error('Error saving project moderation policy:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to save moderation policy'
+      });
+    }
+  }
+);
+
+// DELETE /api/moderation/policies/projects/:projectId - Return a project to the default policy
+router.delete('/projects/:projectId',
+  requireAuth,
+  [param('projectId').isString().isLength({ min: 1 }).withMessage('Project ID is required')],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const policy = await ModerationPolicy.findOneAndDelete({ projectId: req.params.projectId });
+      if (!policy) {
+        return res.status(404).json({
+          success: false,
+          error: 'Project has no moderation policy of its own'
+        });
+      }
+
+      res.json({
+        success: true,
+        message: 'Project moderation policy removed; the default policy applies'
+      });
+    } catch (error) {
+      console.error('Error deleting moderation policy:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to delete moderation policy'
+      });
+    }
+  }
+);
+
+export default router;
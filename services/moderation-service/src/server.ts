@@ -0,0 +1,123 @@
+import express from 'express';
+import cors from 'cors';
+import mongoose from 'mongoose';
+import dotenv from 'dotenv';
+import winston from 'winston';
+import checkRoutes from './routes/checks.js';
+import policyRoutes from './routes/policies.js';
+
+// Load environment variables
+dotenv.config();
+
+const app = express();
+const PORT = process.env.PORT || 3020;
+
+// Logger configuration
+const logger = winston.createLogger({
+  level: 'info',
+  format: winston.format.combine(
+    winston.format.timestamp(),
+    winston.format.json()
+  ),
+  transports: [
+    new winston.transports.Console(),
+    new winston.transports.File({ filename: 'logs/moderation-service.log' })
+  ]
+});
+
+// Database connection
+const MONGODB_URI = process.env.MONGODB_URI || 'mongodb://localhost:27017/ai-pipeline';
+
+mongoose.connect(MONGODB_URI)
+  .then(() => {
+    logger.info('🗄️  Connected to MongoDB');
+  })
+  .catch((error) => {
+    logger.error('❌ MongoDB connection error:', error);
+    process.exit(1);
+  });
+
+// Middleware
+app.use(cors({
+  origin: process.env.FRONTEND_URL || 'http://localhost:5173',
+  credentials: true
+}));
+app.use(express.json({ limit: '50mb' }));
+app.use(express.urlencoded({ extended: true }));
+
+// Request logging middleware
+app.use((req, res, next) => {
+  logger.info(`${req.method} ${req.path}`, {
+    ip: req.ip,
+    userAgent: req.get('User-Agent'),
+    timestamp: new Date().toISOString()
+  });
+  next();
+});
+
+// Routes
+app.use('/api/moderation/policies', policyRoutes);
+app.use('/api/moderation', checkRoutes);
+
+// Health check endpoint
+app.get('/health', (req, res) => {
+  res.json({
+    status: 'ok',
+    service: 'moderation-service',
+    timestamp: new Date().toISOString(),
+    version: '1.0.0',
+    database: mongoose.connection.readyState === 1 ? 'connected' : 'disconnected'
+  });
+});
+
+// Service info endpoint
+app.get('/info', (req, res) => {
+  res.json({
+    service: 'Moderation Service',
+    version: '1.0.0',
+    description: 'Content moderation and policy filtering for prompts, agent output and artifacts',
+    endpoints: [
+      { method: 'POST', path: '/api/moderation/checks', description: 'Screen content against the project policy' },
+      { method: 'GET', path: '/api/moderation/audit', description: 'Blocked, redacted and flagged items' },
+      { method: 'GET', path: '/api/moderation/audit/:id', description: 'Audit entry with findings' },
+      { method: 'GET', path: '/api/moderation/policies', description: 'Stored policies' },
+      { method: 'GET', path: '/api/moderation/policies/effective', description: 'Policy in force for a project' },
+      { method: 'PUT', path: '/api/moderation/policies/default', description: 'Update the default policy' },
+      { method: 'PUT', path: '/api/moderation/policies/projects/:projectId', description: 'Update a project policy' },
+      { method: 'DELETE', path: '/api/moderation/policies/projects/:projectId', description: 'Remove a project policy' }
+    ]
+  });
+});
+
+// Catch-all for undefined routes
+app.use('*', (req, res) => {
+  res.status(404).json({
+    success: false,
+    error: 'Route not found',
+    service: 'moderation-service'
+  });
+});
+
+// Global error handler
+app.use((err: any, req: any, res: any, next: any) => {
+  logger.error('Unhandled error:', err);
+  res.status(500).json({
+    success: false,
+    error: 'Internal server error',
+    service: 'moderation-service'
+  });
+});
+
+// Graceful shutdown
+process.on('SIGTERM', () => {
+  logger.info('SIGTERM received. Shutting down gracefully...');
+  mongoose.connection.close();
+  process.exit(0);
+});
+
+// Start server
+app.listen(PORT, () => {
+  logger.info(`🛡️ Moderation Service running on port ${PORT}`);
+});
+
+export default app;
@@ -0,0 +1,297 @@
+import { createHash } from 'crypto';
+
+export type ModerationCategory = 'pii' | 'toxicity' | 'license' | 'custom';
+// block withholds the item, redact masks the match, flag only records it
+export type ModerationAction = 'block' | 'redact' | 'flag';
+export type ModerationDecision = 'allowed' | 'flagged' | 'redacted' | 'blocked';
+export type ModerationSource = 'prompt' | 'output' | 'artifact' | 'files';
+
+export interface ModeratedItem {
+  name: string;
+  content: string;
+}
+
+export interface ModerationFinding {
+  item: string;
+  line: number;
+  column: number;
+  category: ModerationCategory;
+  rule: string;
+  description: string;
+  action: ModerationAction;
+  // Personal data is masked; other matches are kept (shortened) so reviewers can judge them
+  excerpt: string;
+}
+
+export interface CategorySettings {
+  enabled: boolean;
+  action: ModerationAction;
+}
+
+export interface CustomRule {
+  id: string;
+  description: string;
+  pattern: string;
+  flags?: string;
+  category?: ModerationCategory;
+  action?: ModerationAction;
+}
+
+export interface PolicySettings {
+  categories: Record<Exclude<ModerationCategory, 'custom'>, CategorySettings>;
+  rules: CustomRule[];
+  // Matches containing any of these are ignored, e.g. example.com addresses in docs
+  allowlist: string[];
+  // Extra terms screened under toxicity
+  blockedTerms: string[];
+}
+
+export interface ModerationResult {
+  decision: ModerationDecision;
+  findings: ModerationFinding[];
+  // The items as they may be used: redactions applied, blocked items still included
+  items: ModeratedItem[];
+  blockedItems: string[];
+}
+
+export interface ModerationRule {
+  id: string;
+  description: string;
+  category: ModerationCategory;
+  pattern: RegExp;
+  // Rules whose action is fixed regardless of the category's, e.g. notices that only warrant a look
+  action?: ModerationAction;
+  // Filters out matches that only look like the real thing
+  validate?: (match: string) => boolean;
+}
+
+export const ACTIONS: ModerationAction[] = ['block', 'redact', 'flag'];
+export const CATEGORIES: Array<Exclude<ModerationCategory, 'custom'>> = ['pii', 'toxicity', 'license'];
+
+export const DEFAULT_POLICY: PolicySettings = {
+  categories: {
+    pii: { enabled: true, action: 'redact' },
+    toxicity: { enabled: true, action: 'block' },
+    license: { enabled: true, action: 'block' }
+  },
+  rules: [],
+  allowlist: ['example.com', 'example.org', 'example.net', '@localhost', '555-01'],
+  blockedTerms: []
+};
+
+const DECISION_ORDER: ModerationDecision[] = ['allowed', 'flagged', 'redacted', 'blocked'];
+const ACTION_DECISION: Record<ModerationAction, ModerationDecision> = {
+  flag: 'flagged',
+  redact: 'redacted',
+  block: 'blocked'
+};
+
+function luhn(value: string): boolean {
+  const digits = value.replace(/\D/g, '');
+  if (digits.length < 13 || digits.length > 19) return false;
+  let sum = 0;
+  for (let i = 0; i < digits.length; i++) {
+    let digit = parseInt(digits[digits.length - 1 - i]);
+    if (i % 2 === 1) {
+      digit *= 2;
+      if (digit > 9) digit -= 9;
+    }
+    sum += digit;
+  }
+  return sum % 10 === 0;
+}
+
+function ibanChecksum(value: string): boolean {
+  const rearranged = value.slice(4) + value.slice(0, 4);
+  const numeric = rearranged.replace(/[A-Z]/g, letter => String(letter.charCodeAt(0) - 55));
+  let remainder = 0;
+  for (const digit of numeric) remainder = (remainder * 10 + parseInt(digit)) % 97;
+  return remainder === 1;
+}
+
+const BUILTIN_RULES: ModerationRule[] = [
+  { id: 'email', description: 'Email address', category: 'pii', pattern: /\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b/g },
+  {
+    id: 'phone-number',
+    description: 'Phone number',
+    category: 'pii',
+    pattern: /(?<![\w.])(?:\+\d{1,3}[ .-]?)?\(?\d{3}\)?[ .-]\d{3}[ .-]\d{4}(?![\w.])/g
+  },
+  { id: 'us-ssn', description: 'US social security number', category: 'pii', pattern: /\b(?!000|666|9\d\d)\d{3}-(?!00)\d{2}-(?!0000)\d{4}\b/g },
+  { id: 'credit-card', description: 'Payment card number', category: 'pii', pattern: /\b\d(?:[ -]?\d){12,18}\b/g, validate: luhn },
+  { id: 'iban', description: 'Bank account number (IBAN)', category: 'pii', pattern: /\b[A-Z]{2}\d{2}[A-Z0-9]{11,30}\b/g, validate: ibanChecksum },
+  {
+    id: 'threat',
+    description: 'Threat of violence',
+    category: 'toxicity',
+    pattern: /\b(?:i(?:'ll| will| am going to|'m going to)|we(?:'ll| will)|gonna)\s+(?:kill|hurt|murder|shoot|stab)\s+(?:you|u|him|her|them)\b/gi
+  },
+  {
+    id: 'self-harm',
+    description: 'Encouragement of self-harm',
+    category: 'toxicity',
+    pattern: /\b(?:go\s+)?(?:kill|hang|hurt)\s+your\s?self\b|\bkys\b/gi
+  },
+  {
+    id: 'profanity',
+    description: 'Profanity',
+    category: 'toxicity',
+    pattern: /\b(?:motherfuck\w*|fuck\w*|shit(?:ty|head)?|bullshit|bitch(?:es)?|cunt\w*|asshole\w*|dickhead\w*)\b/gi
+  },
+  {
+    id: 'copyleft-license',
+    description: 'Copyleft license text or identifier (GPL family)',
+    category: 'license',
+    pattern: /GNU (?:Affero |Lesser )?General Public License|SPDX-License-Identifier:\s*(?:A|L)?GPL-[\d.]+[\w.+-]*/gi
+  },
+  {
+    id: 'source-available-license',
+    description: 'Source-available or non-commercial license',
+    category: 'license',
+    pattern: /Server Side Public License|Business Source License|Commons Clause|SPDX-License-Identifier:\s*(?:SSPL-1\.0|BUSL-1\.1|CC-BY-NC[\w.-]*)/gi
+  },
+  {
+    id: 'proprietary-notice',
+    description: 'Third-party copyright notice',
+    category: 'license',
+    pattern: /Copyright\s+(?:\(c\)|©)?\s*(?:19|20)\d{2}[^\n]{0,80}All rights reserved/gi,
+    action: 'flag'
+  }
+];
+
+function escapeRegExp(value: string): string {
+  return value.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+}
+
+function mask(value: string): string {
+  if (value.length <= 4) return '*'.repeat(value.length);
+  return `${value[0]}${'*'.repeat(value.length - 2)}${value[value.length - 1]}`;
+}
+
+// Compiles a policy's own rule, throwing when the pattern is not a valid regular expression
+export function compileCustomRule(rule: CustomRule): ModerationRule {
+  const flags = Array.from(new Set(`${rule.flags || ''}g`)).join('');
+  return {
+    id: rule.id,
+    description: rule.description,
+    category: rule.category || 'custom',
+    pattern: new RegExp(rule.pattern, flags),
+    action: rule.action || 'block'
+  };
+}
+
+// Screens text against a policy: personal data, toxic language and license-restricted code by
+// default, plus the policy's own rules. The strictest action found decides the outcome.
+export class Moderator {
+  moderate(items: ModeratedItem[], policy: PolicySettings): ModerationResult {
+    const rules = this.rulesFor(policy);
+    const allowlist = policy.allowlist.map(entry => entry.toLowerCase());
+    const findings: ModerationFinding[] = [];
+    const moderated: ModeratedItem[] = [];
+    const blockedItems: string[] = [];
+
+    for (const item of items) {
+      const matches = this.scan(item, rules, policy, allowlist);
+      findings.push(...matches.map(match => match.finding));
+
+      if (matches.some(match => match.finding.action === 'block')) blockedItems.push(item.name);
+      moderated.push({
+        name: item.name,
+        content: this.redact(item.content, matches.filter(match => match.finding.action === 'redact'))
+      });
+    }
+
+    const decision = findings.reduce<ModerationDecision>((worst, finding) => {
+      const next = ACTION_DECISION[finding.action];
+      return DECISION_ORDER.indexOf(next) > DECISION_ORDER.indexOf(worst) ? next : worst;
+    }, 'allowed');
+
+    return { decision, findings, items: moderated, blockedItems };
+  }
+
+  // Stable hash so a blocked item can be recognised again without the audit log storing it
+  fingerprint(content: string): string {
+    return createHash('sha256').update(content).digest('hex');
+  }
+
+  private rulesFor(policy: PolicySettings): ModerationRule[] {
+    const rules = BUILTIN_RULES.filter(rule => rule.category !== 'custom' && policy.categories[rule.category].enabled);
+
+    if (policy.categories.toxicity.enabled && policy.blockedTerms.length > 0) {
+      rules.push({
+        id: 'blocked-term',
+        description: 'Term blocked by policy',
+        category: 'toxicity',
+        pattern: new RegExp(`\\b(?:${policy.blockedTerms.map(escapeRegExp).join('|')})\\b`, 'gi')
+      });
+    }
+
+    for (const rule of policy.rules) {
+      try {
+        rules.push(compileCustomRule(rule));
+      } catch (error) {
+        // Policies are validated when saved; a rule that still fails to compile is skipped, not fatal
+        console.error(`Skipping moderation rule ${rule.id}:`, error);
+      }
+    }
+
+    return rules;
+  }
+
+  private scan(
+    item: ModeratedItem,
+    rules: ModerationRule[],
+    policy: PolicySettings,
+    allowlist: string[]
+  ): Array<{ start: number; end: number; finding: ModerationFinding }> {
+    const lineStarts = [0];
+    for (let i = 0; i < item.content.length; i++) {
+      if (item.content[i] === '\n') lineStarts.push(i + 1);
+    }
+
+    const matches: Array<{ start: number; end: number; finding: ModerationFinding }> = [];
+    for (const rule of rules) {
+      for (const match of item.content.matchAll(rule.pattern)) {
+        const value = match[0];
+        if (!value) continue;
+        if (rule.validate && !rule.validate(value)) continue;
+        if (allowlist.some(entry => value.toLowerCase().includes(entry))) continue;
+
+        const start = match.index ?? 0;
+        let line = lineStarts.length - 1;
+        while (lineStarts[line] > start) line--;
+
+        const action = rule.action
+          || (rule.category === 'custom' ? 'block' : policy.categories[rule.category].action);
+        matches.push({
+          start,
+          end: start + value.length,
+          finding: {
+            item: item.name,
+            line: line + 1,
+            column: start - lineStarts[line] + 1,
+            category: rule.category,
+            rule: rule.id,
+            description: rule.description,
+            action,
+            excerpt: rule.category === 'pii' ? mask(value) : value.slice(0, 120)
+          }
+        });
+      }
+    }
+
+    return matches.sort((a, b) => a.start - b.start);
+  }
+
+  private redact(content: string, matches: Array<{ start: number; end: number; finding: ModerationFinding }>): string {
+    let result = '';
+    let cursor = 0;
+    for (const match of matches) {
+      // Overlapping matches are covered by the first one
+      if (match.start < cursor) continue;
+      result += content.slice(cursor, match.start) + `[REDACTED:${match.finding.rule}]`;
+      cursor = match.end;
+    }
+    return result + content.slice(cursor);
+  }
+}
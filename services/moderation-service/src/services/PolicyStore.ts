@@ -0,0 +1,35 @@
+import { ModerationPolicy, IModerationPolicy } from '../models/ModerationPolicy.js';
+import { DEFAULT_POLICY, PolicySettings } from './Moderator.js';
+
+export interface ResolvedPolicy {
+  id?: string;
+  name: string;
+  settings: PolicySettings;
+}
+
+export function toSettings(policy: IModerationPolicy): PolicySettings {
+  return {
+    categories: {
+      pii: { enabled: policy.categories.pii.enabled, action: policy.categories.pii.action },
+      toxicity: { enabled: policy.categories.toxicity.enabled, action: policy.categories.toxicity.action },
+      license: { enabled: policy.categories.license.enabled, action: policy.categories.license.action }
+    },
+    rules: policy.rules,
+    allowlist: policy.allowlist,
+    blockedTerms: policy.blockedTerms
+  };
+}
+
+// The policy that applies to a check: the project's own, else the stored default, else the
+// built-in defaults
+export class PolicyStore {
+  async resolve(projectId?: string): Promise<ResolvedPolicy> {
+    const policy = (projectId ? await ModerationPolicy.findOne({ projectId }) : null)
+      || await ModerationPolicy.findOne({ projectId: { $exists: false } });
+
+    if (!policy) {
+      return { name: 'built-in', settings: DEFAULT_POLICY };
+    }
+    return { id: policy.id, name: policy.name, settings: toSettings(policy) };
+  }
+}
@@ -0,0 +1,8 @@
+import { IChannelTarget } from '../models/NotificationChannel.js';
+import { RenderedMessage } from '../templates/messages.js';
+
+// A delivery mechanism for rendered notifications. Add new channels by implementing this and registering them in NotificationService.
+export interface Channel {
+  readonly type: string;
+  send(target: IChannelTarget, message: RenderedMessage): Promise<void>;
+}
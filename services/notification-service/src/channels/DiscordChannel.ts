@@ -0,0 +1,29 @@
+import axios from 'axios';
+import { Channel } from './Channel.js';
+import { IChannelTarget } from '../models/NotificationChannel.js';
+import { RenderedMessage } from '../templates/messages.js';
+
+const SEVERITY_COLOR: Record<RenderedMessage['severity'], number> = {
+  info: 0x3b82f6,
+  success: 0x22c55e,
+  warning: 0xf59e0b,
+  error: 0xef4444
+};
+
+// Posts an embed to a Discord channel webhook
+export class DiscordChannel implements Channel {
+  readonly type = 'discord';
+
+  async send(target: IChannelTarget, message: RenderedMessage): Promise<void> {
+    if (!target.webhookUrl) throw new Error('Discord channel has no webhook URL');
+
+    await axios.post(target.webhookUrl, {
+      embeds: [{
+        title: message.title,
+        description: message.body.slice(0, 4000),
+        color: SEVERITY_COLOR[message.severity],
+        timestamp: new Date().toISOString()
+      }]
+    }, { timeout: 10000 });
+  }
+}
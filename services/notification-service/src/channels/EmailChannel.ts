@@ -0,0 +1,42 @@
+import nodemailer, { Transporter } from 'nodemailer';
+import { Channel } from './Channel.js';
+import { IChannelTarget } from '../models/NotificationChannel.js';
+import { RenderedMessage } from '../templates/messages.js';
+
+// Sends plain-text email through the configured SMTP server
+export class EmailChannel implements Channel {
+  readonly type = 'email';
+  private transporter: Transporter | null = null;
+
+  isConfigured(): boolean {
+    return Boolean(process.env.SMTP_HOST);
+  }
+
+  async send(target: IChannelTarget, message: RenderedMessage): Promise<void> {
+    if (!this.isConfigured()) throw new Error('SMTP is not configured');
+    if (!target.recipients || target.recipients.length === 0) throw new Error('Email channel has no recipients');
+
+    await this.getTransporter().sendMail({
+      from: process.env.SMTP_FROM || 'AI Pipeline <no-reply@ai-pipeline.local>',
+      to: target.recipients.join(', '),
+      subject: `[AI Pipeline] ${message.title}`,
+      text: message.body
+    });
+  }
+
+  private getTransporter(): Transporter {
+    if (!this.transporter) {
+      const port = parseInt(process.env.SMTP_PORT || '587');
+      this.transporter = nodemailer.createTransport({
+        host: process.env.SMTP_HOST,
+        port,
+        secure: port === 465,
+        auth: process.env.SMTP_USER ? {
+          user: process.env.SMTP_USER,
+          pass: process.env.SMTP_PASSWORD
+        } : undefined
+      });
+    }
+    return this.transporter;
+  }
+}
@@ -0,0 +1,24 @@
+import axios from 'axios';
+import { Channel } from './Channel.js';
+import { IChannelTarget } from '../models/NotificationChannel.js';
+import { RenderedMessage } from '../templates/messages.js';
+
+const SEVERITY_EMOJI: Record<RenderedMessage['severity'], string> = {
+  info: ':information_source:',
+  success: ':white_check_mark:',
+  warning: ':warning:',
+  error: ':x:'
+};
+
+// Posts to a Slack incoming webhook
+export class SlackChannel implements Channel {
+  readonly type = 'slack';
+
+  async send(target: IChannelTarget, message: RenderedMessage): Promise<void> {
+    if (!target.webhookUrl) throw new Error('Slack channel has no webhook URL');
+
+    await axios.post(target.webhookUrl, {
+      text: `${SEVERITY_EMOJI[message.severity]} *${message.title}*\n${message.body}`
+    }, { timeout: 10000 });
+  }
+}
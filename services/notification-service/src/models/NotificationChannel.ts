@@ -0,0 +1,77 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export type ChannelType = 'slack' | 'email' | 'discord';
+
+export interface IChannelTarget {
+  webhookUrl?: string;
+  recipients?: string[];
+}
+
+export interface INotificationChannel extends Document {
+  name: string;
+  type: ChannelType;
+  target: IChannelTarget;
+  projectId?: string;
+  allProjects: boolean;
+  service?: string;
+  events: string[];
+  templates: Map<string, string>;
+  active: boolean;
+  ownerId: string;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const NotificationChannelSchema: Schema = new Schema({
+  name: {
+    type: String,
+    required: true,
+    trim: true,
+    maxlength: 100
+  },
+  type: {
+    type: String,
+    enum: ['slack', 'email', 'discord'],
+    required: true
+  },
+  target: {
+    webhookUrl: { type: String, select: false },
+    recipients: [{ type: String, lowercase: true, trim: true }]
+  },
+  // Scope: a project, optionally narrowed to an emitting service
+  projectId: {
+    type: String,
+    index: true
+  },
+  // Only admins may configure channels that receive every project's events
+  allProjects: {
+    type: Boolean,
+    default: false
+  },
+  service: {
+    type: String
+  },
+  events: {
+    type: [String],
+    default: ['pipeline.completed', 'pipeline.failed', 'stage.failed']
+  },
+  // Per-event message overrides, keyed by event type
+  templates: {
+    type: Map,
+    of: String,
+    default: {}
+  },
+  active: {
+    type: Boolean,
+    default: true
+  },
+  ownerId: {
+    type: String,
+    required: true,
+    index: true
+  }
+}, {
+  timestamps: true
+});
+
+export const NotificationChannel = mongoose.model<INotificationChannel>('NotificationChannel', NotificationChannelSchema);
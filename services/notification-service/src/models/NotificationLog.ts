@@ -0,0 +1,37 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export interface INotificationLog extends Document {
+  channelId: mongoose.Types.ObjectId;
+  eventType: string;
+  status: 'sent' | 'failed';
+  error?: string;
+  createdAt: Date;
+}
+
+const NotificationLogSchema: Schema = new Schema({
+  channelId: {
+    type: Schema.Types.ObjectId,
+    ref: 'NotificationChannel',
+    required: true,
+    index: true
+  },
+  eventType: {
+    type: String,
+    required: true
+  },
+  status: {
+    type: String,
+    enum: ['sent', 'failed'],
+    required: true
+  },
+  error: {
+    type: String
+  }
+}, {
+  timestamps: { createdAt: true, updatedAt: false }
+});
+
+// Keep notification history for 30 days
+NotificationLogSchema.index({ createdAt: 1 }, { expireAfterSeconds: 30 * 24 * 60 * 60 });
+
+export const NotificationLog = mongoose.model<INotificationLog>('NotificationLog', NotificationLogSchema);
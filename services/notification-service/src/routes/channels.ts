@@ -0,0 +1,317 @@
+import express, { Request, Response } from 'express';
+import { body, param, query, validationResult } from 'express-validator';
+import axios from 'axios';
+import { NotificationChannel } from '../models/NotificationChannel.js';
+import { NotificationLog } from '../models/NotificationLog.js';
+import { NotificationService } from '../services/NotificationService.js';
+import { requireAuth, AuthenticatedRequest } from '../middleware/auth.js';
+
+const router = express.Router();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const idParam = param('id').isMongoId().withMessage('Invalid channel ID');
+
+// Slack and Discord need a webhook URL, email needs recipients
+const validateTarget = (type: string, target: any): string | null => {
+  if (type === 'email') {
+    return Array.isArray(target?.recipients) && target.recipients.length > 0 ? null : 'Email channels need at least one recipient';
+  }
+  return typeof target?.webhookUrl === 'string' && /^https:\/\//.test(target.webhookUrl) ? null : 'Slack and Discord channels need an https webhook URL';
+};
+
+const findOwnedChannel = (req: AuthenticatedRequest, id: string) => {
+  const filter: any = { _id: id };
+  if (req.user!.role !== 'admin') filter.ownerId = req.user!._id;
+  return NotificationChannel.findOne(filter);
+};
+
+// Confirms through project-service that the caller is a member of the project
+const canAccessProject = async (req: AuthenticatedRequest, projectId: string): Promise<boolean> => {
+  const projectServiceUrl = process.env.PROJECT_SERVICE_URL || 'http://localhost:3002';
+
+  try {
+    await axios.get(`${projectServiceUrl}/api/projects/${encodeURIComponent(projectId)}`, {
+      headers: { Authorization: req.headers.authorization },
+      timeout: 5000
+    });
+    return true;
+  } catch (error) {
+    if (!axios.isAxiosError(error) || !error.response) throw error;
+    return false;
+  }
+};
+
+// Channels are scoped to a project the caller belongs to; only admins may leave the project out
+const checkProjectScope = async (req: AuthenticatedRequest, res: Response, projectId?: string): Promise<boolean> => {
+  if (!projectId) {
+    if (req.user!.role === 'admin') return true;
+    res.status(403).json({
+      success: false,
+      error: 'Only admins can configure channels for all projects'
+    });
+    return false;
+  }
+
+  if (await canAccessProject(req, projectId)) return true;
+  res.status(404).json({
+    success: false,
+    error: 'Project not found'
+  });
+  return false;
+};
+
+const channelRules = [
+  body('name').optional().trim().isLength({ min: 1, max: 100 }).withMessage('Name must be between 1 and 100 characters'),
+  body('target').optional().isObject().withMessage('Target must be an object'),
+  body('target.recipients.*').optional().isEmail().withMessage('Recipients must be email addresses'),
+  body('projectId').optional().isString().withMessage('Project ID must be a string'),
+  body('service').optional().isString().withMessage('Service must be a string'),
+  body('events').optional().isArray({ min: 1 }).withMessage('Events must be a non-empty array'),
+  body('events.*').optional().isString().withMessage('Event types must be strings'),
+  body('templates').optional().isObject().withMessage('Templates must map event types to message bodies'),
+  body('active').optional().isBoolean().withMessage('Active must be a boolean')
+];
+
+export default function createChannelRoutes(notifications: NotificationService) {
+  router.use(requireAuth);
+
+  // POST /api/notifications/channels - Configure a notification channel
+  router.post('/',
+    [
+      body('name').trim().isLength({ min: 1, max: 100 }).withMessage('Channel name is required'),
+      body('type').isIn(['slack', 'email', 'discord']).withMessage('Type must be slack, email or discord'),
+      ...channelRules
+    ],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const targetError = validateTarget(req.body.type, req.body.target);
+        if (targetError) {
+          return res.status(400).json({
+            success: false,
+            error: targetError
+          });
+        }
+
+        if (!await checkProjectScope(req, res, req.body.projectId)) return;
+
+        const channel = new NotificationChannel({
+          name: req.body.name,
+          type: req.body.type,
+          target: req.body.target,
+          projectId: req.body.projectId,
+          allProjects: !req.body.projectId,
+          service: req.body.service,
+          events: req.body.events,
+          templates: req.body.templates,
+          ownerId: req.user!._id
+        });
+        await channel.save();
+
+        res.status(201).json({
+          success: true,
+          data: channel,
+          message: 'Notification channel created successfully'
+        });
+      } catch (error) {
+        console.error('Error creating notification channel:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to create notification channel'
+        });
+      }
+    }
+  );
+
+  // GET /api/notifications/channels - List channels, optionally for one project
+  router.get('/',
+    [
+      query('projectId').optional().isString().withMessage('Project ID must be a string')
+    ],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const filter: any = { ownerId: req.user!._id };
+        if (req.query.projectId) filter.projectId = req.query.projectId;
+
+        const channels = await NotificationChannel.find(filter).sort({ createdAt: -1 });
+
+        res.json({
+          success: true,
+          data: channels
+        });
+      } catch (error) {
+        console.error('Error listing notification channels:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to list notification channels'
+        });
+      }
+    }
+  );
+
+  // PUT /api/notifications/channels/:id - Update a channel
+  router.put('/:id',
+    [idParam, ...channelRules],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const channel = await findOwnedChannel(req, req.params.id);
+        if (!channel) {
+          return res.status(404).json({
+            success: false,
+            error: 'Notification channel not found'
+          });
+        }
+
+        if (req.body.target !== undefined) {
+          const targetError = validateTarget(channel.type, req.body.target);
+          if (targetError) {
+            return res.status(400).json({
+              success: false,
+              error: targetError
+            });
+          }
+          channel.target = req.body.target;
+        }
+
+        const { name, projectId, service, events, templates, active } = req.body;
+        if (projectId !== undefined && projectId !== channel.projectId) {
+          if (!await checkProjectScope(req, res, projectId)) return;
+          channel.projectId = projectId || undefined;
+          channel.allProjects = !projectId;
+        }
+        if (name !== undefined) channel.name = name;
+        if (service !== undefined) channel.service = service;
+        if (events !== undefined) channel.events = events;
+        if (templates !== undefined) channel.set('templates', templates);
+        if (active !== undefined) channel.active = active;
+        await channel.save();
+
+        res.json({
+          success: true,
+          data: channel,
+          message: 'Notification channel updated successfully'
+        });
+      } catch (error) {
+        console.error('Error updating notification channel:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to update notification channel'
+        });
+      }
+    }
+  );
+
+  // POST /api/notifications/channels/:id/test - Send a test message
+  router.post('/:id/test',
+    [idParam],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const channel = await findOwnedChannel(req, req.params.id).select('+target.webhookUrl');
+        if (!channel) {
+          return res.status(404).json({
+            success: false,
+            error: 'Notification channel not found'
+          });
+        }
+
+        const result = await notifications.deliver(channel, {
+          type: 'notification.test',
+          projectId: channel.projectId,
+          data: { message: `Test notification for channel "${channel.name}"` }
+        });
+
+        res.status(result.status === 'sent' ? 200 : 502).json({
+          success: result.status === 'sent',
+          data: result,
+          error: result.error
+        });
+      } catch (error) {
+        console.error('Error sending test notification:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to send test notification'
+        });
+      }
+    }
+  );
+
+  // GET /api/notifications/channels/:id/logs - Recent sends for a channel
+  router.get('/:id/logs',
+    [idParam],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const channel = await findOwnedChannel(req, req.params.id);
+        if (!channel) {
+          return res.status(404).json({
+            success: false,
+            error: 'Notification channel not found'
+          });
+        }
+
+        const logs = await NotificationLog.find({ channelId: channel._id })
+          .sort({ createdAt: -1 })
+          .limit(100);
+
+        res.json({
+          success: true,
+          data: logs
+        });
+      } catch (error) {
+        console.error('Error listing notification logs:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to list notification logs'
+        });
+      }
+    }
+  );
+
+  // DELETE /api/notifications/channels/:id - Remove a channel
+  router.delete('/:id',
+    [idParam],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const channel = await findOwnedChannel(req, req.params.id);
+        if (!channel) {
+          return res.status(404).json({
+            success: false,
+            error: 'Notification channel not found'
+          });
+        }
+
+        await NotificationLog.deleteMany({ channelId: channel._id });
+        await channel.deleteOne();
+
+        res.json({
+          success: true,
+          message: 'Notification channel deleted successfully'
+        });
+      } catch (error) {
+        console.error('Error deleting notification channel:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to delete notification channel'
+        });
+      }
+    }
+  );
+
+  return router;
+}
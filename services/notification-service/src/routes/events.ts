@@ -0,0 +1,57 @@
+import express, { Request, Response } from 'express';
+import { body, validationResult } from 'express-validator';
+import { NotificationService } from '../services/NotificationService.js';
+import { requireServiceToken } from '../middleware/auth.js';
+
+const router = express.Router();
+
+// Internal ingestion endpoint: other services publish lifecycle events here as CloudEvents
+// (legacy { type, service, projectId, data } bodies are still accepted). Not exposed via the gateway, and
+// callers must present the shared service token since events fan out to every subscribed channel.
+export default function createEventRoutes(notifications: NotificationService) {
+  router.use(requireServiceToken);
+
+  // POST /api/notifications/events - Notify every channel subscribed to an event
+  router.post('/',
+    [
+      body('type').matches(/^[a-z][a-z0-9_.-]*$/).withMessage('Event type must be a dotted lowercase name'),
+      body('specversion').optional().equals('1.0').withMessage('Only CloudEvents specversion 1.0 is supported'),
+      body('source').optional().isString().withMessage('Source must be a string'),
+      body('service').optional().isString().withMessage('Service must be a string'),
+      body('projectid').optional().isString().withMessage('Project ID must be a string'),
+      body('projectId').optional().isString().withMessage('Project ID must be a string'),
+      body('data').exists().withMessage('Event data is required')
+    ],
+    async (req: Request, res: Response) => {
+      const errors = validationResult(req);
+      if (!errors.isEmpty()) {
+        return res.status(400).json({
+          success: false,
+          error: 'Validation failed',
+          details: errors.array()
+        });
+      }
+
+      try {
+        const { type, data } = req.body;
+        const projectId = req.body.projectid ?? req.body.projectId;
+        // A CloudEvents source of "/<service>" names the emitting service
+        const service = req.body.service ?? (typeof req.body.source === 'string' ? req.body.source.replace(/^\//, '') : undefined);
+        const results = await notifications.notify({ type, projectId, service, data });
+
+        res.json({
+          success: true,
+          data: results
+        });
+      } catch (error) {
+        console.error('Error sending notifications:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to send notifications'
+        });
+      }
+    }
+  );
+
+  return router;
+}
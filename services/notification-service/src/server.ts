@@ -0,0 +1,127 @@
+import express from 'express';
+import cors from 'cors';
+import mongoose from 'mongoose';
+import dotenv from 'dotenv';
+import winston from 'winston';
+import { NotificationService } from './services/NotificationService.js';
+import createChannelRoutes from './routes/channels.js';
+import createEventRoutes from './routes/events.js';
+
+// Load environment variables
+dotenv.config();
+
+const app = express();
+const PORT = process.env.PORT || 3010;
+
+// Logger configuration
+const logger = winston.createLogger({
+  level: 'info',
+  format: winston.format.combine(
+    winston.format.timestamp(),
+    winston.format.json()
+  ),
+  transports: [
+    new winston.transports.Console(),
+    new winston.transports.File({ filename: 'logs/notification-service.log' })
+  ]
+});
+
+const notifications = new NotificationService();
+
+// Database connection
+const MONGODB_URI = process.env.MONGODB_URI || 'mongodb://localhost:27017/ai-pipeline';
+
+mongoose.connect(MONGODB_URI)
+  .then(() => {
+    logger.info('🗄️  Connected to MongoDB');
+  })
+  .catch((error) => {
+    logger.error('❌ MongoDB connection error:', error);
+    process.exit(1);
+  });
+
+// Middleware
+app.use(cors({
+  origin: process.env.FRONTEND_URL || 'http://localhost:5173',
+  credentials: true
+}));
+// Events arrive as CloudEvents in structured mode
+app.use(express.json({ limit: '5mb', type: ['application/json', 'application/cloudevents+json'] }));
+app.use(express.urlencoded({ extended: true }));
+
+// Request logging middleware
+app.use((req, res, next) => {
+  logger.info(`${req.method} ${req.path}`, {
+    ip: req.ip,
+    userAgent: req.get('User-Agent'),
+    timestamp: new Date().toISOString()
+  });
+  next();
+});
+
+// Routes
+// Event ingestion is internal and mounted before the authenticated channel routes
+app.use('/api/notifications/events', createEventRoutes(notifications));
+app.use('/api/notifications/channels', createChannelRoutes(notifications));
+
+// Health check endpoint
+app.get('/health', (req, res) => {
+  res.json({
+    status: 'ok',
+    service: 'notification-service',
+    timestamp: new Date().toISOString(),
+    version: '1.0.0',
+    database: mongoose.connection.readyState === 1 ? 'connected' : 'disconnected'
+  });
+});
+
+// Service info endpoint
+app.get('/info', (req, res) => {
+  res.json({
+    service: 'Notification Service',
+    version: '1.0.0',
+    description: 'Slack, email and Discord notifications for pipeline and key events',
+    endpoints: [
+      { method: 'POST', path: '/api/notifications/channels', description: 'Create notification channel' },
+      { method: 'GET', path: '/api/notifications/channels', description: 'List notification channels' },
+      { method: 'PUT', path: '/api/notifications/channels/:id', description: 'Update notification channel' },
+      { method: 'POST', path: '/api/notifications/channels/:id/test', description: 'Send test notification' },
+      { method: 'GET', path: '/api/notifications/channels/:id/logs', description: 'List recent notifications' },
+      { method: 'DELETE', path: '/api/notifications/channels/:id', description: 'Delete notification channel' },
+      { method: 'POST', path: '/api/notifications/events', description: 'Notify subscribed channels (internal)' }
+    ]
+  });
+});
+
+// Catch-all for undefined routes
+app.use('*', (req, res) => {
+  res.status(404).json({
+    success: false,
+    error: 'Route not found',
+    service: 'notification-service'
+  });
+});
+
+// Global error handler
+app.use((err: any, req: any, res: any, next: any) => {
+  logger.error('Unhandled error:', err);
+  res.status(500).json({
+    success: false,
+    error: 'Internal server error',
+    service: 'notification-service'
+  });
+});
+
+// Graceful shutdown
+process.on('SIGTERM', () => {
+  logger.info('SIGTERM received. Shutting down gracefully...');
+  mongoose.connection.close();
+  process.exit(0);
+});
+
+// Start server
+app.listen(PORT, () => {
+  logger.info(`🔔 Notification Service running on port ${PORT}`);
+});
+
+export default app;
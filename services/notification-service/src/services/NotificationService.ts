@@ -0,0 +1,56 @@
+import { Channel } from '../channels/Channel.js';
+import { SlackChannel } from '../channels/SlackChannel.js';
+import { DiscordChannel } from '../channels/DiscordChannel.js';
+import { EmailChannel } from '../channels/EmailChannel.js';
+import { NotificationChannel, INotificationChannel } from '../models/NotificationChannel.js';
+import { NotificationLog } from '../models/NotificationLog.js';
+import { NotificationEvent, renderMessage } from '../templates/messages.js';
+
+export interface DispatchResult {
+  channelId: string;
+  status: 'sent' | 'failed';
+  error?: string;
+}
+
+export class NotificationService {
+  private channels: Map<string, Channel> = new Map();
+
+  constructor() {
+    [new SlackChannel(), new DiscordChannel(), new EmailChannel()].forEach(channel => {
+      this.channels.set(channel.type, channel);
+    });
+  }
+
+  // Sends an event to every active channel whose scope and event filter match: channels of the
+  // event's project, plus admin-configured channels that receive every project's events
+  async notify(event: NotificationEvent): Promise<DispatchResult[]> {
+    const scope: any[] = [{ allProjects: true }];
+    if (event.projectId) scope.push({ projectId: event.projectId });
+
+    const subscribed = await NotificationChannel.find({
+      active: true,
+      events: { $in: [event.type, '*'] },
+      $or: scope
+    }).select('+target.webhookUrl');
+
+    const matching = subscribed.filter(channel => !channel.service || channel.service === event.service);
+    return Promise.all(matching.map(channel => this.deliver(channel, event)));
+  }
+
+  async deliver(channel: INotificationChannel, event: NotificationEvent): Promise<DispatchResult> {
+    const sender = this.channels.get(channel.type);
+    const message = renderMessage(event, channel.templates?.get(event.type));
+
+    try {
+      if (!sender) throw new Error(`Unsupported channel type "${channel.type}"`);
+      await sender.send(channel.target, message);
+
+      await NotificationLog.create({ channelId: channel._id, eventType: event.type, status: 'sent' });
+      return { channelId: channel._id.toString(), status: 'sent' };
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : 'Notification failed';
+      await NotificationLog.create({ channelId: channel._id, eventType: event.type, status: 'failed', error: errorMessage });
+      return { channelId: channel._id.toString(), status: 'failed', error: errorMessage };
+    }
+  }
+}
@@ -0,0 +1,91 @@
+export interface NotificationEvent {
+  type: string;
+  projectId?: string;
+  service?: string;
+  data: Record<string, any>;
+}
+
+export interface RenderedMessage {
+  title: string;
+  body: string;
+  severity: 'info' | 'success' | 'warning' | 'error';
+}
+
+interface MessageTemplate {
+  title: string;
+  body: string;
+  severity: RenderedMessage['severity'];
+}
+
+// Default wording per event type; channels can override the body per event
+const DEFAULT_TEMPLATES: Record<string, MessageTemplate> = {
+  'pipeline.completed': {
+    title: 'Pipeline completed',
+    body: 'Pipeline "{{name}}" ({{pipelineId}}) finished successfully.',
+    severity: 'success'
+  },
+  'pipeline.failed': {
+    title: 'Pipeline failed',
+    body: 'Pipeline {{pipelineId}} failed at stage "{{stage}}": {{error}}',
+    severity: 'error'
+  },
+  'stage.failed': {
+    title: 'Stage failed',
+    body: 'Stage "{{stage}}" of pipeline {{pipelineId}} failed: {{error}}',
+    severity: 'error'
+  },
+  'pipeline.cancelled': {
+    title: 'Pipeline cancelled',
+    body: 'Pipeline {{pipelineId}} was cancelled.',
+    severity: 'warning'
+  },
+  'budget.warning': {
+    title: 'LLM budget warning',
+    body: 'The {{scope}} budget for {{scopeId}} has used ${{spentUsd}} of its ${{limitUsd}} {{period}} limit.',
+    severity: 'warning'
+  },
+  'key.expiring': {
+    title: 'API key expiring',
+    body: 'Key "{{keyName}}" for {{service}} expires on {{expiresAt}}. Rotate it before then.',
+    severity: 'warning'
+  },
+  'key.expired': {
+    title: 'API key expired',
+    body: 'Key "{{keyName}}" for {{service}} expired on {{expiresAt}}.',
+    severity: 'error'
+  }
+};
+
+const GENERIC_TEMPLATE: MessageTemplate = {
+  title: 'AI Pipeline event',
+  body: '{{type}}: {{summary}}',
+  severity: 'info'
+};
+
+function render(template: string, variables: Record<string, any>): string {
+  return template.replace(/\{\{\s*([\w.]+)\s*\}\}/g, (match, key: string) => {
+    const value = key.split('.').reduce<any>((current, part) => {
+      return current !== undefined && current !== null ? current[part] : undefined;
+    }, variables);
+
+    if (value === undefined || value === null) return '';
+    return typeof value === 'string' ? value : JSON.stringify(value);
+  });
+}
+
+export function renderMessage(event: NotificationEvent, overrideBody?: string): RenderedMessage {
+  const template = DEFAULT_TEMPLATES[event.type] || GENERIC_TEMPLATE;
+  const variables = {
+    ...event.data,
+    type: event.type,
+    projectId: event.projectId,
+    service: event.data.service || event.service,
+    summary: JSON.stringify(event.data)
+  };
+
+  return {
+    title: render(template.title, variables),
+    body: render(overrideBody || template.body, variables),
+    severity: template.severity
+  };
+}
@@ -0,0 +1,153 @@
+import { readFileSync } from 'fs';
+import * as path from 'path';
+import { DefinitionValidationError, parseDefinition } from './DefinitionParser.js';
+
+// Validation errors of a definition, or none when it parses
+const errorsOf = (raw: string): string[] => {
+  try {
+    parseDefinition(raw);
+    return [];
+  } catch (error) {
+    if (error instanceof DefinitionValidationError) return error.errors;
+    throw error;
+  }
+};
+
+describe('parseDefinition', () => {
+  it('parses the bundled definitions', () => {
+    const raw = readFileSync(path.join(__dirname, '../../pipelines/software-delivery.yaml'), 'utf8');
+
+    expect(parseDefinition(raw, 'software-delivery.yaml').name).toBe('software-delivery');
+  });
+
+  it('chains stages without needs in declaration order and defaults name and version', () => {
+    const definition = parseDefinition(`
+name: linear
+stages:
+  - id: plan
+    agent: architect
+  - id: build
+    name: Build it
+    agent: developer
+`);
+
+    expect(definition.version).toBe(1);
+    expect(definition.stages.map(stage => [stage.id, stage.name, stage.needs])).toEqual([
+      ['plan', 'plan', []],
+      ['build', 'Build it', ['plan']]
+    ]);
+  });
+
+  it('accepts parallel branches that join', () => {
+    const definition = parseDefinition(`
+name: fan-out
+stages:
+  - id: design
+  - id: frontend
+    needs: [design]
+    inputs:
+      architecture: "{{stages.design.output.architecture}}"
+  - id: backend
+    needs: [design, design]
+  - id: qa
+    needs: [frontend, backend]
+    when: stages.frontend.status == 'completed'
+    inputs:
+      architecture: "{{stages.design.output.architecture}}"
+`);
+
+    expect(definition.stages.map(stage => stage.needs)).toEqual([[], ['design'], ['design'], ['frontend', 'backend']]);
+  });
+
+  it('rejects needs on stages declared later or not at all, which rules out cycles', () => {
+    expect(errorsOf(`
+name: cycle
+stages:
+  - id: a
+    needs: [b]
+  - id: b
+    needs: [a]
+  - id: c
+    needs: [missing]
+`)).toEqual([
+      'stage "a" needs stage "b" which is not declared before it',
+      'stage "c" needs stage "missing" which is not declared before it'
+    ]);
+  });
+
+  it('rejects reading from a stage on a parallel branch', () => {
+    expect(errorsOf(`
+name: branches
+stages:
+  - id: design
+  - id: frontend
+    needs: [design]
+  - id: backend
+    needs: [design]
+    inputs:
+      ui: "{{stages.frontend.output.components}}"
+`)).toEqual(['stage "backend" references stage "frontend" which it does not depend on; add it to needs']);
+  });
+
+  it('rejects conditions and inputs that read unknown values or later stages', () => {
+    expect(errorsOf(`
+name: references
+stages:
+  - id: first
+    when: stages.second.output.ready
+  - id: second
+    inputs:
+      secret: "{{env.API_KEY}}"
+`)).toEqual([
+      'stage "first" references stage "second" which does not run before it',
+      'stage "second" references unknown value "env.API_KEY"'
+    ]);
+  });
+
+  it('collects every problem in one error', () => {
+    const errors = errorsOf(`
+name: Not An Identifier
+version: 1.5
+stages:
+  - id: build
+    type: deploy
+    onFailure: ignore
+    retry:
+      attempts: 11
+      backoff: linear
+      delayMs: -1
+    timeoutMs: 0
+    fallback: {}
+    outputRepairs: 6
+  - id: build
+  - name: no id
+`);
+
+    expect(errors).toEqual([
+      'name must be a lowercase identifier',
+      'version must be an integer',
+      'stage "build" type must be agent, review, secret-scan, quality-gate, preview, diagram or moderation',
+      'stage "build" onFailure must be stop or continue',
+      'stage "build" retry.attempts must be an integer from 1 to 10',
+      'stage "build" retry.backoff must be fixed or exponential',
+      'stage "build" retry.delayMs must be a non-negative integer',
+      'stage "build" timeoutMs must be a positive integer',
+      'stage "build" fallback needs an agent or a model',
+      'stage "build" outputRepairs must be an integer between 0 and 5',
+      'stage "build" is declared more than once',
+      'stage #3 needs a lowercase identifier id'
+    ]);
+  });
+
+  it('reports YAML syntax errors and the source they came from', () => {
+    expect(() => parseDefinition('name: [unclosed', 'broken.yaml')).toThrow(/^Invalid pipeline definition broken\.yaml: YAML syntax error/);
+  });
+
+  it.each([
+    ['a scalar', 'just text', 'Definition must be a YAML mapping'],
+    ['no stages', 'name: empty', 'stages must be a non-empty list'],
+    ['an empty stage list', 'name: empty\nstages: []', 'stages must be a non-empty list']
+  ])('rejects %s', (_, raw, message) => {
+    expect(errorsOf(raw)).toContain(message);
+  });
+});
@@ -0,0 +1,190 @@
+import * as yaml from 'yaml';
+import { PipelineDefinition, PipelineStageDefinition } from '../types/index.js';
+import { collectReferences, conditionPaths } from './expressions.js';
+
+const IDENTIFIER = /^[a-z][a-z0-9_-]*$/;
+
+export class DefinitionValidationError extends Error {
+  constructor(public source: string, public errors: string[]) {
+    super(`Invalid pipeline definition ${source}: ${errors.join('; ')}`);
+    this.name = 'DefinitionValidationError';
+  }
+}
+
+// Parses a YAML pipeline definition and checks the stage graph before anything runs
+export function parseDefinition(raw: string, source = 'definition'): PipelineDefinition {
+  let data: any;
+  try {
+    data = yaml.parse(raw);
+  } catch (error) {
+    throw new DefinitionValidationError(source, [`YAML syntax error: ${error instanceof Error ? error.message : error}`]);
+  }
+
+  const errors: string[] = [];
+
+  if (!data || typeof data !== 'object') {
+    throw new DefinitionValidationError(source, ['Definition must be a YAML mapping']);
+  }
+  if (typeof data.name !== 'string' || !IDENTIFIER.test(data.name)) {
+    errors.push('name must be a lowercase identifier');
+  }
+  if (data.version !== undefined && !Number.isInteger(data.version)) {
+    errors.push('version must be an integer');
+  }
+  if (!Array.isArray(data.stages) || data.stages.length === 0) {
+    errors.push('stages must be a non-empty list');
+    throw new DefinitionValidationError(source, errors);
+  }
+
+  const seen = new Set<string>();
+  // Every stage a stage transitively needs; inputs and conditions may only read from these
+  const ancestors = new Map<string, Set<string>>();
+  let previousId: string | undefined;
+
+  const stages: PipelineStageDefinition[] = data.stages.map((stage: any, index: number) => {
+    const label = stage?.id ? `stage "${stage.id}"` : `stage #${index + 1}`;
+
+    if (!stage || typeof stage !== 'object') {
+      errors.push(`${label} must be a mapping`);
+      return stage;
+    }
+    if (typeof stage.id !== 'string' || !IDENTIFIER.test(stage.id)) {
+      errors.push(`${label} needs a lowercase identifier id`);
+    } else if (seen.has(stage.id)) {
+      errors.push(`${label} is declared more than once`);
+    }
+    if (stage.type !== undefined && !['agent', 'review', 'secret-scan', 'quality-gate', 'preview', 'diagram', 'moderation'].includes(stage.type)) {
+      errors.push(`${label} type must be agent, review, secret-scan, quality-gate, preview, diagram or moderation`);
+    }
+    if (stage.agent !== undefined && typeof stage.agent !== 'string') {
+      errors.push(`${label} agent must be a role name`);
+    }
+    if (stage.model !== undefined && typeof stage.model !== 'string') {
+      errors.push(`${label} model must be a string`);
+    }
+    if (stage.inputs !== undefined && (typeof stage.inputs !== 'object' || Array.isArray(stage.inputs))) {
+      errors.push(`${label} inputs must be a mapping`);
+    }
+    if (stage.outputs !== undefined && !(Array.isArray(stage.outputs) && stage.outputs.every((o: any) => typeof o === 'string'))) {
+      errors.push(`${label} outputs must be a list of names`);
+    }
+    if (stage.when !== undefined && typeof stage.when !== 'string') {
+      errors.push(`${label} when must be an expression string`);
+    }
+    if (stage.onFailure !== undefined && !['stop', 'continue'].includes(stage.onFailure)) {
+      errors.push(`${label} onFailure must be stop or continue`);
+    }
+    if (stage.retry !== undefined) {
+      const retry = stage.retry;
+      if (!retry || typeof retry !== 'object' || Array.isArray(retry)) {
+        errors.push(`${label} retry must be a mapping`);
+      } else {
+        if (retry.attempts !== undefined && !(Number.isInteger(retry.attempts) && retry.attempts >= 1 && retry.attempts <= 10)) {
+          errors.push(`${label} retry.attempts must be an integer from 1 to 10`);
+        }
+        if (retry.backoff !== undefined && !['fixed', 'exponential'].includes(retry.backoff)) {
+          errors.push(`${label} retry.backoff must be fixed or exponential`);
+        }
+        if (retry.delayMs !== undefined && !(Number.isInteger(retry.delayMs) && retry.delayMs >= 0)) {
+          errors.push(`${label} retry.delayMs must be a non-negative integer`);
+        }
+      }
+    }
+    if (stage.timeoutMs !== undefined && !(Number.isInteger(stage.timeoutMs) && stage.timeoutMs > 0)) {
+      errors.push(`${label} timeoutMs must be a positive integer`);
+    }
+    if (stage.fallback !== undefined) {
+      const fallback = stage.fallback;
+      if (!fallback || typeof fallback !== 'object' || Array.isArray(fallback)) {
+        errors.push(`${label} fallback must be a mapping`);
+      } else if (fallback.agent === undefined && fallback.model === undefined) {
+        errors.push(`${label} fallback needs an agent or a model`);
+      } else if ((fallback.agent !== undefined && typeof fallback.agent !== 'string') || (fallback.model !== undefined && typeof fallback.model !== 'string')) {
+        errors.push(`${label} fallback agent and model must be strings`);
+      }
+    }
+    if (stage.toolScopes !== undefined && !(Array.isArray(stage.toolScopes) && stage.toolScopes.every((scope: any) => typeof scope === 'string' && scope.length > 0))) {
+      errors.push(`${label} toolScopes must be a list of scope names`);
+    }
+    if (stage.outputSchema !== undefined && (!stage.outputSchema || typeof stage.outputSchema !== 'object' || Array.isArray(stage.outputSchema))) {
+      errors.push(`${label} outputSchema must be a mapping (a JSON Schema)`);
+    }
+    if (stage.outputRepairs !== undefined && !(Number.isInteger(stage.outputRepairs) && stage.outputRepairs >= 0 && stage.outputRepairs <= 5)) {
+      errors.push(`${label} outputRepairs must be an integer between 0 and 5`);
+    }
+
+    // Without needs a stage follows the one listed before it, so linear definitions keep their
+    // order. Needs may only name stages declared earlier, which keeps the graph acyclic.
+    let needs: string[] = previousId ? [previousId] : [];
+    if (stage.needs !== undefined) {
+      if (!Array.isArray(stage.needs) || !stage.needs.every((need: any) => typeof need === 'string')) {
+        errors.push(`${label} needs must be a list of stage ids`);
+      } else {
+        needs = Array.from(new Set<string>(stage.needs));
+        for (const need of needs) {
+          if (!seen.has(need)) errors.push(`${label} needs stage "${need}" which is not declared before it`);
+        }
+      }
+    }
+
+    const upstream = new Set<string>();
+    for (const need of needs) {
+      upstream.add(need);
+      ancestors.get(need)?.forEach(ancestor => upstream.add(ancestor));
+    }
+
+    // Inputs and conditions may only read from the pipeline or stages this one depends on; a
+    // stage on a parallel branch may not have finished yet
+    const references = [
+      ...collectReferences(stage.inputs),
+      ...(typeof stage.when === 'string' ? conditionPaths(stage.when) : [])
+    ];
+    for (const reference of references) {
+      const [root, stageId] = reference.split('.');
+      if (root === 'pipeline') continue;
+      if (root !== 'stages') {
+        errors.push(`${label} references unknown value "${reference}"`);
+      } else if (!seen.has(stageId)) {
+        errors.push(`${label} references stage "${stageId}" which does not run before it`);
+      } else if (!upstream.has(stageId)) {
+        errors.push(`${label} references stage "${stageId}" which it does not depend on; add it to needs`);
+      }
+    }
+
+    if (typeof stage.id === 'string') {
+      seen.add(stage.id);
+      ancestors.set(stage.id, upstream);
+      previousId = stage.id;
+    }
+
+    return {
+      id: stage.id,
+      name: stage.name || stage.id,
+      type: stage.type,
+      agent: stage.agent,
+      model: stage.model,
+      inputs: stage.inputs,
+      outputs: stage.outputs,
+      when: stage.when,
+      needs,
+      onFailure: stage.onFailure,
+      retry: stage.retry,
+      timeoutMs: stage.timeoutMs,
+      fallback: stage.fallback,
+      toolScopes: stage.toolScopes,
+      outputSchema: stage.outputSchema,
+      outputRepairs: stage.outputRepairs
+    };
+  });
+
+  if (errors.length > 0) {
+    throw new DefinitionValidationError(source, errors);
+  }
+
+  return {
+    name: data.name,
+    description: data.description,
+    version: data.version || 1,
+    stages
+  };
+}
@@ -0,0 +1,62 @@
+import * as path from 'path';
+import { promises as fs } from 'fs';
+import { MLPipelineStage, PipelineDefinition } from '../types/index.js';
+import { parseDefinition } from './DefinitionParser.js';
+
+export class DefinitionRegistry {
+  private definitions: Map<string, PipelineDefinition> = new Map();
+
+  constructor(private definitionsDir: string) {}
+
+  async load(): Promise<void> {
+    const entries = await fs.readdir(this.definitionsDir).catch(() => [] as string[]);
+    const definitions: Map<string, PipelineDefinition> = new Map();
+
+    for (const entry of entries) {
+      if (!entry.endsWith('.yaml') && !entry.endsWith('.yml')) continue;
+
+      const raw = await fs.readFile(path.join(this.definitionsDir, entry), 'utf-8');
+      const definition = parseDefinition(raw, entry);
+      definitions.set(definition.name, definition);
+    }
+
+    this.definitions = definitions;
+  }
+
+  register(definition: PipelineDefinition): void {
+    this.definitions.set(definition.name, definition);
+  }
+
+  get(name: string): PipelineDefinition | undefined {
+    return this.definitions.get(name);
+  }
+
+  list(): PipelineDefinition[] {
+    return Array.from(this.definitions.values());
+  }
+
+  // Expands a definition into the runtime stage list used by PipelineService
+  buildStages(definition: PipelineDefinition): MLPipelineStage[] {
+    return definition.stages.map(stage => ({
+      id: stage.id,
+      name: stage.name,
+      status: 'idle',
+      logs: [],
+      outputs: {},
+      artifacts: [],
+      kind: stage.type,
+      agentRole: stage.agent,
+      model: stage.model,
+      inputs: stage.inputs,
+      condition: stage.when,
+      needs: stage.needs,
+      onFailure: stage.onFailure,
+      retry: stage.retry,
+      timeoutMs: stage.timeoutMs,
+      fallback: stage.fallback,
+      toolScopes: stage.toolScopes,
+      outputSchema: stage.outputSchema,
+      outputRepairs: stage.outputRepairs
+    }));
+  }
+}
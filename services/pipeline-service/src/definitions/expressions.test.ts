@@ -0,0 +1,100 @@
+import {
+  StageContext,
+  collectReferences,
+  conditionPaths,
+  evaluateCondition,
+  resolveInputs,
+  resolvePath
+} from './expressions.js';
+
+const context: StageContext = {
+  pipeline: { name: 'delivery', modelConfig: { temperature: 0.2 } },
+  stages: {
+    requirements: { status: 'completed', output: { summary: 'A todo app', stories: ['add', 'remove'], approved: true, count: 3 } },
+    review: { status: 'skipped', output: null }
+  }
+};
+
+describe('resolvePath', () => {
+  it('walks nested values', () => {
+    expect(resolvePath(context, 'stages.requirements.output.summary')).toBe('A todo app');
+    expect(resolvePath(context, 'pipeline.modelConfig.temperature')).toBe(0.2);
+  });
+
+  it('is undefined past a missing or null value', () => {
+    expect(resolvePath(context, 'stages.design.output')).toBeUndefined();
+    expect(resolvePath(context, 'stages.review.output.findings')).toBeUndefined();
+  });
+});
+
+describe('collectReferences', () => {
+  it('finds placeholders in nested objects and arrays', () => {
+    expect(collectReferences({
+      prompt: 'Build {{ pipeline.name }} from {{stages.requirements.output.summary}}',
+      extra: [{ stories: '{{stages.requirements.output.stories}}' }, 42, null]
+    })).toEqual([
+      'pipeline.name',
+      'stages.requirements.output.summary',
+      'stages.requirements.output.stories'
+    ]);
+  });
+
+  it('finds nothing in plain values', () => {
+    expect(collectReferences('no placeholders')).toEqual([]);
+    expect(collectReferences(undefined)).toEqual([]);
+  });
+});
+
+describe('resolveInputs', () => {
+  it('keeps the type of a value that is a single placeholder', () => {
+    expect(resolveInputs({ stories: '{{ stages.requirements.output.stories }}' }, context))
+      .toEqual({ stories: ['add', 'remove'] });
+  });
+
+  it('substitutes placeholders inside text, serialising objects', () => {
+    expect(resolveInputs('Build {{pipeline.name}} with {{stages.requirements.output.stories}}', context))
+      .toBe('Build delivery with ["add","remove"]');
+  });
+
+  it('substitutes missing values inside text with nothing', () => {
+    expect(resolveInputs('Findings: {{stages.review.output.findings}}.', context)).toBe('Findings: .');
+  });
+
+  it('resolves inside arrays and leaves other values alone', () => {
+    expect(resolveInputs(['{{pipeline.name}}', 7, true, null], context)).toEqual(['delivery', 7, true, null]);
+  });
+});
+
+describe('conditionPaths', () => {
+  it('returns the path a condition reads', () => {
+    expect(conditionPaths('stages.requirements.output.approved')).toEqual(['stages.requirements.output.approved']);
+    expect(conditionPaths(' !stages.review.output ')).toEqual(['stages.review.output']);
+    expect(conditionPaths('stages.review.status == "skipped"')).toEqual(['stages.review.status']);
+  });
+});
+
+describe('evaluateCondition', () => {
+  it('tests a path for truthiness', () => {
+    expect(evaluateCondition('stages.requirements.output.approved', context)).toBe(true);
+    expect(evaluateCondition('stages.review.output', context)).toBe(false);
+    expect(evaluateCondition('stages.design.output', context)).toBe(false);
+  });
+
+  it('negates with !', () => {
+    expect(evaluateCondition('!stages.review.output', context)).toBe(true);
+    expect(evaluateCondition('! stages.requirements.output.approved', context)).toBe(false);
+  });
+
+  it('compares with string, number, boolean and null literals', () => {
+    expect(evaluateCondition('stages.review.status == "skipped"', context)).toBe(true);
+    expect(evaluateCondition("stages.review.status != 'skipped'", context)).toBe(false);
+    expect(evaluateCondition('stages.requirements.output.count == 3', context)).toBe(true);
+    expect(evaluateCondition('stages.requirements.output.count == "3"', context)).toBe(false);
+    expect(evaluateCondition('stages.requirements.output.approved == true', context)).toBe(true);
+    expect(evaluateCondition('stages.review.output == null', context)).toBe(true);
+  });
+
+  it('treats an unquoted word as a string', () => {
+    expect(evaluateCondition('stages.requirements.status == completed', context)).toBe(true);
+  });
+});
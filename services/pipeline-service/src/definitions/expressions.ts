@@ -0,0 +1,87 @@
+// Runtime values visible to stage inputs and `when` conditions
+export interface StageContext {
+  pipeline: Record<string, any>;
+  stages: Record<string, { status: string; output: any }>;
+}
+
+const PLACEHOLDER = /\{\{\s*([\w.]+)\s*\}\}/g;
+const WHOLE_PLACEHOLDER = /^\{\{\s*([\w.]+)\s*\}\}$/;
+const COMPARISON = /^(.+?)\s*(==|!=)\s*(.+)$/;
+
+export function resolvePath(context: Record<string, any>, path: string): any {
+  return path.split('.').reduce<any>((current, part) => {
+    return current !== undefined && current !== null ? current[part] : undefined;
+  }, context);
+}
+
+// Lists every {{path}} referenced by a value, walking nested objects and arrays
+export function collectReferences(value: any): string[] {
+  if (typeof value === 'string') {
+    return Array.from(value.matchAll(PLACEHOLDER), match => match[1]);
+  }
+  if (Array.isArray(value)) {
+    return value.flatMap(collectReferences);
+  }
+  if (value && typeof value === 'object') {
+    return Object.values(value).flatMap(collectReferences);
+  }
+  return [];
+}
+
+// Substitutes {{path}} placeholders. A value that is exactly one placeholder keeps its original type.
+export function resolveInputs(value: any, context: StageContext): any {
+  if (typeof value === 'string') {
+    const whole = value.match(WHOLE_PLACEHOLDER);
+    if (whole) return resolvePath(context, whole[1]);
+
+    return value.replace(PLACEHOLDER, (match, path: string) => {
+      const resolved = resolvePath(context, path);
+      if (resolved === undefined || resolved === null) return '';
+      return typeof resolved === 'string' ? resolved : JSON.stringify(resolved);
+    });
+  }
+  if (Array.isArray(value)) {
+    return value.map(item => resolveInputs(item, context));
+  }
+  if (value && typeof value === 'object') {
+    return Object.fromEntries(
+      Object.entries(value).map(([key, item]) => [key, resolveInputs(item, context)])
+    );
+  }
+  return value;
+}
+
+// Returns the context paths a condition reads, for validation
+export function conditionPaths(expression: string): string[] {
+  const trimmed = expression.trim();
+  const comparison = trimmed.match(COMPARISON);
+  const path = comparison ? comparison[1].trim() : trimmed.replace(/^!/, '').trim();
+  return [path];
+}
+
+// Evaluates `path`, `!path`, `path == literal` or `path != literal`
+export function evaluateCondition(expression: string, context: StageContext): boolean {
+  const trimmed = expression.trim();
+  const comparison = trimmed.match(COMPARISON);
+
+  if (comparison) {
+    const actual = resolvePath(context, comparison[1].trim());
+    const expected = parseLiteral(comparison[3].trim());
+    return comparison[2] === '==' ? actual === expected : actual !== expected;
+  }
+
+  if (trimmed.startsWith('!')) {
+    return !resolvePath(context, trimmed.slice(1).trim());
+  }
+
+  return Boolean(resolvePath(context, trimmed));
+}
+
+function parseLiteral(literal: string): any {
+  if (/^(['"]).*\1$/.test(literal)) return literal.slice(1, -1);
+  if (literal === 'true') return true;
+  if (literal === 'false') return false;
+  if (literal === 'null') return null;
+  if (!isNaN(Number(literal))) return Number(literal);
+  return literal;
+}
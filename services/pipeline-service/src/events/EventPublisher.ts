@@ -0,0 +1,74 @@
+import axios from 'axios';
+import { randomUUID } from 'crypto';
+import { DomainEvent, MessageBus } from './bus/MessageBus.js';
+
+// Attribution carried on every event as CloudEvents extension attributes
+export interface EventContext {
+  projectId?: string;
+  tenantId?: string;
+  requestId?: string;
+}
+
+// Forwards pipeline lifecycle events to the webhook and notification services and, when one is
+// configured, to the message bus. Every destination receives the same CloudEvents envelope.
+// Delivery problems are logged and never affect the run.
+export class EventPublisher {
+  private webhookServiceUrl?: string;
+  private notificationServiceUrl?: string;
+
+  constructor(private bus?: MessageBus) {
+    this.webhookServiceUrl = process.env.WEBHOOK_SERVICE_URL;
+    this.notificationServiceUrl = process.env.NOTIFICATION_SERVICE_URL;
+  }
+
+  publish(type: string, data: Record<string, any>, context: EventContext = {}): void {
+    const event = this.envelope(type, data, context);
+    if (this.webhookServiceUrl) {
+      this.post(`${this.webhookServiceUrl}/api/events`, event);
+    }
+    if (this.notificationServiceUrl) {
+      this.post(`${this.notificationServiceUrl}/api/notifications/events`, event);
+    }
+    if (this.bus) {
+      this.publishToBus(event);
+    }
+  }
+
+  // Stage transitions and log lines go to the bus only; they are too chatty for webhooks
+  progress(data: Record<string, any>, context: EventContext = {}): void {
+    if (this.bus) {
+      this.publishToBus(this.envelope('pipeline.progress', data, context));
+    }
+  }
+
+  private envelope(type: string, data: Record<string, any>, context: EventContext): DomainEvent {
+    return {
+      specversion: '1.0',
+      id: randomUUID(),
+      type,
+      source: '/pipeline-service',
+      time: new Date().toISOString(),
+      subject: data.pipelineId,
+      datacontenttype: 'application/json',
+      tenantid: context.tenantId,
+      requestid: context.requestId,
+      projectid: context.projectId,
+      data
+    };
+  }
+
+  private publishToBus(event: DomainEvent): void {
+    this.bus!.publish(event)
+      .catch(error => console.error(`Failed to publish ${event.type} event to ${this.bus!.name}:`, error.message));
+  }
+
+  private post(url: string, event: DomainEvent): void {
+    axios.post(url, event, {
+      headers: {
+        'Content-Type': 'application/cloudevents+json',
+        'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || ''
+      },
+      timeout: 5000
+    }).catch(error => console.error(`Failed to publish ${event.type} event:`, error.message));
+  }
+}
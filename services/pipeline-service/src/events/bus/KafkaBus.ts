@@ -0,0 +1,47 @@
+import { Admin, Kafka, Producer } from 'kafkajs';
+import { DomainEvent, MessageBus } from './MessageBus.js';
+
+// Publishes all events to one topic, keyed by project so each project's events stay ordered.
+// Messages use the CloudEvents Kafka binding in structured mode.
+export class KafkaBus implements MessageBus {
+  readonly name = 'kafka';
+  private kafka: Kafka;
+  private producer: Producer;
+  // Opened by the first health check
+  private admin?: Admin;
+  private topic: string;
+
+  constructor(brokers: string = process.env.KAFKA_BROKERS || 'localhost:9092') {
+    this.kafka = new Kafka({ clientId: 'pipeline-service', brokers: brokers.split(',') });
+    this.producer = this.kafka.producer();
+    this.topic = process.env.EVENT_BUS_TOPIC || 'ai-pipeline.events';
+  }
+
+  async connect(): Promise<void> {
+    await this.producer.connect();
+  }
+
+  async publish(event: DomainEvent): Promise<void> {
+    await this.producer.send({
+      topic: this.topic,
+      messages: [{
+        key: event.projectid || event.subject || event.id,
+        value: JSON.stringify(event),
+        headers: { 'content-type': 'application/cloudevents+json; charset=UTF-8', type: event.type }
+      }]
+    });
+  }
+
+  async ping(): Promise<void> {
+    if (!this.admin) {
+      this.admin = this.kafka.admin();
+      await this.admin.connect();
+    }
+    await this.admin.describeCluster();
+  }
+
+  async close(): Promise<void> {
+    await this.admin?.disconnect();
+    await this.producer.disconnect();
+  }
+}
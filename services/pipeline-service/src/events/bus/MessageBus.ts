@@ -0,0 +1,27 @@
+// CloudEvents 1.0 envelope (structured JSON mode) used for every event pipeline-service emits, on
+// the bus and to webhook-service and notification-service. Types and data: docs/events.md
+export interface DomainEvent {
+  specversion: '1.0';
+  id: string;
+  type: string;
+  source: string;
+  time: string;
+  // The run the event is about
+  subject?: string;
+  datacontenttype: 'application/json';
+  // Extension attributes: the account the run belongs to, the request that started it, its project
+  tenantid?: string;
+  requestid?: string;
+  projectid?: string;
+  data: Record<string, any>;
+}
+
+// Pluggable transport for domain events so downstream systems can subscribe instead of polling
+export interface MessageBus {
+  readonly name: string;
+  connect(): Promise<void>;
+  publish(event: DomainEvent): Promise<void>;
+  // Round trip to the broker, for health checks; rejects when it cannot be reached
+  ping(): Promise<void>;
+  close(): Promise<void>;
+}
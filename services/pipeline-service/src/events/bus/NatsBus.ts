@@ -0,0 +1,32 @@
+import { connect, JSONCodec, NatsConnection } from 'nats';
+import { DomainEvent, MessageBus } from './MessageBus.js';
+
+// Publishes each event on its own subject, e.g. ai-pipeline.stage.completed
+export class NatsBus implements MessageBus {
+  readonly name = 'nats';
+  private connection?: NatsConnection;
+  private codec = JSONCodec<DomainEvent>();
+  private subjectPrefix: string;
+
+  constructor(private servers: string = process.env.NATS_URL || 'nats://localhost:4222') {
+    this.subjectPrefix = process.env.EVENT_BUS_PREFIX || 'ai-pipeline';
+  }
+
+  async connect(): Promise<void> {
+    this.connection = await connect({ servers: this.servers.split(','), name: 'pipeline-service' });
+  }
+
+  async publish(event: DomainEvent): Promise<void> {
+    if (!this.connection) throw new Error('NATS connection is not open');
+    this.connection.publish(`${this.subjectPrefix}.${event.type}`, this.codec.encode(event));
+  }
+
+  async ping(): Promise<void> {
+    if (!this.connection || this.connection.isClosed()) throw new Error('NATS connection is not open');
+    await this.connection.flush();
+  }
+
+  async close(): Promise<void> {
+    await this.connection?.drain();
+  }
+}
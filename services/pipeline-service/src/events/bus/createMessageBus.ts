@@ -0,0 +1,19 @@
+import { MessageBus } from './MessageBus.js';
+import { NatsBus } from './NatsBus.js';
+import { KafkaBus } from './KafkaBus.js';
+
+// Selects the bus from EVENT_BUS (nats | kafka); returns undefined when publishing is disabled
+export function createMessageBus(kind: string | undefined = process.env.EVENT_BUS): MessageBus | undefined {
+  switch (kind) {
+    case 'nats':
+      return new NatsBus();
+    case 'kafka':
+      return new KafkaBus();
+    case undefined:
+    case '':
+    case 'none':
+      return undefined;
+    default:
+      throw new Error(`Unknown EVENT_BUS "${kind}" (expected nats, kafka or none)`);
+  }
+}
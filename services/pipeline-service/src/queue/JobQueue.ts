@@ -0,0 +1,207 @@
+import Queue, { Job } from 'bull';
+import { DeadLetterJob, JobResult, PipelineJob } from '../types/index.js';
+import { StageRunOptions } from './StageExecutor.js';
+
+export interface JobQueueOptions {
+  attempts: number;
+  backoffDelayMs: number;
+  concurrency: number;
+}
+
+// Workers get a signal that aborts when the orchestrator cancels the job, and a sink for agent
+// output that is relayed to the orchestrator as job progress
+export type JobHandler = (job: Job<PipelineJob>, options: Required<StageRunOptions>) => Promise<JobResult>;
+
+const STAGE_QUEUE = 'pipeline-stages';
+const DEAD_LETTER_QUEUE = 'pipeline-stages-dead-letter';
+const CANCEL_CHANNEL = `${STAGE_QUEUE}:cancel`;
+// Agent output is batched into one progress update per interval rather than one per token
+const TOKEN_FLUSH_MS = parseInt(process.env.STAGE_TOKEN_FLUSH_MS || '100');
+
+export function loadQueueOptions(): JobQueueOptions {
+  return {
+    attempts: parseInt(process.env.STAGE_MAX_ATTEMPTS || '3'),
+    backoffDelayMs: parseInt(process.env.STAGE_BACKOFF_DELAY_MS || '5000'),
+    concurrency: parseInt(process.env.STAGE_WORKER_CONCURRENCY || '2')
+  };
+}
+
+// Redis-backed stage queue. Jobs that exhaust their retries are moved to a dead-letter queue.
+export class JobQueue {
+  private queue: Queue.Queue<PipelineJob>;
+  private deadLetter: Queue.Queue<DeadLetterJob>;
+  // Orchestrator side: token sinks of jobs this process is waiting on
+  private tokenSinks: Map<string, (text: string) => void> = new Map();
+  // Worker side: jobs running in this process, to abort on cancel
+  private active: Map<string, AbortController> = new Map();
+  private cancelSubscriber?: Queue.Queue['client'];
+
+  constructor(private options: JobQueueOptions = loadQueueOptions()) {
+    const redis = {
+      host: process.env.REDIS_HOST || 'localhost',
+      port: parseInt(process.env.REDIS_PORT || '6379'),
+      password: process.env.REDIS_PASSWORD || undefined
+    };
+
+    this.queue = new Queue<PipelineJob>(STAGE_QUEUE, {
+      redis,
+      defaultJobOptions: {
+        attempts: options.attempts,
+        backoff: { type: 'exponential', delay: options.backoffDelayMs },
+        removeOnComplete: 500,
+        removeOnFail: 500
+      }
+    });
+    this.deadLetter = new Queue<DeadLetterJob>(DEAD_LETTER_QUEUE, { redis });
+
+    // Orchestrated jobs are one attempt each, so the queue's own count says nothing about whether
+    // the stage will be tried again; those are dead-lettered only on the orchestrator's last attempt
+    this.queue.on('failed', (job, error) => {
+      const exhausted = job.data.lastAttempt ?? job.attemptsMade >= (job.opts.attempts || 1);
+      if (exhausted) {
+        this.deadLetter.add({
+          job: job.data,
+          error: error.message,
+          attempts: job.data.attempt || job.attemptsMade,
+          failedAt: new Date().toISOString()
+        }, { jobId: String(job.id) }).catch(err => console.error('Dead-letter enqueue failed:', err));
+      }
+    });
+
+    this.queue.on('global:progress', (jobId: string | number, progress: any) => {
+      const data = typeof progress === 'string' ? JSON.parse(progress) : progress;
+      if (data?.tokens) this.tokenSinks.get(String(jobId))?.(data.tokens);
+    });
+  }
+
+  // Registers a worker for this process; concurrency bounds in-flight stages per process
+  process(handler: JobHandler): void {
+    this.subscribeToCancels();
+
+    this.queue.process(this.options.concurrency, async job => {
+      const jobId = String(job.id);
+      const abort = new AbortController();
+      this.active.set(jobId, abort);
+
+      let pending = '';
+      let timer: NodeJS.Timeout | undefined;
+      const flush = () => {
+        timer = undefined;
+        if (!pending) return;
+        const tokens = pending;
+        pending = '';
+        job.progress({ tokens }).catch(() => undefined);
+      };
+
+      try {
+        return {
+          ...(await handler(job, {
+            signal: abort.signal,
+            onToken: text => {
+              pending += text;
+              timer ??= setTimeout(flush, TOKEN_FLUSH_MS);
+            }
+          })),
+          attempts: job.attemptsMade + 1
+        };
+      } finally {
+        clearTimeout(timer);
+        flush();
+        this.active.delete(jobId);
+      }
+    });
+  }
+
+  async enqueue(job: PipelineJob): Promise<Job<PipelineJob>> {
+    return this.queue.add(job, { jobId: job.id });
+  }
+
+  // Enqueues one attempt of a stage and waits for a worker (in this or another process) to finish
+  // it. The orchestrator applies the stage's retry policy, so the queue does not retry these.
+  // Agent output the worker streams is passed to onToken. Aborting the signal cancels the job:
+  // a waiting job is removed, a running one is aborted on its worker.
+  async run(job: PipelineJob, timeoutMs?: number, options: StageRunOptions = {}): Promise<JobResult> {
+    const { onToken, signal } = options;
+    signal?.throwIfAborted();
+    if (onToken) this.tokenSinks.set(job.id, onToken);
+
+    try {
+      const queued = await this.queue.add(job, { jobId: job.id, attempts: 1, timeout: timeoutMs });
+      if (!signal) return await queued.finished();
+
+      let onAbort: (() => void) | undefined;
+      const cancelled = new Promise<never>((_, reject) => {
+        onAbort = () => {
+          this.cancel(job.id);
+          queued.remove().catch(() => undefined);
+          reject(new Error('Stage cancelled'));
+        };
+        signal.addEventListener('abort', onAbort, { once: true });
+      });
+      try {
+        return await Promise.race([queued.finished(), cancelled]);
+      } finally {
+        signal.removeEventListener('abort', onAbort!);
+      }
+    } finally {
+      this.tokenSinks.delete(job.id);
+    }
+  }
+
+  // Tells whichever worker is running the job to abort it
+  cancel(jobId: string): void {
+    this.queue.client.publish(CANCEL_CHANNEL, jobId).catch(error => console.error('Job cancel publish failed:', error));
+  }
+
+  private subscribeToCancels(): void {
+    if (this.cancelSubscriber) return;
+    // A dedicated connection, since a subscribed Redis connection cannot run other commands
+    this.cancelSubscriber = this.queue.client.duplicate();
+    this.cancelSubscriber.subscribe(CANCEL_CHANNEL).catch(error => console.error('Job cancel subscribe failed:', error));
+    this.cancelSubscriber.on('message', (channel: string, jobId: string) => {
+      if (channel === CANCEL_CHANNEL) this.active.get(jobId)?.abort();
+    });
+  }
+
+  async getStats(): Promise<Record<string, any>> {
+    const [counts, deadLetter] = await Promise.all([
+      this.queue.getJobCounts(),
+      this.deadLetter.getWaitingCount()
+    ]);
+
+    return {
+      ...counts,
+      deadLetter,
+      concurrency: this.options.concurrency,
+      maxAttempts: this.options.attempts
+    };
+  }
+
+  async listDeadLetters(start = 0, end = 49): Promise<Array<DeadLetterJob & { id: string }>> {
+    const jobs = await this.deadLetter.getWaiting(start, end);
+    return jobs.map(job => ({ id: String(job.id), ...job.data }));
+  }
+
+  async getDeadLetter(id: string): Promise<DeadLetterJob | null> {
+    const dead = await this.deadLetter.getJob(id);
+    return dead ? dead.data : null;
+  }
+
+  async removeDeadLetter(id: string): Promise<void> {
+    const dead = await this.deadLetter.getJob(id);
+    if (dead) await dead.remove();
+  }
+
+  get maxAttempts(): number {
+    return this.options.attempts;
+  }
+
+  async ping(): Promise<void> {
+    await this.queue.client.ping();
+  }
+
+  async close(): Promise<void> {
+    this.cancelSubscriber?.disconnect();
+    await Promise.all([this.queue.close(), this.deadLetter.close()]);
+  }
+}
@@ -0,0 +1,610 @@
+import axios from 'axios';
+import { JobResult, LLMUsage, PipelineJob, StageErrorCode } from '../types/index.js';
+import { sumUsage } from '../services/CostReport.js';
+
+// Messages prefixed with WARN_PREFIX are surfaced to clients as warnings
+export type StageLogger = (message: string) => void;
+
+export const WARN_PREFIX = 'WARN: ';
+
+export interface StageRunOptions {
+  // Agent output as it is generated; agent stages stream when this is set
+  onToken?: (text: string) => void;
+  // Aborting cancels the agent call, and with it the LLM request behind it, and stops waiting on a preview build
+  signal?: AbortSignal;
+}
+
+type GeneratedFile = { path: string; content: string };
+
+const OUTPUT_INVALID_PREFIX = 'Agent output failed validation';
+
+// The agent kept answering with output that does not parse or match the stage's schema, even
+// after the agent service's repair prompts
+export class AgentOutputError extends Error {
+  constructor(message: string, public validationErrors: string[] = [], public repairs = 0) {
+    // The agent service's message already starts with the prefix
+    super(message.startsWith(OUTPUT_INVALID_PREFIX) ? message : `${OUTPUT_INVALID_PREFIX}: ${message}`);
+    this.name = 'AgentOutputError';
+  }
+}
+
+// Errors from queue workers arrive as plain messages, so the prefix is checked as well
+export function stageErrorCode(error: unknown): StageErrorCode | undefined {
+  if (error instanceof AgentOutputError) return 'output_invalid';
+  if (error instanceof Error && error.message.startsWith(OUTPUT_INVALID_PREFIX)) return 'output_invalid';
+  return undefined;
+}
+
+function agentOutputError(result: any): AgentOutputError {
+  return new AgentOutputError(result.error || OUTPUT_INVALID_PREFIX, result.validationErrors, result.repairs);
+}
+
+// Runs a single stage: agent-backed stages go to the agent service, review, secret-scan,
+// quality-gate, preview, diagram and moderation stages to their services, the rest are simulated
+export class StageExecutor {
+  private agentServiceUrl: string;
+  private reviewServiceUrl: string;
+  private secretScanServiceUrl: string;
+  private qualityGateServiceUrl: string;
+  private previewServiceUrl: string;
+  private diagramServiceUrl: string;
+  private moderationServiceUrl: string;
+
+  constructor(
+    agentServiceUrl?: string,
+    reviewServiceUrl?: string,
+    secretScanServiceUrl?: string,
+    qualityGateServiceUrl?: string,
+    previewServiceUrl?: string,
+    diagramServiceUrl?: string,
+    moderationServiceUrl?: string
+  ) {
+    this.agentServiceUrl = agentServiceUrl || process.env.AGENT_SERVICE_URL || 'http://localhost:3005';
+    this.reviewServiceUrl = reviewServiceUrl || process.env.REVIEW_SERVICE_URL || 'http://localhost:3011';
+    this.secretScanServiceUrl = secretScanServiceUrl || process.env.SECRET_SCAN_SERVICE_URL || 'http://localhost:3016';
+    this.qualityGateServiceUrl = qualityGateServiceUrl || process.env.QUALITY_GATE_SERVICE_URL || 'http://localhost:3017';
+    this.previewServiceUrl = previewServiceUrl || process.env.PREVIEW_SERVICE_URL || 'http://localhost:3018';
+    this.diagramServiceUrl = diagramServiceUrl || process.env.DIAGRAM_SERVICE_URL || 'http://localhost:3019';
+    this.moderationServiceUrl = moderationServiceUrl || process.env.MODERATION_SERVICE_URL || 'http://localhost:3020';
+  }
+
+  async execute(job: PipelineJob, log: StageLogger, options: StageRunOptions = {}): Promise<JobResult> {
+    const logs: string[] = [];
+    const record: StageLogger = (message) => {
+      logs.push(message);
+      log(message);
+    };
+
+    if (job.stage.kind === 'review') {
+      const output = await this.executeReviewStage(job, record);
+      return { success: true, output, logs };
+    }
+
+    if (job.stage.kind === 'secret-scan') {
+      const output = await this.executeSecretScanStage(job, record);
+      return { success: true, output, logs };
+    }
+
+    if (job.stage.kind === 'quality-gate') {
+      const output = await this.executeQualityGateStage(job, record, options);
+      return { success: true, output, logs };
+    }
+
+    if (job.stage.kind === 'preview') {
+      const output = await this.executePreviewStage(job, record, options);
+      return { success: true, output, logs };
+    }
+
+    if (job.stage.kind === 'diagram') {
+      const output = await this.executeDiagramStage(job, record);
+      return { success: true, output, logs };
+    }
+
+    if (job.stage.kind === 'moderation') {
+      const output = await this.executeModerationStage(job, record);
+      return { success: true, output, logs };
+    }
+
+    if (job.stage.agentRole) {
+      const output = await this.executeAgentStage(job, record, options);
+      return { success: true, output, logs };
+    }
+
+    await this.simulateStageExecution(job, record);
+    return { success: true, output: { status: 'completed', timestamp: new Date() }, logs };
+  }
+
+  private async executeAgentStage(job: PipelineJob, log: StageLogger, options: StageRunOptions): Promise<any> {
+    const { stage, config } = job;
+    log(`${stage.name}: Invoking ${stage.agentRole} agent${stage.model ? ` (${stage.model})` : ''}...`);
+
+    const result = await this.callAgent(stage.agentRole!, job, {
+      projectName: config.name,
+      description: config.description,
+      ...stage.inputs
+    }, undefined, options);
+
+    log(`${stage.name}: Agent finished in ${result.durationMs}ms`);
+    return result;
+  }
+
+  private async callAgent(
+    role: string,
+    job: PipelineJob,
+    inputs: Record<string, any>,
+    files?: Record<string, string>,
+    options: StageRunOptions = {}
+  ): Promise<any> {
+    const request = {
+      runId: job.pipelineId,
+      stageId: job.stage.id,
+      projectId: job.config.projectId,
+      inputs,
+      previousOutputs: job.previousOutputs || {},
+      files,
+      modelOverride: job.stage.model,
+      ...(job.stage.toolScopes ? { toolScopes: job.stage.toolScopes } : {}),
+      ...(job.stage.outputSchema ? { outputSchema: job.stage.outputSchema } : {}),
+      ...(job.stage.outputRepairs !== undefined ? { outputRepairs: job.stage.outputRepairs } : {})
+    };
+
+    try {
+      if (options.onToken) {
+        return await this.streamAgent(role, request, options.onToken, options.signal);
+      }
+
+      const response = await axios.post(`${this.agentServiceUrl}/api/agents/${role}/execute`, request, {
+        headers: { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' },
+        timeout: parseInt(process.env.AGENT_TIMEOUT_MS || '600000'),
+        signal: options.signal
+      });
+
+      return response.data.data;
+    } catch (error) {
+      if (options.signal?.aborted) {
+        throw new Error('Stage cancelled');
+      }
+      if (axios.isAxiosError(error) && error.response?.data?.data?.errorType === 'output_invalid') {
+        throw agentOutputError(error.response.data.data);
+      }
+      if (axios.isAxiosError(error) && error.response?.data?.error) {
+        throw new Error(`Agent stage failed: ${error.response.data.error}`);
+      }
+      throw error;
+    }
+  }
+
+  // Streaming ExecuteStage: the agent service answers with newline-delimited JSON, token lines
+  // while the agent writes and a final result or error line
+  private async streamAgent(role: string, request: Record<string, any>, onToken: (text: string) => void, signal?: AbortSignal): Promise<any> {
+    const response = await axios.post(`${this.agentServiceUrl}/api/agents/${role}/execute`, { ...request, stream: true }, {
+      headers: { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' },
+      timeout: parseInt(process.env.AGENT_TIMEOUT_MS || '600000'),
+      responseType: 'stream',
+      signal
+    });
+
+    let buffer = '';
+    for await (const chunk of response.data as AsyncIterable<Buffer>) {
+      buffer += chunk.toString();
+      let newline: number;
+      while ((newline = buffer.indexOf('\n')) >= 0) {
+        const line = buffer.slice(0, newline).trim();
+        buffer = buffer.slice(newline + 1);
+        if (!line) continue;
+
+        const message = JSON.parse(line);
+        if (message.type === 'token') {
+          onToken(message.text);
+        } else if (message.type === 'result') {
+          // Same contract as the buffered call: a parse failure comes back as a failed result
+          if (message.data.errorType === 'output_invalid') {
+            throw agentOutputError(message.data);
+          }
+          if (message.data.status !== 'completed') {
+            throw new Error(`Agent stage failed: ${message.data.error || 'agent output could not be used'}`);
+          }
+          return message.data;
+        } else if (message.type === 'error') {
+          throw new Error(`Agent stage failed: ${message.error}`);
+        }
+      }
+    }
+
+    throw new Error('Agent stage failed: the agent stream ended without a result');
+  }
+
+  // Reviews the most recent generated files; blocking findings fail the stage so the run is not promoted
+  private async executeReviewStage(job: PipelineJob, log: StageLogger): Promise<any> {
+    const { stage, config } = job;
+    const files = this.latestFiles(job);
+
+    if (!files || files.length === 0) {
+      throw new Error('Review stage has no generated files to review');
+    }
+
+    log(`${stage.name}: Reviewing ${files.length} files...`);
+
+    let review: any;
+    try {
+      const response = await axios.post(`${this.reviewServiceUrl}/api/reviews`, {
+        files,
+        projectId: config.projectId,
+        runId: job.pipelineId,
+        stageId: stage.id,
+        context: config.description,
+        model: stage.model
+      }, {
+        headers: { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' },
+        timeout: parseInt(process.env.REVIEW_TIMEOUT_MS || '300000')
+      });
+      review = response.data.data;
+    } catch (error) {
+      if (axios.isAxiosError(error) && error.response?.data?.error) {
+        throw new Error(`Review stage failed: ${error.response.data.error}`);
+      }
+      throw error;
+    }
+
+    log(`${review.findings.length > 0 && !review.blocking ? WARN_PREFIX : ''}${stage.name}: ${review.findings.length} findings - ${review.summary}`);
+
+    if (review.blocking) {
+      throw new Error(`Promotion blocked: review ${review._id} has blocking findings`);
+    }
+
+    return {
+      reviewId: review._id,
+      summary: review.summary,
+      counts: review.counts,
+      findings: review.findings,
+      model: review.model,
+      usage: review.usage
+    };
+  }
+
+  // Scans the most recent generated files for leaked credentials. In the default "fail" mode a leak
+  // fails the stage; with inputs.onLeak "quarantine" the leaking files are withheld from later stages.
+  private async executeSecretScanStage(job: PipelineJob, log: StageLogger): Promise<any> {
+    const { stage, config } = job;
+    const files = this.latestFiles(job);
+
+    if (!files || files.length === 0) {
+      throw new Error('Secret scan stage has no generated files to scan');
+    }
+
+    log(`${stage.name}: Scanning ${files.length} files for leaked credentials...`);
+
+    let scan: any;
+    try {
+      const response = await axios.post(`${this.secretScanServiceUrl}/api/secret-scans`, {
+        files,
+        projectId: config.projectId,
+        runId: job.pipelineId,
+        stageId: stage.id,
+        mode: stage.inputs?.onLeak || 'fail',
+        failOn: stage.inputs?.failOn
+      }, {
+        headers: { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' },
+        timeout: parseInt(process.env.SECRET_SCAN_TIMEOUT_MS || '60000')
+      });
+      scan = response.data.data;
+    } catch (error) {
+      if (axios.isAxiosError(error) && error.response?.data?.error) {
+        throw new Error(`Secret scan stage failed: ${error.response.data.error}`);
+      }
+      throw error;
+    }
+
+    log(`${scan.findings.length > 0 && scan.status !== 'failed' ? WARN_PREFIX : ''}${stage.name}: ${scan.findings.length} findings, ${scan.blocking} blocking`);
+
+    if (scan.status === 'failed') {
+      throw new Error(`Secret scan ${scan._id} found ${scan.blocking} leaked credential(s)`);
+    }
+    if (scan.status === 'quarantined') {
+      log(`${WARN_PREFIX}${stage.name}: Quarantined ${scan.quarantinedFiles.join(', ')}`);
+    }
+
+    return {
+      scanId: scan._id,
+      status: scan.status,
+      findings: scan.findings,
+      quarantinedFiles: scan.quarantinedFiles,
+      // Exposed like an agent's generated files so later stages pick up the clean set
+      output: { files: scan.cleanFiles || files }
+    };
+  }
+
+  // Runs the quality checks against the most recent generated files. When the report recommends a
+  // repair and the stage names an agent, that agent gets the failures and the current files, and the
+  // gate runs again, up to inputs.maxRepairs times. The stage fails if the code still does not pass.
+  private async executeQualityGateStage(job: PipelineJob, log: StageLogger, options: StageRunOptions): Promise<any> {
+    const { stage, config } = job;
+    let files = this.latestFiles(job);
+
+    if (!files || files.length === 0) {
+      throw new Error('Quality gate stage has no generated files to check');
+    }
+
+    const maxRepairs = stage.agentRole
+      ? parseInt(stage.inputs?.maxRepairs ?? process.env.QUALITY_GATE_MAX_REPAIRS ?? '2')
+      : 0;
+    const reportIds: string[] = [];
+    // Repairs are the LLM calls this stage makes
+    const repairUsage: LLMUsage[] = [];
+
+    for (let attempt = 0; ; attempt++) {
+      log(`${stage.name}: Running quality checks on ${files.length} files${attempt > 0 ? ` (after repair ${attempt})` : ''}...`);
+
+      let report: any;
+      try {
+        const response = await axios.post(`${this.qualityGateServiceUrl}/api/quality-gates/runs`, {
+          files,
+          projectId: config.projectId,
+          runId: job.pipelineId,
+          stageId: stage.id,
+          attempt,
+          checks: stage.inputs?.checks,
+          coverageThreshold: stage.inputs?.coverageThreshold
+        }, {
+          headers: { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' },
+          timeout: parseInt(process.env.QUALITY_GATE_TIMEOUT_MS || '900000')
+        });
+        report = response.data.data;
+      } catch (error) {
+        if (axios.isAxiosError(error) && error.response?.data?.error) {
+          throw new Error(`Quality gate stage failed: ${error.response.data.error}`);
+        }
+        throw error;
+      }
+
+      reportIds.push(report._id);
+      const summary = report.checks.map((check: any) => `${check.id}=${check.status}`).join(', ');
+      log(`${stage.name}: ${report.status} (${summary})`);
+
+      if (report.status === 'passed') {
+        return {
+          reportId: report._id,
+          reportIds,
+          repairs: attempt,
+          checks: report.checks,
+          coverage: report.coverage,
+          usage: repairUsage.length > 0 ? sumUsage(repairUsage) : undefined,
+          // Exposed like an agent's generated files so later stages pick up the repaired code
+          output: { files }
+        };
+      }
+
+      if (!report.repair.recommended || attempt >= maxRepairs) {
+        const why = report.repair.recommended ? `still failing after ${attempt} repair(s)` : report.repair.reason;
+        throw new Error(`Quality gate ${report._id} ${report.status}: ${why}`);
+      }
+
+      log(`${WARN_PREFIX}${stage.name}: ${report.repair.reason}; asking the ${stage.agentRole} agent for a repair`);
+      const repair = await this.callAgent(stage.agentRole!, job, {
+        projectName: config.name,
+        description: `${config.description}\n\nThe generated code failed its quality checks. Fix these problems `
+          + `and return every file that needs to change:\n\n${report.repair.feedback}`
+      }, Object.fromEntries(files.map(file => [file.path, file.content])), options);
+
+      if (repair.usage) repairUsage.push(repair.usage);
+      files = this.mergeFiles(files, repair.output?.files);
+    }
+  }
+
+  // Deploys the most recent generated files to a preview environment and waits until it is reachable.
+  // inputs.target (compose | kubernetes), inputs.port and inputs.ttlHours are passed through.
+  private async executePreviewStage(job: PipelineJob, log: StageLogger, options: StageRunOptions): Promise<any> {
+    const { stage, config } = job;
+    const files = this.latestFiles(job);
+
+    if (!files || files.length === 0) {
+      throw new Error('Preview stage has no generated files to deploy');
+    }
+
+    let deployment: any;
+    try {
+      const response = await axios.post(`${this.previewServiceUrl}/api/previews/builds`, {
+        files,
+        name: config.name,
+        projectId: config.projectId,
+        runId: job.pipelineId,
+        stageId: stage.id,
+        target: stage.inputs?.target,
+        port: stage.inputs?.port,
+        ttlHours: stage.inputs?.ttlHours,
+        createdBy: config.tenantId
+      }, {
+        headers: { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' },
+        timeout: 30000,
+        signal: options.signal
+      });
+      deployment = response.data.data;
+    } catch (error) {
+      if (options.signal?.aborted) {
+        throw new Error('Stage cancelled');
+      }
+      if (axios.isAxiosError(error) && error.response?.data?.error) {
+        throw new Error(`Preview stage failed: ${error.response.data.error}`);
+      }
+      throw error;
+    }
+
+    log(`${stage.name}: Building preview ${deployment.name} (${deployment.target})...`);
+
+    const deadline = Date.now() + parseInt(process.env.PREVIEW_TIMEOUT_MS || '1200000');
+    const interval = parseInt(process.env.PREVIEW_POLL_INTERVAL_MS || '5000');
+    let status = deployment.status;
+
+    while (deployment.status !== 'ready') {
+      // A cancelled run stops waiting at once instead of polling until the timeout
+      if (options.signal?.aborted) {
+        throw new Error('Stage cancelled');
+      }
+      if (deployment.status === 'failed') {
+        throw new Error(`Preview ${deployment.name} failed: ${deployment.error}`);
+      }
+      if (Date.now() > deadline) {
+        throw new Error(`Preview ${deployment.name} was not ready in time (still ${deployment.status})`);
+      }
+
+      await new Promise<void>(resolve => {
+        const timer = setTimeout(resolve, interval);
+        options.signal?.addEventListener('abort', () => {
+          clearTimeout(timer);
+          resolve();
+        }, { once: true });
+      });
+      if (options.signal?.aborted) continue;
+
+      const response = await axios.get(`${this.previewServiceUrl}/api/previews/builds/${deployment._id}`, {
+        headers: { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' },
+        timeout: 10000,
+        signal: options.signal
+      });
+      deployment = response.data.data;
+
+      if (deployment.status !== status) {
+        status = deployment.status;
+        log(`${stage.name}: Preview ${status}`);
+      }
+    }
+
+    log(`${stage.name}: Preview available at ${deployment.url} until ${deployment.expiresAt}`);
+    return {
+      deploymentId: deployment._id,
+      url: deployment.url,
+      target: deployment.target,
+      expiresAt: deployment.expiresAt
+    };
+  }
+
+  // Renders diagrams for an architect output (inputs.architecture). Diagrams are documentation, so
+  // sources that fail to render are logged rather than failing the run.
+  private async executeDiagramStage(job: PipelineJob, log: StageLogger): Promise<any> {
+    const { stage, config } = job;
+    const architecture = stage.inputs?.architecture;
+
+    if (!architecture || typeof architecture !== 'object') {
+      throw new Error('Diagram stage needs an architecture output in inputs.architecture');
+    }
+
+    log(`${stage.name}: Rendering architecture diagrams...`);
+
+    let result: any;
+    try {
+      const response = await axios.post(`${this.diagramServiceUrl}/api/diagrams/architecture`, {
+        architecture,
+        projectName: config.name,
+        projectId: config.projectId,
+        runId: job.pipelineId,
+        stageId: stage.id,
+        formats: stage.inputs?.formats
+      }, {
+        headers: { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' },
+        timeout: parseInt(process.env.DIAGRAM_TIMEOUT_MS || '120000')
+      });
+      result = response.data.data;
+    } catch (error) {
+      if (axios.isAxiosError(error) && error.response?.data?.error) {
+        throw new Error(`Diagram stage failed: ${error.response.data.error}`);
+      }
+      throw error;
+    }
+
+    for (const diagram of result.diagrams) {
+      log(diagram.status === 'rendered'
+        ? `${stage.name}: Rendered ${diagram.title} (${diagram.renders.map((render: any) => render.format).join(', ')})`
+        : `${WARN_PREFIX}${stage.name}: Could not render ${diagram.title}: ${diagram.error}`);
+    }
+
+    return {
+      diagrams: result.diagrams.map((diagram: any) => ({
+        id: diagram._id,
+        name: diagram.name,
+        title: diagram.title,
+        status: diagram.status,
+        artifacts: diagram.renders
+      })),
+      failed: result.failed
+    };
+  }
+
+  // Screens the most recent generated files against the project's moderation policy before they are
+  // persisted or shown. Blocked content fails the stage; redactions are passed on to later stages.
+  private async executeModerationStage(job: PipelineJob, log: StageLogger): Promise<any> {
+    const { stage, config } = job;
+    const files = this.latestFiles(job);
+
+    if (!files || files.length === 0) {
+      throw new Error('Moderation stage has no generated files to screen');
+    }
+
+    log(`${stage.name}: Screening ${files.length} files against the moderation policy...`);
+
+    let check: any;
+    try {
+      const response = await axios.post(`${this.moderationServiceUrl}/api/moderation/checks`, {
+        source: 'files',
+        items: files.map(file => ({ name: file.path, content: file.content })),
+        projectId: config.projectId,
+        runId: job.pipelineId,
+        stageId: stage.id
+      }, {
+        headers: { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' },
+        timeout: parseInt(process.env.MODERATION_TIMEOUT_MS || '60000')
+      });
+      check = response.data.data;
+    } catch (error) {
+      if (axios.isAxiosError(error) && error.response?.data?.error) {
+        throw new Error(`Moderation stage failed: ${error.response.data.error}`);
+      }
+      throw error;
+    }
+
+    log(`${check.decision !== 'allowed' && check.decision !== 'blocked' ? WARN_PREFIX : ''}${stage.name}: ${check.decision} under policy ${check.policy.name} (${check.findings.length} findings)`);
+
+    if (check.decision === 'blocked') {
+      throw new Error(`Moderation blocked ${check.blockedItems.join(', ')}; see audit record ${check.recordId}`);
+    }
+
+    return {
+      decision: check.decision,
+      recordId: check.recordId,
+      findings: check.findings,
+      // Exposed like an agent's generated files so later stages pick up the redacted set
+      output: {
+        files: check.items.map((item: { name: string; content: string }) => ({ path: item.name, content: item.content }))
+      }
+    };
+  }
+
+  // Agents may return only the files they changed
+  private mergeFiles(current: GeneratedFile[], changed: GeneratedFile[] | undefined): GeneratedFile[] {
+    if (!Array.isArray(changed) || changed.length === 0) {
+      throw new Error('Repair agent returned no files');
+    }
+
+    const merged = new Map(current.map(file => [file.path, file]));
+    for (const file of changed) merged.set(file.path, file);
+    return Array.from(merged.values());
+  }
+
+  // Files from the most recent stage that produced any
+  private latestFiles(job: PipelineJob): GeneratedFile[] | undefined {
+    return Object.values(job.previousOutputs || {})
+      .map((output: any) => output?.output?.files)
+      .filter(Array.isArray)
+      .pop();
+  }
+
+  private async simulateStageExecution(job: PipelineJob, log: StageLogger): Promise<void> {
+    // Simulate processing time
+    const duration = 2000 + Math.random() * 3000;
+    const steps = ['Initializing...', 'Processing...', 'Finalizing...'];
+
+    for (const step of steps) {
+      await new Promise(resolve => setTimeout(resolve, duration / steps.length));
+      log(`${job.stage.name}: ${step}`);
+    }
+  }
+}
@@ -1,16 +1,62 @@
 import express, { Request, Response } from 'express';
 import { body, validationResult } from 'express-validator';
 import { PipelineService } from '../services/PipelineService.js';
-import { MLPipelineConfig } from '../types/index.js';
+import { UnknownModelError } from '../services/ModelRegistryClient.js';
+import { DefinitionRegistry } from '../definitions/DefinitionRegistry.js';
+import { DefinitionValidationError, parseDefinition } from '../definitions/DefinitionParser.js';
+import { MLPipelineConfig, RunOwner } from '../types/index.js';
+import { RunStreamEvent } from '../services/RunEventStream.js';
+import { ProjectClient } from '../services/ProjectClient.js';
+import { AuthenticatedRequest, requireAuth, requireRole } from '../middleware/auth.js';
 
 const router = express.Router();
 
-export default function createPipelineRoutes(pipelineService: PipelineService) {
+const SSE_HEARTBEAT_MS = parseInt(process.env.SSE_HEARTBEAT_MS || '15000');
+
+export default function createPipelineRoutes(
+  pipelineService: PipelineService,
+  definitions: DefinitionRegistry,
+  projects: ProjectClient = new ProjectClient()
+) {
+  router.use(requireAuth);
+
+  // Admins and members of the run's project may see it; runs without a project only whoever started them
+  const canAccessRun = async (req: AuthenticatedRequest, owner: RunOwner): Promise<boolean> => {
+    if (req.user!.role === 'admin') return true;
+    if (!owner.projectId) return !!owner.tenantId && owner.tenantId === req.user!._id;
+    return !!await projects.get(owner.projectId, req.get('Authorization'));
+  };
+
+  // Project runs belong to the project's tenant rather than to whoever started them. Undefined
+  // when the caller cannot see the project.
+  const tenantFor = async (req: AuthenticatedRequest, projectId?: string): Promise<string | undefined> => {
+    if (!projectId) return req.user!._id;
+    const project = await projects.get(projectId, req.get('Authorization'));
+    return project ? ProjectClient.tenantOf(project) : undefined;
+  };
+
+  // Stage tool scopes grant agents tools, so they only ever come from the registered definition the
+  // run names, never from a configuration the caller supplies
+  const withDefinitionScopes = (config: MLPipelineConfig): MLPipelineConfig => {
+    const definition = config.definition ? definitions.get(config.definition) : undefined;
+    for (const stage of config.stages) {
+      stage.toolScopes = definition?.stages.find(candidate => candidate.id === stage.id)?.toolScopes;
+    }
+    return config;
+  };
+
+  const projectNotFound = (res: Response) => res.status(404).json({
+    success: false,
+    error: 'Project not found'
+  });
+
   // POST /api/pipeline/create - Create a new pipeline
   router.post('/create', [
     body('name').notEmpty().withMessage('Pipeline name is required'),
-    body('description').optional().isString()
-  ], async (req: Request, res: Response) => {
+    body('description').optional().isString(),
+    body('definition').optional().isString().withMessage('Definition must be a definition name'),
+    body('projectId').optional().isString().withMessage('Project ID must be a string')
+  ], async (req: AuthenticatedRequest, res: Response) => {
     try {
       const errors = validationResult(req);
       if (!errors.isEmpty()) {
@@ -21,14 +67,30 @@ export default function createPipelineRoutes(pipelineService: PipelineService) {
         });
       }
 
-      const { name, description, dataPath, modelConfig, outputPath } = req.body;
+      const { name, description, dataPath, modelConfig, outputPath, projectId, definition: definitionName } = req.body;
+
+      const definition = definitionName ? definitions.get(definitionName) : undefined;
+      if (definitionName && !definition) {
+        return res.status(404).json({
+          success: false,
+          error: `Pipeline definition "${definitionName}" not found`
+        });
+      }
+
+      const tenantId = await tenantFor(req, projectId);
+      if (!tenantId) return projectNotFound(res);
       
       const config = {
         name,
-        description,
+        description: description || definition?.description,
         dataPath,
         modelConfig,
-        outputPath
+        outputPath,
+        projectId,
+        tenantId,
+        requestId: req.get('X-Request-Id'),
+        definition: definition?.name,
+        stages: definition ? definitions.buildStages(definition) : undefined
       };
 
       const pipeline = await pipelineService.createPipeline(config);
@@ -39,6 +101,12 @@ export default function createPipelineRoutes(pipelineService: PipelineService) {
       });
     } catch (error) {
       console.error('Pipeline creation error:', error);
+      if (error instanceof UnknownModelError) {
+        return res.status(400).json({
+          success: false,
+          error: error.message
+        });
+      }
       res.status(500).json({
         success: false,
         error: 'Failed to create pipeline'
@@ -46,8 +114,272 @@ export default function createPipelineRoutes(pipelineService: PipelineService) {
     }
   });
 
+  // POST /api/pipeline/runs - Create and start a run of a pipeline definition in one call
+  router.post('/runs', [
+    body('definition').isString().withMessage('Definition name is required'),
+    body('name').optional().isString().isLength({ min: 1, max: 200 }).withMessage('Name must be 1-200 characters'),
+    body('description').optional().isString(),
+    body('projectId').optional().isString().withMessage('Project ID must be a string')
+  ], async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const errors = validationResult(req);
+      if (!errors.isEmpty()) {
+        return res.status(400).json({
+          success: false,
+          error: 'Validation failed',
+          details: errors.array()
+        });
+      }
+
+      const definition = definitions.get(req.body.definition);
+      if (!definition) {
+        return res.status(404).json({
+          success: false,
+          error: `Pipeline definition "${req.body.definition}" not found`
+        });
+      }
+
+      const tenantId = await tenantFor(req, req.body.projectId);
+      if (!tenantId) return projectNotFound(res);
+
+      const config = await pipelineService.createPipeline({
+        name: req.body.name || definition.name,
+        description: req.body.description || definition.description,
+        dataPath: req.body.dataPath,
+        modelConfig: req.body.modelConfig,
+        outputPath: req.body.outputPath,
+        projectId: req.body.projectId,
+        tenantId,
+        requestId: req.get('X-Request-Id'),
+        definition: definition.name,
+        stages: definitions.buildStages(definition)
+      });
+      await pipelineService.executePipeline(config.id, config);
+
+      res.status(202).json({
+        success: true,
+        data: {
+          runId: config.id,
+          status: 'running',
+          stages: config.stages.map(stage => ({ id: stage.id, name: stage.name })),
+          eventsUrl: `/api/pipeline/runs/${config.id}/events`
+        }
+      });
+    } catch (error) {
+      console.error('Pipeline run error:', error);
+      if (error instanceof UnknownModelError) {
+        return res.status(400).json({
+          success: false,
+          error: error.message
+        });
+      }
+      res.status(500).json({
+        success: false,
+        error: 'Failed to start pipeline run'
+      });
+    }
+  });
+
+  // GET /api/pipeline/runs/:id - Run status and stages
+  router.get('/runs/:id', async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const execution = await pipelineService.getPipelineStatus(req.params.id);
+      if (!execution || !await canAccessRun(req, execution.config)) {
+        return res.status(404).json({
+          success: false,
+          error: 'Run not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: execution
+      });
+    } catch (error) {
+      console.error('Run status error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to get run status'
+      });
+    }
+  });
+
+  // GET /api/pipeline/runs/:id/cost - Tokens and dollar cost per stage, agent and model
+  router.get('/runs/:id/cost', async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const owner = await pipelineService.getRunOwner(req.params.id);
+      const report = owner && await canAccessRun(req, owner) ? await pipelineService.getRunCost(req.params.id) : null;
+      if (!report) {
+        return res.status(404).json({
+          success: false,
+          error: 'Run not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: report
+      });
+    } catch (error) {
+      console.error('Run cost error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to get run cost'
+      });
+    }
+  });
+
+  // GET /api/pipeline/runs/:id/events - Server-sent events: run and stage transitions, logs,
+  // warnings and agent output tokens. Resume with Last-Event-ID or ?since=<seq>.
+  router.get('/runs/:id/events', async (req: AuthenticatedRequest, res: Response) => {
+    const runId = req.params.id;
+    const stream = pipelineService.runEvents;
+    const execution = await pipelineService.getPipelineStatus(runId);
+    if (!stream.has(runId) || !execution) {
+      return res.status(404).json({
+        success: false,
+        error: 'Run not found on this instance'
+      });
+    }
+
+    try {
+      if (!await canAccessRun(req, execution.config)) {
+        return res.status(404).json({
+          success: false,
+          error: 'Run not found'
+        });
+      }
+    } catch (error) {
+      console.error('Run access check error:', error);
+      return res.status(502).json({
+        success: false,
+        error: 'Failed to check run access'
+      });
+    }
+
+    res.writeHead(200, {
+      'Content-Type': 'text/event-stream',
+      'Cache-Control': 'no-cache',
+      'Connection': 'keep-alive',
+      'X-Accel-Buffering': 'no'
+    });
+
+    let unsubscribe = () => {};
+    const heartbeat = setInterval(() => res.write(': heartbeat\n\n'), SSE_HEARTBEAT_MS);
+    const close = () => {
+      clearInterval(heartbeat);
+      unsubscribe();
+      if (!res.writableEnded) res.end();
+    };
+
+    const send = (event: RunStreamEvent) => {
+      res.write(`id: ${event.seq}\nevent: ${event.type}\ndata: ${JSON.stringify(event)}\n\n`);
+    };
+
+    // Replay and subscribe happen in the same tick, so no event can slip in between
+    const since = parseInt((req.get('Last-Event-ID') || req.query.since || '0') as string) || 0;
+    stream.replay(runId, since).forEach(send);
+    if (stream.isFinished(runId)) return close();
+
+    // The stream ends with the run, so clients know not to reconnect
+    unsubscribe = stream.subscribe(runId, event => {
+      send(event);
+      if (event.type === 'run' && stream.isFinished(runId)) close();
+    });
+    req.on('close', close);
+  });
+
+  // GET /api/pipeline/definitions - List declarative pipeline definitions
+  router.get('/definitions', (req: Request, res: Response) => {
+    res.json({
+      success: true,
+      data: definitions.list()
+    });
+  });
+
+  // GET /api/pipeline/definitions/:name - Get a pipeline definition
+  router.get('/definitions/:name', (req: Request, res: Response) => {
+    const definition = definitions.get(req.params.name);
+    if (!definition) {
+      return res.status(404).json({
+        success: false,
+        error: 'Pipeline definition not found'
+      });
+    }
+
+    res.json({
+      success: true,
+      data: definition
+    });
+  });
+
+  // POST /api/pipeline/definitions/validate - Parse and validate a YAML definition without saving it
+  router.post('/definitions/validate', [
+    body('yaml').isString().withMessage('YAML definition is required')
+  ], (req: Request, res: Response) => {
+    const errors = validationResult(req);
+    if (!errors.isEmpty()) {
+      return res.status(400).json({
+        success: false,
+        error: 'Validation failed',
+        details: errors.array()
+      });
+    }
+
+    try {
+      const definition = parseDefinition(req.body.yaml);
+      res.json({
+        success: true,
+        data: definition
+      });
+    } catch (error) {
+      if (error instanceof DefinitionValidationError) {
+        return res.status(422).json({
+          success: false,
+          error: 'Invalid pipeline definition',
+          details: error.errors
+        });
+      }
+      throw error;
+    }
+  });
+
+  // POST /api/pipeline/definitions - Register (or replace) a YAML definition. Definitions are shared
+  // by every tenant, so only admins can change them.
+  router.post('/definitions', requireRole('admin'), [
+    body('yaml').isString().withMessage('YAML definition is required')
+  ], (req: Request, res: Response) => {
+    const errors = validationResult(req);
+    if (!errors.isEmpty()) {
+      return res.status(400).json({
+        success: false,
+        error: 'Validation failed',
+        details: errors.array()
+      });
+    }
+
+    try {
+      const definition = parseDefinition(req.body.yaml);
+      definitions.register(definition);
+
+      res.status(201).json({
+        success: true,
+        data: definition
+      });
+    } catch (error) {
+      if (error instanceof DefinitionValidationError) {
+        return res.status(422).json({
+          success: false,
+          error: 'Invalid pipeline definition',
+          details: error.errors
+        });
+      }
+      throw error;
+    }
+  });
+
   // POST /api/pipeline/:id/execute - Execute a pipeline
-  router.post('/:id/execute', async (req: Request, res: Response) => {
+  router.post('/:id/execute', async (req: AuthenticatedRequest, res: Response) => {
     try {
       const { id } = req.params;
       const config: MLPipelineConfig = req.body;
@@ -59,7 +391,22 @@ export default function createPipelineRoutes(pipelineService: PipelineService) {
         });
       }
 
-      const executionId = await pipelineService.executePipeline(id, config);
+      // A run ID that already has checkpoints can only be reused by someone who can see that run
+      const owner = await pipelineService.getRunOwner(id);
+      if (owner && !await canAccessRun(req, owner)) {
+        return res.status(404).json({
+          success: false,
+          error: 'Pipeline execution not found'
+        });
+      }
+
+      // Attribution comes from the caller's token and project, never from the client-supplied config
+      const tenantId = await tenantFor(req, config.projectId);
+      if (!tenantId) return projectNotFound(res);
+      config.tenantId = tenantId;
+      config.requestId = req.get('X-Request-Id') || config.requestId;
+
+      const executionId = await pipelineService.executePipeline(id, withDefinitionScopes(config));
       
       res.json({
         success: true,
@@ -75,12 +422,12 @@ export default function createPipelineRoutes(pipelineService: PipelineService) {
   });
 
   // GET /api/pipeline/:id/status - Get pipeline execution status
-  router.get('/:id/status', async (req: Request, res: Response) => {
+  router.get('/:id/status', async (req: AuthenticatedRequest, res: Response) => {
     try {
       const { id } = req.params;
       const execution = await pipelineService.getPipelineStatus(id);
       
-      if (!execution) {
+      if (!execution || !await canAccessRun(req, execution.config)) {
         return res.status(404).json({
           success: false,
           error: 'Pipeline execution not found'
@@ -100,14 +447,29 @@ export default function createPipelineRoutes(pipelineService: PipelineService) {
     }
   });
 
-  // GET /api/pipeline/list - Get all pipeline executions
-  router.get('/list', async (req: Request, res: Response) => {
+  // GET /api/pipeline/list - Get the pipeline executions the caller can see
+  router.get('/list', async (req: AuthenticatedRequest, res: Response) => {
     try {
       const executions = await pipelineService.getAllPipelines();
-      
+      if (req.user!.role === 'admin') {
+        return res.json({
+          success: true,
+          data: executions
+        });
+      }
+
+      // One project-service lookup per project rather than per run
+      const projectIds = [...new Set(executions.map(execution => execution.config.projectId).filter((id): id is string => !!id))];
+      const visible = new Set<string>();
+      await Promise.all(projectIds.map(async projectId => {
+        if (await projects.get(projectId, req.get('Authorization'))) visible.add(projectId);
+      }));
+
       res.json({
         success: true,
-        data: executions
+        data: executions.filter(({ config }) => config.projectId
+          ? visible.has(config.projectId)
+          : !!config.tenantId && config.tenantId === req.user!._id)
       });
     } catch (error) {
       console.error('Pipeline list error:', error);
@@ -119,10 +481,11 @@ export default function createPipelineRoutes(pipelineService: PipelineService) {
   });
 
   // POST /api/pipeline/:id/cancel - Cancel pipeline execution
-  router.post('/:id/cancel', async (req: Request, res: Response) => {
+  router.post('/:id/cancel', async (req: AuthenticatedRequest, res: Response) => {
     try {
       const { id } = req.params;
-      const cancelled = await pipelineService.cancelPipeline(id);
+      const execution = await pipelineService.getPipelineStatus(id);
+      const cancelled = !!execution && await canAccessRun(req, execution.config) && await pipelineService.cancelPipeline(id);
       
       if (!cancelled) {
         return res.status(404).json({
@@ -144,5 +507,121 @@ export default function createPipelineRoutes(pipelineService: PipelineService) {
     }
   });
 
+  // POST /api/pipeline/:id/resume - Resume a failed or interrupted run, re-running its unfinished stages
+  // and the stages that were skipped because one of them failed
+  router.post('/:id/resume', async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const { id } = req.params;
+      const owner = await pipelineService.getRunOwner(id);
+      if (owner && !await canAccessRun(req, owner)) {
+        return res.status(404).json({
+          success: false,
+          error: 'Pipeline execution not found'
+        });
+      }
+
+      // A supplied configuration is only used for runs this instance no longer holds. It keeps the
+      // checkpointed run's project, and its attribution comes from the caller, not the body.
+      let config: MLPipelineConfig | undefined;
+      if (req.body?.stages && !await pipelineService.getPipelineStatus(id)) {
+        const projectId = owner ? owner.projectId : req.body.projectId;
+        const tenantId = await tenantFor(req, projectId);
+        if (!tenantId) return projectNotFound(res);
+        config = withDefinitionScopes({ ...req.body, id, projectId, tenantId, requestId: req.get('X-Request-Id') || undefined });
+      }
+      const resumed = await pipelineService.resumePipeline(id, config);
+
+      if (!resumed) {
+        return res.status(404).json({
+          success: false,
+          error: 'Pipeline execution not found; supply the pipeline configuration to resume it'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: { executionId: id, ...resumed }
+      });
+    } catch (error) {
+      console.error('Pipeline resume error:', error);
+      res.status(500).json({
+        success: false,
+        error: error instanceof Error ? error.message : 'Failed to resume pipeline'
+      });
+    }
+  });
+
+  // GET /api/pipeline/queue/stats - Stage queue depth and worker settings
+  router.get('/queue/stats', async (req: Request, res: Response) => {
+    try {
+      const stats = await pipelineService.getQueueStats();
+      if (!stats) {
+        return res.status(404).json({
+          success: false,
+          error: 'Job queue is not enabled'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: stats
+      });
+    } catch (error) {
+      console.error('Queue stats error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to get queue stats'
+      });
+    }
+  });
+
+  // GET /api/pipeline/queue/dead-letter - Stages that exhausted their retries, across all tenants (admin only)
+  router.get('/queue/dead-letter', requireRole('admin'), async (req: Request, res: Response) => {
+    try {
+      const jobs = await pipelineService.listDeadLetters();
+      if (!jobs) {
+        return res.status(404).json({
+          success: false,
+          error: 'Job queue is not enabled'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: jobs
+      });
+    } catch (error) {
+      console.error('Dead-letter list error:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to list dead-letter jobs'
+      });
+    }
+  });
+
+  // POST /api/pipeline/queue/dead-letter/:jobId/retry - Resume the run a dead-lettered stage belongs to (admin only)
+  router.post('/queue/dead-letter/:jobId/retry', requireRole('admin'), async (req: Request, res: Response) => {
+    try {
+      const retried = await pipelineService.retryDeadLetter(req.params.jobId);
+      if (!retried) {
+        return res.status(404).json({
+          success: false,
+          error: 'Dead-letter job not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: { retried: true, executionId: retried.pipelineId, resumedFrom: retried.resumedFrom }
+      });
+    } catch (error) {
+      console.error('Dead-letter retry error:', error);
+      res.status(500).json({
+        success: false,
+        error: error instanceof Error ? error.message : 'Failed to retry job'
+      });
+    }
+  });
+
   return router;
 }
\ No newline at end of file
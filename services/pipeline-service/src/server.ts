@@ -1,11 +1,18 @@
 import express from 'express';
 import cors from 'cors';
 import { createServer } from 'http';
+import * as path from 'path';
 import { Server as SocketIOServer } from 'socket.io';
 import { config } from 'dotenv';
 import winston from 'winston';
 import { PipelineService } from './services/PipelineService.js';
+import { JobQueue } from './queue/JobQueue.js';
+import { StageExecutor } from './queue/StageExecutor.js';
 import createPipelineRoutes from './routes/pipeline.js';
+import { DefinitionRegistry } from './definitions/DefinitionRegistry.js';
+import { EventPublisher } from './events/EventPublisher.js';
+import { createMessageBus } from './events/bus/createMessageBus.js';
+import { HealthCheck, httpDependency } from './services/HealthCheck.js';
 
 // Load environment variables
 config();
@@ -55,7 +62,7 @@ app.use((req, res, next) => {
   next();
 });
 
-// Health check endpoint
+// Health check endpoint; liveness only, /health/deep checks dependencies
 app.get('/health', (req, res) => {
   res.json({
     status: 'ok',
@@ -65,11 +72,54 @@ app.get('/health', (req, res) => {
   });
 });
 
+// Stage job queue (Redis). Without it, stages run in-process.
+const jobQueue = process.env.JOB_QUEUE_ENABLED === 'true' ? new JobQueue() : undefined;
+
+if (jobQueue && process.env.RUN_EMBEDDED_WORKER !== 'false') {
+  const executor = new StageExecutor();
+  jobQueue.process((job, options) => executor.execute(job.data, (message) => {
+    job.log(message).catch(() => undefined);
+  }, options));
+}
+
+// Domain event bus (NATS or Kafka); disabled unless EVENT_BUS is set
+const messageBus = createMessageBus();
+if (messageBus) {
+  messageBus.connect()
+    .then(() => logger.info(`📡 Publishing domain events to ${messageBus.name}`))
+    .catch((error) => logger.error(`❌ Failed to connect to ${messageBus.name}:`, error));
+}
+
+// Without Redis or the agent service no stage can run; the bus and run state only lose events and checkpoints
+const healthCheck = new HealthCheck([
+  ...(jobQueue ? [{ name: 'redis', critical: true, check: () => jobQueue.ping() }] : []),
+  ...(messageBus ? [{ name: messageBus.name, critical: false, check: () => messageBus.ping() }] : []),
+  httpDependency('agent-service', process.env.AGENT_SERVICE_URL || 'http://localhost:3005', true),
+  ...(process.env.CONVERSATION_SERVICE_URL
+    ? [httpDependency('conversation-service', process.env.CONVERSATION_SERVICE_URL, false)]
+    : [])
+]);
+
+// Dependency health check; answers 503 when unhealthy so readiness probes take the instance out
+app.get('/health/deep', async (req, res) => {
+  const report = await healthCheck.run();
+  res.status(report.status === 'unhealthy' ? 503 : 200).json({
+    status: report.status,
+    service: 'pipeline-service',
+    timestamp: new Date().toISOString(),
+    version: '1.0.0',
+    dependencies: report.dependencies
+  });
+});
+
 // Initialize Pipeline Service
-const pipelineService = new PipelineService(io);
+const pipelineService = new PipelineService(io, jobQueue, new EventPublisher(messageBus));
+
+// Declarative pipeline definitions
+const definitions = new DefinitionRegistry(process.env.PIPELINE_DEFINITIONS_DIR || path.join(process.cwd(), 'pipelines'));
 
 // Routes
-app.use('/api/pipeline', createPipelineRoutes(pipelineService));
+app.use('/api/pipeline', createPipelineRoutes(pipelineService, definitions));
 
 // Socket.IO connection handling
 io.on('connection', (socket) => {
@@ -109,10 +159,27 @@ app.use('*', (req, res) => {
   });
 });
 
+// Graceful shutdown
+process.on('SIGTERM', async () => {
+  logger.info('SIGTERM received. Shutting down gracefully...');
+  if (jobQueue) await jobQueue.close();
+  if (messageBus) await messageBus.close().catch(() => undefined);
+  process.exit(0);
+});
+
 const PORT = process.env.PORT || 3004;
 
+definitions.load()
+  .then(() => {
+    logger.info(`🧭 Loaded pipeline definitions: ${definitions.list().map(definition => definition.name).join(', ') || 'none'}`);
+  })
+  .catch((error) => {
+    logger.error('❌ Failed to load pipeline definitions:', error);
+  });
+
 server.listen(PORT, () => {
   logger.info(`🚀 Pipeline Service running on port ${PORT}`);
   logger.info(`📊 Health check: http://localhost:${PORT}/health`);
   logger.info(`🔄 WebSocket server ready for real-time updates`);
+  if (jobQueue) logger.info(`📬 Stage job queue enabled`);
 });
\ No newline at end of file
@@ -0,0 +1,82 @@
+import { LLMUsage, MLPipelineStage } from '../types/index.js';
+
+export interface CostLine extends LLMUsage {
+  costUsd: number;
+  // Fraction of the run's cost, 0-1
+  share: number;
+}
+
+export interface StageCost extends CostLine {
+  stageId: string;
+  stage: string;
+  kind?: MLPipelineStage['kind'];
+  agentRole?: string;
+  model?: string;
+  status: MLPipelineStage['status'];
+}
+
+export interface RunCostReport {
+  runId: string;
+  total: LLMUsage & { costUsd: number };
+  stages: StageCost[];
+  // The same costs grouped by specialist and by model, most expensive first
+  byAgent: Array<CostLine & { agentRole: string }>;
+  byModel: Array<CostLine & { model: string }>;
+}
+
+const empty = (): LLMUsage & { costUsd: number } => ({ promptTokens: 0, completionTokens: 0, totalTokens: 0, costUsd: 0 });
+
+export function sumUsage(usages: LLMUsage[]): LLMUsage & { costUsd: number } {
+  return usages.reduce((sum, usage) => ({
+    promptTokens: sum.promptTokens + (usage.promptTokens || 0),
+    completionTokens: sum.completionTokens + (usage.completionTokens || 0),
+    totalTokens: sum.totalTokens + (usage.totalTokens || (usage.promptTokens || 0) + (usage.completionTokens || 0)),
+    costUsd: sum.costUsd + (usage.costUsd || 0)
+  }), empty());
+}
+
+// Token counts come back as integers, but summed dollar amounts pick up float noise
+const roundUsd = (value: number) => Math.round(value * 1e6) / 1e6;
+
+const withShare = (usage: LLMUsage & { costUsd: number }, totalCost: number): CostLine => ({
+  ...usage,
+  costUsd: roundUsd(usage.costUsd),
+  share: totalCost > 0 ? Math.round((usage.costUsd / totalCost) * 1000) / 1000 : 0
+});
+
+function groupBy<K extends string>(stages: StageCost[], key: (stage: StageCost) => string, totalCost: number, label: K) {
+  const groups = new Map<string, LLMUsage[]>();
+  for (const stage of stages) {
+    const name = key(stage);
+    groups.set(name, [...(groups.get(name) || []), stage]);
+  }
+  return Array.from(groups, ([name, usages]) => ({ [label]: name, ...withShare(sumUsage(usages), totalCost) }) as CostLine & Record<K, string>)
+    .sort((a, b) => b.costUsd - a.costUsd);
+}
+
+// Breaks a run's LLM spend down by stage, agent and model. Stages that made no LLM calls
+// (scans, previews, diagrams) are listed with zero usage.
+export function buildCostReport(runId: string, stages: MLPipelineStage[]): RunCostReport {
+  const total = sumUsage(stages.map(stage => stage.usage || empty()));
+
+  const stageCosts: StageCost[] = stages.map(stage => ({
+    stageId: stage.id,
+    stage: stage.name,
+    kind: stage.kind,
+    agentRole: stage.agentRole,
+    // The fallback's model when that is what completed the stage
+    model: stage.attempts?.find(attempt => attempt.status === 'completed')?.model || stage.model,
+    status: stage.status,
+    ...withShare(sumUsage(stage.usage ? [stage.usage] : []), total.costUsd)
+  }));
+  const billed = stageCosts.filter(stage => stage.totalTokens > 0 || stage.costUsd > 0);
+
+  return {
+    runId,
+    total: { ...total, costUsd: roundUsd(total.costUsd) },
+    stages: stageCosts,
+    // Review stages have no agent role; they are billed to the reviewer
+    byAgent: groupBy(billed, stage => stage.agentRole || (stage.kind === 'review' ? 'reviewer' : stage.stageId), total.costUsd, 'agentRole'),
+    byModel: groupBy(billed, stage => stage.model || 'unknown', total.costUsd, 'model')
+  };
+}
@@ -0,0 +1,78 @@
+import axios from 'axios';
+
+export type DependencyStatus = 'up' | 'down';
+export type HealthVerdict = 'healthy' | 'degraded' | 'unhealthy';
+
+export interface Dependency {
+  name: string;
+  // Runs cannot make progress without a critical dependency; the others only lose a side effect
+  critical: boolean;
+  check: () => Promise<void>;
+}
+
+export interface DependencyReport {
+  name: string;
+  status: DependencyStatus;
+  critical: boolean;
+  latencyMs: number;
+  error?: string;
+}
+
+export interface HealthReport {
+  status: HealthVerdict;
+  dependencies: DependencyReport[];
+}
+
+const CHECK_TIMEOUT_MS = parseInt(process.env.HEALTH_CHECK_TIMEOUT_MS || '2000');
+
+// Checks another service's /health endpoint
+export function httpDependency(name: string, baseUrl: string, critical: boolean): Dependency {
+  return {
+    name,
+    critical,
+    check: async () => {
+      await axios.get(`${baseUrl}/health`, { timeout: CHECK_TIMEOUT_MS });
+    }
+  };
+}
+
+// Checks every dependency at once, each bounded by HEALTH_CHECK_TIMEOUT_MS. The service is unhealthy
+// when a critical dependency is down and degraded when only others are.
+export class HealthCheck {
+  constructor(private dependencies: Dependency[], private timeoutMs: number = CHECK_TIMEOUT_MS) {}
+
+  async run(): Promise<HealthReport> {
+    const dependencies = await Promise.all(this.dependencies.map(dependency => this.probe(dependency)));
+    const down = dependencies.filter(dependency => dependency.status === 'down');
+
+    const status: HealthVerdict = down.some(dependency => dependency.critical)
+      ? 'unhealthy'
+      : down.length > 0 ? 'degraded' : 'healthy';
+
+    return { status, dependencies };
+  }
+
+  private async probe(dependency: Dependency): Promise<DependencyReport> {
+    const startTime = Date.now();
+    let timer: NodeJS.Timeout | undefined;
+    try {
+      await Promise.race([
+        dependency.check(),
+        new Promise<never>((_, reject) => {
+          timer = setTimeout(() => reject(new Error(`No answer within ${this.timeoutMs}ms`)), this.timeoutMs);
+        })
+      ]);
+      return { name: dependency.name, status: 'up', critical: dependency.critical, latencyMs: Date.now() - startTime };
+    } catch (error) {
+      return {
+        name: dependency.name,
+        status: 'down',
+        critical: dependency.critical,
+        latencyMs: Date.now() - startTime,
+        error: error instanceof Error ? error.message : 'Check failed'
+      };
+    } finally {
+      clearTimeout(timer);
+    }
+  }
+}
@@ -0,0 +1,43 @@
+import axios from 'axios';
+import { MLPipelineStage } from '../types/index.js';
+
+export class UnknownModelError extends Error {
+  constructor(public model: string) {
+    super(`No usable model registered for "${model}"`);
+    this.name = 'UnknownModelError';
+  }
+}
+
+// Resolves stage model aliases (e.g. gpt-4o-class) through the LLM gateway's model registry
+// so each run records the concrete model it used
+export class ModelRegistryClient {
+  private llmGatewayUrl: string;
+
+  constructor(llmGatewayUrl?: string) {
+    this.llmGatewayUrl = llmGatewayUrl || process.env.LLM_GATEWAY_URL || 'http://localhost:3006';
+  }
+
+  async resolve(model: string): Promise<string> {
+    try {
+      const response = await axios.get(`${this.llmGatewayUrl}/api/llm/models/resolve/${encodeURIComponent(model)}`, {
+        timeout: 5000
+      });
+      return response.data.data.id;
+    } catch (error) {
+      if (axios.isAxiosError(error) && error.response?.status === 404) {
+        throw new UnknownModelError(model);
+      }
+      // Registry unreachable: leave the alias for the gateway to resolve at call time
+      console.error(`Model resolution failed for ${model}:`, error instanceof Error ? error.message : error);
+      return model;
+    }
+  }
+
+  async resolveStages(stages: MLPipelineStage[]): Promise<MLPipelineStage[]> {
+    return Promise.all(stages.map(async stage => ({
+      ...stage,
+      model: stage.model ? await this.resolve(stage.model) : stage.model,
+      fallback: stage.fallback?.model ? { ...stage.fallback, model: await this.resolve(stage.fallback.model) } : stage.fallback
+    })));
+  }
+}
@@ -0,0 +1,189 @@
+import type { Server as SocketIOServer } from 'socket.io';
+import { PipelineService } from './PipelineService.js';
+import { StageExecutor } from '../queue/StageExecutor.js';
+import { JobResult, MLPipelineConfig, MLPipelineStage, PipelineJob } from '../types/index.js';
+
+// Covers the stage graph scheduler: stages run in-process with the executor stubbed, so each test
+// decides what every stage does
+const io = { to: () => ({ emit: () => true }) } as unknown as SocketIOServer;
+
+const stage = (id: string, extra: Partial<MLPipelineStage> = {}): MLPipelineStage => ({
+  id,
+  name: id,
+  status: 'idle',
+  logs: [],
+  outputs: {},
+  artifacts: [],
+  ...extra
+});
+
+let runs = 0;
+const pipeline = (...stages: MLPipelineStage[]): MLPipelineConfig => ({
+  id: `run_${++runs}`,
+  name: 'Scheduler test',
+  description: 'Stage graph under test',
+  modelConfig: {},
+  stages
+});
+
+// Resolves with the data of the run's next terminal event
+const finished = (service: PipelineService, runId: string) => new Promise<Record<string, any>>(resolve => {
+  const unsubscribe = service.runEvents.subscribe(runId, event => {
+    if (event.type === 'run' && event.data.status !== 'running') {
+      unsubscribe();
+      resolve(event.data);
+    }
+  });
+});
+
+const run = async (service: PipelineService, config: MLPipelineConfig) => {
+  const outcome = finished(service, config.id);
+  await service.executePipeline(config.id, config);
+  return outcome;
+};
+
+const statusOf = (config: MLPipelineConfig) =>
+  Object.fromEntries(config.stages.map(entry => [entry.id, [entry.status, entry.skipReason]]));
+
+describe('PipelineService stage graph', () => {
+  let service: PipelineService;
+  let executed: string[];
+  // Stage IDs whose executor call fails
+  let failing: Set<string>;
+
+  beforeEach(() => {
+    service = new PipelineService(io);
+    executed = [];
+    failing = new Set();
+
+    jest.spyOn(PipelineService.prototype as any, 'createPipelineConfig').mockResolvedValue(undefined);
+    jest.spyOn(StageExecutor.prototype, 'execute').mockImplementation(async (job: PipelineJob): Promise<JobResult> => {
+      executed.push(job.stage.id);
+      await new Promise(resolve => setTimeout(resolve, 10));
+      if (failing.has(job.stage.id)) throw new Error(`${job.stage.id} failed`);
+      return { success: true, output: job.stage.outputs, logs: [] };
+    });
+  });
+
+  afterEach(() => {
+    jest.restoreAllMocks();
+  });
+
+  it('runs stages without needs in declaration order', async () => {
+    const config = pipeline(stage('plan'), stage('build'), stage('test'));
+
+    expect(await run(service, config)).toMatchObject({ status: 'completed', failedStages: [] });
+    expect(executed).toEqual(['plan', 'build', 'test']);
+  });
+
+  it('runs independent branches concurrently and joins them', async () => {
+    const timeline: string[] = [];
+    const jobs: Record<string, PipelineJob> = {};
+    let active = 0;
+    let maxActive = 0;
+    jest.spyOn(StageExecutor.prototype, 'execute').mockImplementation(async (job: PipelineJob): Promise<JobResult> => {
+      jobs[job.stage.id] = job;
+      timeline.push(`start:${job.stage.id}`);
+      maxActive = Math.max(maxActive, ++active);
+      await new Promise(resolve => setTimeout(resolve, 20));
+      active--;
+      timeline.push(`end:${job.stage.id}`);
+      return { success: true, output: { from: job.stage.id }, logs: [] };
+    });
+
+    const config = pipeline(
+      stage('design'),
+      stage('frontend', { needs: ['design'] }),
+      stage('backend', { needs: ['design'] }),
+      stage('qa', { needs: ['frontend', 'backend'] })
+    );
+
+    expect(await run(service, config)).toMatchObject({ status: 'completed' });
+    expect(maxActive).toBe(2);
+    expect(timeline.indexOf('start:frontend')).toBeLessThan(timeline.indexOf('end:backend'));
+    expect(timeline.indexOf('start:qa')).toBeGreaterThan(timeline.indexOf('end:frontend'));
+    expect(timeline.indexOf('start:qa')).toBeGreaterThan(timeline.indexOf('end:backend'));
+    expect(jobs.qa.previousOutputs).toEqual({
+      design: { from: 'design' },
+      frontend: { from: 'frontend' },
+      backend: { from: 'backend' }
+    });
+  });
+
+  it('fails the run and starts nothing further when a stop stage fails', async () => {
+    failing.add('build');
+    const config = pipeline(stage('plan'), stage('build'), stage('test'));
+
+    expect(await run(service, config)).toMatchObject({ status: 'failed', stageId: 'build', error: 'build failed' });
+    expect(executed).toEqual(['plan', 'build']);
+    expect(config.stages[2].status).toBe('idle');
+  });
+
+  it('skips only the branch of a continue stage that failed', async () => {
+    failing.add('diagrams');
+    const config = pipeline(
+      stage('design'),
+      stage('diagrams', { needs: ['design'], onFailure: 'continue' }),
+      stage('publish-diagrams', { needs: ['diagrams'] }),
+      stage('archive', { needs: ['publish-diagrams'] }),
+      stage('implement', { needs: ['design'] })
+    );
+
+    expect(await run(service, config)).toMatchObject({ status: 'completed', failedStages: ['diagrams'] });
+    expect(executed.sort()).toEqual(['design', 'diagrams', 'implement']);
+    expect(statusOf(config)).toEqual({
+      design: ['completed', undefined],
+      diagrams: ['error', undefined],
+      'publish-diagrams': ['skipped', 'upstream'],
+      archive: ['skipped', 'upstream'],
+      implement: ['completed', undefined]
+    });
+  });
+
+  it('runs the dependents of a stage skipped by its condition', async () => {
+    const config = pipeline(
+      stage('review', { outputs: { approved: false } }),
+      stage('deploy', { needs: ['review'], condition: 'stages.review.output.approved' }),
+      stage('report', { needs: ['deploy'] })
+    );
+
+    expect(await run(service, config)).toMatchObject({ status: 'completed' });
+    expect(executed).toEqual(['review', 'report']);
+    expect(statusOf(config).deploy).toEqual(['skipped', 'condition']);
+  });
+
+  it('fails a hand-written stage list whose needs form a cycle', async () => {
+    const config = pipeline(stage('a', { needs: ['b'] }), stage('b', { needs: ['a'] }));
+
+    const outcome = await run(service, config);
+
+    expect(outcome).toMatchObject({ status: 'failed', stageId: 'a' });
+    expect(outcome.error).toMatch(/dependency cycle/);
+    expect(executed).toEqual([]);
+  });
+
+  it('re-runs failed stages and their skipped dependents on resume, but not condition skips', async () => {
+    failing.add('diagrams');
+    const config = pipeline(
+      stage('diagrams', { needs: [], onFailure: 'continue' }),
+      stage('publish', { needs: ['diagrams'] }),
+      stage('preview', { needs: [], condition: 'pipeline.modelConfig.preview' }),
+      stage('implement', { needs: [] })
+    );
+    expect(await run(service, config)).toMatchObject({ status: 'completed', failedStages: ['diagrams'] });
+
+    failing.clear();
+    executed = [];
+    const outcome = finished(service, config.id);
+    expect(await service.resumePipeline(config.id)).toEqual({ resumedFrom: 'diagrams' });
+
+    expect(await outcome).toMatchObject({ status: 'completed', failedStages: [] });
+    expect(executed).toEqual(['diagrams', 'publish']);
+    expect(statusOf(config)).toEqual({
+      diagrams: ['completed', undefined],
+      publish: ['completed', undefined],
+      preview: ['skipped', 'condition'],
+      implement: ['completed', undefined]
+    });
+  });
+});
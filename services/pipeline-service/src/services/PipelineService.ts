@@ -3,13 +3,46 @@ import { spawn, ChildProcess } from 'child_process';
 import * as path from 'path';
 import { promises as fs } from 'fs';
 import * as yaml from 'yaml';
-import { MLPipelineConfig, PipelineExecution, MLPipelineStage, PipelineEvent, PipelineJob, JobResult } from '../types/index.js';
+import {
+  MLPipelineConfig,
+  PipelineExecution,
+  MLPipelineStage,
+  PipelineEvent,
+  PipelineJob,
+  JobResult,
+  RunOwner,
+  StageAttempt,
+  StageErrorCode,
+  StageRetryPolicy
+} from '../types/index.js';
+import { JobQueue } from '../queue/JobQueue.js';
+import { StageExecutor, StageRunOptions, WARN_PREFIX, stageErrorCode } from '../queue/StageExecutor.js';
+import { StageContext, evaluateCondition, resolveInputs } from '../definitions/expressions.js';
+import { EventPublisher } from '../events/EventPublisher.js';
+import { ModelRegistryClient } from './ModelRegistryClient.js';
+import { RunStateClient } from './RunStateClient.js';
+import { TelemetryClient } from './TelemetryClient.js';
+import { RunEventStream } from './RunEventStream.js';
+import { RunCostReport, buildCostReport } from './CostReport.js';
 
 export class PipelineService {
   private executions: Map<string, PipelineExecution> = new Map();
   private processes: Map<string, ChildProcess> = new Map();
-
-  constructor(private io: SocketIOServer) {}
+  // One per run in progress; cancelling the run aborts in-flight agent and LLM calls
+  private aborts: Map<string, AbortController> = new Map();
+  private executor = new StageExecutor();
+  private models = new ModelRegistryClient();
+  private runState = new RunStateClient();
+  private telemetry = new TelemetryClient();
+  // Progress of runs on this instance, for GET /runs/:id/events
+  readonly runEvents = new RunEventStream();
+
+  // When a queue is supplied, stages are dispatched to workers instead of running in-process
+  constructor(
+    private io: SocketIOServer,
+    private queue?: JobQueue,
+    private events: EventPublisher = new EventPublisher()
+  ) {}
 
   async createPipeline(config: Partial<MLPipelineConfig>): Promise<MLPipelineConfig> {
     const pipelineId = `pipeline_${Date.now()}`;
@@ -61,7 +94,11 @@ export class PipelineService {
       id: pipelineId,
       name: config.name || 'AI Pipeline',
       description: config.description || 'AI/ML Pipeline execution',
-      stages: defaultStages,
+      stages: config.stages ? await this.models.resolveStages(config.stages) : defaultStages,
+      definition: config.definition,
+      projectId: config.projectId,
+      tenantId: config.tenantId,
+      requestId: config.requestId,
       dataPath: config.dataPath,
       modelConfig: config.modelConfig,
       outputPath: config.outputPath || `./outputs/${pipelineId}`
@@ -89,8 +126,15 @@ export class PipelineService {
     // Create pipeline configuration file
     await this.createPipelineConfig(pipelineId, config);
 
+    this.events.publish('pipeline.started', { pipelineId, name: config.name }, config);
+    this.runEvents.push(pipelineId, 'run', {
+      status: 'running',
+      name: config.name,
+      stages: config.stages.map(stage => ({ id: stage.id, name: stage.name }))
+    });
+
     // Start pipeline execution
-    this.runPipelineStages(pipelineId, config);
+    this.startRun(pipelineId, config);
 
     return pipelineId;
   }
@@ -129,147 +173,594 @@ export class PipelineService {
     await fs.writeFile(path.join(configDir, `${pipelineId}.yaml`), yamlStr);
   }
 
+  private startRun(pipelineId: string, config: MLPipelineConfig): void {
+    const abort = new AbortController();
+    this.aborts.set(pipelineId, abort);
+    this.runPipelineStages(pipelineId, config).finally(() => {
+      // A resumed run may already have replaced this one
+      if (this.aborts.get(pipelineId) === abort) this.aborts.delete(pipelineId);
+    });
+  }
+
+  // Runs the stage graph: every stage whose needs have finished is started, so independent
+  // branches run concurrently and a stage with several needs waits for all of them (fan-in).
+  // Stages already completed or skipped by their condition (on resume) are left as they are.
   private async runPipelineStages(pipelineId: string, config: MLPipelineConfig): Promise<void> {
     const execution = this.executions.get(pipelineId);
     if (!execution) return;
 
-    for (let i = 0; i < config.stages.length; i++) {
-      const stage = config.stages[i];
-      execution.currentStage = stage.id;
-      
-      this.emitEvent(pipelineId, {
-        type: 'stage_start',
-        pipelineId,
-        stageId: stage.id,
-        data: { stage: stage.name },
-        timestamp: new Date()
-      });
+    const needs = this.stageNeeds(config);
+    const running = new Map<string, Promise<void>>();
+    // Stages skipped because something they need failed; their own dependents are skipped too
+    const blocked = new Set<string>();
+    // Set from stage callbacks, so typed by cast rather than narrowed to undefined here
+    let failure = undefined as { stage: MLPipelineStage; error: string } | undefined;
 
-      try {
-        await this.executeStage(pipelineId, stage);
-        
-        stage.status = 'completed';
-        stage.endTime = new Date();
-        execution.progress = ((i + 1) / config.stages.length) * 100;
+    const isDone = (stage: MLPipelineStage) => ['completed', 'skipped', 'error'].includes(stage.status);
+    const byId = new Map(config.stages.map(stage => [stage.id, stage]));
 
-        this.emitEvent(pipelineId, {
-          type: 'stage_complete',
-          pipelineId,
-          stageId: stage.id,
-          data: { stage: stage.name, outputs: stage.outputs },
-          timestamp: new Date()
-        });
+    for (const stage of config.stages) {
+      if (stage.status !== 'completed' && stage.status !== 'skipped') stage.status = 'idle';
+    }
 
-      } catch (error) {
-        stage.status = 'error';
-        execution.status = 'error';
-        
-        this.emitEvent(pipelineId, {
-          type: 'stage_failed',
-          pipelineId,
-          stageId: stage.id,
-          data: { error: error instanceof Error ? error.message : 'Unknown error' },
-          timestamp: new Date()
-        });
-        
-        return;
+    // Starts or skips every stage that is ready; skipping one can make others ready, hence the loop
+    const schedule = () => {
+      let changed = true;
+      while (changed && !failure && execution.status === 'running') {
+        changed = false;
+
+        for (const stage of config.stages) {
+          if (stage.status !== 'idle' || running.has(stage.id)) continue;
+          const upstream = needs[stage.id].filter(id => byId.has(id)).map(id => byId.get(id)!);
+          if (!upstream.every(isDone)) continue;
+
+          changed = true;
+          const failedNeed = upstream.find(need => need.status === 'error' || blocked.has(need.id));
+          if (failedNeed) {
+            blocked.add(stage.id);
+            this.skipStage(pipelineId, config, stage, 'upstream', `${failedNeed.name} did not complete`);
+            continue;
+          }
+          if (stage.condition && !evaluateCondition(stage.condition, this.buildStageContext(config))) {
+            this.skipStage(pipelineId, config, stage, 'condition', `condition "${stage.condition}" not met`);
+            continue;
+          }
+
+          running.set(stage.id, this.runStage(pipelineId, config, stage).then(error => {
+            running.delete(stage.id);
+            if (error && (stage.onFailure || 'stop') === 'stop' && !failure) {
+              failure = { stage, error };
+            }
+          }));
+        }
       }
+    };
+
+    schedule();
+    while (running.size > 0) {
+      await Promise.race(running.values());
+      schedule();
+    }
+
+    // Cancelled while stages were in flight
+    if (execution.status !== 'running') return;
+
+    // Only a dependency cycle in a hand-written stage list leaves stages waiting without a failure
+    const stuck = config.stages.find(stage => stage.status === 'idle');
+    if (!failure && stuck) {
+      failure = { stage: stuck, error: `Stage ${stuck.name} is waiting on a dependency cycle` };
     }
 
+    if (failure) {
+      execution.status = 'error';
+      execution.endTime = new Date();
+      const payload = {
+        pipelineId,
+        stageId: failure.stage.id,
+        stage: failure.stage.name,
+        error: failure.error,
+        attempts: failure.stage.attempts?.length || 1
+      };
+      this.events.publish('pipeline.failed', payload, config);
+      this.runEvents.push(pipelineId, 'run', { status: 'failed', stageId: failure.stage.id, error: failure.error });
+      return;
+    }
+
+    // Failures under onFailure: continue only drop their branch; the run still completes
+    const failedStages = config.stages.filter(stage => stage.status === 'error').map(stage => stage.id);
+
     execution.status = 'completed';
     execution.endTime = new Date();
     
     this.emitEvent(pipelineId, {
       type: 'pipeline_completed',
       pipelineId,
-      data: { results: execution.results },
+      data: { results: execution.results, failedStages },
       timestamp: new Date()
     });
+    this.events.publish('pipeline.completed', { pipelineId, name: config.name, failedStages }, config);
+    this.runEvents.push(pipelineId, 'run', { status: 'completed', progress: 100, failedStages });
   }
 
-  private async executeStage(pipelineId: string, stage: MLPipelineStage): Promise<void> {
-    stage.status = 'running';
-    stage.startTime = new Date();
-    stage.logs = [];
+  // Resolves each stage's dependencies; a stage without needs follows the one listed before it
+  private stageNeeds(config: MLPipelineConfig): Record<string, string[]> {
+    return Object.fromEntries(config.stages.map((stage, index) => [
+      stage.id,
+      stage.needs ?? (index > 0 ? [config.stages[index - 1].id] : [])
+    ]));
+  }
+
+  // Every stage a stage transitively depends on, in declaration order
+  private upstreamStages(config: MLPipelineConfig, stageId: string): MLPipelineStage[] {
+    const needs = this.stageNeeds(config);
+    const upstream = new Set<string>();
+    const visit = (id: string) => needs[id]?.forEach(need => {
+      if (!upstream.has(need)) {
+        upstream.add(need);
+        visit(need);
+      }
+    });
+    visit(stageId);
+    return config.stages.filter(stage => upstream.has(stage.id));
+  }
+
+  private updateProgress(pipelineId: string, config: MLPipelineConfig): void {
+    const execution = this.executions.get(pipelineId);
+    if (!execution) return;
+    const done = config.stages.filter(stage => ['completed', 'skipped', 'error'].includes(stage.status)).length;
+    execution.progress = (done / config.stages.length) * 100;
+  }
+
+  private skipStage(
+    pipelineId: string,
+    config: MLPipelineConfig,
+    stage: MLPipelineStage,
+    cause: NonNullable<MLPipelineStage['skipReason']>,
+    reason: string
+  ): void {
+    stage.status = 'skipped';
+    stage.skipReason = cause;
+    this.updateProgress(pipelineId, config);
+    this.runState.save(pipelineId, stage, config);
+    this.runEvents.push(pipelineId, 'stage', { status: 'skipped', stage: stage.name, reason }, stage.id);
 
     this.emitEvent(pipelineId, {
       type: 'log',
       pipelineId,
       stageId: stage.id,
-      data: { level: 'info', message: `Starting stage ${stage.name}` },
+      data: { level: 'info', message: `Skipping stage ${stage.name}: ${reason}` },
+      timestamp: new Date()
+    });
+  }
+
+  // Runs one stage and records its outcome; resolves with the error message when it failed
+  private async runStage(pipelineId: string, config: MLPipelineConfig, stage: MLPipelineStage): Promise<string | undefined> {
+    const execution = this.executions.get(pipelineId)!;
+    execution.currentStage = stage.id;
+
+    this.emitEvent(pipelineId, {
+      type: 'stage_start',
+      pipelineId,
+      stageId: stage.id,
+      data: { stage: stage.name },
       timestamp: new Date()
     });
 
     try {
-      // Simulate stage execution for now
-      await this.simulateStageExecution(pipelineId, stage);
-      
-      // Stage completed successfully
-      stage.outputs = { status: 'completed', timestamp: new Date() };
+      await this.executeStage(pipelineId, config, stage);
       
+      stage.status = 'completed';
+      stage.endTime = new Date();
+      this.updateProgress(pipelineId, config);
+      this.runState.save(pipelineId, stage, config);
+
       this.emitEvent(pipelineId, {
-        type: 'log',
+        type: 'stage_complete',
         pipelineId,
         stageId: stage.id,
-        data: { level: 'info', message: `Stage ${stage.name} completed successfully` },
+        data: { stage: stage.name, outputs: stage.outputs, attempts: stage.attempts, usage: stage.usage },
         timestamp: new Date()
       });
+      this.events.publish('stage.completed', { pipelineId, stageId: stage.id, stage: stage.name, usage: stage.usage }, config);
+      return undefined;
 
     } catch (error) {
-      const errorMessage = error instanceof Error ? error.message : 'Unknown execution error';
-      
-      stage.logs.push(`ERROR: ${errorMessage}`);
+      const message = error instanceof Error ? error.message : 'Unknown error';
+      stage.status = 'error';
+      stage.endTime = new Date();
+      this.updateProgress(pipelineId, config);
+      this.runState.save(pipelineId, stage, config);
       
       this.emitEvent(pipelineId, {
-        type: 'log',
+        type: 'stage_failed',
         pipelineId,
         stageId: stage.id,
-        data: { level: 'error', message: errorMessage },
+        data: { error: message, onFailure: stage.onFailure || 'stop', attempts: stage.attempts },
         timestamp: new Date()
       });
-
-      throw error;
+      this.events.publish('stage.failed', {
+        pipelineId,
+        stageId: stage.id,
+        stage: stage.name,
+        error: message,
+        attempts: stage.attempts?.length || 1
+      }, config);
+      
+      return message;
     }
   }
 
-  private async simulateStageExecution(pipelineId: string, stage: MLPipelineStage): Promise<void> {
-    // Simulate processing time
-    const duration = 2000 + Math.random() * 3000;
+  // Runs a stage under its retry policy: its own agent and model up to retry.attempts times with
+  // backoff between tries, then the fallback once. Every try is recorded in stage.attempts.
+  private async executeStage(pipelineId: string, config: MLPipelineConfig, stage: MLPipelineStage): Promise<void> {
+    stage.status = 'running';
+    stage.startTime = new Date();
+    stage.logs = [];
+    stage.attempts = [];
+
+    this.emitEvent(pipelineId, {
+      type: 'log',
+      pipelineId,
+      stageId: stage.id,
+      data: { level: 'info', message: `Starting stage ${stage.name}` },
+      timestamp: new Date()
+    });
+
+    const policy = this.retryPolicy(stage);
+    const totalAttempts = policy.attempts + (stage.fallback ? 1 : 0);
+    let lastError: unknown;
+
+    for (let attempt = 1; attempt <= totalAttempts; attempt++) {
+      const fallback = attempt > policy.attempts;
+      const attemptStage: MLPipelineStage = fallback
+        ? { ...stage, agentRole: stage.fallback!.agent || stage.agentRole, model: stage.fallback!.model || stage.model }
+        : stage;
+
+      if (attempt > 1) {
+        const error = lastError instanceof Error ? lastError.message : 'Unknown execution error';
+        const delayMs = fallback ? 0 : this.backoffDelay(policy, attempt);
+        const next = fallback
+          ? `falling back to ${[attemptStage.agentRole && `the ${attemptStage.agentRole} agent`, attemptStage.model].filter(Boolean).join(' with ')}`
+          : `retrying (attempt ${attempt} of ${policy.attempts})${delayMs ? ` in ${delayMs}ms` : ''}`;
+
+        this.emitStageLog(pipelineId, stage, `${WARN_PREFIX}${stage.name} failed: ${error}; ${next}`);
+        this.runEvents.push(pipelineId, 'stage', { status: 'retrying', attempt, fallback, delayMs, error }, stage.id);
+        if (delayMs) await new Promise(resolve => setTimeout(resolve, delayMs));
+
+        // Cancelled while waiting
+        if (this.executions.get(pipelineId)?.status !== 'running') throw lastError;
+      }
+
+      const startedAt = Date.now();
+      try {
+        const result = await this.runAttempt(pipelineId, config, attemptStage, attempt, attempt === totalAttempts);
+        stage.attempts.push(this.attemptRecord(attemptStage, attempt, fallback, startedAt));
+
+        // Stage completed successfully
+        stage.outputs = result.output;
+        stage.artifacts = result.artifacts || stage.artifacts;
+        stage.usage = result.output?.usage;
+        this.telemetry.recordStage(pipelineId, config, attemptStage, {
+          status: 'completed',
+          output: result.output,
+          retries: attempt - 1
+        });
+
+        this.emitEvent(pipelineId, {
+          type: 'log',
+          pipelineId,
+          stageId: stage.id,
+          data: {
+            level: 'info',
+            message: `Stage ${stage.name} completed successfully${attempt > 1 ? ` on attempt ${attempt}${fallback ? ' (fallback)' : ''}` : ''}`
+          },
+          timestamp: new Date()
+        });
+        return;
+
+      } catch (error) {
+        lastError = error;
+        stage.attempts.push(this.attemptRecord(
+          attemptStage, attempt, fallback, startedAt,
+          error instanceof Error ? error.message : 'Unknown execution error',
+          stageErrorCode(error)
+        ));
+        // A cancelled run is not retried
+        if (this.aborts.get(pipelineId)?.signal.aborted) break;
+      }
+    }
+
+    const errorMessage = lastError instanceof Error ? lastError.message : 'Unknown execution error';
     
-    return new Promise((resolve, reject) => {
-      const steps = ['Initializing...', 'Processing...', 'Finalizing...'];
-      let currentStep = 0;
-      
-      const interval = setInterval(() => {
-        if (currentStep < steps.length) {
-          const logMessage = `${stage.name}: ${steps[currentStep]}`;
-          stage.logs.push(logMessage);
-          
-          this.emitEvent(pipelineId, {
-            type: 'log',
-            pipelineId,
-            stageId: stage.id,
-            data: { level: 'info', message: logMessage },
-            timestamp: new Date()
-          });
-          
-          currentStep++;
-        } else {
-          clearInterval(interval);
-          resolve();
-        }
-      }, duration / steps.length);
+    stage.logs.push(`ERROR: ${errorMessage}`);
+    this.telemetry.recordStage(pipelineId, config, stage, {
+      status: 'failed',
+      retries: stage.attempts.length - 1,
+      error: errorMessage
+    });
+    
+    this.emitEvent(pipelineId, {
+      type: 'log',
+      pipelineId,
+      stageId: stage.id,
+      data: { level: 'error', message: stage.attempts.length > 1 ? `${errorMessage} (after ${stage.attempts.length} attempts)` : errorMessage },
+      timestamp: new Date()
+    });
+
+    throw lastError;
+  }
+
+  // One try of a stage, on the queue or in-process, bounded by the stage timeout
+  private async runAttempt(
+    pipelineId: string,
+    config: MLPipelineConfig,
+    stage: MLPipelineStage,
+    attempt: number,
+    lastAttempt: boolean
+  ): Promise<JobResult> {
+    const job: PipelineJob = {
+      // Unique per try, since the queue keeps finished jobs under their ids for a while
+      id: `${pipelineId}:${stage.id}:${attempt}:${Date.now()}`,
+      pipelineId,
+      stage: { ...stage, inputs: resolveInputs(stage.inputs || {}, this.buildStageContext(config)) },
+      config,
+      previousOutputs: Object.fromEntries(
+        this.upstreamStages(config, stage.id).map(previous => [previous.id, previous.outputs])
+      ),
+      attempt,
+      lastAttempt
+    };
+
+    // Aborted when the run is cancelled or the attempt times out, so the agent and its LLM call
+    // stop rather than running on unobserved
+    const abort = new AbortController();
+    const runSignal = this.aborts.get(pipelineId)?.signal;
+    const onRunAbort = () => abort.abort();
+    runSignal?.addEventListener('abort', onRunAbort, { once: true });
+
+    const options: StageRunOptions = {
+      signal: abort.signal,
+      onToken: text => this.runEvents.push(pipelineId, 'token', { text, attempt }, stage.id)
+    };
+
+    const run = async () => {
+      if (this.queue) {
+        const result = await this.queue.run(job, stage.timeoutMs, options);
+        result.logs.forEach(message => this.emitStageLog(pipelineId, stage, message));
+        return result;
+      }
+      return this.executor.execute(job, message => this.emitStageLog(pipelineId, stage, message), options);
+    };
+
+    let timer: NodeJS.Timeout | undefined;
+    try {
+      if (!stage.timeoutMs) return await run();
+
+      const timeout = new Promise<never>((_, reject) => {
+        timer = setTimeout(() => {
+          abort.abort();
+          reject(new Error(`Stage ${stage.name} timed out after ${stage.timeoutMs}ms`));
+        }, stage.timeoutMs);
+      });
+      return await Promise.race([run(), timeout]);
+    } finally {
+      clearTimeout(timer);
+      runSignal?.removeEventListener('abort', onRunAbort);
+    }
+  }
+
+  // Stage policy over the service defaults (STAGE_MAX_ATTEMPTS on the queue, one try in-process)
+  private retryPolicy(stage: MLPipelineStage): Required<StageRetryPolicy> {
+    return {
+      attempts: stage.retry?.attempts ?? (this.queue ? this.queue.maxAttempts : 1),
+      backoff: stage.retry?.backoff ?? 'exponential',
+      delayMs: stage.retry?.delayMs ?? parseInt(process.env.STAGE_BACKOFF_DELAY_MS || '5000')
+    };
+  }
+
+  private backoffDelay(policy: Required<StageRetryPolicy>, attempt: number): number {
+    return policy.backoff === 'fixed' ? policy.delayMs : policy.delayMs * 2 ** (attempt - 2);
+  }
+
+  private attemptRecord(
+    stage: MLPipelineStage,
+    attempt: number,
+    fallback: boolean,
+    startedAt: number,
+    error?: string,
+    errorCode?: StageErrorCode
+  ): StageAttempt {
+    return {
+      attempt,
+      fallback,
+      agentRole: stage.agentRole,
+      model: stage.model,
+      status: error ? 'failed' : 'completed',
+      error,
+      ...(errorCode ? { errorCode } : {}),
+      startedAt: new Date(startedAt).toISOString(),
+      durationMs: Date.now() - startedAt
+    };
+  }
+
+  // Values that definition inputs and conditions can reference
+  private buildStageContext(config: MLPipelineConfig): StageContext {
+    return {
+      pipeline: {
+        id: config.id,
+        name: config.name,
+        description: config.description,
+        modelConfig: config.modelConfig || {}
+      },
+      stages: Object.fromEntries(
+        config.stages.map(stage => [stage.id, { status: stage.status, output: stage.outputs }])
+      )
+    };
+  }
+
+  private emitStageLog(pipelineId: string, stage: MLPipelineStage, message: string): void {
+    stage.logs.push(message);
+    const warning = message.startsWith(WARN_PREFIX);
+
+    this.emitEvent(pipelineId, {
+      type: 'log',
+      pipelineId,
+      stageId: stage.id,
+      data: { level: warning ? 'warn' : 'info', message: warning ? message.slice(WARN_PREFIX.length) : message },
+      timestamp: new Date()
     });
   }
 
+  async getQueueStats(): Promise<Record<string, any> | null> {
+    return this.queue ? this.queue.getStats() : null;
+  }
+
+  async listDeadLetters(): Promise<any[] | null> {
+    return this.queue ? this.queue.listDeadLetters() : null;
+  }
+
+  // A dead-lettered stage has no orchestrator waiting on it, so it is retried by resuming the run
+  // it belongs to rather than by putting the job back on the queue
+  async retryDeadLetter(jobId: string): Promise<{ pipelineId: string; resumedFrom: string } | null> {
+    const dead = await this.queue?.getDeadLetter(jobId);
+    if (!dead) return null;
+
+    const resumed = await this.resumePipeline(dead.job.pipelineId, dead.job.config);
+    if (!resumed) return null;
+
+    await this.queue!.removeDeadLetter(jobId);
+    return { pipelineId: dead.job.pipelineId, ...resumed };
+  }
+
   private emitEvent(pipelineId: string, event: PipelineEvent): void {
     this.io.to(`pipeline-${pipelineId}`).emit('pipeline-event', event);
+    this.events.progress({ ...event }, this.executions.get(pipelineId)?.config);
+    this.streamEvent(pipelineId, event);
+  }
+
+  // Maps socket events onto the SSE stream: stage transitions, logs and warnings. Run-level
+  // transitions are pushed where they happen, since only those places know the outcome.
+  private streamEvent(pipelineId: string, event: PipelineEvent): void {
+    switch (event.type) {
+      case 'stage_start':
+        this.runEvents.push(pipelineId, 'stage', { status: 'running', stage: event.data.stage }, event.stageId);
+        break;
+      case 'stage_complete':
+        this.runEvents.push(pipelineId, 'stage', {
+          status: 'completed',
+          stage: event.data.stage,
+          progress: this.executions.get(pipelineId)?.progress,
+          usage: event.data.usage
+        }, event.stageId);
+        break;
+      case 'stage_failed':
+        this.runEvents.push(pipelineId, 'stage', {
+          status: 'failed',
+          error: event.data.error,
+          attempts: event.data.attempts?.length
+        }, event.stageId);
+        break;
+      case 'log':
+        this.runEvents.push(
+          pipelineId,
+          event.data.level === 'warn' ? 'warning' : 'log',
+          { level: event.data.level, message: event.data.message },
+          event.stageId
+        );
+        break;
+    }
+  }
+
+  // Re-runs the unfinished stages of a failed or interrupted run. Completed stages are taken
+  // from the checkpoints in the conversation service, so this also works after a restart when the
+  // caller supplies the pipeline configuration again.
+  async resumePipeline(pipelineId: string, config?: MLPipelineConfig): Promise<{ resumedFrom: string } | null> {
+    const existing = this.executions.get(pipelineId);
+    if (existing?.status === 'running') {
+      throw new Error('Pipeline already running');
+    }
+
+    const runConfig = existing?.config || config;
+    if (!runConfig) return null;
+
+    const checkpoints = await this.runState.load(pipelineId);
+    for (const stage of runConfig.stages) {
+      const checkpoint = checkpoints[stage.id];
+      if (checkpoint && (checkpoint.status === 'completed' || checkpoint.status === 'skipped')) {
+        stage.status = checkpoint.status;
+        stage.skipReason = checkpoint.state.skipReason ?? stage.skipReason;
+        stage.outputs = checkpoint.state.outputs ?? stage.outputs;
+        stage.artifacts = checkpoint.state.artifacts ?? stage.artifacts;
+      }
+      // Stages skipped only because something they need failed run again once it does; stages
+      // skipped by their condition stay skipped
+      if (stage.status === 'skipped' && stage.skipReason === 'upstream') {
+        stage.status = 'idle';
+        stage.skipReason = undefined;
+      }
+    }
+
+    const unfinished = runConfig.stages.filter(stage => stage.status !== 'completed' && stage.status !== 'skipped');
+    if (unfinished.length === 0) {
+      throw new Error('Pipeline has no unfinished stages');
+    }
+
+    this.executions.set(pipelineId, {
+      id: pipelineId,
+      config: runConfig,
+      status: 'running',
+      progress: ((runConfig.stages.length - unfinished.length) / runConfig.stages.length) * 100,
+      startTime: existing?.startTime || new Date(),
+      results: existing?.results || {}
+    });
+
+    const resumedFrom = unfinished[0].id;
+    this.events.publish('pipeline.resumed', { pipelineId, name: runConfig.name, stageId: resumedFrom }, runConfig);
+    this.runEvents.push(pipelineId, 'run', { status: 'running', resumedFrom });
+
+    this.startRun(pipelineId, runConfig);
+
+    return { resumedFrom };
+  }
+
+  // Token and dollar cost per stage, agent and model. Runs no longer in memory (e.g. after a
+  // restart) are rebuilt from their checkpoints, which keep each stage's usage.
+  async getRunCost(pipelineId: string): Promise<RunCostReport | null> {
+    const execution = this.executions.get(pipelineId);
+    if (execution) return buildCostReport(pipelineId, execution.config.stages);
+
+    const checkpoints = Object.values(await this.runState.load(pipelineId));
+    if (checkpoints.length === 0) return null;
+
+    return buildCostReport(pipelineId, checkpoints.map(checkpoint => ({
+      id: checkpoint.stageId,
+      name: checkpoint.state.name || checkpoint.stageId,
+      status: checkpoint.status === 'failed' ? 'error' : checkpoint.status,
+      logs: [],
+      outputs: checkpoint.state.outputs,
+      artifacts: checkpoint.state.artifacts || [],
+      attempts: checkpoint.state.attempts,
+      usage: checkpoint.state.usage,
+      kind: checkpoint.state.kind,
+      agentRole: checkpoint.state.agentRole,
+      model: checkpoint.state.model
+    })));
   }
 
   async getPipelineStatus(pipelineId: string): Promise<PipelineExecution | null> {
     return this.executions.get(pipelineId) || null;
   }
 
+  // Who a run belongs to: from memory or, for runs this instance no longer holds, from the
+  // checkpoints of its stages
+  async getRunOwner(pipelineId: string): Promise<RunOwner | null> {
+    const execution = this.executions.get(pipelineId);
+    if (execution) return { projectId: execution.config.projectId, tenantId: execution.config.tenantId };
+
+    const [checkpoint] = Object.values(await this.runState.load(pipelineId));
+    return checkpoint ? { projectId: checkpoint.projectId, tenantId: checkpoint.tenantId } : null;
+  }
+
   async getAllPipelines(): Promise<PipelineExecution[]> {
     return Array.from(this.executions.values());
   }
@@ -284,6 +775,9 @@ export class PipelineService {
       this.processes.delete(pipelineId);
     }
 
+    // Stops in-flight agent stages on their worker, down to the LLM provider request
+    this.aborts.get(pipelineId)?.abort();
+
     execution.status = 'error';
     execution.endTime = new Date();
     
@@ -293,6 +787,8 @@ export class PipelineService {
       data: { level: 'info', message: 'Pipeline cancelled by user' },
       timestamp: new Date()
     });
+    this.events.publish('pipeline.cancelled', { pipelineId }, execution.config);
+    this.runEvents.push(pipelineId, 'run', { status: 'cancelled' });
 
     return true;
   }
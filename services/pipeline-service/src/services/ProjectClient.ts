@@ -0,0 +1,39 @@
+import axios from 'axios';
+
+export interface ProjectSummary {
+  _id: string;
+  ownerId: string;
+  organizationId?: string;
+}
+
+// Looks projects up in project-service as the caller, so a project the caller cannot see is
+// indistinguishable from one that does not exist
+export class ProjectClient {
+  private projectServiceUrl: string;
+
+  constructor(projectServiceUrl?: string) {
+    this.projectServiceUrl = projectServiceUrl || process.env.PROJECT_SERVICE_URL || 'http://localhost:3002';
+  }
+
+  // Null when the caller has no access; throws when project-service cannot answer
+  async get(projectId: string, authorization?: string): Promise<ProjectSummary | null> {
+    if (!authorization) return null;
+
+    try {
+      const response = await axios.get(`${this.projectServiceUrl}/api/projects/${encodeURIComponent(projectId)}`, {
+        headers: { Authorization: authorization },
+        timeout: 5000
+      });
+      return response.data.data;
+    } catch (error) {
+      if (!axios.isAxiosError(error) || !error.response) throw error;
+      return null;
+    }
+  }
+
+  // Runs are attributed to the organization that owns their project, or to the project's owner
+  // for projects outside an organization
+  static tenantOf(project: ProjectSummary): string {
+    return project.organizationId || project.ownerId;
+  }
+}
@@ -0,0 +1,112 @@
+import { EventEmitter } from 'events';
+
+export type RunStreamEventType = 'run' | 'stage' | 'log' | 'warning' | 'token';
+
+// One entry of a run's progress stream, numbered so a reconnecting client can resume
+export interface RunStreamEvent {
+  seq: number;
+  runId: string;
+  type: RunStreamEventType;
+  stageId?: string;
+  data: Record<string, any>;
+  time: string;
+}
+
+interface RunStream {
+  events: RunStreamEvent[];
+  nextSeq: number;
+  finishedAt?: number;
+  lastActivity: number;
+}
+
+const TERMINAL_STATUSES = ['completed', 'failed', 'cancelled'];
+
+// Keeps the progress of runs started on this instance so GET /runs/:id/events can replay what a
+// client missed and then follow along. Finished runs are dropped after a grace period, runs that
+// stopped reporting (e.g. their orchestrator died mid-run) once idle for longer than the TTL, and
+// the least recently active runs whenever more than maxRuns are held.
+export class RunEventStream {
+  private runs = new Map<string, RunStream>();
+  private emitter = new EventEmitter();
+  private sweeper: NodeJS.Timeout;
+
+  constructor(
+    private maxEventsPerRun: number = parseInt(process.env.RUN_STREAM_BUFFER_SIZE || '2000'),
+    private retainFinishedMs: number = parseInt(process.env.RUN_STREAM_RETAIN_MS || '900000'),
+    private idleTtlMs: number = parseInt(process.env.RUN_STREAM_IDLE_TTL_MS || '3600000'),
+    private maxRuns: number = parseInt(process.env.RUN_STREAM_MAX_RUNS || '500')
+  ) {
+    this.emitter.setMaxListeners(0);
+    this.sweeper = setInterval(() => this.evictStale(), 60000);
+    this.sweeper.unref();
+  }
+
+  push(runId: string, type: RunStreamEventType, data: Record<string, any>, stageId?: string): void {
+    let stream = this.runs.get(runId);
+    if (!stream) {
+      stream = { events: [], nextSeq: 1, lastActivity: Date.now() };
+    }
+    // Maps iterate in insertion order, so re-inserting keeps the least recently active run first
+    this.runs.delete(runId);
+    this.runs.set(runId, stream);
+    stream.lastActivity = Date.now();
+    this.evictOverflow(runId);
+
+    const event: RunStreamEvent = { seq: stream.nextSeq++, runId, type, stageId, data, time: new Date().toISOString() };
+    stream.events.push(event);
+    if (stream.events.length > this.maxEventsPerRun) {
+      // Token deltas are the bulk of a long run; drop those first so transitions stay replayable
+      const tokenIndex = stream.events.findIndex(candidate => candidate.type === 'token');
+      stream.events.splice(tokenIndex >= 0 ? tokenIndex : 0, 1);
+    }
+
+    if (type === 'run') {
+      stream.finishedAt = TERMINAL_STATUSES.includes(data.status) ? Date.now() : undefined;
+    }
+
+    this.emitter.emit(runId, event);
+  }
+
+  has(runId: string): boolean {
+    return this.runs.has(runId);
+  }
+
+  replay(runId: string, afterSeq = 0): RunStreamEvent[] {
+    return (this.runs.get(runId)?.events || []).filter(event => event.seq > afterSeq);
+  }
+
+  isFinished(runId: string): boolean {
+    return this.runs.get(runId)?.finishedAt !== undefined;
+  }
+
+  subscribe(runId: string, listener: (event: RunStreamEvent) => void): () => void {
+    this.emitter.on(runId, listener);
+    return () => this.emitter.off(runId, listener);
+  }
+
+  private evictStale(): void {
+    const now = Date.now();
+    for (const [runId, stream] of this.runs) {
+      const expired = stream.finishedAt !== undefined
+        ? stream.finishedAt < now - this.retainFinishedMs
+        : stream.lastActivity < now - this.idleTtlMs;
+      if (expired && this.emitter.listenerCount(runId) === 0) {
+        this.runs.delete(runId);
+      }
+    }
+  }
+
+  // Drops the least recently active runs over the cap, sparing followed ones while any other can go
+  private evictOverflow(current: string): void {
+    while (this.runs.size > Math.max(this.maxRuns, 1)) {
+      let victim: string | undefined;
+      for (const runId of this.runs.keys()) {
+        if (runId !== current && this.emitter.listenerCount(runId) === 0) {
+          victim = runId;
+          break;
+        }
+      }
+      this.runs.delete(victim ?? this.runs.keys().next().value!);
+    }
+  }
+}
@@ -0,0 +1,65 @@
+import axios from 'axios';
+import { LLMUsage, MLPipelineStage, RunOwner, StageAttempt } from '../types/index.js';
+
+export interface StageCheckpoint {
+  stageId: string;
+  projectId?: string;
+  tenantId?: string;
+  status: 'running' | 'completed' | 'failed' | 'skipped';
+  state: {
+    outputs?: any;
+    artifacts?: string[];
+    attempts?: StageAttempt[];
+    usage?: LLMUsage;
+    name?: string;
+    kind?: MLPipelineStage['kind'];
+    agentRole?: string;
+    model?: string;
+    skipReason?: MLPipelineStage['skipReason'];
+  };
+}
+
+// Checkpoints stage results in the conversation service so a failed or interrupted run can be
+// resumed from its first unfinished stage. Without CONVERSATION_SERVICE_URL nothing is persisted.
+export class RunStateClient {
+  private baseUrl: string | undefined;
+
+  constructor(baseUrl?: string) {
+    this.baseUrl = baseUrl || process.env.CONVERSATION_SERVICE_URL;
+  }
+
+  save(runId: string, stage: MLPipelineStage, owner: RunOwner): void {
+    if (!this.baseUrl) return;
+
+    axios.put(`${this.baseUrl}/api/conversations/state/${encodeURIComponent(runId)}/${encodeURIComponent(stage.id)}`, {
+      projectId: owner.projectId,
+      tenantId: owner.tenantId,
+      status: stage.status === 'error' ? 'failed' : stage.status,
+      state: {
+        outputs: stage.outputs,
+        artifacts: stage.artifacts,
+        attempts: stage.attempts,
+        usage: stage.usage,
+        name: stage.name,
+        kind: stage.kind,
+        agentRole: stage.agentRole,
+        model: stage.model,
+        skipReason: stage.skipReason
+      }
+    }, {
+      headers: { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' },
+      timeout: 5000
+    })
+      .catch(error => console.error(`Failed to checkpoint ${runId}/${stage.id}:`, error.message));
+  }
+
+  async load(runId: string): Promise<Record<string, StageCheckpoint>> {
+    if (!this.baseUrl) return {};
+
+    const response = await axios.get(`${this.baseUrl}/api/conversations/state/${encodeURIComponent(runId)}`, {
+      headers: { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' },
+      timeout: 5000
+    });
+    return response.data.data;
+  }
+}
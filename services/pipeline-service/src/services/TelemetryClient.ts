@@ -0,0 +1,46 @@
+import axios from 'axios';
+import { MLPipelineConfig, MLPipelineStage } from '../types/index.js';
+
+export interface StageOutcome {
+  status: 'completed' | 'failed';
+  output?: any;
+  retries: number;
+  error?: string;
+}
+
+// Reports per-stage duration, token usage, retries and failure reasons to the telemetry service
+export class TelemetryClient {
+  private baseUrl: string | undefined;
+
+  constructor(baseUrl?: string) {
+    this.baseUrl = baseUrl || process.env.TELEMETRY_SERVICE_URL;
+  }
+
+  recordStage(runId: string, config: MLPipelineConfig, stage: MLPipelineStage, outcome: StageOutcome): void {
+    if (!this.baseUrl) return;
+
+    const startedAt = stage.startTime || new Date();
+    // Agent and review stages return the LLM usage of their call
+    const usage = outcome.output?.usage || {};
+
+    axios.post(`${this.baseUrl}/api/telemetry/metrics/stages`, {
+      runId,
+      stageId: stage.id,
+      stageName: stage.name,
+      pipeline: config.definition,
+      projectId: config.projectId,
+      service: 'pipeline-service',
+      agentRole: stage.agentRole,
+      model: outcome.output?.model || stage.model,
+      status: outcome.status,
+      durationMs: Date.now() - startedAt.getTime(),
+      promptTokens: usage.promptTokens,
+      completionTokens: usage.completionTokens,
+      costUsd: usage.costUsd,
+      retries: outcome.retries,
+      failureReason: outcome.error,
+      startedAt: startedAt.toISOString()
+    }, { timeout: 5000 })
+      .catch(error => console.error(`Failed to report telemetry for ${runId}/${stage.id}:`, error.message));
+  }
+}
@@ -2,14 +2,78 @@
 export interface MLPipelineStage {
   id: string;
   name: string;
-  status: 'idle' | 'running' | 'completed' | 'error';
+  status: 'idle' | 'running' | 'completed' | 'error' | 'skipped';
   startTime?: Date;
   endTime?: Date;
   logs: string[];
   outputs: any;
   artifacts: string[];
+  kind?: 'agent' | 'review' | 'secret-scan' | 'quality-gate' | 'preview' | 'diagram' | 'moderation';
+  agentRole?: string;
+  model?: string;
+  inputs?: Record<string, any>;
+  condition?: string;
+  // Stages that must finish before this one starts; defaults to the stage listed before it
+  needs?: string[];
+  onFailure?: StageFailurePolicy;
+  retry?: StageRetryPolicy;
+  // Per attempt; a stage that runs longer fails that attempt
+  timeoutMs?: number;
+  fallback?: StageFallback;
+  // Tool scopes the stage grants its agent on top of the role's own, e.g. mcp:github
+  toolScopes?: string[];
+  // JSON Schema the agent's output must match, and how many repair prompts it gets; override the role's
+  outputSchema?: Record<string, any>;
+  outputRepairs?: number;
+  // Every try of the stage in this run, including retries and the fallback
+  attempts?: StageAttempt[];
+  // LLM tokens and cost of the successful attempt, as reported by the LLM gateway
+  usage?: LLMUsage;
+  // Why a skipped stage was skipped: a stage it needs did not complete, or its condition was not met
+  skipReason?: 'upstream' | 'condition';
+}
+
+export interface LLMUsage {
+  promptTokens: number;
+  completionTokens: number;
+  totalTokens: number;
+  costUsd?: number;
 }
 
+export interface StageRetryPolicy {
+  // Tries with the stage's own agent and model, including the first
+  attempts?: number;
+  backoff?: 'fixed' | 'exponential';
+  // Wait before the first retry; exponential backoff doubles it for each further retry
+  delayMs?: number;
+}
+
+// Tried once after the stage's own attempts are used up
+export interface StageFallback {
+  agent?: string;
+  model?: string;
+}
+
+// output_invalid: the agent's output still failed its schema after the agent service's repair prompts
+export type StageErrorCode = 'output_invalid';
+
+export interface StageAttempt {
+  attempt: number;
+  fallback: boolean;
+  agentRole?: string;
+  model?: string;
+  status: 'completed' | 'failed';
+  error?: string;
+  // Set for failures the orchestrator can tell apart from the message
+  errorCode?: StageErrorCode;
+  startedAt: string;
+  durationMs: number;
+}
+
+// stop: a failure fails the run and no further stages start
+// continue: only the failed stage's branch is dropped (its dependents are skipped) and the rest of the graph runs on
+export type StageFailurePolicy = 'stop' | 'continue';
+
 export interface MLPipelineConfig {
   id: string;
   name: string;
@@ -18,6 +82,42 @@ export interface MLPipelineConfig {
   dataPath?: string;
   modelConfig?: any;
   outputPath?: string;
+  definition?: string;
+  projectId?: string;
+  // Tenant the run belongs to and the request that started it, for event attribution. Project runs
+  // belong to the project's organization (or its owner); other runs to the user who started them.
+  tenantId?: string;
+  requestId?: string;
+}
+
+// Who a run belongs to, for access checks
+export type RunOwner = Pick<MLPipelineConfig, 'projectId' | 'tenantId'>;
+
+// Declarative pipeline definitions (pipelines/*.yaml)
+export interface PipelineStageDefinition {
+  id: string;
+  name: string;
+  type?: 'agent' | 'review' | 'secret-scan' | 'quality-gate' | 'preview' | 'diagram' | 'moderation';
+  agent?: string;
+  model?: string;
+  inputs?: Record<string, any>;
+  outputs?: string[];
+  when?: string;
+  needs?: string[];
+  onFailure?: StageFailurePolicy;
+  retry?: StageRetryPolicy;
+  timeoutMs?: number;
+  fallback?: StageFallback;
+  toolScopes?: string[];
+  outputSchema?: Record<string, any>;
+  outputRepairs?: number;
+}
+
+export interface PipelineDefinition {
+  name: string;
+  description?: string;
+  version: number;
+  stages: PipelineStageDefinition[];
 }
 
 export interface PipelineExecution {
@@ -53,6 +153,10 @@ export interface PipelineJob {
   pipelineId: string;
   stage: MLPipelineStage;
   config: MLPipelineConfig;
+  previousOutputs?: Record<string, any>;
+  attempt?: number;
+  // False while the orchestrator still has retries or a fallback left; only the last attempt is dead-lettered
+  lastAttempt?: boolean;
 }
 
 export interface JobResult {
@@ -60,4 +164,14 @@ export interface JobResult {
   output?: any;
   logs: string[];
   artifacts?: string[];
+  error?: string;
+  // Set by the queue: 1 on the first try, higher when the stage was retried
+  attempts?: number;
+}
+
+export interface DeadLetterJob {
+  job: PipelineJob;
+  error: string;
+  attempts: number;
+  failedAt: string;
 }
\ No newline at end of file
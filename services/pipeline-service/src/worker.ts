@@ -0,0 +1,41 @@
+import { config } from 'dotenv';
+import winston from 'winston';
+import { JobQueue } from './queue/JobQueue.js';
+import { StageExecutor } from './queue/StageExecutor.js';
+
+// Load environment variables
+config();
+
+// Configure logger
+const logger = winston.createLogger({
+  level: process.env.LOG_LEVEL || 'info',
+  format: winston.format.combine(
+    winston.format.timestamp(),
+    winston.format.errors({ stack: true }),
+    winston.format.json()
+  ),
+  transports: [
+    new winston.transports.Console({
+      format: winston.format.simple()
+    })
+  ]
+});
+
+// Standalone stage worker; run several of these to scale stage execution horizontally
+const queue = new JobQueue();
+const executor = new StageExecutor();
+
+queue.process(async (job, options) => {
+  logger.info(`Executing stage ${job.data.stage.id} of ${job.data.pipelineId} (attempt ${job.data.attempt || job.attemptsMade + 1})`);
+  return executor.execute(job.data, (message) => {
+    job.log(message).catch(() => undefined);
+  }, options);
+});
+
+process.on('SIGTERM', async () => {
+  logger.info('SIGTERM received. Draining stage worker...');
+  await queue.close();
+  process.exit(0);
+});
+
+logger.info('⚙️  Pipeline stage worker started');
@@ -0,0 +1,77 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export type PreviewTargetKind = 'compose' | 'kubernetes';
+export type PreviewStatus = 'building' | 'deploying' | 'ready' | 'failed' | 'stopped';
+
+export interface IPreviewDeployment extends Document {
+  // DNS-safe name used for the compose project, namespace and hostname
+  name: string;
+  projectId?: string;
+  runId?: string;
+  stageId?: string;
+  target: PreviewTargetKind;
+  status: PreviewStatus;
+  runtime?: string;
+  image?: string;
+  port?: number;
+  // Published port on the runner (compose) or namespace (kubernetes) holding the preview
+  hostPort?: number;
+  namespace?: string;
+  url?: string;
+  logs: string[];
+  error?: string;
+  createdBy?: string;
+  expiresAt: Date;
+  readyAt?: Date;
+  stoppedAt?: Date;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const PreviewDeploymentSchema: Schema = new Schema({
+  name: {
+    type: String,
+    required: true,
+    unique: true
+  },
+  projectId: {
+    type: String,
+    index: true
+  },
+  runId: {
+    type: String,
+    index: true
+  },
+  stageId: { type: String },
+  target: {
+    type: String,
+    enum: ['compose', 'kubernetes'],
+    required: true
+  },
+  status: {
+    type: String,
+    enum: ['building', 'deploying', 'ready', 'failed', 'stopped'],
+    default: 'building',
+    index: true
+  },
+  runtime: { type: String },
+  image: { type: String },
+  port: { type: Number },
+  hostPort: { type: Number },
+  namespace: { type: String },
+  url: { type: String },
+  logs: [{ type: String }],
+  error: { type: String },
+  createdBy: { type: String },
+  expiresAt: {
+    type: Date,
+    required: true,
+    index: true
+  },
+  readyAt: { type: Date },
+  stoppedAt: { type: Date }
+}, {
+  timestamps: true
+});
+
+export const PreviewDeployment = mongoose.model<IPreviewDeployment>('PreviewDeployment', PreviewDeploymentSchema);
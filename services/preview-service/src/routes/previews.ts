@@ -0,0 +1,285 @@
+import express, { Request, Response } from 'express';
+import { body, param, query, validationResult } from 'express-validator';
+import axios from 'axios';
+import { IPreviewDeployment, PreviewDeployment } from '../models/PreviewDeployment.js';
+import { PreviewDeployer } from '../services/PreviewDeployer.js';
+import { AuthenticatedRequest, requireAuth, requireServiceToken } from '../middleware/auth.js';
+
+const router = express.Router();
+export const deployer = new PreviewDeployer();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const MAX_TTL_HOURS = parseFloat(process.env.PREVIEW_MAX_TTL_HOURS || '168');
+
+// Confirms through project-service that the caller is a member of the preview's project. Admins see
+// every preview; previews of runs without a project only whoever started the run.
+const canAccessPreview = async (
+  req: AuthenticatedRequest,
+  preview: Pick<IPreviewDeployment, 'projectId' | 'createdBy'>
+): Promise<boolean> => {
+  if (req.user!.role === 'admin') return true;
+  if (!preview.projectId) return !!preview.createdBy && preview.createdBy === req.user!._id;
+
+  const projectServiceUrl = process.env.PROJECT_SERVICE_URL || 'http://localhost:3002';
+  try {
+    await axios.get(`${projectServiceUrl}/api/projects/${encodeURIComponent(preview.projectId)}`, {
+      headers: { Authorization: req.headers.authorization },
+      timeout: 5000
+    });
+    return true;
+  } catch (error) {
+    if (!axios.isAxiosError(error) || !error.response) throw error;
+    return false;
+  }
+};
+
+// Loads a preview the caller may see. Answers 404 for missing and hidden previews alike, and 502
+// when project-service cannot answer; resolves to null once it has answered.
+const findPreview = async (req: AuthenticatedRequest, res: Response): Promise<IPreviewDeployment | null> => {
+  const deployment = await PreviewDeployment.findById(req.params.id);
+  let visible = false;
+  try {
+    visible = !!deployment && await canAccessPreview(req, deployment);
+  } catch (error) {
+    console.error('Project access check error:', error);
+    res.status(502).json({
+      success: false,
+      error: 'Failed to check project access'
+    });
+    return null;
+  }
+
+  if (!visible) {
+    res.status(404).json({
+      success: false,
+      error: 'Preview not found'
+    });
+    return null;
+  }
+  return deployment;
+};
+
+// POST /api/previews/builds - Package, build and deploy generated files (internal; builds run
+// arbitrary Dockerfiles, so only the orchestrator may start them)
+router.post('/builds',
+  requireServiceToken,
+  [
+    body('files').isArray({ min: 1 }).withMessage('Files must be a non-empty array'),
+    body('files.*.path').isString().isLength({ min: 1 }).withMessage('Each file needs a path'),
+    body('files.*.content').isString().withMessage('Each file needs content'),
+    body('target').optional().isIn(['compose', 'kubernetes']).withMessage('Target must be compose or kubernetes'),
+    body('port').optional().isInt({ min: 1, max: 65535 }).withMessage('Port must be between 1 and 65535'),
+    body('ttlHours').optional().isFloat({ min: 0.1, max: MAX_TTL_HOURS }).withMessage(`TTL must be between 0.1 and ${MAX_TTL_HOURS} hours`),
+    body('name').optional().isString().isLength({ max: 60 }).withMessage('Name must be at most 60 characters')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const deployment = await deployer.start({
+        files: req.body.files,
+        name: req.body.name,
+        projectId: req.body.projectId,
+        runId: req.body.runId,
+        stageId: req.body.stageId,
+        target: req.body.target,
+        port: req.body.port ? parseInt(req.body.port) : undefined,
+        ttlHours: req.body.ttlHours ? parseFloat(req.body.ttlHours) : undefined,
+        createdBy: req.body.createdBy
+      });
+
+      res.status(202).json({
+        success: true,
+        data: deployment,
+        message: 'Preview build started'
+      });
+    } catch (error) {
+      console.error('Error starting preview:', error);
+      res.status(400).json({
+        success: false,
+        error: error instanceof Error ? error.message : 'Failed to start preview'
+      });
+    }
+  }
+);
+
+// GET /api/previews/builds/:id - Build status for the orchestrator to poll (internal)
+router.get('/builds/:id',
+  requireServiceToken,
+  [param('id').isMongoId().withMessage('Invalid preview ID')],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const deployment = await PreviewDeployment.findById(req.params.id).select('-logs');
+      if (!deployment) {
+        return res.status(404).json({
+          success: false,
+          error: 'Preview not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: deployment
+      });
+    } catch (error) {
+      console.error('Error fetching preview build:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch preview build'
+      });
+    }
+  }
+);
+
+// GET /api/previews/deployments - Previews for a project or run
+router.get('/deployments',
+  requireAuth,
+  [
+    query('projectId').optional().isString().withMessage('Project ID must be a string'),
+    query('runId').optional().isString().withMessage('Run ID must be a string'),
+    query('status').optional().isIn(['building', 'deploying', 'ready', 'failed', 'stopped']).withMessage('Invalid status')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const filter: any = {};
+      if (req.query.projectId) {
+        if (!await canAccessPreview(req, { projectId: req.query.projectId as string })) {
+          return res.status(404).json({
+            success: false,
+            error: 'Project not found'
+          });
+        }
+        filter.projectId = req.query.projectId;
+      } else if (req.user!.role !== 'admin') {
+        // Without a project, only the previews of the caller's own runs
+        filter.projectId = { $exists: false };
+        filter.createdBy = req.user!._id;
+      }
+      if (req.query.runId) filter.runId = req.query.runId;
+      if (req.query.status) filter.status = req.query.status;
+
+      const deployments = await PreviewDeployment.find(filter)
+        .select('-logs')
+        .sort({ createdAt: -1 })
+        .limit(100);
+
+      res.json({
+        success: true,
+        data: deployments
+      });
+    } catch (error) {
+      console.error('Error fetching previews:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch previews'
+      });
+    }
+  }
+);
+
+// GET /api/previews/deployments/:id - Preview with its build and deploy log
+router.get('/deployments/:id',
+  requireAuth,
+  [param('id').isMongoId().withMessage('Invalid preview ID')],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const deployment = await findPreview(req, res);
+      if (!deployment) return;
+
+      res.json({
+        success: true,
+        data: deployment
+      });
+    } catch (error) {
+      console.error('Error fetching preview:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch preview'
+      });
+    }
+  }
+);
+
+// POST /api/previews/deployments/:id/extend - Keep a ready preview up for longer
+router.post('/deployments/:id/extend',
+  requireAuth,
+  [
+    param('id').isMongoId().withMessage('Invalid preview ID'),
+    body('hours').isFloat({ min: 0.1, max: MAX_TTL_HOURS }).withMessage(`Hours must be between 0.1 and ${MAX_TTL_HOURS}`)
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const deployment = await findPreview(req, res);
+      if (!deployment) return;
+      if (deployment.status !== 'ready') {
+        return res.status(409).json({
+          success: false,
+          error: `Only ready previews can be extended (this one is ${deployment.status})`
+        });
+      }
+
+      deployment.expiresAt = new Date(Date.now() + parseFloat(req.body.hours) * 60 * 60 * 1000);
+      await deployment.save();
+
+      res.json({
+        success: true,
+        data: deployment,
+        message: 'Preview extended'
+      });
+    } catch (error) {
+      console.error('Error extending preview:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to extend preview'
+      });
+    }
+  }
+);
+
+// DELETE /api/previews/deployments/:id - Tear the preview down
+router.delete('/deployments/:id',
+  requireAuth,
+  [param('id').isMongoId().withMessage('Invalid preview ID')],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const deployment = await findPreview(req, res);
+      if (!deployment) return;
+      if (deployment.status === 'building' || deployment.status === 'deploying') {
+        return res.status(409).json({
+          success: false,
+          error: 'Preview is still deploying; stop it once it is ready or has failed'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: await deployer.stop(deployment),
+        message: 'Preview stopped'
+      });
+    } catch (error) {
+      console.error('Error stopping preview:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to stop preview'
+      });
+    }
+  }
+);
+
+export default router;
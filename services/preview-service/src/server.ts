@@ -0,0 +1,129 @@
+import express from 'express';
+import cors from 'cors';
+import mongoose from 'mongoose';
+import dotenv from 'dotenv';
+import winston from 'winston';
+import previewRoutes, { deployer } from './routes/previews.js';
+
+// Load environment variables
+dotenv.config();
+
+const app = express();
+const PORT = process.env.PORT || 3018;
+
+// Logger configuration
+const logger = winston.createLogger({
+  level: 'info',
+  format: winston.format.combine(
+    winston.format.timestamp(),
+    winston.format.json()
+  ),
+  transports: [
+    new winston.transports.Console(),
+    new winston.transports.File({ filename: 'logs/preview-service.log' })
+  ]
+});
+
+// Database connection
+const MONGODB_URI = process.env.MONGODB_URI || 'mongodb://localhost:27017/ai-pipeline';
+
+mongoose.connect(MONGODB_URI)
+  .then(() => {
+    logger.info('🗄️  Connected to MongoDB');
+  })
+  .catch((error) => {
+    logger.error('❌ MongoDB connection error:', error);
+    process.exit(1);
+  });
+
+// Middleware
+app.use(cors({
+  origin: process.env.FRONTEND_URL || 'http://localhost:5173',
+  credentials: true
+}));
+app.use(express.json({ limit: '50mb' }));
+app.use(express.urlencoded({ extended: true }));
+
+// Request logging middleware
+app.use((req, res, next) => {
+  logger.info(`${req.method} ${req.path}`, {
+    ip: req.ip,
+    userAgent: req.get('User-Agent'),
+    timestamp: new Date().toISOString()
+  });
+  next();
+});
+
+// Routes
+app.use('/api/previews', previewRoutes);
+
+// Health check endpoint
+app.get('/health', (req, res) => {
+  res.json({
+    status: 'ok',
+    service: 'preview-service',
+    timestamp: new Date().toISOString(),
+    version: '1.0.0',
+    database: mongoose.connection.readyState === 1 ? 'connected' : 'disconnected'
+  });
+});
+
+// Service info endpoint
+app.get('/info', (req, res) => {
+  res.json({
+    service: 'Preview Service',
+    version: '1.0.0',
+    description: 'Builds generated applications and deploys them to preview environments',
+    endpoints: [
+      { method: 'POST', path: '/api/previews/builds', description: 'Build and deploy generated files' },
+      { method: 'GET', path: '/api/previews/builds/:id', description: 'Build status' },
+      { method: 'GET', path: '/api/previews/deployments', description: 'List previews' },
+      { method: 'GET', path: '/api/previews/deployments/:id', description: 'Preview with build log' },
+      { method: 'POST', path: '/api/previews/deployments/:id/extend', description: 'Extend a preview' },
+      { method: 'DELETE', path: '/api/previews/deployments/:id', description: 'Stop a preview' }
+    ]
+  });
+});
+
+// Catch-all for undefined routes
+app.use('*', (req, res) => {
+  res.status(404).json({
+    success: false,
+    error: 'Route not found',
+    service: 'preview-service'
+  });
+});
+
+// Global error handler
+app.use((err: any, req: any, res: any, next: any) => {
+  logger.error('Unhandled error:', err);
+  res.status(500).json({
+    success: false,
+    error: 'Internal server error',
+    service: 'preview-service'
+  });
+});
+
+// Expired previews are torn down in the background
+const sweepInterval = setInterval(() => {
+  deployer.sweepExpired()
+    .then(count => {
+      if (count > 0) logger.info(`Stopped ${count} expired preview(s)`);
+    })
+    .catch(error => logger.error('Error sweeping expired previews:', error));
+}, parseInt(process.env.PREVIEW_SWEEP_INTERVAL_MS || '600000'));
+
+// Graceful shutdown
+process.on('SIGTERM', () => {
+  logger.info('SIGTERM received. Shutting down gracefully...');
+  clearInterval(sweepInterval);
+  mongoose.connection.close();
+  process.exit(0);
+});
+
+// Start server
+app.listen(PORT, () => {
+  logger.info(`🌐 Preview Service running on port ${PORT}`);
+});
+
+export default app;
@@ -0,0 +1,65 @@
+import { spawn } from 'child_process';
+
+export interface CommandOptions {
+  cwd?: string;
+  // Written to stdin, e.g. manifests for `kubectl apply -f -`
+  input?: string;
+  timeoutMs?: number;
+  // Receives output as it arrives so long builds show progress
+  onOutput?: (line: string) => void;
+}
+
+const MAX_OUTPUT_CHARS = 32000;
+
+export class CommandError extends Error {
+  constructor(command: string, public exitCode: number | null, public output: string) {
+    super(`${command} exited with ${exitCode ?? 'a signal'}: ${output.slice(-500).trim()}`);
+    this.name = 'CommandError';
+  }
+}
+
+// Runs a CLI (docker, kubectl) and resolves with its combined output, rejecting on a non-zero exit
+export function runCommand(command: string, args: string[], options: CommandOptions = {}): Promise<string> {
+  return new Promise((resolve, reject) => {
+    const child = spawn(command, args, { cwd: options.cwd });
+    let output = '';
+    let pending = '';
+
+    const append = (chunk: Buffer) => {
+      const text = chunk.toString();
+      output = (output + text).slice(-MAX_OUTPUT_CHARS);
+
+      if (options.onOutput) {
+        const lines = (pending + text).split('\n');
+        pending = lines.pop() || '';
+        lines.filter(line => line.trim()).forEach(line => options.onOutput!(line));
+      }
+    };
+    child.stdout.on('data', append);
+    child.stderr.on('data', append);
+
+    const timer = options.timeoutMs
+      ? setTimeout(() => child.kill('SIGKILL'), options.timeoutMs)
+      : undefined;
+
+    child.on('error', (error) => {
+      if (timer) clearTimeout(timer);
+      reject(error);
+    });
+    child.on('close', (exitCode) => {
+      if (timer) clearTimeout(timer);
+      if (pending.trim() && options.onOutput) options.onOutput(pending);
+      if (exitCode === 0) {
+        resolve(output);
+      } else {
+        reject(new CommandError(`${command} ${args[0]}`, exitCode, output));
+      }
+    });
+
+    if (options.input !== undefined) {
+      child.stdin.end(options.input);
+    } else {
+      child.stdin.end();
+    }
+  });
+}
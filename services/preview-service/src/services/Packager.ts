@@ -0,0 +1,123 @@
+import { promises as fs } from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+
+export interface GeneratedFile {
+  path: string;
+  content: string;
+}
+
+export type PreviewRuntime = 'dockerfile' | 'node' | 'static-build' | 'go' | 'static';
+
+export interface PackagedApp {
+  workspace: string;
+  runtime: PreviewRuntime;
+  // Port the application listens on inside the container
+  port: number;
+}
+
+const DOCKERIGNORE = ['node_modules', '.git', 'dist', 'coverage', '.env'].join('\n');
+
+const DOCKERFILES: Record<Exclude<PreviewRuntime, 'dockerfile'>, (port: number) => string> = {
+  node: (port) => `FROM node:20-alpine
+WORKDIR /app
+COPY . .
+RUN npm install --no-audit --no-fund && npm run build --if-present
+ENV NODE_ENV=production PORT=${port}
+EXPOSE ${port}
+CMD ["npm", "start"]
+`,
+  // Front-end projects with a build script but no server: build, then serve the output with nginx
+  'static-build': () => `FROM node:20-alpine AS build
+WORKDIR /app
+COPY . .
+RUN npm install --no-audit --no-fund && npm run build
+
+FROM nginx:alpine
+COPY --from=build /app/dist /usr/share/nginx/html
+EXPOSE 80
+`,
+  go: (port) => `FROM golang:1.22-alpine AS build
+WORKDIR /src
+COPY . .
+RUN go build -o /out/app .
+
+FROM alpine:3.20
+COPY --from=build /out/app /app
+ENV PORT=${port}
+EXPOSE ${port}
+CMD ["/app"]
+`,
+  static: () => `FROM nginx:alpine
+COPY . /usr/share/nginx/html
+EXPOSE 80
+`
+};
+
+const DEFAULT_PORTS: Record<PreviewRuntime, number> = {
+  dockerfile: 3000,
+  node: 3000,
+  'static-build': 80,
+  go: 8080,
+  static: 80
+};
+
+// Writes generated files into a build context and adds a Dockerfile for the detected runtime when
+// the project does not ship its own
+export class Packager {
+  detect(files: Map<string, string>): PreviewRuntime {
+    if (files.has('Dockerfile')) return 'dockerfile';
+    if (files.has('package.json')) {
+      const scripts = this.packageScripts(files.get('package.json')!);
+      return !scripts.start && scripts.build ? 'static-build' : 'node';
+    }
+    if (files.has('go.mod')) return 'go';
+    if (files.has('index.html')) return 'static';
+    throw new Error('Cannot tell how to run this project: add a Dockerfile, package.json, go.mod or index.html');
+  }
+
+  async package(files: GeneratedFile[], port?: number): Promise<PackagedApp> {
+    const byPath = new Map(files.map(file => [file.path.replace(/^\.\//, ''), file.content]));
+    const runtime = this.detect(byPath);
+    const workspace = await fs.mkdtemp(path.join(process.env.PREVIEW_WORKDIR || os.tmpdir(), 'preview-'));
+
+    for (const [relative, content] of byPath) {
+      const target = path.resolve(workspace, relative);
+      // Generated paths are untrusted; never write outside the build context
+      if (!target.startsWith(workspace + path.sep)) {
+        throw new Error(`Refusing to write file outside the build context: ${relative}`);
+      }
+      await fs.mkdir(path.dirname(target), { recursive: true });
+      await fs.writeFile(target, content);
+    }
+
+    const exposed = runtime === 'dockerfile' ? this.exposedPort(byPath.get('Dockerfile')!) : undefined;
+    const resolvedPort = port || exposed || DEFAULT_PORTS[runtime];
+
+    if (runtime !== 'dockerfile') {
+      await fs.writeFile(path.join(workspace, 'Dockerfile'), DOCKERFILES[runtime](resolvedPort));
+    }
+    if (!byPath.has('.dockerignore')) {
+      await fs.writeFile(path.join(workspace, '.dockerignore'), DOCKERIGNORE);
+    }
+
+    return { workspace, runtime, port: resolvedPort };
+  }
+
+  async cleanup(workspace: string): Promise<void> {
+    await fs.rm(workspace, { recursive: true, force: true });
+  }
+
+  private exposedPort(dockerfile: string): number | undefined {
+    const match = dockerfile.match(/^\s*EXPOSE\s+(\d+)/im);
+    return match ? parseInt(match[1]) : undefined;
+  }
+
+  private packageScripts(packageJson: string): Record<string, string> {
+    try {
+      return JSON.parse(packageJson).scripts || {};
+    } catch {
+      return {};
+    }
+  }
+}
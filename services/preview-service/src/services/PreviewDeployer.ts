@@ -0,0 +1,151 @@
+import { randomBytes } from 'crypto';
+import { PreviewDeployment, IPreviewDeployment, PreviewTargetKind } from '../models/PreviewDeployment.js';
+import { createPreviewTarget, DEFAULT_TARGET } from '../targets/createPreviewTarget.js';
+import { Packager, GeneratedFile } from './Packager.js';
+import { runCommand } from './CommandRunner.js';
+
+export interface PreviewRequest {
+  files: GeneratedFile[];
+  name?: string;
+  projectId?: string;
+  runId?: string;
+  stageId?: string;
+  target?: PreviewTargetKind;
+  port?: number;
+  ttlHours?: number;
+  createdBy?: string;
+}
+
+const MAX_LOG_LINES = 500;
+const DEFAULT_TTL_HOURS = parseFloat(process.env.PREVIEW_TTL_HOURS || '48');
+const BUILD_TIMEOUT_MS = parseInt(process.env.PREVIEW_BUILD_TIMEOUT_MS || '900000');
+
+// Lowercase, DNS-label safe, with a random suffix so reruns of the same project never collide
+const previewName = (base: string | undefined) => {
+  const slug = (base || 'app').toLowerCase().replace(/[^a-z0-9-]+/g, '-').replace(/^-+|-+$/g, '').slice(0, 40) || 'app';
+  return `${slug}-${randomBytes(3).toString('hex')}`;
+};
+
+// Packages generated files, builds an image and deploys it to a preview target. Deploys run in the
+// background; callers poll the deployment until it is ready or failed.
+export class PreviewDeployer {
+  constructor(private packager: Packager = new Packager()) {}
+
+  async start(request: PreviewRequest): Promise<IPreviewDeployment> {
+    const target = request.target || DEFAULT_TARGET;
+    if (createPreviewTarget(target).needsRegistry && !process.env.PREVIEW_REGISTRY) {
+      throw new Error(`${target} previews require PREVIEW_REGISTRY to push images to`);
+    }
+
+    const ttlHours = request.ttlHours || DEFAULT_TTL_HOURS;
+    const deployment = await PreviewDeployment.create({
+      name: previewName(request.name),
+      projectId: request.projectId,
+      runId: request.runId,
+      stageId: request.stageId,
+      target,
+      status: 'building',
+      createdBy: request.createdBy,
+      expiresAt: new Date(Date.now() + ttlHours * 60 * 60 * 1000)
+    });
+
+    this.deploy(deployment, request).catch(error => {
+      console.error(`Preview ${deployment.name} failed:`, error);
+    });
+
+    return deployment;
+  }
+
+  async stop(deployment: IPreviewDeployment): Promise<IPreviewDeployment> {
+    const teardown = createPreviewTarget(deployment.target).teardown(deployment);
+    if (deployment.status === 'failed') {
+      // A failed deploy may have left half-started resources behind, or none at all
+      await teardown.catch(() => undefined);
+    } else {
+      await teardown;
+    }
+    deployment.status = 'stopped';
+    deployment.stoppedAt = new Date();
+    await deployment.save();
+    return deployment;
+  }
+
+  // Tears down previews past their expiry; runs on an interval from server.ts
+  async sweepExpired(): Promise<number> {
+    const expired = await PreviewDeployment.find({ status: 'ready', expiresAt: { $lte: new Date() } });
+
+    for (const deployment of expired) {
+      try {
+        await this.stop(deployment);
+      } catch (error) {
+        console.error(`Error stopping expired preview ${deployment.name}:`, error);
+      }
+    }
+    return expired.length;
+  }
+
+  private async deploy(deployment: IPreviewDeployment, request: PreviewRequest): Promise<void> {
+    const target = createPreviewTarget(deployment.target);
+    const log = this.logger(deployment);
+    let workspace: string | undefined;
+
+    try {
+      const packaged = await this.packager.package(request.files, request.port);
+      workspace = packaged.workspace;
+      log(`Packaged ${request.files.length} files as a ${packaged.runtime} app on port ${packaged.port}`);
+
+      const registry = process.env.PREVIEW_REGISTRY;
+      const image = `${registry ? `${registry.replace(/\/$/, '')}/` : ''}ai-pipeline-preview/${deployment.name}:latest`;
+
+      await runCommand('docker', ['build', '--pull', '-t', image, '.'], {
+        cwd: workspace,
+        timeoutMs: BUILD_TIMEOUT_MS,
+        onOutput: log
+      });
+      if (target.needsRegistry) {
+        await runCommand('docker', ['push', image], { timeoutMs: BUILD_TIMEOUT_MS, onOutput: log });
+      }
+
+      await PreviewDeployment.updateOne({ _id: deployment._id }, {
+        status: 'deploying',
+        image,
+        runtime: packaged.runtime,
+        port: packaged.port
+      });
+
+      const deployed = await target.deploy({
+        deploymentId: deployment.id,
+        name: deployment.name,
+        image,
+        port: packaged.port
+      }, log);
+
+      await PreviewDeployment.updateOne({ _id: deployment._id }, {
+        status: 'ready',
+        url: deployed.url,
+        hostPort: deployed.hostPort,
+        namespace: deployed.namespace,
+        readyAt: new Date()
+      });
+      log(`Preview ready at ${deployed.url}`);
+    } catch (error) {
+      await PreviewDeployment.updateOne({ _id: deployment._id }, {
+        status: 'failed',
+        error: error instanceof Error ? error.message : String(error)
+      });
+      throw error;
+    } finally {
+      if (workspace) await this.packager.cleanup(workspace);
+    }
+  }
+
+  // Appends to the deployment's capped build log without waiting on every write
+  private logger(deployment: IPreviewDeployment): (line: string) => void {
+    return (line: string) => {
+      PreviewDeployment.updateOne(
+        { _id: deployment._id },
+        { $push: { logs: { $each: [line.slice(0, 2000)], $slice: -MAX_LOG_LINES } } }
+      ).catch(error => console.error(`Failed to append preview log for ${deployment.name}:`, error.message));
+    };
+  }
+}
@@ -0,0 +1,88 @@
+import { promises as fs } from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { PreviewDeployment, IPreviewDeployment } from '../models/PreviewDeployment.js';
+import { runCommand } from '../services/CommandRunner.js';
+import { PreviewTarget, PreviewSpec, DeployedPreview } from './PreviewTarget.js';
+
+// Runs each preview as its own docker compose project on the runner, published on a port from
+// PREVIEW_PORT_RANGE (e.g. 4100-4199) and reachable under PREVIEW_PUBLIC_HOST
+export class ComposeTarget implements PreviewTarget {
+  readonly kind = 'compose' as const;
+  readonly needsRegistry = false;
+
+  private portRange: [number, number];
+  private publicHost: string;
+  private allocation: Promise<unknown> = Promise.resolve();
+
+  constructor() {
+    const [from, to] = (process.env.PREVIEW_PORT_RANGE || '4100-4199').split('-').map(value => parseInt(value));
+    this.portRange = [from, to || from];
+    this.publicHost = process.env.PREVIEW_PUBLIC_HOST || 'http://localhost';
+  }
+
+  async deploy(spec: PreviewSpec, log: (line: string) => void): Promise<DeployedPreview> {
+    const hostPort = await this.allocatePort(spec.deploymentId);
+    const project = this.projectName(spec.name);
+    const dir = await fs.mkdtemp(path.join(os.tmpdir(), `${project}-`));
+    const file = path.join(dir, 'docker-compose.yml');
+
+    await fs.writeFile(file, JSON.stringify({
+      services: {
+        app: {
+          image: spec.image,
+          restart: 'unless-stopped',
+          ports: [`${hostPort}:${spec.port}`],
+          environment: { PORT: String(spec.port) },
+          labels: { 'ai-pipeline.preview': spec.deploymentId },
+          mem_limit: process.env.PREVIEW_MEMORY || '512m',
+          cpus: parseFloat(process.env.PREVIEW_CPUS || '0.5')
+        }
+      }
+    }, null, 2));
+
+    try {
+      log(`Starting compose project ${project} on port ${hostPort}`);
+      await runCommand('docker', ['compose', '-p', project, '-f', file, 'up', '-d', '--wait'], {
+        timeoutMs: 180000,
+        onOutput: log
+      });
+    } finally {
+      await fs.rm(dir, { recursive: true, force: true });
+    }
+
+    return { url: `${this.publicHost}:${hostPort}`, hostPort };
+  }
+
+  async teardown(deployment: IPreviewDeployment): Promise<void> {
+    await runCommand('docker', ['compose', '-p', this.projectName(deployment.name), 'down', '--remove-orphans'], {
+      timeoutMs: 120000
+    });
+  }
+
+  private projectName(name: string): string {
+    return `preview-${name}`;
+  }
+
+  // Claims the first port in the range that no live preview holds. Allocation is serialized and the
+  // port is recorded before returning so concurrent deploys never pick the same one.
+  private allocatePort(deploymentId: string): Promise<number> {
+    const allocation = this.allocation.then(async () => {
+      const used = new Set<number>(await PreviewDeployment.distinct('hostPort', {
+        target: 'compose',
+        status: { $in: ['building', 'deploying', 'ready'] }
+      }));
+
+      for (let port = this.portRange[0]; port <= this.portRange[1]; port++) {
+        if (used.has(port)) continue;
+        await PreviewDeployment.updateOne({ _id: deploymentId }, { hostPort: port });
+        return port;
+      }
+
+      throw new Error(`No free preview ports left in ${this.portRange.join('-')}; stop an old preview first`);
+    });
+
+    this.allocation = allocation.catch(() => undefined);
+    return allocation;
+  }
+}
@@ -0,0 +1,106 @@
+import { IPreviewDeployment } from '../models/PreviewDeployment.js';
+import { runCommand } from '../services/CommandRunner.js';
+import { PreviewTarget, PreviewSpec, DeployedPreview } from './PreviewTarget.js';
+
+// Deploys each preview into its own namespace with a Deployment, Service and Ingress at
+// <name>.<PREVIEW_DOMAIN>; tearing down deletes the namespace. kubectl uses the service's
+// in-cluster or KUBECONFIG credentials.
+export class KubernetesTarget implements PreviewTarget {
+  readonly kind = 'kubernetes' as const;
+  readonly needsRegistry = true;
+
+  async deploy(spec: PreviewSpec, log: (line: string) => void): Promise<DeployedPreview> {
+    const domain = process.env.PREVIEW_DOMAIN;
+    if (!domain) {
+      throw new Error('Kubernetes previews require PREVIEW_DOMAIN');
+    }
+
+    const namespace = this.namespace(spec.name);
+    const host = `${spec.name}.${domain}`;
+
+    log(`Applying manifests to namespace ${namespace}`);
+    await runCommand('kubectl', ['apply', '-f', '-'], {
+      input: JSON.stringify({ apiVersion: 'v1', kind: 'List', items: this.manifests(spec, namespace, host) }),
+      timeoutMs: 60000,
+      onOutput: log
+    });
+
+    log('Waiting for the rollout');
+    await runCommand('kubectl', ['rollout', 'status', 'deployment/app', '-n', namespace, '--timeout=300s'], {
+      timeoutMs: 320000,
+      onOutput: log
+    });
+
+    return { url: `${process.env.PREVIEW_URL_SCHEME || 'https'}://${host}`, namespace };
+  }
+
+  async teardown(deployment: IPreviewDeployment): Promise<void> {
+    await runCommand('kubectl', ['delete', 'namespace', deployment.namespace || this.namespace(deployment.name), '--ignore-not-found', '--wait=false'], {
+      timeoutMs: 60000
+    });
+  }
+
+  private namespace(name: string): string {
+    return `preview-${name}`;
+  }
+
+  private manifests(spec: PreviewSpec, namespace: string, host: string): any[] {
+    const labels = { app: 'preview', 'ai-pipeline/preview': spec.deploymentId };
+    const ingressClass = process.env.PREVIEW_INGRESS_CLASS;
+    const tlsSecret = process.env.PREVIEW_TLS_SECRET;
+
+    return [
+      { apiVersion: 'v1', kind: 'Namespace', metadata: { name: namespace, labels } },
+      {
+        apiVersion: 'apps/v1',
+        kind: 'Deployment',
+        metadata: { name: 'app', namespace, labels },
+        spec: {
+          replicas: 1,
+          selector: { matchLabels: labels },
+          template: {
+            metadata: { labels },
+            spec: {
+              automountServiceAccountToken: false,
+              containers: [{
+                name: 'app',
+                image: spec.image,
+                ports: [{ containerPort: spec.port }],
+                env: [{ name: 'PORT', value: String(spec.port) }],
+                resources: {
+                  requests: { cpu: '100m', memory: '128Mi' },
+                  limits: { cpu: process.env.PREVIEW_CPUS || '500m', memory: process.env.PREVIEW_MEMORY || '512Mi' }
+                }
+              }]
+            }
+          }
+        }
+      },
+      {
+        apiVersion: 'v1',
+        kind: 'Service',
+        metadata: { name: 'app', namespace, labels },
+        spec: { selector: labels, ports: [{ port: 80, targetPort: spec.port }] }
+      },
+      {
+        apiVersion: 'networking.k8s.io/v1',
+        kind: 'Ingress',
+        metadata: { name: 'app', namespace, labels },
+        spec: {
+          ...(ingressClass ? { ingressClassName: ingressClass } : {}),
+          ...(tlsSecret ? { tls: [{ hosts: [host], secretName: tlsSecret }] } : {}),
+          rules: [{
+            host,
+            http: {
+              paths: [{
+                path: '/',
+                pathType: 'Prefix',
+                backend: { service: { name: 'app', port: { number: 80 } } }
+              }]
+            }
+          }]
+        }
+      }
+    ];
+  }
+}
@@ -0,0 +1,23 @@
+import { IPreviewDeployment } from '../models/PreviewDeployment.js';
+
+export interface PreviewSpec {
+  name: string;
+  image: string;
+  port: number;
+  deploymentId: string;
+}
+
+export interface DeployedPreview {
+  url: string;
+  hostPort?: number;
+  namespace?: string;
+}
+
+// Where preview environments run; the deployer builds the image and hands it to one of these
+export interface PreviewTarget {
+  readonly kind: 'compose' | 'kubernetes';
+  // Whether the image has to be pushed to PREVIEW_REGISTRY before deploying
+  readonly needsRegistry: boolean;
+  deploy(spec: PreviewSpec, log: (line: string) => void): Promise<DeployedPreview>;
+  teardown(deployment: IPreviewDeployment): Promise<void>;
+}
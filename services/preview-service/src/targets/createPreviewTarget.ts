@@ -0,0 +1,18 @@
+import { PreviewTargetKind } from '../models/PreviewDeployment.js';
+import { PreviewTarget } from './PreviewTarget.js';
+import { ComposeTarget } from './ComposeTarget.js';
+import { KubernetesTarget } from './KubernetesTarget.js';
+
+const targets = new Map<PreviewTargetKind, PreviewTarget>();
+
+export const DEFAULT_TARGET = (process.env.PREVIEW_TARGET === 'kubernetes' ? 'kubernetes' : 'compose') as PreviewTargetKind;
+
+// Targets are shared so the compose target's port allocation is serialized across requests
+export function createPreviewTarget(kind: PreviewTargetKind = DEFAULT_TARGET): PreviewTarget {
+  let target = targets.get(kind);
+  if (!target) {
+    target = kind === 'kubernetes' ? new KubernetesTarget() : new ComposeTarget();
+    targets.set(kind, target);
+  }
+  return target;
+}
@@ -6,6 +6,9 @@ export interface IProject extends Document {
   projectType: 'frontend' | 'backend' | 'fullstack';
   files: { [filename: string]: string };
   ownerId: string;
+  // Organization (auth-service) the project belongs to; runs of the project are billed and
+  // attributed to it
+  organizationId?: string;
   techStack: {
     frontend?: string[];
     backend?: string[];
@@ -25,6 +28,25 @@ export interface IProject extends Document {
     role: 'owner' | 'editor' | 'viewer';
     addedAt: Date;
   }>;
+  requirements?: Array<{
+    _id?: any;
+    title: string;
+    content: string;
+    format: 'markdown' | 'text';
+    createdBy: string;
+    updatedBy?: string;
+    createdAt?: Date;
+    updatedAt?: Date;
+  }>;
+  artifacts?: Array<{
+    artifactId: string;
+    type: 'code-bundle' | 'design-doc' | 'diagram' | 'other';
+    name: string;
+    url?: string;
+    runId?: string;
+    stageId?: string;
+    createdAt: Date;
+  }>;
   createdAt: Date;
   updatedAt: Date;
 }
@@ -58,6 +80,10 @@ const ProjectSchema: Schema = new Schema({
     required: true,
     index: true
   },
+  organizationId: {
+    type: String,
+    index: true
+  },
   techStack: {
     frontend: [String],
     backend: [String],
@@ -98,6 +124,26 @@ const ProjectSchema: Schema = new Schema({
       required: true 
     },
     addedAt: { type: Date, default: Date.now }
+  }],
+  requirements: [new Schema({
+    title: { type: String, required: true, trim: true, maxlength: 255 },
+    content: { type: String, required: true, maxlength: 100000 },
+    format: { type: String, enum: ['markdown', 'text'], default: 'markdown' },
+    createdBy: { type: String, required: true },
+    updatedBy: { type: String }
+  }, { timestamps: true })],
+  artifacts: [{
+    artifactId: { type: String, required: true },
+    type: {
+      type: String,
+      enum: ['code-bundle', 'design-doc', 'diagram', 'other'],
+      required: true
+    },
+    name: { type: String, required: true, trim: true },
+    url: { type: String },
+    runId: { type: String },
+    stageId: { type: String },
+    createdAt: { type: Date, default: Date.now }
   }]
 }, {
   timestamps: true,
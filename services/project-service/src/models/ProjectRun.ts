@@ -0,0 +1,69 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export interface IProjectRun extends Document {
+  projectId: string;
+  runId: string;
+  pipelineId?: string;
+  status: 'queued' | 'running' | 'completed' | 'failed' | 'cancelled';
+  triggeredBy: string;
+  startedAt: Date;
+  completedAt?: Date;
+  summary?: string;
+  stages?: Array<{
+    stageId: string;
+    status: string;
+    startedAt?: Date;
+    completedAt?: Date;
+  }>;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const ProjectRunSchema: Schema = new Schema({
+  projectId: {
+    type: String,
+    required: true,
+    index: true
+  },
+  runId: {
+    type: String,
+    required: true,
+    unique: true
+  },
+  pipelineId: {
+    type: String
+  },
+  status: {
+    type: String,
+    enum: ['queued', 'running', 'completed', 'failed', 'cancelled'],
+    default: 'queued',
+    index: true
+  },
+  triggeredBy: {
+    type: String,
+    required: true
+  },
+  startedAt: {
+    type: Date,
+    default: Date.now
+  },
+  completedAt: {
+    type: Date
+  },
+  summary: {
+    type: String,
+    maxlength: 5000
+  },
+  stages: [{
+    stageId: { type: String, required: true },
+    status: { type: String, required: true },
+    startedAt: Date,
+    completedAt: Date
+  }]
+}, {
+  timestamps: true
+});
+
+ProjectRunSchema.index({ projectId: 1, startedAt: -1 });
+
+export const ProjectRun = mongoose.model<IProjectRun>('ProjectRun', ProjectRunSchema);
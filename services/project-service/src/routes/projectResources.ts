@@ -0,0 +1,475 @@
+import express, { Request, Response } from 'express';
+import { body, param, query, validationResult } from 'express-validator';
+import { Project, IProject } from '../models/Project.js';
+import { ProjectRun } from '../models/ProjectRun.js';
+import { requireAuth, AuthenticatedRequest } from '../middleware/auth.js';
+
+const router = express.Router();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+// Loads the project and checks the caller's role, sending the error response itself on failure
+const loadProject = async (
+  req: AuthenticatedRequest,
+  res: Response,
+  requiredRole: 'viewer' | 'editor' | 'owner'
+): Promise<IProject | null> => {
+  const project = await Project.findById(req.params.id);
+
+  if (!project) {
+    res.status(404).json({
+      success: false,
+      error: 'Project not found'
+    });
+    return null;
+  }
+
+  if (!(project as any).hasAccess(req.user!._id, requiredRole)) {
+    res.status(403).json({
+      success: false,
+      error: 'Access denied'
+    });
+    return null;
+  }
+
+  return project;
+};
+
+const projectIdParam = param('id').isMongoId().withMessage('Invalid project ID');
+
+// GET /api/projects/:id/members - List owner and collaborators
+router.get('/:id/members',
+  requireAuth,
+  [projectIdParam],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const project = await loadProject(req, res, 'viewer');
+      if (!project) return;
+
+      res.json({
+        success: true,
+        data: [
+          { userId: project.ownerId, role: 'owner', addedAt: project.createdAt },
+          ...(project.collaborators || [])
+        ]
+      });
+    } catch (error) {
+      console.error('Error fetching project members:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch project members'
+      });
+    }
+  }
+);
+
+// GET /api/projects/:id/requirements - List requirements documents
+router.get('/:id/requirements',
+  requireAuth,
+  [projectIdParam],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const project = await loadProject(req, res, 'viewer');
+      if (!project) return;
+
+      res.json({
+        success: true,
+        data: project.requirements || []
+      });
+    } catch (error) {
+      console.error('Error fetching requirements:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch requirements'
+      });
+    }
+  }
+);
+
+// POST /api/projects/:id/requirements - Add a requirements document
+router.post('/:id/requirements',
+  requireAuth,
+  [
+    projectIdParam,
+    body('title').trim().isLength({ min: 1, max: 255 }).withMessage('Title is required and must be less than 255 characters'),
+    body('content').isString().isLength({ min: 1, max: 100000 }).withMessage('Content is required'),
+    body('format').optional().isIn(['markdown', 'text']).withMessage('Format must be markdown or text')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const project = await loadProject(req, res, 'editor');
+      if (!project) return;
+
+      project.requirements = project.requirements || [];
+      project.requirements.push({
+        title: req.body.title,
+        content: req.body.content,
+        format: req.body.format || 'markdown',
+        createdBy: req.user!._id
+      });
+      await project.save();
+
+      res.status(201).json({
+        success: true,
+        data: project.requirements[project.requirements.length - 1],
+        message: 'Requirements document added successfully'
+      });
+    } catch (error) {
+      console.error('Error adding requirements document:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to add requirements document'
+      });
+    }
+  }
+);
+
+// PUT /api/projects/:id/requirements/:docId - Update a requirements document
+router.put('/:id/requirements/:docId',
+  requireAuth,
+  [
+    projectIdParam,
+    param('docId').isMongoId().withMessage('Invalid document ID'),
+    body('title').optional().trim().isLength({ min: 1, max: 255 }).withMessage('Title must be less than 255 characters'),
+    body('content').optional().isString().isLength({ min: 1, max: 100000 }).withMessage('Content cannot be empty'),
+    body('format').optional().isIn(['markdown', 'text']).withMessage('Format must be markdown or text')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const project = await loadProject(req, res, 'editor');
+      if (!project) return;
+
+      const doc = (project.requirements as any).id(req.params.docId);
+      if (!doc) {
+        return res.status(404).json({
+          success: false,
+          error: 'Requirements document not found'
+        });
+      }
+
+      const { title, content, format } = req.body;
+      if (title !== undefined) doc.title = title;
+      if (content !== undefined) doc.content = content;
+      if (format !== undefined) doc.format = format;
+      doc.updatedBy = req.user!._id;
+      await project.save();
+
+      res.json({
+        success: true,
+        data: doc,
+        message: 'Requirements document updated successfully'
+      });
+    } catch (error) {
+      console.error('Error updating requirements document:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to update requirements document'
+      });
+    }
+  }
+);
+
+// DELETE /api/projects/:id/requirements/:docId - Remove a requirements document
+router.delete('/:id/requirements/:docId',
+  requireAuth,
+  [
+    projectIdParam,
+    param('docId').isMongoId().withMessage('Invalid document ID')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const project = await loadProject(req, res, 'editor');
+      if (!project) return;
+
+      const doc = (project.requirements as any).id(req.params.docId);
+      if (!doc) {
+        return res.status(404).json({
+          success: false,
+          error: 'Requirements document not found'
+        });
+      }
+
+      doc.deleteOne();
+      await project.save();
+
+      res.json({
+        success: true,
+        message: 'Requirements document removed successfully'
+      });
+    } catch (error) {
+      console.error('Error removing requirements document:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to remove requirements document'
+      });
+    }
+  }
+);
+
+// GET /api/projects/:id/artifacts - List generated artifacts linked to the project
+router.get('/:id/artifacts',
+  requireAuth,
+  [
+    projectIdParam,
+    query('runId').optional().isString().withMessage('Run ID must be a string')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const project = await loadProject(req, res, 'viewer');
+      if (!project) return;
+
+      const artifacts = (project.artifacts || [])
+        .filter(artifact => !req.query.runId || artifact.runId === req.query.runId);
+
+      res.json({
+        success: true,
+        data: artifacts
+      });
+    } catch (error) {
+      console.error('Error fetching artifacts:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch artifacts'
+      });
+    }
+  }
+);
+
+// POST /api/projects/:id/artifacts - Link a generated artifact to the project
+router.post('/:id/artifacts',
+  requireAuth,
+  [
+    projectIdParam,
+    body('artifactId').isString().isLength({ min: 1 }).withMessage('Artifact ID is required'),
+    body('type').isIn(['code-bundle', 'design-doc', 'diagram', 'other']).withMessage('Invalid artifact type'),
+    body('name').trim().isLength({ min: 1 }).withMessage('Artifact name is required'),
+    body('url').optional().isURL({ require_tld: false }).withMessage('URL must be valid'),
+    body('runId').optional().isString().withMessage('Run ID must be a string'),
+    body('stageId').optional().isString().withMessage('Stage ID must be a string')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const project = await loadProject(req, res, 'editor');
+      if (!project) return;
+
+      const { artifactId, type, name, url, runId, stageId } = req.body;
+      project.artifacts = (project.artifacts || []).filter(artifact => artifact.artifactId !== artifactId);
+      project.artifacts.push({ artifactId, type, name, url, runId, stageId, createdAt: new Date() });
+      await project.save();
+
+      res.status(201).json({
+        success: true,
+        data: project.artifacts[project.artifacts.length - 1],
+        message: 'Artifact linked successfully'
+      });
+    } catch (error) {
+      console.error('Error linking artifact:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to link artifact'
+      });
+    }
+  }
+);
+
+// DELETE /api/projects/:id/artifacts/:artifactId - Unlink an artifact
+router.delete('/:id/artifacts/:artifactId',
+  requireAuth,
+  [
+    projectIdParam,
+    param('artifactId').isString().withMessage('Invalid artifact ID')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const project = await loadProject(req, res, 'editor');
+      if (!project) return;
+
+      const before = project.artifacts?.length || 0;
+      project.artifacts = (project.artifacts || []).filter(artifact => artifact.artifactId !== req.params.artifactId);
+      if (project.artifacts.length === before) {
+        return res.status(404).json({
+          success: false,
+          error: 'Artifact not found'
+        });
+      }
+      await project.save();
+
+      res.json({
+        success: true,
+        message: 'Artifact unlinked successfully'
+      });
+    } catch (error) {
+      console.error('Error unlinking artifact:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to unlink artifact'
+      });
+    }
+  }
+);
+
+// GET /api/projects/:id/runs - Pipeline run history
+router.get('/:id/runs',
+  requireAuth,
+  [
+    projectIdParam,
+    query('page').optional().isInt({ min: 1 }).withMessage('Page must be a positive integer'),
+    query('limit').optional().isInt({ min: 1, max: 100 }).withMessage('Limit must be between 1 and 100'),
+    query('status').optional().isIn(['queued', 'running', 'completed', 'failed', 'cancelled']).withMessage('Invalid status')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const project = await loadProject(req, res, 'viewer');
+      if (!project) return;
+
+      const page = parseInt(req.query.page as string) || 1;
+      const limit = parseInt(req.query.limit as string) || 20;
+      const filter: any = { projectId: req.params.id };
+      if (req.query.status) filter.status = req.query.status;
+
+      const [runs, total] = await Promise.all([
+        ProjectRun.find(filter)
+          .sort({ startedAt: -1 })
+          .skip((page - 1) * limit)
+          .limit(limit),
+        ProjectRun.countDocuments(filter)
+      ]);
+
+      res.json({
+        success: true,
+        data: runs,
+        pagination: {
+          page,
+          limit,
+          total,
+          pages: Math.ceil(total / limit)
+        }
+      });
+    } catch (error) {
+      console.error('Error fetching run history:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch run history'
+      });
+    }
+  }
+);
+
+// POST /api/projects/:id/runs - Record a pipeline run
+router.post('/:id/runs',
+  requireAuth,
+  [
+    projectIdParam,
+    body('runId').isString().isLength({ min: 1 }).withMessage('Run ID is required'),
+    body('pipelineId').optional().isString().withMessage('Pipeline ID must be a string'),
+    body('status').optional().isIn(['queued', 'running', 'completed', 'failed', 'cancelled']).withMessage('Invalid status')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const project = await loadProject(req, res, 'editor');
+      if (!project) return;
+
+      const existing = await ProjectRun.findOne({ runId: req.body.runId });
+      if (existing) {
+        return res.status(409).json({
+          success: false,
+          error: 'Run already recorded'
+        });
+      }
+
+      const run = new ProjectRun({
+        projectId: req.params.id,
+        runId: req.body.runId,
+        pipelineId: req.body.pipelineId,
+        status: req.body.status || 'queued',
+        triggeredBy: req.user!._id
+      });
+      await run.save();
+
+      res.status(201).json({
+        success: true,
+        data: run,
+        message: 'Run recorded successfully'
+      });
+    } catch (error) {
+      console.error('Error recording run:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to record run'
+      });
+    }
+  }
+);
+
+// PATCH /api/projects/:id/runs/:runId - Update run status and stage progress
+router.patch('/:id/runs/:runId',
+  requireAuth,
+  [
+    projectIdParam,
+    param('runId').isString().withMessage('Invalid run ID'),
+    body('status').optional().isIn(['queued', 'running', 'completed', 'failed', 'cancelled']).withMessage('Invalid status'),
+    body('summary').optional().isString().isLength({ max: 5000 }).withMessage('Summary must be less than 5000 characters'),
+    body('stages').optional().isArray().withMessage('Stages must be an array')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const project = await loadProject(req, res, 'editor');
+      if (!project) return;
+
+      const run = await ProjectRun.findOne({ projectId: req.params.id, runId: req.params.runId });
+      if (!run) {
+        return res.status(404).json({
+          success: false,
+          error: 'Run not found'
+        });
+      }
+
+      const { status, summary, stages } = req.body;
+      if (status !== undefined) {
+        run.status = status;
+        if (['completed', 'failed', 'cancelled'].includes(status) && !run.completedAt) {
+          run.completedAt = new Date();
+        }
+      }
+      if (summary !== undefined) run.summary = summary;
+      if (stages !== undefined) run.stages = stages;
+      await run.save();
+
+      res.json({
+        success: true,
+        data: run,
+        message: 'Run updated successfully'
+      });
+    } catch (error) {
+      console.error('Error updating run:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to update run'
+      });
+    }
+  }
+);
+
+export default router;
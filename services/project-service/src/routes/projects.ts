@@ -1,6 +1,8 @@
 import express, { Request, Response } from 'express';
+import axios from 'axios';
 import { body, param, query, validationResult } from 'express-validator';
 import { Project, IProject } from '../models/Project.js';
+import { ProjectRun } from '../models/ProjectRun.js';
 import { requireAuth, AuthenticatedRequest } from '../middleware/auth.js';
 
 const router = express.Router();
@@ -18,6 +20,17 @@ const validateRequest = (req: Request, res: Response, next: express.NextFunction
   next();
 };
 
+// Whether the caller belongs to the organization, per the principal auth-service resolves
+const isOrganizationMember = async (req: AuthenticatedRequest, organizationId: string): Promise<boolean> => {
+  const authServiceUrl = process.env.AUTH_SERVICE_URL || 'http://localhost:3001';
+  const response = await axios.get(`${authServiceUrl}/api/auth/principal`, {
+    headers: { Authorization: req.headers.authorization },
+    timeout: 5000
+  });
+  const organizations: Array<{ id: string }> = response.data.data.organizations || [];
+  return organizations.some(organization => organization.id === organizationId);
+};
+
 // POST /api/projects - Create a new project
 router.post('/',
   requireAuth,
@@ -28,17 +41,26 @@ router.post('/',
     body('files').optional().isObject().withMessage('Files must be an object'),
     body('techStack').optional().isObject().withMessage('Tech stack must be an object'),
     body('repositoryUrl').optional().isURL().withMessage('Repository URL must be a valid URL'),
-    body('githubRepo').optional().isObject().withMessage('GitHub repo must be an object')
+    body('githubRepo').optional().isObject().withMessage('GitHub repo must be an object'),
+    body('organizationId').optional().isMongoId().withMessage('Invalid organization ID')
   ],
   validateRequest,
   async (req: AuthenticatedRequest, res: Response) => {
     try {
       const user = req.user!;
+      if (req.body.organizationId && !await isOrganizationMember(req, req.body.organizationId)) {
+        return res.status(403).json({
+          success: false,
+          error: 'Not a member of this organization'
+        });
+      }
+
       const projectData = {
         name: req.body.name,
         description: req.body.description,
         projectType: req.body.projectType,
         ownerId: user._id,
+        organizationId: req.body.organizationId,
         files: req.body.files || {},
         techStack: req.body.techStack || {},
         repositoryUrl: req.body.repositoryUrl,
@@ -99,7 +121,7 @@ router.get('/',
           .sort(req.query.search ? { score: { $meta: 'textScore' } } : { updatedAt: -1 })
           .skip(skip)
           .limit(limit)
-          .select('-files'), // Exclude large files field from list view
+          .select('-files -requirements'), // Exclude large fields from list view
         Project.countDocuments(filter)
       ]);
 
@@ -250,6 +272,7 @@ router.delete('/:id',
       }
 
       await Project.findByIdAndDelete(req.params.id);
+      await ProjectRun.deleteMany({ projectId: req.params.id });
 
       res.json({
         success: true,
@@ -4,6 +4,7 @@ import mongoose from 'mongoose';
 import dotenv from 'dotenv';
 import winston from 'winston';
 import projectRoutes from './routes/projects.js';
+import projectResourceRoutes from './routes/projectResources.js';
 
 // Load environment variables
 dotenv.config();
@@ -56,6 +57,7 @@ app.use((req, res, next) => {
 
 // Routes
 app.use('/api/projects', projectRoutes);
+app.use('/api/projects', projectResourceRoutes);
 
 // Health check endpoint
 app.get('/health', (req, res) => {
@@ -81,7 +83,18 @@ app.get('/info', (req, res) => {
       { method: 'PUT', path: '/api/projects/:id', description: 'Update project' },
       { method: 'DELETE', path: '/api/projects/:id', description: 'Delete project' },
       { method: 'POST', path: '/api/projects/:id/collaborators', description: 'Add collaborator' },
-      { method: 'DELETE', path: '/api/projects/:id/collaborators/:userId', description: 'Remove collaborator' }
+      { method: 'DELETE', path: '/api/projects/:id/collaborators/:userId', description: 'Remove collaborator' },
+      { method: 'GET', path: '/api/projects/:id/members', description: 'List project members' },
+      { method: 'GET', path: '/api/projects/:id/requirements', description: 'List requirements documents' },
+      { method: 'POST', path: '/api/projects/:id/requirements', description: 'Add requirements document' },
+      { method: 'PUT', path: '/api/projects/:id/requirements/:docId', description: 'Update requirements document' },
+      { method: 'DELETE', path: '/api/projects/:id/requirements/:docId', description: 'Remove requirements document' },
+      { method: 'GET', path: '/api/projects/:id/artifacts', description: 'List linked artifacts' },
+      { method: 'POST', path: '/api/projects/:id/artifacts', description: 'Link generated artifact' },
+      { method: 'DELETE', path: '/api/projects/:id/artifacts/:artifactId', description: 'Unlink artifact' },
+      { method: 'GET', path: '/api/projects/:id/runs', description: 'Pipeline run history' },
+      { method: 'POST', path: '/api/projects/:id/runs', description: 'Record pipeline run' },
+      { method: 'PATCH', path: '/api/projects/:id/runs/:runId', description: 'Update pipeline run' }
     ],
     features: [
       'Project CRUD operations',
@@ -89,6 +102,9 @@ app.get('/info', (req, res) => {
       'Role-based access control (Owner, Editor, Viewer)',
       'GitHub repository integration',
       'File management',
+      'Requirements documents',
+      'Generated artifact links',
+      'Pipeline run history',
       'Search and filtering',
       'Pagination support'
     ]
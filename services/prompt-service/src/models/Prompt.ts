@@ -0,0 +1,75 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+// Publishing a version first replays an eval dataset through it and the published version
+export interface IEvalGate {
+  dataset: string;
+  // A dataset version number, a label such as baseline, or latest
+  datasetVersion: string;
+  // block keeps a regressed version a draft; flag publishes it marked as regressed
+  mode: 'block' | 'flag';
+  // Largest drop in the mean case score (0-1) that still passes
+  maxScoreDrop: number;
+  // Cases that may go from passing to failing
+  maxRegressions: number;
+  // Model both versions run with; the role's own model when unset
+  model?: string;
+}
+
+export interface IPrompt extends Document {
+  name: string;
+  role: string;
+  description: string;
+  publishedVersion?: number;
+  latestVersion: number;
+  evalGate?: IEvalGate;
+  ownerId: string;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const PromptSchema: Schema = new Schema({
+  name: {
+    type: String,
+    required: true,
+    unique: true,
+    trim: true,
+    maxlength: 100,
+    match: /^[a-z0-9][a-z0-9._-]*$/
+  },
+  role: {
+    type: String,
+    required: true,
+    trim: true,
+    index: true
+  },
+  description: {
+    type: String,
+    default: '',
+    maxlength: 2000
+  },
+  publishedVersion: {
+    type: Number
+  },
+  latestVersion: {
+    type: Number,
+    default: 0
+  },
+  evalGate: {
+    type: new Schema({
+      dataset: { type: String, required: true },
+      datasetVersion: { type: String, default: 'latest' },
+      mode: { type: String, enum: ['block', 'flag'], default: 'block' },
+      maxScoreDrop: { type: Number, default: 0.05, min: 0, max: 1 },
+      maxRegressions: { type: Number, default: 0, min: 0 },
+      model: String
+    }, { _id: false })
+  },
+  ownerId: {
+    type: String,
+    required: true
+  }
+}, {
+  timestamps: true
+});
+
+export const Prompt = mongoose.model<IPrompt>('Prompt', PromptSchema);
@@ -0,0 +1,125 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export interface IPromptVariable {
+  name: string;
+  description?: string;
+  required: boolean;
+  defaultValue?: string;
+}
+
+export interface IPromptEvaluation {
+  status: 'running' | 'passed' | 'regressed' | 'failed';
+  mode: 'block' | 'flag';
+  dataset: string;
+  baselineVersion: number;
+  // The gate's thresholds when publishing was requested
+  maxScoreDrop: number;
+  maxRegressions: number;
+  runId?: string;
+  baselineRunId?: string;
+  score?: number;
+  baselineScore?: number;
+  scoreDelta?: number;
+  // Cases that passed on the baseline and fail on this version
+  regressions: string[];
+  // Published despite a regression or a failed evaluation, under a flag gate
+  flagged: boolean;
+  // A newer version was published while this one was evaluating, so this one was not published
+  supersededBy?: number;
+  error?: string;
+  // Who asked to publish; recorded as the publisher once the gate passes
+  requestedBy: string;
+  startedAt: Date;
+  completedAt?: Date;
+}
+
+export interface IPromptVersion extends Document {
+  promptName: string;
+  version: number;
+  systemPrompt?: string;
+  template: string;
+  variables: IPromptVariable[];
+  changelog: string;
+  // evaluating: publication is waiting on the prompt's eval gate
+  status: 'draft' | 'evaluating' | 'published' | 'archived';
+  createdBy: string;
+  publishedBy?: string;
+  publishedAt?: Date;
+  evaluation?: IPromptEvaluation;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const PromptVersionSchema: Schema = new Schema({
+  promptName: {
+    type: String,
+    required: true,
+    index: true
+  },
+  version: {
+    type: Number,
+    required: true
+  },
+  systemPrompt: {
+    type: String
+  },
+  template: {
+    type: String,
+    required: true
+  },
+  variables: [{
+    name: { type: String, required: true },
+    description: String,
+    required: { type: Boolean, default: true },
+    defaultValue: String
+  }],
+  changelog: {
+    type: String,
+    default: '',
+    maxlength: 5000
+  },
+  status: {
+    type: String,
+    enum: ['draft', 'evaluating', 'published', 'archived'],
+    default: 'draft',
+    index: true
+  },
+  createdBy: {
+    type: String,
+    required: true
+  },
+  publishedBy: {
+    type: String
+  },
+  publishedAt: {
+    type: Date
+  },
+  evaluation: {
+    type: new Schema({
+      status: { type: String, enum: ['running', 'passed', 'regressed', 'failed'], required: true },
+      mode: { type: String, enum: ['block', 'flag'], required: true },
+      dataset: { type: String, required: true },
+      baselineVersion: { type: Number, required: true },
+      maxScoreDrop: { type: Number, required: true },
+      maxRegressions: { type: Number, required: true },
+      runId: String,
+      baselineRunId: String,
+      score: Number,
+      baselineScore: Number,
+      scoreDelta: Number,
+      regressions: [String],
+      flagged: { type: Boolean, default: false },
+      supersededBy: Number,
+      error: String,
+      requestedBy: { type: String, required: true },
+      startedAt: { type: Date, required: true },
+      completedAt: Date
+    }, { _id: false })
+  }
+}, {
+  timestamps: true
+});
+
+PromptVersionSchema.index({ promptName: 1, version: -1 }, { unique: true });
+
+export const PromptVersion = mongoose.model<IPromptVersion>('PromptVersion', PromptVersionSchema);
@@ -0,0 +1,597 @@
+import express, { Request, Response } from 'express';
+import { body, param, query, validationResult } from 'express-validator';
+import { Prompt } from '../models/Prompt.js';
+import { PromptVersion } from '../models/PromptVersion.js';
+import { requireAuth, AuthenticatedRequest } from '../middleware/auth.js';
+import { renderPrompt, extractVariables, MissingVariablesError } from '../services/PromptRenderer.js';
+import { EvalGate, EvalGateError, publishVersion } from '../services/EvalGate.js';
+import { EvalClient } from '../services/EvalClient.js';
+
+const router = express.Router();
+const evalGate = new EvalGate(new EvalClient());
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const nameParam = param('name').matches(/^[a-z0-9][a-z0-9._-]*$/).withMessage('Invalid prompt name');
+const versionParam = param('version').isInt({ min: 1 }).withMessage('Version must be a positive integer');
+
+// POST /api/prompts - Create a new prompt
+router.post('/',
+  requireAuth,
+  [
+    body('name').matches(/^[a-z0-9][a-z0-9._-]*$/).isLength({ max: 100 }).withMessage('Name must be lowercase letters, digits, dots, dashes or underscores'),
+    body('role').trim().isLength({ min: 1 }).withMessage('Agent role is required'),
+    body('description').optional().isString().isLength({ max: 2000 }).withMessage('Description must be less than 2000 characters')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const existing = await Prompt.findOne({ name: req.body.name });
+      if (existing) {
+        return res.status(409).json({
+          success: false,
+          error: 'Prompt already exists'
+        });
+      }
+
+      const prompt = new Prompt({
+        name: req.body.name,
+        role: req.body.role,
+        description: req.body.description || '',
+        ownerId: req.user!._id
+      });
+      await prompt.save();
+
+      res.status(201).json({
+        success: true,
+        data: prompt,
+        message: 'Prompt created successfully'
+      });
+    } catch (error) {
+      console.error('Error creating prompt:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to create prompt'
+      });
+    }
+  }
+);
+
+// GET /api/prompts - List prompts, optionally filtered by agent role
+router.get('/',
+  [
+    query('role').optional().isString().withMessage('Role must be a string')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const filter: any = {};
+      if (req.query.role) filter.role = req.query.role;
+
+      const prompts = await Prompt.find(filter).sort({ name: 1 });
+
+      res.json({
+        success: true,
+        data: prompts
+      });
+    } catch (error) {
+      console.error('Error fetching prompts:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch prompts'
+      });
+    }
+  }
+);
+
+// GET /api/prompts/:name - Get a prompt with its published version
+router.get('/:name',
+  [nameParam],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const prompt = await Prompt.findOne({ name: req.params.name });
+      if (!prompt) {
+        return res.status(404).json({
+          success: false,
+          error: 'Prompt not found'
+        });
+      }
+
+      const published = prompt.publishedVersion
+        ? await PromptVersion.findOne({ promptName: prompt.name, version: prompt.publishedVersion })
+        : null;
+
+      res.json({
+        success: true,
+        data: {
+          ...prompt.toJSON(),
+          published
+        }
+      });
+    } catch (error) {
+      console.error('Error fetching prompt:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch prompt'
+      });
+    }
+  }
+);
+
+// POST /api/prompts/:name/versions - Create a new draft version
+router.post('/:name/versions',
+  requireAuth,
+  [
+    nameParam,
+    body('template').isString().isLength({ min: 1 }).withMessage('Template is required'),
+    body('systemPrompt').optional().isString().withMessage('System prompt must be a string'),
+    body('variables').optional().isArray().withMessage('Variables must be an array'),
+    body('variables.*.name').optional().isString().withMessage('Each variable must have a name'),
+    body('changelog').isString().isLength({ min: 1, max: 5000 }).withMessage('Changelog entry is required')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const prompt = await Prompt.findOneAndUpdate(
+        { name: req.params.name },
+        { $inc: { latestVersion: 1 } },
+        { new: true }
+      );
+      if (!prompt) {
+        return res.status(404).json({
+          success: false,
+          error: 'Prompt not found'
+        });
+      }
+
+      // Declare any referenced variables the author did not describe explicitly
+      const declared = req.body.variables || [];
+      const referenced = extractVariables(`${req.body.systemPrompt || ''}\n${req.body.template}`);
+      const variables = [
+        ...declared,
+        ...referenced
+          .filter(name => !declared.some((variable: any) => variable.name === name))
+          .map(name => ({ name, required: true }))
+      ];
+
+      const version = new PromptVersion({
+        promptName: prompt.name,
+        version: prompt.latestVersion,
+        systemPrompt: req.body.systemPrompt,
+        template: req.body.template,
+        variables,
+        changelog: req.body.changelog,
+        createdBy: req.user!._id
+      });
+      await version.save();
+
+      res.status(201).json({
+        success: true,
+        data: version,
+        message: 'Draft version created successfully'
+      });
+    } catch (error) {
+      console.error('Error creating prompt version:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to create prompt version'
+      });
+    }
+  }
+);
+
+// GET /api/prompts/:name/versions - List versions (changelog history)
+router.get('/:name/versions',
+  [
+    nameParam,
+    query('status').optional().isIn(['draft', 'published', 'archived']).withMessage('Invalid status')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const filter: any = { promptName: req.params.name };
+      if (req.query.status) filter.status = req.query.status;
+
+      const versions = await PromptVersion.find(filter)
+        .sort({ version: -1 })
+        .select('-template -systemPrompt');
+
+      res.json({
+        success: true,
+        data: versions
+      });
+    } catch (error) {
+      console.error('Error fetching prompt versions:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch prompt versions'
+      });
+    }
+  }
+);
+
+// GET /api/prompts/:name/versions/:version - Get a specific version
+router.get('/:name/versions/:version',
+  [nameParam, versionParam],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const version = await PromptVersion.findOne({
+        promptName: req.params.name,
+        version: parseInt(req.params.version)
+      });
+
+      if (!version) {
+        return res.status(404).json({
+          success: false,
+          error: 'Prompt version not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: version
+      });
+    } catch (error) {
+      console.error('Error fetching prompt version:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch prompt version'
+      });
+    }
+  }
+);
+
+// PUT /api/prompts/:name/versions/:version - Edit a draft version
+router.put('/:name/versions/:version',
+  requireAuth,
+  [
+    nameParam,
+    versionParam,
+    body('template').optional().isString().isLength({ min: 1 }).withMessage('Template cannot be empty'),
+    body('systemPrompt').optional().isString().withMessage('System prompt must be a string'),
+    body('variables').optional().isArray().withMessage('Variables must be an array'),
+    body('changelog').optional().isString().isLength({ min: 1, max: 5000 }).withMessage('Changelog cannot be empty')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const version = await PromptVersion.findOne({
+        promptName: req.params.name,
+        version: parseInt(req.params.version)
+      });
+
+      if (!version) {
+        return res.status(404).json({
+          success: false,
+          error: 'Prompt version not found'
+        });
+      }
+
+      // Published history is immutable; changes go into a new draft
+      if (version.status !== 'draft') {
+        return res.status(409).json({
+          success: false,
+          error: 'Only draft versions can be edited'
+        });
+      }
+
+      const { template, systemPrompt, variables, changelog } = req.body;
+      if (template !== undefined) version.template = template;
+      if (systemPrompt !== undefined) version.systemPrompt = systemPrompt;
+      if (variables !== undefined) version.variables = variables;
+      if (changelog !== undefined) version.changelog = changelog;
+      await version.save();
+
+      res.json({
+        success: true,
+        data: version,
+        message: 'Draft version updated successfully'
+      });
+    } catch (error) {
+      console.error('Error updating prompt version:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to update prompt version'
+      });
+    }
+  }
+);
+
+// POST /api/prompts/:name/versions/:version/publish - Publish a draft version. With an eval gate
+// the version is evaluated first (202) and published by the gate once it passes; admins can
+// skipEvalGate to publish straight away.
+router.post('/:name/versions/:version/publish',
+  requireAuth,
+  [
+    nameParam,
+    versionParam,
+    body('skipEvalGate').optional().isBoolean().withMessage('skipEvalGate must be a boolean')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const prompt = await Prompt.findOne({ name: req.params.name });
+      const version = await PromptVersion.findOne({
+        promptName: req.params.name,
+        version: parseInt(req.params.version)
+      });
+
+      if (!prompt || !version) {
+        return res.status(404).json({
+          success: false,
+          error: 'Prompt version not found'
+        });
+      }
+
+      if (version.status !== 'draft') {
+        return res.status(409).json({
+          success: false,
+          error: `Version ${version.version} is already ${version.status}`
+        });
+      }
+
+      if (req.body.skipEvalGate) {
+        if (req.user!.role !== 'admin') {
+          return res.status(403).json({
+            success: false,
+            error: 'Only admins can skip the eval gate'
+          });
+        }
+        await publishVersion(version, req.user!._id);
+        return res.json({
+          success: true,
+          data: version,
+          message: `Version ${version.version} published without evaluation`
+        });
+      }
+
+      const outcome = await evalGate.publish(prompt, version, req.user!._id, req.headers.authorization);
+
+      res.status(outcome.evaluating ? 202 : 200).json({
+        success: true,
+        data: outcome.version,
+        message: outcome.message
+      });
+    } catch (error) {
+      if (error instanceof EvalGateError) {
+        return res.status(503).json({
+          success: false,
+          error: error.message
+        });
+      }
+
+      console.error('Error publishing prompt version:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to publish prompt version'
+      });
+    }
+  }
+);
+
+// Changing or removing a gate is admin-only, like skipping it: otherwise anyone could lift the
+// gate, publish and put it back
+const requireAdmin = (req: AuthenticatedRequest, res: Response, next: express.NextFunction) => {
+  if (req.user!.role !== 'admin') {
+    return res.status(403).json({
+      success: false,
+      error: 'Only admins can change the eval gate'
+    });
+  }
+  next();
+};
+
+// PUT /api/prompts/:name/eval-gate - Require new versions to hold up on an eval dataset before publishing
+router.put('/:name/eval-gate',
+  requireAuth,
+  requireAdmin,
+  [
+    nameParam,
+    body('dataset').matches(/^[a-z0-9][a-z0-9._-]*$/).withMessage('Dataset must be an eval dataset name'),
+    body('datasetVersion').optional().isString().isLength({ min: 1 }).withMessage('Dataset version must be a number, a label or latest'),
+    body('mode').optional().isIn(['block', 'flag']).withMessage('Mode must be block or flag'),
+    body('maxScoreDrop').optional().isFloat({ min: 0, max: 1 }).withMessage('maxScoreDrop must be between 0 and 1'),
+    body('maxRegressions').optional().isInt({ min: 0 }).withMessage('maxRegressions must be a non-negative integer'),
+    body('model').optional().isString().isLength({ min: 1 }).withMessage('Model must be a model ID or alias')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const prompt = await Prompt.findOne({ name: req.params.name });
+      if (!prompt) {
+        return res.status(404).json({
+          success: false,
+          error: 'Prompt not found'
+        });
+      }
+
+      prompt.evalGate = {
+        dataset: req.body.dataset,
+        datasetVersion: req.body.datasetVersion || 'latest',
+        mode: req.body.mode || 'block',
+        maxScoreDrop: req.body.maxScoreDrop ?? 0.05,
+        maxRegressions: req.body.maxRegressions ?? 0,
+        ...(req.body.model ? { model: req.body.model } : {})
+      };
+      await prompt.save();
+
+      res.json({
+        success: true,
+        data: prompt,
+        message: 'Eval gate updated successfully'
+      });
+    } catch (error) {
+      console.error('Error updating eval gate:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to update eval gate'
+      });
+    }
+  }
+);
+
+// DELETE /api/prompts/:name/eval-gate - Publish new versions without evaluation; evaluations
+// already running still settle
+router.delete('/:name/eval-gate',
+  requireAuth,
+  requireAdmin,
+  [nameParam],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const prompt = await Prompt.findOneAndUpdate(
+        { name: req.params.name },
+        { $unset: { evalGate: 1 } },
+        { new: true }
+      );
+      if (!prompt) {
+        return res.status(404).json({
+          success: false,
+          error: 'Prompt not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: prompt,
+        message: 'Eval gate removed'
+      });
+    } catch (error) {
+      console.error('Error removing eval gate:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to remove eval gate'
+      });
+    }
+  }
+);
+
+// POST /api/prompts/:name/rollback - Point the prompt back at an earlier published version
+router.post('/:name/rollback',
+  requireAuth,
+  [
+    nameParam,
+    body('version').isInt({ min: 1 }).withMessage('Version must be a positive integer')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const prompt = await Prompt.findOne({ name: req.params.name });
+      if (!prompt) {
+        return res.status(404).json({
+          success: false,
+          error: 'Prompt not found'
+        });
+      }
+
+      const target = await PromptVersion.findOne({
+        promptName: prompt.name,
+        version: req.body.version,
+        status: 'published'
+      });
+      if (!target) {
+        return res.status(404).json({
+          success: false,
+          error: 'Published version not found'
+        });
+      }
+
+      prompt.publishedVersion = target.version;
+      await prompt.save();
+
+      res.json({
+        success: true,
+        data: prompt,
+        message: `Rolled back to version ${target.version}`
+      });
+    } catch (error) {
+      console.error('Error rolling back prompt:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to roll back prompt'
+      });
+    }
+  }
+);
+
+// POST /api/prompts/:name/render - Render the published (or a given) version with variables
+router.post('/:name/render',
+  [
+    nameParam,
+    body('variables').optional().isObject().withMessage('Variables must be an object'),
+    body('version').optional().isInt({ min: 1 }).withMessage('Version must be a positive integer')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const prompt = await Prompt.findOne({ name: req.params.name });
+      if (!prompt) {
+        return res.status(404).json({
+          success: false,
+          error: 'Prompt not found'
+        });
+      }
+
+      const versionNumber = req.body.version || prompt.publishedVersion;
+      if (!versionNumber) {
+        return res.status(409).json({
+          success: false,
+          error: 'Prompt has no published version'
+        });
+      }
+
+      const version = await PromptVersion.findOne({ promptName: prompt.name, version: versionNumber });
+      if (!version) {
+        return res.status(404).json({
+          success: false,
+          error: 'Prompt version not found'
+        });
+      }
+
+      const rendered = renderPrompt(
+        version.template,
+        version.systemPrompt,
+        version.variables,
+        req.body.variables || {}
+      );
+
+      res.json({
+        success: true,
+        data: {
+          name: prompt.name,
+          version: version.version,
+          ...rendered
+        }
+      });
+    } catch (error) {
+      if (error instanceof MissingVariablesError) {
+        return res.status(400).json({
+          success: false,
+          error: error.message,
+          details: error.missing
+        });
+      }
+
+      console.error('Error rendering prompt:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to render prompt'
+      });
+    }
+  }
+);
+
+export default router;
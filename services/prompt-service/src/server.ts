@@ -0,0 +1,131 @@
+import express from 'express';
+import cors from 'cors';
+import mongoose from 'mongoose';
+import dotenv from 'dotenv';
+import winston from 'winston';
+import promptRoutes from './routes/prompts.js';
+import { EvalGate } from './services/EvalGate.js';
+import { EvalClient } from './services/EvalClient.js';
+
+// Load environment variables
+dotenv.config();
+
+const app = express();
+const PORT = process.env.PORT || 3007;
+
+// Logger configuration
+const logger = winston.createLogger({
+  level: 'info',
+  format: winston.format.combine(
+    winston.format.timestamp(),
+    winston.format.json()
+  ),
+  transports: [
+    new winston.transports.Console(),
+    new winston.transports.File({ filename: 'logs/prompt-service.log' })
+  ]
+});
+
+const evalGate = new EvalGate(new EvalClient());
+
+// Database connection
+const MONGODB_URI = process.env.MONGODB_URI || 'mongodb://localhost:27017/ai-pipeline';
+
+mongoose.connect(MONGODB_URI)
+  .then(() => {
+    logger.info('🗄️  Connected to MongoDB');
+    evalGate.start(parseInt(process.env.PROMPT_EVAL_POLL_MS || '30000'));
+  })
+  .catch((error) => {
+    logger.error('❌ MongoDB connection error:', error);
+    process.exit(1);
+  });
+
+// Middleware
+app.use(cors({
+  origin: process.env.FRONTEND_URL || 'http://localhost:5173',
+  credentials: true
+}));
+app.use(express.json({ limit: '20mb' }));
+app.use(express.urlencoded({ extended: true }));
+
+// Request logging middleware
+app.use((req, res, next) => {
+  logger.info(`${req.method} ${req.path}`, {
+    ip: req.ip,
+    userAgent: req.get('User-Agent'),
+    timestamp: new Date().toISOString()
+  });
+  next();
+});
+
+// Routes
+app.use('/api/prompts', promptRoutes);
+
+// Health check endpoint
+app.get('/health', (req, res) => {
+  res.json({
+    status: 'ok',
+    service: 'prompt-service',
+    timestamp: new Date().toISOString(),
+    version: '1.0.0',
+    database: mongoose.connection.readyState === 1 ? 'connected' : 'disconnected'
+  });
+});
+
+// Service info endpoint
+app.get('/info', (req, res) => {
+  res.json({
+    service: 'Prompt Service',
+    version: '1.0.0',
+    description: 'Versioned prompt templates for AI agent roles',
+    endpoints: [
+      { method: 'POST', path: '/api/prompts', description: 'Create prompt' },
+      { method: 'GET', path: '/api/prompts', description: 'List prompts' },
+      { method: 'GET', path: '/api/prompts/:name', description: 'Get prompt and published version' },
+      { method: 'POST', path: '/api/prompts/:name/versions', description: 'Create draft version' },
+      { method: 'GET', path: '/api/prompts/:name/versions', description: 'List versions and changelog' },
+      { method: 'GET', path: '/api/prompts/:name/versions/:version', description: 'Get version' },
+      { method: 'PUT', path: '/api/prompts/:name/versions/:version', description: 'Edit draft version' },
+      { method: 'POST', path: '/api/prompts/:name/versions/:version/publish', description: 'Publish draft version (after its eval gate)' },
+      { method: 'PUT', path: '/api/prompts/:name/eval-gate', description: 'Set the eval gate for new versions' },
+      { method: 'DELETE', path: '/api/prompts/:name/eval-gate', description: 'Remove the eval gate' },
+      { method: 'POST', path: '/api/prompts/:name/rollback', description: 'Roll back to a published version' },
+      { method: 'POST', path: '/api/prompts/:name/render', description: 'Render prompt with variables' }
+    ]
+  });
+});
+
+// Catch-all for undefined routes
+app.use('*', (req, res) => {
+  res.status(404).json({
+    success: false,
+    error: 'Route not found',
+    service: 'prompt-service'
+  });
+});
+
+// Global error handler
+app.use((err: any, req: any, res: any, next: any) => {
+  logger.error('Unhandled error:', err);
+  res.status(500).json({
+    success: false,
+    error: 'Internal server error',
+    service: 'prompt-service'
+  });
+});
+
+// Graceful shutdown
+process.on('SIGTERM', () => {
+  logger.info('SIGTERM received. Shutting down gracefully...');
+  evalGate.stop();
+  mongoose.connection.close();
+  process.exit(0);
+});
+
+// Start server
+app.listen(PORT, () => {
+  logger.info(`📝 Prompt Service running on port ${PORT}`);
+});
+
+export default app;
@@ -0,0 +1,65 @@
+import axios from 'axios';
+
+export interface EvalRunSummary {
+  _id: string;
+  status: 'queued' | 'running' | 'completed' | 'failed' | 'cancelled';
+  error?: string;
+}
+
+export interface EvalComparison {
+  score: number;
+  baselineScore: number;
+  scoreDelta: number;
+  regressions: string[];
+}
+
+export interface StartRunsRequest {
+  dataset: string;
+  datasetVersion: string;
+  role: string;
+  candidates: Array<{ promptVersion: number; model?: string }>;
+  label: string;
+}
+
+export class EvalClient {
+  private baseUrl: string | undefined;
+
+  constructor(baseUrl?: string) {
+    this.baseUrl = baseUrl || process.env.EVAL_SERVICE_URL;
+  }
+
+  isConfigured(): boolean {
+    return !!this.baseUrl;
+  }
+
+  // Runs are started on behalf of the user publishing, so their token is passed along
+  async startRuns(request: StartRunsRequest, authorization?: string): Promise<EvalRunSummary[]> {
+    return this.call(() => axios.post(`${this.baseUrl}/api/evals/runs`, request, {
+      headers: authorization ? { Authorization: authorization } : {},
+      timeout: 10000
+    }));
+  }
+
+  async getRun(id: string): Promise<EvalRunSummary> {
+    return this.call(() => axios.get(`${this.baseUrl}/api/evals/runs/${id}`, { timeout: 10000 }));
+  }
+
+  async compare(id: string, baselineId: string): Promise<EvalComparison> {
+    return this.call(() => axios.get(`${this.baseUrl}/api/evals/runs/${id}/compare`, {
+      params: { baseline: baselineId },
+      timeout: 10000
+    }));
+  }
+
+  private async call<T>(request: () => Promise<{ data: { data: T } }>): Promise<T> {
+    try {
+      const response = await request();
+      return response.data.data;
+    } catch (error) {
+      if (axios.isAxiosError(error) && error.response?.data?.error) {
+        throw new Error(`Eval service error: ${error.response.data.error}`);
+      }
+      throw new Error(`Eval service error: ${error instanceof Error ? error.message : 'Unknown error'}`);
+    }
+  }
+}
@@ -0,0 +1,192 @@
+import { IPrompt, Prompt } from '../models/Prompt.js';
+import { IPromptEvaluation, IPromptVersion, PromptVersion } from '../models/PromptVersion.js';
+import { EvalClient } from './EvalClient.js';
+
+export class EvalGateError extends Error {
+  constructor(message: string) {
+    super(message);
+    this.name = 'EvalGateError';
+  }
+}
+
+export interface PublishOutcome {
+  version: IPromptVersion;
+  // Publication waits on an eval run; the version is evaluating until the gate settles it
+  evaluating: boolean;
+  message: string;
+}
+
+export async function publishVersion(version: IPromptVersion, publishedBy: string): Promise<void> {
+  version.status = 'published';
+  version.publishedBy = publishedBy;
+  version.publishedAt = new Date();
+  await version.save();
+
+  await Prompt.updateOne({ name: version.promptName }, { $set: { publishedVersion: version.version } });
+}
+
+// Regression gate between a draft and publication. A prompt with an evalGate has each new version
+// replayed through its eval dataset alongside the published version; the version is published once
+// its score holds up, and otherwise kept a draft (block) or published marked as regressed (flag).
+// Evaluations are settled by a sweep that polls the eval service.
+export class EvalGate {
+  private timer: NodeJS.Timeout | null = null;
+  private timeoutMs = parseInt(process.env.PROMPT_EVAL_TIMEOUT_MS || '3600000');
+
+  constructor(private evals: EvalClient) {}
+
+  start(intervalMs: number): void {
+    this.timer = setInterval(() => {
+      this.sweep().catch(error => console.error('Eval gate sweep failed:', error));
+    }, intervalMs);
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = null;
+    }
+  }
+
+  // Publishes the version straight away when there is no gate or nothing published to compare
+  // against; otherwise starts the evaluation. A block gate refuses to publish unevaluated.
+  async publish(prompt: IPrompt, version: IPromptVersion, userId: string, authorization?: string): Promise<PublishOutcome> {
+    const gate = prompt.evalGate;
+    const baselineVersion = prompt.publishedVersion;
+    if (!gate || !baselineVersion) {
+      await publishVersion(version, userId);
+      return { version, evaluating: false, message: `Version ${version.version} published successfully` };
+    }
+
+    const evaluation: IPromptEvaluation = {
+      status: 'running',
+      mode: gate.mode,
+      dataset: gate.dataset,
+      baselineVersion,
+      maxScoreDrop: gate.maxScoreDrop,
+      maxRegressions: gate.maxRegressions,
+      regressions: [],
+      flagged: false,
+      requestedBy: userId,
+      startedAt: new Date()
+    };
+
+    try {
+      if (!this.evals.isConfigured()) {
+        throw new Error('EVAL_SERVICE_URL is not configured');
+      }
+      const [run, baseline] = await this.evals.startRuns({
+        dataset: gate.dataset,
+        datasetVersion: gate.datasetVersion,
+        role: prompt.role,
+        candidates: [
+          { promptVersion: version.version, ...(gate.model ? { model: gate.model } : {}) },
+          { promptVersion: baselineVersion, ...(gate.model ? { model: gate.model } : {}) }
+        ],
+        label: `Publish gate for ${prompt.name} v${version.version} against v${baselineVersion}`
+      }, authorization);
+      evaluation.runId = run._id;
+      evaluation.baselineRunId = baseline._id;
+    } catch (error) {
+      const message = error instanceof Error ? error.message : 'Eval service unavailable';
+      if (gate.mode === 'block') {
+        throw new EvalGateError(`Could not evaluate version ${version.version}: ${message}`);
+      }
+
+      version.evaluation = { ...evaluation, status: 'failed', flagged: true, error: message, completedAt: new Date() };
+      await publishVersion(version, userId);
+      return { version, evaluating: false, message: `Version ${version.version} published without evaluation: ${message}` };
+    }
+
+    version.status = 'evaluating';
+    version.evaluation = evaluation;
+    await version.save();
+
+    return {
+      version,
+      evaluating: true,
+      message: `Evaluating version ${version.version} against version ${baselineVersion} on ${gate.dataset} before publishing`
+    };
+  }
+
+  // Settles every evaluation whose runs have finished; returns how many were settled
+  async sweep(): Promise<number> {
+    const pending = await PromptVersion.find({ status: 'evaluating' }).limit(100);
+
+    let settled = 0;
+    for (const version of pending) {
+      try {
+        if (await this.settle(version)) settled++;
+      } catch (error) {
+        console.error(`Eval gate check for ${version.promptName} v${version.version} failed:`, error);
+      }
+    }
+    return settled;
+  }
+
+  private async settle(version: IPromptVersion): Promise<boolean> {
+    const evaluation = version.evaluation!;
+    const runs = await Promise.all([this.evals.getRun(evaluation.runId!), this.evals.getRun(evaluation.baselineRunId!)]);
+
+    const stopped = runs.find(run => run.status === 'failed' || run.status === 'cancelled');
+    if (stopped) {
+      await this.conclude(version, 'failed', { error: `Eval run ${stopped._id} ${stopped.status}${stopped.error ? `: ${stopped.error}` : ''}` });
+      return true;
+    }
+
+    if (runs.some(run => run.status !== 'completed')) {
+      if (Date.now() - evaluation.startedAt.getTime() <= this.timeoutMs) return false;
+      await this.conclude(version, 'failed', { error: `Evaluation did not finish within ${Math.round(this.timeoutMs / 60000)} minutes` });
+      return true;
+    }
+
+    const comparison = await this.evals.compare(evaluation.runId!, evaluation.baselineRunId!);
+    const regressed = comparison.scoreDelta < -evaluation.maxScoreDrop
+      || comparison.regressions.length > evaluation.maxRegressions;
+
+    await this.conclude(version, regressed ? 'regressed' : 'passed', {
+      score: comparison.score,
+      baselineScore: comparison.baselineScore,
+      scoreDelta: comparison.scoreDelta,
+      regressions: comparison.regressions
+    });
+    return true;
+  }
+
+  private async conclude(
+    version: IPromptVersion,
+    status: IPromptEvaluation['status'],
+    details: Partial<IPromptEvaluation>
+  ): Promise<void> {
+    const evaluation = version.evaluation!;
+    Object.assign(evaluation, details, { status, completedAt: new Date() });
+
+    if (status === 'passed' || evaluation.mode === 'flag') {
+      evaluation.flagged = status !== 'passed';
+
+      // Only move the prompt forward: a version published while this one was evaluating stays
+      // published, and this one keeps its result as a draft
+      const { matchedCount } = await Prompt.updateOne(
+        {
+          name: version.promptName,
+          $or: [{ publishedVersion: { $exists: false } }, { publishedVersion: null }, { publishedVersion: { $lt: version.version } }]
+        },
+        { $set: { publishedVersion: version.version } }
+      );
+      if (matchedCount > 0) {
+        version.status = 'published';
+        version.publishedBy = evaluation.requestedBy;
+        version.publishedAt = new Date();
+        await version.save();
+        return;
+      }
+
+      const prompt = await Prompt.findOne({ name: version.promptName });
+      evaluation.supersededBy = prompt?.publishedVersion;
+    }
+
+    // Blocked or superseded: the version goes back to being an editable draft with the evaluation on record
+    version.status = 'draft';
+    await version.save();
+  }
+}
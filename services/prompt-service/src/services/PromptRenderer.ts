@@ -0,0 +1,61 @@
+import { IPromptVariable } from '../models/PromptVersion.js';
+
+const PLACEHOLDER = /\{\{\s*([\w.]+)\s*\}\}/g;
+
+export interface RenderResult {
+  systemPrompt?: string;
+  prompt: string;
+}
+
+export class MissingVariablesError extends Error {
+  constructor(public missing: string[]) {
+    super(`Missing required variables: ${missing.join(', ')}`);
+    this.name = 'MissingVariablesError';
+  }
+}
+
+// Lists the root variable names referenced by a template, e.g. {{inputs.name}} -> inputs
+export function extractVariables(template: string): string[] {
+  const names = new Set<string>();
+  for (const match of template.matchAll(PLACEHOLDER)) {
+    names.add(match[1].split('.')[0]);
+  }
+  return Array.from(names);
+}
+
+export function renderPrompt(
+  template: string,
+  systemPrompt: string | undefined,
+  declared: IPromptVariable[],
+  values: Record<string, any>
+): RenderResult {
+  const resolved: Record<string, any> = {};
+  declared.forEach(variable => {
+    if (variable.defaultValue !== undefined) resolved[variable.name] = variable.defaultValue;
+  });
+  Object.assign(resolved, values);
+
+  const missing = declared
+    .filter(variable => variable.required && resolved[variable.name] === undefined)
+    .map(variable => variable.name);
+  if (missing.length > 0) {
+    throw new MissingVariablesError(missing);
+  }
+
+  return {
+    ...(systemPrompt ? { systemPrompt: substitute(systemPrompt, resolved) } : {}),
+    prompt: substitute(template, resolved)
+  };
+}
+
+function substitute(template: string, values: Record<string, any>): string {
+  return template.replace(PLACEHOLDER, (match, key: string) => {
+    const value = key.split('.').reduce<any>((current, part) => {
+      return current !== undefined && current !== null ? current[part] : undefined;
+    }, values);
+
+    if (value === undefined || value === null) return '';
+    if (typeof value === 'string') return value;
+    return JSON.stringify(value, null, 2);
+  });
+}
@@ -0,0 +1,86 @@
+import mongoose, { Schema, Document } from 'mongoose';
+import { CheckResult, RepairDecision } from '../services/QualityGate.js';
+
+export interface IQualityReport extends Document {
+  projectId?: string;
+  runId?: string;
+  stageId?: string;
+  // Which repair round of the stage this report belongs to, 0 for the first gate run
+  attempt: number;
+  status: 'passed' | 'failed' | 'error';
+  filesChecked: number;
+  checks: CheckResult[];
+  coverage: { percent?: number; threshold?: number };
+  repair: RepairDecision;
+  durationMs: number;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const IssueSchema = new Schema({
+  file: { type: String },
+  line: { type: Number },
+  column: { type: Number },
+  rule: { type: String },
+  message: { type: String, required: true }
+}, { _id: false });
+
+const CheckResultSchema = new Schema({
+  id: { type: String, required: true },
+  description: { type: String },
+  status: {
+    type: String,
+    enum: ['passed', 'failed', 'skipped', 'error'],
+    required: true
+  },
+  durationMs: { type: Number, default: 0 },
+  exitCode: { type: Number },
+  timedOut: { type: Boolean },
+  issues: [IssueSchema],
+  coverage: { type: Number },
+  reason: { type: String },
+  output: { type: String }
+}, { _id: false });
+
+const QualityReportSchema: Schema = new Schema({
+  projectId: {
+    type: String,
+    index: true
+  },
+  runId: {
+    type: String,
+    index: true
+  },
+  stageId: { type: String },
+  attempt: {
+    type: Number,
+    default: 0
+  },
+  status: {
+    type: String,
+    enum: ['passed', 'failed', 'error'],
+    required: true
+  },
+  filesChecked: {
+    type: Number,
+    default: 0
+  },
+  checks: [CheckResultSchema],
+  coverage: {
+    percent: { type: Number },
+    threshold: { type: Number }
+  },
+  repair: {
+    recommended: { type: Boolean, default: false },
+    reason: { type: String },
+    feedback: { type: String }
+  },
+  durationMs: {
+    type: Number,
+    default: 0
+  }
+}, {
+  timestamps: true
+});
+
+export const QualityReport = mongoose.model<IQualityReport>('QualityReport', QualityReportSchema);
@@ -0,0 +1,160 @@
+import express, { Request, Response } from 'express';
+import { body, param, query, validationResult } from 'express-validator';
+import { QualityReport } from '../models/QualityReport.js';
+import { QualityGate } from '../services/QualityGate.js';
+import { BUILTIN_CHECKS, COVERAGE_CHECK, DEFAULT_CHECKS, TOOLCHAINS } from '../services/checks.js';
+import { requireAuth, requireServiceToken } from '../middleware/auth.js';
+
+const router = express.Router();
+const gate = new QualityGate();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+// POST /api/quality-gates/runs - Run the checks against generated files and store the report (internal).
+// Checks are named, never supplied as commands: the sandbox runs them on the host in local mode.
+router.post('/runs',
+  requireServiceToken,
+  [
+    body('files').isArray({ min: 1 }).withMessage('Files must be a non-empty array'),
+    body('files.*.path').isString().isLength({ min: 1 }).withMessage('Each file needs a path'),
+    body('files.*.content').isString().withMessage('Each file needs content'),
+    body('checks').optional().isArray().withMessage('Checks must be an array'),
+    body('checks.*').isString().withMessage('Checks must be check IDs'),
+    body('coverageThreshold').optional().isFloat({ min: 0, max: 100 }).withMessage('Coverage threshold must be between 0 and 100'),
+    body('attempt').optional().isInt({ min: 0 }).withMessage('Attempt must be a non-negative integer')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const { files, checks, coverageThreshold, projectId, runId, stageId, attempt } = req.body;
+
+      try {
+        gate.resolveChecks(checks);
+      } catch (error) {
+        return res.status(400).json({
+          success: false,
+          error: error instanceof Error ? error.message : 'Invalid checks'
+        });
+      }
+
+      const report = await gate.run({
+        files,
+        checks,
+        coverageThreshold: coverageThreshold !== undefined ? parseFloat(coverageThreshold) : undefined
+      });
+
+      const saved = await QualityReport.create({
+        projectId,
+        runId,
+        stageId,
+        attempt: attempt || 0,
+        filesChecked: files.length,
+        ...report
+      });
+
+      res.status(201).json({
+        success: true,
+        data: saved
+      });
+    } catch (error) {
+      console.error('Error running quality gate:', error);
+      res.status(500).json({
+        success: false,
+        error: error instanceof Error ? error.message : 'Failed to run quality gate'
+      });
+    }
+  }
+);
+
+// GET /api/quality-gates/checks - Built-in checks and the default selection
+router.get('/checks', requireAuth, (req: Request, res: Response) => {
+  res.json({
+    success: true,
+    data: {
+      checks: [
+        ...BUILTIN_CHECKS.map(check => ({ id: check.id, description: check.description, toolchain: check.toolchain })),
+        { id: COVERAGE_CHECK, description: 'Fails when measured coverage is below the threshold' }
+      ],
+      defaults: DEFAULT_CHECKS,
+      toolchains: Object.fromEntries(Object.entries(TOOLCHAINS).map(([id, toolchain]) => [id, { image: toolchain.image, marker: toolchain.marker }]))
+    }
+  });
+});
+
+// GET /api/quality-gates/reports - Report history for a project or run
+router.get('/reports',
+  requireAuth,
+  [
+    query('projectId').optional().isString().withMessage('Project ID must be a string'),
+    query('runId').optional().isString().withMessage('Run ID must be a string'),
+    query('status').optional().isIn(['passed', 'failed', 'error']).withMessage('Status must be passed, failed or error')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const filter: any = {};
+      if (req.query.projectId) filter.projectId = req.query.projectId;
+      if (req.query.runId) filter.runId = req.query.runId;
+      if (req.query.status) filter.status = req.query.status;
+
+      // Tool output is large; the list only needs the verdicts
+      const reports = await QualityReport.find(filter)
+        .select('-checks.output -repair.feedback')
+        .sort({ createdAt: -1 })
+        .limit(100);
+
+      res.json({
+        success: true,
+        data: reports
+      });
+    } catch (error) {
+      console.error('Error fetching quality reports:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch quality reports'
+      });
+    }
+  }
+);
+
+// GET /api/quality-gates/reports/:id - Full report including tool output
+router.get('/reports/:id',
+  requireAuth,
+  [param('id').isMongoId().withMessage('Invalid report ID')],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const report = await QualityReport.findById(req.params.id);
+      if (!report) {
+        return res.status(404).json({
+          success: false,
+          error: 'Report not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: report
+      });
+    } catch (error) {
+      console.error('Error fetching quality report:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch quality report'
+      });
+    }
+  }
+);
+
+export default router;
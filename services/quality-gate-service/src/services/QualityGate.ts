@@ -0,0 +1,246 @@
+import { promises as fs } from 'fs';
+import * as path from 'path';
+import { Sandbox, SandboxResult, WorkspaceFile } from './Sandbox.js';
+import {
+  BUILTIN_CHECKS,
+  COVERAGE_CHECK,
+  DEFAULT_CHECKS,
+  TOOLCHAINS,
+  CheckDefinition,
+  CheckIssue,
+  Toolchain
+} from './checks.js';
+
+export type CheckStatus = 'passed' | 'failed' | 'skipped' | 'error';
+
+export interface CheckResult {
+  id: string;
+  description: string;
+  status: CheckStatus;
+  durationMs: number;
+  exitCode?: number | null;
+  timedOut?: boolean;
+  issues: CheckIssue[];
+  coverage?: number;
+  // Why the check was skipped, failed without running, or errored
+  reason?: string;
+  // Tail of the tool output
+  output?: string;
+}
+
+export interface GateRequest {
+  files: WorkspaceFile[];
+  checks?: string[];
+  coverageThreshold?: number;
+}
+
+export interface RepairDecision {
+  recommended: boolean;
+  reason: string;
+  // Condensed failures, written to be handed to the agent that repairs the code
+  feedback?: string;
+}
+
+export interface GateReport {
+  status: 'passed' | 'failed' | 'error';
+  checks: CheckResult[];
+  coverage: { percent?: number; threshold?: number };
+  repair: RepairDecision;
+  durationMs: number;
+}
+
+const CHECK_TIMEOUT_MS = parseInt(process.env.QUALITY_GATE_CHECK_TIMEOUT_MS || '300000');
+const SETUP_TIMEOUT_MS = parseInt(process.env.QUALITY_GATE_SETUP_TIMEOUT_MS || '300000');
+const REPORTED_OUTPUT_CHARS = 4000;
+const FEEDBACK_ISSUES_PER_CHECK = 20;
+
+const envThreshold = process.env.QUALITY_GATE_COVERAGE_MIN ? parseFloat(process.env.QUALITY_GATE_COVERAGE_MIN) : undefined;
+
+const tail = (text: string, chars = REPORTED_OUTPUT_CHARS) => (text.length > chars ? text.slice(-chars) : text);
+
+// Runs the configured checks against generated code in the sandbox and decides whether a failure is
+// worth a repair attempt: failing checks are, sandbox errors are not (the code never ran).
+export class QualityGate {
+  constructor(private sandbox: Sandbox = new Sandbox()) {}
+
+  // Throws on unknown check IDs so a typo in a pipeline definition is not silently a pass
+  resolveChecks(requested: string[] = DEFAULT_CHECKS): { checks: CheckDefinition[]; coverage: boolean } {
+    const checks: CheckDefinition[] = [];
+    let coverage = false;
+
+    for (const entry of requested) {
+      if (entry === COVERAGE_CHECK) {
+        coverage = true;
+        continue;
+      }
+      const check = BUILTIN_CHECKS.find(candidate => candidate.id === entry);
+      if (!check) {
+        throw new Error(`Unknown check "${entry}"`);
+      }
+      checks.push(check);
+    }
+
+    return { checks, coverage };
+  }
+
+  async run(request: GateRequest): Promise<GateReport> {
+    const startedAt = Date.now();
+    const { checks, coverage } = this.resolveChecks(request.checks);
+    const files = new Map(request.files.map(file => [file.path.replace(/^\.\//, ''), file.content]));
+    const workspace = await this.sandbox.createWorkspace(request.files);
+
+    try {
+      const results: CheckResult[] = [];
+      const blockedToolchains = new Map<Toolchain, CheckResult>();
+
+      for (const toolchain of new Set(checks.map(check => check.toolchain))) {
+        const blocked = await this.setup(workspace, toolchain, files);
+        if (blocked) blockedToolchains.set(toolchain, blocked);
+      }
+
+      for (const check of checks) {
+        const blocked = blockedToolchains.get(check.toolchain);
+        results.push(blocked
+          ? { ...blocked, id: check.id, description: check.description }
+          : await this.runCheck(workspace, check, files));
+      }
+
+      const threshold = request.coverageThreshold ?? envThreshold;
+      const measured = results.map(result => result.coverage).filter((value): value is number => value !== undefined);
+      // With several toolchains the weakest one decides
+      const percent = measured.length > 0 ? Math.min(...measured) : undefined;
+      if (coverage) {
+        results.push(this.coverageResult(percent, threshold));
+      }
+
+      const status = results.some(result => result.status === 'error')
+        ? 'error'
+        : results.some(result => result.status === 'failed') ? 'failed' : 'passed';
+
+      return {
+        status,
+        checks: results,
+        coverage: { percent, threshold },
+        repair: this.decideRepair(status, results),
+        durationMs: Date.now() - startedAt
+      };
+    } finally {
+      await this.sandbox.removeWorkspace(workspace);
+    }
+  }
+
+  // Installs dependencies once per toolchain; returns the result every check of the toolchain gets
+  // when that is not possible
+  private async setup(workspace: string, toolchain: Toolchain, files: Map<string, string>): Promise<CheckResult | undefined> {
+    const definition = TOOLCHAINS[toolchain];
+    const base = { id: '', description: '', durationMs: 0, issues: [] as CheckIssue[] };
+
+    if (!files.has(definition.marker)) {
+      return { ...base, status: 'skipped', reason: `No ${definition.marker} at the project root` };
+    }
+
+    let result: SandboxResult;
+    try {
+      result = await this.sandbox.run(workspace, {
+        image: definition.image,
+        command: definition.setup,
+        timeoutMs: SETUP_TIMEOUT_MS,
+        network: true,
+        env: definition.env
+      });
+    } catch (error) {
+      return { ...base, status: 'error', reason: `Sandbox unavailable: ${error instanceof Error ? error.message : error}` };
+    }
+
+    if (result.exitCode === 0 && !result.timedOut) return undefined;
+
+    // A manifest the package manager rejects is a defect in the generated code, so this is a failure
+    return {
+      ...base,
+      status: 'failed',
+      durationMs: result.durationMs,
+      exitCode: result.exitCode,
+      timedOut: result.timedOut,
+      reason: result.timedOut ? 'Dependency installation timed out' : 'Dependency installation failed',
+      issues: [{ file: definition.marker, message: `${definition.setup.split(' && ')[0]} failed` }],
+      output: tail(result.output)
+    };
+  }
+
+  private async runCheck(workspace: string, check: CheckDefinition, files: Map<string, string>): Promise<CheckResult> {
+    const base = { id: check.id, description: check.description, issues: [] as CheckIssue[] };
+
+    if (check.applies && !check.applies(files)) {
+      return { ...base, status: 'skipped', durationMs: 0, reason: 'Not applicable to this project' };
+    }
+
+    const toolchain = TOOLCHAINS[check.toolchain];
+    let result: SandboxResult;
+    try {
+      result = await this.sandbox.run(workspace, {
+        image: toolchain.image,
+        command: check.command,
+        timeoutMs: CHECK_TIMEOUT_MS,
+        env: toolchain.env
+      });
+    } catch (error) {
+      return { ...base, status: 'error', durationMs: 0, reason: `Sandbox unavailable: ${error instanceof Error ? error.message : error}` };
+    }
+
+    const readFile = (relative: string) => fs.readFile(path.join(workspace, relative), 'utf-8').catch(() => undefined);
+    const passed = result.exitCode === 0 && !result.timedOut;
+
+    return {
+      ...base,
+      status: passed ? 'passed' : 'failed',
+      durationMs: result.durationMs,
+      exitCode: result.exitCode,
+      timedOut: result.timedOut || undefined,
+      reason: result.timedOut ? `Timed out after ${CHECK_TIMEOUT_MS}ms` : undefined,
+      issues: check.parse ? check.parse(result) : [],
+      coverage: check.coverage ? await check.coverage(result, readFile) : undefined,
+      output: tail(result.output)
+    };
+  }
+
+  private coverageResult(percent: number | undefined, threshold: number | undefined): CheckResult {
+    const base = { id: COVERAGE_CHECK, description: 'Coverage threshold', durationMs: 0, issues: [] as CheckIssue[], coverage: percent };
+
+    if (threshold === undefined) {
+      return { ...base, status: percent === undefined ? 'skipped' : 'passed', reason: 'No coverage threshold configured' };
+    }
+    if (percent === undefined) {
+      return { ...base, status: 'failed', reason: 'Coverage was not measured', issues: [{ message: `No test run reported coverage; ${threshold}% is required` }] };
+    }
+    if (percent < threshold) {
+      return { ...base, status: 'failed', reason: `Coverage ${percent}% is below ${threshold}%`, issues: [{ message: `Coverage is ${percent}%, at least ${threshold}% is required; add tests` }] };
+    }
+    return { ...base, status: 'passed' };
+  }
+
+  private decideRepair(status: GateReport['status'], results: CheckResult[]): RepairDecision {
+    if (status === 'passed') {
+      return { recommended: false, reason: 'All checks passed' };
+    }
+    if (status === 'error') {
+      return { recommended: false, reason: 'The sandbox could not run every check; rerun the gate instead of changing the code' };
+    }
+
+    const failed = results.filter(result => result.status === 'failed');
+    const feedback = failed.map(result => {
+      const issues = result.issues.slice(0, FEEDBACK_ISSUES_PER_CHECK).map(issue => {
+        const location = issue.file ? `${issue.file}${issue.line ? `:${issue.line}` : ''}: ` : '';
+        return `- ${location}${issue.message}${issue.rule ? ` (${issue.rule})` : ''}`;
+      });
+      // Without parsed issues the tool output is the only clue
+      const detail = issues.length > 0 ? issues.join('\n') : tail(result.output || result.reason || '', 1500);
+      return `## ${result.id}${result.reason ? ` - ${result.reason}` : ''}\n${detail}`;
+    });
+
+    return {
+      recommended: true,
+      reason: `${failed.length} check(s) failed: ${failed.map(result => result.id).join(', ')}`,
+      feedback: feedback.join('\n\n')
+    };
+  }
+}
@@ -0,0 +1,132 @@
+import { spawn, ChildProcess } from 'child_process';
+import { promises as fs } from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { randomUUID } from 'crypto';
+
+export interface WorkspaceFile {
+  path: string;
+  content: string;
+}
+
+export interface SandboxCommand {
+  image: string;
+  command: string;
+  timeoutMs: number;
+  // Only dependency installation gets network access; checks themselves run offline
+  network?: boolean;
+  env?: Record<string, string>;
+}
+
+export interface SandboxResult {
+  exitCode: number | null;
+  output: string;
+  durationMs: number;
+  timedOut: boolean;
+}
+
+const MAX_OUTPUT_CHARS = parseInt(process.env.QUALITY_GATE_MAX_OUTPUT_CHARS || '65536');
+
+// Runs commands against a scratch copy of the generated files. In "docker" mode (the default) every
+// command gets a throwaway container with no network, capped memory, CPU and process count; "local"
+// runs on the host and is only meant for development machines without Docker.
+export class Sandbox {
+  private mode: 'docker' | 'local';
+
+  constructor(mode: string | undefined = process.env.QUALITY_GATE_SANDBOX) {
+    this.mode = mode === 'local' ? 'local' : 'docker';
+  }
+
+  get isolated(): boolean {
+    return this.mode === 'docker';
+  }
+
+  async createWorkspace(files: WorkspaceFile[]): Promise<string> {
+    const root = await fs.mkdtemp(path.join(process.env.QUALITY_GATE_WORKDIR || os.tmpdir(), 'quality-gate-'));
+
+    for (const file of files) {
+      const target = path.resolve(root, file.path);
+      // Generated paths are untrusted; never write outside the workspace
+      if (!target.startsWith(root + path.sep)) {
+        throw new Error(`Refusing to write file outside the workspace: ${file.path}`);
+      }
+      await fs.mkdir(path.dirname(target), { recursive: true });
+      await fs.writeFile(target, file.content);
+    }
+
+    return root;
+  }
+
+  async removeWorkspace(workspace: string): Promise<void> {
+    await fs.rm(workspace, { recursive: true, force: true });
+  }
+
+  run(workspace: string, command: SandboxCommand): Promise<SandboxResult> {
+    const containerName = `quality-gate-${randomUUID()}`;
+    const child = this.mode === 'docker'
+      ? spawn('docker', this.dockerArgs(workspace, containerName, command))
+      : spawn('sh', ['-c', command.command], { cwd: workspace, env: { ...process.env, ...command.env, CI: 'true' } });
+
+    return this.collect(child, command.timeoutMs, () => {
+      if (this.mode === 'docker') {
+        // Killing the docker client does not stop the container
+        spawn('docker', ['rm', '-f', containerName]).on('error', () => undefined);
+      }
+      child.kill('SIGKILL');
+    });
+  }
+
+  private dockerArgs(workspace: string, containerName: string, command: SandboxCommand): string[] {
+    const env = Object.entries({ ...command.env, CI: 'true' }).flatMap(([key, value]) => ['-e', `${key}=${value}`]);
+
+    return [
+      'run', '--rm',
+      '--name', containerName,
+      '--network', command.network ? 'bridge' : 'none',
+      '--memory', process.env.QUALITY_GATE_MEMORY || '1g',
+      '--cpus', process.env.QUALITY_GATE_CPUS || '1',
+      '--pids-limit', '512',
+      '--security-opt', 'no-new-privileges',
+      '-v', `${workspace}:/workspace`,
+      '-w', '/workspace',
+      ...env,
+      command.image,
+      'sh', '-c', command.command
+    ];
+  }
+
+  private collect(child: ChildProcess, timeoutMs: number, kill: () => void): Promise<SandboxResult> {
+    const startedAt = Date.now();
+    let output = '';
+    let timedOut = false;
+
+    // Keep the tail: failures are reported at the end of the output
+    const append = (chunk: Buffer) => {
+      output += chunk.toString();
+      if (output.length > MAX_OUTPUT_CHARS * 2) output = output.slice(-MAX_OUTPUT_CHARS);
+    };
+    child.stdout?.on('data', append);
+    child.stderr?.on('data', append);
+
+    const timer = setTimeout(() => {
+      timedOut = true;
+      kill();
+    }, timeoutMs);
+
+    return new Promise((resolve, reject) => {
+      child.on('error', (error) => {
+        clearTimeout(timer);
+        reject(error);
+      });
+      child.on('close', (exitCode) => {
+        clearTimeout(timer);
+        resolve({
+          exitCode,
+          output: output.slice(-MAX_OUTPUT_CHARS),
+          durationMs: Date.now() - startedAt,
+          timedOut
+        });
+      });
+    });
+  }
+}
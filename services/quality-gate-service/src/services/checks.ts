@@ -0,0 +1,143 @@
+import { SandboxResult } from './Sandbox.js';
+
+export type Toolchain = 'go' | 'node';
+
+export interface CheckIssue {
+  file?: string;
+  line?: number;
+  column?: number;
+  rule?: string;
+  message: string;
+}
+
+export interface CheckDefinition {
+  id: string;
+  description: string;
+  toolchain: Toolchain;
+  command: string;
+  // Turns tool output into file/line issues the repair agent can act on
+  parse?: (result: SandboxResult) => CheckIssue[];
+  // Statement or line coverage in percent, when the check measures it
+  coverage?: (result: SandboxResult, readFile: (path: string) => Promise<string | undefined>) => Promise<number | undefined>;
+  // Skips the check when the project does not support it, e.g. a package.json without a test script
+  applies?: (files: Map<string, string>) => boolean;
+}
+
+export interface ToolchainDefinition {
+  image: string;
+  // Marker file that has to exist at the workspace root for the toolchain's checks to run
+  marker: string;
+  // Dependency installation, the only step that runs with network access
+  setup: string;
+  env?: Record<string, string>;
+}
+
+export const TOOLCHAINS: Record<Toolchain, ToolchainDefinition> = {
+  go: {
+    image: process.env.QUALITY_GATE_GO_IMAGE || 'golang:1.22-alpine',
+    marker: 'go.mod',
+    setup: 'go mod download',
+    // Module and build caches live in the workspace so they survive between containers
+    env: { GOMODCACHE: '/workspace/.cache/gomod', GOCACHE: '/workspace/.cache/gobuild', GOFLAGS: '-mod=mod' }
+  },
+  node: {
+    image: process.env.QUALITY_GATE_NODE_IMAGE || 'node:20-alpine',
+    marker: 'package.json',
+    setup: 'npm install --no-audit --no-fund --ignore-scripts && '
+      + '(test -x node_modules/.bin/eslint || npm install --no-save --no-audit --no-fund eslint@8)',
+    env: { npm_config_cache: '/workspace/.cache/npm' }
+  }
+};
+
+const GO_POSITION = /^(?:\.\/)?([^\s:]+\.go):(\d+):(?:(\d+):)?\s*(.+)$/;
+
+const parseGoPositions = (result: SandboxResult): CheckIssue[] =>
+  result.output.split('\n').flatMap(raw => {
+    const match = raw.trim().match(GO_POSITION);
+    return match
+      ? [{ file: match[1], line: parseInt(match[2]), column: match[3] ? parseInt(match[3]) : undefined, message: match[4] }]
+      : [];
+  });
+
+const packageScripts = (files: Map<string, string>): Record<string, string> => {
+  try {
+    return JSON.parse(files.get('package.json') || '{}').scripts || {};
+  } catch {
+    return {};
+  }
+};
+
+export const BUILTIN_CHECKS: CheckDefinition[] = [
+  {
+    id: 'go-vet',
+    description: 'go vet ./...',
+    toolchain: 'go',
+    command: 'go vet ./...',
+    parse: parseGoPositions
+  },
+  {
+    id: 'go-test',
+    description: 'go test with statement coverage',
+    toolchain: 'go',
+    command: 'go test -coverprofile=.cache/cover.out ./... && go tool cover -func=.cache/cover.out | tail -n 1',
+    parse: (result) => {
+      const failedTests = Array.from(result.output.matchAll(/^--- FAIL: (\S+)/gm))
+        .map(match => ({ rule: match[1], message: `Test ${match[1]} failed` }));
+      return [...failedTests, ...parseGoPositions(result)];
+    },
+    coverage: async (result) => {
+      const match = result.output.match(/^total:\s+\(statements\)\s+([\d.]+)%/m);
+      return match ? parseFloat(match[1]) : undefined;
+    }
+  },
+  {
+    id: 'eslint',
+    description: 'ESLint with the project configuration',
+    toolchain: 'node',
+    command: 'node_modules/.bin/eslint . --ext .js,.jsx,.ts,.tsx --format json',
+    parse: (result) => {
+      const json = result.output.slice(result.output.indexOf('['));
+      try {
+        const reports = JSON.parse(json) as Array<{ filePath: string; messages: any[] }>;
+        return reports.flatMap(report => report.messages
+          .filter(message => message.severity === 2)
+          .map(message => ({
+            file: report.filePath.replace(/^\/workspace\//, ''),
+            line: message.line,
+            column: message.column,
+            rule: message.ruleId || undefined,
+            message: message.message
+          })));
+      } catch {
+        // Configuration errors and crashes are not JSON; the raw output is kept on the check
+        return [];
+      }
+    }
+  },
+  {
+    id: 'unit-tests',
+    description: 'npm test, with a Jest json-summary coverage report when available',
+    toolchain: 'node',
+    command: 'npm test --silent -- --coverage --coverageReporters=json-summary --coverageReporters=text-summary',
+    applies: (files) => Boolean(packageScripts(files).test) && !/no test specified/.test(packageScripts(files).test),
+    parse: (result) => Array.from(result.output.matchAll(/^\s*(?:FAIL|✕)\s+(.+)$/gm))
+      .map(match => ({ message: `Failing: ${match[1].trim()}` })),
+    coverage: async (_result, readFile) => {
+      const summary = await readFile('coverage/coverage-summary.json');
+      if (!summary) return undefined;
+      try {
+        return JSON.parse(summary).total?.lines?.pct;
+      } catch {
+        return undefined;
+      }
+    }
+  }
+];
+
+// The pseudo-check that compares measured coverage against the threshold
+export const COVERAGE_CHECK = 'coverage';
+
+export const DEFAULT_CHECKS = (process.env.QUALITY_GATE_CHECKS || 'go-vet,go-test,eslint,unit-tests,coverage')
+  .split(',')
+  .map(id => id.trim())
+  .filter(Boolean);
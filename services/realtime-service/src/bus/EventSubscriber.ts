@@ -0,0 +1,21 @@
+// CloudEvents 1.0 envelope published by pipeline-service (see docs/events.md)
+export interface DomainEvent {
+  specversion: '1.0';
+  id: string;
+  type: string;
+  source: string;
+  time: string;
+  subject?: string;
+  datacontenttype?: string;
+  tenantid?: string;
+  requestid?: string;
+  projectid?: string;
+  data: Record<string, any>;
+}
+
+// Receives every domain event from the message bus
+export interface EventSubscriber {
+  readonly name: string;
+  subscribe(handler: (event: DomainEvent) => void): Promise<void>;
+  close(): Promise<void>;
+}
@@ -0,0 +1,35 @@
+import { Kafka, Consumer } from 'kafkajs';
+import { hostname } from 'os';
+import { DomainEvent, EventSubscriber } from './EventSubscriber.js';
+
+// Every instance needs every event to serve its own clients, so each one gets its own consumer group
+export class KafkaSubscriber implements EventSubscriber {
+  readonly name = 'kafka';
+  private consumer: Consumer;
+  private topic: string;
+
+  constructor(brokers: string = process.env.KAFKA_BROKERS || 'localhost:9092') {
+    const kafka = new Kafka({ clientId: 'realtime-service', brokers: brokers.split(',') });
+    this.consumer = kafka.consumer({ groupId: `realtime-service-${hostname()}` });
+    this.topic = process.env.EVENT_BUS_TOPIC || 'ai-pipeline.events';
+  }
+
+  async subscribe(handler: (event: DomainEvent) => void): Promise<void> {
+    await this.consumer.connect();
+    await this.consumer.subscribe({ topic: this.topic, fromBeginning: false });
+    await this.consumer.run({
+      eachMessage: async ({ message }) => {
+        if (!message.value) return;
+        try {
+          handler(JSON.parse(message.value.toString()));
+        } catch (error) {
+          console.error('Failed to handle Kafka event:', error);
+        }
+      }
+    });
+  }
+
+  async close(): Promise<void> {
+    await this.consumer.disconnect();
+  }
+}
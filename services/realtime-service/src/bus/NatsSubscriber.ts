@@ -0,0 +1,33 @@
+import { connect, JSONCodec, NatsConnection } from 'nats';
+import { DomainEvent, EventSubscriber } from './EventSubscriber.js';
+
+// Subscribes to every subject under the event prefix, e.g. ai-pipeline.stage.completed
+export class NatsSubscriber implements EventSubscriber {
+  readonly name = 'nats';
+  private connection?: NatsConnection;
+  private codec = JSONCodec<DomainEvent>();
+  private subjectPrefix: string;
+
+  constructor(private servers: string = process.env.NATS_URL || 'nats://localhost:4222') {
+    this.subjectPrefix = process.env.EVENT_BUS_PREFIX || 'ai-pipeline';
+  }
+
+  async subscribe(handler: (event: DomainEvent) => void): Promise<void> {
+    this.connection = await connect({ servers: this.servers.split(','), name: 'realtime-service' });
+    const subscription = this.connection.subscribe(`${this.subjectPrefix}.>`);
+
+    (async () => {
+      for await (const message of subscription) {
+        try {
+          handler(this.codec.decode(message.data));
+        } catch (error) {
+          console.error(`Failed to handle ${message.subject} event:`, error);
+        }
+      }
+    })();
+  }
+
+  async close(): Promise<void> {
+    await this.connection?.drain();
+  }
+}
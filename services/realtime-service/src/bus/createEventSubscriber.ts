@@ -0,0 +1,15 @@
+import { EventSubscriber } from './EventSubscriber.js';
+import { NatsSubscriber } from './NatsSubscriber.js';
+import { KafkaSubscriber } from './KafkaSubscriber.js';
+
+// Selects the bus from EVENT_BUS (nats | kafka), matching the publisher in pipeline-service
+export function createEventSubscriber(kind: string | undefined = process.env.EVENT_BUS): EventSubscriber {
+  switch (kind) {
+    case 'nats':
+      return new NatsSubscriber();
+    case 'kafka':
+      return new KafkaSubscriber();
+    default:
+      throw new Error(`Unknown EVENT_BUS "${kind}" (expected nats or kafka)`);
+  }
+}
@@ -0,0 +1,110 @@
+import express, { Request, Response } from 'express';
+import { param, query, validationResult } from 'express-validator';
+import { RunEventBuffer, RunEvent } from '../services/RunEventBuffer.js';
+import { requireAuth, AuthenticatedRequest } from '../middleware/auth.js';
+import { canAccessRun } from '../services/RunAccess.js';
+
+const router = express.Router();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const runIdParam = param('runId').isString().isLength({ min: 1, max: 200 }).withMessage('Invalid run ID');
+const sinceQuery = query('since').optional().isInt({ min: 0 }).withMessage('since must be a non-negative integer');
+
+const HEARTBEAT_MS = parseInt(process.env.SSE_HEARTBEAT_MS || '15000');
+
+// EventSource resends the last seen id as Last-Event-ID on reconnect; ?since= covers manual resumes
+const resumeFrom = (req: Request): number =>
+  parseInt((req.get('Last-Event-ID') || req.query.since || '0') as string) || 0;
+
+export default function createRealtimeRoutes(buffer: RunEventBuffer) {
+  // Runs the caller cannot see answer the same as runs that do not exist
+  const requireRunAccess = async (req: AuthenticatedRequest, res: Response, next: express.NextFunction) => {
+    try {
+      if (!await canAccessRun(buffer, req.params.runId, req.user!, req.headers.authorization!)) {
+        return res.status(404).json({
+          success: false,
+          error: 'Run not found'
+        });
+      }
+      next();
+    } catch (error) {
+      console.error('Run access check error:', error);
+      res.status(502).json({
+        success: false,
+        error: 'Failed to check run access'
+      });
+    }
+  };
+
+  // GET /api/realtime/runs/:runId/events - Server-sent event stream of run progress
+  router.get('/runs/:runId/events',
+    requireAuth,
+    [runIdParam, sinceQuery],
+    validateRequest,
+    requireRunAccess,
+    (req: Request, res: Response) => {
+      const { runId } = req.params;
+
+      res.writeHead(200, {
+        'Content-Type': 'text/event-stream',
+        'Cache-Control': 'no-cache',
+        'Connection': 'keep-alive',
+        'X-Accel-Buffering': 'no'
+      });
+
+      const send = (event: RunEvent) => {
+        res.write(`id: ${event.seq}\nevent: ${event.type}\ndata: ${JSON.stringify(event)}\n\n`);
+      };
+
+      // Replay and subscribe happen in the same tick, so no event can slip in between
+      const { events, truncated } = buffer.replay(runId, resumeFrom(req));
+      if (truncated) {
+        res.write(`event: truncated\ndata: ${JSON.stringify({ runId, oldestSeq: events[0]?.seq })}\n\n`);
+      }
+      events.forEach(send);
+      const unsubscribe = buffer.subscribe(runId, send);
+
+      const heartbeat = setInterval(() => res.write(': heartbeat\n\n'), HEARTBEAT_MS);
+
+      req.on('close', () => {
+        clearInterval(heartbeat);
+        unsubscribe();
+      });
+    }
+  );
+
+  // GET /api/realtime/runs/:runId/events/history - Buffered events as JSON, for polling clients
+  router.get('/runs/:runId/events/history',
+    requireAuth,
+    [runIdParam, sinceQuery],
+    validateRequest,
+    requireRunAccess,
+    (req: Request, res: Response) => {
+      const { events, truncated } = buffer.replay(req.params.runId, resumeFrom(req));
+
+      res.json({
+        success: true,
+        data: {
+          runId: req.params.runId,
+          events,
+          truncated,
+          finished: buffer.isFinished(req.params.runId)
+        }
+      });
+    }
+  );
+
+  return router;
+}
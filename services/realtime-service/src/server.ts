@@ -0,0 +1,192 @@
+import express from 'express';
+import cors from 'cors';
+import { createServer } from 'http';
+import { Server as SocketIOServer } from 'socket.io';
+import dotenv from 'dotenv';
+import winston from 'winston';
+import createRealtimeRoutes from './routes/realtime.js';
+import { RunEventBuffer } from './services/RunEventBuffer.js';
+import { createEventSubscriber } from './bus/createEventSubscriber.js';
+import { verifyToken } from './middleware/auth.js';
+import { canAccessRun } from './services/RunAccess.js';
+
+// Load environment variables
+dotenv.config();
+
+const app = express();
+const server = createServer(app);
+const PORT = process.env.PORT || 3013;
+
+// Logger configuration
+const logger = winston.createLogger({
+  level: 'info',
+  format: winston.format.combine(
+    winston.format.timestamp(),
+    winston.format.json()
+  ),
+  transports: [
+    new winston.transports.Console(),
+    new winston.transports.File({ filename: 'logs/realtime-service.log' })
+  ]
+});
+
+// Socket.IO lives under /realtime so the gateway can route it apart from pipeline-service's socket
+const io = new SocketIOServer(server, {
+  path: '/realtime/socket.io',
+  cors: {
+    origin: process.env.FRONTEND_URL || 'http://localhost:5173',
+    methods: ['GET', 'POST'],
+    credentials: true
+  }
+});
+
+// Run progress from the event bus, buffered for replay
+const buffer = new RunEventBuffer();
+buffer.start();
+
+const subscriber = createEventSubscriber(process.env.EVENT_BUS || 'nats');
+subscriber.subscribe(event => {
+  const runEvent = buffer.ingest(event);
+  if (runEvent) {
+    io.to(`run-${runEvent.runId}`).emit('pipeline-event', runEvent);
+  }
+})
+  .then(() => logger.info(`📡 Subscribed to pipeline events on ${subscriber.name}`))
+  .catch((error) => {
+    logger.error(`❌ Failed to subscribe to ${subscriber.name}:`, error);
+    process.exit(1);
+  });
+
+// Middleware
+app.use(cors({
+  origin: process.env.FRONTEND_URL || 'http://localhost:5173',
+  credentials: true
+}));
+app.use(express.json());
+
+// Request logging middleware
+app.use((req, res, next) => {
+  logger.info(`${req.method} ${req.path}`, {
+    ip: req.ip,
+    userAgent: req.get('User-Agent'),
+    timestamp: new Date().toISOString()
+  });
+  next();
+});
+
+// Routes
+app.use('/api/realtime', createRealtimeRoutes(buffer));
+
+// Health check endpoint
+app.get('/health', (req, res) => {
+  res.json({
+    status: 'ok',
+    service: 'realtime-service',
+    timestamp: new Date().toISOString(),
+    version: '1.0.0',
+    eventBus: subscriber.name,
+    connections: io.engine.clientsCount,
+    buffered: buffer.stats()
+  });
+});
+
+// Service info endpoint
+app.get('/info', (req, res) => {
+  res.json({
+    service: 'Realtime Service',
+    version: '1.0.0',
+    description: 'Streams pipeline run progress to the IDE',
+    endpoints: [
+      { method: 'GET', path: '/api/realtime/runs/:runId/events', description: 'Server-sent event stream (resume with Last-Event-ID or ?since=)' },
+      { method: 'GET', path: '/api/realtime/runs/:runId/events/history', description: 'Buffered run events as JSON' },
+      { method: 'WS', path: '/realtime/socket.io', description: 'Socket.IO: join-pipeline { pipelineId, since } -> pipeline-event, or pipeline-error when the run is not visible' }
+    ]
+  });
+});
+
+// Socket.IO clients authenticate with { auth: { token } } on connect
+io.use(async (socket, next) => {
+  const token = socket.handshake.auth?.token;
+  if (!token) return next(new Error('Access token required'));
+
+  try {
+    const user = await verifyToken(token);
+    if (!user) return next(new Error('Invalid token'));
+    socket.data.user = user;
+    socket.data.authorization = `Bearer ${token}`;
+    next();
+  } catch (error) {
+    next(new Error('Authentication failed'));
+  }
+});
+
+io.on('connection', (socket) => {
+  logger.info(`Client connected: ${socket.id}`);
+
+  // Join a run the user can see and replay anything after the client's last seen seq
+  socket.on('join-pipeline', async (request: string | { pipelineId: string; since?: number }) => {
+    const { pipelineId, since = 0 } = typeof request === 'string' ? { pipelineId: request } : request;
+    if (!pipelineId) return;
+
+    try {
+      if (!await canAccessRun(buffer, pipelineId, socket.data.user, socket.data.authorization)) {
+        socket.emit('pipeline-error', { runId: pipelineId, error: 'Run not found' });
+        return;
+      }
+    } catch (error) {
+      logger.error('Run access check error:', error);
+      socket.emit('pipeline-error', { runId: pipelineId, error: 'Failed to check run access' });
+      return;
+    }
+
+    const { events, truncated } = buffer.replay(pipelineId, since);
+    if (truncated) {
+      socket.emit('pipeline-truncated', { runId: pipelineId, oldestSeq: events[0]?.seq });
+    }
+    events.forEach(event => socket.emit('pipeline-event', event));
+    socket.join(`run-${pipelineId}`);
+  });
+
+  socket.on('leave-pipeline', (pipelineId: string) => {
+    socket.leave(`run-${pipelineId}`);
+  });
+
+  socket.on('disconnect', () => {
+    logger.info(`Client disconnected: ${socket.id}`);
+  });
+});
+
+// Catch-all for undefined routes
+app.use('*', (req, res) => {
+  res.status(404).json({
+    success: false,
+    error: 'Route not found',
+    service: 'realtime-service'
+  });
+});
+
+// Global error handler
+app.use((err: any, req: any, res: any, next: any) => {
+  logger.error('Unhandled error:', err);
+  res.status(500).json({
+    success: false,
+    error: 'Internal server error',
+    service: 'realtime-service'
+  });
+});
+
+// Graceful shutdown
+process.on('SIGTERM', async () => {
+  logger.info('SIGTERM received. Shutting down gracefully...');
+  buffer.stop();
+  io.close();
+  await subscriber.close().catch(() => undefined);
+  process.exit(0);
+});
+
+// Start server
+server.listen(PORT, () => {
+  logger.info(`📺 Realtime Service running on port ${PORT}`);
+});
+
+export default app;
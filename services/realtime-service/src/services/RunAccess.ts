@@ -0,0 +1,30 @@
+import axios from 'axios';
+import { RunEventBuffer } from './RunEventBuffer.js';
+import { User } from '../middleware/auth.js';
+
+// Run IDs are guessable, so every subscription is checked against the run's owner: members of its
+// project, or for runs without a project the user who started it. Admins see every run.
+// Resolves to null when no events of the run have been seen, true or false otherwise.
+export const canAccessRun = async (
+  buffer: RunEventBuffer,
+  runId: string,
+  user: User,
+  authorization: string
+): Promise<boolean | null> => {
+  const owner = buffer.ownerOf(runId);
+  if (!owner) return null;
+  if (user.role === 'admin') return true;
+  if (!owner.projectId) return owner.tenantId === user._id;
+
+  const projectServiceUrl = process.env.PROJECT_SERVICE_URL || 'http://localhost:3002';
+  try {
+    await axios.get(`${projectServiceUrl}/api/projects/${encodeURIComponent(owner.projectId)}`, {
+      headers: { Authorization: authorization },
+      timeout: 5000
+    });
+    return true;
+  } catch (error) {
+    if (!axios.isAxiosError(error) || !error.response) throw error;
+    return false;
+  }
+};
@@ -0,0 +1,130 @@
+import { EventEmitter } from 'events';
+import { DomainEvent } from '../bus/EventSubscriber.js';
+
+// What clients receive: one entry per run event, numbered so a reconnecting client can resume
+export interface RunEvent {
+  seq: number;
+  id: string;
+  runId: string;
+  type: string;
+  stageId?: string;
+  data: any;
+  time: string;
+}
+
+export interface Replay {
+  events: RunEvent[];
+  // Events between the client's last seq and the oldest retained one were evicted
+  truncated: boolean;
+}
+
+// Who a run belongs to, taken from the attribution on its events
+export interface RunOwner {
+  projectId?: string;
+  tenantId?: string;
+}
+
+interface RunStream {
+  events: RunEvent[];
+  nextSeq: number;
+  lastActivity: number;
+  owner: RunOwner;
+}
+
+const TERMINAL_TYPES = ['pipeline.completed', 'pipeline.failed', 'pipeline.cancelled'];
+
+// Keeps the recent events of each run in memory so clients can replay what they missed
+export class RunEventBuffer {
+  private runs = new Map<string, RunStream>();
+  private emitter = new EventEmitter();
+  private sweeper?: NodeJS.Timeout;
+
+  constructor(
+    private maxEventsPerRun: number = parseInt(process.env.RUN_BUFFER_SIZE || '1000'),
+    private idleTtlMs: number = parseInt(process.env.RUN_BUFFER_TTL_MS || '3600000')
+  ) {
+    this.emitter.setMaxListeners(0);
+  }
+
+  start(): void {
+    this.sweeper = setInterval(() => this.evictIdle(), 60000);
+    this.sweeper.unref();
+  }
+
+  stop(): void {
+    if (this.sweeper) clearInterval(this.sweeper);
+  }
+
+  // Maps a domain event onto its run; events without a pipeline ID are not run progress
+  ingest(event: DomainEvent): RunEvent | undefined {
+    const runId = event.data?.pipelineId;
+    if (!runId) return undefined;
+
+    let stream = this.runs.get(runId);
+    if (!stream) {
+      stream = { events: [], nextSeq: 1, lastActivity: Date.now(), owner: {} };
+      this.runs.set(runId, stream);
+    }
+    stream.owner.projectId ??= event.projectid;
+    stream.owner.tenantId ??= event.tenantid;
+
+    // Progress events wrap the socket payload pipeline-service already emits to its own clients
+    const progress = event.type === 'pipeline.progress';
+    const runEvent: RunEvent = {
+      seq: stream.nextSeq++,
+      id: event.id,
+      runId,
+      type: progress ? event.data.type : event.type,
+      stageId: event.data.stageId,
+      data: progress ? event.data.data : event.data,
+      time: event.time
+    };
+
+    stream.events.push(runEvent);
+    if (stream.events.length > this.maxEventsPerRun) {
+      stream.events.splice(0, stream.events.length - this.maxEventsPerRun);
+    }
+    stream.lastActivity = Date.now();
+
+    this.emitter.emit(runId, runEvent);
+    return runEvent;
+  }
+
+  replay(runId: string, afterSeq: number = 0): Replay {
+    const stream = this.runs.get(runId);
+    if (!stream) return { events: [], truncated: false };
+
+    const events = stream.events.filter(event => event.seq > afterSeq);
+    const oldest = stream.events[0]?.seq ?? stream.nextSeq;
+    return { events, truncated: oldest > afterSeq + 1 };
+  }
+
+  // Undefined for runs with no buffered events
+  ownerOf(runId: string): RunOwner | undefined {
+    return this.runs.get(runId)?.owner;
+  }
+
+  isFinished(runId: string): boolean {
+    return !!this.runs.get(runId)?.events.some(event => TERMINAL_TYPES.includes(event.type));
+  }
+
+  subscribe(runId: string, listener: (event: RunEvent) => void): () => void {
+    this.emitter.on(runId, listener);
+    return () => this.emitter.off(runId, listener);
+  }
+
+  stats(): { runs: number; events: number } {
+    let events = 0;
+    for (const stream of this.runs.values()) events += stream.events.length;
+    return { runs: this.runs.size, events };
+  }
+
+  private evictIdle(): void {
+    const cutoff = Date.now() - this.idleTtlMs;
+    for (const [runId, stream] of this.runs) {
+      if (stream.lastActivity < cutoff && this.emitter.listenerCount(runId) === 0) {
+        this.runs.delete(runId);
+      }
+    }
+  }
+}
@@ -0,0 +1,97 @@
+import mongoose, { Schema, Document } from 'mongoose';
+import { ReviewFinding } from '../types/index.js';
+
+export interface IReview extends Document {
+  projectId?: string;
+  runId?: string;
+  stageId?: string;
+  artifactId?: string;
+  status: 'completed' | 'failed';
+  model: string;
+  summary: string;
+  findings: ReviewFinding[];
+  counts: Map<string, number>;
+  blocking: boolean;
+  error?: string;
+  usage?: {
+    promptTokens: number;
+    completionTokens: number;
+    totalTokens: number;
+    costUsd?: number;
+  };
+  pullRequest?: {
+    projectId: string;
+    number: number;
+    url?: string;
+    publishedAt: Date;
+  };
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const FindingSchema = new Schema({
+  severity: {
+    type: String,
+    enum: ['critical', 'major', 'minor', 'info'],
+    required: true
+  },
+  file: { type: String, required: true },
+  line: { type: Number },
+  title: { type: String, required: true },
+  description: { type: String, default: '' },
+  suggestion: { type: String }
+}, { _id: false });
+
+const ReviewSchema: Schema = new Schema({
+  projectId: {
+    type: String,
+    index: true
+  },
+  runId: {
+    type: String,
+    index: true
+  },
+  stageId: { type: String },
+  artifactId: { type: String },
+  status: {
+    type: String,
+    enum: ['completed', 'failed'],
+    required: true
+  },
+  model: {
+    type: String,
+    required: true
+  },
+  summary: {
+    type: String,
+    default: ''
+  },
+  findings: [FindingSchema],
+  counts: {
+    type: Map,
+    of: Number,
+    default: {}
+  },
+  // True when any finding is severe enough to block promotion
+  blocking: {
+    type: Boolean,
+    default: false
+  },
+  error: { type: String },
+  usage: {
+    promptTokens: Number,
+    completionTokens: Number,
+    totalTokens: Number,
+    costUsd: Number
+  },
+  pullRequest: {
+    projectId: String,
+    number: Number,
+    url: String,
+    publishedAt: Date
+  }
+}, {
+  timestamps: true
+});
+
+export const Review = mongoose.model<IReview>('Review', ReviewSchema);
@@ -0,0 +1,268 @@
+import express, { Request, Response } from 'express';
+import { body, param, query, validationResult } from 'express-validator';
+import axios from 'axios';
+import { Review } from '../models/Review.js';
+import { ReviewAgent } from '../services/ReviewAgent.js';
+import { GitClient } from '../services/GitClient.js';
+import { loadArtifactFiles } from '../services/artifacts.js';
+import { ReviewFile } from '../types/index.js';
+import { requireUserOrService, AuthenticatedRequest } from '../middleware/auth.js';
+
+const router = express.Router();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const reviewIdParam = param('id').isMongoId().withMessage('Invalid review ID');
+
+// Confirms through project-service that the caller is a member of the project. Platform services
+// (pipeline-service's review stage) are trusted; reviews without a project are theirs alone.
+const canAccessProject = async (req: AuthenticatedRequest, projectId?: string): Promise<boolean> => {
+  if (req.service) return true;
+  if (!projectId) return false;
+
+  const projectServiceUrl = process.env.PROJECT_SERVICE_URL || 'http://localhost:3002';
+  try {
+    await axios.get(`${projectServiceUrl}/api/projects/${encodeURIComponent(projectId)}`, {
+      headers: { Authorization: req.headers.authorization },
+      timeout: 5000
+    });
+    return true;
+  } catch (error) {
+    if (!axios.isAxiosError(error) || !error.response) throw error;
+    return false;
+  }
+};
+
+export default function createReviewRoutes(agent: ReviewAgent, git: GitClient) {
+  router.use(requireUserOrService);
+
+  // POST /api/reviews - Review a generated bundle and store structured findings
+  router.post('/',
+    [
+      body('files').optional().isArray({ min: 1 }).withMessage('Files must be a non-empty array'),
+      body('files.*.path').optional().isString().isLength({ min: 1 }).withMessage('Each file needs a path'),
+      body('files.*.content').optional().isString().withMessage('Each file needs content'),
+      body('artifactId').optional().isMongoId().withMessage('Invalid artifact ID'),
+      body('projectId').optional().isString().withMessage('Project ID must be a string'),
+      body('runId').optional().isString().withMessage('Run ID must be a string'),
+      body('stageId').optional().isString().withMessage('Stage ID must be a string'),
+      body('context').optional().isString().withMessage('Context must be a string'),
+      body('model').optional().isString().withMessage('Model must be a string')
+    ],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      const { files, artifactId, projectId, runId, stageId, context, model } = req.body;
+
+      if (!files && !artifactId) {
+        return res.status(400).json({
+          success: false,
+          error: 'files or artifactId is required'
+        });
+      }
+
+      try {
+        if (!await canAccessProject(req, projectId)) {
+          return res.status(404).json({
+            success: false,
+            error: 'Project not found'
+          });
+        }
+      } catch (error) {
+        console.error('Project access check error:', error);
+        return res.status(502).json({
+          success: false,
+          error: 'Failed to check project access'
+        });
+      }
+
+      try {
+        const reviewFiles: ReviewFile[] = files || await loadArtifactFiles(artifactId, req.service
+          ? { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' }
+          : { Authorization: req.headers.authorization || '' });
+        const outcome = await agent.review(reviewFiles, context, model, projectId);
+
+        const review = new Review({
+          projectId,
+          runId,
+          stageId,
+          artifactId,
+          status: 'completed',
+          model: outcome.model,
+          summary: outcome.summary,
+          findings: outcome.findings,
+          counts: outcome.counts,
+          blocking: outcome.blocking,
+          usage: outcome.usage
+        });
+        await review.save();
+
+        res.status(201).json({
+          success: true,
+          data: review
+        });
+      } catch (error) {
+        console.error('Review error:', error);
+
+        const review = await Review.create({
+          projectId,
+          runId,
+          stageId,
+          artifactId,
+          status: 'failed',
+          model: model || process.env.REVIEW_MODEL || 'gpt-4o',
+          error: error instanceof Error ? error.message : 'Review failed'
+        }).catch(() => null);
+
+        res.status(502).json({
+          success: false,
+          error: 'Failed to review code',
+          data: review
+        });
+      }
+    }
+  );
+
+  // GET /api/reviews - Reviews for a project or run
+  router.get('/',
+    [
+      query('projectId').optional().isString().withMessage('Project ID must be a string'),
+      query('runId').optional().isString().withMessage('Run ID must be a string')
+    ],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const { projectId, runId } = req.query as Record<string, string | undefined>;
+        if (!projectId && !runId) {
+          return res.status(400).json({
+            success: false,
+            error: 'projectId or runId is required'
+          });
+        }
+
+        if (projectId && !await canAccessProject(req, projectId)) {
+          return res.status(404).json({
+            success: false,
+            error: 'Project not found'
+          });
+        }
+
+        const filter: any = {};
+        if (projectId) filter.projectId = projectId;
+        if (runId) filter.runId = runId;
+
+        const reviews = await Review.find(filter).sort({ createdAt: -1 }).limit(100);
+
+        // A run's reviews all belong to its project, but only show what the caller may read
+        const readable = new Map<string | undefined, boolean>();
+        for (const review of reviews) {
+          if (!readable.has(review.projectId)) {
+            readable.set(review.projectId, await canAccessProject(req, review.projectId));
+          }
+        }
+
+        res.json({
+          success: true,
+          data: reviews.filter(review => readable.get(review.projectId))
+        });
+      } catch (error) {
+        console.error('Review list error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to list reviews'
+        });
+      }
+    }
+  );
+
+  // GET /api/reviews/:id - Review with findings
+  router.get('/:id',
+    [reviewIdParam],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const review = await Review.findById(req.params.id);
+        if (!review || !await canAccessProject(req, review.projectId)) {
+          return res.status(404).json({
+            success: false,
+            error: 'Review not found'
+          });
+        }
+
+        res.json({
+          success: true,
+          data: review
+        });
+      } catch (error) {
+        console.error('Review fetch error:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to fetch review'
+        });
+      }
+    }
+  );
+
+  // POST /api/reviews/:id/publish - Post findings as pull request review comments
+  router.post('/:id/publish',
+    [
+      reviewIdParam,
+      body('projectId').isMongoId().withMessage('Project ID is required'),
+      body('pullNumber').isInt({ min: 1 }).withMessage('Pull request number is required')
+    ],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const authorization = req.headers.authorization;
+        if (!authorization) {
+          return res.status(401).json({
+            success: false,
+            error: 'Access token required'
+          });
+        }
+
+        const review = await Review.findById(req.params.id);
+        if (!review || review.status !== 'completed' || !await canAccessProject(req, review.projectId)) {
+          return res.status(404).json({
+            success: false,
+            error: 'Completed review not found'
+          });
+        }
+
+        const result = await git.postReview(review, req.body.projectId, req.body.pullNumber, authorization);
+
+        review.pullRequest = {
+          projectId: req.body.projectId,
+          number: req.body.pullNumber,
+          url: result?.url,
+          publishedAt: new Date()
+        };
+        await review.save();
+
+        res.json({
+          success: true,
+          data: review,
+          message: 'Review published to pull request'
+        });
+      } catch (error) {
+        console.error('Review publish error:', error);
+        res.status(502).json({
+          success: false,
+          error: 'Failed to publish review'
+        });
+      }
+    }
+  );
+
+  return router;
+}
@@ -0,0 +1,60 @@
+import axios from 'axios';
+import { IReview } from '../models/Review.js';
+
+const SEVERITY_LABEL: Record<string, string> = {
+  critical: '🔴 Critical',
+  major: '🟠 Major',
+  minor: '🟡 Minor',
+  info: '🔵 Info'
+};
+
+// Markdown summary used as the review body and for findings without a line
+export function formatReviewSummary(review: IReview): string {
+  const counts = ['critical', 'major', 'minor', 'info']
+    .map(severity => `${SEVERITY_LABEL[severity]}: ${review.counts.get(severity) || 0}`)
+    .join(' · ');
+
+  const unanchored = review.findings.filter(f => !f.line);
+  const details = unanchored.map(f => `- **${f.title}** (${SEVERITY_LABEL[f.severity]}, \`${f.file}\`): ${f.description}${f.suggestion ? `\n  _Suggestion:_ ${f.suggestion}` : ''}`);
+
+  return [
+    review.summary,
+    counts,
+    review.blocking ? '**Promotion is blocked until the critical findings are resolved.**' : '',
+    details.length > 0 ? `### Additional findings\n${details.join('\n')}` : ''
+  ].filter(Boolean).join('\n\n');
+}
+
+// Posts reviews through the git integration endpoints in github-service
+export class GitClient {
+  private baseUrl: string;
+
+  constructor(baseUrl?: string) {
+    this.baseUrl = baseUrl || process.env.GITHUB_SERVICE_URL || 'http://localhost:3003';
+  }
+
+  async postReview(review: IReview, projectId: string, pullNumber: number, authorization: string): Promise<{ url?: string }> {
+    const comments = review.findings
+      .filter(f => f.line)
+      .map(f => ({
+        path: f.file,
+        line: f.line,
+        body: `**${SEVERITY_LABEL[f.severity]}: ${f.title}**\n\n${f.description}${f.suggestion ? `\n\n_Suggestion:_ ${f.suggestion}` : ''}`
+      }));
+
+    const response = await axios.post(
+      `${this.baseUrl}/api/git/projects/${projectId}/pulls/${pullNumber}/reviews`,
+      {
+        summary: formatReviewSummary(review),
+        comments,
+        requestChanges: review.blocking
+      },
+      {
+        headers: { Authorization: authorization },
+        timeout: 30000
+      }
+    );
+
+    return response.data.data;
+  }
+}
@@ -0,0 +1,134 @@
+import { LLMClient } from './LLMClient.js';
+import { ChatMessage, FindingSeverity, LLMUsage, ReviewFile, ReviewFinding, ReviewResult } from '../types/index.js';
+
+const SEVERITIES: FindingSeverity[] = ['critical', 'major', 'minor', 'info'];
+const DEFAULT_MAX_REVIEW_CHARS = 120000;
+
+const SYSTEM_PROMPT = `You are a meticulous senior code reviewer. Review the files for bugs, security issues,
+missing error handling and maintainability problems. Only report real, actionable problems.
+
+Respond with JSON only, using this shape:
+{
+  "summary": "two or three sentence overview of the change quality",
+  "findings": [
+    {
+      "severity": "critical | major | minor | info",
+      "file": "path/of/file",
+      "line": 12,
+      "title": "short title",
+      "description": "what is wrong and why it matters",
+      "suggestion": "concrete fix"
+    }
+  ]
+}
+
+Use "critical" only for issues that must block release: security vulnerabilities, data loss, or code that cannot run.`;
+
+export interface ReviewOutcome extends ReviewResult {
+  model: string;
+  usage?: LLMUsage;
+  counts: Record<FindingSeverity, number>;
+  blocking: boolean;
+}
+
+export class ReviewAgent {
+  private model: string;
+  private blockingSeverities: FindingSeverity[];
+
+  constructor(private llm: LLMClient) {
+    this.model = process.env.REVIEW_MODEL || 'gpt-4o';
+    this.blockingSeverities = (process.env.REVIEW_BLOCKING_SEVERITIES || 'critical')
+      .split(',')
+      .map(s => s.trim())
+      .filter((s): s is FindingSeverity => SEVERITIES.includes(s as FindingSeverity));
+  }
+
+  async review(files: ReviewFile[], context?: string, model?: string, projectId?: string): Promise<ReviewOutcome> {
+    const messages: ChatMessage[] = [
+      { role: 'system', content: SYSTEM_PROMPT },
+      { role: 'user', content: this.buildUserPrompt(files, context) }
+    ];
+
+    const completion = await this.llm.chat({
+      model: model || this.model,
+      messages,
+      temperature: 0.1,
+      maxTokens: 4096,
+      metadata: {
+        purpose: 'code-review',
+        ...(projectId ? { projectId } : {})
+      }
+    });
+
+    const result = this.parse(completion.content, files);
+    const counts = SEVERITIES.reduce((acc, severity) => {
+      acc[severity] = result.findings.filter(f => f.severity === severity).length;
+      return acc;
+    }, {} as Record<FindingSeverity, number>);
+
+    return {
+      ...result,
+      model: completion.model,
+      usage: completion.usage,
+      counts,
+      blocking: result.findings.some(f => this.blockingSeverities.includes(f.severity))
+    };
+  }
+
+  // Packs files into the prompt until the character budget runs out
+  private buildUserPrompt(files: ReviewFile[], context?: string): string {
+    const budget = parseInt(process.env.REVIEW_MAX_CHARS || String(DEFAULT_MAX_REVIEW_CHARS));
+    const sections: string[] = [];
+    let used = 0;
+    let skipped = 0;
+
+    for (const file of files) {
+      const section = `### ${file.path}\n\`\`\`\n${this.numberLines(file.content)}\n\`\`\``;
+      if (used + section.length > budget) {
+        skipped++;
+        continue;
+      }
+      sections.push(section);
+      used += section.length;
+    }
+
+    return [
+      context ? `Context:\n${context}` : '',
+      `Files to review (line numbers are prefixed):`,
+      sections.join('\n\n'),
+      skipped > 0 ? `(${skipped} additional files omitted for length)` : ''
+    ].filter(Boolean).join('\n\n');
+  }
+
+  private numberLines(content: string): string {
+    return content.split('\n').map((line, index) => `${index + 1}: ${line}`).join('\n');
+  }
+
+  private parse(raw: string, files: ReviewFile[]): ReviewResult {
+    const start = raw.indexOf('{');
+    const end = raw.lastIndexOf('}');
+    if (start === -1 || end <= start) {
+      throw new Error('Reviewer response did not contain JSON');
+    }
+
+    const parsed = JSON.parse(raw.slice(start, end + 1));
+    const knownPaths = new Set(files.map(f => f.path));
+
+    const findings: ReviewFinding[] = (Array.isArray(parsed.findings) ? parsed.findings : [])
+      .filter((f: any) => f && typeof f.file === 'string' && typeof f.title === 'string')
+      .map((f: any) => ({
+        severity: SEVERITIES.includes(f.severity) ? f.severity : 'info',
+        file: f.file,
+        // Drop line numbers for files we never showed the model
+        line: knownPaths.has(f.file) && Number.isInteger(f.line) && f.line > 0 ? f.line : undefined,
+        title: f.title,
+        description: typeof f.description === 'string' ? f.description : '',
+        suggestion: typeof f.suggestion === 'string' ? f.suggestion : undefined
+      }));
+
+    return {
+      summary: typeof parsed.summary === 'string' ? parsed.summary : '',
+      findings
+    };
+  }
+}
@@ -0,0 +1,20 @@
+import axios from 'axios';
+import { ReviewFile } from '../types/index.js';
+
+// Code bundles in artifact-service are JSON: either a files array or { files: [...] }. The credentials
+// are the reviewer's own (their Authorization header, or the service token for platform callers), so
+// artifact-service only hands out bundles the reviewer may read.
+export async function loadArtifactFiles(artifactId: string, credentials: Record<string, string>): Promise<ReviewFile[]> {
+  const artifactServiceUrl = process.env.ARTIFACT_SERVICE_URL || 'http://localhost:3008';
+  const { data: link } = await axios.get(`${artifactServiceUrl}/api/artifacts/${artifactId}/download-url`, {
+    headers: credentials,
+    timeout: 10000
+  });
+  const { data: bundle } = await axios.get(link.data.url, { timeout: 60000 });
+
+  const files = Array.isArray(bundle) ? bundle : bundle?.files;
+  if (!Array.isArray(files)) {
+    throw new Error('Artifact is not a code bundle');
+  }
+  return files.map((file: any) => ({ path: file.path, content: file.content }));
+}
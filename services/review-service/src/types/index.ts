@@ -0,0 +1,50 @@
+// Review Service types
+export type FindingSeverity = 'critical' | 'major' | 'minor' | 'info';
+
+export interface ReviewFinding {
+  severity: FindingSeverity;
+  file: string;
+  line?: number;
+  title: string;
+  description: string;
+  suggestion?: string;
+}
+
+export interface ReviewFile {
+  path: string;
+  content: string;
+}
+
+export interface ReviewResult {
+  summary: string;
+  findings: ReviewFinding[];
+}
+
+export interface ChatMessage {
+  role: 'system' | 'user' | 'assistant';
+  content: string;
+}
+
+export interface LLMUsage {
+  promptTokens: number;
+  completionTokens: number;
+  totalTokens: number;
+  costUsd?: number;
+}
+
+export interface ChatCompletionRequest {
+  model: string;
+  messages: ChatMessage[];
+  temperature?: number;
+  maxTokens?: number;
+  metadata?: Record<string, string>;
+}
+
+export interface ChatCompletionResponse {
+  id: string;
+  provider: string;
+  model: string;
+  content: string;
+  finishReason: string;
+  usage: LLMUsage;
+}
@@ -0,0 +1,68 @@
+import mongoose, { Schema, Document } from 'mongoose';
+import { SecretFinding } from '../services/SecretScanner.js';
+
+export type ScanMode = 'fail' | 'quarantine';
+
+export interface ISecretScan extends Document {
+  projectId?: string;
+  runId?: string;
+  stageId?: string;
+  artifactId?: string;
+  mode: ScanMode;
+  status: 'clean' | 'failed' | 'quarantined';
+  filesScanned: number;
+  findings: SecretFinding[];
+  // Files held back from later stages in quarantine mode
+  quarantinedFiles: string[];
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const FindingSchema = new Schema({
+  file: { type: String, required: true },
+  line: { type: Number, required: true },
+  column: { type: Number, required: true },
+  rule: { type: String, required: true },
+  description: { type: String, required: true },
+  severity: {
+    type: String,
+    enum: ['critical', 'high', 'medium'],
+    required: true
+  },
+  redacted: { type: String, required: true },
+  entropy: { type: Number },
+  fingerprint: { type: String, required: true }
+}, { _id: false });
+
+const SecretScanSchema: Schema = new Schema({
+  projectId: {
+    type: String,
+    index: true
+  },
+  runId: {
+    type: String,
+    index: true
+  },
+  stageId: { type: String },
+  artifactId: { type: String },
+  mode: {
+    type: String,
+    enum: ['fail', 'quarantine'],
+    default: 'fail'
+  },
+  status: {
+    type: String,
+    enum: ['clean', 'failed', 'quarantined'],
+    required: true
+  },
+  filesScanned: {
+    type: Number,
+    default: 0
+  },
+  findings: [FindingSchema],
+  quarantinedFiles: [{ type: String }]
+}, {
+  timestamps: true
+});
+
+export const SecretScan = mongoose.model<ISecretScan>('SecretScan', SecretScanSchema);
@@ -0,0 +1,167 @@
+import express, { Request, Response } from 'express';
+import { body, param, query, validationResult } from 'express-validator';
+import { SecretScan } from '../models/SecretScan.js';
+import { SecretScanner, ScannedFile, SecretSeverity, SEVERITY_ORDER } from '../services/SecretScanner.js';
+import { ArtifactClient } from '../services/ArtifactClient.js';
+import { requireAuth, requireServiceToken } from '../middleware/auth.js';
+
+const router = express.Router();
+const scanner = new SecretScanner();
+const artifacts = new ArtifactClient();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const DEFAULT_FAIL_ON = (process.env.SECRET_SCAN_FAIL_ON || 'high') as SecretSeverity;
+
+// POST /api/secret-scans - Scan files or a text artifact; leaks fail the scan or quarantine the files
+// (internal; scans can quarantine any artifact, so only the orchestrator may start them)
+router.post('/',
+  requireServiceToken,
+  [
+    body('files').optional().isArray().withMessage('Files must be an array'),
+    body('files.*.path').optional().isString().withMessage('Each file needs a path'),
+    body('files.*.content').optional().isString().withMessage('Each file needs content'),
+    body('artifactId').optional().isMongoId().withMessage('Invalid artifact ID'),
+    body('mode').optional().isIn(['fail', 'quarantine']).withMessage('Mode must be fail or quarantine'),
+    body('failOn').optional().isIn(SEVERITY_ORDER).withMessage('failOn must be medium, high or critical')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const { runId, stageId, projectId, artifactId } = req.body;
+      const mode = req.body.mode || 'fail';
+      const threshold = SEVERITY_ORDER.indexOf(req.body.failOn || DEFAULT_FAIL_ON);
+
+      let files: ScannedFile[] = req.body.files || [];
+      if (files.length === 0 && artifactId) {
+        if (!artifacts.isConfigured()) {
+          return res.status(400).json({
+            success: false,
+            error: 'Artifact scanning requires ARTIFACT_SERVICE_URL'
+          });
+        }
+        files = [await artifacts.fetchText(artifactId)];
+      }
+      if (files.length === 0) {
+        return res.status(400).json({
+          success: false,
+          error: 'Provide files or an artifactId to scan'
+        });
+      }
+
+      const findings = scanner.scan(files);
+      // Findings below the threshold are reported but do not block
+      const blocking = findings.filter(finding => SEVERITY_ORDER.indexOf(finding.severity) >= threshold);
+      const leakedFiles = Array.from(new Set(blocking.map(finding => finding.file)));
+
+      const scan = new SecretScan({
+        projectId,
+        runId,
+        stageId,
+        artifactId,
+        mode,
+        status: blocking.length === 0 ? 'clean' : mode === 'quarantine' ? 'quarantined' : 'failed',
+        filesScanned: files.length,
+        findings,
+        quarantinedFiles: mode === 'quarantine' ? leakedFiles : []
+      });
+      await scan.save();
+
+      if (artifactId && blocking.length > 0 && artifacts.isConfigured()) {
+        await artifacts.quarantine(artifactId, `${blocking.length} leaked credential(s) found by secret scan`, scan.id);
+      }
+
+      res.status(201).json({
+        success: true,
+        data: {
+          ...scan.toJSON(),
+          blocking: blocking.length,
+          // In quarantine mode later stages continue with the files that are clean
+          cleanFiles: mode === 'quarantine'
+            ? files.filter(file => !leakedFiles.includes(file.path))
+            : undefined
+        }
+      });
+    } catch (error) {
+      console.error('Error running secret scan:', error);
+      res.status(500).json({
+        success: false,
+        error: error instanceof Error ? error.message : 'Failed to run secret scan'
+      });
+    }
+  }
+);
+
+// GET /api/secret-scans - Scan history for a project or run
+router.get('/',
+  requireAuth,
+  [
+    query('projectId').optional().isString().withMessage('Project ID must be a string'),
+    query('runId').optional().isString().withMessage('Run ID must be a string'),
+    query('status').optional().isIn(['clean', 'failed', 'quarantined']).withMessage('Status must be clean, failed or quarantined')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const filter: any = {};
+      if (req.query.projectId) filter.projectId = req.query.projectId;
+      if (req.query.runId) filter.runId = req.query.runId;
+      if (req.query.status) filter.status = req.query.status;
+
+      const scans = await SecretScan.find(filter).sort({ createdAt: -1 }).limit(100);
+
+      res.json({
+        success: true,
+        data: scans
+      });
+    } catch (error) {
+      console.error('Error fetching secret scans:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch secret scans'
+      });
+    }
+  }
+);
+
+// GET /api/secret-scans/:id - Scan with its findings
+router.get('/:id',
+  requireAuth,
+  [param('id').isMongoId().withMessage('Invalid scan ID')],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const scan = await SecretScan.findById(req.params.id);
+      if (!scan) {
+        return res.status(404).json({
+          success: false,
+          error: 'Scan not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: scan
+      });
+    } catch (error) {
+      console.error('Error fetching secret scan:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch secret scan'
+      });
+    }
+  }
+);
+
+export default router;
@@ -0,0 +1,47 @@
+import axios from 'axios';
+import { ScannedFile } from './SecretScanner.js';
+
+const TEXT_CONTENT_TYPES = /^(text\/|application\/(json|xml|yaml|x-yaml|javascript|typescript))/;
+
+// Reads stored artifacts for scanning and quarantines the ones that leak credentials
+export class ArtifactClient {
+  private baseUrl: string | undefined;
+
+  constructor(baseUrl?: string) {
+    this.baseUrl = baseUrl || process.env.ARTIFACT_SERVICE_URL;
+  }
+
+  isConfigured(): boolean {
+    return !!this.baseUrl;
+  }
+
+  // Only text artifacts can be scanned; archives have to be scanned as files before upload
+  async fetchText(artifactId: string): Promise<ScannedFile> {
+    const { data: meta } = await axios.get(`${this.baseUrl}/api/artifacts/${artifactId}`, {
+      headers: this.headers(),
+      timeout: 5000
+    });
+    if (!TEXT_CONTENT_TYPES.test(meta.data.contentType)) {
+      throw new Error(`Artifact ${artifactId} is ${meta.data.contentType}; only text artifacts can be scanned`);
+    }
+
+    const { data: link } = await axios.get(`${this.baseUrl}/api/artifacts/${artifactId}/download-url`, {
+      headers: this.headers(),
+      timeout: 5000
+    });
+    const { data: content } = await axios.get(link.data.url, { responseType: 'text', timeout: 30000 });
+
+    return { path: meta.data.name, content };
+  }
+
+  async quarantine(artifactId: string, reason: string, scanId: string): Promise<void> {
+    await axios.put(`${this.baseUrl}/api/artifacts/${artifactId}/quarantine`, { reason, scanId }, {
+      headers: this.headers(),
+      timeout: 5000
+    });
+  }
+
+  private headers() {
+    return { 'X-Service-Token': process.env.INTERNAL_SERVICE_TOKEN || '' };
+  }
+}
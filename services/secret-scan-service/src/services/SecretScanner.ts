@@ -0,0 +1,180 @@
+import { createHash } from 'crypto';
+
+export type SecretSeverity = 'critical' | 'high' | 'medium';
+
+export interface ScannedFile {
+  path: string;
+  content: string;
+}
+
+export interface SecretFinding {
+  file: string;
+  line: number;
+  column: number;
+  rule: string;
+  description: string;
+  severity: SecretSeverity;
+  // The matched value with everything but its first and last characters masked
+  redacted: string;
+  entropy?: number;
+  // Stable hash of the secret so the same leak can be tracked across runs without storing it
+  fingerprint: string;
+}
+
+export interface SecretRule {
+  id: string;
+  description: string;
+  pattern: RegExp;
+  severity: SecretSeverity;
+  // Capture group holding the secret itself; the whole match when omitted
+  group?: number;
+  // Only report when the secret also looks random, for rules that would otherwise be noisy
+  minEntropy?: number;
+}
+
+export const SEVERITY_ORDER: SecretSeverity[] = ['medium', 'high', 'critical'];
+
+const BUILTIN_RULES: SecretRule[] = [
+  { id: 'private-key', description: 'Private key block', pattern: /-----BEGIN (?:[A-Z]+ )*PRIVATE KEY-----/g, severity: 'critical' },
+  { id: 'aws-access-key', description: 'AWS access key ID', pattern: /\b(?:AKIA|ASIA)[0-9A-Z]{16}\b/g, severity: 'critical' },
+  {
+    id: 'aws-secret-key',
+    description: 'AWS secret access key',
+    pattern: /aws.{0,20}(?:secret|private).{0,20}["'`]([0-9a-zA-Z/+]{40})["'`]/gi,
+    severity: 'critical',
+    group: 1
+  },
+  { id: 'github-token', description: 'GitHub token', pattern: /\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})/g, severity: 'critical' },
+  { id: 'gitlab-token', description: 'GitLab personal access token', pattern: /\bglpat-[A-Za-z0-9_-]{20,}/g, severity: 'critical' },
+  { id: 'anthropic-key', description: 'Anthropic API key', pattern: /\bsk-ant-[A-Za-z0-9_-]{32,}/g, severity: 'critical' },
+  { id: 'openai-key', description: 'OpenAI API key', pattern: /\bsk-(?!ant-)(?:proj-)?[A-Za-z0-9_-]{32,}/g, severity: 'critical' },
+  { id: 'google-api-key', description: 'Google API key', pattern: /\bAIza[0-9A-Za-z_-]{35}\b/g, severity: 'high' },
+  { id: 'slack-token', description: 'Slack token', pattern: /\bxox[abprs]-[A-Za-z0-9-]{10,}/g, severity: 'high' },
+  { id: 'slack-webhook', description: 'Slack webhook URL', pattern: /https:\/\/hooks\.slack\.com\/services\/[A-Za-z0-9/]{20,}/g, severity: 'high' },
+  { id: 'stripe-key', description: 'Stripe live key', pattern: /\b(?:sk|rk)_live_[A-Za-z0-9]{24,}/g, severity: 'critical' },
+  { id: 'jwt', description: 'JSON Web Token', pattern: /\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}/g, severity: 'medium' },
+  {
+    id: 'connection-string-password',
+    description: 'Password embedded in a connection string',
+    pattern: /\b[a-z][a-z0-9+.-]*:\/\/[^\s:@/'"`]+:([^\s@/'"`]{3,})@/gi,
+    severity: 'high',
+    group: 1
+  },
+  {
+    id: 'generic-secret',
+    description: 'Hard-coded secret assignment',
+    pattern: /(?:secret|passw(?:or)?d|api[_-]?key|access[_-]?token|auth[_-]?token|client[_-]?secret)["'`]?\s*[:=]\s*["'`]([^"'`\s]{8,})["'`]/gi,
+    severity: 'high',
+    group: 1,
+    minEntropy: 3.0
+  },
+  {
+    id: 'high-entropy-string',
+    description: 'High-entropy string literal',
+    pattern: /["'`]([A-Za-z0-9+/_=-]{32,})["'`]/g,
+    severity: 'medium',
+    group: 1,
+    minEntropy: 4.5
+  }
+];
+
+// Values that are obviously not real credentials
+const PLACEHOLDER = /^(?:x+|\*+|\.+|changeme|change-me|password|secret|example|dummy|test|todo|redacted|your[-_].*|<.*>|\$\{.*\}|\{\{.*\}\}|process\.env\..*)$/i;
+const PLACEHOLDER_HINT = /example|placeholder|dummy|sample|fake|change[-_]?(?:me|this)|in[-_]production/i;
+
+// Lock files are full of hashes that look random but are not secrets
+const DEFAULT_IGNORED_PATHS = ['package-lock.json', 'yarn.lock', 'pnpm-lock.yaml', 'go.sum', 'Cargo.lock', 'poetry.lock'];
+
+// Inline opt-out for deliberate test fixtures
+const ALLOW_MARKER = 'secret-scan:allow';
+
+export function shannonEntropy(value: string): number {
+  const counts = new Map<string, number>();
+  for (const char of value) counts.set(char, (counts.get(char) || 0) + 1);
+
+  let entropy = 0;
+  for (const count of counts.values()) {
+    const p = count / value.length;
+    entropy -= p * Math.log2(p);
+  }
+  return entropy;
+}
+
+const redact = (secret: string) =>
+  secret.length <= 8 ? '*'.repeat(secret.length) : `${secret.slice(0, 4)}${'*'.repeat(Math.min(secret.length - 6, 24))}${secret.slice(-2)}`;
+
+// Custom rules (e.g. the formats of keys issued by this platform) come from SECRET_SCAN_RULES as
+// JSON: [{ "id": "...", "description": "...", "pattern": "...", "severity": "high" }]
+function loadCustomRules(json: string | undefined): SecretRule[] {
+  if (!json) return [];
+
+  const parsed = JSON.parse(json) as Array<Omit<SecretRule, 'pattern'> & { pattern: string }>;
+  return parsed.map(rule => ({ ...rule, pattern: new RegExp(rule.pattern, 'g') }));
+}
+
+// Finds leaked credentials with provider-specific patterns plus an entropy check for generic
+// secrets, skipping lock files, placeholders and lines marked secret-scan:allow
+export class SecretScanner {
+  private rules: SecretRule[];
+  private ignoredPaths: string[];
+
+  constructor(
+    customRules: string | undefined = process.env.SECRET_SCAN_RULES,
+    ignoredPaths: string | undefined = process.env.SECRET_SCAN_IGNORE_PATHS
+  ) {
+    this.rules = [...loadCustomRules(customRules), ...BUILTIN_RULES];
+    this.ignoredPaths = ignoredPaths ? ignoredPaths.split(',').map(entry => entry.trim()) : DEFAULT_IGNORED_PATHS;
+  }
+
+  scan(files: ScannedFile[]): SecretFinding[] {
+    return files
+      .filter(file => !this.isIgnored(file.path))
+      .flatMap(file => this.scanFile(file));
+  }
+
+  private isIgnored(path: string): boolean {
+    const name = path.split('/').pop() || path;
+    return this.ignoredPaths.includes(name) || this.ignoredPaths.includes(path);
+  }
+
+  private scanFile(file: ScannedFile): SecretFinding[] {
+    const findings: SecretFinding[] = [];
+
+    file.content.split('\n').forEach((text, index) => {
+      if (text.includes(ALLOW_MARKER)) return;
+
+      // Earlier (more specific) rules claim their span so generic rules don't report it again
+      const claimed: Array<[number, number]> = [];
+
+      for (const rule of this.rules) {
+        for (const match of text.matchAll(rule.pattern)) {
+          const secret = rule.group ? match[rule.group] : match[0];
+          if (!secret) continue;
+
+          const start = (match.index || 0) + (rule.group ? match[0].indexOf(secret) : 0);
+          const end = start + secret.length;
+          if (claimed.some(([from, to]) => start < to && end > from)) continue;
+          if (PLACEHOLDER.test(secret) || PLACEHOLDER_HINT.test(secret)) continue;
+
+          const entropy = rule.minEntropy !== undefined ? shannonEntropy(secret) : undefined;
+          if (entropy !== undefined && entropy < rule.minEntropy!) continue;
+
+          claimed.push([start, end]);
+          findings.push({
+            file: file.path,
+            line: index + 1,
+            column: start + 1,
+            rule: rule.id,
+            description: rule.description,
+            severity: rule.severity,
+            redacted: redact(secret),
+            ...(entropy !== undefined ? { entropy: Math.round(entropy * 100) / 100 } : {}),
+            fingerprint: createHash('sha256').update(`${rule.id}:${secret}`).digest('hex').slice(0, 16)
+          });
+        }
+      }
+    });
+
+    return findings;
+  }
+}
@@ -0,0 +1,85 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export interface IStageMetric extends Document {
+  runId: string;
+  stageId: string;
+  stageName?: string;
+  pipeline?: string;
+  projectId?: string;
+  service: string;
+  agentRole?: string;
+  model?: string;
+  status: 'completed' | 'failed';
+  durationMs: number;
+  promptTokens: number;
+  completionTokens: number;
+  costUsd: number;
+  retries: number;
+  failureReason?: string;
+  failureCategory?: string;
+  startedAt: Date;
+  createdAt: Date;
+}
+
+const StageMetricSchema: Schema = new Schema({
+  runId: {
+    type: String,
+    required: true,
+    index: true
+  },
+  stageId: {
+    type: String,
+    required: true
+  },
+  stageName: { type: String },
+  // Pipeline definition the run came from, e.g. software-delivery
+  pipeline: { type: String },
+  projectId: { type: String },
+  // Service that reported the metric
+  service: {
+    type: String,
+    required: true
+  },
+  agentRole: { type: String },
+  model: { type: String },
+  status: {
+    type: String,
+    enum: ['completed', 'failed'],
+    required: true
+  },
+  durationMs: {
+    type: Number,
+    required: true,
+    min: 0
+  },
+  promptTokens: {
+    type: Number,
+    default: 0
+  },
+  completionTokens: {
+    type: Number,
+    default: 0
+  },
+  costUsd: {
+    type: Number,
+    default: 0
+  },
+  retries: {
+    type: Number,
+    default: 0
+  },
+  failureReason: { type: String },
+  // failureReason with IDs and numbers stripped, so similar failures group together
+  failureCategory: { type: String },
+  startedAt: {
+    type: Date,
+    default: Date.now
+  }
+}, {
+  timestamps: { createdAt: true, updatedAt: false }
+});
+
+StageMetricSchema.index({ stageId: 1, startedAt: -1 });
+StageMetricSchema.index({ pipeline: 1, startedAt: -1 });
+
+export const StageMetric = mongoose.model<IStageMetric>('StageMetric', StageMetricSchema);
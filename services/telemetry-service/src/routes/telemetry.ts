@@ -0,0 +1,203 @@
+import express, { Request, Response } from 'express';
+import { body, param, query, validationResult } from 'express-validator';
+import { StageMetric } from '../models/StageMetric.js';
+import { StageAggregator, MetricFilter, failureCategory } from '../services/StageAggregator.js';
+import { requireAuth } from '../middleware/auth.js';
+
+const router = express.Router();
+const aggregator = new StageAggregator();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const filterQuery = [
+  query('since').optional().isISO8601().withMessage('since must be an ISO 8601 date'),
+  query('pipeline').optional().isString().withMessage('Pipeline must be a string'),
+  query('projectId').optional().isString().withMessage('Project ID must be a string'),
+  query('limit').optional().isInt({ min: 1, max: 100 }).withMessage('Limit must be between 1 and 100')
+];
+
+const toFilter = (req: Request): MetricFilter => ({
+  since: req.query.since ? new Date(req.query.since as string) : undefined,
+  pipeline: req.query.pipeline as string | undefined,
+  projectId: req.query.projectId as string | undefined
+});
+
+// POST /api/telemetry/metrics/stages - Report one stage execution (internal)
+router.post('/metrics/stages',
+  [
+    body('runId').isString().isLength({ min: 1 }).withMessage('Run ID is required'),
+    body('stageId').isString().isLength({ min: 1 }).withMessage('Stage ID is required'),
+    body('service').isString().isLength({ min: 1 }).withMessage('Reporting service is required'),
+    body('status').isIn(['completed', 'failed']).withMessage('Status must be completed or failed'),
+    body('durationMs').isFloat({ min: 0 }).withMessage('Duration must be a non-negative number'),
+    body('promptTokens').optional().isInt({ min: 0 }).withMessage('Prompt tokens must be a non-negative integer'),
+    body('completionTokens').optional().isInt({ min: 0 }).withMessage('Completion tokens must be a non-negative integer'),
+    body('costUsd').optional().isFloat({ min: 0 }).withMessage('Cost must be a non-negative number'),
+    body('retries').optional().isInt({ min: 0 }).withMessage('Retries must be a non-negative integer'),
+    body('startedAt').optional().isISO8601().withMessage('startedAt must be an ISO 8601 date')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const metric = await StageMetric.create({
+        runId: req.body.runId,
+        stageId: req.body.stageId,
+        stageName: req.body.stageName,
+        pipeline: req.body.pipeline,
+        projectId: req.body.projectId,
+        service: req.body.service,
+        agentRole: req.body.agentRole,
+        model: req.body.model,
+        status: req.body.status,
+        durationMs: req.body.durationMs,
+        promptTokens: req.body.promptTokens,
+        completionTokens: req.body.completionTokens,
+        costUsd: req.body.costUsd,
+        retries: req.body.retries,
+        failureReason: req.body.failureReason,
+        failureCategory: req.body.failureReason ? failureCategory(req.body.failureReason) : undefined,
+        startedAt: req.body.startedAt
+      });
+
+      res.status(201).json({
+        success: true,
+        data: { id: metric._id }
+      });
+    } catch (error) {
+      console.error('Error recording stage metric:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to record stage metric'
+      });
+    }
+  }
+);
+
+// GET /api/telemetry/stages - Duration percentiles, failure rate, tokens, cost and retries per stage
+router.get('/stages',
+  requireAuth,
+  filterQuery,
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      res.json({
+        success: true,
+        data: await aggregator.stageSummaries(toFilter(req))
+      });
+    } catch (error) {
+      console.error('Error aggregating stage metrics:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to aggregate stage metrics'
+      });
+    }
+  }
+);
+
+// GET /api/telemetry/stages/slowest - Stages ranked by p95 duration
+router.get('/stages/slowest',
+  requireAuth,
+  filterQuery,
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      res.json({
+        success: true,
+        data: await aggregator.slowestStages(toFilter(req), parseInt(req.query.limit as string) || 10)
+      });
+    } catch (error) {
+      console.error('Error ranking slowest stages:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to rank slowest stages'
+      });
+    }
+  }
+);
+
+// GET /api/telemetry/failures - Most frequent failure reasons, optionally for one stage
+router.get('/failures',
+  requireAuth,
+  [...filterQuery, query('stageId').optional().isString().withMessage('Stage ID must be a string')],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const filter = { ...toFilter(req), stageId: req.query.stageId as string | undefined };
+
+      res.json({
+        success: true,
+        data: await aggregator.failures(filter, parseInt(req.query.limit as string) || 20)
+      });
+    } catch (error) {
+      console.error('Error aggregating failures:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to aggregate failures'
+      });
+    }
+  }
+);
+
+// GET /api/telemetry/models - Token usage and cost per model
+router.get('/models',
+  requireAuth,
+  filterQuery,
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      res.json({
+        success: true,
+        data: await aggregator.modelUsage(toFilter(req))
+      });
+    } catch (error) {
+      console.error('Error aggregating model usage:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to aggregate model usage'
+      });
+    }
+  }
+);
+
+// GET /api/telemetry/runs/:runId - Every stage metric recorded for a run
+router.get('/runs/:runId',
+  requireAuth,
+  [param('runId').isString().isLength({ min: 1, max: 200 }).withMessage('Invalid run ID')],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const metrics = await StageMetric.find({ runId: req.params.runId }).sort({ startedAt: 1 });
+
+      if (metrics.length === 0) {
+        return res.status(404).json({
+          success: false,
+          error: 'No metrics recorded for this run'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: metrics
+      });
+    } catch (error) {
+      console.error('Error fetching run metrics:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch run metrics'
+      });
+    }
+  }
+);
+
+export default router;
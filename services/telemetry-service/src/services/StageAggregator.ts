@@ -0,0 +1,161 @@
+import { StageMetric } from '../models/StageMetric.js';
+
+export interface MetricFilter {
+  since?: Date;
+  pipeline?: string;
+  projectId?: string;
+  stageId?: string;
+}
+
+export interface StageSummary {
+  stageId: string;
+  stageName?: string;
+  runs: number;
+  failures: number;
+  failureRate: number;
+  durationMs: { avg: number; p50: number; p95: number; max: number };
+  avgTokens: number;
+  totalCostUsd: number;
+  avgRetries: number;
+  topFailures: Array<{ category: string; count: number }>;
+}
+
+const DEFAULT_WINDOW_MS = 7 * 24 * 60 * 60 * 1000;
+
+// Groups "Agent stage failed: timeout of 600000ms exceeded" and friends under one key
+export function failureCategory(reason: string): string {
+  return reason
+    .replace(/[0-9a-f]{24}|[0-9a-f-]{36}/gi, '<id>')
+    .replace(/\d+(\.\d+)?/g, '#')
+    .slice(0, 160);
+}
+
+const percentile = (sorted: number[], p: number): number =>
+  sorted.length === 0 ? 0 : sorted[Math.min(sorted.length - 1, Math.ceil(p * sorted.length) - 1)];
+
+const round = (value: number, digits = 2) => Math.round(value * 10 ** digits) / 10 ** digits;
+
+// Aggregates stage metrics for the dashboard endpoints. Percentiles are computed here rather than
+// in MongoDB so older server versions work; the default 7-day window keeps the result sets small.
+export class StageAggregator {
+  private match(filter: MetricFilter): Record<string, any> {
+    const match: Record<string, any> = {
+      startedAt: { $gte: filter.since || new Date(Date.now() - DEFAULT_WINDOW_MS) }
+    };
+    if (filter.pipeline) match.pipeline = filter.pipeline;
+    if (filter.projectId) match.projectId = filter.projectId;
+    if (filter.stageId) match.stageId = filter.stageId;
+    return match;
+  }
+
+  async stageSummaries(filter: MetricFilter = {}): Promise<StageSummary[]> {
+    const groups = await StageMetric.aggregate([
+      { $match: this.match(filter) },
+      {
+        $group: {
+          _id: '$stageId',
+          stageName: { $last: '$stageName' },
+          runs: { $sum: 1 },
+          failures: { $sum: { $cond: [{ $eq: ['$status', 'failed'] }, 1, 0] } },
+          durations: { $push: '$durationMs' },
+          tokens: { $avg: { $add: ['$promptTokens', '$completionTokens'] } },
+          cost: { $sum: '$costUsd' },
+          retries: { $avg: '$retries' },
+          failureCategories: { $push: '$failureCategory' }
+        }
+      }
+    ]);
+
+    return groups.map(group => {
+      const durations = (group.durations as number[]).sort((a, b) => a - b);
+      const counts = new Map<string, number>();
+      for (const category of group.failureCategories as Array<string | null>) {
+        if (category) counts.set(category, (counts.get(category) || 0) + 1);
+      }
+
+      return {
+        stageId: group._id,
+        stageName: group.stageName,
+        runs: group.runs,
+        failures: group.failures,
+        failureRate: round(group.failures / group.runs, 4),
+        durationMs: {
+          avg: Math.round(durations.reduce((sum, value) => sum + value, 0) / durations.length),
+          p50: percentile(durations, 0.5),
+          p95: percentile(durations, 0.95),
+          max: durations[durations.length - 1]
+        },
+        avgTokens: Math.round(group.tokens || 0),
+        totalCostUsd: round(group.cost || 0, 4),
+        avgRetries: round(group.retries || 0),
+        topFailures: Array.from(counts.entries())
+          .map(([category, count]) => ({ category, count }))
+          .sort((a, b) => b.count - a.count)
+          .slice(0, 5)
+      };
+    });
+  }
+
+  // Slowest stages by p95 duration, each with what most often goes wrong there
+  async slowestStages(filter: MetricFilter = {}, limit = 10): Promise<StageSummary[]> {
+    const summaries = await this.stageSummaries(filter);
+    return summaries.sort((a, b) => b.durationMs.p95 - a.durationMs.p95).slice(0, limit);
+  }
+
+  async failures(filter: MetricFilter = {}, limit = 20): Promise<any[]> {
+    return StageMetric.aggregate([
+      { $match: { ...this.match(filter), status: 'failed' } },
+      { $sort: { startedAt: -1 } },
+      {
+        $group: {
+          _id: { stageId: '$stageId', category: '$failureCategory' },
+          count: { $sum: 1 },
+          lastSeen: { $first: '$startedAt' },
+          example: { $first: '$failureReason' },
+          exampleRunId: { $first: '$runId' }
+        }
+      },
+      { $sort: { count: -1 } },
+      { $limit: limit },
+      {
+        $project: {
+          _id: 0,
+          stageId: '$_id.stageId',
+          category: '$_id.category',
+          count: 1,
+          lastSeen: 1,
+          example: 1,
+          exampleRunId: 1
+        }
+      }
+    ]);
+  }
+
+  async modelUsage(filter: MetricFilter = {}): Promise<any[]> {
+    return StageMetric.aggregate([
+      { $match: { ...this.match(filter), model: { $exists: true } } },
+      {
+        $group: {
+          _id: '$model',
+          stages: { $sum: 1 },
+          promptTokens: { $sum: '$promptTokens' },
+          completionTokens: { $sum: '$completionTokens' },
+          costUsd: { $sum: '$costUsd' },
+          avgDurationMs: { $avg: '$durationMs' }
+        }
+      },
+      { $sort: { costUsd: -1 } },
+      {
+        $project: {
+          _id: 0,
+          model: '$_id',
+          stages: 1,
+          promptTokens: 1,
+          completionTokens: 1,
+          costUsd: { $round: ['$costUsd', 4] },
+          avgDurationMs: { $round: ['$avgDurationMs', 0] }
+        }
+      }
+    ]);
+  }
+}
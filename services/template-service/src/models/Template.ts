@@ -0,0 +1,59 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export interface ITemplate extends Document {
+  name: string;
+  displayName: string;
+  description: string;
+  tags: string[];
+  stack: string[];
+  latestVersion: number;
+  ownerId: string;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const TemplateSchema: Schema = new Schema({
+  name: {
+    type: String,
+    required: true,
+    unique: true,
+    trim: true,
+    maxlength: 100,
+    match: /^[a-z0-9][a-z0-9._-]*$/
+  },
+  displayName: {
+    type: String,
+    required: true,
+    trim: true,
+    maxlength: 200
+  },
+  description: {
+    type: String,
+    default: '',
+    maxlength: 2000
+  },
+  tags: [{
+    type: String,
+    trim: true
+  }],
+  // Technologies in the skeleton, e.g. react, go, postgres
+  stack: [{
+    type: String,
+    trim: true
+  }],
+  latestVersion: {
+    type: Number,
+    default: 0
+  },
+  // Built-in catalog entries are owned by "system"
+  ownerId: {
+    type: String,
+    required: true
+  }
+}, {
+  timestamps: true
+});
+
+TemplateSchema.index({ tags: 1 });
+
+export const Template = mongoose.model<ITemplate>('Template', TemplateSchema);
@@ -0,0 +1,68 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export interface ITemplateParameter {
+  name: string;
+  description?: string;
+  type: 'string' | 'boolean' | 'number';
+  required: boolean;
+  defaultValue?: any;
+  options?: string[];
+}
+
+export interface ITemplateFile {
+  path: string;
+  content: string;
+  // Parameter name (or !name) that must be truthy for the file to be rendered
+  when?: string;
+}
+
+export interface ITemplateVersion extends Document {
+  templateName: string;
+  version: number;
+  parameters: ITemplateParameter[];
+  files: ITemplateFile[];
+  changelog: string;
+  createdBy: string;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const TemplateVersionSchema: Schema = new Schema({
+  templateName: {
+    type: String,
+    required: true,
+    index: true
+  },
+  version: {
+    type: Number,
+    required: true
+  },
+  parameters: [{
+    name: { type: String, required: true },
+    description: String,
+    type: { type: String, enum: ['string', 'boolean', 'number'], default: 'string' },
+    required: { type: Boolean, default: false },
+    defaultValue: Schema.Types.Mixed,
+    options: [String]
+  }],
+  files: [{
+    path: { type: String, required: true },
+    content: { type: String, default: '' },
+    when: String
+  }],
+  changelog: {
+    type: String,
+    default: '',
+    maxlength: 5000
+  },
+  createdBy: {
+    type: String,
+    required: true
+  }
+}, {
+  timestamps: true
+});
+
+TemplateVersionSchema.index({ templateName: 1, version: -1 }, { unique: true });
+
+export const TemplateVersion = mongoose.model<ITemplateVersion>('TemplateVersion', TemplateVersionSchema);
@@ -0,0 +1,316 @@
+import express, { Request, Response } from 'express';
+import { body, param, query, validationResult } from 'express-validator';
+import { Template } from '../models/Template.js';
+import { TemplateVersion } from '../models/TemplateVersion.js';
+import { requireAuth, AuthenticatedRequest } from '../middleware/auth.js';
+import { renderFiles, undeclaredPlaceholders, InvalidParametersError } from '../services/TemplateRenderer.js';
+
+const router = express.Router();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const nameParam = param('name').matches(/^[a-z0-9][a-z0-9._-]*$/).withMessage('Invalid template name');
+const versionParam = param('version').isInt({ min: 1 }).withMessage('Version must be a positive integer');
+
+// POST /api/templates - Register a template in the catalog
+router.post('/',
+  requireAuth,
+  [
+    body('name').matches(/^[a-z0-9][a-z0-9._-]*$/).isLength({ max: 100 }).withMessage('Name must be lowercase letters, digits, dots, dashes or underscores'),
+    body('displayName').trim().isLength({ min: 1, max: 200 }).withMessage('Display name is required'),
+    body('description').optional().isString().isLength({ max: 2000 }).withMessage('Description must be less than 2000 characters'),
+    body('tags').optional().isArray().withMessage('Tags must be an array'),
+    body('stack').optional().isArray().withMessage('Stack must be an array')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const existing = await Template.findOne({ name: req.body.name });
+      if (existing) {
+        return res.status(409).json({
+          success: false,
+          error: 'Template already exists'
+        });
+      }
+
+      const template = new Template({
+        name: req.body.name,
+        displayName: req.body.displayName,
+        description: req.body.description || '',
+        tags: req.body.tags || [],
+        stack: req.body.stack || [],
+        ownerId: req.user!._id
+      });
+      await template.save();
+
+      res.status(201).json({
+        success: true,
+        data: template,
+        message: 'Template created successfully'
+      });
+    } catch (error) {
+      console.error('Error creating template:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to create template'
+      });
+    }
+  }
+);
+
+// GET /api/templates - Browse the catalog, optionally by tag or stack component
+router.get('/',
+  [
+    query('tag').optional().isString().withMessage('Tag must be a string'),
+    query('stack').optional().isString().withMessage('Stack must be a string')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const filter: any = { latestVersion: { $gt: 0 } };
+      if (req.query.tag) filter.tags = req.query.tag;
+      if (req.query.stack) filter.stack = req.query.stack;
+
+      const templates = await Template.find(filter).sort({ name: 1 });
+
+      res.json({
+        success: true,
+        data: templates
+      });
+    } catch (error) {
+      console.error('Error fetching templates:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch templates'
+      });
+    }
+  }
+);
+
+// GET /api/templates/:name - Template with the parameters of its latest version
+router.get('/:name',
+  [nameParam],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const template = await Template.findOne({ name: req.params.name });
+      if (!template) {
+        return res.status(404).json({
+          success: false,
+          error: 'Template not found'
+        });
+      }
+
+      const latest = template.latestVersion
+        ? await TemplateVersion.findOne({ templateName: template.name, version: template.latestVersion }).select('-files.content')
+        : null;
+
+      res.json({
+        success: true,
+        data: {
+          ...template.toJSON(),
+          latest
+        }
+      });
+    } catch (error) {
+      console.error('Error fetching template:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch template'
+      });
+    }
+  }
+);
+
+// POST /api/templates/:name/versions - Publish a new immutable version
+router.post('/:name/versions',
+  requireAuth,
+  [
+    nameParam,
+    body('files').isArray({ min: 1 }).withMessage('At least one file is required'),
+    body('files.*.path').isString().isLength({ min: 1 }).withMessage('Each file needs a path'),
+    body('files.*.content').isString().withMessage('Each file needs content'),
+    body('files.*.when').optional().isString().withMessage('File condition must be a parameter name'),
+    body('parameters').optional().isArray().withMessage('Parameters must be an array'),
+    body('parameters.*.name').optional().matches(/^[\w-]+$/).withMessage('Each parameter needs a name'),
+    body('parameters.*.type').optional().isIn(['string', 'boolean', 'number']).withMessage('Parameter type must be string, boolean or number'),
+    body('changelog').isString().isLength({ min: 1, max: 5000 }).withMessage('Changelog entry is required')
+  ],
+  validateRequest,
+  async (req: AuthenticatedRequest, res: Response) => {
+    try {
+      const template = await Template.findOne({ name: req.params.name });
+      if (!template) {
+        return res.status(404).json({
+          success: false,
+          error: 'Template not found'
+        });
+      }
+
+      if (template.ownerId !== req.user!._id && req.user!.role !== 'admin') {
+        return res.status(403).json({
+          success: false,
+          error: 'Only the template owner can publish versions'
+        });
+      }
+
+      const parameters = req.body.parameters || [];
+      const bumped = await Template.findOneAndUpdate(
+        { name: template.name },
+        { $inc: { latestVersion: 1 } },
+        { new: true }
+      );
+
+      const version = new TemplateVersion({
+        templateName: template.name,
+        version: bumped!.latestVersion,
+        parameters,
+        files: req.body.files,
+        changelog: req.body.changelog,
+        createdBy: req.user!._id
+      });
+      await version.save();
+
+      const undeclared = undeclaredPlaceholders(req.body.files, parameters);
+
+      res.status(201).json({
+        success: true,
+        data: version,
+        message: `Version ${version.version} published successfully`,
+        ...(undeclared.length > 0 ? { warnings: undeclared.map(name => `{{${name}}} is not a declared parameter and will be left as-is`) } : {})
+      });
+    } catch (error) {
+      console.error('Error creating template version:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to create template version'
+      });
+    }
+  }
+);
+
+// GET /api/templates/:name/versions - Version history
+router.get('/:name/versions',
+  [nameParam],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const versions = await TemplateVersion.find({ templateName: req.params.name })
+        .sort({ version: -1 })
+        .select('-files');
+
+      res.json({
+        success: true,
+        data: versions
+      });
+    } catch (error) {
+      console.error('Error fetching template versions:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch template versions'
+      });
+    }
+  }
+);
+
+// GET /api/templates/:name/versions/:version - Get a specific version with its files
+router.get('/:name/versions/:version',
+  [nameParam, versionParam],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const version = await TemplateVersion.findOne({
+        templateName: req.params.name,
+        version: parseInt(req.params.version)
+      });
+
+      if (!version) {
+        return res.status(404).json({
+          success: false,
+          error: 'Template version not found'
+        });
+      }
+
+      res.json({
+        success: true,
+        data: version
+      });
+    } catch (error) {
+      console.error('Error fetching template version:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to fetch template version'
+      });
+    }
+  }
+);
+
+// POST /api/templates/:name/render - Render the latest (or a given) version into files
+router.post('/:name/render',
+  [
+    nameParam,
+    body('parameters').optional().isObject().withMessage('Parameters must be an object'),
+    body('version').optional().isInt({ min: 1 }).withMessage('Version must be a positive integer')
+  ],
+  validateRequest,
+  async (req: Request, res: Response) => {
+    try {
+      const template = await Template.findOne({ name: req.params.name });
+      if (!template) {
+        return res.status(404).json({
+          success: false,
+          error: 'Template not found'
+        });
+      }
+
+      const versionNumber = req.body.version || template.latestVersion;
+      const version = versionNumber
+        ? await TemplateVersion.findOne({ templateName: template.name, version: versionNumber })
+        : null;
+      if (!version) {
+        return res.status(404).json({
+          success: false,
+          error: 'Template version not found'
+        });
+      }
+
+      const files = renderFiles(version.files, version.parameters, req.body.parameters || {});
+
+      res.json({
+        success: true,
+        data: {
+          name: template.name,
+          version: version.version,
+          files
+        }
+      });
+    } catch (error) {
+      if (error instanceof InvalidParametersError) {
+        return res.status(400).json({
+          success: false,
+          error: error.message,
+          details: error.errors
+        });
+      }
+
+      console.error('Error rendering template:', error);
+      res.status(500).json({
+        success: false,
+        error: 'Failed to render template'
+      });
+    }
+  }
+);
+
+export default router;
@@ -0,0 +1,128 @@
+import express from 'express';
+import cors from 'cors';
+import mongoose from 'mongoose';
+import * as path from 'path';
+import dotenv from 'dotenv';
+import winston from 'winston';
+import templateRoutes from './routes/templates.js';
+import { CatalogLoader } from './services/CatalogLoader.js';
+
+// Load environment variables
+dotenv.config();
+
+const app = express();
+const PORT = process.env.PORT || 3012;
+
+// Logger configuration
+const logger = winston.createLogger({
+  level: 'info',
+  format: winston.format.combine(
+    winston.format.timestamp(),
+    winston.format.json()
+  ),
+  transports: [
+    new winston.transports.Console(),
+    new winston.transports.File({ filename: 'logs/template-service.log' })
+  ]
+});
+
+// Database connection
+const MONGODB_URI = process.env.MONGODB_URI || 'mongodb://localhost:27017/ai-pipeline';
+
+const catalog = new CatalogLoader(process.env.TEMPLATE_CATALOG_DIR || path.join(process.cwd(), 'catalog'));
+
+mongoose.connect(MONGODB_URI)
+  .then(async () => {
+    logger.info('🗄️  Connected to MongoDB');
+    const seeded = await catalog.load();
+    if (seeded.length > 0) {
+      logger.info(`📦 Seeded catalog templates: ${seeded.join(', ')}`);
+    }
+  })
+  .catch((error) => {
+    logger.error('❌ MongoDB connection error:', error);
+    process.exit(1);
+  });
+
+// Middleware
+app.use(cors({
+  origin: process.env.FRONTEND_URL || 'http://localhost:5173',
+  credentials: true
+}));
+app.use(express.json({ limit: '20mb' }));
+app.use(express.urlencoded({ extended: true }));
+
+// Request logging middleware
+app.use((req, res, next) => {
+  logger.info(`${req.method} ${req.path}`, {
+    ip: req.ip,
+    userAgent: req.get('User-Agent'),
+    timestamp: new Date().toISOString()
+  });
+  next();
+});
+
+// Routes
+app.use('/api/templates', templateRoutes);
+
+// Health check endpoint
+app.get('/health', (req, res) => {
+  res.json({
+    status: 'ok',
+    service: 'template-service',
+    timestamp: new Date().toISOString(),
+    version: '1.0.0',
+    database: mongoose.connection.readyState === 1 ? 'connected' : 'disconnected'
+  });
+});
+
+// Service info endpoint
+app.get('/info', (req, res) => {
+  res.json({
+    service: 'Template Service',
+    version: '1.0.0',
+    description: 'Versioned project starter templates',
+    endpoints: [
+      { method: 'POST', path: '/api/templates', description: 'Create template' },
+      { method: 'GET', path: '/api/templates', description: 'Browse catalog' },
+      { method: 'GET', path: '/api/templates/:name', description: 'Get template and latest parameters' },
+      { method: 'POST', path: '/api/templates/:name/versions', description: 'Publish version' },
+      { method: 'GET', path: '/api/templates/:name/versions', description: 'List versions and changelog' },
+      { method: 'GET', path: '/api/templates/:name/versions/:version', description: 'Get version with files' },
+      { method: 'POST', path: '/api/templates/:name/render', description: 'Render template with parameters' }
+    ]
+  });
+});
+
+// Catch-all for undefined routes
+app.use('*', (req, res) => {
+  res.status(404).json({
+    success: false,
+    error: 'Route not found',
+    service: 'template-service'
+  });
+});
+
+// Global error handler
+app.use((err: any, req: any, res: any, next: any) => {
+  logger.error('Unhandled error:', err);
+  res.status(500).json({
+    success: false,
+    error: 'Internal server error',
+    service: 'template-service'
+  });
+});
+
+// Graceful shutdown
+process.on('SIGTERM', () => {
+  logger.info('SIGTERM received. Shutting down gracefully...');
+  mongoose.connection.close();
+  process.exit(0);
+});
+
+// Start server
+app.listen(PORT, () => {
+  logger.info(`🧩 Template Service running on port ${PORT}`);
+});
+
+export default app;
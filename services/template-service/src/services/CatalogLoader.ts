@@ -0,0 +1,49 @@
+import * as path from 'path';
+import { promises as fs } from 'fs';
+import * as yaml from 'yaml';
+import { Template } from '../models/Template.js';
+import { TemplateVersion } from '../models/TemplateVersion.js';
+
+// Seeds the built-in starter templates from YAML. Templates that already exist are left alone so
+// edits made through the API are never overwritten.
+export class CatalogLoader {
+  constructor(private catalogDir: string) {}
+
+  async load(): Promise<string[]> {
+    const entries = await fs.readdir(this.catalogDir).catch(() => [] as string[]);
+    const seeded: string[] = [];
+
+    for (const entry of entries) {
+      if (!entry.endsWith('.yaml') && !entry.endsWith('.yml')) continue;
+
+      const raw = await fs.readFile(path.join(this.catalogDir, entry), 'utf-8');
+      const data = yaml.parse(raw);
+      if (!data?.name || !Array.isArray(data.files)) {
+        throw new Error(`Catalog template ${entry} must declare name and files`);
+      }
+
+      if (await Template.exists({ name: data.name })) continue;
+
+      await TemplateVersion.create({
+        templateName: data.name,
+        version: 1,
+        parameters: data.parameters || [],
+        files: data.files,
+        changelog: data.changelog || 'Initial catalog version',
+        createdBy: 'system'
+      });
+      await Template.create({
+        name: data.name,
+        displayName: data.displayName || data.name,
+        description: data.description || '',
+        tags: data.tags || [],
+        stack: data.stack || [],
+        latestVersion: 1,
+        ownerId: 'system'
+      });
+      seeded.push(data.name);
+    }
+
+    return seeded;
+  }
+}
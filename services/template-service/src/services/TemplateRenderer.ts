@@ -0,0 +1,87 @@
+import { ITemplateFile, ITemplateParameter } from '../models/TemplateVersion.js';
+
+const PLACEHOLDER = /\{\{\s*([\w.-]+)\s*\}\}/g;
+
+export interface RenderedFile {
+  path: string;
+  content: string;
+}
+
+export class InvalidParametersError extends Error {
+  constructor(public errors: string[]) {
+    super(`Invalid template parameters: ${errors.join('; ')}`);
+    this.name = 'InvalidParametersError';
+  }
+}
+
+// Applies defaults and checks required parameters, types and allowed options
+export function resolveParameters(declared: ITemplateParameter[], values: Record<string, any>): Record<string, any> {
+  const resolved: Record<string, any> = {};
+  const errors: string[] = [];
+
+  for (const parameter of declared) {
+    const value = values[parameter.name] !== undefined ? values[parameter.name] : parameter.defaultValue;
+
+    if (value === undefined || value === null || value === '') {
+      if (parameter.required) errors.push(`${parameter.name} is required`);
+      continue;
+    }
+    if (typeof value !== parameter.type) {
+      errors.push(`${parameter.name} must be a ${parameter.type}`);
+      continue;
+    }
+    if (parameter.options && parameter.options.length > 0 && !parameter.options.includes(String(value))) {
+      errors.push(`${parameter.name} must be one of ${parameter.options.join(', ')}`);
+      continue;
+    }
+    resolved[parameter.name] = value;
+  }
+
+  if (errors.length > 0) {
+    throw new InvalidParametersError(errors);
+  }
+  return resolved;
+}
+
+// Renders the skeleton. Only declared parameters are substituted so that placeholders belonging
+// to the generated code itself (Go templates, Handlebars, JSX styles) survive untouched.
+export function renderFiles(
+  files: ITemplateFile[],
+  declared: ITemplateParameter[],
+  values: Record<string, any>
+): RenderedFile[] {
+  const parameters = resolveParameters(declared, values);
+  const names = new Set(declared.map(parameter => parameter.name));
+
+  const substitute = (text: string) => text.replace(PLACEHOLDER, (match, key: string) => {
+    if (!names.has(key)) return match;
+    const value = parameters[key];
+    return value === undefined ? '' : String(value);
+  });
+
+  return files
+    .filter(file => included(file.when, parameters))
+    .map(file => ({
+      path: substitute(file.path),
+      content: substitute(file.content)
+    }));
+}
+
+function included(condition: string | undefined, parameters: Record<string, any>): boolean {
+  if (!condition) return true;
+  const negated = condition.startsWith('!');
+  const value = Boolean(parameters[negated ? condition.slice(1) : condition]);
+  return negated ? !value : value;
+}
+
+// Lists placeholders that are not declared parameters, so authors can spot typos
+export function undeclaredPlaceholders(files: ITemplateFile[], declared: ITemplateParameter[]): string[] {
+  const names = new Set(declared.map(parameter => parameter.name));
+  const found = new Set<string>();
+  for (const file of files) {
+    for (const match of `${file.path}\n${file.content}`.matchAll(PLACEHOLDER)) {
+      if (!names.has(match[1]) && !match[1].includes('.')) found.add(match[1]);
+    }
+  }
+  return Array.from(found);
+}
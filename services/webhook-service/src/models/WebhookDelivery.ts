@@ -0,0 +1,74 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export interface IDeliveryAttempt {
+  attempt: number;
+  statusCode?: number;
+  error?: string;
+  durationMs: number;
+  attemptedAt: Date;
+}
+
+export interface IWebhookDelivery extends Document {
+  endpointId: mongoose.Types.ObjectId;
+  eventId: string;
+  eventType: string;
+  payload: any;
+  status: 'pending' | 'sending' | 'delivered' | 'failed';
+  attempts: IDeliveryAttempt[];
+  nextAttemptAt?: Date;
+  claimedAt?: Date;
+  deliveredAt?: Date;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const DeliveryAttemptSchema = new Schema({
+  attempt: { type: Number, required: true },
+  statusCode: { type: Number },
+  error: { type: String },
+  durationMs: { type: Number, required: true },
+  attemptedAt: { type: Date, default: Date.now }
+}, { _id: false });
+
+const WebhookDeliverySchema: Schema = new Schema({
+  endpointId: {
+    type: Schema.Types.ObjectId,
+    ref: 'WebhookEndpoint',
+    required: true,
+    index: true
+  },
+  eventId: {
+    type: String,
+    required: true
+  },
+  eventType: {
+    type: String,
+    required: true
+  },
+  payload: {
+    type: Schema.Types.Mixed,
+    required: true
+  },
+  status: {
+    type: String,
+    enum: ['pending', 'sending', 'delivered', 'failed'],
+    default: 'pending'
+  },
+  attempts: [DeliveryAttemptSchema],
+  nextAttemptAt: {
+    type: Date
+  },
+  // Set when a dispatcher claims the delivery for an attempt; stale claims are reclaimed by the sweep
+  claimedAt: {
+    type: Date
+  },
+  deliveredAt: {
+    type: Date
+  }
+}, {
+  timestamps: true
+});
+
+WebhookDeliverySchema.index({ status: 1, nextAttemptAt: 1 });
+
+export const WebhookDelivery = mongoose.model<IWebhookDelivery>('WebhookDelivery', WebhookDeliverySchema);
@@ -0,0 +1,59 @@
+import mongoose, { Schema, Document } from 'mongoose';
+
+export interface IWebhookEndpoint extends Document {
+  url: string;
+  secret: string;
+  events: string[];
+  projectId?: string;
+  allProjects: boolean;
+  description: string;
+  active: boolean;
+  ownerId: string;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const WebhookEndpointSchema: Schema = new Schema({
+  url: {
+    type: String,
+    required: true,
+    trim: true
+  },
+  secret: {
+    type: String,
+    required: true,
+    select: false
+  },
+  // Event types to deliver; '*' subscribes to everything
+  events: {
+    type: [String],
+    default: ['*']
+  },
+  projectId: {
+    type: String,
+    index: true
+  },
+  // Only admins may register endpoints that receive every project's events
+  allProjects: {
+    type: Boolean,
+    default: false
+  },
+  description: {
+    type: String,
+    default: '',
+    maxlength: 500
+  },
+  active: {
+    type: Boolean,
+    default: true
+  },
+  ownerId: {
+    type: String,
+    required: true,
+    index: true
+  }
+}, {
+  timestamps: true
+});
+
+export const WebhookEndpoint = mongoose.model<IWebhookEndpoint>('WebhookEndpoint', WebhookEndpointSchema);
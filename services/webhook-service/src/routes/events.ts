@@ -0,0 +1,68 @@
+import express, { Request, Response } from 'express';
+import { body, validationResult } from 'express-validator';
+import { WebhookDispatcher } from '../services/WebhookDispatcher.js';
+import { requireServiceToken } from '../middleware/auth.js';
+
+const router = express.Router();
+
+// Internal ingestion endpoint: other services publish lifecycle events here as CloudEvents
+// (legacy { type, projectId, data } bodies are still accepted). Not exposed via the gateway, and
+// callers must present the shared service token since published events go out signed.
+export default function createEventRoutes(dispatcher: WebhookDispatcher) {
+  router.use(requireServiceToken);
+
+  // POST /api/events - Publish an event to subscribed webhooks
+  router.post('/',
+    [
+      body('type').matches(/^[a-z][a-z0-9_.-]*$/).withMessage('Event type must be a dotted lowercase name'),
+      body('specversion').optional().equals('1.0').withMessage('Only CloudEvents specversion 1.0 is supported'),
+      body('id').optional().isString().withMessage('Event ID must be a string'),
+      body('source').optional().isString().withMessage('Source must be a string'),
+      body('projectid').optional().isString().withMessage('Project ID must be a string'),
+      body('projectId').optional().isString().withMessage('Project ID must be a string'),
+      body('data').exists().withMessage('Event data is required')
+    ],
+    async (req: Request, res: Response) => {
+      const errors = validationResult(req);
+      if (!errors.isEmpty()) {
+        return res.status(400).json({
+          success: false,
+          error: 'Validation failed',
+          details: errors.array()
+        });
+      }
+
+      try {
+        const { id, type, source, time, subject, tenantid, requestid, data } = req.body;
+        const projectId = req.body.projectid ?? req.body.projectId;
+        const deliveries = await dispatcher.publish({
+          id,
+          type,
+          source,
+          time,
+          subject,
+          projectId,
+          tenantId: tenantid,
+          requestId: requestid,
+          data
+        });
+
+        res.status(202).json({
+          success: true,
+          data: {
+            eventId: deliveries[0]?.eventId || id,
+            deliveries: deliveries.length
+          }
+        });
+      } catch (error) {
+        console.error('Error publishing event:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to publish event'
+        });
+      }
+    }
+  );
+
+  return router;
+}
@@ -0,0 +1,389 @@
+import express, { Request, Response } from 'express';
+import { body, param, query, validationResult } from 'express-validator';
+import { randomBytes } from 'crypto';
+import axios from 'axios';
+import { WebhookEndpoint } from '../models/WebhookEndpoint.js';
+import { WebhookDelivery } from '../models/WebhookDelivery.js';
+import { WebhookDispatcher } from '../services/WebhookDispatcher.js';
+import { requireAuth, AuthenticatedRequest } from '../middleware/auth.js';
+
+const router = express.Router();
+
+// Validation middleware
+const validateRequest = (req: Request, res: Response, next: express.NextFunction) => {
+  const errors = validationResult(req);
+  if (!errors.isEmpty()) {
+    return res.status(400).json({
+      success: false,
+      error: 'Validation failed',
+      details: errors.array()
+    });
+  }
+  next();
+};
+
+const idParam = param('id').isMongoId().withMessage('Invalid webhook ID');
+const deliveryIdParam = param('deliveryId').isMongoId().withMessage('Invalid delivery ID');
+
+const generateSecret = () => `whsec_${randomBytes(24).toString('hex')}`;
+
+// Loads an endpoint the current user owns (admins can access any)
+const findOwnedEndpoint = async (req: AuthenticatedRequest, id: string, withSecret = false) => {
+  const filter: any = { _id: id };
+  if (req.user!.role !== 'admin') filter.ownerId = req.user!._id;
+
+  const lookup = WebhookEndpoint.findOne(filter);
+  return withSecret ? lookup.select('+secret') : lookup;
+};
+
+// Confirms through project-service that the caller is a member of the project
+const canAccessProject = async (req: AuthenticatedRequest, projectId: string): Promise<boolean> => {
+  const projectServiceUrl = process.env.PROJECT_SERVICE_URL || 'http://localhost:3002';
+
+  try {
+    await axios.get(`${projectServiceUrl}/api/projects/${encodeURIComponent(projectId)}`, {
+      headers: { Authorization: req.headers.authorization },
+      timeout: 5000
+    });
+    return true;
+  } catch (error) {
+    if (!axios.isAxiosError(error) || !error.response) throw error;
+    return false;
+  }
+};
+
+export default function createWebhookRoutes(dispatcher: WebhookDispatcher) {
+  router.use(requireAuth);
+
+  // POST /api/webhooks - Register an endpoint; the signing secret is only returned here.
+  // Endpoints are scoped to a project the caller belongs to; only admins may leave out projectId
+  // and receive every project's events.
+  router.post('/',
+    [
+      body('url').isURL({ require_tld: false, protocols: ['http', 'https'] }).withMessage('A valid http(s) URL is required'),
+      body('events').optional().isArray({ min: 1 }).withMessage('Events must be a non-empty array'),
+      body('events.*').optional().isString().withMessage('Event types must be strings'),
+      body('projectId').optional().isString().withMessage('Project ID must be a string'),
+      body('description').optional().isString().isLength({ max: 500 }).withMessage('Description must be less than 500 characters')
+    ],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const { projectId } = req.body;
+        if (!projectId && req.user!.role !== 'admin') {
+          return res.status(403).json({
+            success: false,
+            error: 'Only admins can register webhooks for all projects'
+          });
+        }
+        if (projectId && !await canAccessProject(req, projectId)) {
+          return res.status(404).json({
+            success: false,
+            error: 'Project not found'
+          });
+        }
+
+        const secret = generateSecret();
+        const endpoint = new WebhookEndpoint({
+          url: req.body.url,
+          secret,
+          events: req.body.events || ['*'],
+          projectId,
+          allProjects: !projectId,
+          description: req.body.description || '',
+          ownerId: req.user!._id
+        });
+        await endpoint.save();
+
+        res.status(201).json({
+          success: true,
+          data: { ...endpoint.toJSON(), secret },
+          message: 'Webhook registered successfully'
+        });
+      } catch (error) {
+        console.error('Error registering webhook:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to register webhook'
+        });
+      }
+    }
+  );
+
+  // GET /api/webhooks - List the current user's endpoints
+  router.get('/',
+    [
+      query('projectId').optional().isString().withMessage('Project ID must be a string')
+    ],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const filter: any = { ownerId: req.user!._id };
+        if (req.query.projectId) filter.projectId = req.query.projectId;
+
+        const endpoints = await WebhookEndpoint.find(filter).sort({ createdAt: -1 });
+
+        res.json({
+          success: true,
+          data: endpoints
+        });
+      } catch (error) {
+        console.error('Error listing webhooks:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to list webhooks'
+        });
+      }
+    }
+  );
+
+  // GET /api/webhooks/deliveries/:deliveryId - Delivery log with every attempt
+  router.get('/deliveries/:deliveryId',
+    [deliveryIdParam],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const delivery = await WebhookDelivery.findById(req.params.deliveryId);
+        const endpoint = delivery && await findOwnedEndpoint(req, delivery.endpointId.toString());
+        if (!delivery || !endpoint) {
+          return res.status(404).json({
+            success: false,
+            error: 'Delivery not found'
+          });
+        }
+
+        res.json({
+          success: true,
+          data: delivery
+        });
+      } catch (error) {
+        console.error('Error fetching delivery:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to fetch delivery'
+        });
+      }
+    }
+  );
+
+  // POST /api/webhooks/deliveries/:deliveryId/redeliver - Send a delivery again
+  router.post('/deliveries/:deliveryId/redeliver',
+    [deliveryIdParam],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const delivery = await WebhookDelivery.findById(req.params.deliveryId);
+        const endpoint = delivery && await findOwnedEndpoint(req, delivery.endpointId.toString());
+        if (!delivery || !endpoint) {
+          return res.status(404).json({
+            success: false,
+            error: 'Delivery not found'
+          });
+        }
+
+        const result = await dispatcher.redeliver(delivery);
+
+        res.json({
+          success: true,
+          data: result
+        });
+      } catch (error) {
+        console.error('Error redelivering webhook:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to redeliver webhook'
+        });
+      }
+    }
+  );
+
+  // GET /api/webhooks/:id - Get an endpoint
+  router.get('/:id',
+    [idParam],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const endpoint = await findOwnedEndpoint(req, req.params.id);
+        if (!endpoint) {
+          return res.status(404).json({
+            success: false,
+            error: 'Webhook not found'
+          });
+        }
+
+        res.json({
+          success: true,
+          data: endpoint
+        });
+      } catch (error) {
+        console.error('Error fetching webhook:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to fetch webhook'
+        });
+      }
+    }
+  );
+
+  // PATCH /api/webhooks/:id - Update URL, subscribed events or active flag
+  router.patch('/:id',
+    [
+      idParam,
+      body('url').optional().isURL({ require_tld: false, protocols: ['http', 'https'] }).withMessage('A valid http(s) URL is required'),
+      body('events').optional().isArray({ min: 1 }).withMessage('Events must be a non-empty array'),
+      body('events.*').optional().isString().withMessage('Event types must be strings'),
+      body('active').optional().isBoolean().withMessage('Active must be a boolean'),
+      body('description').optional().isString().isLength({ max: 500 }).withMessage('Description must be less than 500 characters')
+    ],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const endpoint = await findOwnedEndpoint(req, req.params.id);
+        if (!endpoint) {
+          return res.status(404).json({
+            success: false,
+            error: 'Webhook not found'
+          });
+        }
+
+        const { url, events, active, description } = req.body;
+        if (url !== undefined) endpoint.url = url;
+        if (events !== undefined) endpoint.events = events;
+        if (active !== undefined) endpoint.active = active;
+        if (description !== undefined) endpoint.description = description;
+        await endpoint.save();
+
+        res.json({
+          success: true,
+          data: endpoint,
+          message: 'Webhook updated successfully'
+        });
+      } catch (error) {
+        console.error('Error updating webhook:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to update webhook'
+        });
+      }
+    }
+  );
+
+  // POST /api/webhooks/:id/rotate-secret - Issue a new signing secret
+  router.post('/:id/rotate-secret',
+    [idParam],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const endpoint = await findOwnedEndpoint(req, req.params.id, true);
+        if (!endpoint) {
+          return res.status(404).json({
+            success: false,
+            error: 'Webhook not found'
+          });
+        }
+
+        endpoint.secret = generateSecret();
+        await endpoint.save();
+
+        res.json({
+          success: true,
+          data: { secret: endpoint.secret },
+          message: 'Webhook secret rotated'
+        });
+      } catch (error) {
+        console.error('Error rotating webhook secret:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to rotate webhook secret'
+        });
+      }
+    }
+  );
+
+  // GET /api/webhooks/:id/deliveries - Recent deliveries for an endpoint
+  router.get('/:id/deliveries',
+    [
+      idParam,
+      query('status').optional().isIn(['pending', 'sending', 'delivered', 'failed']).withMessage('Invalid delivery status'),
+      query('page').optional().isInt({ min: 1 }).withMessage('Page must be a positive integer'),
+      query('limit').optional().isInt({ min: 1, max: 100 }).withMessage('Limit must be between 1 and 100')
+    ],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const endpoint = await findOwnedEndpoint(req, req.params.id);
+        if (!endpoint) {
+          return res.status(404).json({
+            success: false,
+            error: 'Webhook not found'
+          });
+        }
+
+        const page = parseInt(req.query.page as string) || 1;
+        const limit = parseInt(req.query.limit as string) || 20;
+        const filter: any = { endpointId: endpoint._id };
+        if (req.query.status) filter.status = req.query.status;
+
+        const [deliveries, total] = await Promise.all([
+          WebhookDelivery.find(filter)
+            .sort({ createdAt: -1 })
+            .skip((page - 1) * limit)
+            .limit(limit),
+          WebhookDelivery.countDocuments(filter)
+        ]);
+
+        res.json({
+          success: true,
+          data: {
+            deliveries,
+            pagination: {
+              page,
+              limit,
+              total,
+              pages: Math.ceil(total / limit)
+            }
+          }
+        });
+      } catch (error) {
+        console.error('Error listing deliveries:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to list deliveries'
+        });
+      }
+    }
+  );
+
+  // DELETE /api/webhooks/:id - Remove an endpoint and its delivery log
+  router.delete('/:id',
+    [idParam],
+    validateRequest,
+    async (req: AuthenticatedRequest, res: Response) => {
+      try {
+        const endpoint = await findOwnedEndpoint(req, req.params.id);
+        if (!endpoint) {
+          return res.status(404).json({
+            success: false,
+            error: 'Webhook not found'
+          });
+        }
+
+        await WebhookDelivery.deleteMany({ endpointId: endpoint._id });
+        await endpoint.deleteOne();
+
+        res.json({
+          success: true,
+          message: 'Webhook deleted successfully'
+        });
+      } catch (error) {
+        console.error('Error deleting webhook:', error);
+        res.status(500).json({
+          success: false,
+          error: 'Failed to delete webhook'
+        });
+      }
+    }
+  );
+
+  return router;
+}
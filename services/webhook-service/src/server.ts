@@ -0,0 +1,131 @@
+import express from 'express';
+import cors from 'cors';
+import mongoose from 'mongoose';
+import dotenv from 'dotenv';
+import winston from 'winston';
+import { WebhookDispatcher } from './services/WebhookDispatcher.js';
+import createWebhookRoutes from './routes/webhooks.js';
+import createEventRoutes from './routes/events.js';
+
+// Load environment variables
+dotenv.config();
+
+const app = express();
+const PORT = process.env.PORT || 3009;
+
+// Logger configuration
+const logger = winston.createLogger({
+  level: 'info',
+  format: winston.format.combine(
+    winston.format.timestamp(),
+    winston.format.json()
+  ),
+  transports: [
+    new winston.transports.Console(),
+    new winston.transports.File({ filename: 'logs/webhook-service.log' })
+  ]
+});
+
+const dispatcher = new WebhookDispatcher();
+
+// Database connection
+const MONGODB_URI = process.env.MONGODB_URI || 'mongodb://localhost:27017/ai-pipeline';
+
+mongoose.connect(MONGODB_URI)
+  .then(() => {
+    logger.info('🗄️  Connected to MongoDB');
+    dispatcher.start(parseInt(process.env.WEBHOOK_RETRY_INTERVAL_MS || '15000'));
+  })
+  .catch((error) => {
+    logger.error('❌ MongoDB connection error:', error);
+    process.exit(1);
+  });
+
+// Middleware
+app.use(cors({
+  origin: process.env.FRONTEND_URL || 'http://localhost:5173',
+  credentials: true
+}));
+// Events arrive as CloudEvents in structured mode
+app.use(express.json({ limit: '5mb', type: ['application/json', 'application/cloudevents+json'] }));
+app.use(express.urlencoded({ extended: true }));
+
+// Request logging middleware
+app.use((req, res, next) => {
+  logger.info(`${req.method} ${req.path}`, {
+    ip: req.ip,
+    userAgent: req.get('User-Agent'),
+    timestamp: new Date().toISOString()
+  });
+  next();
+});
+
+// Routes
+app.use('/api/webhooks', createWebhookRoutes(dispatcher));
+app.use('/api/events', createEventRoutes(dispatcher));
+
+// Health check endpoint
+app.get('/health', (req, res) => {
+  res.json({
+    status: 'ok',
+    service: 'webhook-service',
+    timestamp: new Date().toISOString(),
+    version: '1.0.0',
+    database: mongoose.connection.readyState === 1 ? 'connected' : 'disconnected'
+  });
+});
+
+// Service info endpoint
+app.get('/info', (req, res) => {
+  res.json({
+    service: 'Webhook Service',
+    version: '1.0.0',
+    description: 'Webhook registration and signed event delivery',
+    endpoints: [
+      { method: 'POST', path: '/api/webhooks', description: 'Register webhook endpoint' },
+      { method: 'GET', path: '/api/webhooks', description: 'List webhook endpoints' },
+      { method: 'GET', path: '/api/webhooks/:id', description: 'Get webhook endpoint' },
+      { method: 'PATCH', path: '/api/webhooks/:id', description: 'Update webhook endpoint' },
+      { method: 'DELETE', path: '/api/webhooks/:id', description: 'Delete webhook endpoint' },
+      { method: 'POST', path: '/api/webhooks/:id/rotate-secret', description: 'Rotate signing secret' },
+      { method: 'GET', path: '/api/webhooks/:id/deliveries', description: 'List deliveries' },
+      { method: 'GET', path: '/api/webhooks/deliveries/:deliveryId', description: 'Get delivery log' },
+      { method: 'POST', path: '/api/webhooks/deliveries/:deliveryId/redeliver', description: 'Redeliver event' },
+      { method: 'POST', path: '/api/events', description: 'Publish event (internal)' }
+    ]
+  });
+});
+
+// Catch-all for undefined routes
+app.use('*', (req, res) => {
+  res.status(404).json({
+    success: false,
+    error: 'Route not found',
+    service: 'webhook-service'
+  });
+});
+
+// Global error handler
+app.use((err: any, req: any, res: any, next: any) => {
+  logger.error('Unhandled error:', err);
+  res.status(500).json({
+    success: false,
+    error: 'Internal server error',
+    service: 'webhook-service'
+  });
+});
+
+// Graceful shutdown
+process.on('SIGTERM', () => {
+  logger.info('SIGTERM received. Shutting down gracefully...');
+  dispatcher.stop();
+  mongoose.connection.close();
+  process.exit(0);
+});
+
+// Start server
+app.listen(PORT, () => {
+  logger.info(`🪝 Webhook Service running on port ${PORT}`);
+});
+
+export default app;
@@ -0,0 +1,211 @@
+import { createHmac, randomUUID } from 'crypto';
+import axios from 'axios';
+import { WebhookEndpoint } from '../models/WebhookEndpoint.js';
+import { WebhookDelivery, IWebhookDelivery } from '../models/WebhookDelivery.js';
+
+export interface WebhookEvent {
+  id?: string;
+  type: string;
+  source?: string;
+  time?: string;
+  subject?: string;
+  projectId?: string;
+  tenantId?: string;
+  requestId?: string;
+  data: any;
+}
+
+const MAX_ATTEMPTS = parseInt(process.env.WEBHOOK_MAX_ATTEMPTS || '8');
+const BASE_DELAY_MS = parseInt(process.env.WEBHOOK_RETRY_BASE_DELAY_MS || '10000');
+const MAX_DELAY_MS = 6 * 60 * 60 * 1000;
+const TIMEOUT_MS = parseInt(process.env.WEBHOOK_TIMEOUT_MS || '10000');
+// A claim older than this belongs to a dispatcher that stopped mid-attempt
+const CLAIM_TTL_MS = TIMEOUT_MS * 3;
+
+// Signs `${timestamp}.${body}` so receivers can reject replayed or tampered payloads
+export function signPayload(secret: string, timestamp: string, body: string): string {
+  return `sha256=${createHmac('sha256', secret).update(`${timestamp}.${body}`).digest('hex')}`;
+}
+
+export function calculateRetryDelay(attempt: number): number {
+  return Math.min(BASE_DELAY_MS * Math.pow(2, attempt - 1), MAX_DELAY_MS);
+}
+
+export class WebhookDispatcher {
+  private timer: NodeJS.Timeout | null = null;
+  private sweeping = false;
+
+  start(intervalMs: number): void {
+    this.timer = setInterval(() => {
+      this.retryDue().catch(error => console.error('Webhook retry sweep failed:', error));
+    }, intervalMs);
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = null;
+    }
+  }
+
+  // Fans an event out to every active endpoint subscribed to it: endpoints scoped to the event's
+  // project, plus admin-registered endpoints that receive every project's events
+  async publish(event: WebhookEvent): Promise<IWebhookDelivery[]> {
+    const eventId = event.id || randomUUID();
+    const filter: any = {
+      active: true,
+      events: { $in: [event.type, '*'] },
+      $or: event.projectId ? [{ projectId: event.projectId }, { allProjects: true }] : [{ allProjects: true }]
+    };
+
+    const endpoints = await WebhookEndpoint.find(filter);
+    // Receivers get a CloudEvents 1.0 envelope in structured mode
+    const payload = {
+      specversion: '1.0',
+      id: eventId,
+      type: event.type,
+      source: event.source || '/webhook-service',
+      time: event.time || new Date().toISOString(),
+      subject: event.subject,
+      datacontenttype: 'application/json',
+      tenantid: event.tenantId,
+      requestid: event.requestId,
+      projectid: event.projectId,
+      data: event.data
+    };
+
+    const deliveries = await WebhookDelivery.insertMany(endpoints.map(endpoint => ({
+      endpointId: endpoint._id,
+      eventId,
+      eventType: event.type,
+      payload,
+      status: 'pending',
+      nextAttemptAt: new Date()
+    })));
+
+    // First attempt happens immediately; failures are picked up by the retry sweep
+    deliveries.forEach(delivery => {
+      this.attempt(delivery).catch(error => console.error('Webhook delivery error:', error));
+    });
+
+    return deliveries;
+  }
+
+  // Sends a delivery again regardless of its current status, unless an attempt is in flight
+  async redeliver(delivery: IWebhookDelivery): Promise<IWebhookDelivery> {
+    const reset = await WebhookDelivery.findOneAndUpdate(
+      { _id: delivery._id, status: { $ne: 'sending' } },
+      { $set: { status: 'pending', nextAttemptAt: new Date() } },
+      { new: true }
+    );
+    return reset ? this.attempt(reset) : delivery;
+  }
+
+  async retryDue(): Promise<void> {
+    if (this.sweeping) return;
+    this.sweeping = true;
+
+    try {
+      const due = await WebhookDelivery.find(this.claimableFilter()).limit(100);
+
+      for (const delivery of due) {
+        await this.attempt(delivery);
+      }
+    } finally {
+      this.sweeping = false;
+    }
+  }
+
+  // Pending deliveries that are due, and claims left behind by a dispatcher that died mid-attempt
+  private claimableFilter(): any {
+    const now = Date.now();
+    return {
+      $or: [
+        { status: 'pending', nextAttemptAt: { $lte: new Date(now) } },
+        { status: 'sending', claimedAt: { $lte: new Date(now - CLAIM_TTL_MS) } }
+      ]
+    };
+  }
+
+  // Moves the delivery from pending to sending so only one dispatcher (or replica) attempts it
+  private async claim(delivery: IWebhookDelivery): Promise<IWebhookDelivery | null> {
+    return WebhookDelivery.findOneAndUpdate(
+      { _id: delivery._id, ...this.claimableFilter() },
+      { $set: { status: 'sending', claimedAt: new Date() } },
+      { new: true }
+    );
+  }
+
+  private async attempt(pending: IWebhookDelivery): Promise<IWebhookDelivery> {
+    const delivery = await this.claim(pending);
+    if (!delivery) return pending;
+
+    const endpoint = await WebhookEndpoint.findById(delivery.endpointId).select('+secret');
+    if (!endpoint || !endpoint.active) {
+      delivery.status = 'failed';
+      delivery.nextAttemptAt = undefined;
+      delivery.claimedAt = undefined;
+      await delivery.save();
+      return delivery;
+    }
+
+    const attemptNumber = delivery.attempts.length + 1;
+    const body = JSON.stringify(delivery.payload);
+    const timestamp = Math.floor(Date.now() / 1000).toString();
+    const startedAt = Date.now();
+
+    try {
+      const response = await axios.post(endpoint.url, body, {
+        headers: {
+          'Content-Type': 'application/cloudevents+json',
+          'User-Agent': 'AI-Pipeline-Webhooks/1.0',
+          'X-Webhook-Id': delivery.eventId,
+          'X-Webhook-Event': delivery.eventType,
+          'X-Webhook-Timestamp': timestamp,
+          'X-Webhook-Signature': signPayload(endpoint.secret, timestamp, body)
+        },
+        timeout: TIMEOUT_MS,
+        validateStatus: () => true
+      });
+
+      const succeeded = response.status >= 200 && response.status < 300;
+      delivery.attempts.push({
+        attempt: attemptNumber,
+        statusCode: response.status,
+        error: succeeded ? undefined : `Endpoint responded with ${response.status}`,
+        durationMs: Date.now() - startedAt,
+        attemptedAt: new Date()
+      });
+
+      if (succeeded) {
+        delivery.status = 'delivered';
+        delivery.deliveredAt = new Date();
+        delivery.nextAttemptAt = undefined;
+      } else {
+        this.scheduleRetry(delivery, attemptNumber);
+      }
+    } catch (error) {
+      delivery.attempts.push({
+        attempt: attemptNumber,
+        error: error instanceof Error ? error.message : 'Delivery failed',
+        durationMs: Date.now() - startedAt,
+        attemptedAt: new Date()
+      });
+      this.scheduleRetry(delivery, attemptNumber);
+    }
+
+    delivery.claimedAt = undefined;
+    await delivery.save();
+    return delivery;
+  }
+
+  private scheduleRetry(delivery: IWebhookDelivery, attemptNumber: number): void {
+    if (attemptNumber >= MAX_ATTEMPTS) {
+      delivery.status = 'failed';
+      delivery.nextAttemptAt = undefined;
+      return;
+    }
+    delivery.status = 'pending';
+    delivery.nextAttemptAt = new Date(Date.now() + calculateRetryDelay(attemptNumber));
+  }
+}